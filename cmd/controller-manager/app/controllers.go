@@ -23,7 +23,10 @@ import (
 	"k8s.io/client-go/rest"
 	"kubesphere.io/kubesphere/pkg/controller/application"
 	"kubesphere.io/kubesphere/pkg/controller/destinationrule"
+	"kubesphere.io/kubesphere/pkg/controller/events"
 	"kubesphere.io/kubesphere/pkg/controller/job"
+	"kubesphere.io/kubesphere/pkg/controller/logindex"
+	"kubesphere.io/kubesphere/pkg/controller/metering"
 
 	//"kubesphere.io/kubesphere/pkg/controller/job"
 	"kubesphere.io/kubesphere/pkg/controller/virtualservice"
@@ -38,6 +41,7 @@ import (
 	applicationinformers "github.com/kubernetes-sigs/application/pkg/client/informers/externalversions"
 	servicemeshclientset "kubesphere.io/kubesphere/pkg/client/clientset/versioned"
 	servicemeshinformers "kubesphere.io/kubesphere/pkg/client/informers/externalversions"
+	fb "kubesphere.io/kubesphere/pkg/simple/client/fluentbit"
 )
 
 const defaultResync = 600 * time.Second
@@ -102,6 +106,25 @@ func AddControllers(mgr manager.Manager, cfg *rest.Config, stopCh <-chan struct{
 
 	jobController := job.NewJobController(informerFactory.Batch().V1().Jobs(), kubeClient)
 
+	eventsExporter := events.NewEventsExporter(informerFactory.Core().V1().Events(),
+		informerFactory.Core().V1().Namespaces(),
+		informerFactory.Core().V1().Pods(),
+		informerFactory.Apps().V1().ReplicaSets(),
+		informerFactory.Batch().V1().Jobs(),
+		kubeClient)
+
+	fluentbitClient, fluentbitScheme, err := fb.NewFluentbitCRDClient(cfg)
+	if err != nil {
+		log.Error(err, "create fluent bit client failed")
+		return err
+	}
+
+	logIndexController := logindex.NewIndexLifecycleController(informerFactory.Core().V1().Namespaces(),
+		fb.CrdClient(fluentbitClient, fluentbitScheme, fb.LoggingNamespace),
+		kubeClient)
+
+	meteringRollupController := metering.NewRollupController(servicemeshclient)
+
 	servicemeshInformer.Start(stopCh)
 	istioInformer.Start(stopCh)
 	informerFactory.Start(stopCh)
@@ -112,6 +135,9 @@ func AddControllers(mgr manager.Manager, cfg *rest.Config, stopCh <-chan struct{
 		"destinationrule-controller": drController,
 		"application-controller":     apController,
 		"job-controller":             jobController,
+		"events-exporter":            eventsExporter,
+		"log-index-lifecycle":        logIndexController,
+		"metering-rollup":            meteringRollupController,
 	}
 
 	for name, ctrl := range controllers {
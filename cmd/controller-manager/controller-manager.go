@@ -28,18 +28,25 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
 	"sigs.k8s.io/controller-runtime/pkg/runtime/signals"
+
+	// Install log backends
+	_ "kubesphere.io/kubesphere/pkg/simple/client/loki"
 )
 
 var (
-	masterURL   string
-	kubeconfig  string
-	metricsAddr string
+	masterURL               string
+	kubeconfig              string
+	metricsAddr             string
+	leaderElection          bool
+	leaderElectionNamespace string
 )
 
 func init() {
 	flag.StringVar(&masterURL, "master-url", "", "only need if out of cluster")
 	flag.StringVar(&kubeconfig, "kubeconfig", "", "only need if out of cluster")
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
+	flag.BoolVar(&leaderElection, "leader-elect", true, "Run controllers only in the replica holding the leader lock.")
+	flag.StringVar(&leaderElectionNamespace, "leader-elect-namespace", "", "Namespace of the leader lock, the namespace of the pod if it's empty. Only needed if out of cluster.")
 }
 
 func main() {
@@ -57,7 +64,11 @@ func main() {
 	stopCh := signals.SetupSignalHandler()
 
 	log.Info("setting up manager")
-	mgr, err := manager.New(cfg, manager.Options{})
+	mgr, err := manager.New(cfg, manager.Options{
+		LeaderElection:          leaderElection,
+		LeaderElectionNamespace: leaderElectionNamespace,
+		LeaderElectionID:        "ks-controller-manager-leader-election",
+	})
 	if err != nil {
 		log.Error(err, "unable to set up overall controller manager")
 		os.Exit(1)
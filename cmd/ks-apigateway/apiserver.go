@@ -22,8 +22,10 @@ import (
 	"github.com/mholt/caddy/caddyhttp/httpserver"
 
 	// Install apis
+	_ "kubesphere.io/kubesphere/pkg/apigateway/caddy-plugin/audit"
 	_ "kubesphere.io/kubesphere/pkg/apigateway/caddy-plugin/authenticate"
 	_ "kubesphere.io/kubesphere/pkg/apigateway/caddy-plugin/authentication"
+	_ "kubesphere.io/kubesphere/pkg/apigateway/caddy-plugin/ratelimit"
 	_ "kubesphere.io/kubesphere/pkg/apigateway/caddy-plugin/swagger"
 )
 
@@ -31,5 +33,7 @@ func main() {
 	httpserver.RegisterDevDirective("authenticate", "jwt")
 	httpserver.RegisterDevDirective("authentication", "jwt")
 	httpserver.RegisterDevDirective("swagger", "jwt")
+	httpserver.RegisterDevDirective("throttle", "jwt")
+	httpserver.RegisterDevDirective("audit", "jwt")
 	caddymain.Run()
 }
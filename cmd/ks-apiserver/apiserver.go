@@ -29,6 +29,8 @@ import (
 	_ "kubesphere.io/kubesphere/pkg/apis/servicemesh/metrics/install"
 	_ "kubesphere.io/kubesphere/pkg/apis/tenant/install"
 	_ "kubesphere.io/kubesphere/pkg/apis/terminal/install"
+	// Install log backends
+	_ "kubesphere.io/kubesphere/pkg/simple/client/loki"
 )
 
 func main() {
@@ -22,6 +22,9 @@ type ServerRunOptions struct {
 
 	// openpitrix service token
 	OpenPitrixProxyToken string
+
+	// resources cached by dynamic informers and searchable by resources api, such as virtualservices.networking.istio.io
+	CachedResources []string
 }
 
 func NewServerRunOptions() *ServerRunOptions {
@@ -42,4 +45,5 @@ func (s *ServerRunOptions) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&s.IstioPilotServiceURL, "istio-pilot-service-url", "http://istio-pilot.istio-system.svc:8080/version", "istio pilot discovery service url")
 	fs.StringVar(&s.JaegerQueryServiceUrl, "jaeger-query-service-url", "http://jaeger-query.istio-system.svc:16686/jaeger", "jaeger query service url")
 	fs.StringVar(&s.ServicemeshPrometheusServiceUrl, "servicemesh-prometheus-service-url", "http://prometheus-k8s-system.kubesphere-monitoring-system.svc:9090", "prometheus service for servicemesh")
+	fs.StringSliceVar(&s.CachedResources, "cached-resources", []string{}, "resources cached and searchable by resources api, in the form of <resource>.<group> or <resource>.<version>.<group>, such as virtualservices.networking.istio.io")
 }
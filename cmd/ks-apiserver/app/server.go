@@ -32,10 +32,12 @@ import (
 	"kubesphere.io/kubesphere/pkg/informers"
 	"kubesphere.io/kubesphere/pkg/models/devops"
 	logging "kubesphere.io/kubesphere/pkg/models/log"
+	"kubesphere.io/kubesphere/pkg/models/resources"
 	"kubesphere.io/kubesphere/pkg/server"
 	"kubesphere.io/kubesphere/pkg/signals"
 	"kubesphere.io/kubesphere/pkg/simple/client/admin_jenkins"
 	"kubesphere.io/kubesphere/pkg/simple/client/devops_mysql"
+	fluentbitclient "kubesphere.io/kubesphere/pkg/simple/client/fluentbit"
 	"log"
 	"net/http"
 )
@@ -70,12 +72,15 @@ func Run(s *options.ServerRunOptions) error {
 
 	var err error
 
-	waitForResourceSync()
+	if err = waitForResourceSync(s); err != nil {
+		return err
+	}
 
 	container := runtime.Container
 	container.DoNotRecover(false)
 	container.Filter(filter.Logging)
 	container.RecoverHandler(server.LogStackOnRecover)
+	runtime.InstallOpenAPIService(container)
 	for _, webservice := range container.RegisteredWebServices() {
 		for _, route := range webservice.Routes() {
 			log.Println(route.Method, route.Path)
@@ -140,14 +145,14 @@ func initializeESClientConfig() {
 
 	// Iterate the outputs to get elasticsearch configs
 	for _, output := range outputs {
-		if configs := logging.ParseEsOutputParams(output.Parameters); configs != nil {
+		if configs := fluentbitclient.ParseEsOutputParams(output.Parameters); configs != nil {
 			configs.WriteESConfigs()
 			return
 		}
 	}
 }
 
-func waitForResourceSync() {
+func waitForResourceSync(s *options.ServerRunOptions) error {
 	stopChan := signals.SetupSignalHandler()
 
 	informerFactory := informers.SharedInformerFactory()
@@ -176,6 +181,7 @@ func waitForResourceSync() {
 	informerFactory.Batch().V1().Jobs().Lister()
 	informerFactory.Batch().V1beta1().CronJobs().Lister()
 	informerFactory.Extensions().V1beta1().Ingresses().Lister()
+	informerFactory.Autoscaling().V1().HorizontalPodAutoscalers().Lister()
 
 	informerFactory.Start(stopChan)
 	informerFactory.WaitForCacheSync(stopChan)
@@ -190,9 +196,28 @@ func waitForResourceSync() {
 
 	ksInformerFactory := informers.KsSharedInformerFactory()
 	ksInformerFactory.Tenant().V1alpha1().Workspaces().Lister()
+	ksInformerFactory.Logging().V1alpha1().SavedLogQueries().Lister()
+	ksInformerFactory.Monitoring().V1alpha1().Dashboards().Lister()
+	ksInformerFactory.Monitoring().V1alpha1().MeteringRollups().Lister()
 
 	ksInformerFactory.Start(stopChan)
 	ksInformerFactory.WaitForCacheSync(stopChan)
 
+	if err := resources.RegisterCustomResources(s.CachedResources); err != nil {
+		return err
+	}
+
+	dynamicInformerFactory := informers.DynamicSharedInformerFactory()
+	dynamicInformerFactory.Start(stopChan)
+	for gvr, synced := range dynamicInformerFactory.WaitForCacheSync(stopChan) {
+		if !synced {
+			return fmt.Errorf("failed to sync %s", gvr)
+		}
+	}
+
+	resources.StartIndex()
+
 	log.Println("resources sync success")
+
+	return nil
 }
@@ -21,6 +21,7 @@ import (
 	goflag "flag"
 	"fmt"
 	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"kubesphere.io/kubesphere/cmd/ks-iam/app/options"
@@ -86,6 +87,8 @@ func Run(s *options.ServerRunOptions) error {
 	container.Filter(filter.Logging)
 	container.DoNotRecover(false)
 	container.RecoverHandler(server.LogStackOnRecover)
+	runtime.InstallOpenAPIService(container)
+	container.Handle("/metrics", promhttp.Handler())
 
 	for _, webservice := range container.RegisteredWebServices() {
 		for _, route := range webservice.Routes() {
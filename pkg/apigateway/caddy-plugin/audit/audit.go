@@ -0,0 +1,298 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package audit
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mholt/caddy/caddyhttp/httpserver"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	auditinternal "k8s.io/apiserver/pkg/apis/audit"
+	"k8s.io/apiserver/pkg/audit"
+	"k8s.io/apiserver/pkg/audit/policy"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	"kubesphere.io/kubesphere/pkg/constants"
+	"kubesphere.io/kubesphere/pkg/informers"
+)
+
+const (
+	// request and response bodies larger than this are not recorded
+	maxBodyBytes = 1 << 20
+	// annotation key of the workspace which the audited object belongs to
+	workspaceAnnotationKey = "kubesphere.io/workspace"
+	// bodies which are not json, eg. csv exports and plain text errors, are recorded in annotations
+	requestBodyAnnotationKey  = "audit.kubesphere.io/request-body"
+	responseBodyAnnotationKey = "audit.kubesphere.io/response-body"
+	// bodies recorded in annotations are truncated to this size
+	maxAnnotatedBodyBytes = 4 << 10
+)
+
+type Audit struct {
+	Rule    Rule
+	Checker policy.Checker
+	Backend audit.Backend
+	Next    httpserver.Handler
+}
+
+type Rule struct {
+	Path          string
+	ExceptedPath  []string
+	PolicyFile    string
+	LogPath       string
+	WebhookURL    string
+	ESHost        string
+	ESPort        string
+	ESIndexPrefix string
+}
+
+func (a Audit) ServeHTTP(w http.ResponseWriter, r *http.Request) (int, error) {
+
+	if !httpserver.Path(r.URL.Path).Matches(a.Rule.Path) {
+		return a.Next.ServeHTTP(w, r)
+	}
+
+	for _, path := range a.Rule.ExceptedPath {
+		if httpserver.Path(r.URL.Path).Matches(path) {
+			return a.Next.ServeHTTP(w, r)
+		}
+	}
+
+	attrs, ok := getAuthorizerAttributes(r)
+
+	// without authenticate, no requestInfo found in the context
+	if !ok {
+		return a.Next.ServeHTTP(w, r)
+	}
+
+	level, omitStages := a.Checker.LevelAndStages(attrs)
+
+	if level == auditinternal.LevelNone || hasStage(omitStages, auditinternal.StageResponseComplete) {
+		return a.Next.ServeHTTP(w, r)
+	}
+
+	event, err := audit.NewEventFromRequest(r, level, attrs)
+
+	if err != nil {
+		log.Println("failed to create audit event", err)
+		return a.Next.ServeHTTP(w, r)
+	}
+
+	if workspace := workspaceOf(r, attrs); workspace != "" {
+		audit.LogAnnotation(event, workspaceAnnotationKey, workspace)
+	}
+
+	if !level.Less(auditinternal.LevelRequest) && r.Body != nil {
+		if body, ok := peekBody(r); ok {
+			event.RequestObject = recordBody(event, requestBodyAnnotationKey, r.Header.Get("Content-Type"), body)
+		}
+	}
+
+	recorder := &responseRecorder{
+		ResponseWriterWrapper: &httpserver.ResponseWriterWrapper{ResponseWriter: w},
+		recordBody:            !level.Less(auditinternal.LevelRequestResponse),
+	}
+
+	status, err := a.Next.ServeHTTP(recorder, r)
+
+	// the status is written by caddy when the handler does not write the response itself
+	if recorder.status == 0 {
+		recorder.status = status
+	}
+
+	if recorder.status == 0 {
+		recorder.status = http.StatusOK
+	}
+
+	event.Stage = auditinternal.StageResponseComplete
+	event.StageTimestamp = metav1.NowMicro()
+	event.ResponseStatus = &metav1.Status{Code: int32(recorder.status)}
+
+	if recorder.status >= http.StatusBadRequest {
+		event.ResponseStatus.Status = metav1.StatusFailure
+		if err != nil {
+			event.ResponseStatus.Message = err.Error()
+		}
+	} else {
+		event.ResponseStatus.Status = metav1.StatusSuccess
+	}
+
+	if recorder.recordBody && !recorder.truncated && recorder.body.Len() > 0 {
+		event.ResponseObject = recordBody(event, responseBodyAnnotationKey, recorder.Header().Get("Content-Type"), recorder.body.Bytes())
+	}
+
+	a.Backend.ProcessEvents(event)
+
+	return status, err
+}
+
+// peekBody reads the request body and restores it for the next handlers
+func peekBody(r *http.Request) ([]byte, bool) {
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxBodyBytes+1))
+
+	r.Body = ioutil.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+
+	if err != nil || len(body) > maxBodyBytes || len(body) == 0 {
+		return nil, false
+	}
+
+	return body, true
+}
+
+// recordBody returns the body as the object of the event if it's json, otherwise the body is recorded in the annotation
+func recordBody(event *auditinternal.Event, annotationKey, contentType string, body []byte) *runtime.Unknown {
+	if isJSON(contentType) && json.Valid(body) {
+		return &runtime.Unknown{Raw: body, ContentType: runtime.ContentTypeJSON}
+	}
+	audit.LogAnnotation(event, annotationKey, summarizeBody(contentType, body))
+	return nil
+}
+
+// isJSON returns whether the content type is json, requests without content types may be json as well
+func isJSON(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == runtime.ContentTypeJSON || strings.HasSuffix(mediaType, "+json")
+}
+
+// summarizeBody returns the content type, size and the beginning of the body, binary bodies are encoded in base64
+func summarizeBody(contentType string, body []byte) string {
+	if contentType == "" {
+		contentType = "unknown"
+	}
+
+	text := body
+	if len(text) > maxAnnotatedBodyBytes {
+		text = text[:maxAnnotatedBodyBytes]
+	}
+
+	if !utf8.Valid(body) {
+		return fmt.Sprintf("%s, %d bytes, base64: %s", contentType, len(body), base64.StdEncoding.EncodeToString(text))
+	}
+
+	// the body may be cut in the middle of a character
+	for !utf8.Valid(text) {
+		text = text[:len(text)-1]
+	}
+	return fmt.Sprintf("%s, %d bytes: %s", contentType, len(body), text)
+}
+
+// workspaceOf returns the workspace of the request, from the kapis path or the namespace label
+func workspaceOf(r *http.Request, attrs authorizer.Attributes) string {
+
+	if attrs.GetResource() == "workspaces" && attrs.GetName() != "" {
+		return attrs.GetName()
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	for i := 0; i < len(parts)-1; i++ {
+		if parts[i] == "workspaces" {
+			return parts[i+1]
+		}
+	}
+
+	if attrs.GetNamespace() != "" {
+		namespace, err := informers.SharedInformerFactory().Core().V1().Namespaces().Lister().Get(attrs.GetNamespace())
+		if err == nil {
+			return namespace.Labels[constants.WorkspaceLabelKey]
+		}
+	}
+
+	return ""
+}
+
+func hasStage(stages []auditinternal.Stage, stage auditinternal.Stage) bool {
+	for _, s := range stages {
+		if s == stage {
+			return true
+		}
+	}
+	return false
+}
+
+func getAuthorizerAttributes(r *http.Request) (authorizer.Attributes, bool) {
+	attribs := authorizer.AttributesRecord{}
+
+	user, ok := request.UserFrom(r.Context())
+	if ok {
+		attribs.User = user
+	}
+
+	requestInfo, found := request.RequestInfoFrom(r.Context())
+	if !found {
+		return nil, false
+	}
+
+	attribs.ResourceRequest = requestInfo.IsResourceRequest
+	attribs.Path = requestInfo.Path
+	attribs.Verb = requestInfo.Verb
+
+	attribs.APIGroup = requestInfo.APIGroup
+	attribs.APIVersion = requestInfo.APIVersion
+	attribs.Resource = requestInfo.Resource
+	attribs.Subresource = requestInfo.Subresource
+	attribs.Namespace = requestInfo.Namespace
+	attribs.Name = requestInfo.Name
+
+	return &attribs, true
+}
+
+type responseRecorder struct {
+	*httpserver.ResponseWriterWrapper
+	status     int
+	recordBody bool
+	truncated  bool
+	body       bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriterWrapper.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(buf []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	if r.recordBody && !r.truncated {
+		if r.body.Len()+len(buf) > maxBodyBytes {
+			r.truncated = true
+			r.body.Reset()
+		} else {
+			r.body.Write(buf)
+		}
+	}
+	return r.ResponseWriterWrapper.Write(buf)
+}
@@ -0,0 +1,241 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package audit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	auditinternal "k8s.io/apiserver/pkg/apis/audit"
+	"k8s.io/apiserver/pkg/audit/policy"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/request"
+)
+
+type fakeBackend struct {
+	events []*auditinternal.Event
+}
+
+func (b *fakeBackend) ProcessEvents(events ...*auditinternal.Event) bool {
+	b.events = append(b.events, events...)
+	return true
+}
+
+func (b *fakeBackend) Run(stopCh <-chan struct{}) error {
+	return nil
+}
+
+func (b *fakeBackend) Shutdown() {
+}
+
+func (b *fakeBackend) String() string {
+	return "fake"
+}
+
+type writeHandler struct {
+	status int
+	body   string
+}
+
+func (h writeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) (int, error) {
+	w.WriteHeader(h.status)
+	w.Write([]byte(h.body))
+	return h.status, nil
+}
+
+func newAuditRequest(method, path, body string, info *request.RequestInfo) *http.Request {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	ctx := request.WithUser(req.Context(), &user.DefaultInfo{Name: "admin"})
+	ctx = request.WithRequestInfo(ctx, info)
+	return req.WithContext(ctx)
+}
+
+func TestServeHTTPPolicyLevels(t *testing.T) {
+	auditPolicy := &auditinternal.Policy{
+		Rules: []auditinternal.PolicyRule{
+			{Level: auditinternal.LevelNone, Verbs: []string{"get", "list", "watch"}},
+			{Level: auditinternal.LevelRequestResponse, Resources: []auditinternal.GroupResources{{Group: "tenant.kubesphere.io", Resources: []string{"workspaces"}}}},
+			{Level: auditinternal.LevelRequest, Resources: []auditinternal.GroupResources{{Group: "iam.kubesphere.io"}}},
+			{Level: auditinternal.LevelMetadata},
+		},
+	}
+
+	tests := []struct {
+		method          string
+		path            string
+		info            *request.RequestInfo
+		level           auditinternal.Level
+		requestObject   bool
+		responseObject  bool
+		workspace       string
+		expectedEvents  int
+		expectedStatus  int32
+		expectedFailure bool
+	}{
+		{
+			method: http.MethodGet,
+			path:   "/apis/tenant.kubesphere.io/v1alpha1/workspaces",
+			info:   &request.RequestInfo{IsResourceRequest: true, Verb: "list", APIGroup: "tenant.kubesphere.io", Resource: "workspaces"},
+		},
+		{
+			method:         http.MethodPost,
+			path:           "/apis/tenant.kubesphere.io/v1alpha1/workspaces",
+			info:           &request.RequestInfo{IsResourceRequest: true, Verb: "create", APIGroup: "tenant.kubesphere.io", Resource: "workspaces", Name: "ws"},
+			level:          auditinternal.LevelRequestResponse,
+			requestObject:  true,
+			responseObject: true,
+			workspace:      "ws",
+			expectedEvents: 1,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			method:         http.MethodPost,
+			path:           "/kapis/iam.kubesphere.io/v1alpha2/workspaces/ws/members",
+			info:           &request.RequestInfo{IsResourceRequest: true, Verb: "create", APIGroup: "iam.kubesphere.io", Resource: "workspaces", Subresource: "members"},
+			level:          auditinternal.LevelRequest,
+			requestObject:  true,
+			workspace:      "ws",
+			expectedEvents: 1,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			method:          http.MethodDelete,
+			path:            "/apis/devops.kubesphere.io/v1alpha1/s2iruns/run",
+			info:            &request.RequestInfo{IsResourceRequest: true, Verb: "delete", APIGroup: "devops.kubesphere.io", Resource: "s2iruns", Name: "run"},
+			level:           auditinternal.LevelMetadata,
+			expectedEvents:  1,
+			expectedStatus:  http.StatusForbidden,
+			expectedFailure: true,
+		},
+	}
+
+	for i, test := range tests {
+		status := int(test.expectedStatus)
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		backend := &fakeBackend{}
+		handler := Audit{
+			Rule:    Rule{Path: "/"},
+			Checker: policy.NewChecker(auditPolicy),
+			Backend: backend,
+			Next:    writeHandler{status: status, body: `{"kind":"Status"}`},
+		}
+
+		handler.ServeHTTP(httptest.NewRecorder(), newAuditRequest(test.method, test.path, `{"metadata":{"name":"ws"}}`, test.info))
+
+		if len(backend.events) != test.expectedEvents {
+			t.Errorf("case %d: expected %d events, got %d", i, test.expectedEvents, len(backend.events))
+			continue
+		}
+		if test.expectedEvents == 0 {
+			continue
+		}
+
+		event := backend.events[0]
+		if event.Level != test.level {
+			t.Errorf("case %d: expected level %s, got %s", i, test.level, event.Level)
+		}
+		if (event.RequestObject != nil) != test.requestObject {
+			t.Errorf("case %d: expected request object %t, got %v", i, test.requestObject, event.RequestObject)
+		}
+		if (event.ResponseObject != nil) != test.responseObject {
+			t.Errorf("case %d: expected response object %t, got %v", i, test.responseObject, event.ResponseObject)
+		}
+		if event.Annotations[workspaceAnnotationKey] != test.workspace {
+			t.Errorf("case %d: expected workspace %q, got %q", i, test.workspace, event.Annotations[workspaceAnnotationKey])
+		}
+		if event.Stage != auditinternal.StageResponseComplete || event.ResponseStatus == nil || event.ResponseStatus.Code != test.expectedStatus {
+			t.Errorf("case %d: expected status %d at %s, got %v at %s", i, test.expectedStatus, auditinternal.StageResponseComplete, event.ResponseStatus, event.Stage)
+		}
+		if failed := event.ResponseStatus != nil && event.ResponseStatus.Status == "Failure"; failed != test.expectedFailure {
+			t.Errorf("case %d: expected failure %t, got %v", i, test.expectedFailure, event.ResponseStatus)
+		}
+	}
+}
+
+func TestServeHTTPRestoresRequestBody(t *testing.T) {
+	backend := &fakeBackend{}
+	var received string
+
+	handler := Audit{
+		Rule:    Rule{Path: "/"},
+		Checker: policy.NewChecker(defaultPolicy),
+		Backend: backend,
+		Next: handlerFunc(func(w http.ResponseWriter, r *http.Request) (int, error) {
+			body := new(strings.Builder)
+			buf := make([]byte, 1024)
+			for {
+				n, err := r.Body.Read(buf)
+				body.Write(buf[:n])
+				if err != nil {
+					break
+				}
+			}
+			received = body.String()
+			return http.StatusOK, nil
+		}),
+	}
+
+	info := &request.RequestInfo{IsResourceRequest: true, Verb: "update", APIGroup: "tenant.kubesphere.io", Resource: "workspaces", Name: "ws"}
+	handler.ServeHTTP(httptest.NewRecorder(), newAuditRequest(http.MethodPut, "/apis/tenant.kubesphere.io/v1alpha1/workspaces/ws", `{"spec":{}}`, info))
+
+	if received != `{"spec":{}}` {
+		t.Errorf("expected the request body to be passed to the next handler, got %q", received)
+	}
+	if len(backend.events) != 1 || backend.events[0].Level != auditinternal.LevelMetadata {
+		t.Errorf("expected a metadata event of the default policy, got %v", backend.events)
+	}
+}
+
+type handlerFunc func(http.ResponseWriter, *http.Request) (int, error)
+
+func (f handlerFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) (int, error) {
+	return f(w, r)
+}
+
+func TestRecordBody(t *testing.T) {
+	tests := []struct {
+		contentType string
+		body        string
+		embedded    bool
+		annotation  string
+	}{
+		{"application/json", `{"name":"admin"}`, true, ""},
+		{"application/merge-patch+json; charset=utf-8", `{"spec":{}}`, true, ""},
+		{"", `{"name":"admin"}`, true, ""},
+		{"text/csv", "username,email\nadmin,admin@kubesphere.io\n", false, "text/csv, 41 bytes: username,email\nadmin,admin@kubesphere.io\n"},
+		{"application/json", "user admin not found", false, "application/json, 20 bytes: user admin not found"},
+		{"application/octet-stream", "\xff\xfe", false, "application/octet-stream, 2 bytes, base64: //4="},
+		{"text/plain", strings.Repeat("é", maxAnnotatedBodyBytes), false, "text/plain, 8192 bytes: " + strings.Repeat("é", maxAnnotatedBodyBytes/2)},
+	}
+
+	for i, test := range tests {
+		event := &auditinternal.Event{Level: auditinternal.LevelRequestResponse}
+		object := recordBody(event, requestBodyAnnotationKey, test.contentType, []byte(test.body))
+		if (object != nil) != test.embedded {
+			t.Errorf("case %d: expected embedded %v, got %v", i, test.embedded, object)
+		}
+		if annotation := event.Annotations[requestBodyAnnotationKey]; annotation != test.annotation {
+			t.Errorf("case %d: expected annotation %q, got %q", i, test.annotation, annotation)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package audit
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mholt/caddy"
+	"github.com/mholt/caddy/caddyhttp/httpserver"
+	auditinternal "k8s.io/apiserver/pkg/apis/audit"
+	"k8s.io/apiserver/pkg/audit"
+	"k8s.io/apiserver/pkg/audit/policy"
+
+	"kubesphere.io/kubesphere/pkg/informers"
+)
+
+func init() {
+	caddy.RegisterPlugin("audit", caddy.Plugin{
+		ServerType: "http",
+		Action:     Setup,
+	})
+}
+
+// defaultPolicy records metadata of all mutating requests
+var defaultPolicy = &auditinternal.Policy{
+	Rules: []auditinternal.PolicyRule{
+		{Level: auditinternal.LevelNone, Verbs: []string{"get", "list", "watch"}},
+		{Level: auditinternal.LevelMetadata},
+	},
+}
+
+// Setup is called by Caddy to parse the config block
+func Setup(c *caddy.Controller) error {
+
+	rule, err := parse(c)
+
+	if err != nil {
+		return err
+	}
+
+	auditPolicy := defaultPolicy
+
+	if rule.PolicyFile != "" {
+		auditPolicy, err = policy.LoadPolicyFromFile(rule.PolicyFile)
+		if err != nil {
+			return c.Err(err.Error())
+		}
+	}
+
+	backends := make([]audit.Backend, 0)
+
+	if rule.LogPath != "" {
+		fileSink, err := newFileSink(rule.LogPath)
+		if err != nil {
+			return c.Err(err.Error())
+		}
+		backends = append(backends, newBufferedBackend(fileSink))
+	}
+
+	if rule.WebhookURL != "" {
+		backends = append(backends, newBufferedBackend(newWebhookSink(rule.WebhookURL)))
+	}
+
+	if rule.ESHost != "" {
+		backends = append(backends, newBufferedBackend(newElasticsearchSink(rule.ESHost, rule.ESPort, rule.ESIndexPrefix)))
+	}
+
+	if len(backends) == 0 {
+		return c.Err("at least one of log, webhook and elasticsearch is required")
+	}
+
+	backend := audit.Union(backends...)
+
+	stopChan := make(chan struct{}, 0)
+
+	c.OnStartup(func() error {
+		informerFactory := informers.SharedInformerFactory()
+		informerFactory.Core().V1().Namespaces().Lister()
+		informerFactory.Start(stopChan)
+		informerFactory.WaitForCacheSync(stopChan)
+		if err := backend.Run(stopChan); err != nil {
+			return err
+		}
+		fmt.Println("Audit middleware is initiated")
+		return nil
+	})
+
+	c.OnShutdown(func() error {
+		close(stopChan)
+		backend.Shutdown()
+		return nil
+	})
+
+	httpserver.GetConfig(c).AddMiddleware(func(next httpserver.Handler) httpserver.Handler {
+		return &Audit{Next: next, Rule: rule, Checker: policy.NewChecker(auditPolicy), Backend: backend}
+	})
+
+	return nil
+}
+
+func parse(c *caddy.Controller) (Rule, error) {
+
+	rule := Rule{Path: "/", ExceptedPath: make([]string, 0), ESIndexPrefix: "ks-audit"}
+
+	if c.Next() {
+		args := c.RemainingArgs()
+		switch len(args) {
+		case 0:
+			for c.NextBlock() {
+				switch c.Val() {
+				case "path":
+					if !c.NextArg() {
+						return rule, c.ArgErr()
+					}
+
+					rule.Path = c.Val()
+
+					if c.NextArg() {
+						return rule, c.ArgErr()
+					}
+				case "except":
+					if !c.NextArg() {
+						return rule, c.ArgErr()
+					}
+
+					rule.ExceptedPath = strings.Split(c.Val(), ",")
+
+					for i := 0; i < len(rule.ExceptedPath); i++ {
+						rule.ExceptedPath[i] = strings.TrimSpace(rule.ExceptedPath[i])
+					}
+
+					if c.NextArg() {
+						return rule, c.ArgErr()
+					}
+				case "policy":
+					if !c.NextArg() {
+						return rule, c.ArgErr()
+					}
+
+					rule.PolicyFile = c.Val()
+
+					if c.NextArg() {
+						return rule, c.ArgErr()
+					}
+				case "log":
+					if !c.NextArg() {
+						return rule, c.ArgErr()
+					}
+
+					rule.LogPath = c.Val()
+
+					if c.NextArg() {
+						return rule, c.ArgErr()
+					}
+				case "webhook":
+					if !c.NextArg() {
+						return rule, c.ArgErr()
+					}
+
+					rule.WebhookURL = c.Val()
+
+					if c.NextArg() {
+						return rule, c.ArgErr()
+					}
+				case "elasticsearch":
+					args := c.RemainingArgs()
+
+					switch len(args) {
+					case 3:
+						rule.ESIndexPrefix = args[2]
+						fallthrough
+					case 2:
+						rule.ESHost = args[0]
+						rule.ESPort = args[1]
+					default:
+						return rule, c.ArgErr()
+					}
+				default:
+					return rule, c.ArgErr()
+				}
+			}
+		default:
+			return rule, c.ArgErr()
+		}
+	}
+
+	if c.Next() {
+		return rule, c.ArgErr()
+	}
+
+	return rule, nil
+}
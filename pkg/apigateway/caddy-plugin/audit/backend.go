@@ -0,0 +1,264 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	auditinternal "k8s.io/apiserver/pkg/apis/audit"
+	"k8s.io/apiserver/pkg/apis/audit/install"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+	"k8s.io/apiserver/pkg/audit"
+	"kubesphere.io/kubesphere/pkg/simple/client/elasticsearch"
+)
+
+const (
+	bufferSize    = 10000
+	maxBatchSize  = 400
+	flushInterval = 5 * time.Second
+)
+
+var encoder = audit.Codecs.LegacyCodec(auditv1.SchemeGroupVersion)
+
+func init() {
+	// internal events are converted to audit.k8s.io/v1 by the encoder
+	install.Install(audit.Scheme)
+}
+
+// sink writes a batch of events to the storage
+type sink interface {
+	write(events []*auditinternal.Event) error
+	String() string
+}
+
+// bufferedBackend batches events in the background, so that slow sinks never block requests
+type bufferedBackend struct {
+	sink   sink
+	buffer chan *auditinternal.Event
+	wg     sync.WaitGroup
+}
+
+func newBufferedBackend(sink sink) audit.Backend {
+	return &bufferedBackend{sink: sink, buffer: make(chan *auditinternal.Event, bufferSize)}
+}
+
+func (b *bufferedBackend) ProcessEvents(events ...*auditinternal.Event) bool {
+	for _, event := range events {
+		select {
+		case b.buffer <- event.DeepCopy():
+		default:
+			log.Printf("audit buffer of %s is full, event %s dropped", b.sink, event.AuditID)
+			return false
+		}
+	}
+	return true
+}
+
+func (b *bufferedBackend) Run(stopCh <-chan struct{}) error {
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		ticker := time.NewTicker(flushInterval)
+		defer ticker.Stop()
+
+		batch := make([]*auditinternal.Event, 0, maxBatchSize)
+
+		for {
+			select {
+			case event := <-b.buffer:
+				batch = append(batch, event)
+				if len(batch) < maxBatchSize {
+					continue
+				}
+			case <-ticker.C:
+			case <-stopCh:
+				// drain the events left in the buffer
+				for {
+					select {
+					case event := <-b.buffer:
+						batch = append(batch, event)
+						if len(batch) == maxBatchSize {
+							b.flush(batch)
+							batch = make([]*auditinternal.Event, 0, maxBatchSize)
+						}
+					default:
+						b.flush(batch)
+						return
+					}
+				}
+			}
+			b.flush(batch)
+			batch = make([]*auditinternal.Event, 0, maxBatchSize)
+		}
+	}()
+	return nil
+}
+
+func (b *bufferedBackend) flush(batch []*auditinternal.Event) {
+	if len(batch) == 0 {
+		return
+	}
+	if err := b.sink.write(batch); err != nil {
+		log.Printf("failed to write %d audit events to %s: %v", len(batch), b.sink, err)
+	}
+}
+
+func (b *bufferedBackend) Shutdown() {
+	b.wg.Wait()
+}
+
+func (b *bufferedBackend) String() string {
+	return b.sink.String()
+}
+
+// encodeEvents encodes events to audit.k8s.io/v1 one by one, events failed to encode are skipped
+func encodeEvents(events []*auditinternal.Event) [][]byte {
+	items := make([][]byte, 0, len(events))
+	for _, event := range events {
+		data, err := runtime.Encode(encoder, event)
+		if err != nil {
+			log.Printf("failed to encode audit event %s: %v", event.AuditID, err)
+			continue
+		}
+		items = append(items, data)
+	}
+	return items
+}
+
+// fileSink appends events as json lines, the file is rotated by external tools
+type fileSink struct {
+	out io.WriteCloser
+}
+
+func newFileSink(path string) (sink, error) {
+	if path == "-" {
+		return &fileSink{out: os.Stdout}, nil
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &fileSink{out: file}, nil
+}
+
+func (s *fileSink) write(events []*auditinternal.Event) error {
+	for _, data := range encodeEvents(events) {
+		if _, err := s.out.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *fileSink) String() string {
+	return "file"
+}
+
+// webhookSink posts audit.k8s.io/v1 EventList to the webhook, compatible with kubernetes audit webhooks
+type webhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func newWebhookSink(url string) sink {
+	return &webhookSink{url: url, client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+func (s *webhookSink) write(events []*auditinternal.Event) error {
+	items := encodeEvents(events)
+	if len(items) == 0 {
+		return nil
+	}
+
+	// the list is assembled from events encoded one by one, so that an event failed to encode is the only one dropped
+	var data bytes.Buffer
+	fmt.Fprintf(&data, `{"kind":"EventList","apiVersion":"%s","metadata":{},"items":[`, auditv1.SchemeGroupVersion)
+	for i, item := range items {
+		if i > 0 {
+			data.WriteByte(',')
+		}
+		data.Write(bytes.TrimSpace(item))
+	}
+	data.WriteString("]}")
+
+	response, err := s.client.Post(s.url, runtime.ContentTypeJSON, &data)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("webhook responded with status %d", response.StatusCode)
+	}
+
+	return nil
+}
+
+func (s *webhookSink) String() string {
+	return "webhook"
+}
+
+// elasticsearchSink indexes events into daily indices named {prefix}-yyyy.MM.dd,
+// the Elasticsearch of audit events is independent of the one of logging
+type elasticsearchSink struct {
+	es *esclient.ESConfigs
+}
+
+func newElasticsearchSink(host, port, indexPrefix string) sink {
+	return &elasticsearchSink{es: &esclient.ESConfigs{Host: host, Port: port, Index: indexPrefix}}
+}
+
+func (s *elasticsearchSink) write(events []*auditinternal.Event) error {
+	documents := make(map[string][]interface{})
+
+	for _, event := range events {
+		data, err := runtime.Encode(encoder, event)
+		if err != nil {
+			log.Printf("failed to encode audit event %s: %v", event.AuditID, err)
+			continue
+		}
+		var document map[string]interface{}
+		if err := json.Unmarshal(data, &document); err != nil {
+			log.Printf("failed to encode audit event %s: %v", event.AuditID, err)
+			continue
+		}
+		index := fmt.Sprintf("%s-%s", s.es.Index, event.RequestReceivedTimestamp.UTC().Format("2006.01.02"))
+		documents[index] = append(documents[index], document)
+	}
+
+	for index, docs := range documents {
+		if err := s.es.Bulk(index, docs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *elasticsearchSink) String() string {
+	return "elasticsearch"
+}
@@ -0,0 +1,224 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	auditinternal "k8s.io/apiserver/pkg/apis/audit"
+)
+
+type fakeSink struct {
+	mutex   sync.Mutex
+	batches [][]*auditinternal.Event
+}
+
+func (s *fakeSink) write(events []*auditinternal.Event) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.batches = append(s.batches, events)
+	return nil
+}
+
+func (s *fakeSink) String() string {
+	return "fake"
+}
+
+func newEvent(id int) *auditinternal.Event {
+	return &auditinternal.Event{
+		Level:                    auditinternal.LevelMetadata,
+		AuditID:                  types.UID(fmt.Sprintf("event-%d", id)),
+		Stage:                    auditinternal.StageResponseComplete,
+		Verb:                     "create",
+		RequestURI:               "/apis/tenant.kubesphere.io/v1alpha1/workspaces",
+		RequestReceivedTimestamp: metav1.NewMicroTime(time.Date(2019, 6, 1, 8, 0, 0, 0, time.UTC)),
+	}
+}
+
+func TestBufferedBackend(t *testing.T) {
+	sink := &fakeSink{}
+	backend := newBufferedBackend(sink)
+
+	stopCh := make(chan struct{})
+	if err := backend.Run(stopCh); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < maxBatchSize+10; i++ {
+		if !backend.ProcessEvents(newEvent(i)) {
+			t.Fatalf("expected event %d to be buffered", i)
+		}
+	}
+
+	close(stopCh)
+	backend.Shutdown()
+
+	total := 0
+	for _, batch := range sink.batches {
+		if len(batch) > maxBatchSize {
+			t.Errorf("expected batches of at most %d events, got %d", maxBatchSize, len(batch))
+		}
+		total += len(batch)
+	}
+	if total != maxBatchSize+10 {
+		t.Errorf("expected all %d events to be flushed on shutdown, got %d", maxBatchSize+10, total)
+	}
+}
+
+func TestBufferedBackendFull(t *testing.T) {
+	// not running, nothing is taken from the buffer
+	backend := newBufferedBackend(&fakeSink{})
+
+	for i := 0; i < bufferSize; i++ {
+		if !backend.ProcessEvents(newEvent(i)) {
+			t.Fatalf("expected event %d to be buffered", i)
+		}
+	}
+
+	if backend.ProcessEvents(newEvent(bufferSize)) {
+		t.Errorf("expected events to be dropped when the buffer is full")
+	}
+}
+
+// newInvalidEvent returns an event which fails to encode, as its request object is not json
+func newInvalidEvent(id int) *auditinternal.Event {
+	event := newEvent(id)
+	event.RequestObject = &runtime.Unknown{Raw: []byte("username,email\n"), ContentType: runtime.ContentTypeJSON}
+	return event
+}
+
+func TestFileSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "audit.log")
+
+	for i := 0; i < 2; i++ {
+		sink, err := newFileSink(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := sink.write([]*auditinternal.Event{newEvent(2 * i), newInvalidEvent(-1), newEvent(2*i + 1)}); err != nil {
+			t.Fatal(err)
+		}
+		sink.(*fileSink).out.Close()
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	// events are appended as json lines
+	var ids []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event struct {
+			APIVersion string `json:"apiVersion"`
+			AuditID    string `json:"auditID"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatal(err)
+		}
+		if event.APIVersion != "audit.k8s.io/v1" {
+			t.Errorf("expected audit.k8s.io/v1 events, got %s", event.APIVersion)
+		}
+		ids = append(ids, event.AuditID)
+	}
+
+	if strings.Join(ids, ",") != "event-0,event-1,event-2,event-3" {
+		t.Errorf("expected events appended in order, got %v", ids)
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	var received struct {
+		Kind  string `json:"kind"`
+		Items []struct {
+			AuditID string `json:"auditID"`
+		} `json:"items"`
+	}
+	status := http.StatusOK
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	sink := newWebhookSink(server.URL)
+
+	if err := sink.write([]*auditinternal.Event{newEvent(0), newInvalidEvent(-1), newEvent(1)}); err != nil {
+		t.Fatal(err)
+	}
+	if received.Kind != "EventList" || len(received.Items) != 2 || received.Items[1].AuditID != "event-1" {
+		t.Errorf("expected an EventList of 2 events, got %+v", received)
+	}
+
+	status = http.StatusInternalServerError
+	if err := sink.write([]*auditinternal.Event{newEvent(2)}); err == nil {
+		t.Errorf("expected an error if the webhook fails")
+	}
+}
+
+func TestElasticsearchSink(t *testing.T) {
+	var path string
+	var lines []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		body, _ := ioutil.ReadAll(r.Body)
+		lines = strings.Split(strings.TrimSpace(string(body)), "\n")
+		w.Write([]byte(`{"errors":false}`))
+	}))
+	defer server.Close()
+
+	hostPort := strings.Split(strings.TrimPrefix(server.URL, "http://"), ":")
+	sink := newElasticsearchSink(hostPort[0], hostPort[1], "ks-audit")
+
+	if err := sink.write([]*auditinternal.Event{newInvalidEvent(-1), newEvent(0)}); err != nil {
+		t.Fatal(err)
+	}
+
+	if path != "/_bulk" {
+		t.Errorf("expected a bulk request to the Elasticsearch of the sink, got %s", path)
+	}
+	if len(lines) != 2 || !strings.Contains(lines[0], `"_index":"ks-audit-2019.06.01"`) || !strings.Contains(lines[1], `"auditID":"event-0"`) {
+		t.Errorf("expected the event indexed into ks-audit-2019.06.01, got %v", lines)
+	}
+}
@@ -30,17 +30,25 @@ import (
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/mholt/caddy/caddyhttp/httpserver"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"kubesphere.io/kubesphere/pkg/constants"
+	"kubesphere.io/kubesphere/pkg/utils/k8sutil"
 )
 
 type Auth struct {
 	Rule Rule
-	Next httpserver.Handler
+	// ServiceAccountAuthenticator is nil unless service account tokens are enabled
+	ServiceAccountAuthenticator authenticator.Token
+	Next                        httpserver.Handler
 }
 
 type Rule struct {
 	Secret       []byte
 	Path         string
 	ExceptedPath []string
+	// ServiceAccountPath accepts kubernetes service account tokens besides KubeSphere tokens
+	ServiceAccountPath []string
 }
 
 type User struct {
@@ -69,6 +77,23 @@ func (h Auth) ServeHTTP(resp http.ResponseWriter, req *http.Request) (int, error
 			return h.HandleUnauthorized(resp, err), nil
 		}
 
+		if h.ServiceAccountEnabled(req) && isServiceAccountToken(uToken) {
+
+			usr, err := h.ValidateServiceAccount(req, uToken)
+
+			if err != nil {
+				return h.HandleUnauthorized(resp, err), nil
+			}
+
+			req, err = h.InjectUser(req, usr)
+
+			if err != nil {
+				return h.HandleUnauthorized(resp, err), nil
+			}
+
+			return h.Next.ServeHTTP(resp, req)
+		}
+
 		token, err := h.Validate(uToken)
 
 		if err != nil {
@@ -93,18 +118,11 @@ func (h Auth) InjectContext(req *http.Request, token *jwt.Token) (*http.Request,
 		return nil, errors.New("invalid payload")
 	}
 
-	for header := range req.Header {
-		if strings.HasPrefix(header, "X-Token-") {
-			req.Header.Del(header)
-		}
-	}
-
 	usr := &user.DefaultInfo{}
 
 	username, ok := payLoad["username"].(string)
 
 	if ok && username != "" {
-		req.Header.Set("X-Token-Username", username)
 		usr.Name = username
 	}
 
@@ -113,11 +131,9 @@ func (h Auth) InjectContext(req *http.Request, token *jwt.Token) (*http.Request,
 	if uid != nil {
 		switch uid.(type) {
 		case int:
-			req.Header.Set("X-Token-UID", strconv.Itoa(uid.(int)))
 			usr.UID = strconv.Itoa(uid.(int))
 			break
 		case string:
-			req.Header.Set("X-Token-UID", uid.(string))
 			usr.UID = uid.(string)
 			break
 		}
@@ -125,7 +141,6 @@ func (h Auth) InjectContext(req *http.Request, token *jwt.Token) (*http.Request,
 
 	groups, ok := payLoad["groups"].([]string)
 	if ok && len(groups) > 0 {
-		req.Header.Set("X-Token-Groups", strings.Join(groups, ","))
 		usr.Groups = groups
 	}
 
@@ -136,6 +151,35 @@ func (h Auth) InjectContext(req *http.Request, token *jwt.Token) (*http.Request,
 		req.SetBasicAuth(username, token.Raw)
 	}
 
+	return h.InjectUser(req, usr)
+}
+
+// InjectUser passes the user to backends by X-Token-* headers and to the next plugins by context
+func (h Auth) InjectUser(req *http.Request, usr user.Info) (*http.Request, error) {
+
+	for header := range req.Header {
+		if strings.HasPrefix(header, "X-Token-") {
+			req.Header.Del(header)
+		}
+	}
+
+	if usr.GetName() != "" {
+		req.Header.Set("X-Token-Username", usr.GetName())
+	}
+
+	if usr.GetUID() != "" {
+		req.Header.Set("X-Token-UID", usr.GetUID())
+	}
+
+	if len(usr.GetGroups()) > 0 {
+		req.Header.Set("X-Token-Groups", strings.Join(usr.GetGroups(), ","))
+	}
+
+	// backends match ServiceAccount subjects of role bindings only for service accounts
+	if k8sutil.IsServiceAccount(usr) {
+		req.Header.Set(constants.UserKindHeader, rbacv1.ServiceAccountKind)
+	}
+
 	context := request.WithUser(req.Context(), usr)
 
 	requestInfo, err := requestInfoFactory.NewRequestInfo(req)
@@ -166,6 +210,33 @@ func (h Auth) Validate(uToken string) (*jwt.Token, error) {
 	return token, nil
 }
 
+func (h Auth) ServiceAccountEnabled(req *http.Request) bool {
+	if h.ServiceAccountAuthenticator == nil {
+		return false
+	}
+	for _, path := range h.Rule.ServiceAccountPath {
+		if httpserver.Path(req.URL.Path).Matches(path) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h Auth) ValidateServiceAccount(req *http.Request, uToken string) (user.Info, error) {
+
+	resp, ok, err := h.ServiceAccountAuthenticator.AuthenticateToken(req.Context(), uToken)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("invalid service account token")
+	}
+
+	return resp.User, nil
+}
+
 func (h Auth) HandleUnauthorized(w http.ResponseWriter, err error) int {
 	message := fmt.Sprintf("Unauthorized,%v", err)
 	w.Header().Add("WWW-Authenticate", message)
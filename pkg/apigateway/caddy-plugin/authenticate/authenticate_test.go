@@ -0,0 +1,132 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package authenticate
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dgrijalva/jwt-go"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/request"
+
+	"kubesphere.io/kubesphere/pkg/constants"
+	"kubesphere.io/kubesphere/pkg/utils/k8sutil"
+)
+
+type userRecorder struct {
+	user user.Info
+	// forwarded is the identity forwarded to backends
+	forwarded user.Info
+}
+
+func (r *userRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) (int, error) {
+	r.user, _ = request.UserFrom(req.Context())
+	r.forwarded = k8sutil.NewIdentity(req.Header.Get(constants.UserNameHeader), req.Header.Get(constants.UserKindHeader))
+	return http.StatusOK, nil
+}
+
+func TestServiceAccountPath(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secret := []byte("secret")
+	serviceAccountToken := signedToken(t, jwt.SigningMethodRS256, rsaKey)
+	userToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"username": "system:serviceaccount:demo:builder"}).SignedString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	review := authenticator.TokenFunc(func(ctx context.Context, token string) (*authenticator.Response, bool, error) {
+		if token != serviceAccountToken {
+			return nil, false, nil
+		}
+		return &authenticator.Response{User: &user.DefaultInfo{
+			Name:  "system:serviceaccount:demo:builder",
+			Extra: map[string][]string{k8sutil.ServiceAccountTokenExtraKey: {"true"}},
+		}}, true, nil
+	})
+
+	tests := []struct {
+		path           string
+		token          string
+		expected       int
+		serviceAccount bool
+	}{
+		{"/kapis/monitoring.kubesphere.io/v1alpha2/cluster", serviceAccountToken, http.StatusOK, true},
+		// service account tokens are only accepted on configured paths
+		{"/kapis/iam.kubesphere.io/v1alpha2/users", serviceAccountToken, http.StatusUnauthorized, false},
+		// a user named like a service account is not a service account
+		{"/kapis/monitoring.kubesphere.io/v1alpha2/cluster", userToken, http.StatusOK, false},
+		{"/kapis/iam.kubesphere.io/v1alpha2/users", userToken, http.StatusOK, false},
+	}
+
+	for i, test := range tests {
+		next := &userRecorder{}
+		auth := Auth{
+			Rule: Rule{
+				Secret:             secret,
+				Path:               "/",
+				ServiceAccountPath: []string{"/kapis/monitoring.kubesphere.io"},
+			},
+			ServiceAccountAuthenticator: review,
+			Next:                        next,
+		}
+
+		req := httptest.NewRequest(http.MethodGet, test.path, nil)
+		req.Header.Set("Authorization", "Bearer "+test.token)
+		// headers of clients are never forwarded
+		req.Header.Set(constants.UserKindHeader, "ServiceAccount")
+
+		code, err := auth.ServeHTTP(httptest.NewRecorder(), req)
+
+		if err != nil {
+			t.Errorf("case %d: %v", i, err)
+			continue
+		}
+
+		if code != test.expected {
+			t.Errorf("case %d: expected status %d, got %d", i, test.expected, code)
+			continue
+		}
+
+		if code != http.StatusOK {
+			continue
+		}
+
+		if next.user == nil || next.user.GetName() != "system:serviceaccount:demo:builder" {
+			t.Errorf("case %d: unexpected user %+v", i, next.user)
+			continue
+		}
+
+		if k8sutil.IsServiceAccount(next.user) != test.serviceAccount {
+			t.Errorf("case %d: expected service account %t", i, test.serviceAccount)
+		}
+
+		if next.forwarded.GetName() != next.user.GetName() || k8sutil.IsServiceAccount(next.forwarded) != test.serviceAccount {
+			t.Errorf("case %d: expected forwarded service account %t, got %+v", i, test.serviceAccount, next.forwarded)
+		}
+	}
+}
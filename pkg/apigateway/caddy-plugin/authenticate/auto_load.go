@@ -45,15 +45,22 @@ func Setup(c *caddy.Controller) error {
 		return nil
 	})
 
+	auth := Auth{Rule: rule}
+
+	if len(rule.ServiceAccountPath) > 0 {
+		auth.ServiceAccountAuthenticator = NewServiceAccountAuthenticator()
+	}
+
 	httpserver.GetConfig(c).AddMiddleware(func(next httpserver.Handler) httpserver.Handler {
-		return &Auth{Next: next, Rule: rule}
+		auth.Next = next
+		return &auth
 	})
 
 	return nil
 }
 func parse(c *caddy.Controller) (Rule, error) {
 
-	rule := Rule{ExceptedPath: make([]string, 0)}
+	rule := Rule{ExceptedPath: make([]string, 0), ServiceAccountPath: make([]string, 0)}
 
 	if c.Next() {
 		args := c.RemainingArgs()
@@ -92,6 +99,20 @@ func parse(c *caddy.Controller) (Rule, error) {
 						rule.ExceptedPath[i] = strings.TrimSpace(rule.ExceptedPath[i])
 					}
 
+					if c.NextArg() {
+						return rule, c.ArgErr()
+					}
+				case "serviceaccount":
+					if !c.NextArg() {
+						return rule, c.ArgErr()
+					}
+
+					rule.ServiceAccountPath = strings.Split(c.Val(), ",")
+
+					for i := 0; i < len(rule.ServiceAccountPath); i++ {
+						rule.ServiceAccountPath[i] = strings.TrimSpace(rule.ServiceAccountPath[i])
+					}
+
 					if c.NextArg() {
 						return rule, c.ArgErr()
 					}
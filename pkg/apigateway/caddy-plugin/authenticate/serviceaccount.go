@@ -0,0 +1,114 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package authenticate
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/serviceaccount"
+	"k8s.io/apiserver/pkg/authentication/token/cache"
+	"k8s.io/apiserver/pkg/authentication/user"
+
+	"kubesphere.io/kubesphere/pkg/simple/client/k8s"
+	"kubesphere.io/kubesphere/pkg/utils/k8sutil"
+)
+
+const (
+	tokenReviewSuccessTTL = 2 * time.Minute
+	tokenReviewFailureTTL = 10 * time.Second
+)
+
+// NewServiceAccountAuthenticator validates kubernetes service account tokens with TokenReview,
+// results are cached to avoid a review for every request
+func NewServiceAccountAuthenticator() authenticator.Token {
+	return newCachedAuthenticator(authenticator.TokenFunc(reviewToken), tokenReviewSuccessTTL, tokenReviewFailureTTL)
+}
+
+// newCachedAuthenticator caches accepted and rejected tokens, failures of calling the api are not cached
+func newCachedAuthenticator(review authenticator.Token, successTTL, failureTTL time.Duration) authenticator.Token {
+	return cache.New(review, false, successTTL, failureTTL)
+}
+
+func reviewToken(ctx context.Context, token string) (*authenticator.Response, bool, error) {
+
+	review := &authenticationv1.TokenReview{Spec: authenticationv1.TokenReviewSpec{Token: token}}
+
+	result, err := k8s.Client().AuthenticationV1().TokenReviews().Create(review)
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	resp, ok := reviewResponse(result.Status)
+
+	// rejected tokens are not errors, otherwise they wouldn't be cached
+	return resp, ok, nil
+}
+
+// reviewResponse returns the service account of the review, identities are marked as authenticated by
+// TokenReview so that they are matched with ServiceAccount subjects of role bindings
+func reviewResponse(status authenticationv1.TokenReviewStatus) (*authenticator.Response, bool) {
+
+	if !status.Authenticated {
+		if status.Error != "" {
+			log.Println("token review failed:", status.Error)
+		}
+		return nil, false
+	}
+
+	// tokens of kubernetes users are not accepted, they are not managed by KubeSphere
+	if _, _, err := serviceaccount.SplitUsername(status.User.Username); err != nil {
+		log.Printf("%s is not a service account", status.User.Username)
+		return nil, false
+	}
+
+	usr := &user.DefaultInfo{
+		Name:   status.User.Username,
+		UID:    status.User.UID,
+		Groups: status.User.Groups,
+		Extra:  make(map[string][]string),
+	}
+
+	for key, value := range status.User.Extra {
+		usr.Extra[key] = value
+	}
+
+	usr.Extra[k8sutil.ServiceAccountTokenExtraKey] = []string{"true"}
+
+	return &authenticator.Response{User: usr}, true
+}
+
+// isServiceAccountToken reports whether the token is signed asymmetrically like service account tokens,
+// tokens issued by KubeSphere are signed with HMAC
+func isServiceAccountToken(uToken string) bool {
+	token, _, err := new(jwt.Parser).ParseUnverified(uToken, jwt.MapClaims{})
+
+	if err != nil {
+		return false
+	}
+
+	alg, _ := token.Header["alg"].(string)
+
+	return strings.HasPrefix(alg, "RS") || strings.HasPrefix(alg, "ES")
+}
@@ -0,0 +1,174 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package authenticate
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/user"
+
+	"kubesphere.io/kubesphere/pkg/utils/k8sutil"
+)
+
+func signedToken(t *testing.T, method jwt.SigningMethod, key interface{}) string {
+	token, err := jwt.NewWithClaims(method, jwt.MapClaims{"sub": "test"}).SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestIsServiceAccountToken(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		token    string
+		expected bool
+	}{
+		{signedToken(t, jwt.SigningMethodRS256, rsaKey), true},
+		{signedToken(t, jwt.SigningMethodES256, ecdsaKey), true},
+		{signedToken(t, jwt.SigningMethodHS256, []byte("secret")), false},
+		{"not-a-jwt", false},
+		{"", false},
+	}
+
+	for i, test := range tests {
+		if result := isServiceAccountToken(test.token); result != test.expected {
+			t.Errorf("case %d: expected %t, got %t", i, test.expected, result)
+		}
+	}
+}
+
+func TestReviewResponse(t *testing.T) {
+	tests := []struct {
+		status   authenticationv1.TokenReviewStatus
+		expected bool
+	}{
+		{authenticationv1.TokenReviewStatus{
+			Authenticated: true,
+			User: authenticationv1.UserInfo{
+				Username: "system:serviceaccount:demo:builder",
+				Groups:   []string{"system:serviceaccounts", "system:serviceaccounts:demo"},
+				Extra:    map[string]authenticationv1.ExtraValue{"authentication.kubernetes.io/pod-name": {"builder-0"}},
+			},
+		}, true},
+		// kubernetes users are not managed by KubeSphere
+		{authenticationv1.TokenReviewStatus{Authenticated: true, User: authenticationv1.UserInfo{Username: "admin"}}, false},
+		{authenticationv1.TokenReviewStatus{Authenticated: false, Error: "token expired"}, false},
+	}
+
+	for i, test := range tests {
+		resp, ok := reviewResponse(test.status)
+
+		if ok != test.expected {
+			t.Errorf("case %d: expected %t, got %t", i, test.expected, ok)
+			continue
+		}
+
+		if !ok {
+			continue
+		}
+
+		usr := resp.User
+
+		if usr.GetName() != test.status.User.Username || len(usr.GetGroups()) != 2 {
+			t.Errorf("case %d: unexpected user %+v", i, usr)
+		}
+		if !k8sutil.IsServiceAccount(usr) {
+			t.Errorf("case %d: expected the identity to be marked as service account", i)
+		}
+		if usr.GetExtra()["authentication.kubernetes.io/pod-name"][0] != "builder-0" {
+			t.Errorf("case %d: expected extra to be kept, got %v", i, usr.GetExtra())
+		}
+	}
+}
+
+func TestCachedAuthenticator(t *testing.T) {
+	reviews := make(map[string]int)
+
+	review := authenticator.TokenFunc(func(ctx context.Context, token string) (*authenticator.Response, bool, error) {
+		reviews[token]++
+		switch token {
+		case "valid":
+			return &authenticator.Response{User: &user.DefaultInfo{Name: "system:serviceaccount:demo:builder"}}, true, nil
+		case "unavailable":
+			return nil, false, context.DeadlineExceeded
+		default:
+			return nil, false, nil
+		}
+	})
+
+	successTTL, failureTTL := 200*time.Millisecond, 50*time.Millisecond
+
+	cached := newCachedAuthenticator(review, successTTL, failureTTL)
+
+	authenticate := func(tokens ...string) {
+		for _, token := range tokens {
+			cached.AuthenticateToken(context.Background(), token)
+		}
+	}
+
+	authenticate("valid", "valid", "invalid", "invalid", "unavailable", "unavailable")
+
+	tests := []struct {
+		token    string
+		expected int
+	}{
+		{"valid", 1},
+		{"invalid", 1},
+		// failures of the api are not cached
+		{"unavailable", 2},
+	}
+
+	for i, test := range tests {
+		if reviews[test.token] != test.expected {
+			t.Errorf("case %d: expected %d reviews of %s, got %d", i, test.expected, test.token, reviews[test.token])
+		}
+	}
+
+	// rejected tokens expire before accepted ones
+	time.Sleep(failureTTL + 20*time.Millisecond)
+	authenticate("valid", "invalid")
+
+	if reviews["valid"] != 1 || reviews["invalid"] != 2 {
+		t.Errorf("expected only the rejected token to be reviewed again, got %v", reviews)
+	}
+
+	time.Sleep(successTTL)
+	authenticate("valid")
+
+	if reviews["valid"] != 2 {
+		t.Errorf("expected the accepted token to be reviewed again, got %v", reviews)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"k8s.io/apiserver/pkg/authentication/user"
 	"k8s.io/apiserver/pkg/authorization/authorizer"
 	"k8s.io/apiserver/pkg/endpoints/request"
 	"kubesphere.io/kubesphere/pkg/utils/k8sutil"
@@ -33,6 +34,7 @@ import (
 	k8serr "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"kubesphere.io/kubesphere/pkg/informers"
 	"kubesphere.io/kubesphere/pkg/utils/sliceutil"
 )
@@ -120,6 +122,78 @@ func permissionValidate(attrs authorizer.Attributes) (bool, error) {
 	return false, nil
 }
 
+// PermittedInAnyNamespace validates requests without a concrete namespace, such as api documents,
+// namespaced requests are permitted if the user is allowed to perform them in at least one namespace
+func PermittedInAnyNamespace(attrs authorizer.Attributes) (bool, error) {
+	return PermittedInNamespaces(attrs, func() ([]string, error) {
+		return UserNamespaces(attrs.GetUser())
+	})
+}
+
+// PermittedInNamespaces is the same as PermittedInAnyNamespace, namespaces of the user are only resolved
+// if cluster roles don't permit the request, callers validating many requests may reuse them
+func PermittedInNamespaces(attrs authorizer.Attributes, namespaces func() ([]string, error)) (bool, error) {
+
+	permitted, err := permissionValidate(attrs)
+
+	if err != nil || permitted || attrs.GetNamespace() != "" || !attrs.IsResourceRequest() {
+		return permitted, err
+	}
+
+	names, err := namespaces()
+
+	if err != nil {
+		return false, err
+	}
+
+	for _, namespace := range names {
+		record := authorizer.AttributesRecord{
+			User:            attrs.GetUser(),
+			Verb:            attrs.GetVerb(),
+			Namespace:       namespace,
+			APIGroup:        attrs.GetAPIGroup(),
+			APIVersion:      attrs.GetAPIVersion(),
+			Resource:        attrs.GetResource(),
+			Subresource:     attrs.GetSubresource(),
+			Name:            attrs.GetName(),
+			ResourceRequest: attrs.IsResourceRequest(),
+			Path:            attrs.GetPath(),
+		}
+
+		permitted, err := roleValidate(&record)
+
+		if err != nil {
+			return false, err
+		}
+
+		if permitted {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// UserNamespaces returns namespaces in which the user is bound to roles
+func UserNamespaces(usr user.Info) ([]string, error) {
+	roleBindingLister := informers.SharedInformerFactory().Rbac().V1().RoleBindings().Lister()
+	roleBindings, err := roleBindingLister.List(labels.Everything())
+
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces := sets.NewString()
+
+	for _, roleBinding := range roleBindings {
+		if k8sutil.ContainsIdentity(roleBinding.Subjects, usr) {
+			namespaces.Insert(roleBinding.Namespace)
+		}
+	}
+
+	return namespaces.List(), nil
+}
+
 func roleValidate(attrs authorizer.Attributes) (bool, error) {
 	roleBindingLister := informers.SharedInformerFactory().Rbac().V1().RoleBindings().Lister()
 	roleLister := informers.SharedInformerFactory().Rbac().V1().Roles().Lister()
@@ -136,7 +210,7 @@ func roleValidate(attrs authorizer.Attributes) (bool, error) {
 	}
 
 	for _, roleBinding := range roleBindings {
-		if k8sutil.ContainsUser(roleBinding.Subjects, attrs.GetUser().GetName()) {
+		if k8sutil.ContainsIdentity(roleBinding.Subjects, attrs.GetUser()) {
 			role, err := roleLister.Roles(attrs.GetNamespace()).Get(roleBinding.RoleRef.Name)
 
 			if err != nil {
@@ -167,7 +241,7 @@ func clusterRoleValidate(attrs authorizer.Attributes) (bool, error) {
 
 	for _, clusterRoleBinding := range clusterRoleBindings {
 
-		if k8sutil.ContainsUser(clusterRoleBinding.Subjects, attrs.GetUser().GetName()) {
+		if k8sutil.ContainsIdentity(clusterRoleBinding.Subjects, attrs.GetUser()) {
 			clusterRole, err := clusterRoleLister.Get(clusterRoleBinding.RoleRef.Name)
 
 			if err != nil {
@@ -0,0 +1,211 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package ratelimit
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-redis/redis"
+	"github.com/mholt/caddy"
+	"github.com/mholt/caddy/caddyhttp/httpserver"
+)
+
+// "ratelimit" is already reserved by caddy and ordered before authenticate,
+// so the username would not be available in the request context
+const directive = "throttle"
+
+func init() {
+	caddy.RegisterPlugin(directive, caddy.Plugin{
+		ServerType: "http",
+		Action:     Setup,
+	})
+}
+
+// Setup is called by Caddy to parse the config block
+func Setup(c *caddy.Controller) error {
+
+	rule, err := parse(c)
+
+	if err != nil {
+		return err
+	}
+
+	limiter := NewLocalLimiter()
+
+	if rule.RedisServer != "" {
+		client := redis.NewClient(&redis.Options{
+			Addr:     rule.RedisServer,
+			Password: rule.RedisPassword,
+			DB:       rule.RedisDB,
+		})
+
+		limiter = NewRedisLimiter(client)
+
+		c.OnShutdown(func() error {
+			return client.Close()
+		})
+	}
+
+	c.OnStartup(func() error {
+		fmt.Println("Throttle middleware is initiated")
+		return nil
+	})
+
+	httpserver.GetConfig(c).AddMiddleware(func(next httpserver.Handler) httpserver.Handler {
+		return &RateLimit{Next: next, Rule: rule, Limiter: limiter}
+	})
+
+	return nil
+}
+
+func parse(c *caddy.Controller) (Rule, error) {
+
+	rule := Rule{Path: "/", ExceptedPath: make([]string, 0), Routes: make([]RouteLimit, 0)}
+
+	if c.Next() {
+		args := c.RemainingArgs()
+		switch len(args) {
+		case 0:
+			for c.NextBlock() {
+				switch c.Val() {
+				case "path":
+					if !c.NextArg() {
+						return rule, c.ArgErr()
+					}
+
+					rule.Path = c.Val()
+
+					if c.NextArg() {
+						return rule, c.ArgErr()
+					}
+				case "except":
+					if !c.NextArg() {
+						return rule, c.ArgErr()
+					}
+
+					rule.ExceptedPath = strings.Split(c.Val(), ",")
+
+					for i := 0; i < len(rule.ExceptedPath); i++ {
+						rule.ExceptedPath[i] = strings.TrimSpace(rule.ExceptedPath[i])
+					}
+
+					if c.NextArg() {
+						return rule, c.ArgErr()
+					}
+				case "default":
+					args := c.RemainingArgs()
+
+					if len(args) != 2 {
+						return rule, c.ArgErr()
+					}
+
+					limit, err := parseLimit(args[0], args[1])
+
+					if err != nil {
+						return rule, c.Err(err.Error())
+					}
+
+					rule.Default = &limit
+				case "limit":
+					args := c.RemainingArgs()
+
+					if len(args) != 3 {
+						return rule, c.ArgErr()
+					}
+
+					limit, err := parseLimit(args[1], args[2])
+
+					if err != nil {
+						return rule, c.Err(err.Error())
+					}
+
+					rule.Routes = append(rule.Routes, RouteLimit{Prefix: args[0], Limit: limit})
+				case "redis":
+					if !c.NextArg() {
+						return rule, c.ArgErr()
+					}
+
+					rule.RedisServer = c.Val()
+
+					if c.NextArg() {
+						return rule, c.ArgErr()
+					}
+				case "redis_password":
+					if !c.NextArg() {
+						return rule, c.ArgErr()
+					}
+
+					rule.RedisPassword = c.Val()
+
+					if c.NextArg() {
+						return rule, c.ArgErr()
+					}
+				case "redis_db":
+					if !c.NextArg() {
+						return rule, c.ArgErr()
+					}
+
+					db, err := strconv.Atoi(c.Val())
+
+					if err != nil {
+						return rule, c.Err(err.Error())
+					}
+
+					rule.RedisDB = db
+
+					if c.NextArg() {
+						return rule, c.ArgErr()
+					}
+				default:
+					return rule, c.ArgErr()
+				}
+			}
+		default:
+			return rule, c.ArgErr()
+		}
+	}
+
+	if c.Next() {
+		return rule, c.ArgErr()
+	}
+
+	sort.SliceStable(rule.Routes, func(i, j int) bool {
+		return len(rule.Routes[i].Prefix) > len(rule.Routes[j].Prefix)
+	})
+
+	return rule, nil
+}
+
+func parseLimit(rate, burst string) (Limit, error) {
+	r, err := strconv.ParseFloat(rate, 64)
+
+	if err != nil || r <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate %s, expect a positive number of requests per second", rate)
+	}
+
+	b, err := strconv.Atoi(burst)
+
+	if err != nil || b < 1 {
+		return Limit{}, fmt.Errorf("invalid burst %s, expect a positive integer", burst)
+	}
+
+	return Limit{Rate: r, Burst: b}, nil
+}
@@ -0,0 +1,176 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+const (
+	keyPrefix = "kubesphere:ratelimit:"
+	// idle buckets are evicted once the local limiter tracks more keys than this
+	maxLocalBuckets = 10000
+)
+
+type Limiter interface {
+	// Allow takes a token from the bucket identified by key,
+	// when the bucket is empty it returns how long the caller should wait before retrying
+	Allow(key string, limit Limit) (bool, time.Duration, error)
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	// limit of the route the bucket belongs to, routes are limited at different rates
+	limit Limit
+}
+
+func (b *bucket) refill(now time.Time) float64 {
+	return math.Min(float64(b.limit.Burst), b.tokens+now.Sub(b.last).Seconds()*b.limit.Rate)
+}
+
+// localLimiter keeps token buckets in memory, counters are not shared between gateway replicas
+type localLimiter struct {
+	mutex   sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+func NewLocalLimiter() Limiter {
+	return &localLimiter{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+func (l *localLimiter) Allow(key string, limit Limit) (bool, time.Duration, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+
+	b, ok := l.buckets[key]
+
+	if !ok {
+		if len(l.buckets) >= maxLocalBuckets {
+			l.evict(now)
+		}
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		l.buckets[key] = b
+	}
+
+	b.limit = limit
+	b.tokens = b.refill(now)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0, nil
+	}
+
+	wait := time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second))
+
+	return false, wait, nil
+}
+
+// evict removes buckets which would have been refilled completely by now, forgetting them changes nothing.
+// Then the least recently used ones are removed until a tenth of the capacity is free, eg. with requests
+// from many addresses, so eviction doesn't scan all buckets on every new key.
+func (l *localLimiter) evict(now time.Time) {
+	for key, b := range l.buckets {
+		if b.refill(now) >= float64(b.limit.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+
+	if len(l.buckets) <= maxLocalBuckets*9/10 {
+		return
+	}
+
+	keys := make([]string, 0, len(l.buckets))
+	for key := range l.buckets {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return l.buckets[keys[i]].last.Before(l.buckets[keys[j]].last)
+	})
+
+	for _, key := range keys[:len(keys)-maxLocalBuckets*9/10] {
+		delete(l.buckets, key)
+	}
+}
+
+// tokenBucketScript refills and takes a token atomically, timestamps are in milliseconds
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local state = redis.call("HMGET", KEYS[1], "tokens", "timestamp")
+local tokens = tonumber(state[1])
+local timestamp = tonumber(state[2])
+if tokens == nil or timestamp == nil then
+  tokens = burst
+  timestamp = now
+end
+tokens = math.min(burst, tokens + math.max(0, now - timestamp) * rate / 1000)
+local allowed = 0
+local wait = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+else
+  wait = math.ceil((1 - tokens) * 1000 / rate)
+end
+redis.call("HMSET", KEYS[1], "tokens", tostring(tokens), "timestamp", now)
+redis.call("PEXPIRE", KEYS[1], math.ceil(burst * 1000 / rate) + 1000)
+return {allowed, wait}
+`)
+
+// redisLimiter shares token buckets between gateway replicas
+type redisLimiter struct {
+	client *redis.Client
+}
+
+func NewRedisLimiter(client *redis.Client) Limiter {
+	return &redisLimiter{client: client}
+}
+
+func (l *redisLimiter) Allow(key string, limit Limit) (bool, time.Duration, error) {
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+
+	result, err := tokenBucketScript.Run(l.client, []string{keyPrefix + key}, limit.Rate, limit.Burst, now).Result()
+
+	if err != nil {
+		return false, 0, err
+	}
+
+	values, ok := result.([]interface{})
+
+	if !ok || len(values) != 2 {
+		return false, 0, fmt.Errorf("unexpected rate limit script result %v", result)
+	}
+
+	allowed, _ := values[0].(int64)
+	wait, _ := values[1].(int64)
+
+	return allowed == 1, time.Duration(wait) * time.Millisecond, nil
+}
@@ -0,0 +1,136 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package ratelimit
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func newFakeLimiter() (*localLimiter, *fakeClock) {
+	clock := &fakeClock{now: time.Unix(1561939200, 0)}
+	limiter := NewLocalLimiter().(*localLimiter)
+	limiter.now = clock.Now
+	return limiter, clock
+}
+
+func TestLocalLimiterRefill(t *testing.T) {
+	limiter, clock := newFakeLimiter()
+	limit := Limit{Rate: 2, Burst: 3}
+
+	tests := []struct {
+		advance time.Duration
+		allowed bool
+		wait    time.Duration
+	}{
+		{allowed: true},
+		{allowed: true},
+		{allowed: true},
+		{allowed: false, wait: 500 * time.Millisecond},
+		{advance: 250 * time.Millisecond, allowed: false, wait: 250 * time.Millisecond},
+		{advance: 250 * time.Millisecond, allowed: true},
+		{allowed: false, wait: 500 * time.Millisecond},
+		// refilled up to the burst only
+		{advance: time.Hour, allowed: true},
+		{allowed: true},
+		{allowed: true},
+		{allowed: false, wait: 500 * time.Millisecond},
+	}
+
+	for i, test := range tests {
+		clock.now = clock.now.Add(test.advance)
+
+		allowed, wait, err := limiter.Allow("user:admin:/", limit)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+		if allowed != test.allowed || wait != test.wait {
+			t.Errorf("case %d: expected %t %s, got %t %s", i, test.allowed, test.wait, allowed, wait)
+		}
+	}
+}
+
+func TestLocalLimiterKeys(t *testing.T) {
+	limiter, _ := newFakeLimiter()
+	limit := Limit{Rate: 1, Burst: 1}
+
+	if allowed, _, _ := limiter.Allow("user:admin:/kapis", limit); !allowed {
+		t.Errorf("expected the first request of admin to be allowed")
+	}
+	if allowed, _, _ := limiter.Allow("user:admin:/kapis", limit); allowed {
+		t.Errorf("expected the second request of admin to be limited")
+	}
+	if allowed, _, _ := limiter.Allow("user:guest:/kapis", limit); !allowed {
+		t.Errorf("expected buckets of users to be independent")
+	}
+	if allowed, _, _ := limiter.Allow("user:admin:/apis", limit); !allowed {
+		t.Errorf("expected buckets of routes to be independent")
+	}
+}
+
+func TestLocalLimiterEvict(t *testing.T) {
+	limiter, clock := newFakeLimiter()
+	slow := Limit{Rate: 0.001, Burst: 1}
+	fast := Limit{Rate: 1000, Burst: 1}
+
+	// buckets of the fast route are refilled in a millisecond, the ones of the slow route in 1000s
+	for i := 0; i < maxLocalBuckets/2; i++ {
+		clock.now = clock.now.Add(time.Millisecond)
+		limiter.Allow(fmt.Sprintf("ip:%d:/slow", i), slow)
+		limiter.Allow(fmt.Sprintf("ip:%d:/fast", i), fast)
+	}
+
+	clock.now = clock.now.Add(time.Second)
+	limiter.Allow("ip:new:/fast", fast)
+
+	if len(limiter.buckets) != maxLocalBuckets/2+1 {
+		t.Errorf("expected refilled buckets of the fast route to be evicted, %d buckets are left", len(limiter.buckets))
+	}
+	if allowed, _, _ := limiter.Allow("ip:0:/slow", slow); allowed {
+		t.Errorf("expected buckets in use not to be evicted")
+	}
+
+	// no bucket is refilled, the least recently used ones are evicted
+	for i := maxLocalBuckets / 2; len(limiter.buckets) < maxLocalBuckets; i++ {
+		clock.now = clock.now.Add(time.Millisecond)
+		limiter.Allow(fmt.Sprintf("ip:%d:/slow", i), slow)
+	}
+
+	clock.now = clock.now.Add(time.Millisecond)
+	limiter.Allow("ip:latest:/slow", slow)
+
+	if len(limiter.buckets) > maxLocalBuckets*9/10+1 {
+		t.Errorf("expected buckets to be evicted down to 90%% of the capacity, %d buckets are left", len(limiter.buckets))
+	}
+	if _, ok := limiter.buckets["ip:1:/slow"]; ok {
+		t.Errorf("expected the least recently used bucket to be evicted")
+	}
+	if _, ok := limiter.buckets["ip:latest:/slow"]; !ok {
+		t.Errorf("expected the new bucket to be kept")
+	}
+}
@@ -0,0 +1,125 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package ratelimit
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/mholt/caddy/caddyhttp/httpserver"
+	"k8s.io/apiserver/pkg/endpoints/request"
+)
+
+type RateLimit struct {
+	Rule    Rule
+	Limiter Limiter
+	Next    httpserver.Handler
+}
+
+type Rule struct {
+	Path         string
+	ExceptedPath []string
+	// Default applies to requests under Path not matched by any route limit
+	Default *Limit
+	// Routes are sorted by prefix length, the longest matching prefix wins
+	Routes        []RouteLimit
+	RedisServer   string
+	RedisPassword string
+	RedisDB       int
+}
+
+type RouteLimit struct {
+	Prefix string
+	Limit  Limit
+}
+
+// Limit is a token bucket refilled at Rate tokens per second, holding at most Burst tokens
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+func (h RateLimit) ServeHTTP(resp http.ResponseWriter, req *http.Request) (int, error) {
+
+	if !httpserver.Path(req.URL.Path).Matches(h.Rule.Path) {
+		return h.Next.ServeHTTP(resp, req)
+	}
+
+	for _, path := range h.Rule.ExceptedPath {
+		if httpserver.Path(req.URL.Path).Matches(path) {
+			return h.Next.ServeHTTP(resp, req)
+		}
+	}
+
+	prefix, limit := h.Rule.match(req.URL.Path)
+
+	if limit == nil {
+		return h.Next.ServeHTTP(resp, req)
+	}
+
+	key := fmt.Sprintf("%s:%s", clientIdentity(req), prefix)
+
+	allowed, retryAfter, err := h.Limiter.Allow(key, *limit)
+
+	// rate limiting must not make the gateway unavailable, fail open
+	if err != nil {
+		log.Println("rate limiter error", err)
+		return h.Next.ServeHTTP(resp, req)
+	}
+
+	if !allowed {
+		return h.HandleTooManyRequests(resp, key, retryAfter.Seconds()), nil
+	}
+
+	return h.Next.ServeHTTP(resp, req)
+}
+
+func (h RateLimit) HandleTooManyRequests(w http.ResponseWriter, key string, retryAfter float64) int {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter))))
+	log.Println("Too Many Requests,", key)
+	return http.StatusTooManyRequests
+}
+
+func (r Rule) match(path string) (string, *Limit) {
+	for _, route := range r.Routes {
+		if httpserver.Path(path).Matches(route.Prefix) {
+			return route.Prefix, &route.Limit
+		}
+	}
+	return r.Path, r.Default
+}
+
+// clientIdentity returns the authenticated username injected by the authenticate plugin,
+// anonymous requests are identified by remote address
+func clientIdentity(req *http.Request) string {
+	if usr, ok := request.UserFrom(req.Context()); ok && usr.GetName() != "" {
+		return "user:" + usr.GetName()
+	}
+
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+
+	if err != nil {
+		host = req.RemoteAddr
+	}
+
+	return "ip:" + host
+}
@@ -0,0 +1,117 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/request"
+)
+
+type recordLimiter struct {
+	keys    []string
+	limits  []Limit
+	allowed bool
+	wait    time.Duration
+}
+
+func (l *recordLimiter) Allow(key string, limit Limit) (bool, time.Duration, error) {
+	l.keys = append(l.keys, key)
+	l.limits = append(l.limits, limit)
+	return l.allowed, l.wait, nil
+}
+
+type nextHandler struct{}
+
+func (nextHandler) ServeHTTP(http.ResponseWriter, *http.Request) (int, error) {
+	return http.StatusOK, nil
+}
+
+func TestServeHTTP(t *testing.T) {
+	rule := Rule{
+		Path:         "/",
+		ExceptedPath: []string{"/kapis/iam.kubesphere.io/v1alpha2/login"},
+		Default:      &Limit{Rate: 10, Burst: 20},
+		Routes: []RouteLimit{
+			{Prefix: "/kapis/logging.kubesphere.io", Limit: Limit{Rate: 1, Burst: 2}},
+			{Prefix: "/kapis", Limit: Limit{Rate: 5, Burst: 10}},
+		},
+	}
+
+	tests := []struct {
+		path       string
+		username   string
+		remoteAddr string
+		key        string
+		limit      *Limit
+	}{
+		{path: "/kapis/logging.kubesphere.io/v1alpha2/cluster", username: "admin", key: "user:admin:/kapis/logging.kubesphere.io", limit: &Limit{Rate: 1, Burst: 2}},
+		{path: "/kapis/tenant.kubesphere.io/v1alpha2/workspaces", username: "admin", key: "user:admin:/kapis", limit: &Limit{Rate: 5, Burst: 10}},
+		{path: "/api/v1/namespaces", remoteAddr: "192.168.0.1:34567", key: "ip:192.168.0.1:/", limit: &Limit{Rate: 10, Burst: 20}},
+		{path: "/kapis/iam.kubesphere.io/v1alpha2/login", remoteAddr: "192.168.0.1:34567"},
+	}
+
+	for i, test := range tests {
+		limiter := &recordLimiter{allowed: true}
+		handler := RateLimit{Rule: rule, Limiter: limiter, Next: nextHandler{}}
+
+		req := httptest.NewRequest(http.MethodGet, test.path, nil)
+		req.RemoteAddr = test.remoteAddr
+		if test.username != "" {
+			req = req.WithContext(request.WithUser(req.Context(), &user.DefaultInfo{Name: test.username}))
+		}
+
+		status, err := handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		if err != nil || status != http.StatusOK {
+			t.Errorf("case %d: expected %d, got %d %v", i, http.StatusOK, status, err)
+		}
+
+		if test.limit == nil {
+			if len(limiter.keys) != 0 {
+				t.Errorf("case %d: expected no limit, got %v", i, limiter.keys)
+			}
+			continue
+		}
+
+		if len(limiter.keys) != 1 || limiter.keys[0] != test.key || limiter.limits[0] != *test.limit {
+			t.Errorf("case %d: expected %s %v, got %v %v", i, test.key, *test.limit, limiter.keys, limiter.limits)
+		}
+	}
+}
+
+func TestServeHTTPTooManyRequests(t *testing.T) {
+	limiter := &recordLimiter{allowed: false, wait: 1200 * time.Millisecond}
+	handler := RateLimit{Rule: Rule{Path: "/", Default: &Limit{Rate: 1, Burst: 1}}, Limiter: limiter, Next: nextHandler{}}
+
+	resp := httptest.NewRecorder()
+	status, err := handler.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/kapis", nil))
+
+	if err != nil || status != http.StatusTooManyRequests {
+		t.Errorf("expected %d, got %d %v", http.StatusTooManyRequests, status, err)
+	}
+
+	// rounded up to whole seconds
+	if retryAfter := resp.Header().Get("Retry-After"); retryAfter != "2" {
+		t.Errorf("expected Retry-After 2, got %q", retryAfter)
+	}
+}
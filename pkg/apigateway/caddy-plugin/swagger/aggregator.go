@@ -0,0 +1,277 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package authenticate
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-openapi/spec"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	"k8s.io/apiserver/pkg/endpoints/request"
+
+	"kubesphere.io/kubesphere/pkg/apigateway/caddy-plugin/authentication"
+)
+
+var requestInfoFactory = request.RequestInfoFactory{
+	APIPrefixes:          sets.NewString("api", "apis", "kapis", "kapi"),
+	GrouplessAPIPrefixes: sets.NewString("api")}
+
+// Spec is the live swagger 2.0 spec served by a backend, such as ks-apiserver and ks-iam
+type Spec struct {
+	Name string
+	URL  string
+}
+
+type aggregator struct {
+	specs    []Spec
+	client   *http.Client
+	mutex    sync.RWMutex
+	cached   map[string]*spec.Swagger
+	document *OpenAPI
+}
+
+func newAggregator(specs []Spec) *aggregator {
+	return &aggregator{
+		specs:  specs,
+		client: &http.Client{Timeout: 30 * time.Second},
+		cached: make(map[string]*spec.Swagger),
+	}
+}
+
+// Run refreshes specs of backends periodically until stopCh is closed
+func (a *aggregator) Run(interval time.Duration, stopCh <-chan struct{}) {
+	a.refresh()
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				a.refresh()
+			case <-stopCh:
+				return
+			}
+		}
+	}()
+}
+
+// refresh keeps the last fetched spec of a backend if it is unavailable
+func (a *aggregator) refresh() {
+	for _, s := range a.specs {
+		swagger, err := a.fetch(s.URL)
+		if err != nil {
+			log.Printf("failed to fetch api spec of %s: %v", s.Name, err)
+			continue
+		}
+		a.mutex.Lock()
+		a.cached[s.Name] = swagger
+		a.mutex.Unlock()
+	}
+
+	document := newOpenAPI()
+
+	a.mutex.RLock()
+	// backends are merged in the configured order, the first one wins on conflicts
+	for _, s := range a.specs {
+		if swagger, ok := a.cached[s.Name]; ok {
+			document.merge(swagger)
+		}
+	}
+	a.mutex.RUnlock()
+
+	a.mutex.Lock()
+	a.document = document
+	a.mutex.Unlock()
+}
+
+func (a *aggregator) fetch(url string) (*spec.Swagger, error) {
+	resp, err := a.client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	swagger := &spec.Swagger{}
+
+	if err := json.NewDecoder(resp.Body).Decode(swagger); err != nil {
+		return nil, err
+	}
+
+	return swagger, nil
+}
+
+// ServeHTTP writes the aggregated document, operations the user is not authorized to perform are removed
+func (a *aggregator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.mutex.RLock()
+	document := a.document
+	a.mutex.RUnlock()
+
+	if document == nil {
+		http.Error(w, "api documents are not ready", http.StatusServiceUnavailable)
+		return
+	}
+
+	// anonymous requests get the full document, it is the same as the published one
+	if usr, ok := request.UserFrom(r.Context()); ok {
+		filtered, err := filter(document, usr)
+		if err != nil {
+			log.Println("failed to filter api document", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		document = filtered
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(document); err != nil {
+		log.Println(err)
+	}
+}
+
+// permittedInNamespaces and userNamespaces are replaced in tests
+var (
+	permittedInNamespaces = authentication.PermittedInNamespaces
+	userNamespaces        = authentication.UserNamespaces
+)
+
+// operationFilter authorizes operations of a single document request, namespaces of the user are
+// resolved at most once and operations resolving to the same attributes share the decision
+type operationFilter struct {
+	user          user.Info
+	resolved      bool
+	namespaces    []string
+	namespacesErr error
+	decisions     map[string]bool
+}
+
+func newOperationFilter(usr user.Info) *operationFilter {
+	return &operationFilter{user: usr, decisions: make(map[string]bool)}
+}
+
+func (f *operationFilter) userNamespaces() ([]string, error) {
+	if !f.resolved {
+		f.namespaces, f.namespacesErr = userNamespaces(f.user)
+		f.resolved = true
+	}
+	return f.namespaces, f.namespacesErr
+}
+
+func filter(document *OpenAPI, usr user.Info) (*OpenAPI, error) {
+	filtered := *document
+	filtered.Paths = make(map[string]PathItem)
+
+	f := newOperationFilter(usr)
+
+	for path, item := range document.Paths {
+		pathItem := make(PathItem)
+		for method, operation := range item {
+			permitted, err := f.permitted(method, path)
+			if err != nil {
+				return nil, err
+			}
+			if permitted {
+				pathItem[method] = operation
+			}
+		}
+		if len(pathItem) > 0 {
+			filtered.Paths[path] = pathItem
+		}
+	}
+
+	return &filtered, nil
+}
+
+// permitted resolves the path template to request attributes, path parameters are treated as wildcards
+func (f *operationFilter) permitted(method, path string) (bool, error) {
+	req, err := http.NewRequest(strings.ToUpper(method), path, nil)
+
+	if err != nil {
+		return false, err
+	}
+
+	info, err := requestInfoFactory.NewRequestInfo(req)
+
+	if err != nil {
+		return false, err
+	}
+
+	// the permission depends on the value of the parameter, such as /namespaces/{namespace}/{resources}
+	if isParameter(info.Resource) || isParameter(info.Subresource) {
+		return true, nil
+	}
+
+	attrs := &authorizer.AttributesRecord{
+		User:            f.user,
+		Verb:            info.Verb,
+		APIGroup:        info.APIGroup,
+		APIVersion:      info.APIVersion,
+		Resource:        info.Resource,
+		Subresource:     info.Subresource,
+		ResourceRequest: info.IsResourceRequest,
+		Path:            info.Path,
+	}
+
+	if !isParameter(info.Namespace) {
+		attrs.Namespace = info.Namespace
+	}
+
+	if !isParameter(info.Name) {
+		attrs.Name = info.Name
+	}
+
+	key := decisionKey(attrs)
+
+	if permitted, ok := f.decisions[key]; ok {
+		return permitted, nil
+	}
+
+	permitted, err := permittedInNamespaces(attrs, f.userNamespaces)
+
+	if err != nil {
+		return false, err
+	}
+
+	f.decisions[key] = permitted
+
+	return permitted, nil
+}
+
+// decisionKey identifies attributes the authorizer depends on, the path only matters for non-resource requests
+func decisionKey(attrs *authorizer.AttributesRecord) string {
+	if !attrs.ResourceRequest {
+		return strings.Join([]string{attrs.Verb, attrs.Path}, " ")
+	}
+	return strings.Join([]string{attrs.Verb, attrs.APIGroup, attrs.Resource, attrs.Subresource, attrs.Namespace, attrs.Name}, " ")
+}
+
+func isParameter(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
@@ -0,0 +1,223 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package authenticate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	"k8s.io/apiserver/pkg/endpoints/request"
+)
+
+// fakePermission permits resources in the namespaces of the user and getting non-resource paths in the list
+type fakePermission struct {
+	namespaces      map[string][]string
+	resources       map[string]string
+	paths           []string
+	namespaceCalls  int
+	permissionCalls int
+}
+
+func (p *fakePermission) userNamespaces(usr user.Info) ([]string, error) {
+	p.namespaceCalls++
+	return p.namespaces[usr.GetName()], nil
+}
+
+func (p *fakePermission) permitted(attrs authorizer.Attributes, namespaces func() ([]string, error)) (bool, error) {
+	p.permissionCalls++
+
+	if !attrs.IsResourceRequest() {
+		if attrs.GetVerb() != "get" {
+			return false, nil
+		}
+		for _, path := range p.paths {
+			if path == attrs.GetPath() {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	names, err := namespaces()
+	if err != nil {
+		return false, err
+	}
+
+	for _, namespace := range names {
+		if p.resources[namespace] == attrs.GetResource() && (attrs.GetNamespace() == "" || attrs.GetNamespace() == namespace) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (p *fakePermission) install(t *testing.T) {
+	permitted, namespaces := permittedInNamespaces, userNamespaces
+	permittedInNamespaces, userNamespaces = p.permitted, p.userNamespaces
+	t.Cleanup(func() {
+		permittedInNamespaces, userNamespaces = permitted, namespaces
+	})
+}
+
+func testDocument() *OpenAPI {
+	document := newOpenAPI()
+	for _, path := range []string{
+		"/api/v1/namespaces/{namespace}/pods",
+		"/api/v1/namespaces/{namespace}/pods/{name}",
+		"/api/v1/namespaces/{namespace}/secrets",
+		"/api/v1/namespaces/{namespace}/{resources}",
+		"/kapis/resources.kubesphere.io/v1alpha2/registry/blob",
+		"/version",
+	} {
+		document.Paths[path] = PathItem{
+			"get":    &Operation{OperationID: "get " + path},
+			"delete": &Operation{OperationID: "delete " + path},
+		}
+	}
+	return document
+}
+
+func documentOperations(document *OpenAPI) []string {
+	var operations []string
+	for path, item := range document.Paths {
+		for method := range item {
+			operations = append(operations, method+" "+path)
+		}
+	}
+	sort.Strings(operations)
+	return operations
+}
+
+func TestFilter(t *testing.T) {
+	permission := &fakePermission{
+		namespaces: map[string][]string{"alice": {"demo", "test"}},
+		resources:  map[string]string{"demo": "pods"},
+		paths:      []string{"/version"},
+	}
+	permission.install(t)
+
+	filtered, err := filter(testDocument(), &user.DefaultInfo{Name: "alice"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"delete /api/v1/namespaces/{namespace}/pods",
+		"delete /api/v1/namespaces/{namespace}/pods/{name}",
+		"delete /api/v1/namespaces/{namespace}/{resources}",
+		"get /api/v1/namespaces/{namespace}/pods",
+		"get /api/v1/namespaces/{namespace}/pods/{name}",
+		"get /api/v1/namespaces/{namespace}/{resources}",
+		"get /version",
+	}
+
+	if operations := documentOperations(filtered); !reflect.DeepEqual(operations, expected) {
+		t.Errorf("expected %v, got %v", expected, operations)
+	}
+
+	if permission.namespaceCalls != 1 {
+		t.Errorf("expected namespaces to be resolved once, got %d", permission.namespaceCalls)
+	}
+
+	// every operation except {resources} is validated once, the namespaces are shared
+	if permission.permissionCalls != 10 {
+		t.Errorf("expected 10 permission checks, got %d", permission.permissionCalls)
+	}
+}
+
+func TestDecisionReused(t *testing.T) {
+	permission := &fakePermission{
+		namespaces: map[string][]string{"alice": {"demo"}},
+		resources:  map[string]string{"demo": "pods"},
+	}
+	permission.install(t)
+
+	document := newOpenAPI()
+	// both resolve to listing pods in any namespace
+	document.Paths["/api/v1/namespaces/{namespace}/pods"] = PathItem{"get": &Operation{}}
+	document.Paths["/api/v1/namespaces/{ns}/pods"] = PathItem{"get": &Operation{}}
+
+	filtered, err := filter(document, &user.DefaultInfo{Name: "alice"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(filtered.Paths) != 2 {
+		t.Errorf("expected 2 paths, got %v", documentOperations(filtered))
+	}
+
+	if permission.permissionCalls != 1 {
+		t.Errorf("expected the decision to be reused, got %d checks", permission.permissionCalls)
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	permission := &fakePermission{
+		namespaces: map[string][]string{"alice": {"demo"}},
+		resources:  map[string]string{"demo": "secrets"},
+	}
+	permission.install(t)
+
+	tests := []struct {
+		user     user.Info
+		expected int
+	}{
+		// anonymous requests get the published document
+		{nil, 12},
+		{&user.DefaultInfo{Name: "alice"}, 4},
+		{&user.DefaultInfo{Name: "bob"}, 2},
+	}
+
+	a := newAggregator(nil)
+	a.document = testDocument()
+
+	for i, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/swagger-ui/api.json", nil)
+		if test.user != nil {
+			req = req.WithContext(request.WithUser(context.Background(), test.user))
+		}
+		recorder := httptest.NewRecorder()
+
+		a.ServeHTTP(recorder, req)
+
+		if recorder.Code != http.StatusOK {
+			t.Errorf("case %d: expected status 200, got %d", i, recorder.Code)
+			continue
+		}
+
+		var document OpenAPI
+		if err := json.Unmarshal(recorder.Body.Bytes(), &document); err != nil {
+			t.Errorf("case %d: %v", i, err)
+			continue
+		}
+
+		if operations := documentOperations(&document); len(operations) != test.expected {
+			t.Errorf("case %d: expected %d operations, got %v", i, test.expected, operations)
+		}
+	}
+}
@@ -20,9 +20,13 @@ package authenticate
 import (
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/mholt/caddy"
 	"github.com/mholt/caddy/caddyhttp/httpserver"
+
+	"kubesphere.io/kubesphere/pkg/informers"
 )
 
 func init() {
@@ -40,11 +44,35 @@ func Setup(c *caddy.Controller) error {
 		return err
 	}
 
+	stopChan := make(chan struct{}, 0)
+
+	if len(handler.Specs) > 0 {
+		aggregator := newAggregator(handler.Specs)
+		handler.Aggregator = aggregator
+
+		c.OnStartup(func() error {
+			informerFactory := informers.SharedInformerFactory()
+			informerFactory.Rbac().V1().Roles().Lister()
+			informerFactory.Rbac().V1().RoleBindings().Lister()
+			informerFactory.Rbac().V1().ClusterRoles().Lister()
+			informerFactory.Rbac().V1().ClusterRoleBindings().Lister()
+			informerFactory.Start(stopChan)
+			informerFactory.WaitForCacheSync(stopChan)
+			aggregator.Run(handler.RefreshInterval, stopChan)
+			return nil
+		})
+	}
+
 	c.OnStartup(func() error {
 		fmt.Println("Swagger middleware is initiated")
 		return nil
 	})
 
+	c.OnShutdown(func() error {
+		close(stopChan)
+		return nil
+	})
+
 	httpserver.GetConfig(c).AddMiddleware(func(next httpserver.Handler) httpserver.Handler {
 		return &Swagger{Next: next, Handler: handler}
 	})
@@ -53,7 +81,7 @@ func Setup(c *caddy.Controller) error {
 }
 func parse(c *caddy.Controller) (Handler, error) {
 
-	handler := Handler{URL: "/swagger-ui", FilePath: "/var/static/swagger-ui"}
+	handler := Handler{URL: "/swagger-ui", FilePath: "/var/static/swagger-ui", Specs: make([]Spec, 0), RefreshInterval: 10 * time.Minute}
 
 	if c.Next() {
 		args := c.RemainingArgs()
@@ -78,6 +106,30 @@ func parse(c *caddy.Controller) (Handler, error) {
 
 					handler.FilePath = c.Val()
 
+					if c.NextArg() {
+						return handler, c.ArgErr()
+					}
+				case "spec":
+					args := c.RemainingArgs()
+
+					if len(args) != 2 {
+						return handler, c.ArgErr()
+					}
+
+					handler.Specs = append(handler.Specs, Spec{Name: args[0], URL: args[1]})
+				case "refresh":
+					if !c.NextArg() {
+						return handler, c.ArgErr()
+					}
+
+					interval, err := time.ParseDuration(c.Val())
+
+					if err != nil {
+						return handler, c.Err(err.Error())
+					}
+
+					handler.RefreshInterval = interval
+
 					if c.NextArg() {
 						return handler, c.ArgErr()
 					}
@@ -94,6 +146,8 @@ func parse(c *caddy.Controller) (Handler, error) {
 		return handler, c.ArgErr()
 	}
 
+	handler.SpecPath = strings.TrimRight(handler.URL, "/") + "/api.json"
+
 	handler.Handler = http.StripPrefix(handler.URL, http.FileServer(http.Dir(handler.FilePath)))
 
 	return handler, nil
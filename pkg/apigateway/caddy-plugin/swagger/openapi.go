@@ -0,0 +1,358 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package authenticate
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/go-openapi/spec"
+)
+
+// OpenAPI is the subset of the OpenAPI 3.0 document converted from swagger 2.0 specs of backends
+type OpenAPI struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       *spec.Info            `json:"info,omitempty"`
+	Tags       []spec.Tag            `json:"tags,omitempty"`
+	Paths      map[string]PathItem   `json:"paths"`
+	Components Components            `json:"components"`
+	Security   []map[string][]string `json:"security,omitempty"`
+}
+
+// PathItem maps lower case http methods to operations
+type PathItem map[string]*Operation
+
+type Operation struct {
+	Tags        []string            `json:"tags,omitempty"`
+	Summary     string              `json:"summary,omitempty"`
+	Description string              `json:"description,omitempty"`
+	OperationID string              `json:"operationId,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+	Deprecated  bool                `json:"deprecated,omitempty"`
+}
+
+type Parameter struct {
+	Name        string       `json:"name"`
+	In          string       `json:"in"`
+	Description string       `json:"description,omitempty"`
+	Required    bool         `json:"required,omitempty"`
+	Schema      *spec.Schema `json:"schema,omitempty"`
+}
+
+type RequestBody struct {
+	Description string               `json:"description,omitempty"`
+	Required    bool                 `json:"required,omitempty"`
+	Content     map[string]MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *spec.Schema `json:"schema,omitempty"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]spec.Schema    `json:"schemas,omitempty"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+	In   string `json:"in,omitempty"`
+}
+
+func newOpenAPI() *OpenAPI {
+	return &OpenAPI{
+		OpenAPI: "3.0.0",
+		Info: &spec.Info{
+			InfoProps: spec.InfoProps{
+				Title:       "KubeSphere",
+				Description: "KubeSphere OpenAPI",
+				License: &spec.License{
+					Name: "Apache",
+					URL:  "http://www.apache.org/licenses/",
+				},
+			},
+		},
+		Paths: make(map[string]PathItem),
+		Components: Components{
+			Schemas: make(map[string]spec.Schema),
+			SecuritySchemes: map[string]SecurityScheme{
+				"jwt": {Type: "apiKey", Name: "Authorization", In: "header"},
+			},
+		},
+		Security: []map[string][]string{{"jwt": []string{}}},
+	}
+}
+
+// merge converts the swagger 2.0 spec and merges it into the document,
+// paths and definitions which already exist are kept
+func (o *OpenAPI) merge(swagger *spec.Swagger) {
+
+	if swagger.Paths != nil {
+		for path, item := range swagger.Paths.Paths {
+			pathItem, ok := o.Paths[path]
+			if !ok {
+				pathItem = make(PathItem)
+				o.Paths[path] = pathItem
+			}
+			for method, operation := range operationsOf(item) {
+				if _, ok := pathItem[method]; !ok {
+					pathItem[method] = convertOperation(operation, swagger.Consumes, swagger.Produces)
+				}
+			}
+		}
+	}
+
+	for name, schema := range swagger.Definitions {
+		if _, ok := o.Components.Schemas[name]; !ok {
+			o.Components.Schemas[name] = convertSchema(schema)
+		}
+	}
+
+	for _, tag := range swagger.Tags {
+		if !o.hasTag(tag.Name) {
+			o.Tags = append(o.Tags, tag)
+		}
+	}
+}
+
+func (o *OpenAPI) hasTag(name string) bool {
+	for _, tag := range o.Tags {
+		if tag.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	definitionsPrefix = "#/definitions/"
+	schemasPrefix     = "#/components/schemas/"
+)
+
+// convertSchema returns a copy of the swagger 2.0 schema with references to definitions
+// rewritten to the OpenAPI 3.0 components, nested schemas are converted recursively
+func convertSchema(schema spec.Schema) spec.Schema {
+	if ref := schema.Ref.String(); strings.HasPrefix(ref, definitionsPrefix) {
+		if converted, err := spec.NewRef(schemasPrefix + strings.TrimPrefix(ref, definitionsPrefix)); err == nil {
+			schema.Ref = converted
+		}
+	}
+
+	schema.Items = convertSchemaOrArray(schema.Items)
+	schema.AllOf = convertSchemaList(schema.AllOf)
+	schema.OneOf = convertSchemaList(schema.OneOf)
+	schema.AnyOf = convertSchemaList(schema.AnyOf)
+	schema.Not = convertSchemaRef(schema.Not)
+	schema.Properties = convertSchemaMap(schema.Properties)
+	schema.AdditionalProperties = convertSchemaOrBool(schema.AdditionalProperties)
+	schema.PatternProperties = convertSchemaMap(schema.PatternProperties)
+	schema.AdditionalItems = convertSchemaOrBool(schema.AdditionalItems)
+	schema.Definitions = convertSchemaMap(schema.Definitions)
+
+	if schema.Dependencies != nil {
+		dependencies := make(spec.Dependencies, len(schema.Dependencies))
+		for name, dependency := range schema.Dependencies {
+			dependency.Schema = convertSchemaRef(dependency.Schema)
+			dependencies[name] = dependency
+		}
+		schema.Dependencies = dependencies
+	}
+
+	return schema
+}
+
+func convertSchemaRef(schema *spec.Schema) *spec.Schema {
+	if schema == nil {
+		return nil
+	}
+	converted := convertSchema(*schema)
+	return &converted
+}
+
+func convertSchemaList(schemas []spec.Schema) []spec.Schema {
+	if schemas == nil {
+		return nil
+	}
+	converted := make([]spec.Schema, len(schemas))
+	for i, schema := range schemas {
+		converted[i] = convertSchema(schema)
+	}
+	return converted
+}
+
+func convertSchemaMap(schemas map[string]spec.Schema) map[string]spec.Schema {
+	if schemas == nil {
+		return nil
+	}
+	converted := make(map[string]spec.Schema, len(schemas))
+	for name, schema := range schemas {
+		converted[name] = convertSchema(schema)
+	}
+	return converted
+}
+
+func convertSchemaOrArray(items *spec.SchemaOrArray) *spec.SchemaOrArray {
+	if items == nil {
+		return nil
+	}
+	return &spec.SchemaOrArray{Schema: convertSchemaRef(items.Schema), Schemas: convertSchemaList(items.Schemas)}
+}
+
+func convertSchemaOrBool(schema *spec.SchemaOrBool) *spec.SchemaOrBool {
+	if schema == nil {
+		return nil
+	}
+	return &spec.SchemaOrBool{Allows: schema.Allows, Schema: convertSchemaRef(schema.Schema)}
+}
+
+func operationsOf(item spec.PathItem) map[string]*spec.Operation {
+	operations := map[string]*spec.Operation{
+		"get":     item.Get,
+		"put":     item.Put,
+		"post":    item.Post,
+		"delete":  item.Delete,
+		"options": item.Options,
+		"head":    item.Head,
+		"patch":   item.Patch,
+	}
+	for method, operation := range operations {
+		if operation == nil {
+			delete(operations, method)
+		}
+	}
+	return operations
+}
+
+func convertOperation(operation *spec.Operation, consumes, produces []string) *Operation {
+
+	if len(operation.Consumes) > 0 {
+		consumes = operation.Consumes
+	}
+	if len(operation.Produces) > 0 {
+		produces = operation.Produces
+	}
+	if len(consumes) == 0 {
+		consumes = []string{"application/json"}
+	}
+	if len(produces) == 0 {
+		produces = []string{"application/json"}
+	}
+
+	result := &Operation{
+		Tags:        operation.Tags,
+		Summary:     operation.Summary,
+		Description: operation.Description,
+		OperationID: operation.ID,
+		Deprecated:  operation.Deprecated,
+		Responses:   make(map[string]Response),
+	}
+
+	var form *spec.Schema
+
+	for _, parameter := range operation.Parameters {
+		switch parameter.In {
+		case "body":
+			result.RequestBody = &RequestBody{
+				Description: parameter.Description,
+				Required:    parameter.Required,
+				Content:     mediaTypes(consumes, convertSchemaRef(parameter.Schema)),
+			}
+		case "formData":
+			if form == nil {
+				form = &spec.Schema{SchemaProps: spec.SchemaProps{Type: spec.StringOrArray{"object"}, Properties: make(map[string]spec.Schema)}}
+			}
+			form.Properties[parameter.Name] = *parameterSchema(parameter)
+			if parameter.Required {
+				form.Required = append(form.Required, parameter.Name)
+			}
+		default:
+			result.Parameters = append(result.Parameters, Parameter{
+				Name:        parameter.Name,
+				In:          parameter.In,
+				Description: parameter.Description,
+				Required:    parameter.Required || parameter.In == "path",
+				Schema:      parameterSchema(parameter),
+			})
+		}
+	}
+
+	if form != nil {
+		result.RequestBody = &RequestBody{Content: mediaTypes(consumes, form)}
+	}
+
+	if operation.Responses != nil {
+		if operation.Responses.Default != nil {
+			result.Responses["default"] = convertResponse(*operation.Responses.Default, produces)
+		}
+		for code, response := range operation.Responses.StatusCodeResponses {
+			result.Responses[strconv.Itoa(code)] = convertResponse(response, produces)
+		}
+	}
+
+	if len(result.Responses) == 0 {
+		result.Responses["default"] = Response{Description: "OK"}
+	}
+
+	return result
+}
+
+func convertResponse(response spec.Response, produces []string) Response {
+	result := Response{Description: response.Description}
+	if response.Schema != nil {
+		result.Content = mediaTypes(produces, convertSchemaRef(response.Schema))
+	}
+	return result
+}
+
+func parameterSchema(parameter spec.Parameter) *spec.Schema {
+	schema := &spec.Schema{
+		SchemaProps: spec.SchemaProps{
+			Format:  parameter.Format,
+			Default: parameter.Default,
+			Enum:    parameter.Enum,
+			Pattern: parameter.Pattern,
+		},
+	}
+	if parameter.Type != "" {
+		schema.Type = spec.StringOrArray{parameter.Type}
+	}
+	if parameter.Items != nil {
+		items := &spec.Schema{SchemaProps: spec.SchemaProps{Format: parameter.Items.Format, Enum: parameter.Items.Enum}}
+		if parameter.Items.Type != "" {
+			items.Type = spec.StringOrArray{parameter.Items.Type}
+		}
+		schema.Items = &spec.SchemaOrArray{Schema: items}
+	}
+	return schema
+}
+
+func mediaTypes(contentTypes []string, schema *spec.Schema) map[string]MediaType {
+	content := make(map[string]MediaType)
+	for _, contentType := range contentTypes {
+		content[contentType] = MediaType{Schema: schema}
+	}
+	return content
+}
@@ -0,0 +1,215 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package authenticate
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-openapi/spec"
+)
+
+func TestConvertOperation(t *testing.T) {
+	var operation spec.Operation
+
+	data := `{
+  "operationId": "CreateUser",
+  "consumes": ["application/json"],
+  "parameters": [
+    {"name": "workspace", "in": "path", "type": "string"},
+    {"name": "limit", "in": "query", "type": "integer", "format": "int32"},
+    {"name": "body", "in": "body", "required": true, "schema": {"$ref": "#/definitions/models.User"}}
+  ],
+  "responses": {
+    "200": {"description": "ok", "schema": {"type": "array", "items": {"$ref": "#/definitions/models.User"}}},
+    "default": {"description": "error", "schema": {"$ref": "#/definitions/errors.Error"}}
+  }
+}`
+
+	if err := json.Unmarshal([]byte(data), &operation); err != nil {
+		t.Fatal(err)
+	}
+
+	result := convertOperation(&operation, nil, []string{"application/json", "text/plain"})
+
+	if len(result.Parameters) != 2 {
+		t.Fatalf("expected 2 parameters, got %d", len(result.Parameters))
+	}
+	if !result.Parameters[0].Required {
+		t.Errorf("expected path parameter to be required")
+	}
+	if result.Parameters[1].Required || result.Parameters[1].Schema.Format != "int32" {
+		t.Errorf("unexpected query parameter %+v", result.Parameters[1])
+	}
+
+	if result.RequestBody == nil || !result.RequestBody.Required {
+		t.Fatalf("expected required request body, got %+v", result.RequestBody)
+	}
+	if ref := result.RequestBody.Content["application/json"].Schema.Ref.String(); ref != "#/components/schemas/models.User" {
+		t.Errorf("expected request body ref to components, got %s", ref)
+	}
+
+	if len(result.Responses) != 2 {
+		t.Fatalf("expected 2 responses, got %d", len(result.Responses))
+	}
+	for _, contentType := range []string{"application/json", "text/plain"} {
+		schema := result.Responses["200"].Content[contentType].Schema
+		if schema == nil || schema.Items == nil || schema.Items.Schema.Ref.String() != "#/components/schemas/models.User" {
+			t.Errorf("unexpected %s response schema %+v", contentType, schema)
+		}
+	}
+	if ref := result.Responses["default"].Content["application/json"].Schema.Ref.String(); ref != "#/components/schemas/errors.Error" {
+		t.Errorf("expected default response ref to components, got %s", ref)
+	}
+
+	// the swagger 2.0 spec is shared by refreshes, it must not be modified
+	if ref := operation.Parameters[2].Schema.Ref.String(); ref != "#/definitions/models.User" {
+		t.Errorf("expected source ref to be kept, got %s", ref)
+	}
+}
+
+func TestConvertFormData(t *testing.T) {
+	operation := &spec.Operation{
+		OperationProps: spec.OperationProps{
+			Parameters: []spec.Parameter{
+				*spec.FormDataParam("username").Typed("string", "").AsRequired(),
+				*spec.FormDataParam("tags").CollectionOf(spec.NewItems().Typed("string", ""), "csv"),
+			},
+		},
+	}
+
+	result := convertOperation(operation, []string{"application/x-www-form-urlencoded"}, nil)
+
+	if len(result.Parameters) != 0 {
+		t.Errorf("expected form data to be moved to request body, got %+v", result.Parameters)
+	}
+	if result.RequestBody == nil {
+		t.Fatal("expected request body")
+	}
+
+	schema := result.RequestBody.Content["application/x-www-form-urlencoded"].Schema
+
+	if schema == nil || !schema.Type.Contains("object") {
+		t.Fatalf("expected object schema, got %+v", schema)
+	}
+	if !reflect.DeepEqual(schema.Required, []string{"username"}) {
+		t.Errorf("expected username to be required, got %v", schema.Required)
+	}
+	if items := schema.Properties["tags"].Items; items == nil || !items.Schema.Type.Contains("string") {
+		t.Errorf("unexpected tags schema %+v", schema.Properties["tags"])
+	}
+	if _, ok := result.Responses["default"]; !ok {
+		t.Errorf("expected default response, got %v", result.Responses)
+	}
+}
+
+func TestConvertSchema(t *testing.T) {
+	var schema spec.Schema
+
+	data := `{
+  "description": "see #/definitions/models.User",
+  "properties": {
+    "user": {"$ref": "#/definitions/models.User"},
+    "labels": {"type": "object", "additionalProperties": {"$ref": "#/definitions/models.Label"}},
+    "items": {"type": "array", "items": {"allOf": [{"$ref": "#/definitions/models.Item"}]}},
+    "remote": {"$ref": "http://example.com/schema.json#/definitions/models.User"}
+  }
+}`
+
+	if err := json.Unmarshal([]byte(data), &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	converted := convertSchema(schema)
+
+	tests := []struct {
+		ref      spec.Ref
+		expected string
+	}{
+		{converted.Properties["user"].Ref, "#/components/schemas/models.User"},
+		{converted.Properties["labels"].AdditionalProperties.Schema.Ref, "#/components/schemas/models.Label"},
+		{converted.Properties["items"].Items.Schema.AllOf[0].Ref, "#/components/schemas/models.Item"},
+		{converted.Properties["remote"].Ref, "http://example.com/schema.json#/definitions/models.User"},
+	}
+
+	for i, test := range tests {
+		if ref := test.ref.String(); ref != test.expected {
+			t.Errorf("case %d: expected %s, got %s", i, test.expected, ref)
+		}
+	}
+
+	if converted.Description != "see #/definitions/models.User" {
+		t.Errorf("expected description to be kept, got %s", converted.Description)
+	}
+
+	if ref := schema.Properties["user"].Ref; ref.String() != "#/definitions/models.User" {
+		t.Errorf("expected source schema to be kept, got %s", ref.String())
+	}
+}
+
+func TestMerge(t *testing.T) {
+	first := &spec.Swagger{
+		SwaggerProps: spec.SwaggerProps{
+			Paths: &spec.Paths{Paths: map[string]spec.PathItem{
+				"/kapis/iam.kubesphere.io/v1alpha2/users": {PathItemProps: spec.PathItemProps{
+					Get: spec.NewOperation("ListUsers").WithTags("Identity Management"),
+				}},
+			}},
+			Definitions: spec.Definitions{"models.User": *spec.RefSchema("#/definitions/models.Group")},
+			Tags:        []spec.Tag{spec.NewTag("Identity Management", "", nil)},
+		},
+	}
+	second := &spec.Swagger{
+		SwaggerProps: spec.SwaggerProps{
+			Paths: &spec.Paths{Paths: map[string]spec.PathItem{
+				"/kapis/iam.kubesphere.io/v1alpha2/users": {PathItemProps: spec.PathItemProps{
+					Get:  spec.NewOperation("ListAllUsers"),
+					Post: spec.NewOperation("CreateUser"),
+				}},
+			}},
+			Definitions: spec.Definitions{"models.User": *spec.StringProperty()},
+			Tags:        []spec.Tag{spec.NewTag("Identity Management", "", nil)},
+		},
+	}
+
+	document := newOpenAPI()
+	document.merge(first)
+	document.merge(second)
+
+	item := document.Paths["/kapis/iam.kubesphere.io/v1alpha2/users"]
+
+	if len(item) != 2 || item["get"].OperationID != "ListUsers" || item["post"].OperationID != "CreateUser" {
+		t.Errorf("unexpected path item %+v", item)
+	}
+	if ref := document.Components.Schemas["models.User"].Ref; ref.String() != "#/components/schemas/models.Group" {
+		t.Errorf("expected the first definition with converted ref, got %s", ref.String())
+	}
+	if len(document.Tags) != 1 {
+		t.Errorf("expected tags to be deduplicated, got %v", document.Tags)
+	}
+
+	data, err := json.Marshal(document)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "#/definitions/") {
+		t.Errorf("expected no swagger 2.0 refs, got %s", data)
+	}
+}
@@ -19,6 +19,7 @@ package authenticate
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/mholt/caddy/caddyhttp/httpserver"
 )
@@ -32,10 +33,20 @@ type Handler struct {
 	URL      string
 	FilePath string
 	Handler  http.Handler
+	// Specs are aggregated and served at SpecPath, instead of the static api.json
+	Specs           []Spec
+	SpecPath        string
+	RefreshInterval time.Duration
+	Aggregator      http.Handler
 }
 
 func (h Swagger) ServeHTTP(resp http.ResponseWriter, req *http.Request) (int, error) {
 
+	if h.Handler.Aggregator != nil && req.URL.Path == h.Handler.SpecPath {
+		h.Handler.Aggregator.ServeHTTP(resp, req)
+		return http.StatusOK, nil
+	}
+
 	if httpserver.Path(req.URL.Path).Matches(h.Handler.URL) {
 		h.Handler.Handler.ServeHTTP(resp, req)
 		return http.StatusOK, nil
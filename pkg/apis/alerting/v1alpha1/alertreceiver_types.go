@@ -0,0 +1,131 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	ResourceKindAlertReceiver     = "AlertReceiver"
+	ResourceSingularAlertReceiver = "alertreceiver"
+	ResourcePluralAlertReceiver   = "alertreceivers"
+
+	ResourceKindAlertNotificationPolicy     = "AlertNotificationPolicy"
+	ResourceSingularAlertNotificationPolicy = "alertnotificationpolicy"
+	ResourcePluralAlertNotificationPolicy   = "alertnotificationpolicies"
+)
+
+// WebhookReceiver receives notifications of Alertmanager by HTTP POST in JSON
+type WebhookReceiver struct {
+	// URL is an http or https URL out of the cluster
+	URL string `json:"url"`
+}
+
+// EmailReceiver receives notifications by email, sent by the SMTP server configured in the global section of Alertmanager config
+type EmailReceiver struct {
+	To []string `json:"to"`
+}
+
+// AlertReceiverSpec defines the desired state of AlertReceiver, at least one of webhook and email is set
+type AlertReceiverSpec struct {
+	Webhook *WebhookReceiver `json:"webhook,omitempty"`
+	Email   *EmailReceiver   `json:"email,omitempty"`
+	// SendResolved notifies receivers when alerts are resolved
+	SendResolved bool `json:"sendResolved,omitempty"`
+}
+
+// AlertReceiverState is the state of rendering a receiver into the config of Alertmanager
+type AlertReceiverState string
+
+const (
+	AlertReceiverStateRendered AlertReceiverState = "Rendered"
+	// AlertReceiverStateInvalid means the receiver is left out of the config, see status.message for details
+	AlertReceiverStateInvalid AlertReceiverState = "Invalid"
+)
+
+// AlertReceiverStatus defines the observed state of AlertReceiver
+type AlertReceiverStatus struct {
+	State              AlertReceiverState `json:"state,omitempty"`
+	ObservedGeneration int64              `json:"observedGeneration,omitempty"`
+	Message            string             `json:"message,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AlertReceiver is the Schema for the alertreceivers API
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="State",type="string",JSONPath=".status.state"
+type AlertReceiver struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AlertReceiverSpec   `json:"spec,omitempty"`
+	Status AlertReceiverStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AlertReceiverList contains a list of AlertReceiver
+type AlertReceiverList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AlertReceiver `json:"items"`
+}
+
+// AlertNotificationPolicySpec defines the desired state of AlertNotificationPolicy.
+// Policies in namespaces of tenants only match alerts of their namespaces.
+type AlertNotificationPolicySpec struct {
+	// Matchers select alerts by labels, all alerts of the namespace if it's empty
+	Matchers map[string]string `json:"matchers,omitempty"`
+	// Receivers are names of AlertReceivers in the same namespace
+	Receivers []string `json:"receivers"`
+	// GroupBy are labels to group alerts into notifications
+	GroupBy        []string         `json:"groupBy,omitempty"`
+	GroupWait      *metav1.Duration `json:"groupWait,omitempty"`
+	GroupInterval  *metav1.Duration `json:"groupInterval,omitempty"`
+	RepeatInterval *metav1.Duration `json:"repeatInterval,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AlertNotificationPolicy is the Schema for the alertnotificationpolicies API
+// +k8s:openapi-gen=true
+type AlertNotificationPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec AlertNotificationPolicySpec `json:"spec,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AlertNotificationPolicyList contains a list of AlertNotificationPolicy
+type AlertNotificationPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AlertNotificationPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&AlertReceiver{}, &AlertReceiverList{}, &AlertNotificationPolicy{}, &AlertNotificationPolicyList{})
+}
@@ -0,0 +1,140 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	ResourceKindAlertRule     = "AlertRule"
+	ResourceSingularAlertRule = "alertrule"
+	ResourcePluralAlertRule   = "alertrules"
+)
+
+// AlertRuleLevel is one of the levels of monitoring metrics
+type AlertRuleLevel string
+
+const (
+	AlertRuleLevelCluster   AlertRuleLevel = "cluster"
+	AlertRuleLevelNode      AlertRuleLevel = "node"
+	AlertRuleLevelWorkspace AlertRuleLevel = "workspace"
+	AlertRuleLevelNamespace AlertRuleLevel = "namespace"
+	AlertRuleLevelWorkload  AlertRuleLevel = "workload"
+	AlertRuleLevelPod       AlertRuleLevel = "pod"
+	AlertRuleLevelContainer AlertRuleLevel = "container"
+	AlertRuleLevelComponent AlertRuleLevel = "component"
+)
+
+// labels set on alerts of rules, they can't be overridden by labels of rules
+const (
+	// AlertRuleLabel is <namespace>/<name> of the rule
+	AlertRuleLabel = "alertrule"
+	// AlertNamespaceLabel and AlertWorkspaceLabel are the tenant of alerts
+	AlertNamespaceLabel = "namespace"
+	AlertWorkspaceLabel = "workspace"
+	AlertSeverityLabel  = "severity"
+)
+
+// AlertRuleState is the state of rendering a rule into a PrometheusRule
+type AlertRuleState string
+
+const (
+	AlertRuleStateRendered AlertRuleState = "Rendered"
+	// AlertRuleStateError means the rule can't be rendered, see status.message for details
+	AlertRuleStateError AlertRuleState = "Error"
+)
+
+// AlertRuleScope restricts the resources of the metric. Rules in namespaces of tenants are
+// restricted to their namespaces, other levels are only allowed in the monitoring system namespace.
+type AlertRuleScope struct {
+	// Workspace is required by workspace rules
+	Workspace string `json:"workspace,omitempty"`
+	// Node restricts node rules to the node, all nodes if it's empty
+	Node string `json:"node,omitempty"`
+	// WorkloadKind is one of deployment, statefulset and daemonset, required by workload rules
+	WorkloadKind string `json:"workloadKind,omitempty"`
+	// Workload restricts workload rules to the workload, all workloads of the kind if it's empty
+	Workload string `json:"workload,omitempty"`
+	// Pod restricts pod rules to the pod, all pods if it's empty. It's required by container rules.
+	Pod string `json:"pod,omitempty"`
+	// Container restricts container rules to the container, all containers if it's empty
+	Container string `json:"container,omitempty"`
+}
+
+// AlertRuleSpec defines the desired state of AlertRule
+type AlertRuleSpec struct {
+	Level AlertRuleLevel `json:"level"`
+	// Metric is a metric name of the level in monitoring API, e.g. namespace_memory_usage
+	Metric string         `json:"metric"`
+	Scope  AlertRuleScope `json:"scope,omitempty"`
+	// Operator compares the metric with the threshold, one of >, >=, <, <=, == and !=
+	Operator string `json:"operator"`
+	// Threshold is a number, e.g. 0.8
+	Threshold string `json:"threshold"`
+	// For fires the rule after the condition holds for the duration, e.g. 5m
+	For *metav1.Duration `json:"for,omitempty"`
+	// Severity of alerts, warning by default
+	Severity string `json:"severity,omitempty"`
+	// Labels are added to alerts, tenant labels of the rule take precedence over them
+	Labels map[string]string `json:"labels,omitempty"`
+	// Annotations are added to alerts, e.g. summary and description
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// AlertRuleStatus defines the observed state of AlertRule
+type AlertRuleStatus struct {
+	State AlertRuleState `json:"state,omitempty"`
+	// Expression is the PromQL expression rendered into the PrometheusRule
+	Expression         string `json:"expression,omitempty"`
+	ObservedGeneration int64  `json:"observedGeneration,omitempty"`
+	Message            string `json:"message,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AlertRule is the Schema for the alertrules API
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Level",type="string",JSONPath=".spec.level"
+// +kubebuilder:printcolumn:name="Metric",type="string",JSONPath=".spec.metric"
+// +kubebuilder:printcolumn:name="Operator",type="string",JSONPath=".spec.operator"
+// +kubebuilder:printcolumn:name="Threshold",type="string",JSONPath=".spec.threshold"
+// +kubebuilder:printcolumn:name="State",type="string",JSONPath=".status.state"
+type AlertRule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AlertRuleSpec   `json:"spec,omitempty"`
+	Status AlertRuleStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// AlertRuleList contains a list of AlertRule
+type AlertRuleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AlertRule `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&AlertRule{}, &AlertRuleList{})
+}
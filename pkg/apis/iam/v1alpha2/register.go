@@ -27,7 +27,9 @@ import (
 	"kubesphere.io/kubesphere/pkg/constants"
 	"kubesphere.io/kubesphere/pkg/errors"
 	"kubesphere.io/kubesphere/pkg/models"
+	"kubesphere.io/kubesphere/pkg/models/iam/bulk"
 	"kubesphere.io/kubesphere/pkg/models/iam/policy"
+	"kubesphere.io/kubesphere/pkg/models/iam/scim"
 	"net/http"
 	"time"
 )
@@ -37,7 +39,7 @@ const GroupName = "iam.kubesphere.io"
 var GroupVersion = schema.GroupVersion{Group: GroupName, Version: "v1alpha2"}
 
 var (
-	WebServiceBuilder = runtime.NewContainerBuilder(addWebService)
+	WebServiceBuilder = runtime.NewContainerBuilder(addWebService, addSCIMWebService)
 	AddToContainer    = WebServiceBuilder.AddToContainer
 )
 
@@ -168,6 +170,21 @@ func addWebService(c *restful.Container) error {
 		Doc("List all users.").
 		Returns(http.StatusOK, ok, UserList{}).
 		Metadata(restfulspec.KeyOpenAPITags, []string{constants.IdentityManagementTag}))
+	ws.Route(ws.GET("/users/export").
+		To(iam.ExportUsers).
+		Doc("Export users with their groups and workspace memberships, and all groups. Passwords are not exported. Requires the permission to get users.").
+		Param(ws.QueryParameter("format", "json or csv, groups are only included in json").DefaultValue(iam.FormatJSON)).
+		Produces(restful.MIME_JSON, iam.MIME_CSV).
+		Returns(http.StatusOK, ok, bulk.Document{}).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.IdentityManagementTag}))
+	ws.Route(ws.POST("/users/import").
+		To(iam.ImportUsers).
+		Doc("Create or update users, groups and workspace memberships in bulk. Nothing is removed. The csv header is username,email,password,description,lang,cluster_role,groups,workspaces, groups and workspaces are separated by semicolon, such as \"dev;ops\" and \"demo=workspace-admin\".").
+		Param(ws.QueryParameter("format", "json or csv, detected from Content-Type if not specified")).
+		Consumes(restful.MIME_JSON, iam.MIME_CSV).
+		Reads(bulk.Document{}).
+		Returns(http.StatusOK, ok, bulk.Report{}).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.IdentityManagementTag}))
 	ws.Route(ws.GET("/users/{user}/roles").
 		To(iam.ListUserRoles).
 		Doc("Retrieve all the roles that are assigned to the specified user.").
@@ -284,3 +301,104 @@ func addWebService(c *restful.Container) error {
 	c.Add(ws)
 	return nil
 }
+
+// addSCIMWebService serves SCIM 2.0 for provisioning from identity providers,
+// the id of user is the username and the id of group is the group path
+func addSCIMWebService(c *restful.Container) error {
+	ws := new(restful.WebService)
+
+	ws.Path(runtime.ApiRootPath+"/"+GroupVersion.String()+"/scim/v2").
+		Consumes(scim.MediaType, restful.MIME_JSON).
+		Produces(scim.MediaType, restful.MIME_JSON)
+
+	ok := "ok"
+
+	ws.Route(ws.GET("/ServiceProviderConfig").
+		To(iam.SCIMServiceProviderConfig).
+		Doc("Describe the SCIM features supported.").
+		Returns(http.StatusOK, ok, scim.ServiceProviderConfig{}).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.IdentityManagementTag}))
+	ws.Route(ws.GET("/Users").
+		To(iam.SCIMListUsers).
+		Doc("List users, only equality filters on userName and emails are supported.").
+		Param(ws.QueryParameter("filter", "filter, such as userName eq \"admin\"")).
+		Param(ws.QueryParameter("startIndex", "1-based index of the first result").DataType("integer")).
+		Param(ws.QueryParameter("count", "max number of results").DataType("integer")).
+		Returns(http.StatusOK, ok, scim.ListResponse{}).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.IdentityManagementTag}))
+	ws.Route(ws.GET("/Users/{id}").
+		To(iam.SCIMGetUser).
+		Doc("Describe the specified user.").
+		Param(ws.PathParameter("id", "username")).
+		Returns(http.StatusOK, ok, scim.User{}).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.IdentityManagementTag}))
+	ws.Route(ws.POST("/Users").
+		To(iam.SCIMCreateUser).
+		Doc("Provision a user, a random password is generated if not specified.").
+		Reads(scim.User{}).
+		Returns(http.StatusCreated, ok, scim.User{}).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.IdentityManagementTag}))
+	ws.Route(ws.PUT("/Users/{id}").
+		To(iam.SCIMReplaceUser).
+		Doc("Update the specified user, the user is deprovisioned if active is false.").
+		Param(ws.PathParameter("id", "username")).
+		Reads(scim.User{}).
+		Returns(http.StatusOK, ok, scim.User{}).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.IdentityManagementTag}))
+	ws.Route(ws.PATCH("/Users/{id}").
+		To(iam.SCIMPatchUser).
+		Doc("Patch the specified user, the user is deprovisioned if active is replaced with false.").
+		Param(ws.PathParameter("id", "username")).
+		Reads(scim.PatchOp{}).
+		Returns(http.StatusOK, ok, scim.User{}).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.IdentityManagementTag}))
+	ws.Route(ws.DELETE("/Users/{id}").
+		To(iam.SCIMDeleteUser).
+		Doc("Deprovision the specified user.").
+		Param(ws.PathParameter("id", "username")).
+		Returns(http.StatusNoContent, ok, nil).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.IdentityManagementTag}))
+	ws.Route(ws.GET("/Groups").
+		To(iam.SCIMListGroups).
+		Doc("List groups, only equality filters on displayName and id are supported.").
+		Param(ws.QueryParameter("filter", "filter, such as displayName eq \"dev\"")).
+		Param(ws.QueryParameter("startIndex", "1-based index of the first result").DataType("integer")).
+		Param(ws.QueryParameter("count", "max number of results").DataType("integer")).
+		Returns(http.StatusOK, ok, scim.ListResponse{}).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.IdentityManagementTag}))
+	ws.Route(ws.GET("/Groups/{id}").
+		To(iam.SCIMGetGroup).
+		Doc("Describe the specified group.").
+		Param(ws.PathParameter("id", "group path")).
+		Returns(http.StatusOK, ok, scim.Group{}).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.IdentityManagementTag}))
+	ws.Route(ws.POST("/Groups").
+		To(iam.SCIMCreateGroup).
+		Doc("Create a group, the name is derived from displayName.").
+		Reads(scim.Group{}).
+		Returns(http.StatusCreated, ok, scim.Group{}).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.IdentityManagementTag}))
+	ws.Route(ws.PUT("/Groups/{id}").
+		To(iam.SCIMReplaceGroup).
+		Doc("Replace members of the specified group.").
+		Param(ws.PathParameter("id", "group path")).
+		Reads(scim.Group{}).
+		Returns(http.StatusOK, ok, scim.Group{}).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.IdentityManagementTag}))
+	ws.Route(ws.PATCH("/Groups/{id}").
+		To(iam.SCIMPatchGroup).
+		Doc("Add or remove members of the specified group.").
+		Param(ws.PathParameter("id", "group path")).
+		Reads(scim.PatchOp{}).
+		Returns(http.StatusOK, ok, scim.Group{}).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.IdentityManagementTag}))
+	ws.Route(ws.DELETE("/Groups/{id}").
+		To(iam.SCIMDeleteGroup).
+		Doc("Delete the specified group.").
+		Param(ws.PathParameter("id", "group path")).
+		Returns(http.StatusNoContent, ok, nil).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.IdentityManagementTag}))
+
+	c.Add(ws)
+	return nil
+}
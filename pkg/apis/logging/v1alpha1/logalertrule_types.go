@@ -0,0 +1,128 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	ResourceKindLogAlertRule     = "LogAlertRule"
+	ResourceSingularLogAlertRule = "logalertrule"
+	ResourcePluralLogAlertRule   = "logalertrules"
+)
+
+// LogAlertState is the state of a rule after the last evaluation
+type LogAlertState string
+
+const (
+	// LogAlertStateInactive means logs matched are no more than the threshold
+	LogAlertStateInactive LogAlertState = "Inactive"
+	// LogAlertStateFiring means logs matched exceed the threshold
+	LogAlertStateFiring LogAlertState = "Firing"
+	// LogAlertStateError means the rule can't be evaluated, see status.message for details
+	LogAlertStateError LogAlertState = "Error"
+)
+
+// LogAlertRuleScope restricts the logs to count, the narrowest one set is used.
+// Workload requires namespace.
+type LogAlertRuleScope struct {
+	Workspace string `json:"workspace,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Workload  string `json:"workload,omitempty"`
+}
+
+// WebhookReceiver receives notifications by HTTP POST in JSON
+type WebhookReceiver struct {
+	URL string `json:"url"`
+}
+
+// EmailReceiver receives notifications by email, sent by the SMTP server configured in controller manager
+type EmailReceiver struct {
+	To []string `json:"to"`
+}
+
+// LogAlertReceiver is one of webhook and email
+type LogAlertReceiver struct {
+	Webhook *WebhookReceiver `json:"webhook,omitempty"`
+	Email   *EmailReceiver   `json:"email,omitempty"`
+}
+
+// LogAlertRuleSpec defines the desired state of LogAlertRule
+type LogAlertRuleSpec struct {
+	Scope LogAlertRuleScope `json:"scope"`
+	// Query is a comma-separated list of keywords matching log messages, the same as log_query of logging API
+	Query string `json:"query"`
+	// Window is the time range counted in each evaluation, e.g. 5m
+	Window metav1.Duration `json:"window"`
+	// Threshold fires the rule when logs matched in the window are more than it
+	Threshold int64 `json:"threshold"`
+	// Interval between evaluations, 1m by default
+	Interval *metav1.Duration `json:"interval,omitempty"`
+	// RepeatInterval resends notifications of firing rules after it, notifications are sent only on state changes if it's not set
+	RepeatInterval *metav1.Duration   `json:"repeatInterval,omitempty"`
+	Receivers      []LogAlertReceiver `json:"receivers,omitempty"`
+}
+
+// LogAlertRuleStatus defines the observed state of LogAlertRule
+type LogAlertRuleStatus struct {
+	State LogAlertState `json:"state,omitempty"`
+	// Value is the number of logs matched in the window of the last evaluation
+	Value int64 `json:"value"`
+	// ObservedGeneration is the generation of spec evaluated last time, changes of spec are evaluated immediately
+	ObservedGeneration int64        `json:"observedGeneration,omitempty"`
+	LastEvaluationTime *metav1.Time `json:"lastEvaluationTime,omitempty"`
+	// FiringSince is the evaluation time when the rule started firing
+	FiringSince          *metav1.Time `json:"firingSince,omitempty"`
+	LastNotificationTime *metav1.Time `json:"lastNotificationTime,omitempty"`
+	// Message describes errors of evaluation or notification
+	Message string `json:"message,omitempty"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// LogAlertRule is the Schema for the logalertrules API
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Query",type="string",JSONPath=".spec.query"
+// +kubebuilder:printcolumn:name="Threshold",type="integer",JSONPath=".spec.threshold"
+// +kubebuilder:printcolumn:name="State",type="string",JSONPath=".status.state"
+// +kubebuilder:printcolumn:name="Value",type="integer",JSONPath=".status.value"
+type LogAlertRule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   LogAlertRuleSpec   `json:"spec,omitempty"`
+	Status LogAlertRuleStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// LogAlertRuleList contains a list of LogAlertRule
+type LogAlertRuleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []LogAlertRule `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&LogAlertRule{}, &LogAlertRuleList{})
+}
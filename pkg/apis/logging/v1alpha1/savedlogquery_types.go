@@ -0,0 +1,85 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	ResourceKindSavedLogQuery     = "SavedLogQuery"
+	ResourceSingularSavedLogQuery = "savedlogquery"
+	ResourcePluralSavedLogQuery   = "savedlogqueries"
+
+	// SavedLogQueryNameLabel is the name of the query in its workspace or namespace
+	SavedLogQueryNameLabel = "logging.kubesphere.io/saved-query"
+)
+
+// SavedLogQueryScope is the workspace or namespace sharing the query, exactly one of them is set
+type SavedLogQueryScope struct {
+	Workspace string `json:"workspace,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// SavedLogQuerySpec defines the desired state of SavedLogQuery
+type SavedLogQuerySpec struct {
+	Scope       SavedLogQueryScope `json:"scope"`
+	Description string             `json:"description,omitempty"`
+	// Namespaces restricts queries of workspaces to the namespaces
+	Namespaces []string `json:"namespaces,omitempty"`
+	Workloads  []string `json:"workloads,omitempty"`
+	Pods       []string `json:"pods,omitempty"`
+	Containers []string `json:"containers,omitempty"`
+	// Levels matches values of the level field of structured logs
+	Levels []string `json:"levels,omitempty"`
+	// LogQuery is a comma-separated list of keywords matching log messages, the same as log_query of logging API
+	LogQuery string `json:"logQuery,omitempty"`
+	// Window is the time range before the execution to query, e.g. 1h
+	Window metav1.Duration `json:"window"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SavedLogQuery is the Schema for the savedlogqueries API
+// +k8s:openapi-gen=true
+// +kubebuilder:printcolumn:name="Workspace",type="string",JSONPath=".spec.scope.workspace"
+// +kubebuilder:printcolumn:name="Namespace",type="string",JSONPath=".spec.scope.namespace"
+// +kubebuilder:printcolumn:name="Query",type="string",JSONPath=".spec.logQuery"
+// +kubebuilder:printcolumn:name="Window",type="string",JSONPath=".spec.window"
+type SavedLogQuery struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec SavedLogQuerySpec `json:"spec,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// SavedLogQueryList contains a list of SavedLogQuery
+type SavedLogQueryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SavedLogQuery `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SavedLogQuery{}, &SavedLogQueryList{})
+}
@@ -49,7 +49,8 @@ func addWebService(c *restful.Container) error {
 	ws.Route(ws.GET("/cluster").To(logging.LoggingQueryCluster).
 		Filter(filter.Logging).
 		Doc("Query logs against the cluster.").
-		Param(ws.QueryParameter("operation", "Query type. This can be one of three types: query (for querying logs), statistics (for retrieving statistical data), and histogram (for displaying log count by time interval). Defaults to query.").DefaultValue("query").DataType("string").Required(false)).
+		Param(ws.QueryParameter("operation", "Query type. This can be one of six types: query (for querying logs), statistics (for retrieving statistical data), histogram (for displaying log count by time interval), aggregation (for retrieving the most frequent values of a structured field), export (for downloading all logs of the time range) and follow (for streaming new logs until the connection is closed). Defaults to query.").DefaultValue("query").DataType("string").Required(false)).
+		Param(ws.QueryParameter("format", "Output format of export and follow. One of json (log records separated by newlines) and text (log messages only). Defaults to json.").DataType("string").DefaultValue("json").Required(false)).
 		Param(ws.QueryParameter("workspaces", "A comma-separated list of workspaces. This field restricts the query to specified workspaces. For example, the following filter matches the workspace my-ws and demo-ws: `my-ws,demo-ws`").DataType("string").Required(false)).
 		Param(ws.QueryParameter("workspace_query", "A comma-separated list of keywords. Differing from **workspaces**, this field performs fuzzy matching on workspaces. For example, the following value limits the query to workspaces whose name contains the word my(My,MY,...) *OR* demo(Demo,DemO,...): `my,demo`.").DataType("string").Required(false)).
 		Param(ws.QueryParameter("namespaces", "A comma-separated list of namespaces. This field restricts the query to specified namespaces. For example, the following filter matches the namespace my-ns and demo-ns: `my-ns,demo-ns`").DataType("string").Required(false)).
@@ -61,23 +62,26 @@ func addWebService(c *restful.Container) error {
 		Param(ws.QueryParameter("containers", "A comma-separated list of containers. This field restricts the query to specified containers. For example, the following filter matches the container my-cont and demo-cont: `my-cont,demo-cont`").DataType("string").Required(false)).
 		Param(ws.QueryParameter("container_query", "A comma-separated list of keywords. Differing from **containers**, this field performs fuzzy matching on containers. For example, the following value limits the query to containers whose name contains the word my(My,MY,...) *OR* demo(Demo,DemO,...): `my,demo`.").DataType("string").Required(false)).
 		Param(ws.QueryParameter("log_query", "A comma-separated list of keywords. The query returns logs which contain at least one keyword. Case-insensitive matching. For example, if the field is set to `err,INFO`, the query returns any log containing err(ERR,Err,...) *OR* INFO(info,InFo,...).").DataType("string").Required(false)).
+		Param(ws.QueryParameter("fields", "A comma-separated list of structured field filters in the format field:value. Structured fields are decoded from JSON logs, nested fields are joined by dots. The query returns logs matching filters of all fields, and any of the values of the same field, eg. `level:error,level:warn,trace_id:abc`.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("aggregation_field", "The structured field to count logs by. It requires **operation** is set to aggregation, and **size** is the number of values to return (at most 100).").DataType("string").Required(false)).
 		Param(ws.QueryParameter("interval", "Time interval. It requires **operation** is set to histogram. The format is [0-9]+[smhdwMqy]. Defaults to 15m (i.e. 15 min).").DefaultValue("15m").DataType("string").Required(false)).
 		Param(ws.QueryParameter("start_time", "Start time of query. Default to 0. The format is a string representing milliseconds since the epoch, eg. 1559664000000.").DataType("string").Required(false)).
 		Param(ws.QueryParameter("end_time", "End time of query. Default to now. The format is a string representing milliseconds since the epoch, eg. 1559664000000.").DataType("string").Required(false)).
 		Param(ws.QueryParameter("sort", "Sort order. One of acs, desc. This field sorts logs by timestamp.").DataType("string").DefaultValue("desc").Required(false)).
 		Param(ws.QueryParameter("from", "The offset from the result set. This field returns query results from the specified offset. It requires **operation** is set to query. Defaults to 0 (i.e. from the beginning of the result set).").DataType("integer").DefaultValue("0").Required(false)).
-		Param(ws.QueryParameter("size", "Size of result to return. It requires **operation** is set to query. Defaults to 10 (i.e. 10 log records).").DataType("integer").DefaultValue("10").Required(false)).
+		Param(ws.QueryParameter("size", "Size of result to return. It requires **operation** is set to query, or follow for the number of latest records sent first (at most 1000). Defaults to 10 (i.e. 10 log records).").DataType("integer").DefaultValue("10").Required(false)).
 		Metadata(restfulspec.KeyOpenAPITags, []string{constants.LogQueryTag}).
 		Writes(esclient.QueryResult{}).
 		Returns(http.StatusOK, RespOK, esclient.QueryResult{})).
 		Consumes(restful.MIME_JSON, restful.MIME_XML).
-		Produces(restful.MIME_JSON)
+		Produces(restful.MIME_JSON, logging.MIME_NDJSON, "text/plain")
 
 	ws.Route(ws.GET("/workspaces/{workspace}").To(logging.LoggingQueryWorkspace).
 		Filter(filter.Logging).
 		Doc("Query logs against the specific workspace.").
 		Param(ws.PathParameter("workspace", "The name of the workspace.").DataType("string").Required(true)).
-		Param(ws.QueryParameter("operation", "Query type. This can be one of three types: query (for querying logs), statistics (for retrieving statistical data), and histogram (for displaying log count by time interval). Defaults to query.").DefaultValue("query").DataType("string").Required(false)).
+		Param(ws.QueryParameter("operation", "Query type. This can be one of six types: query (for querying logs), statistics (for retrieving statistical data), histogram (for displaying log count by time interval), aggregation (for retrieving the most frequent values of a structured field), export (for downloading all logs of the time range) and follow (for streaming new logs until the connection is closed). Defaults to query.").DefaultValue("query").DataType("string").Required(false)).
+		Param(ws.QueryParameter("format", "Output format of export and follow. One of json (log records separated by newlines) and text (log messages only). Defaults to json.").DataType("string").DefaultValue("json").Required(false)).
 		Param(ws.QueryParameter("namespaces", "A comma-separated list of namespaces. This field restricts the query to specified namespaces. For example, the following filter matches the namespace my-ns and demo-ns: `my-ns,demo-ns`").DataType("string").Required(false)).
 		Param(ws.QueryParameter("namespace_query", "A comma-separated list of keywords. Differing from **namespaces**, this field performs fuzzy matching on namespaces. For example, the following value limits the query to namespaces whose name contains the word my(My,MY,...) *OR* demo(Demo,DemO,...): `my,demo`.").DataType("string").Required(false)).
 		Param(ws.QueryParameter("workloads", "A comma-separated list of workloads. This field restricts the query to specified workloads. For example, the following filter matches the workload my-wl and demo-wl: `my-wl,demo-wl`").DataType("string").Required(false)).
@@ -87,23 +91,26 @@ func addWebService(c *restful.Container) error {
 		Param(ws.QueryParameter("containers", "A comma-separated list of containers. This field restricts the query to specified containers. For example, the following filter matches the container my-cont and demo-cont: `my-cont,demo-cont`").DataType("string").Required(false)).
 		Param(ws.QueryParameter("container_query", "A comma-separated list of keywords. Differing from **containers**, this field performs fuzzy matching on containers. For example, the following value limits the query to containers whose name contains the word my(My,MY,...) *OR* demo(Demo,DemO,...): `my,demo`.").DataType("string").Required(false)).
 		Param(ws.QueryParameter("log_query", "A comma-separated list of keywords. The query returns logs which contain at least one keyword. Case-insensitive matching. For example, if the field is set to `err,INFO`, the query returns any log containing err(ERR,Err,...) *OR* INFO(info,InFo,...).").DataType("string").Required(false)).
+		Param(ws.QueryParameter("fields", "A comma-separated list of structured field filters in the format field:value. Structured fields are decoded from JSON logs, nested fields are joined by dots. The query returns logs matching filters of all fields, and any of the values of the same field, eg. `level:error,level:warn,trace_id:abc`.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("aggregation_field", "The structured field to count logs by. It requires **operation** is set to aggregation, and **size** is the number of values to return (at most 100).").DataType("string").Required(false)).
 		Param(ws.QueryParameter("interval", "Time interval. It requires **operation** is set to histogram. The format is [0-9]+[smhdwMqy]. Defaults to 15m (i.e. 15 min).").DefaultValue("15m").DataType("string").Required(false)).
 		Param(ws.QueryParameter("start_time", "Start time of query. Default to 0. The format is a string representing milliseconds since the epoch, eg. 1559664000000.").DataType("string").Required(false)).
 		Param(ws.QueryParameter("end_time", "End time of query. Default to now. The format is a string representing milliseconds since the epoch, eg. 1559664000000.").DataType("string").Required(false)).
 		Param(ws.QueryParameter("sort", "Sort order. One of acs, desc. This field sorts logs by timestamp.").DataType("string").DefaultValue("desc").Required(false)).
 		Param(ws.QueryParameter("from", "The offset from the result set. This field returns query results from the specified offset. It requires **operation** is set to query. Defaults to 0 (i.e. from the beginning of the result set).").DataType("integer").DefaultValue("0").Required(false)).
-		Param(ws.QueryParameter("size", "Size of result to return. It requires **operation** is set to query. Defaults to 10 (i.e. 10 log records).").DataType("integer").DefaultValue("10").Required(false)).
+		Param(ws.QueryParameter("size", "Size of result to return. It requires **operation** is set to query, or follow for the number of latest records sent first (at most 1000). Defaults to 10 (i.e. 10 log records).").DataType("integer").DefaultValue("10").Required(false)).
 		Metadata(restfulspec.KeyOpenAPITags, []string{constants.LogQueryTag}).
 		Writes(esclient.QueryResult{}).
 		Returns(http.StatusOK, RespOK, esclient.QueryResult{})).
 		Consumes(restful.MIME_JSON, restful.MIME_XML).
-		Produces(restful.MIME_JSON)
+		Produces(restful.MIME_JSON, logging.MIME_NDJSON, "text/plain")
 
 	ws.Route(ws.GET("/namespaces/{namespace}").To(logging.LoggingQueryNamespace).
 		Filter(filter.Logging).
 		Doc("Query logs against the specific namespace.").
 		Param(ws.PathParameter("namespace", "The name of the namespace.").DataType("string").Required(true)).
-		Param(ws.QueryParameter("operation", "Query type. This can be one of three types: query (for querying logs), statistics (for retrieving statistical data), and histogram (for displaying log count by time interval). Defaults to query.").DefaultValue("query").DataType("string").Required(false)).
+		Param(ws.QueryParameter("operation", "Query type. This can be one of six types: query (for querying logs), statistics (for retrieving statistical data), histogram (for displaying log count by time interval), aggregation (for retrieving the most frequent values of a structured field), export (for downloading all logs of the time range) and follow (for streaming new logs until the connection is closed). Defaults to query.").DefaultValue("query").DataType("string").Required(false)).
+		Param(ws.QueryParameter("format", "Output format of export and follow. One of json (log records separated by newlines) and text (log messages only). Defaults to json.").DataType("string").DefaultValue("json").Required(false)).
 		Param(ws.QueryParameter("workloads", "A comma-separated list of workloads. This field restricts the query to specified workloads. For example, the following filter matches the workload my-wl and demo-wl: `my-wl,demo-wl`").DataType("string").Required(false)).
 		Param(ws.QueryParameter("workload_query", "A comma-separated list of keywords. Differing from **workloads**, this field performs fuzzy matching on workloads. For example, the following value limits the query to workloads whose name contains the word my(My,MY,...) *OR* demo(Demo,DemO,...): `my,demo`.").DataType("string").Required(false)).
 		Param(ws.QueryParameter("pods", "A comma-separated list of pods. This field restricts the query to specified pods. For example, the following filter matches the pod my-po and demo-po: `my-po,demo-po`").DataType("string").Required(false)).
@@ -111,61 +118,69 @@ func addWebService(c *restful.Container) error {
 		Param(ws.QueryParameter("containers", "A comma-separated list of containers. This field restricts the query to specified containers. For example, the following filter matches the container my-cont and demo-cont: `my-cont,demo-cont`").DataType("string").Required(false)).
 		Param(ws.QueryParameter("container_query", "A comma-separated list of keywords. Differing from **containers**, this field performs fuzzy matching on containers. For example, the following value limits the query to containers whose name contains the word my(My,MY,...) *OR* demo(Demo,DemO,...): `my,demo`.").DataType("string").Required(false)).
 		Param(ws.QueryParameter("log_query", "A comma-separated list of keywords. The query returns logs which contain at least one keyword. Case-insensitive matching. For example, if the field is set to `err,INFO`, the query returns any log containing err(ERR,Err,...) *OR* INFO(info,InFo,...).").DataType("string").Required(false)).
+		Param(ws.QueryParameter("fields", "A comma-separated list of structured field filters in the format field:value. Structured fields are decoded from JSON logs, nested fields are joined by dots. The query returns logs matching filters of all fields, and any of the values of the same field, eg. `level:error,level:warn,trace_id:abc`.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("aggregation_field", "The structured field to count logs by. It requires **operation** is set to aggregation, and **size** is the number of values to return (at most 100).").DataType("string").Required(false)).
 		Param(ws.QueryParameter("interval", "Time interval. It requires **operation** is set to histogram. The format is [0-9]+[smhdwMqy]. Defaults to 15m (i.e. 15 min).").DefaultValue("15m").DataType("string").Required(false)).
 		Param(ws.QueryParameter("start_time", "Start time of query. Default to 0. The format is a string representing milliseconds since the epoch, eg. 1559664000000.").DataType("string").Required(false)).
 		Param(ws.QueryParameter("end_time", "End time of query. Default to now. The format is a string representing milliseconds since the epoch, eg. 1559664000000.").DataType("string").Required(false)).
 		Param(ws.QueryParameter("sort", "Sort order. One of acs, desc. This field sorts logs by timestamp.").DataType("string").DefaultValue("desc").Required(false)).
 		Param(ws.QueryParameter("from", "The offset from the result set. This field returns query results from the specified offset. It requires **operation** is set to query. Defaults to 0 (i.e. from the beginning of the result set).").DataType("integer").DefaultValue("0").Required(false)).
-		Param(ws.QueryParameter("size", "Size of result to return. It requires **operation** is set to query. Defaults to 10 (i.e. 10 log records).").DataType("integer").DefaultValue("10").Required(false)).
+		Param(ws.QueryParameter("size", "Size of result to return. It requires **operation** is set to query, or follow for the number of latest records sent first (at most 1000). Defaults to 10 (i.e. 10 log records).").DataType("integer").DefaultValue("10").Required(false)).
 		Metadata(restfulspec.KeyOpenAPITags, []string{constants.LogQueryTag}).
 		Writes(esclient.QueryResult{}).
 		Returns(http.StatusOK, RespOK, esclient.QueryResult{})).
 		Consumes(restful.MIME_JSON, restful.MIME_XML).
-		Produces(restful.MIME_JSON)
+		Produces(restful.MIME_JSON, logging.MIME_NDJSON, "text/plain")
 
 	ws.Route(ws.GET("/namespaces/{namespace}/workloads/{workload}").To(logging.LoggingQueryWorkload).
 		Filter(filter.Logging).
 		Doc("Query logs against the specific workload.").
 		Param(ws.PathParameter("namespace", "The name of the namespace.").DataType("string").Required(true)).
 		Param(ws.PathParameter("workload", "The name of the workload.").DataType("string").Required(true)).
-		Param(ws.QueryParameter("operation", "Query type. This can be one of three types: query (for querying logs), statistics (for retrieving statistical data), and histogram (for displaying log count by time interval). Defaults to query.").DefaultValue("query").DataType("string").Required(false)).
+		Param(ws.QueryParameter("operation", "Query type. This can be one of six types: query (for querying logs), statistics (for retrieving statistical data), histogram (for displaying log count by time interval), aggregation (for retrieving the most frequent values of a structured field), export (for downloading all logs of the time range) and follow (for streaming new logs until the connection is closed). Defaults to query.").DefaultValue("query").DataType("string").Required(false)).
+		Param(ws.QueryParameter("format", "Output format of export and follow. One of json (log records separated by newlines) and text (log messages only). Defaults to json.").DataType("string").DefaultValue("json").Required(false)).
 		Param(ws.QueryParameter("pods", "A comma-separated list of pods. This field restricts the query to specified pods. For example, the following filter matches the pod my-po and demo-po: `my-po,demo-po`").DataType("string").Required(false)).
 		Param(ws.QueryParameter("pod_query", "A comma-separated list of keywords. Differing from **pods**, this field performs fuzzy matching on pods. For example, the following value limits the query to pods whose name contains the word my(My,MY,...) *OR* demo(Demo,DemO,...): `my,demo`.").DataType("string").Required(false)).
 		Param(ws.QueryParameter("containers", "A comma-separated list of containers. This field restricts the query to specified containers. For example, the following filter matches the container my-cont and demo-cont: `my-cont,demo-cont`").DataType("string").Required(false)).
 		Param(ws.QueryParameter("container_query", "A comma-separated list of keywords. Differing from **containers**, this field performs fuzzy matching on containers. For example, the following value limits the query to containers whose name contains the word my(My,MY,...) *OR* demo(Demo,DemO,...): `my,demo`.").DataType("string").Required(false)).
 		Param(ws.QueryParameter("log_query", "A comma-separated list of keywords. The query returns logs which contain at least one keyword. Case-insensitive matching. For example, if the field is set to `err,INFO`, the query returns any log containing err(ERR,Err,...) *OR* INFO(info,InFo,...).").DataType("string").Required(false)).
+		Param(ws.QueryParameter("fields", "A comma-separated list of structured field filters in the format field:value. Structured fields are decoded from JSON logs, nested fields are joined by dots. The query returns logs matching filters of all fields, and any of the values of the same field, eg. `level:error,level:warn,trace_id:abc`.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("aggregation_field", "The structured field to count logs by. It requires **operation** is set to aggregation, and **size** is the number of values to return (at most 100).").DataType("string").Required(false)).
 		Param(ws.QueryParameter("interval", "Time interval. It requires **operation** is set to histogram. The format is [0-9]+[smhdwMqy]. Defaults to 15m (i.e. 15 min).").DefaultValue("15m").DataType("string").Required(false)).
 		Param(ws.QueryParameter("start_time", "Start time of query. Default to 0. The format is a string representing milliseconds since the epoch, eg. 1559664000000.").DataType("string").Required(false)).
 		Param(ws.QueryParameter("end_time", "End time of query. Default to now. The format is a string representing milliseconds since the epoch, eg. 1559664000000.").DataType("string").Required(false)).
 		Param(ws.QueryParameter("sort", "Sort order. One of acs, desc. This field sorts logs by timestamp.").DataType("string").DefaultValue("desc").Required(false)).
 		Param(ws.QueryParameter("from", "The offset from the result set. This field returns query results from the specified offset. It requires **operation** is set to query. Defaults to 0 (i.e. from the beginning of the result set).").DataType("integer").DefaultValue("0").Required(false)).
-		Param(ws.QueryParameter("size", "Size of result to return. It requires **operation** is set to query. Defaults to 10 (i.e. 10 log records).").DataType("integer").DefaultValue("10").Required(false)).
+		Param(ws.QueryParameter("size", "Size of result to return. It requires **operation** is set to query, or follow for the number of latest records sent first (at most 1000). Defaults to 10 (i.e. 10 log records).").DataType("integer").DefaultValue("10").Required(false)).
 		Metadata(restfulspec.KeyOpenAPITags, []string{constants.LogQueryTag}).
 		Writes(esclient.QueryResult{}).
 		Returns(http.StatusOK, RespOK, esclient.QueryResult{})).
 		Consumes(restful.MIME_JSON, restful.MIME_XML).
-		Produces(restful.MIME_JSON)
+		Produces(restful.MIME_JSON, logging.MIME_NDJSON, "text/plain")
 
 	ws.Route(ws.GET("/namespaces/{namespace}/pods/{pod}").To(logging.LoggingQueryPod).
 		Filter(filter.Logging).
 		Doc("Query logs against the specific pod.").
 		Param(ws.PathParameter("namespace", "The name of the namespace.").DataType("string").Required(true)).
 		Param(ws.PathParameter("pod", "Pod name.").DataType("string").Required(true)).
-		Param(ws.QueryParameter("operation", "Query type. This can be one of three types: query (for querying logs), statistics (for retrieving statistical data), and histogram (for displaying log count by time interval). Defaults to query.").DefaultValue("query").DataType("string").Required(false)).
+		Param(ws.QueryParameter("operation", "Query type. This can be one of six types: query (for querying logs), statistics (for retrieving statistical data), histogram (for displaying log count by time interval), aggregation (for retrieving the most frequent values of a structured field), export (for downloading all logs of the time range) and follow (for streaming new logs until the connection is closed). Defaults to query.").DefaultValue("query").DataType("string").Required(false)).
+		Param(ws.QueryParameter("format", "Output format of export and follow. One of json (log records separated by newlines) and text (log messages only). Defaults to json.").DataType("string").DefaultValue("json").Required(false)).
 		Param(ws.QueryParameter("containers", "A comma-separated list of containers. This field restricts the query to specified containers. For example, the following filter matches the container my-cont and demo-cont: `my-cont,demo-cont`").DataType("string").Required(false)).
 		Param(ws.QueryParameter("container_query", "A comma-separated list of keywords. Differing from **containers**, this field performs fuzzy matching on containers. For example, the following value limits the query to containers whose name contains the word my(My,MY,...) *OR* demo(Demo,DemO,...): `my,demo`.").DataType("string").Required(false)).
 		Param(ws.QueryParameter("log_query", "A comma-separated list of keywords. The query returns logs which contain at least one keyword. Case-insensitive matching. For example, if the field is set to `err,INFO`, the query returns any log containing err(ERR,Err,...) *OR* INFO(info,InFo,...).").DataType("string").Required(false)).
+		Param(ws.QueryParameter("fields", "A comma-separated list of structured field filters in the format field:value. Structured fields are decoded from JSON logs, nested fields are joined by dots. The query returns logs matching filters of all fields, and any of the values of the same field, eg. `level:error,level:warn,trace_id:abc`.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("aggregation_field", "The structured field to count logs by. It requires **operation** is set to aggregation, and **size** is the number of values to return (at most 100).").DataType("string").Required(false)).
 		Param(ws.QueryParameter("interval", "Time interval. It requires **operation** is set to histogram. The format is [0-9]+[smhdwMqy]. Defaults to 15m (i.e. 15 min).").DefaultValue("15m").DataType("string").Required(false)).
 		Param(ws.QueryParameter("start_time", "Start time of query. Default to 0. The format is a string representing milliseconds since the epoch, eg. 1559664000000.").DataType("string").Required(false)).
 		Param(ws.QueryParameter("end_time", "End time of query. Default to now. The format is a string representing milliseconds since the epoch, eg. 1559664000000.").DataType("string").Required(false)).
 		Param(ws.QueryParameter("sort", "Sort order. One of acs, desc. This field sorts logs by timestamp.").DataType("string").DefaultValue("desc").Required(false)).
 		Param(ws.QueryParameter("from", "The offset from the result set. This field returns query results from the specified offset. It requires **operation** is set to query. Defaults to 0 (i.e. from the beginning of the result set).").DataType("integer").DefaultValue("0").Required(false)).
-		Param(ws.QueryParameter("size", "Size of result to return. It requires **operation** is set to query. Defaults to 10 (i.e. 10 log records).").DataType("integer").DefaultValue("10").Required(false)).
+		Param(ws.QueryParameter("size", "Size of result to return. It requires **operation** is set to query, or follow for the number of latest records sent first (at most 1000). Defaults to 10 (i.e. 10 log records).").DataType("integer").DefaultValue("10").Required(false)).
 		Metadata(restfulspec.KeyOpenAPITags, []string{constants.LogQueryTag}).
 		Writes(esclient.QueryResult{}).
 		Returns(http.StatusOK, RespOK, esclient.QueryResult{})).
 		Consumes(restful.MIME_JSON, restful.MIME_XML).
-		Produces(restful.MIME_JSON)
+		Produces(restful.MIME_JSON, logging.MIME_NDJSON, "text/plain")
 
 	ws.Route(ws.GET("/namespaces/{namespace}/pods/{pod}/containers/{container}").To(logging.LoggingQueryContainer).
 		Filter(filter.Logging).
@@ -173,20 +188,291 @@ func addWebService(c *restful.Container) error {
 		Param(ws.PathParameter("namespace", "The name of the namespace.").DataType("string").Required(true)).
 		Param(ws.PathParameter("pod", "Pod name.").DataType("string").Required(true)).
 		Param(ws.PathParameter("container", "Container name.").DataType("string").Required(true)).
-		Param(ws.QueryParameter("operation", "Query type. This can be one of three types: query (for querying logs), statistics (for retrieving statistical data), and histogram (for displaying log count by time interval). Defaults to query.").DefaultValue("query").DataType("string").Required(false)).
+		Param(ws.QueryParameter("operation", "Query type. This can be one of six types: query (for querying logs), statistics (for retrieving statistical data), histogram (for displaying log count by time interval), aggregation (for retrieving the most frequent values of a structured field), export (for downloading all logs of the time range) and follow (for streaming new logs until the connection is closed). Defaults to query.").DefaultValue("query").DataType("string").Required(false)).
+		Param(ws.QueryParameter("format", "Output format of export and follow. One of json (log records separated by newlines) and text (log messages only). Defaults to json.").DataType("string").DefaultValue("json").Required(false)).
 		Param(ws.QueryParameter("log_query", "A comma-separated list of keywords. The query returns logs which contain at least one keyword. Case-insensitive matching. For example, if the field is set to `err,INFO`, the query returns any log containing err(ERR,Err,...) *OR* INFO(info,InFo,...).").DataType("string").Required(false)).
+		Param(ws.QueryParameter("fields", "A comma-separated list of structured field filters in the format field:value. Structured fields are decoded from JSON logs, nested fields are joined by dots. The query returns logs matching filters of all fields, and any of the values of the same field, eg. `level:error,level:warn,trace_id:abc`.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("aggregation_field", "The structured field to count logs by. It requires **operation** is set to aggregation, and **size** is the number of values to return (at most 100).").DataType("string").Required(false)).
 		Param(ws.QueryParameter("interval", "Time interval. It requires **operation** is set to histogram. The format is [0-9]+[smhdwMqy]. Defaults to 15m (i.e. 15 min).").DefaultValue("15m").DataType("string").Required(false)).
 		Param(ws.QueryParameter("start_time", "Start time of query. Default to 0. The format is a string representing milliseconds since the epoch, eg. 1559664000000.").DataType("string").Required(false)).
 		Param(ws.QueryParameter("end_time", "End time of query. Default to now. The format is a string representing milliseconds since the epoch, eg. 1559664000000.").DataType("string").Required(false)).
 		Param(ws.QueryParameter("sort", "Sort order. One of acs, desc. This field sorts logs by timestamp.").DataType("string").DefaultValue("desc").Required(false)).
 		Param(ws.QueryParameter("from", "The offset from the result set. This field returns query results from the specified offset. It requires **operation** is set to query. Defaults to 0 (i.e. from the beginning of the result set).").DataType("integer").DefaultValue("0").Required(false)).
-		Param(ws.QueryParameter("size", "Size of result to return. It requires **operation** is set to query. Defaults to 10 (i.e. 10 log records).").DataType("integer").DefaultValue("10").Required(false)).
+		Param(ws.QueryParameter("size", "Size of result to return. It requires **operation** is set to query, or follow for the number of latest records sent first (at most 1000). Defaults to 10 (i.e. 10 log records).").DataType("integer").DefaultValue("10").Required(false)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.LogQueryTag}).
+		Writes(esclient.QueryResult{}).
+		Returns(http.StatusOK, RespOK, esclient.QueryResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON, logging.MIME_NDJSON, "text/plain")
+
+	ws.Route(ws.GET("/namespaces/{namespace}/traces/{trace}").To(logging.LoggingQueryTrace).
+		Filter(filter.Logging).
+		Doc("Query logs of the trace in the specific namespace. Logs of a trace share the trace id in the structured field set by --logging-trace-field, trace ids are found in traces of services returned by the servicemesh API. Logs are sorted by time ascending by default.").
+		Param(ws.PathParameter("namespace", "The name of the namespace.").DataType("string").Required(true)).
+		Param(ws.PathParameter("trace", "Trace ID.").DataType("string").Required(true)).
+		Param(ws.QueryParameter("operation", "Query type. This can be one of four types: query (for querying logs), statistics (for retrieving statistical data), histogram (for displaying log count by time interval) and aggregation (for retrieving the most frequent values of a structured field). Defaults to query.").DefaultValue("query").DataType("string").Required(false)).
+		Param(ws.QueryParameter("workloads", "A comma-separated list of workloads. This field restricts the query to specified workloads. For example, the following filter matches the workload my-wl and demo-wl: `my-wl,demo-wl`").DataType("string").Required(false)).
+		Param(ws.QueryParameter("pods", "A comma-separated list of pods. This field restricts the query to specified pods. For example, the following filter matches the pod my-po and demo-po: `my-po,demo-po`").DataType("string").Required(false)).
+		Param(ws.QueryParameter("containers", "A comma-separated list of containers. This field restricts the query to specified containers. For example, the following filter matches the container my-cont and demo-cont: `my-cont,demo-cont`").DataType("string").Required(false)).
+		Param(ws.QueryParameter("log_query", "A comma-separated list of keywords. The query returns logs which contain at least one keyword. Case-insensitive matching. For example, if the field is set to `err,INFO`, the query returns any log containing err(ERR,Err,...) *OR* INFO(info,InFo,...).").DataType("string").Required(false)).
+		Param(ws.QueryParameter("fields", "A comma-separated list of structured field filters in the format field:value. Structured fields are decoded from JSON logs, nested fields are joined by dots. The query returns logs matching filters of all fields, and any of the values of the same field, eg. `level:error,level:warn,trace_id:abc`.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("aggregation_field", "The structured field to count logs by. It requires **operation** is set to aggregation, and **size** is the number of values to return (at most 100).").DataType("string").Required(false)).
+		Param(ws.QueryParameter("interval", "Time interval. It requires **operation** is set to histogram. The format is [0-9]+[smhdwMqy]. Defaults to 15m (i.e. 15 min).").DefaultValue("15m").DataType("string").Required(false)).
+		Param(ws.QueryParameter("start_time", "Start time of query. Default to 0. The format is a string representing milliseconds since the epoch, eg. 1559664000000.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("end_time", "End time of query. Default to now. The format is a string representing milliseconds since the epoch, eg. 1559664000000.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("sort", "Sort order. One of acs, desc. This field sorts logs by timestamp.").DataType("string").DefaultValue("asc").Required(false)).
+		Param(ws.QueryParameter("from", "The offset from the result set. This field returns query results from the specified offset. It requires **operation** is set to query. Defaults to 0 (i.e. from the beginning of the result set).").DataType("integer").DefaultValue("0").Required(false)).
+		Param(ws.QueryParameter("size", "Size of result to return. It requires **operation** is set to query or aggregation. Defaults to 10 (i.e. 10 log records).").DataType("integer").DefaultValue("10").Required(false)).
 		Metadata(restfulspec.KeyOpenAPITags, []string{constants.LogQueryTag}).
 		Writes(esclient.QueryResult{}).
 		Returns(http.StatusOK, RespOK, esclient.QueryResult{})).
 		Consumes(restful.MIME_JSON, restful.MIME_XML).
 		Produces(restful.MIME_JSON)
 
+	ws.Route(ws.GET("/workspaces/{workspace}/savedlogqueries").To(logging.LoggingQueryWorkspaceSavedQueries).
+		Filter(filter.Logging).
+		Doc("List log queries saved in the workspace.").
+		Param(ws.PathParameter("workspace", "Workspace name.").DataType("string").Required(true)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.SavedLogQueryTag}).
+		Writes(log.SavedLogQueryResult{}).
+		Returns(http.StatusOK, RespOK, log.SavedLogQueryResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.POST("/workspaces/{workspace}/savedlogqueries").To(logging.LoggingInsertWorkspaceSavedQuery).
+		Filter(filter.Logging).
+		Doc("Save a log query in the workspace, members of the workspace share it.").
+		Param(ws.PathParameter("workspace", "Workspace name.").DataType("string").Required(true)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.SavedLogQueryTag}).
+		Reads(log.SavedLogQuery{}).
+		Writes(log.SavedLogQueryResult{}).
+		Returns(http.StatusOK, RespOK, log.SavedLogQueryResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.GET("/workspaces/{workspace}/savedlogqueries/{query}").To(logging.LoggingGetWorkspaceSavedQuery).
+		Filter(filter.Logging).
+		Doc("Get the log query saved in the workspace.").
+		Param(ws.PathParameter("workspace", "Workspace name.").DataType("string").Required(true)).
+		Param(ws.PathParameter("query", "Name of the saved query.").DataType("string").Required(true)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.SavedLogQueryTag}).
+		Writes(log.SavedLogQueryResult{}).
+		Returns(http.StatusOK, RespOK, log.SavedLogQueryResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.PUT("/workspaces/{workspace}/savedlogqueries/{query}").To(logging.LoggingUpdateWorkspaceSavedQuery).
+		Filter(filter.Logging).
+		Doc("Replace the log query saved in the workspace, its creator is kept.").
+		Param(ws.PathParameter("workspace", "Workspace name.").DataType("string").Required(true)).
+		Param(ws.PathParameter("query", "Name of the saved query.").DataType("string").Required(true)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.SavedLogQueryTag}).
+		Reads(log.SavedLogQuery{}).
+		Writes(log.SavedLogQueryResult{}).
+		Returns(http.StatusOK, RespOK, log.SavedLogQueryResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.DELETE("/workspaces/{workspace}/savedlogqueries/{query}").To(logging.LoggingDeleteWorkspaceSavedQuery).
+		Filter(filter.Logging).
+		Doc("Delete the log query saved in the workspace.").
+		Param(ws.PathParameter("workspace", "Workspace name.").DataType("string").Required(true)).
+		Param(ws.PathParameter("query", "Name of the saved query.").DataType("string").Required(true)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.SavedLogQueryTag}).
+		Writes(log.SavedLogQueryResult{}).
+		Returns(http.StatusOK, RespOK, log.SavedLogQueryResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.GET("/workspaces/{workspace}/savedlogqueries/{query}/logs").To(logging.LoggingExecuteWorkspaceSavedQuery).
+		Filter(filter.Logging).
+		Doc("Execute the log query saved in the workspace. Logs are queried with workloads, pods, containers, levels and log_query of the saved query, over its window before now.").
+		Param(ws.PathParameter("workspace", "Workspace name.").DataType("string").Required(true)).
+		Param(ws.PathParameter("query", "Name of the saved query.").DataType("string").Required(true)).
+		Param(ws.QueryParameter("operation", "Query type. This can be one of four types: query (for querying logs), statistics (for retrieving statistical data), histogram (for displaying log count by time interval) and aggregation (for retrieving the most frequent values of a structured field). Defaults to query.").DefaultValue("query").DataType("string").Required(false)).
+		Param(ws.QueryParameter("aggregation_field", "The structured field to count logs by. It requires **operation** is set to aggregation, and **size** is the number of values to return (at most 100).").DataType("string").Required(false)).
+		Param(ws.QueryParameter("interval", "Time interval. It requires **operation** is set to histogram. The format is [0-9]+[smhdwMqy]. Defaults to 15m (i.e. 15 min).").DefaultValue("15m").DataType("string").Required(false)).
+		Param(ws.QueryParameter("sort", "Sort order. One of acs, desc. This field sorts logs by timestamp.").DataType("string").DefaultValue("desc").Required(false)).
+		Param(ws.QueryParameter("from", "The offset from the result set. This field returns query results from the specified offset. It requires **operation** is set to query. Defaults to 0 (i.e. from the beginning of the result set).").DataType("integer").DefaultValue("0").Required(false)).
+		Param(ws.QueryParameter("size", "Size of result to return. It requires **operation** is set to query or aggregation. Defaults to 10 (i.e. 10 log records).").DataType("integer").DefaultValue("10").Required(false)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.SavedLogQueryTag}).
+		Writes(esclient.QueryResult{}).
+		Returns(http.StatusOK, RespOK, esclient.QueryResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.GET("/namespaces/{namespace}/savedlogqueries").To(logging.LoggingQueryNamespaceSavedQueries).
+		Filter(filter.Logging).
+		Doc("List log queries saved in the namespace.").
+		Param(ws.PathParameter("namespace", "The name of the namespace.").DataType("string").Required(true)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.SavedLogQueryTag}).
+		Writes(log.SavedLogQueryResult{}).
+		Returns(http.StatusOK, RespOK, log.SavedLogQueryResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.POST("/namespaces/{namespace}/savedlogqueries").To(logging.LoggingInsertNamespaceSavedQuery).
+		Filter(filter.Logging).
+		Doc("Save a log query in the namespace, members of the namespace share it.").
+		Param(ws.PathParameter("namespace", "The name of the namespace.").DataType("string").Required(true)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.SavedLogQueryTag}).
+		Reads(log.SavedLogQuery{}).
+		Writes(log.SavedLogQueryResult{}).
+		Returns(http.StatusOK, RespOK, log.SavedLogQueryResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.GET("/namespaces/{namespace}/savedlogqueries/{query}").To(logging.LoggingGetNamespaceSavedQuery).
+		Filter(filter.Logging).
+		Doc("Get the log query saved in the namespace.").
+		Param(ws.PathParameter("namespace", "The name of the namespace.").DataType("string").Required(true)).
+		Param(ws.PathParameter("query", "Name of the saved query.").DataType("string").Required(true)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.SavedLogQueryTag}).
+		Writes(log.SavedLogQueryResult{}).
+		Returns(http.StatusOK, RespOK, log.SavedLogQueryResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.PUT("/namespaces/{namespace}/savedlogqueries/{query}").To(logging.LoggingUpdateNamespaceSavedQuery).
+		Filter(filter.Logging).
+		Doc("Replace the log query saved in the namespace, its creator is kept.").
+		Param(ws.PathParameter("namespace", "The name of the namespace.").DataType("string").Required(true)).
+		Param(ws.PathParameter("query", "Name of the saved query.").DataType("string").Required(true)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.SavedLogQueryTag}).
+		Reads(log.SavedLogQuery{}).
+		Writes(log.SavedLogQueryResult{}).
+		Returns(http.StatusOK, RespOK, log.SavedLogQueryResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.DELETE("/namespaces/{namespace}/savedlogqueries/{query}").To(logging.LoggingDeleteNamespaceSavedQuery).
+		Filter(filter.Logging).
+		Doc("Delete the log query saved in the namespace.").
+		Param(ws.PathParameter("namespace", "The name of the namespace.").DataType("string").Required(true)).
+		Param(ws.PathParameter("query", "Name of the saved query.").DataType("string").Required(true)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.SavedLogQueryTag}).
+		Writes(log.SavedLogQueryResult{}).
+		Returns(http.StatusOK, RespOK, log.SavedLogQueryResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.GET("/namespaces/{namespace}/savedlogqueries/{query}/logs").To(logging.LoggingExecuteNamespaceSavedQuery).
+		Filter(filter.Logging).
+		Doc("Execute the log query saved in the namespace. Logs are queried with workloads, pods, containers, levels and log_query of the saved query, over its window before now.").
+		Param(ws.PathParameter("namespace", "The name of the namespace.").DataType("string").Required(true)).
+		Param(ws.PathParameter("query", "Name of the saved query.").DataType("string").Required(true)).
+		Param(ws.QueryParameter("operation", "Query type. This can be one of four types: query (for querying logs), statistics (for retrieving statistical data), histogram (for displaying log count by time interval) and aggregation (for retrieving the most frequent values of a structured field). Defaults to query.").DefaultValue("query").DataType("string").Required(false)).
+		Param(ws.QueryParameter("aggregation_field", "The structured field to count logs by. It requires **operation** is set to aggregation, and **size** is the number of values to return (at most 100).").DataType("string").Required(false)).
+		Param(ws.QueryParameter("interval", "Time interval. It requires **operation** is set to histogram. The format is [0-9]+[smhdwMqy]. Defaults to 15m (i.e. 15 min).").DefaultValue("15m").DataType("string").Required(false)).
+		Param(ws.QueryParameter("sort", "Sort order. One of acs, desc. This field sorts logs by timestamp.").DataType("string").DefaultValue("desc").Required(false)).
+		Param(ws.QueryParameter("from", "The offset from the result set. This field returns query results from the specified offset. It requires **operation** is set to query. Defaults to 0 (i.e. from the beginning of the result set).").DataType("integer").DefaultValue("0").Required(false)).
+		Param(ws.QueryParameter("size", "Size of result to return. It requires **operation** is set to query or aggregation. Defaults to 10 (i.e. 10 log records).").DataType("integer").DefaultValue("10").Required(false)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.SavedLogQueryTag}).
+		Writes(esclient.QueryResult{}).
+		Returns(http.StatusOK, RespOK, esclient.QueryResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.GET("/events").To(logging.LoggingQueryClusterEvents).
+		Filter(filter.Logging).
+		Doc("Query kubernetes events against the cluster.").
+		Param(ws.QueryParameter("operation", "Query type. This can be one of three types: query (for querying events), statistics (for retrieving the number of events and warnings) and histogram (for displaying event count by time interval). Defaults to query.").DefaultValue("query").DataType("string").Required(false)).
+		Param(ws.QueryParameter("workspaces", "A comma-separated list of workspaces. This field restricts the query to events of objects in specified workspaces. For example, the following filter matches the workspace my-ws and demo-ws: `my-ws,demo-ws`").DataType("string").Required(false)).
+		Param(ws.QueryParameter("workspace_query", "A comma-separated list of keywords. Differing from **workspaces**, this field performs fuzzy matching on workspaces. For example, the following value limits the query to workspaces whose name contains the word my(My,MY,...) *OR* demo(Demo,DemO,...): `my,demo`.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("namespaces", "A comma-separated list of namespaces. This field restricts the query to events of objects in specified namespaces. For example, the following filter matches the namespace my-ns and demo-ns: `my-ns,demo-ns`").DataType("string").Required(false)).
+		Param(ws.QueryParameter("namespace_query", "A comma-separated list of keywords. Differing from **namespaces**, this field performs fuzzy matching on namespaces. For example, the following value limits the query to namespaces whose name contains the word my(My,MY,...) *OR* demo(Demo,DemO,...): `my,demo`.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("workloads", "A comma-separated list of workloads. This field restricts the query to events of specified workloads and their replicasets, jobs and pods. For example, the following filter matches the workload my-wl and demo-wl: `my-wl,demo-wl`").DataType("string").Required(false)).
+		Param(ws.QueryParameter("workload_query", "A comma-separated list of keywords. Differing from **workloads**, this field performs fuzzy matching on workloads. For example, the following value limits the query to workloads whose name contains the word my(My,MY,...) *OR* demo(Demo,DemO,...): `my,demo`.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("involved_object_kinds", "A comma-separated list of kinds of the objects events are about. For example, the following filter matches events of pods and nodes: `Pod,Node`").DataType("string").Required(false)).
+		Param(ws.QueryParameter("involved_object_names", "A comma-separated list of names of the objects events are about. For example, the following filter matches events of the object my-po and demo-po: `my-po,demo-po`").DataType("string").Required(false)).
+		Param(ws.QueryParameter("reasons", "A comma-separated list of reasons. For example, the following filter matches events of the reason FailedScheduling and BackOff: `FailedScheduling,BackOff`").DataType("string").Required(false)).
+		Param(ws.QueryParameter("types", "A comma-separated list of event types. One of Normal, Warning.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("message_query", "A comma-separated list of keywords. The query returns events whose message contains at least one keyword. Case-insensitive matching. For example, if the field is set to `err,image`, the query returns any event whose message contains err(ERR,Err,...) *OR* image(IMAGE,Image,...).").DataType("string").Required(false)).
+		Param(ws.QueryParameter("interval", "Time interval. It requires **operation** is set to histogram. The format is [0-9]+[smhdwMqy]. Defaults to 15m (i.e. 15 min).").DefaultValue("15m").DataType("string").Required(false)).
+		Param(ws.QueryParameter("start_time", "Start time of query. Default to 0. The format is a string representing milliseconds since the epoch, eg. 1559664000000.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("end_time", "End time of query. Default to now. The format is a string representing milliseconds since the epoch, eg. 1559664000000.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("sort", "Sort order. One of acs, desc. This field sorts events by the time they last occurred.").DataType("string").DefaultValue("desc").Required(false)).
+		Param(ws.QueryParameter("from", "The offset from the result set. This field returns query results from the specified offset. It requires **operation** is set to query. Defaults to 0 (i.e. from the beginning of the result set).").DataType("integer").DefaultValue("0").Required(false)).
+		Param(ws.QueryParameter("size", "Size of result to return. It requires **operation** is set to query. Defaults to 10 (i.e. 10 events).").DataType("integer").DefaultValue("10").Required(false)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.EventQueryTag}).
+		Writes(esclient.EventsResult{}).
+		Returns(http.StatusOK, RespOK, esclient.EventsResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.GET("/workspaces/{workspace}/events").To(logging.LoggingQueryWorkspaceEvents).
+		Filter(filter.Logging).
+		Doc("Query kubernetes events against the specific workspace.").
+		Param(ws.PathParameter("workspace", "The name of the workspace.").DataType("string").Required(true)).
+		Param(ws.QueryParameter("operation", "Query type. This can be one of three types: query (for querying events), statistics (for retrieving the number of events and warnings) and histogram (for displaying event count by time interval). Defaults to query.").DefaultValue("query").DataType("string").Required(false)).
+		Param(ws.QueryParameter("namespaces", "A comma-separated list of namespaces. This field restricts the query to events of objects in specified namespaces. For example, the following filter matches the namespace my-ns and demo-ns: `my-ns,demo-ns`").DataType("string").Required(false)).
+		Param(ws.QueryParameter("namespace_query", "A comma-separated list of keywords. Differing from **namespaces**, this field performs fuzzy matching on namespaces. For example, the following value limits the query to namespaces whose name contains the word my(My,MY,...) *OR* demo(Demo,DemO,...): `my,demo`.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("workloads", "A comma-separated list of workloads. This field restricts the query to events of specified workloads and their replicasets, jobs and pods. For example, the following filter matches the workload my-wl and demo-wl: `my-wl,demo-wl`").DataType("string").Required(false)).
+		Param(ws.QueryParameter("workload_query", "A comma-separated list of keywords. Differing from **workloads**, this field performs fuzzy matching on workloads. For example, the following value limits the query to workloads whose name contains the word my(My,MY,...) *OR* demo(Demo,DemO,...): `my,demo`.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("involved_object_kinds", "A comma-separated list of kinds of the objects events are about. For example, the following filter matches events of pods and nodes: `Pod,Node`").DataType("string").Required(false)).
+		Param(ws.QueryParameter("involved_object_names", "A comma-separated list of names of the objects events are about. For example, the following filter matches events of the object my-po and demo-po: `my-po,demo-po`").DataType("string").Required(false)).
+		Param(ws.QueryParameter("reasons", "A comma-separated list of reasons. For example, the following filter matches events of the reason FailedScheduling and BackOff: `FailedScheduling,BackOff`").DataType("string").Required(false)).
+		Param(ws.QueryParameter("types", "A comma-separated list of event types. One of Normal, Warning.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("message_query", "A comma-separated list of keywords. The query returns events whose message contains at least one keyword. Case-insensitive matching. For example, if the field is set to `err,image`, the query returns any event whose message contains err(ERR,Err,...) *OR* image(IMAGE,Image,...).").DataType("string").Required(false)).
+		Param(ws.QueryParameter("interval", "Time interval. It requires **operation** is set to histogram. The format is [0-9]+[smhdwMqy]. Defaults to 15m (i.e. 15 min).").DefaultValue("15m").DataType("string").Required(false)).
+		Param(ws.QueryParameter("start_time", "Start time of query. Default to 0. The format is a string representing milliseconds since the epoch, eg. 1559664000000.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("end_time", "End time of query. Default to now. The format is a string representing milliseconds since the epoch, eg. 1559664000000.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("sort", "Sort order. One of acs, desc. This field sorts events by the time they last occurred.").DataType("string").DefaultValue("desc").Required(false)).
+		Param(ws.QueryParameter("from", "The offset from the result set. This field returns query results from the specified offset. It requires **operation** is set to query. Defaults to 0 (i.e. from the beginning of the result set).").DataType("integer").DefaultValue("0").Required(false)).
+		Param(ws.QueryParameter("size", "Size of result to return. It requires **operation** is set to query. Defaults to 10 (i.e. 10 events).").DataType("integer").DefaultValue("10").Required(false)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.EventQueryTag}).
+		Writes(esclient.EventsResult{}).
+		Returns(http.StatusOK, RespOK, esclient.EventsResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.GET("/namespaces/{namespace}/events").To(logging.LoggingQueryNamespaceEvents).
+		Filter(filter.Logging).
+		Doc("Query kubernetes events against the specific namespace.").
+		Param(ws.PathParameter("namespace", "The name of the namespace.").DataType("string").Required(true)).
+		Param(ws.QueryParameter("operation", "Query type. This can be one of three types: query (for querying events), statistics (for retrieving the number of events and warnings) and histogram (for displaying event count by time interval). Defaults to query.").DefaultValue("query").DataType("string").Required(false)).
+		Param(ws.QueryParameter("workloads", "A comma-separated list of workloads. This field restricts the query to events of specified workloads and their replicasets, jobs and pods. For example, the following filter matches the workload my-wl and demo-wl: `my-wl,demo-wl`").DataType("string").Required(false)).
+		Param(ws.QueryParameter("workload_query", "A comma-separated list of keywords. Differing from **workloads**, this field performs fuzzy matching on workloads. For example, the following value limits the query to workloads whose name contains the word my(My,MY,...) *OR* demo(Demo,DemO,...): `my,demo`.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("involved_object_kinds", "A comma-separated list of kinds of the objects events are about. For example, the following filter matches events of pods and nodes: `Pod,Node`").DataType("string").Required(false)).
+		Param(ws.QueryParameter("involved_object_names", "A comma-separated list of names of the objects events are about. For example, the following filter matches events of the object my-po and demo-po: `my-po,demo-po`").DataType("string").Required(false)).
+		Param(ws.QueryParameter("reasons", "A comma-separated list of reasons. For example, the following filter matches events of the reason FailedScheduling and BackOff: `FailedScheduling,BackOff`").DataType("string").Required(false)).
+		Param(ws.QueryParameter("types", "A comma-separated list of event types. One of Normal, Warning.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("message_query", "A comma-separated list of keywords. The query returns events whose message contains at least one keyword. Case-insensitive matching. For example, if the field is set to `err,image`, the query returns any event whose message contains err(ERR,Err,...) *OR* image(IMAGE,Image,...).").DataType("string").Required(false)).
+		Param(ws.QueryParameter("interval", "Time interval. It requires **operation** is set to histogram. The format is [0-9]+[smhdwMqy]. Defaults to 15m (i.e. 15 min).").DefaultValue("15m").DataType("string").Required(false)).
+		Param(ws.QueryParameter("start_time", "Start time of query. Default to 0. The format is a string representing milliseconds since the epoch, eg. 1559664000000.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("end_time", "End time of query. Default to now. The format is a string representing milliseconds since the epoch, eg. 1559664000000.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("sort", "Sort order. One of acs, desc. This field sorts events by the time they last occurred.").DataType("string").DefaultValue("desc").Required(false)).
+		Param(ws.QueryParameter("from", "The offset from the result set. This field returns query results from the specified offset. It requires **operation** is set to query. Defaults to 0 (i.e. from the beginning of the result set).").DataType("integer").DefaultValue("0").Required(false)).
+		Param(ws.QueryParameter("size", "Size of result to return. It requires **operation** is set to query. Defaults to 10 (i.e. 10 events).").DataType("integer").DefaultValue("10").Required(false)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.EventQueryTag}).
+		Writes(esclient.EventsResult{}).
+		Returns(http.StatusOK, RespOK, esclient.EventsResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.GET("/namespaces/{namespace}/workloads/{workload}/events").To(logging.LoggingQueryWorkloadEvents).
+		Filter(filter.Logging).
+		Doc("Query kubernetes events against the specific workload.").
+		Param(ws.PathParameter("namespace", "The name of the namespace.").DataType("string").Required(true)).
+		Param(ws.PathParameter("workload", "The name of the workload.").DataType("string").Required(true)).
+		Param(ws.QueryParameter("operation", "Query type. This can be one of three types: query (for querying events), statistics (for retrieving the number of events and warnings) and histogram (for displaying event count by time interval). Defaults to query.").DefaultValue("query").DataType("string").Required(false)).
+		Param(ws.QueryParameter("involved_object_kinds", "A comma-separated list of kinds of the objects events are about. For example, the following filter matches events of pods and nodes: `Pod,Node`").DataType("string").Required(false)).
+		Param(ws.QueryParameter("involved_object_names", "A comma-separated list of names of the objects events are about. For example, the following filter matches events of the object my-po and demo-po: `my-po,demo-po`").DataType("string").Required(false)).
+		Param(ws.QueryParameter("reasons", "A comma-separated list of reasons. For example, the following filter matches events of the reason FailedScheduling and BackOff: `FailedScheduling,BackOff`").DataType("string").Required(false)).
+		Param(ws.QueryParameter("types", "A comma-separated list of event types. One of Normal, Warning.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("message_query", "A comma-separated list of keywords. The query returns events whose message contains at least one keyword. Case-insensitive matching. For example, if the field is set to `err,image`, the query returns any event whose message contains err(ERR,Err,...) *OR* image(IMAGE,Image,...).").DataType("string").Required(false)).
+		Param(ws.QueryParameter("interval", "Time interval. It requires **operation** is set to histogram. The format is [0-9]+[smhdwMqy]. Defaults to 15m (i.e. 15 min).").DefaultValue("15m").DataType("string").Required(false)).
+		Param(ws.QueryParameter("start_time", "Start time of query. Default to 0. The format is a string representing milliseconds since the epoch, eg. 1559664000000.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("end_time", "End time of query. Default to now. The format is a string representing milliseconds since the epoch, eg. 1559664000000.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("sort", "Sort order. One of acs, desc. This field sorts events by the time they last occurred.").DataType("string").DefaultValue("desc").Required(false)).
+		Param(ws.QueryParameter("from", "The offset from the result set. This field returns query results from the specified offset. It requires **operation** is set to query. Defaults to 0 (i.e. from the beginning of the result set).").DataType("integer").DefaultValue("0").Required(false)).
+		Param(ws.QueryParameter("size", "Size of result to return. It requires **operation** is set to query. Defaults to 10 (i.e. 10 events).").DataType("integer").DefaultValue("10").Required(false)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.EventQueryTag}).
+		Writes(esclient.EventsResult{}).
+		Returns(http.StatusOK, RespOK, esclient.EventsResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
 	ws.Route(ws.GET("/fluentbit/outputs").To(logging.LoggingQueryFluentbitOutputs).
 		Filter(filter.Logging).
 		Doc("List all Fluent bit output plugins.").
@@ -227,6 +513,166 @@ func addWebService(c *restful.Container) error {
 		Consumes(restful.MIME_JSON, restful.MIME_XML).
 		Produces(restful.MIME_JSON)
 
+	ws.Route(ws.GET("/fluentbit/inputs").To(logging.LoggingQueryFluentbitInputs).
+		Filter(filter.Logging).
+		Doc("List all Fluent bit input plugins. Plugins managed by KubeSphere are marked read_only.").
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.FluentBitSetting}).
+		Writes(log.FluentbitPipelineResult{}).
+		Returns(http.StatusOK, RespOK, log.FluentbitPipelineResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.POST("/fluentbit/inputs").To(logging.LoggingInsertFluentbitInput).
+		Filter(filter.Logging).
+		Doc("Add a new Fluent bit input plugin. Supported plugins are tail, systemd and forward.").
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.FluentBitSetting}).
+		Reads(log.FluentbitPipelinePlugin{}).
+		Writes(log.FluentbitPipelineResult{}).
+		Returns(http.StatusOK, RespOK, log.FluentbitPipelineResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.PUT("/fluentbit/inputs/{input}").To(logging.LoggingUpdateFluentbitInput).
+		Filter(filter.Logging).
+		Doc("Update the specific Fluent bit input plugin.").
+		Param(ws.PathParameter("input", "Name of the input.").DataType("string").Required(true)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.FluentBitSetting}).
+		Reads(log.FluentbitPipelinePlugin{}).
+		Writes(log.FluentbitPipelineResult{}).
+		Returns(http.StatusOK, RespOK, log.FluentbitPipelineResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.DELETE("/fluentbit/inputs/{input}").To(logging.LoggingDeleteFluentbitInput).
+		Filter(filter.Logging).
+		Doc("Delete the specific Fluent bit input plugin.").
+		Param(ws.PathParameter("input", "Name of the input.").DataType("string").Required(true)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.FluentBitSetting}).
+		Writes(log.FluentbitPipelineResult{}).
+		Returns(http.StatusOK, RespOK, log.FluentbitPipelineResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.GET("/fluentbit/parsers").To(logging.LoggingQueryFluentbitParsers).
+		Filter(filter.Logging).
+		Doc("List all Fluent bit parser plugins. Plugins managed by KubeSphere are marked read_only.").
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.FluentBitSetting}).
+		Writes(log.FluentbitPipelineResult{}).
+		Returns(http.StatusOK, RespOK, log.FluentbitPipelineResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.POST("/fluentbit/parsers").To(logging.LoggingInsertFluentbitParser).
+		Filter(filter.Logging).
+		Doc("Add a new Fluent bit parser plugin. Supported plugins are regex, json and multiline.").
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.FluentBitSetting}).
+		Reads(log.FluentbitPipelinePlugin{}).
+		Writes(log.FluentbitPipelineResult{}).
+		Returns(http.StatusOK, RespOK, log.FluentbitPipelineResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.PUT("/fluentbit/parsers/{parser}").To(logging.LoggingUpdateFluentbitParser).
+		Filter(filter.Logging).
+		Doc("Update the specific Fluent bit parser plugin.").
+		Param(ws.PathParameter("parser", "Name of the parser.").DataType("string").Required(true)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.FluentBitSetting}).
+		Reads(log.FluentbitPipelinePlugin{}).
+		Writes(log.FluentbitPipelineResult{}).
+		Returns(http.StatusOK, RespOK, log.FluentbitPipelineResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.DELETE("/fluentbit/parsers/{parser}").To(logging.LoggingDeleteFluentbitParser).
+		Filter(filter.Logging).
+		Doc("Delete the specific Fluent bit parser plugin.").
+		Param(ws.PathParameter("parser", "Name of the parser.").DataType("string").Required(true)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.FluentBitSetting}).
+		Writes(log.FluentbitPipelineResult{}).
+		Returns(http.StatusOK, RespOK, log.FluentbitPipelineResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.POST("/fluentbit/parsers/preview").To(logging.LoggingPreviewFluentbitParsers).
+		Filter(filter.Logging).
+		Doc("Run a sample log through the given Fluent bit parsers and return the parsed records. All parsers are tried if none is specified.").
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.FluentBitSetting}).
+		Reads(log.ParserPreviewRequest{}).
+		Writes(log.ParserPreviewResult{}).
+		Returns(http.StatusOK, RespOK, log.ParserPreviewResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.GET("/fluentbit/filters").To(logging.LoggingQueryFluentbitFilters).
+		Filter(filter.Logging).
+		Doc("List all Fluent bit filter plugins. Plugins managed by KubeSphere are marked read_only.").
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.FluentBitSetting}).
+		Writes(log.FluentbitPipelineResult{}).
+		Returns(http.StatusOK, RespOK, log.FluentbitPipelineResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.POST("/fluentbit/filters").To(logging.LoggingInsertFluentbitFilter).
+		Filter(filter.Logging).
+		Doc("Add a new Fluent bit filter plugin. Supported plugins are grep, modify, nest and kubernetes.").
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.FluentBitSetting}).
+		Reads(log.FluentbitPipelinePlugin{}).
+		Writes(log.FluentbitPipelineResult{}).
+		Returns(http.StatusOK, RespOK, log.FluentbitPipelineResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.PUT("/fluentbit/filters/{filter}").To(logging.LoggingUpdateFluentbitFilter).
+		Filter(filter.Logging).
+		Doc("Update the specific Fluent bit filter plugin.").
+		Param(ws.PathParameter("filter", "Name of the filter.").DataType("string").Required(true)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.FluentBitSetting}).
+		Reads(log.FluentbitPipelinePlugin{}).
+		Writes(log.FluentbitPipelineResult{}).
+		Returns(http.StatusOK, RespOK, log.FluentbitPipelineResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.DELETE("/fluentbit/filters/{filter}").To(logging.LoggingDeleteFluentbitFilter).
+		Filter(filter.Logging).
+		Doc("Delete the specific Fluent bit filter plugin.").
+		Param(ws.PathParameter("filter", "Name of the filter.").DataType("string").Required(true)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.FluentBitSetting}).
+		Writes(log.FluentbitPipelineResult{}).
+		Returns(http.StatusOK, RespOK, log.FluentbitPipelineResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.GET("/fluentbit/workspaces").To(logging.LoggingQueryWorkspaceSettings).
+		Filter(filter.Logging).
+		Doc("List log retention and index settings of workspaces.").
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.FluentBitSetting}).
+		Writes(log.WorkspaceLogSettingsResult{}).
+		Returns(http.StatusOK, RespOK, log.WorkspaceLogSettingsResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.PUT("/fluentbit/workspaces/{workspace}").To(logging.LoggingUpdateWorkspaceSettings).
+		Filter(filter.Logging).
+		Doc("Set log retention and index settings of the workspace. Logs of the workspace are written to dedicated indices if index_prefix is set, queries of the workspace search the shared indices only for logs before index_time. Logs older than retention_days are deleted periodically by ks-controller-manager.").
+		Param(ws.PathParameter("workspace", "Workspace name.").DataType("string").Required(true)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.FluentBitSetting}).
+		Reads(log.WorkspaceLogSettings{}).
+		Writes(log.WorkspaceLogSettingsResult{}).
+		Returns(http.StatusOK, RespOK, log.WorkspaceLogSettingsResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.DELETE("/fluentbit/workspaces/{workspace}").To(logging.LoggingDeleteWorkspaceSettings).
+		Filter(filter.Logging).
+		Doc("Delete log settings of the workspace, logs are written to the shared indices again.").
+		Param(ws.PathParameter("workspace", "Workspace name.").DataType("string").Required(true)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.FluentBitSetting}).
+		Writes(log.WorkspaceLogSettingsResult{}).
+		Returns(http.StatusOK, RespOK, log.WorkspaceLogSettingsResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
 	c.Add(ws)
 	return nil
 }
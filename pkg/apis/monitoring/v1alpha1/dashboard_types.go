@@ -0,0 +1,95 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	ResourceKindDashboard     = "Dashboard"
+	ResourceSingularDashboard = "dashboard"
+	ResourcePluralDashboard   = "dashboards"
+
+	// DashboardNameLabel is the name of the dashboard in its workspace or namespace
+	DashboardNameLabel = "monitoring.kubesphere.io/dashboard"
+)
+
+// DashboardScope is the workspace or namespace sharing the dashboard, exactly one of them is set
+type DashboardScope struct {
+	Workspace string `json:"workspace,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// PanelLayout is the position and size of the panel in the grid of the dashboard
+type PanelLayout struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// Panel shows a metric of the scope of the dashboard, exactly one of metric and query is set
+type Panel struct {
+	Title string `json:"title"`
+	// Metric is a metric name of the level of the dashboard in monitoring API,
+	// e.g. namespace_memory_usage of namespace dashboards and workspace_memory_usage of workspace dashboards
+	Metric string `json:"metric,omitempty"`
+	// Query is a PromQL expression, selectors are restricted to namespaces of the dashboard
+	Query string `json:"query,omitempty"`
+	// Unit of values, e.g. bytes, cores and percent
+	Unit   string      `json:"unit,omitempty"`
+	Layout PanelLayout `json:"layout,omitempty"`
+}
+
+// DashboardSpec defines the desired state of Dashboard
+type DashboardSpec struct {
+	Scope       DashboardScope `json:"scope"`
+	Description string         `json:"description,omitempty"`
+	Panels      []Panel        `json:"panels,omitempty"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// Dashboard is the Schema for the dashboards API
+// +k8s:openapi-gen=true
+// +kubebuilder:printcolumn:name="Workspace",type="string",JSONPath=".spec.scope.workspace"
+// +kubebuilder:printcolumn:name="Namespace",type="string",JSONPath=".spec.scope.namespace"
+// +kubebuilder:printcolumn:name="Description",type="string",JSONPath=".spec.description"
+type Dashboard struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec DashboardSpec `json:"spec,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DashboardList contains a list of Dashboard
+type DashboardList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Dashboard `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Dashboard{}, &DashboardList{})
+}
@@ -0,0 +1,103 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	ResourceKindMeteringRollup     = "MeteringRollup"
+	ResourceSingularMeteringRollup = "meteringrollup"
+	ResourcePluralMeteringRollup   = "meteringrollups"
+
+	// MeteringRollupDateLayout is the layout of dates of rollups, days are in UTC
+	MeteringRollupDateLayout = "2006-01-02"
+	// MeteringRollupDateLabel is the label of dates of rollups, usage of a day is split into rollups
+	// to keep objects within the size limit of etcd
+	MeteringRollupDateLabel = "monitoring.kubesphere.io/metering-date"
+
+	MeteringLevelWorkspace = "workspace"
+	MeteringLevelNamespace = "namespace"
+	MeteringLevelWorkload  = "workload"
+)
+
+// ResourceUsage is the resource usage accumulated over a period
+type ResourceUsage struct {
+	// CPU usage in core hours
+	CPUCoreHours float64 `json:"cpuCoreHours"`
+	// memory usage without cache in GiB hours
+	MemoryGiBHours float64 `json:"memoryGiBHours"`
+	// storage requested by persistent volume claims in GiB hours
+	StorageGiBHours float64 `json:"storageGiBHours"`
+	// network traffic transmitted and received in GiB
+	NetworkGiB float64 `json:"networkGiB"`
+	// GPUs requested by containers in GPU hours
+	GPUHours float64 `json:"gpuHours"`
+}
+
+// MeteringEntry is the resource usage of a workspace, namespace or workload,
+// storage and GPUs are only metered at the workspace and namespace levels
+type MeteringEntry struct {
+	// Level is one of workspace, namespace and workload
+	Level     string `json:"level"`
+	Workspace string `json:"workspace,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	// Workload is in the format of <kind>:<name>, eg. Deployment:nginx
+	Workload string        `json:"workload,omitempty"`
+	Usage    ResourceUsage `json:"usage"`
+}
+
+// MeteringRollupSpec defines a part of the resource usage of a day
+type MeteringRollupSpec struct {
+	// Date is the day of the rollup in the format of 2006-01-02
+	Date string `json:"date"`
+	// Shards is the number of rollups of the day, usage of the day is complete if all of them exist
+	Shards  int             `json:"shards"`
+	Entries []MeteringEntry `json:"entries,omitempty"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MeteringRollup is the Schema for the meteringrollups API, rollups keep resource usage
+// metered from Prometheus after metrics are deleted by the retention of Prometheus
+// +k8s:openapi-gen=true
+// +kubebuilder:printcolumn:name="Date",type="string",JSONPath=".spec.date"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+type MeteringRollup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec MeteringRollupSpec `json:"spec,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// MeteringRollupList contains a list of MeteringRollup
+type MeteringRollupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MeteringRollup `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MeteringRollup{}, &MeteringRollupList{})
+}
@@ -21,10 +21,13 @@ import (
 	"github.com/emicklei/go-restful"
 	"github.com/emicklei/go-restful-openapi"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	alertingv1alpha1 "kubesphere.io/kubesphere/pkg/apis/alerting/v1alpha1"
 	"kubesphere.io/kubesphere/pkg/apiserver/monitoring"
 	"kubesphere.io/kubesphere/pkg/apiserver/runtime"
 	"kubesphere.io/kubesphere/pkg/constants"
+	"kubesphere.io/kubesphere/pkg/models/alerting"
 	"kubesphere.io/kubesphere/pkg/models/metrics"
+	"kubesphere.io/kubesphere/pkg/simple/client/alertmanager"
 	"net/http"
 )
 
@@ -353,6 +356,347 @@ func addWebService(c *restful.Container) error {
 		Consumes(restful.MIME_JSON, restful.MIME_XML).
 		Produces(restful.MIME_JSON)
 
+	ws.Route(ws.GET("/promql").To(monitoring.QueryPromQL).
+		Doc("Evaluate a PromQL expression against metrics of all namespaces the user can access. Every vector selector of the expression is restricted by a matcher of the namespace label. It's a range query if both start and end are given, otherwise an instant query.").
+		Param(ws.QueryParameter("query", "PromQL expression, eg. `sum(rate(http_requests_total[5m])) by (pod)`. Ranges of range selectors and subqueries are limited, 1d by default.").DataType("string").Required(true)).
+		Param(ws.QueryParameter("time", "Evaluation time of instant queries, a unix timestamp in seconds or an RFC3339 time. Defaults to now.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("start", "Start time of range queries, a unix timestamp in seconds or an RFC3339 time. The range is limited, 7d by default.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("end", "End time of range queries, a unix timestamp in seconds or an RFC3339 time.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("step", "Step of range queries in seconds or a duration, eg. 30 or 1m. Points per series are limited, 1100 by default.").DataType("string").DefaultValue("10m").Required(false)).
+		Param(ws.QueryParameter("timeout", "Evaluation timeout in seconds or a duration, eg. 30s. It's limited to 30s by default.").DataType("string").Required(false)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.PromQLQueryTag}).
+		Writes(metrics.PromQLResult{}).
+		Returns(http.StatusOK, RespOK, metrics.PromQLResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.GET("/workspaces/{workspace}/promql").To(monitoring.QueryPromQL).
+		Doc("Evaluate a PromQL expression against metrics of namespaces of the workspace the user can access. Every vector selector of the expression is restricted by a matcher of the namespace label. It's a range query if both start and end are given, otherwise an instant query.").
+		Param(ws.PathParameter("workspace", "Workspace name.").DataType("string").Required(true)).
+		Param(ws.QueryParameter("query", "PromQL expression, eg. `sum(rate(http_requests_total[5m])) by (pod)`. Ranges of range selectors and subqueries are limited, 1d by default.").DataType("string").Required(true)).
+		Param(ws.QueryParameter("time", "Evaluation time of instant queries, a unix timestamp in seconds or an RFC3339 time. Defaults to now.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("start", "Start time of range queries, a unix timestamp in seconds or an RFC3339 time. The range is limited, 7d by default.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("end", "End time of range queries, a unix timestamp in seconds or an RFC3339 time.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("step", "Step of range queries in seconds or a duration, eg. 30 or 1m. Points per series are limited, 1100 by default.").DataType("string").DefaultValue("10m").Required(false)).
+		Param(ws.QueryParameter("timeout", "Evaluation timeout in seconds or a duration, eg. 30s. It's limited to 30s by default.").DataType("string").Required(false)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.PromQLQueryTag}).
+		Writes(metrics.PromQLResult{}).
+		Returns(http.StatusOK, RespOK, metrics.PromQLResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.GET("/namespaces/{namespace}/promql").To(monitoring.QueryPromQL).
+		Doc("Evaluate a PromQL expression against metrics of the namespace. Every vector selector of the expression is restricted by a matcher of the namespace label. It's a range query if both start and end are given, otherwise an instant query.").
+		Param(ws.PathParameter("namespace", "The name of the namespace.").DataType("string").Required(true)).
+		Param(ws.QueryParameter("query", "PromQL expression, eg. `sum(rate(http_requests_total[5m])) by (pod)`. Ranges of range selectors and subqueries are limited, 1d by default.").DataType("string").Required(true)).
+		Param(ws.QueryParameter("time", "Evaluation time of instant queries, a unix timestamp in seconds or an RFC3339 time. Defaults to now.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("start", "Start time of range queries, a unix timestamp in seconds or an RFC3339 time. The range is limited, 7d by default.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("end", "End time of range queries, a unix timestamp in seconds or an RFC3339 time.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("step", "Step of range queries in seconds or a duration, eg. 30 or 1m. Points per series are limited, 1100 by default.").DataType("string").DefaultValue("10m").Required(false)).
+		Param(ws.QueryParameter("timeout", "Evaluation timeout in seconds or a duration, eg. 30s. It's limited to 30s by default.").DataType("string").Required(false)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.PromQLQueryTag}).
+		Writes(metrics.PromQLResult{}).
+		Returns(http.StatusOK, RespOK, metrics.PromQLResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.GET("/workspaces/{workspace}/dashboards").To(monitoring.ListWorkspaceDashboards).
+		Doc("List dashboards of the workspace.").
+		Param(ws.PathParameter("workspace", "Workspace name.").DataType("string").Required(true)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.DashboardTag}).
+		Writes(metrics.DashboardResult{}).
+		Returns(http.StatusOK, RespOK, metrics.DashboardResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.POST("/workspaces/{workspace}/dashboards").To(monitoring.CreateWorkspaceDashboard).
+		Doc("Create a dashboard in the workspace, members of the workspace share it. Panels show metric names of the workspace level, eg. workspace_memory_usage, or PromQL expressions restricted to namespaces of the workspace.").
+		Param(ws.PathParameter("workspace", "Workspace name.").DataType("string").Required(true)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.DashboardTag}).
+		Reads(metrics.Dashboard{}).
+		Writes(metrics.DashboardResult{}).
+		Returns(http.StatusOK, RespOK, metrics.DashboardResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.GET("/workspaces/{workspace}/dashboards/{dashboard}").To(monitoring.GetWorkspaceDashboard).
+		Doc("Get the dashboard of the workspace.").
+		Param(ws.PathParameter("workspace", "Workspace name.").DataType("string").Required(true)).
+		Param(ws.PathParameter("dashboard", "Name of the dashboard.").DataType("string").Required(true)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.DashboardTag}).
+		Writes(metrics.DashboardResult{}).
+		Returns(http.StatusOK, RespOK, metrics.DashboardResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.PUT("/workspaces/{workspace}/dashboards/{dashboard}").To(monitoring.UpdateWorkspaceDashboard).
+		Doc("Replace the dashboard of the workspace, its creator is kept.").
+		Param(ws.PathParameter("workspace", "Workspace name.").DataType("string").Required(true)).
+		Param(ws.PathParameter("dashboard", "Name of the dashboard.").DataType("string").Required(true)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.DashboardTag}).
+		Reads(metrics.Dashboard{}).
+		Writes(metrics.DashboardResult{}).
+		Returns(http.StatusOK, RespOK, metrics.DashboardResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.DELETE("/workspaces/{workspace}/dashboards/{dashboard}").To(monitoring.DeleteWorkspaceDashboard).
+		Doc("Delete the dashboard of the workspace.").
+		Param(ws.PathParameter("workspace", "Workspace name.").DataType("string").Required(true)).
+		Param(ws.PathParameter("dashboard", "Name of the dashboard.").DataType("string").Required(true)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.DashboardTag}).
+		Writes(metrics.DashboardResult{}).
+		Returns(http.StatusOK, RespOK, metrics.DashboardResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.GET("/workspaces/{workspace}/dashboards/{dashboard}/render").To(monitoring.RenderWorkspaceDashboard).
+		Doc("Evaluate all panels of the dashboard of the workspace concurrently. It's a range query if both start and end are given, otherwise an instant query. A failed panel doesn't fail others, its error is returned with the panel.").
+		Param(ws.PathParameter("workspace", "Workspace name.").DataType("string").Required(true)).
+		Param(ws.PathParameter("dashboard", "Name of the dashboard.").DataType("string").Required(true)).
+		Param(ws.QueryParameter("time", "Evaluation time of instant queries, a unix timestamp in seconds or an RFC3339 time. Defaults to now.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("start", "Start time of range queries, a unix timestamp in seconds or an RFC3339 time.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("end", "End time of range queries, a unix timestamp in seconds or an RFC3339 time.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("step", "Step of range queries in seconds or a duration, eg. 30 or 1m.").DataType("string").DefaultValue("10m").Required(false)).
+		Param(ws.QueryParameter("timeout", "Evaluation timeout of each panel in seconds or a duration, eg. 30s.").DataType("string").Required(false)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.DashboardTag}).
+		Writes(metrics.DashboardRenderResult{}).
+		Returns(http.StatusOK, RespOK, metrics.DashboardRenderResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.GET("/namespaces/{namespace}/dashboards").To(monitoring.ListNamespaceDashboards).
+		Doc("List dashboards of the namespace.").
+		Param(ws.PathParameter("namespace", "The name of the namespace.").DataType("string").Required(true)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.DashboardTag}).
+		Writes(metrics.DashboardResult{}).
+		Returns(http.StatusOK, RespOK, metrics.DashboardResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.POST("/namespaces/{namespace}/dashboards").To(monitoring.CreateNamespaceDashboard).
+		Doc("Create a dashboard in the namespace, members of the namespace share it. Panels show metric names of the namespace level, eg. namespace_memory_usage, or PromQL expressions restricted to namespaces of the namespace.").
+		Param(ws.PathParameter("namespace", "The name of the namespace.").DataType("string").Required(true)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.DashboardTag}).
+		Reads(metrics.Dashboard{}).
+		Writes(metrics.DashboardResult{}).
+		Returns(http.StatusOK, RespOK, metrics.DashboardResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.GET("/namespaces/{namespace}/dashboards/{dashboard}").To(monitoring.GetNamespaceDashboard).
+		Doc("Get the dashboard of the namespace.").
+		Param(ws.PathParameter("namespace", "The name of the namespace.").DataType("string").Required(true)).
+		Param(ws.PathParameter("dashboard", "Name of the dashboard.").DataType("string").Required(true)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.DashboardTag}).
+		Writes(metrics.DashboardResult{}).
+		Returns(http.StatusOK, RespOK, metrics.DashboardResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.PUT("/namespaces/{namespace}/dashboards/{dashboard}").To(monitoring.UpdateNamespaceDashboard).
+		Doc("Replace the dashboard of the namespace, its creator is kept.").
+		Param(ws.PathParameter("namespace", "The name of the namespace.").DataType("string").Required(true)).
+		Param(ws.PathParameter("dashboard", "Name of the dashboard.").DataType("string").Required(true)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.DashboardTag}).
+		Reads(metrics.Dashboard{}).
+		Writes(metrics.DashboardResult{}).
+		Returns(http.StatusOK, RespOK, metrics.DashboardResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.DELETE("/namespaces/{namespace}/dashboards/{dashboard}").To(monitoring.DeleteNamespaceDashboard).
+		Doc("Delete the dashboard of the namespace.").
+		Param(ws.PathParameter("namespace", "The name of the namespace.").DataType("string").Required(true)).
+		Param(ws.PathParameter("dashboard", "Name of the dashboard.").DataType("string").Required(true)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.DashboardTag}).
+		Writes(metrics.DashboardResult{}).
+		Returns(http.StatusOK, RespOK, metrics.DashboardResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.GET("/namespaces/{namespace}/dashboards/{dashboard}/render").To(monitoring.RenderNamespaceDashboard).
+		Doc("Evaluate all panels of the dashboard of the namespace concurrently. It's a range query if both start and end are given, otherwise an instant query. A failed panel doesn't fail others, its error is returned with the panel.").
+		Param(ws.PathParameter("namespace", "The name of the namespace.").DataType("string").Required(true)).
+		Param(ws.PathParameter("dashboard", "Name of the dashboard.").DataType("string").Required(true)).
+		Param(ws.QueryParameter("time", "Evaluation time of instant queries, a unix timestamp in seconds or an RFC3339 time. Defaults to now.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("start", "Start time of range queries, a unix timestamp in seconds or an RFC3339 time.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("end", "End time of range queries, a unix timestamp in seconds or an RFC3339 time.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("step", "Step of range queries in seconds or a duration, eg. 30 or 1m.").DataType("string").DefaultValue("10m").Required(false)).
+		Param(ws.QueryParameter("timeout", "Evaluation timeout of each panel in seconds or a duration, eg. 30s.").DataType("string").Required(false)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.DashboardTag}).
+		Writes(metrics.DashboardRenderResult{}).
+		Returns(http.StatusOK, RespOK, metrics.DashboardRenderResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.POST("/namespaces/{namespace}/alertrules/render").To(monitoring.RenderAlertRule).
+		Doc("Render the spec of an alert rule in the namespace into a PromQL expression. Rules in namespaces of tenants are restricted to namespace, workload, pod and container metrics of the namespace, other levels are only allowed in kubesphere-monitoring-system.").
+		Param(ws.PathParameter("namespace", "The name of the namespace.").DataType("string").Required(true)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.AlertingTag}).
+		Reads(alertingv1alpha1.AlertRuleSpec{}).
+		Writes(metrics.AlertRuleExpression{}).
+		Returns(http.StatusOK, RespOK, metrics.AlertRuleExpression{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.GET("/alerts").To(monitoring.ListAlerts).
+		Doc("List firing alerts of the cluster, including alerts of all workspaces and namespaces.").
+		Param(ws.QueryParameter("filter", "Matchers of alert labels, the same as filters of Alertmanager. Multiple filters are ANDed, eg. `severity=\"critical\"`.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("muted", "Whether to list silenced and inhibited alerts. Defaults to false.").DataType("boolean").DefaultValue("false").Required(false)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.AlertingTag}).
+		Writes(alerting.AlertsResult{}).
+		Returns(http.StatusOK, RespOK, alerting.AlertsResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.GET("/silences").To(monitoring.ListSilences).
+		Doc("List silences of the cluster.").
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.AlertingTag}).
+		Writes(alerting.SilencesResult{}).
+		Returns(http.StatusOK, RespOK, alerting.SilencesResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.POST("/silences").To(monitoring.CreateSilence).
+		Doc("Create a silence muting alerts of the cluster. Matchers of the tenant label are replaced with the one of the cluster.").
+		Reads(alertmanager.Silence{}).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.AlertingTag}).
+		Writes(alerting.SilencesResult{}).
+		Returns(http.StatusOK, RespOK, alerting.SilencesResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.DELETE("/silences/{silence}").To(monitoring.ExpireSilence).
+		Doc("Expire the silence of the cluster.").
+		Param(ws.PathParameter("silence", "Silence ID.").DataType("string").Required(true)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.AlertingTag}).
+		Writes(alerting.SilencesResult{}).
+		Returns(http.StatusOK, RespOK, alerting.SilencesResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.GET("/workspaces/{workspace}/alerts").To(monitoring.ListAlerts).
+		Doc("List firing alerts of the workspace.").
+		Param(ws.PathParameter("workspace", "Workspace name.").DataType("string").Required(true)).
+		Param(ws.QueryParameter("filter", "Matchers of alert labels, the same as filters of Alertmanager. Multiple filters are ANDed, eg. `severity=\"critical\"`.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("muted", "Whether to list silenced and inhibited alerts. Defaults to false.").DataType("boolean").DefaultValue("false").Required(false)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.AlertingTag}).
+		Writes(alerting.AlertsResult{}).
+		Returns(http.StatusOK, RespOK, alerting.AlertsResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.GET("/workspaces/{workspace}/silences").To(monitoring.ListSilences).
+		Doc("List silences of the workspace.").
+		Param(ws.PathParameter("workspace", "Workspace name.").DataType("string").Required(true)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.AlertingTag}).
+		Writes(alerting.SilencesResult{}).
+		Returns(http.StatusOK, RespOK, alerting.SilencesResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.POST("/workspaces/{workspace}/silences").To(monitoring.CreateSilence).
+		Doc("Create a silence muting alerts of the workspace. Matchers of the tenant label are replaced with the one of the workspace.").
+		Param(ws.PathParameter("workspace", "Workspace name.").DataType("string").Required(true)).
+		Reads(alertmanager.Silence{}).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.AlertingTag}).
+		Writes(alerting.SilencesResult{}).
+		Returns(http.StatusOK, RespOK, alerting.SilencesResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.DELETE("/workspaces/{workspace}/silences/{silence}").To(monitoring.ExpireSilence).
+		Doc("Expire the silence of the workspace.").
+		Param(ws.PathParameter("workspace", "Workspace name.").DataType("string").Required(true)).
+		Param(ws.PathParameter("silence", "Silence ID.").DataType("string").Required(true)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.AlertingTag}).
+		Writes(alerting.SilencesResult{}).
+		Returns(http.StatusOK, RespOK, alerting.SilencesResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.GET("/namespaces/{namespace}/alerts").To(monitoring.ListAlerts).
+		Doc("List firing alerts of the namespace.").
+		Param(ws.PathParameter("namespace", "The name of the namespace.").DataType("string").Required(true)).
+		Param(ws.QueryParameter("filter", "Matchers of alert labels, the same as filters of Alertmanager. Multiple filters are ANDed, eg. `severity=\"critical\"`.").DataType("string").Required(false)).
+		Param(ws.QueryParameter("muted", "Whether to list silenced and inhibited alerts. Defaults to false.").DataType("boolean").DefaultValue("false").Required(false)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.AlertingTag}).
+		Writes(alerting.AlertsResult{}).
+		Returns(http.StatusOK, RespOK, alerting.AlertsResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.GET("/namespaces/{namespace}/silences").To(monitoring.ListSilences).
+		Doc("List silences of the namespace.").
+		Param(ws.PathParameter("namespace", "The name of the namespace.").DataType("string").Required(true)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.AlertingTag}).
+		Writes(alerting.SilencesResult{}).
+		Returns(http.StatusOK, RespOK, alerting.SilencesResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.POST("/namespaces/{namespace}/silences").To(monitoring.CreateSilence).
+		Doc("Create a silence muting alerts of the namespace. Matchers of the tenant label are replaced with the one of the namespace.").
+		Param(ws.PathParameter("namespace", "The name of the namespace.").DataType("string").Required(true)).
+		Reads(alertmanager.Silence{}).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.AlertingTag}).
+		Writes(alerting.SilencesResult{}).
+		Returns(http.StatusOK, RespOK, alerting.SilencesResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.DELETE("/namespaces/{namespace}/silences/{silence}").To(monitoring.ExpireSilence).
+		Doc("Expire the silence of the namespace.").
+		Param(ws.PathParameter("namespace", "The name of the namespace.").DataType("string").Required(true)).
+		Param(ws.PathParameter("silence", "Silence ID.").DataType("string").Required(true)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.AlertingTag}).
+		Writes(alerting.SilencesResult{}).
+		Returns(http.StatusOK, RespOK, alerting.SilencesResult{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON)
+
+	ws.Route(ws.GET("/metering").To(monitoring.GetMeteringReport).
+		Doc("Get resource usage and costs of workspaces, namespaces or workloads of the cluster per day or month. Usage is rolled up daily, costs are priced by the price sheet in the configmap metering-price-sheet of kubesphere-system.").
+		Param(ws.QueryParameter("start", "First day of the report in UTC, eg. 2019-06-01.").DataType("string").Required(true)).
+		Param(ws.QueryParameter("end", "Last day of the report in UTC, eg. 2019-06-30. A report covers at most 366 days.").DataType("string").Required(true)).
+		Param(ws.QueryParameter("period", "Period of records, one of daily and monthly.").DataType("string").DefaultValue(metrics.MeteringPeriodDaily).Required(false)).
+		Param(ws.QueryParameter("level", "Level of records, one of workspace, namespace and workload.").DataType("string").DefaultValue("workspace").Required(false)).
+		Param(ws.QueryParameter("format", "json or csv.").DataType("string").DefaultValue(monitoring.FormatJSON).Required(false)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.MeteringTag}).
+		Writes(metrics.MeteringReport{}).
+		Returns(http.StatusOK, RespOK, metrics.MeteringReport{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON, monitoring.MIME_CSV)
+
+	ws.Route(ws.GET("/workspaces/{workspace}/metering").To(monitoring.GetMeteringReport).
+		Doc("Get resource usage and costs of the workspace, its namespaces or workloads per day or month. Namespaces are counted in the workspace they belonged to when they were metered.").
+		Param(ws.PathParameter("workspace", "Workspace name.").DataType("string").Required(true)).
+		Param(ws.QueryParameter("start", "First day of the report in UTC, eg. 2019-06-01.").DataType("string").Required(true)).
+		Param(ws.QueryParameter("end", "Last day of the report in UTC, eg. 2019-06-30. A report covers at most 366 days.").DataType("string").Required(true)).
+		Param(ws.QueryParameter("period", "Period of records, one of daily and monthly.").DataType("string").DefaultValue(metrics.MeteringPeriodDaily).Required(false)).
+		Param(ws.QueryParameter("level", "Level of records, one of workspace, namespace and workload.").DataType("string").DefaultValue("workspace").Required(false)).
+		Param(ws.QueryParameter("format", "json or csv.").DataType("string").DefaultValue(monitoring.FormatJSON).Required(false)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.MeteringTag}).
+		Writes(metrics.MeteringReport{}).
+		Returns(http.StatusOK, RespOK, metrics.MeteringReport{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON, monitoring.MIME_CSV)
+
+	ws.Route(ws.GET("/namespaces/{namespace}/metering").To(monitoring.GetMeteringReport).
+		Doc("Get resource usage and costs of the namespace or its workloads per day or month. Storage and GPUs are only metered at the namespace level.").
+		Param(ws.PathParameter("namespace", "The name of the namespace.").DataType("string").Required(true)).
+		Param(ws.QueryParameter("start", "First day of the report in UTC, eg. 2019-06-01.").DataType("string").Required(true)).
+		Param(ws.QueryParameter("end", "Last day of the report in UTC, eg. 2019-06-30. A report covers at most 366 days.").DataType("string").Required(true)).
+		Param(ws.QueryParameter("period", "Period of records, one of daily and monthly.").DataType("string").DefaultValue(metrics.MeteringPeriodDaily).Required(false)).
+		Param(ws.QueryParameter("level", "Level of records, one of namespace and workload.").DataType("string").DefaultValue("namespace").Required(false)).
+		Param(ws.QueryParameter("format", "json or csv.").DataType("string").DefaultValue(monitoring.FormatJSON).Required(false)).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.MeteringTag}).
+		Writes(metrics.MeteringReport{}).
+		Returns(http.StatusOK, RespOK, metrics.MeteringReport{})).
+		Consumes(restful.MIME_JSON, restful.MIME_XML).
+		Produces(restful.MIME_JSON, monitoring.MIME_CSV)
+
 	c.Add(ws)
 	return nil
 }
@@ -39,6 +39,7 @@ import (
 	"kubesphere.io/kubesphere/pkg/models/applications"
 	gitmodel "kubesphere.io/kubesphere/pkg/models/git"
 	registriesmodel "kubesphere.io/kubesphere/pkg/models/registries"
+	modelsresources "kubesphere.io/kubesphere/pkg/models/resources"
 	"kubesphere.io/kubesphere/pkg/models/status"
 	"kubesphere.io/kubesphere/pkg/params"
 	"kubesphere.io/kubesphere/pkg/simple/client/openpitrix"
@@ -65,18 +66,50 @@ func addWebService(c *restful.Container) error {
 		Metadata(restfulspec.KeyOpenAPITags, []string{constants.NamespaceResourcesTag}).
 		Doc("Namespace level resource query").
 		Param(webservice.PathParameter("namespace", "the name of the project")).
-		Param(webservice.PathParameter("resources", "namespace level resource type, e.g. pods,jobs,configmaps,services.")).
+		Param(webservice.PathParameter("resources", "namespace level resource type, e.g. pods,jobs,configmaps,services, resources registered by --cached-resources are searched by <resource>.<group> or plural name.")).
 		Param(webservice.QueryParameter(params.ConditionsParam, "query conditions,connect multiple conditions with commas, equal symbol for exact query, wave symbol for fuzzy query e.g. name~a").
 			Required(false).
 			DataFormat("key=%s,key~%s")).
+		Param(webservice.QueryParameter(params.FilterParam, "filter expression evaluated after conditions, supports =, !=, ~, !~, >, >=, <, <=, in, notin, existence, && (or comma), || and !, e.g. labels.app in (web, api), status!=stopped || createTime>=2019-06-01").
+			Required(false)).
 		Param(webservice.QueryParameter(params.PagingParam, "paging query, e.g. limit=100,page=1").
 			Required(false).
 			DataFormat("limit=%d,page=%d").
 			DefaultValue("limit=10,page=1")).
 		Param(webservice.QueryParameter(params.ReverseParam, "sort parameters, e.g. reverse=true")).
 		Param(webservice.QueryParameter(params.OrderByParam, "sort parameters, e.g. orderBy=createTime")).
+		Param(webservice.QueryParameter(params.LimitParam, "page size of cursor pagination, takes precedence over paging, the continue token of the next page is returned if there are more items").
+			Required(false).
+			DataFormat("%d")).
+		Param(webservice.QueryParameter(params.ContinueParam, "continue token returned by the previous page, pages are listed from the same snapshot and expire after 5 minutes of inactivity").
+			Required(false)).
+		Param(webservice.QueryParameter(params.FieldsParam, "comma separated paths of fields to return, e.g. metadata.name,status.phase").
+			Required(false)).
+		Param(webservice.QueryParameter(params.OutputParam, "output format, table returns rows of fields, e.g. output=table&fields=name,status,spec.nodeName").
+			Required(false)).
+		Param(webservice.QueryParameter(params.WatchParam, "watch resources matching conditions and filter, events of ADDED, MODIFIED and DELETED are streamed as json separated by newlines, existing resources are sent as ADDED first").
+			Required(false).
+			DataFormat("watch=true")).
+		Param(webservice.QueryParameter(params.TimeoutParam, "timeout of watch in seconds, default 1800").
+			Required(false)).
 		Returns(http.StatusOK, ok, models.PageableResponse{}))
 
+	webservice.Route(webservice.GET("/namespaces/{namespace}/topology").
+		To(resources.GetNamespaceTopology).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.NamespaceResourcesTag}).
+		Doc("Graph of workloads, pods, services, ingresses and HPAs of the namespace, with the config maps, secrets and persistent volume claims used by pods. Edges are owns, selects, routes, mounts, references and scales, nodes are annotated with health.").
+		Param(webservice.PathParameter("namespace", "the name of the project")).
+		Returns(http.StatusOK, ok, modelsresources.Topology{}))
+
+	webservice.Route(webservice.GET("/namespaces/{namespace}/{resources}/{name}/topology").
+		To(resources.GetTopology).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.NamespaceResourcesTag}).
+		Doc("Graph of the objects related to the specified resource: owners, owned objects, services selecting its pods, ingresses routing to those services, objects used by its pods and HPAs.").
+		Param(webservice.PathParameter("namespace", "the name of the project")).
+		Param(webservice.PathParameter("resources", "deployments, replicasets, statefulsets, daemonsets, jobs, pods, services or ingresses")).
+		Param(webservice.PathParameter("name", "the name of the resource")).
+		Returns(http.StatusOK, ok, modelsresources.Topology{}))
+
 	webservice.Route(webservice.POST("/namespaces/{namespace}/jobs/{job}").
 		To(operations.RerunJob).
 		Metadata(restfulspec.KeyOpenAPITags, []string{constants.NamespaceResourcesTag}).
@@ -96,12 +129,28 @@ func addWebService(c *restful.Container) error {
 			Required(false).
 			DataFormat("key=value,key~value").
 			DefaultValue("")).
+		Param(webservice.QueryParameter(params.FilterParam, "filter expression evaluated after conditions, supports =, !=, ~, !~, >, >=, <, <=, in, notin, existence, && (or comma), || and !, e.g. labels.app in (web, api), status!=stopped || createTime>=2019-06-01").
+			Required(false)).
 		Param(webservice.QueryParameter(params.PagingParam, "paging query, e.g. limit=100,page=1").
 			Required(false).
 			DataFormat("limit=%d,page=%d").
 			DefaultValue("limit=10,page=1")).
 		Param(webservice.QueryParameter(params.ReverseParam, "sort parameters, e.g. reverse=true")).
-		Param(webservice.QueryParameter(params.OrderByParam, "sort parameters, e.g. orderBy=createTime")))
+		Param(webservice.QueryParameter(params.OrderByParam, "sort parameters, e.g. orderBy=createTime")).
+		Param(webservice.QueryParameter(params.LimitParam, "page size of cursor pagination, takes precedence over paging, the continue token of the next page is returned if there are more items").
+			Required(false).
+			DataFormat("%d")).
+		Param(webservice.QueryParameter(params.ContinueParam, "continue token returned by the previous page, pages are listed from the same snapshot and expire after 5 minutes of inactivity").
+			Required(false)).
+		Param(webservice.QueryParameter(params.FieldsParam, "comma separated paths of fields to return, e.g. metadata.name,status.phase").
+			Required(false)).
+		Param(webservice.QueryParameter(params.OutputParam, "output format, table returns rows of fields, e.g. output=table&fields=name,status,spec.nodeName").
+			Required(false)).
+		Param(webservice.QueryParameter(params.WatchParam, "watch resources matching conditions and filter, events of ADDED, MODIFIED and DELETED are streamed as json separated by newlines, existing resources are sent as ADDED first").
+			Required(false).
+			DataFormat("watch=true")).
+		Param(webservice.QueryParameter(params.TimeoutParam, "timeout of watch in seconds, default 1800").
+			Required(false)))
 
 	webservice.Route(webservice.POST("/nodes/{node}/drainage").
 		To(operations.DrainNode).
@@ -198,7 +198,7 @@ func addWebService(c *restful.Container) error {
 	// Get service tracing
 	webservice.Route(webservice.GET("/namespaces/{namespace}/services/{service}/traces").
 		To(tracing.GetServiceTracing).
-		Doc("Get tracing of a service, should have servicemesh enabled first").
+		Doc("Get tracing of a service, should have servicemesh enabled first. Logs of a trace are queried by its traceID with /namespaces/{namespace}/traces/{trace} of the logging API").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
 		Param(webservice.PathParameter("namespace", "namespace of service").Required(true)).
 		Param(webservice.PathParameter("service", "name of service queried").Required(true)).
@@ -175,6 +175,20 @@ func addWebService(c *restful.Container) error {
 		Consumes(restful.MIME_JSON, restful.MIME_XML).
 		Produces(restful.MIME_JSON)
 
+	ws.Route(ws.GET("/search").
+		To(tenant.Search).
+		Doc("Search resources of all kinds in namespaces, workspaces and devops projects that the current user can see, by names, display names and labels").
+		Param(ws.QueryParameter("q", "words to search, results contain all of them as words or prefixes of words, e.g. payments").
+			Required(true)).
+		Param(ws.QueryParameter("kinds", "comma separated kinds of results, e.g. deployments,services,pipelines,applications, empty means all").
+			Required(false)).
+		Param(ws.QueryParameter(params.PagingParam, "paging query, e.g. limit=100,page=1").
+			Required(false).
+			DataFormat("limit=%d,page=%d").
+			DefaultValue("limit=10,page=1")).
+		Returns(http.StatusOK, ok, models.PageableResponse{}).
+		Metadata(restfulspec.KeyOpenAPITags, []string{constants.TenantResourcesTag}))
+
 	c.Add(ws)
 	return nil
 }
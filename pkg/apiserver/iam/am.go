@@ -28,6 +28,7 @@ import (
 	"kubesphere.io/kubesphere/pkg/errors"
 	"kubesphere.io/kubesphere/pkg/models/iam"
 	"kubesphere.io/kubesphere/pkg/models/iam/policy"
+	"kubesphere.io/kubesphere/pkg/utils/k8sutil"
 )
 
 type RoleList struct {
@@ -116,16 +117,16 @@ func ListNamespaceUsers(req *restful.Request, resp *restful.Response) {
 
 func ListUserRoles(req *restful.Request, resp *restful.Response) {
 
-	username := req.PathParameter("user")
+	identity := k8sutil.NewIdentity(req.PathParameter("user"), v1.UserKind)
 
-	roles, err := iam.GetUserRoles("", username)
+	roles, err := iam.GetUserRoles("", identity)
 
 	if err != nil {
 		resp.WriteHeaderAndEntity(http.StatusInternalServerError, errors.Wrap(err))
 		return
 	}
 
-	_, clusterRoles, err := iam.GetUserClusterRoles(username)
+	_, clusterRoles, err := iam.GetUserClusterRoles(identity)
 
 	if err != nil {
 		resp.WriteHeaderAndEntity(http.StatusInternalServerError, errors.Wrap(err))
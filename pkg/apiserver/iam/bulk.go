@@ -0,0 +1,101 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package iam
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/emicklei/go-restful"
+	"github.com/golang/glog"
+
+	"kubesphere.io/kubesphere/pkg/errors"
+	"kubesphere.io/kubesphere/pkg/models/iam/bulk"
+)
+
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+	MIME_CSV   = "text/csv"
+)
+
+func ExportUsers(req *restful.Request, resp *restful.Response) {
+	format := req.QueryParameter("format")
+
+	if format == "" {
+		format = FormatJSON
+	}
+
+	if format != FormatJSON && format != FormatCSV {
+		resp.WriteHeaderAndEntity(http.StatusBadRequest, errors.Wrap(fmt.Errorf("unsupported format %s", format)))
+		return
+	}
+
+	doc, err := bulk.Export()
+
+	if err != nil {
+		glog.Error(err)
+		resp.WriteHeaderAndEntity(http.StatusInternalServerError, errors.Wrap(err))
+		return
+	}
+
+	if format == FormatJSON {
+		resp.WriteAsJson(doc)
+		return
+	}
+
+	resp.Header().Set("Content-Type", MIME_CSV)
+	resp.Header().Set("Content-Disposition", "attachment; filename=users.csv")
+
+	if err := bulk.WriteCSV(resp, doc); err != nil {
+		glog.Error(err)
+	}
+}
+
+func ImportUsers(req *restful.Request, resp *restful.Response) {
+	format := req.QueryParameter("format")
+
+	if format == "" {
+		format = FormatJSON
+		if strings.HasPrefix(req.HeaderParameter("Content-Type"), MIME_CSV) {
+			format = FormatCSV
+		}
+	}
+
+	var doc *bulk.Document
+	var err error
+
+	switch format {
+	case FormatCSV:
+		doc, err = bulk.ReadCSV(req.Request.Body)
+	case FormatJSON:
+		doc = &bulk.Document{}
+		err = req.ReadEntity(doc)
+	default:
+		err = fmt.Errorf("unsupported format %s", format)
+	}
+
+	if err != nil {
+		glog.Info(err)
+		resp.WriteHeaderAndEntity(http.StatusBadRequest, errors.Wrap(err))
+		return
+	}
+
+	resp.WriteAsJson(bulk.Import(doc))
+}
@@ -51,6 +51,8 @@ func CreateGroup(req *restful.Request, resp *restful.Response) {
 	if err != nil {
 		if ldap.IsErrorWithCode(err, ldap.LDAPResultEntryAlreadyExists) {
 			resp.WriteHeaderAndEntity(http.StatusConflict, errors.Wrap(err))
+		} else if ldap.IsErrorWithCode(err, ldap.LDAPResultUnwillingToPerform) {
+			resp.WriteHeaderAndEntity(http.StatusForbidden, errors.Wrap(err))
 		} else {
 			resp.WriteHeaderAndEntity(http.StatusInternalServerError, errors.Wrap(err))
 		}
@@ -75,6 +77,10 @@ func DeleteGroup(req *restful.Request, resp *restful.Response) {
 			resp.WriteHeaderAndEntity(http.StatusNotFound, errors.Wrap(err))
 			return
 		}
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultUnwillingToPerform) {
+			resp.WriteHeaderAndEntity(http.StatusForbidden, errors.Wrap(err))
+			return
+		}
 		resp.WriteHeaderAndEntity(http.StatusInternalServerError, errors.Wrap(err))
 		return
 	}
@@ -98,6 +104,10 @@ func UpdateGroup(req *restful.Request, resp *restful.Response) {
 	edited, err := iam.UpdateGroup(&group)
 
 	if err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultUnwillingToPerform) {
+			resp.WriteHeaderAndEntity(http.StatusForbidden, errors.Wrap(err))
+			return
+		}
 		resp.WriteHeaderAndEntity(http.StatusInternalServerError, errors.Wrap(err))
 		return
 	}
@@ -28,10 +28,12 @@ import (
 	"github.com/emicklei/go-restful"
 	"github.com/go-ldap/ldap"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
 	"kubesphere.io/kubesphere/pkg/constants"
 	"kubesphere.io/kubesphere/pkg/errors"
 	"kubesphere.io/kubesphere/pkg/models"
 	"kubesphere.io/kubesphere/pkg/models/iam"
+	"kubesphere.io/kubesphere/pkg/utils/k8sutil"
 )
 
 const (
@@ -56,6 +58,12 @@ func CreateUser(req *restful.Request, resp *restful.Response) {
 		return
 	}
 
+	if err := iam.ValidateUsername(user.Username); err != nil {
+		glog.Info(err)
+		resp.WriteHeaderAndEntity(http.StatusBadRequest, errors.Wrap(err))
+		return
+	}
+
 	if !regexp.MustCompile(emailRegex).MatchString(user.Email) {
 		err = fmt.Errorf("invalid email: %s", user.Email)
 		glog.Info(err, user.Email)
@@ -73,6 +81,11 @@ func CreateUser(req *restful.Request, resp *restful.Response) {
 	created, err := iam.CreateUser(&user)
 
 	if err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultUnwillingToPerform) {
+			glog.Info(err)
+			resp.WriteHeaderAndEntity(http.StatusForbidden, errors.Wrap(err))
+			return
+		}
 		if ldap.IsErrorWithCode(err, ldap.LDAPResultEntryAlreadyExists) {
 			glog.Info(err)
 			resp.WriteHeaderAndEntity(http.StatusConflict, errors.Wrap(err))
@@ -101,6 +114,11 @@ func DeleteUser(req *restful.Request, resp *restful.Response) {
 	err := iam.DeleteUser(username)
 
 	if err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultUnwillingToPerform) {
+			glog.Info(err)
+			resp.WriteHeaderAndEntity(http.StatusForbidden, errors.Wrap(err))
+			return
+		}
 		glog.Error(err)
 		resp.WriteHeaderAndEntity(http.StatusInternalServerError, errors.Wrap(err))
 		return
@@ -146,7 +164,7 @@ func UpdateUser(req *restful.Request, resp *restful.Response) {
 
 	// change password by self
 	if usernameInHeader == user.Username && user.Password != "" {
-		isUserManager, err := isUserManager(usernameInHeader)
+		isUserManager, err := isUserManager(params.ParseIdentity(req))
 		if err != nil {
 			glog.Error(err)
 			resp.WriteHeaderAndEntity(http.StatusInternalServerError, errors.Wrap(err))
@@ -166,6 +184,11 @@ func UpdateUser(req *restful.Request, resp *restful.Response) {
 	result, err := iam.UpdateUser(&user)
 
 	if err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultUnwillingToPerform) {
+			glog.Info(err)
+			resp.WriteHeaderAndEntity(http.StatusForbidden, errors.Wrap(err))
+			return
+		}
 		if ldap.IsErrorWithCode(err, ldap.LDAPResultEntryAlreadyExists) {
 			glog.Info(err)
 			resp.WriteHeaderAndEntity(http.StatusConflict, errors.Wrap(err))
@@ -180,8 +203,8 @@ func UpdateUser(req *restful.Request, resp *restful.Response) {
 	resp.WriteAsJson(result)
 }
 
-func isUserManager(username string) (bool, error) {
-	rules, err := iam.GetUserClusterRules(username)
+func isUserManager(usr user.Info) (bool, error) {
+	rules, err := iam.GetUserClusterRules(usr)
 	if err != nil {
 		return false, err
 	}
@@ -233,7 +256,7 @@ func DescribeUser(req *restful.Request, resp *restful.Response) {
 		return
 	}
 
-	clusterRole, err := iam.GetUserClusterRole(username)
+	clusterRole, err := iam.GetUserClusterRole(k8sutil.NewIdentity(username, rbacv1.UserKind))
 
 	if err != nil {
 		glog.Error(err)
@@ -243,7 +266,7 @@ func DescribeUser(req *restful.Request, resp *restful.Response) {
 
 	user.ClusterRole = clusterRole.Name
 
-	clusterRules, err := iam.GetUserClusterSimpleRules(username)
+	clusterRules, err := iam.GetUserClusterSimpleRules(k8sutil.NewIdentity(username, rbacv1.UserKind))
 
 	if err != nil {
 		glog.Error(err)
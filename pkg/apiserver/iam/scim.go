@@ -0,0 +1,270 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package iam
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/emicklei/go-restful"
+	"github.com/go-ldap/ldap"
+	"github.com/golang/glog"
+
+	"kubesphere.io/kubesphere/pkg/models/iam/scim"
+)
+
+func init() {
+	restful.RegisterEntityAccessor(scim.MediaType, restful.NewEntityAccessorJSON(scim.MediaType))
+}
+
+func SCIMServiceProviderConfig(req *restful.Request, resp *restful.Response) {
+	resp.WriteHeaderAndJson(http.StatusOK, scim.ServiceProvider(), scim.MediaType)
+}
+
+func SCIMListUsers(req *restful.Request, resp *restful.Response) {
+	startIndex, count, err := scimPaging(req)
+
+	if err != nil {
+		writeSCIMError(resp, err)
+		return
+	}
+
+	result, err := scim.ListUsers(req.QueryParameter("filter"), startIndex, count)
+
+	if err != nil {
+		writeSCIMError(resp, err)
+		return
+	}
+
+	resp.WriteHeaderAndJson(http.StatusOK, result, scim.MediaType)
+}
+
+func SCIMGetUser(req *restful.Request, resp *restful.Response) {
+	user, err := scim.GetUser(req.PathParameter("id"))
+
+	if err != nil {
+		writeSCIMError(resp, err)
+		return
+	}
+
+	resp.WriteHeaderAndJson(http.StatusOK, user, scim.MediaType)
+}
+
+func SCIMCreateUser(req *restful.Request, resp *restful.Response) {
+	var user scim.User
+
+	if err := req.ReadEntity(&user); err != nil {
+		writeSCIMError(resp, scim.NewError(http.StatusBadRequest, "invalidSyntax", err.Error()))
+		return
+	}
+
+	created, err := scim.CreateUser(&user)
+
+	if err != nil {
+		writeSCIMError(resp, err)
+		return
+	}
+
+	resp.WriteHeaderAndJson(http.StatusCreated, created, scim.MediaType)
+}
+
+func SCIMReplaceUser(req *restful.Request, resp *restful.Response) {
+	var user scim.User
+
+	if err := req.ReadEntity(&user); err != nil {
+		writeSCIMError(resp, scim.NewError(http.StatusBadRequest, "invalidSyntax", err.Error()))
+		return
+	}
+
+	replaced, err := scim.ReplaceUser(req.PathParameter("id"), &user)
+
+	if err != nil {
+		writeSCIMError(resp, err)
+		return
+	}
+
+	resp.WriteHeaderAndJson(http.StatusOK, replaced, scim.MediaType)
+}
+
+func SCIMPatchUser(req *restful.Request, resp *restful.Response) {
+	var patch scim.PatchOp
+
+	if err := req.ReadEntity(&patch); err != nil {
+		writeSCIMError(resp, scim.NewError(http.StatusBadRequest, "invalidSyntax", err.Error()))
+		return
+	}
+
+	patched, err := scim.PatchUser(req.PathParameter("id"), &patch)
+
+	if err != nil {
+		writeSCIMError(resp, err)
+		return
+	}
+
+	resp.WriteHeaderAndJson(http.StatusOK, patched, scim.MediaType)
+}
+
+func SCIMDeleteUser(req *restful.Request, resp *restful.Response) {
+	if err := scim.DeleteUser(req.PathParameter("id")); err != nil {
+		writeSCIMError(resp, err)
+		return
+	}
+
+	resp.WriteHeader(http.StatusNoContent)
+}
+
+func SCIMListGroups(req *restful.Request, resp *restful.Response) {
+	startIndex, count, err := scimPaging(req)
+
+	if err != nil {
+		writeSCIMError(resp, err)
+		return
+	}
+
+	result, err := scim.ListGroups(req.QueryParameter("filter"), startIndex, count)
+
+	if err != nil {
+		writeSCIMError(resp, err)
+		return
+	}
+
+	resp.WriteHeaderAndJson(http.StatusOK, result, scim.MediaType)
+}
+
+func SCIMGetGroup(req *restful.Request, resp *restful.Response) {
+	group, err := scim.GetGroup(req.PathParameter("id"))
+
+	if err != nil {
+		writeSCIMError(resp, err)
+		return
+	}
+
+	resp.WriteHeaderAndJson(http.StatusOK, group, scim.MediaType)
+}
+
+func SCIMCreateGroup(req *restful.Request, resp *restful.Response) {
+	var group scim.Group
+
+	if err := req.ReadEntity(&group); err != nil {
+		writeSCIMError(resp, scim.NewError(http.StatusBadRequest, "invalidSyntax", err.Error()))
+		return
+	}
+
+	created, err := scim.CreateGroup(&group)
+
+	if err != nil {
+		writeSCIMError(resp, err)
+		return
+	}
+
+	resp.WriteHeaderAndJson(http.StatusCreated, created, scim.MediaType)
+}
+
+func SCIMReplaceGroup(req *restful.Request, resp *restful.Response) {
+	var group scim.Group
+
+	if err := req.ReadEntity(&group); err != nil {
+		writeSCIMError(resp, scim.NewError(http.StatusBadRequest, "invalidSyntax", err.Error()))
+		return
+	}
+
+	replaced, err := scim.ReplaceGroup(req.PathParameter("id"), &group)
+
+	if err != nil {
+		writeSCIMError(resp, err)
+		return
+	}
+
+	resp.WriteHeaderAndJson(http.StatusOK, replaced, scim.MediaType)
+}
+
+func SCIMPatchGroup(req *restful.Request, resp *restful.Response) {
+	var patch scim.PatchOp
+
+	if err := req.ReadEntity(&patch); err != nil {
+		writeSCIMError(resp, scim.NewError(http.StatusBadRequest, "invalidSyntax", err.Error()))
+		return
+	}
+
+	patched, err := scim.PatchGroup(req.PathParameter("id"), &patch)
+
+	if err != nil {
+		writeSCIMError(resp, err)
+		return
+	}
+
+	resp.WriteHeaderAndJson(http.StatusOK, patched, scim.MediaType)
+}
+
+func SCIMDeleteGroup(req *restful.Request, resp *restful.Response) {
+	if err := scim.DeleteGroup(req.PathParameter("id")); err != nil {
+		writeSCIMError(resp, err)
+		return
+	}
+
+	resp.WriteHeader(http.StatusNoContent)
+}
+
+// scimPaging parses startIndex and count, startIndex is 1-based
+func scimPaging(req *restful.Request) (int, int, error) {
+	startIndex, count := 1, scim.MaxResults
+
+	if value := req.QueryParameter("startIndex"); value != "" {
+		i, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, 0, scim.NewError(http.StatusBadRequest, "invalidValue", "invalid startIndex")
+		}
+		if i > 1 {
+			startIndex = i
+		}
+	}
+
+	if value := req.QueryParameter("count"); value != "" {
+		c, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, 0, scim.NewError(http.StatusBadRequest, "invalidValue", "invalid count")
+		}
+		if c >= 0 && c < count {
+			count = c
+		}
+	}
+
+	return startIndex, count, nil
+}
+
+func writeSCIMError(resp *restful.Response, err error) {
+	scimErr, ok := err.(*scim.Error)
+
+	if !ok {
+		switch {
+		case ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject):
+			scimErr = scim.NewError(http.StatusNotFound, "", err.Error())
+		case ldap.IsErrorWithCode(err, ldap.LDAPResultEntryAlreadyExists):
+			scimErr = scim.NewError(http.StatusConflict, "uniqueness", err.Error())
+		case ldap.IsErrorWithCode(err, ldap.LDAPResultUnwillingToPerform):
+			scimErr = scim.NewError(http.StatusForbidden, "", err.Error())
+		default:
+			glog.Error(err)
+			scimErr = scim.NewError(http.StatusInternalServerError, "", err.Error())
+		}
+	}
+
+	status, _ := strconv.Atoi(scimErr.Status)
+
+	resp.WriteHeaderAndJson(status, scimErr, scim.MediaType)
+}
@@ -19,6 +19,7 @@ package iam
 
 import (
 	"github.com/emicklei/go-restful"
+	rbacv1 "k8s.io/api/rbac/v1"
 	k8serr "k8s.io/apimachinery/pkg/api/errors"
 	"kubesphere.io/kubesphere/pkg/constants"
 	"kubesphere.io/kubesphere/pkg/errors"
@@ -26,6 +27,7 @@ import (
 	"kubesphere.io/kubesphere/pkg/models/iam"
 	"kubesphere.io/kubesphere/pkg/models/workspaces"
 	"kubesphere.io/kubesphere/pkg/params"
+	"kubesphere.io/kubesphere/pkg/utils/k8sutil"
 	"net/http"
 )
 
@@ -79,7 +81,7 @@ func DescribeWorkspaceUser(req *restful.Request, resp *restful.Response) {
 	workspace := req.PathParameter("workspace")
 	username := req.PathParameter("member")
 
-	workspaceRole, err := iam.GetUserWorkspaceRole(workspace, username)
+	workspaceRole, err := iam.GetUserWorkspaceRole(workspace, k8sutil.NewIdentity(username, rbacv1.UserKind))
 
 	if err != nil {
 		if k8serr.IsNotFound(err) {
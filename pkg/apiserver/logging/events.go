@@ -0,0 +1,124 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+
+package logging
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/emicklei/go-restful"
+
+	"kubesphere.io/kubesphere/pkg/errors"
+	"kubesphere.io/kubesphere/pkg/models/log"
+	es "kubesphere.io/kubesphere/pkg/simple/client/elasticsearch"
+)
+
+func LoggingQueryClusterEvents(request *restful.Request, response *restful.Response) {
+	queryEvents(log.QueryLevelCluster, request, response)
+}
+
+func LoggingQueryWorkspaceEvents(request *restful.Request, response *restful.Response) {
+	queryEvents(log.QueryLevelWorkspace, request, response)
+}
+
+func LoggingQueryNamespaceEvents(request *restful.Request, response *restful.Response) {
+	queryEvents(log.QueryLevelNamespace, request, response)
+}
+
+func LoggingQueryWorkloadEvents(request *restful.Request, response *restful.Response) {
+	queryEvents(log.QueryLevelWorkload, request, response)
+}
+
+func queryEvents(level log.LogQueryLevel, request *restful.Request, response *restful.Response) {
+	res := es.QueryEvents(parseEventQueryParameters(level, request))
+
+	if res.Status != http.StatusOK {
+		response.WriteHeaderAndEntity(res.Status, errors.New(res.Error))
+		return
+	}
+
+	response.WriteAsJson(res)
+}
+
+func parseEventQueryParameters(level log.LogQueryLevel, request *restful.Request) es.EventQueryParameters {
+	var param es.EventQueryParameters
+
+	var namespaces []string
+
+	switch level {
+	case log.QueryLevelCluster:
+		param.NamespaceFilled, namespaces = log.QueryWorkspace(request.QueryParameter("workspaces"), request.QueryParameter("workspace_query"))
+		param.NamespaceFilled, namespaces = log.MatchNamespace(request.QueryParameter("namespaces"), param.NamespaceFilled, namespaces)
+		param.NamespaceQuery = request.QueryParameter("namespace_query")
+		param.WorkloadFilled, param.Workloads = splitFilter(request.QueryParameter("workloads"))
+		param.WorkloadQuery = request.QueryParameter("workload_query")
+	case log.QueryLevelWorkspace:
+		param.NamespaceFilled, namespaces = log.QueryWorkspace(request.PathParameter("workspace"), "")
+		param.NamespaceFilled, namespaces = log.MatchNamespace(request.QueryParameter("namespaces"), param.NamespaceFilled, namespaces)
+		param.NamespaceQuery = request.QueryParameter("namespace_query")
+		param.WorkloadFilled, param.Workloads = splitFilter(request.QueryParameter("workloads"))
+		param.WorkloadQuery = request.QueryParameter("workload_query")
+	case log.QueryLevelNamespace:
+		param.NamespaceFilled, namespaces = log.MatchNamespace(request.PathParameter("namespace"), false, nil)
+		param.WorkloadFilled, param.Workloads = splitFilter(request.QueryParameter("workloads"))
+		param.WorkloadQuery = request.QueryParameter("workload_query")
+	case log.QueryLevelWorkload:
+		param.NamespaceFilled, namespaces = log.MatchNamespace(request.PathParameter("namespace"), false, nil)
+		param.WorkloadFilled, param.Workloads = splitFilter(request.PathParameter("workload"))
+	}
+
+	// events of cluster scoped objects, eg. nodes, are only left out if namespaces are filtered
+	if param.NamespaceFilled && len(namespaces) > 0 {
+		_, param.NamespaceWithCreationTime = log.GetNamespaceCreationTimeMap(namespaces)
+	}
+
+	_, param.InvolvedObjectKinds = splitFilter(request.QueryParameter("involved_object_kinds"))
+	_, param.InvolvedObjectNames = splitFilter(request.QueryParameter("involved_object_names"))
+	_, param.Reasons = splitFilter(request.QueryParameter("reasons"))
+	_, param.Types = splitFilter(request.QueryParameter("types"))
+	param.MessageQuery = request.QueryParameter("message_query")
+
+	param.Operation = request.QueryParameter("operation")
+	param.Interval = request.QueryParameter("interval")
+	param.StartTime = request.QueryParameter("start_time")
+	param.EndTime = request.QueryParameter("end_time")
+	param.Sort = request.QueryParameter("sort")
+
+	var err error
+	param.From, err = strconv.ParseInt(request.QueryParameter("from"), 10, 64)
+	if err != nil {
+		param.From = 0
+	}
+	param.Size, err = strconv.ParseInt(request.QueryParameter("size"), 10, 64)
+	if err != nil {
+		param.Size = 10
+	}
+
+	return param
+}
+
+// splitFilter splits the comma-separated list, it returns false if the filter is not set
+func splitFilter(filter string) (bool, []string) {
+	if filter == "" {
+		return false, nil
+	}
+
+	return true, strings.Split(strings.Replace(filter, ",", " ", -1), " ")
+}
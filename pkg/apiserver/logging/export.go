@@ -0,0 +1,159 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/emicklei/go-restful"
+	"github.com/golang/glog"
+
+	"kubesphere.io/kubesphere/pkg/errors"
+	"kubesphere.io/kubesphere/pkg/models/log"
+	es "kubesphere.io/kubesphere/pkg/simple/client/elasticsearch"
+)
+
+const (
+	OperationExport = "export"
+	OperationFollow = "follow"
+
+	FormatJSON  = "json"
+	FormatText  = "text"
+	MIME_NDJSON = "application/x-ndjson"
+)
+
+func isStreamOperation(request *restful.Request) bool {
+	operation := request.QueryParameter("operation")
+	return operation == OperationExport || operation == OperationFollow
+}
+
+// recordWriter writes records as json separated by newlines or as plain log lines, the response header is
+// written with the first record, so errors happened before any output are still reported with status
+type recordWriter struct {
+	response *restful.Response
+	format   string
+	flush    bool
+	started  bool
+}
+
+func (w *recordWriter) start() {
+	if w.started {
+		return
+	}
+
+	w.started = true
+
+	if w.format == FormatText {
+		w.response.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	} else {
+		w.response.Header().Set("Content-Type", MIME_NDJSON)
+	}
+
+	w.response.WriteHeader(http.StatusOK)
+}
+
+func (w *recordWriter) write(record es.LogRecord) error {
+	w.start()
+
+	var line []byte
+
+	if w.format == FormatText {
+		line = []byte(record.Log)
+		if !strings.HasSuffix(record.Log, "\n") {
+			line = append(line, '\n')
+		}
+	} else {
+		data, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		line = append(data, '\n')
+	}
+
+	if _, err := w.response.Write(line); err != nil {
+		return err
+	}
+
+	if w.flush {
+		w.response.Flush()
+	}
+
+	return nil
+}
+
+// streamLogs exports logs of the whole time range, or follows new logs until the client disconnects
+func streamLogs(level log.LogQueryLevel, request *restful.Request, response *restful.Response) {
+	format := request.QueryParameter("format")
+
+	if format == "" {
+		format = FormatJSON
+	}
+
+	if format != FormatJSON && format != FormatText {
+		response.WriteHeaderAndEntity(http.StatusBadRequest, errors.Wrap(fmt.Errorf("unsupported format %s", format)))
+		return
+	}
+
+	writer := &recordWriter{response: response, format: format}
+
+	var err error
+
+	if request.QueryParameter("operation") == OperationExport {
+		filename := "logs.json"
+		if format == FormatText {
+			filename = "logs.txt"
+		}
+		response.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+
+		err = es.Export(parseQueryParameters(level, request), writer.write)
+	} else {
+		if _, ok := response.ResponseWriter.(http.Flusher); !ok {
+			response.WriteHeaderAndEntity(http.StatusInternalServerError, errors.Wrap(fmt.Errorf("streaming is not supported")))
+			return
+		}
+
+		param := parseQueryParameters(level, request)
+
+		// send headers at once, as there may be no logs for a long time
+		writer.flush = true
+		writer.start()
+		response.Flush()
+
+		err = es.Follow(func() es.QueryParameters {
+			return parseQueryParameters(level, request)
+		}, param.Size, request.Request.Context().Done(), writer.write)
+	}
+
+	if err == nil {
+		writer.start()
+		return
+	}
+
+	if writer.started {
+		// the client is gone or the stream is broken, nothing could be sent back
+		glog.Infoln("stream logs", err)
+		return
+	}
+
+	glog.Errorln(err)
+	response.WriteHeaderAndEntity(http.StatusInternalServerError, errors.Wrap(err))
+}
@@ -30,6 +30,11 @@ import (
 )
 
 func LoggingQueryCluster(request *restful.Request, response *restful.Response) {
+	if isStreamOperation(request) {
+		streamLogs(log.QueryLevelCluster, request, response)
+		return
+	}
+
 	res := logQuery(log.QueryLevelCluster, request)
 
 	if res.Status != http.StatusOK {
@@ -41,6 +46,11 @@ func LoggingQueryCluster(request *restful.Request, response *restful.Response) {
 }
 
 func LoggingQueryWorkspace(request *restful.Request, response *restful.Response) {
+	if isStreamOperation(request) {
+		streamLogs(log.QueryLevelWorkspace, request, response)
+		return
+	}
+
 	res := logQuery(log.QueryLevelWorkspace, request)
 
 	if res.Status != http.StatusOK {
@@ -52,6 +62,11 @@ func LoggingQueryWorkspace(request *restful.Request, response *restful.Response)
 }
 
 func LoggingQueryNamespace(request *restful.Request, response *restful.Response) {
+	if isStreamOperation(request) {
+		streamLogs(log.QueryLevelNamespace, request, response)
+		return
+	}
+
 	res := logQuery(log.QueryLevelNamespace, request)
 
 	if res.Status != http.StatusOK {
@@ -63,6 +78,11 @@ func LoggingQueryNamespace(request *restful.Request, response *restful.Response)
 }
 
 func LoggingQueryWorkload(request *restful.Request, response *restful.Response) {
+	if isStreamOperation(request) {
+		streamLogs(log.QueryLevelWorkload, request, response)
+		return
+	}
+
 	res := logQuery(log.QueryLevelWorkload, request)
 
 	if res.Status != http.StatusOK {
@@ -74,6 +94,11 @@ func LoggingQueryWorkload(request *restful.Request, response *restful.Response)
 }
 
 func LoggingQueryPod(request *restful.Request, response *restful.Response) {
+	if isStreamOperation(request) {
+		streamLogs(log.QueryLevelPod, request, response)
+		return
+	}
+
 	res := logQuery(log.QueryLevelPod, request)
 	if res.Status != http.StatusOK {
 		response.WriteHeaderAndEntity(res.Status, errors.New(res.Error))
@@ -83,6 +108,11 @@ func LoggingQueryPod(request *restful.Request, response *restful.Response) {
 }
 
 func LoggingQueryContainer(request *restful.Request, response *restful.Response) {
+	if isStreamOperation(request) {
+		streamLogs(log.QueryLevelContainer, request, response)
+		return
+	}
+
 	res := logQuery(log.QueryLevelContainer, request)
 	if res.Status != http.StatusOK {
 		response.WriteHeaderAndEntity(res.Status, errors.New(res.Error))
@@ -91,27 +121,6 @@ func LoggingQueryContainer(request *restful.Request, response *restful.Response)
 	response.WriteAsJson(res)
 }
 
-func LoggingQueryFluentbitFilters(request *restful.Request, response *restful.Response) {
-	res := log.FluentbitFiltersQuery()
-	response.WriteAsJson(res)
-}
-
-func LoggingUpdateFluentbitFilters(request *restful.Request, response *restful.Response) {
-
-	var res *log.FluentbitFiltersResult
-
-	filters := new([]log.FluentbitFilter)
-
-	err := request.ReadEntity(&filters)
-	if err != nil {
-		res = &log.FluentbitFiltersResult{Status: http.StatusBadRequest}
-	} else {
-		res = log.FluentbitFiltersUpdate(filters)
-	}
-
-	response.WriteAsJson(res)
-}
-
 func LoggingQueryFluentbitOutputs(request *restful.Request, response *restful.Response) {
 	res := log.FluentbitOutputsQuery()
 	if res.Status != http.StatusOK {
@@ -180,70 +189,105 @@ func LoggingDeleteFluentbitOutput(request *restful.Request, response *restful.Re
 	response.WriteAsJson(res)
 }
 
+// LoggingQueryTrace queries logs of the trace in the namespace, sorted by time ascending by default
+func LoggingQueryTrace(request *restful.Request, response *restful.Response) {
+	param := parseQueryParameters(log.QueryLevelNamespace, request)
+	param.FieldFilters = append(param.FieldFilters, log.TraceFieldFilter(request.PathParameter("trace")))
+	if param.Sort == "" {
+		param.Sort = "asc"
+	}
+
+	res := queryLogs(param)
+	if res.Status != http.StatusOK {
+		response.WriteHeaderAndEntity(res.Status, errors.New(res.Error))
+		return
+	}
+
+	response.WriteAsJson(res)
+}
+
 func logQuery(level log.LogQueryLevel, request *restful.Request) *es.QueryResult {
+	return queryLogs(parseQueryParameters(level, request))
+}
+
+func queryLogs(param es.QueryParameters) *es.QueryResult {
+	if param.Operation == "aggregation" && param.AggregationField == "" {
+		return &es.QueryResult{Status: http.StatusBadRequest, Error: "a valid aggregation_field is required by the aggregation operation"}
+	}
+
+	return es.Query(param)
+}
+
+func parseQueryParameters(level log.LogQueryLevel, request *restful.Request) es.QueryParameters {
+	return newQueryParameters(level, request.PathParameter, request.QueryParameter)
+}
+
+// newQueryParameters builds parameters of the level from path and query parameters,
+// saved queries supply query parameters of their own
+func newQueryParameters(level log.LogQueryLevel, pathParameter, queryParameter func(string) string) es.QueryParameters {
 	var param es.QueryParameters
 
-	param.Operation = request.QueryParameter("operation")
+	param.Operation = queryParameter("operation")
 
 	switch level {
 	case log.QueryLevelCluster:
 		{
-			param.NamespaceFilled, param.Namespaces = log.QueryWorkspace(request.QueryParameter("workspaces"), request.QueryParameter("workspace_query"))
-			param.NamespaceFilled, param.Namespaces = log.MatchNamespace(request.QueryParameter("namespaces"), param.NamespaceFilled, param.Namespaces)
+			param.NamespaceFilled, param.Namespaces = log.QueryWorkspace(queryParameter("workspaces"), queryParameter("workspace_query"))
+			param.NamespaceFilled, param.Namespaces = log.MatchNamespace(queryParameter("namespaces"), param.NamespaceFilled, param.Namespaces)
 			param.NamespaceFilled, param.NamespaceWithCreationTime = log.GetNamespaceCreationTimeMap(param.Namespaces)
-			param.NamespaceQuery = request.QueryParameter("namespace_query")
-			param.PodFilled, param.Pods = log.QueryWorkload(request.QueryParameter("workloads"), request.QueryParameter("workload_query"), param.Namespaces)
-			param.PodFilled, param.Pods = log.MatchPod(request.QueryParameter("pods"), param.PodFilled, param.Pods)
-			param.PodQuery = request.QueryParameter("pod_query")
-			param.ContainerFilled, param.Containers = log.MatchContainer(request.QueryParameter("containers"))
-			param.ContainerQuery = request.QueryParameter("container_query")
+			param.NamespaceQuery = queryParameter("namespace_query")
+			param.PodFilled, param.Pods = log.QueryWorkload(queryParameter("workloads"), queryParameter("workload_query"), param.Namespaces)
+			param.PodFilled, param.Pods = log.MatchPod(queryParameter("pods"), param.PodFilled, param.Pods)
+			param.PodQuery = queryParameter("pod_query")
+			param.ContainerFilled, param.Containers = log.MatchContainer(queryParameter("containers"))
+			param.ContainerQuery = queryParameter("container_query")
 		}
 	case log.QueryLevelWorkspace:
 		{
-			param.NamespaceFilled, param.Namespaces = log.QueryWorkspace(request.PathParameter("workspace"), "")
-			param.NamespaceFilled, param.Namespaces = log.MatchNamespace(request.QueryParameter("namespaces"), param.NamespaceFilled, param.Namespaces)
+			param.NamespaceFilled, param.Namespaces = log.QueryWorkspace(pathParameter("workspace"), "")
+			param.NamespaceFilled, param.Namespaces = log.MatchNamespace(queryParameter("namespaces"), param.NamespaceFilled, param.Namespaces)
 			param.NamespaceFilled, param.NamespaceWithCreationTime = log.GetNamespaceCreationTimeMap(param.Namespaces)
-			param.NamespaceQuery = request.QueryParameter("namespace_query")
-			param.PodFilled, param.Pods = log.QueryWorkload(request.QueryParameter("workloads"), request.QueryParameter("workload_query"), param.Namespaces)
-			param.PodFilled, param.Pods = log.MatchPod(request.QueryParameter("pods"), param.PodFilled, param.Pods)
-			param.PodQuery = request.QueryParameter("pod_query")
-			param.ContainerFilled, param.Containers = log.MatchContainer(request.QueryParameter("containers"))
-			param.ContainerQuery = request.QueryParameter("container_query")
+			param.NamespaceQuery = queryParameter("namespace_query")
+			param.PodFilled, param.Pods = log.QueryWorkload(queryParameter("workloads"), queryParameter("workload_query"), param.Namespaces)
+			param.PodFilled, param.Pods = log.MatchPod(queryParameter("pods"), param.PodFilled, param.Pods)
+			param.PodQuery = queryParameter("pod_query")
+			param.ContainerFilled, param.Containers = log.MatchContainer(queryParameter("containers"))
+			param.ContainerQuery = queryParameter("container_query")
 		}
 	case log.QueryLevelNamespace:
 		{
-			param.NamespaceFilled, param.Namespaces = log.MatchNamespace(request.PathParameter("namespace"), false, nil)
+			param.NamespaceFilled, param.Namespaces = log.MatchNamespace(pathParameter("namespace"), false, nil)
 			param.NamespaceFilled, param.NamespaceWithCreationTime = log.GetNamespaceCreationTimeMap(param.Namespaces)
-			param.PodFilled, param.Pods = log.QueryWorkload(request.QueryParameter("workloads"), request.QueryParameter("workload_query"), param.Namespaces)
-			param.PodFilled, param.Pods = log.MatchPod(request.QueryParameter("pods"), param.PodFilled, param.Pods)
-			param.PodQuery = request.QueryParameter("pod_query")
-			param.ContainerFilled, param.Containers = log.MatchContainer(request.QueryParameter("containers"))
-			param.ContainerQuery = request.QueryParameter("container_query")
+			param.PodFilled, param.Pods = log.QueryWorkload(queryParameter("workloads"), queryParameter("workload_query"), param.Namespaces)
+			param.PodFilled, param.Pods = log.MatchPod(queryParameter("pods"), param.PodFilled, param.Pods)
+			param.PodQuery = queryParameter("pod_query")
+			param.ContainerFilled, param.Containers = log.MatchContainer(queryParameter("containers"))
+			param.ContainerQuery = queryParameter("container_query")
 		}
 	case log.QueryLevelWorkload:
 		{
-			param.NamespaceFilled, param.Namespaces = log.MatchNamespace(request.PathParameter("namespace"), false, nil)
+			param.NamespaceFilled, param.Namespaces = log.MatchNamespace(pathParameter("namespace"), false, nil)
 			param.NamespaceFilled, param.NamespaceWithCreationTime = log.GetNamespaceCreationTimeMap(param.Namespaces)
-			param.PodFilled, param.Pods = log.QueryWorkload(request.PathParameter("workload"), "", param.Namespaces)
-			param.PodFilled, param.Pods = log.MatchPod(request.QueryParameter("pods"), param.PodFilled, param.Pods)
-			param.PodQuery = request.QueryParameter("pod_query")
-			param.ContainerFilled, param.Containers = log.MatchContainer(request.QueryParameter("containers"))
-			param.ContainerQuery = request.QueryParameter("container_query")
+			param.PodFilled, param.Pods = log.QueryWorkload(pathParameter("workload"), "", param.Namespaces)
+			param.PodFilled, param.Pods = log.MatchPod(queryParameter("pods"), param.PodFilled, param.Pods)
+			param.PodQuery = queryParameter("pod_query")
+			param.ContainerFilled, param.Containers = log.MatchContainer(queryParameter("containers"))
+			param.ContainerQuery = queryParameter("container_query")
 		}
 	case log.QueryLevelPod:
 		{
-			param.NamespaceFilled, param.Namespaces = log.MatchNamespace(request.PathParameter("namespace"), false, nil)
+			param.NamespaceFilled, param.Namespaces = log.MatchNamespace(pathParameter("namespace"), false, nil)
 			param.NamespaceFilled, param.NamespaceWithCreationTime = log.GetNamespaceCreationTimeMap(param.Namespaces)
-			param.PodFilled, param.Pods = log.MatchPod(request.PathParameter("pod"), false, nil)
-			param.ContainerFilled, param.Containers = log.MatchContainer(request.QueryParameter("containers"))
-			param.ContainerQuery = request.QueryParameter("container_query")
+			param.PodFilled, param.Pods = log.MatchPod(pathParameter("pod"), false, nil)
+			param.ContainerFilled, param.Containers = log.MatchContainer(queryParameter("containers"))
+			param.ContainerQuery = queryParameter("container_query")
 		}
 	case log.QueryLevelContainer:
 		{
-			param.NamespaceFilled, param.Namespaces = log.MatchNamespace(request.PathParameter("namespace"), false, nil)
+			param.NamespaceFilled, param.Namespaces = log.MatchNamespace(pathParameter("namespace"), false, nil)
 			param.NamespaceFilled, param.NamespaceWithCreationTime = log.GetNamespaceCreationTimeMap(param.Namespaces)
-			param.PodFilled, param.Pods = log.MatchPod(request.PathParameter("pod"), false, nil)
-			param.ContainerFilled, param.Containers = log.MatchContainer(request.PathParameter("container"))
+			param.PodFilled, param.Pods = log.MatchPod(pathParameter("pod"), false, nil)
+			param.ContainerFilled, param.Containers = log.MatchContainer(pathParameter("container"))
 		}
 	}
 
@@ -251,22 +295,69 @@ func logQuery(level log.LogQueryLevel, request *restful.Request) *es.QueryResult
 		param.Workspace = log.GetWorkspaceOfNamesapce(param.Namespaces[0])
 	}
 
-	param.Interval = request.QueryParameter("interval")
+	param.DedicatedIndices = log.QueryIndices(param.NamespaceFilled, param.NamespaceWithCreationTime)
+
+	param.Interval = queryParameter("interval")
 
-	param.LogQuery = request.QueryParameter("log_query")
-	param.StartTime = request.QueryParameter("start_time")
-	param.EndTime = request.QueryParameter("end_time")
-	param.Sort = request.QueryParameter("sort")
+	param.LogQuery = queryParameter("log_query")
+	param.FieldFilters = log.ParseFieldFilters(queryParameter("fields"))
+	if field := queryParameter("aggregation_field"); log.IsValidField(field) {
+		param.AggregationField = field
+	}
+	param.StartTime = queryParameter("start_time")
+	param.EndTime = queryParameter("end_time")
+	param.Sort = queryParameter("sort")
 
 	var err error
-	param.From, err = strconv.ParseInt(request.QueryParameter("from"), 10, 64)
+	param.From, err = strconv.ParseInt(queryParameter("from"), 10, 64)
 	if err != nil {
 		param.From = 0
 	}
-	param.Size, err = strconv.ParseInt(request.QueryParameter("size"), 10, 64)
+	param.Size, err = strconv.ParseInt(queryParameter("size"), 10, 64)
 	if err != nil {
 		param.Size = 10
 	}
 
-	return es.Query(param)
+	return param
+}
+
+func LoggingQueryWorkspaceSettings(request *restful.Request, response *restful.Response) {
+	res := log.WorkspaceLogSettingsQuery()
+	if res.Status != http.StatusOK {
+		response.WriteHeaderAndEntity(res.Status, errors.New(res.Error))
+		return
+	}
+	response.WriteAsJson(res)
+}
+
+func LoggingUpdateWorkspaceSettings(request *restful.Request, response *restful.Response) {
+
+	var settings log.WorkspaceLogSettings
+
+	workspace := request.PathParameter("workspace")
+
+	err := request.ReadEntity(&settings)
+	if err != nil {
+		glog.Errorln(err)
+		response.WriteHeaderAndEntity(http.StatusBadRequest, errors.Wrap(err))
+		return
+	}
+
+	res := log.WorkspaceLogSettingsUpdate(workspace, settings)
+	if res.Status != http.StatusOK {
+		response.WriteHeaderAndEntity(res.Status, errors.New(res.Error))
+		return
+	}
+
+	response.WriteAsJson(res)
+}
+
+func LoggingDeleteWorkspaceSettings(request *restful.Request, response *restful.Response) {
+	res := log.WorkspaceLogSettingsDelete(request.PathParameter("workspace"))
+	if res.Status != http.StatusOK {
+		response.WriteHeaderAndEntity(res.Status, errors.New(res.Error))
+		return
+	}
+
+	response.WriteAsJson(res)
 }
@@ -0,0 +1,131 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+
+package logging
+
+import (
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+	"github.com/golang/glog"
+
+	"kubesphere.io/kubesphere/pkg/errors"
+	"kubesphere.io/kubesphere/pkg/models/log"
+)
+
+func LoggingQueryFluentbitInputs(request *restful.Request, response *restful.Response) {
+	writePipelineResult(response, log.FluentbitPipelineQuery(log.PipelineInputs))
+}
+
+func LoggingInsertFluentbitInput(request *restful.Request, response *restful.Response) {
+	insertPipelinePlugin(log.PipelineInputs, request, response)
+}
+
+func LoggingUpdateFluentbitInput(request *restful.Request, response *restful.Response) {
+	updatePipelinePlugin(log.PipelineInputs, request.PathParameter("input"), request, response)
+}
+
+func LoggingDeleteFluentbitInput(request *restful.Request, response *restful.Response) {
+	writePipelineResult(response, log.FluentbitPipelineDelete(log.PipelineInputs, request.PathParameter("input")))
+}
+
+func LoggingQueryFluentbitParsers(request *restful.Request, response *restful.Response) {
+	writePipelineResult(response, log.FluentbitPipelineQuery(log.PipelineParsers))
+}
+
+func LoggingInsertFluentbitParser(request *restful.Request, response *restful.Response) {
+	insertPipelinePlugin(log.PipelineParsers, request, response)
+}
+
+func LoggingUpdateFluentbitParser(request *restful.Request, response *restful.Response) {
+	updatePipelinePlugin(log.PipelineParsers, request.PathParameter("parser"), request, response)
+}
+
+func LoggingDeleteFluentbitParser(request *restful.Request, response *restful.Response) {
+	writePipelineResult(response, log.FluentbitPipelineDelete(log.PipelineParsers, request.PathParameter("parser")))
+}
+
+func LoggingPreviewFluentbitParsers(request *restful.Request, response *restful.Response) {
+	var preview log.ParserPreviewRequest
+
+	err := request.ReadEntity(&preview)
+	if err != nil {
+		glog.Errorln(err)
+		response.WriteHeaderAndEntity(http.StatusBadRequest, errors.Wrap(err))
+		return
+	}
+
+	res := log.FluentbitParserPreview(preview)
+	if res.Status != http.StatusOK {
+		response.WriteHeaderAndEntity(res.Status, errors.New(res.Error))
+		return
+	}
+
+	response.WriteAsJson(res)
+}
+
+func LoggingQueryFluentbitFilters(request *restful.Request, response *restful.Response) {
+	writePipelineResult(response, log.FluentbitPipelineQuery(log.PipelineFilters))
+}
+
+func LoggingInsertFluentbitFilter(request *restful.Request, response *restful.Response) {
+	insertPipelinePlugin(log.PipelineFilters, request, response)
+}
+
+func LoggingUpdateFluentbitFilter(request *restful.Request, response *restful.Response) {
+	updatePipelinePlugin(log.PipelineFilters, request.PathParameter("filter"), request, response)
+}
+
+func LoggingDeleteFluentbitFilter(request *restful.Request, response *restful.Response) {
+	writePipelineResult(response, log.FluentbitPipelineDelete(log.PipelineFilters, request.PathParameter("filter")))
+}
+
+func insertPipelinePlugin(section string, request *restful.Request, response *restful.Response) {
+	var plugin log.FluentbitPipelinePlugin
+
+	err := request.ReadEntity(&plugin)
+	if err != nil {
+		glog.Errorln(err)
+		response.WriteHeaderAndEntity(http.StatusBadRequest, errors.Wrap(err))
+		return
+	}
+
+	writePipelineResult(response, log.FluentbitPipelineInsert(section, plugin))
+}
+
+func updatePipelinePlugin(section, name string, request *restful.Request, response *restful.Response) {
+	var plugin log.FluentbitPipelinePlugin
+
+	err := request.ReadEntity(&plugin)
+	if err != nil {
+		glog.Errorln(err)
+		response.WriteHeaderAndEntity(http.StatusBadRequest, errors.Wrap(err))
+		return
+	}
+
+	writePipelineResult(response, log.FluentbitPipelineUpdate(section, name, plugin))
+}
+
+func writePipelineResult(response *restful.Response, res *log.FluentbitPipelineResult) {
+	if res.Status != http.StatusOK {
+		response.WriteHeaderAndEntity(res.Status, errors.New(res.Error))
+		return
+	}
+
+	response.WriteAsJson(res)
+}
@@ -0,0 +1,154 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+
+package logging
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/emicklei/go-restful"
+	"github.com/golang/glog"
+
+	"kubesphere.io/kubesphere/pkg/constants"
+	"kubesphere.io/kubesphere/pkg/errors"
+	"kubesphere.io/kubesphere/pkg/models/log"
+)
+
+// parameters of log queries which callers may override when executing saved queries
+var savedQueryOverrides = []string{"operation", "interval", "sort", "from", "size", "aggregation_field"}
+
+func LoggingQueryWorkspaceSavedQueries(request *restful.Request, response *restful.Response) {
+	writeSavedQueryResult(response, log.SavedLogQueriesQuery(workspaceScope(request)))
+}
+
+func LoggingGetWorkspaceSavedQuery(request *restful.Request, response *restful.Response) {
+	writeSavedQueryResult(response, log.SavedLogQueryGet(workspaceScope(request), request.PathParameter("query")))
+}
+
+func LoggingInsertWorkspaceSavedQuery(request *restful.Request, response *restful.Response) {
+	insertSavedQuery(workspaceScope(request), request, response)
+}
+
+func LoggingUpdateWorkspaceSavedQuery(request *restful.Request, response *restful.Response) {
+	updateSavedQuery(workspaceScope(request), request, response)
+}
+
+func LoggingDeleteWorkspaceSavedQuery(request *restful.Request, response *restful.Response) {
+	writeSavedQueryResult(response, log.SavedLogQueryDelete(workspaceScope(request), request.PathParameter("query")))
+}
+
+func LoggingExecuteWorkspaceSavedQuery(request *restful.Request, response *restful.Response) {
+	executeSavedQuery(log.QueryLevelWorkspace, workspaceScope(request), request, response)
+}
+
+func LoggingQueryNamespaceSavedQueries(request *restful.Request, response *restful.Response) {
+	writeSavedQueryResult(response, log.SavedLogQueriesQuery(namespaceScope(request)))
+}
+
+func LoggingGetNamespaceSavedQuery(request *restful.Request, response *restful.Response) {
+	writeSavedQueryResult(response, log.SavedLogQueryGet(namespaceScope(request), request.PathParameter("query")))
+}
+
+func LoggingInsertNamespaceSavedQuery(request *restful.Request, response *restful.Response) {
+	insertSavedQuery(namespaceScope(request), request, response)
+}
+
+func LoggingUpdateNamespaceSavedQuery(request *restful.Request, response *restful.Response) {
+	updateSavedQuery(namespaceScope(request), request, response)
+}
+
+func LoggingDeleteNamespaceSavedQuery(request *restful.Request, response *restful.Response) {
+	writeSavedQueryResult(response, log.SavedLogQueryDelete(namespaceScope(request), request.PathParameter("query")))
+}
+
+func LoggingExecuteNamespaceSavedQuery(request *restful.Request, response *restful.Response) {
+	executeSavedQuery(log.QueryLevelNamespace, namespaceScope(request), request, response)
+}
+
+func workspaceScope(request *restful.Request) log.SavedLogQueryScope {
+	return log.SavedLogQueryScope{Workspace: request.PathParameter("workspace")}
+}
+
+func namespaceScope(request *restful.Request) log.SavedLogQueryScope {
+	return log.SavedLogQueryScope{Namespace: request.PathParameter("namespace")}
+}
+
+func insertSavedQuery(scope log.SavedLogQueryScope, request *restful.Request, response *restful.Response) {
+	var query log.SavedLogQuery
+
+	err := request.ReadEntity(&query)
+	if err != nil {
+		glog.Errorln(err)
+		response.WriteHeaderAndEntity(http.StatusBadRequest, errors.Wrap(err))
+		return
+	}
+
+	writeSavedQueryResult(response, log.SavedLogQueryCreate(scope, query, request.HeaderParameter(constants.UserNameHeader)))
+}
+
+func updateSavedQuery(scope log.SavedLogQueryScope, request *restful.Request, response *restful.Response) {
+	var query log.SavedLogQuery
+
+	err := request.ReadEntity(&query)
+	if err != nil {
+		glog.Errorln(err)
+		response.WriteHeaderAndEntity(http.StatusBadRequest, errors.Wrap(err))
+		return
+	}
+
+	writeSavedQueryResult(response, log.SavedLogQueryUpdate(scope, request.PathParameter("query"), query))
+}
+
+// executeSavedQuery queries logs with parameters of the saved query over its window before now
+func executeSavedQuery(level log.LogQueryLevel, scope log.SavedLogQueryScope, request *restful.Request, response *restful.Response) {
+	if isStreamOperation(request) {
+		response.WriteHeaderAndEntity(http.StatusBadRequest, errors.New("export and follow are not supported by saved queries"))
+		return
+	}
+
+	saved := log.SavedLogQueryGet(scope, request.PathParameter("query"))
+	if saved.Status != http.StatusOK {
+		response.WriteHeaderAndEntity(saved.Status, errors.New(saved.Error))
+		return
+	}
+
+	values := log.SavedLogQueryValues(saved.Queries[0], time.Now())
+	for _, key := range savedQueryOverrides {
+		if value := request.QueryParameter(key); value != "" {
+			values.Set(key, value)
+		}
+	}
+
+	res := queryLogs(newQueryParameters(level, request.PathParameter, values.Get))
+	if res.Status != http.StatusOK {
+		response.WriteHeaderAndEntity(res.Status, errors.New(res.Error))
+		return
+	}
+
+	response.WriteAsJson(res)
+}
+
+func writeSavedQueryResult(response *restful.Response, res *log.SavedLogQueryResult) {
+	if res.Status != http.StatusOK {
+		response.WriteHeaderAndEntity(res.Status, errors.New(res.Error))
+		return
+	}
+
+	response.WriteAsJson(res)
+}
@@ -0,0 +1,96 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+
+package monitoring
+
+import (
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+	"github.com/golang/glog"
+
+	"kubesphere.io/kubesphere/pkg/apis/alerting/v1alpha1"
+	"kubesphere.io/kubesphere/pkg/constants"
+	"kubesphere.io/kubesphere/pkg/errors"
+	"kubesphere.io/kubesphere/pkg/models/alerting"
+	"kubesphere.io/kubesphere/pkg/models/metrics"
+	"kubesphere.io/kubesphere/pkg/simple/client/alertmanager"
+)
+
+// RenderAlertRule renders the spec of an alert rule in the namespace into PromQL,
+// the alert rule controller renders PrometheusRules with it
+func RenderAlertRule(request *restful.Request, response *restful.Response) {
+	var spec v1alpha1.AlertRuleSpec
+
+	if err := request.ReadEntity(&spec); err != nil {
+		glog.Errorln(err)
+		response.WriteHeaderAndEntity(http.StatusBadRequest, errors.Wrap(err))
+		return
+	}
+
+	expression, err := metrics.MakeAlertRulePromQL(request.PathParameter("namespace"), spec)
+	if err != nil {
+		response.WriteHeaderAndEntity(http.StatusBadRequest, errors.Wrap(err))
+		return
+	}
+
+	response.WriteAsJson(metrics.AlertRuleExpression{Expression: expression})
+}
+
+func ListAlerts(request *restful.Request, response *restful.Response) {
+	res := alerting.ListAlerts(alertingScope(request), request.QueryParameters("filter"), request.QueryParameter("muted") == "true")
+	if res.Status != http.StatusOK {
+		response.WriteHeaderAndEntity(res.Status, errors.New(res.Error))
+		return
+	}
+
+	response.WriteAsJson(res)
+}
+
+func ListSilences(request *restful.Request, response *restful.Response) {
+	writeSilencesResult(response, alerting.ListSilences(alertingScope(request)))
+}
+
+func CreateSilence(request *restful.Request, response *restful.Response) {
+	var silence alertmanager.Silence
+
+	if err := request.ReadEntity(&silence); err != nil {
+		glog.Errorln(err)
+		response.WriteHeaderAndEntity(http.StatusBadRequest, errors.Wrap(err))
+		return
+	}
+
+	writeSilencesResult(response, alerting.CreateSilence(alertingScope(request), silence, request.HeaderParameter(constants.UserNameHeader)))
+}
+
+func ExpireSilence(request *restful.Request, response *restful.Response) {
+	writeSilencesResult(response, alerting.ExpireSilence(alertingScope(request), request.PathParameter("silence")))
+}
+
+func alertingScope(request *restful.Request) alerting.Scope {
+	return alerting.Scope{Workspace: request.PathParameter("workspace"), Namespace: request.PathParameter("namespace")}
+}
+
+func writeSilencesResult(response *restful.Response, res *alerting.SilencesResult) {
+	if res.Status != http.StatusOK {
+		response.WriteHeaderAndEntity(res.Status, errors.New(res.Error))
+		return
+	}
+
+	response.WriteAsJson(res)
+}
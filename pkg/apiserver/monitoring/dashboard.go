@@ -0,0 +1,141 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+
+package monitoring
+
+import (
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+	"github.com/golang/glog"
+
+	"kubesphere.io/kubesphere/pkg/constants"
+	"kubesphere.io/kubesphere/pkg/errors"
+	"kubesphere.io/kubesphere/pkg/models/metrics"
+	"kubesphere.io/kubesphere/pkg/params"
+)
+
+func ListWorkspaceDashboards(request *restful.Request, response *restful.Response) {
+	writeDashboardResult(response, metrics.ListDashboards(workspaceDashboardScope(request)))
+}
+
+func GetWorkspaceDashboard(request *restful.Request, response *restful.Response) {
+	writeDashboardResult(response, metrics.GetDashboard(workspaceDashboardScope(request), request.PathParameter("dashboard")))
+}
+
+func CreateWorkspaceDashboard(request *restful.Request, response *restful.Response) {
+	createDashboard(workspaceDashboardScope(request), request, response)
+}
+
+func UpdateWorkspaceDashboard(request *restful.Request, response *restful.Response) {
+	updateDashboard(workspaceDashboardScope(request), request, response)
+}
+
+func DeleteWorkspaceDashboard(request *restful.Request, response *restful.Response) {
+	writeDashboardResult(response, metrics.DeleteDashboard(workspaceDashboardScope(request), request.PathParameter("dashboard")))
+}
+
+func RenderWorkspaceDashboard(request *restful.Request, response *restful.Response) {
+	renderDashboard(workspaceDashboardScope(request), request, response)
+}
+
+func ListNamespaceDashboards(request *restful.Request, response *restful.Response) {
+	writeDashboardResult(response, metrics.ListDashboards(namespaceDashboardScope(request)))
+}
+
+func GetNamespaceDashboard(request *restful.Request, response *restful.Response) {
+	writeDashboardResult(response, metrics.GetDashboard(namespaceDashboardScope(request), request.PathParameter("dashboard")))
+}
+
+func CreateNamespaceDashboard(request *restful.Request, response *restful.Response) {
+	createDashboard(namespaceDashboardScope(request), request, response)
+}
+
+func UpdateNamespaceDashboard(request *restful.Request, response *restful.Response) {
+	updateDashboard(namespaceDashboardScope(request), request, response)
+}
+
+func DeleteNamespaceDashboard(request *restful.Request, response *restful.Response) {
+	writeDashboardResult(response, metrics.DeleteDashboard(namespaceDashboardScope(request), request.PathParameter("dashboard")))
+}
+
+func RenderNamespaceDashboard(request *restful.Request, response *restful.Response) {
+	renderDashboard(namespaceDashboardScope(request), request, response)
+}
+
+func workspaceDashboardScope(request *restful.Request) metrics.DashboardScope {
+	return metrics.DashboardScope{Workspace: request.PathParameter("workspace")}
+}
+
+func namespaceDashboardScope(request *restful.Request) metrics.DashboardScope {
+	return metrics.DashboardScope{Namespace: request.PathParameter("namespace")}
+}
+
+func createDashboard(scope metrics.DashboardScope, request *restful.Request, response *restful.Response) {
+	var dashboard metrics.Dashboard
+
+	err := request.ReadEntity(&dashboard)
+	if err != nil {
+		glog.Errorln(err)
+		response.WriteHeaderAndEntity(http.StatusBadRequest, errors.Wrap(err))
+		return
+	}
+
+	writeDashboardResult(response, metrics.CreateDashboard(scope, dashboard, request.HeaderParameter(constants.UserNameHeader)))
+}
+
+func updateDashboard(scope metrics.DashboardScope, request *restful.Request, response *restful.Response) {
+	var dashboard metrics.Dashboard
+
+	err := request.ReadEntity(&dashboard)
+	if err != nil {
+		glog.Errorln(err)
+		response.WriteHeaderAndEntity(http.StatusBadRequest, errors.Wrap(err))
+		return
+	}
+
+	writeDashboardResult(response, metrics.UpdateDashboard(scope, request.PathParameter("dashboard"), dashboard))
+}
+
+// renderDashboard evaluates panels of the dashboard at the time or over the time range of the request
+func renderDashboard(scope metrics.DashboardScope, request *restful.Request, response *restful.Response) {
+	query := metrics.PromQLQuery{
+		Time:    request.QueryParameter("time"),
+		Start:   request.QueryParameter("start"),
+		End:     request.QueryParameter("end"),
+		Step:    request.QueryParameter("step"),
+		Timeout: request.QueryParameter("timeout"),
+	}
+
+	res := metrics.RenderDashboard(params.ParseIdentity(request), scope, request.PathParameter("dashboard"), query)
+	if res.Status != http.StatusOK {
+		response.WriteHeaderAndEntity(res.Status, errors.New(res.Error))
+		return
+	}
+
+	response.WriteAsJson(res)
+}
+
+func writeDashboardResult(response *restful.Response, res *metrics.DashboardResult) {
+	if res.Status != http.StatusOK {
+		response.WriteHeaderAndEntity(res.Status, errors.New(res.Error))
+		return
+	}
+
+	response.WriteAsJson(res)
+}
@@ -0,0 +1,80 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+
+package monitoring
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+	"github.com/golang/glog"
+
+	"kubesphere.io/kubesphere/pkg/errors"
+	"kubesphere.io/kubesphere/pkg/models/metrics"
+)
+
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+	MIME_CSV   = "text/csv"
+)
+
+// GetMeteringReport returns resource usage and costs of the cluster, the workspace or the namespace of the path
+func GetMeteringReport(request *restful.Request, response *restful.Response) {
+	format := request.QueryParameter("format")
+
+	if format == "" {
+		format = FormatJSON
+	}
+
+	if format != FormatJSON && format != FormatCSV {
+		response.WriteHeaderAndEntity(http.StatusBadRequest, errors.Wrap(fmt.Errorf("unsupported format %s", format)))
+		return
+	}
+
+	scope := metrics.MeteringScope{
+		Workspace: request.PathParameter("workspace"),
+		Namespace: request.PathParameter("namespace"),
+	}
+
+	query := metrics.MeteringQuery{
+		Start:  request.QueryParameter("start"),
+		End:    request.QueryParameter("end"),
+		Period: request.QueryParameter("period"),
+		Level:  request.QueryParameter("level"),
+	}
+
+	res := metrics.GetMeteringReport(scope, query)
+	if res.Status != http.StatusOK {
+		response.WriteHeaderAndEntity(res.Status, errors.New(res.Error))
+		return
+	}
+
+	if format == FormatJSON {
+		response.WriteAsJson(res)
+		return
+	}
+
+	response.Header().Set("Content-Type", MIME_CSV)
+	response.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=metering-%s-%s.csv", query.Start, query.End))
+
+	if err := metrics.WriteMeteringCSV(response, res); err != nil {
+		glog.Errorln(err)
+	}
+}
@@ -0,0 +1,50 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+
+package monitoring
+
+import (
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+
+	"kubesphere.io/kubesphere/pkg/errors"
+	"kubesphere.io/kubesphere/pkg/models/metrics"
+	"kubesphere.io/kubesphere/pkg/params"
+)
+
+// QueryPromQL evaluates the PromQL expression against metrics of namespaces the user can access
+func QueryPromQL(request *restful.Request, response *restful.Response) {
+	scope := metrics.PromQLScope{Workspace: request.PathParameter("workspace"), Namespace: request.PathParameter("namespace")}
+	query := metrics.PromQLQuery{
+		Query:   request.QueryParameter("query"),
+		Time:    request.QueryParameter("time"),
+		Start:   request.QueryParameter("start"),
+		End:     request.QueryParameter("end"),
+		Step:    request.QueryParameter("step"),
+		Timeout: request.QueryParameter("timeout"),
+	}
+
+	res := metrics.QueryPromQL(params.ParseIdentity(request), scope, query)
+	if res.Status != http.StatusOK {
+		response.WriteHeaderAndEntity(res.Status, errors.New(res.Error))
+		return
+	}
+
+	response.WriteAsJson(res)
+}
@@ -18,9 +18,14 @@
 package resources
 
 import (
+	"encoding/json"
+	"fmt"
 	"github.com/emicklei/go-restful"
+	"github.com/golang/glog"
 	"kubesphere.io/kubesphere/pkg/models/resources"
 	"net/http"
+	"strconv"
+	"time"
 
 	"kubesphere.io/kubesphere/pkg/errors"
 	"kubesphere.io/kubesphere/pkg/params"
@@ -48,12 +53,111 @@ func ListResources(req *restful.Request, resp *restful.Response) {
 		return
 	}
 
-	result, err := resources.ListResources(namespace, resourceName, conditions, orderBy, reverse, limit, offset)
+	conditions.Filter, err = params.ParseFilter(req.QueryParameter(params.FilterParam))
 
 	if err != nil {
+		resp.WriteHeaderAndEntity(http.StatusBadRequest, errors.Wrap(err))
+		return
+	}
+
+	if watch, _ := strconv.ParseBool(req.QueryParameter(params.WatchParam)); watch {
+		watchResources(req, resp, namespace, resourceName, conditions)
+		return
+	}
+
+	options := &resources.ListOptions{
+		Limit:    limit,
+		Offset:   offset,
+		Continue: req.QueryParameter(params.ContinueParam),
+		Fields:   params.ParseFields(req.QueryParameter(params.FieldsParam)),
+		Table:    req.QueryParameter(params.OutputParam) == params.OutputTable,
+	}
+
+	// limit and continue take precedence over paging
+	if value := req.QueryParameter(params.LimitParam); value != "" || options.Continue != "" {
+		options.Cursor = true
+		if value != "" {
+			if options.Limit, err = strconv.Atoi(value); err != nil || options.Limit < 1 {
+				resp.WriteHeaderAndEntity(http.StatusBadRequest, errors.Wrap(fmt.Errorf("invalid limit %s", value)))
+				return
+			}
+		}
+	}
+
+	result, err := resources.ListResourcesWithOptions(namespace, resourceName, conditions, orderBy, reverse, options)
+
+	switch err {
+	case nil:
+	case resources.ErrInvalidContinue:
+		resp.WriteHeaderAndEntity(http.StatusBadRequest, errors.Wrap(err))
+		return
+	case resources.ErrContinueExpired:
+		resp.WriteHeaderAndEntity(http.StatusGone, errors.Wrap(err))
+		return
+	default:
 		resp.WriteHeaderAndEntity(http.StatusInternalServerError, errors.Wrap(err))
 		return
 	}
 
 	resp.WriteAsJson(result)
 }
+
+const defaultWatchTimeout = 30 * time.Minute
+
+// watchResources streams events as json objects separated by newlines, until the client disconnects or timeout
+func watchResources(req *restful.Request, resp *restful.Response, namespace, resourceName string, conditions *params.Conditions) {
+	timeout := defaultWatchTimeout
+
+	if value := req.QueryParameter(params.TimeoutParam); value != "" {
+		seconds, err := strconv.Atoi(value)
+		if err != nil || seconds < 1 {
+			resp.WriteHeaderAndEntity(http.StatusBadRequest, errors.Wrap(fmt.Errorf("invalid timeoutSeconds %s", value)))
+			return
+		}
+		timeout = time.Duration(seconds) * time.Second
+	}
+
+	flusher, ok := resp.ResponseWriter.(http.Flusher)
+
+	if !ok {
+		resp.WriteHeaderAndEntity(http.StatusInternalServerError, errors.Wrap(fmt.Errorf("streaming is not supported")))
+		return
+	}
+
+	watcher, err := resources.Watch(namespace, resourceName, conditions)
+
+	if err != nil {
+		resp.WriteHeaderAndEntity(http.StatusNotFound, errors.Wrap(err))
+		return
+	}
+
+	defer watcher.Stop()
+
+	resp.Header().Set("Content-Type", restful.MIME_JSON)
+	resp.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	encoder := json.NewEncoder(resp)
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case event := <-watcher.ResultChan():
+			if err := encoder.Encode(event); err != nil {
+				glog.Infoln("watch", resourceName, err)
+				return
+			}
+			// send events in the buffer together
+			if len(watcher.ResultChan()) == 0 {
+				flusher.Flush()
+			}
+		case <-watcher.Done():
+			return
+		case <-timer.C:
+			return
+		case <-req.Request.Context().Done():
+			return
+		}
+	}
+}
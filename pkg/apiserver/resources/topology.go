@@ -0,0 +1,63 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package resources
+
+import (
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+	"github.com/golang/glog"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+
+	"kubesphere.io/kubesphere/pkg/errors"
+	"kubesphere.io/kubesphere/pkg/models/resources"
+)
+
+func GetNamespaceTopology(req *restful.Request, resp *restful.Response) {
+	topology, err := resources.GetNamespaceTopology(req.PathParameter("namespace"))
+
+	if err != nil {
+		writeTopologyError(resp, err)
+		return
+	}
+
+	resp.WriteAsJson(topology)
+}
+
+func GetTopology(req *restful.Request, resp *restful.Response) {
+	topology, err := resources.GetTopology(req.PathParameter("namespace"), req.PathParameter("resources"), req.PathParameter("name"))
+
+	if err != nil {
+		writeTopologyError(resp, err)
+		return
+	}
+
+	resp.WriteAsJson(topology)
+}
+
+func writeTopologyError(resp *restful.Response, err error) {
+	switch {
+	case k8serr.IsBadRequest(err):
+		resp.WriteHeaderAndEntity(http.StatusBadRequest, errors.Wrap(err))
+	case k8serr.IsNotFound(err):
+		resp.WriteHeaderAndEntity(http.StatusNotFound, errors.Wrap(err))
+	default:
+		glog.Error(err)
+		resp.WriteHeaderAndEntity(http.StatusInternalServerError, errors.Wrap(err))
+	}
+}
@@ -19,11 +19,14 @@ package runtime
 
 import (
 	"github.com/emicklei/go-restful"
+	"github.com/emicklei/go-restful-openapi"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 const (
 	ApiRootPath = "/kapis"
+	// live OpenAPI spec of the registered webservices, aggregated by the apigateway
+	OpenAPIPath = "/apidocs.json"
 )
 
 // container holds all webservice of apiserver
@@ -42,6 +45,15 @@ func NewWebService(gv schema.GroupVersion) *restful.WebService {
 	return &webservice
 }
 
+// InstallOpenAPIService must be called after all webservices are registered
+func InstallOpenAPIService(c *restful.Container) {
+	config := restfulspec.Config{
+		WebServices: c.RegisteredWebServices(),
+		APIPath:     OpenAPIPath,
+	}
+	c.Add(restfulspec.NewOpenAPIService(config))
+}
+
 func (cb *ContainerBuilder) AddToContainer(c *restful.Container) error {
 	for _, f := range *cb {
 		if err := f(c); err != nil {
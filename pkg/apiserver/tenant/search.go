@@ -0,0 +1,50 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package tenant
+
+import (
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+	"github.com/golang/glog"
+
+	"kubesphere.io/kubesphere/pkg/errors"
+	"kubesphere.io/kubesphere/pkg/models/search"
+	"kubesphere.io/kubesphere/pkg/params"
+)
+
+func Search(req *restful.Request, resp *restful.Response) {
+	query := req.QueryParameter("q")
+	kinds := params.ParseFields(req.QueryParameter("kinds"))
+	limit, offset := params.ParsePaging(req.QueryParameter(params.PagingParam))
+
+	if query == "" {
+		resp.WriteHeaderAndEntity(http.StatusBadRequest, errors.New("q is required"))
+		return
+	}
+
+	result, err := search.Search(params.ParseIdentity(req), query, kinds, limit, offset, req.Request)
+
+	if err != nil {
+		glog.Errorln(err)
+		resp.WriteHeaderAndEntity(http.StatusInternalServerError, errors.Wrap(err))
+		return
+	}
+
+	resp.WriteAsJson(result)
+}
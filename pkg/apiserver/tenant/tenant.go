@@ -37,6 +37,7 @@ import (
 	"kubesphere.io/kubesphere/pkg/params"
 
 	"kubesphere.io/kubesphere/pkg/simple/client/elasticsearch"
+	"kubesphere.io/kubesphere/pkg/utils/k8sutil"
 	"kubesphere.io/kubesphere/pkg/utils/sliceutil"
 	"net/http"
 	"strings"
@@ -44,9 +45,8 @@ import (
 
 func ListWorkspaceRules(req *restful.Request, resp *restful.Response) {
 	workspace := req.PathParameter("workspace")
-	username := req.HeaderParameter(constants.UserNameHeader)
 
-	rules, err := iam.GetUserWorkspaceSimpleRules(workspace, username)
+	rules, err := iam.GetUserWorkspaceSimpleRules(workspace, params.ParseIdentity(req))
 
 	if err != nil {
 		resp.WriteHeaderAndEntity(http.StatusInternalServerError, errors.Wrap(err))
@@ -57,7 +57,7 @@ func ListWorkspaceRules(req *restful.Request, resp *restful.Response) {
 }
 
 func ListWorkspaces(req *restful.Request, resp *restful.Response) {
-	username := req.HeaderParameter(constants.UserNameHeader)
+	identity := params.ParseIdentity(req)
 	conditions, err := params.ParseConditions(req.QueryParameter(params.ConditionsParam))
 	orderBy := req.QueryParameter(params.OrderByParam)
 	limit, offset := params.ParsePaging(req.QueryParameter(params.PagingParam))
@@ -73,7 +73,7 @@ func ListWorkspaces(req *restful.Request, resp *restful.Response) {
 		return
 	}
 
-	result, err := tenant.ListWorkspaces(username, conditions, orderBy, reverse, limit, offset)
+	result, err := tenant.ListWorkspaces(identity, conditions, orderBy, reverse, limit, offset)
 
 	if err != nil {
 		resp.WriteHeaderAndEntity(http.StatusInternalServerError, errors.Wrap(err))
@@ -84,10 +84,9 @@ func ListWorkspaces(req *restful.Request, resp *restful.Response) {
 }
 
 func DescribeWorkspace(req *restful.Request, resp *restful.Response) {
-	username := req.HeaderParameter(constants.UserNameHeader)
 	workspaceName := req.PathParameter("workspace")
 
-	result, err := tenant.DescribeWorkspace(username, workspaceName)
+	result, err := tenant.DescribeWorkspace(params.ParseIdentity(req), workspaceName)
 
 	if err != nil {
 		glog.Errorf("describe workspace failed: %+v", err)
@@ -107,11 +106,10 @@ func ListNamespacesByUsername(req *restful.Request, resp *restful.Response) {
 
 func ListNamespaces(req *restful.Request, resp *restful.Response) {
 	workspace := req.PathParameter("workspace")
-	username := req.PathParameter("member")
+	identity := params.ParseIdentity(req)
 	// /workspaces/{workspace}/members/{username}/namespaces
-	if username == "" {
-		// /workspaces/{workspace}/namespaces
-		username = req.HeaderParameter(constants.UserNameHeader)
+	if member := req.PathParameter("member"); member != "" {
+		identity = k8sutil.NewIdentity(member, rbacv1.UserKind)
 	}
 
 	conditions, err := params.ParseConditions(req.QueryParameter(params.ConditionsParam))
@@ -126,7 +124,7 @@ func ListNamespaces(req *restful.Request, resp *restful.Response) {
 
 	conditions.Match[constants.WorkspaceLabelKey] = workspace
 
-	result, err := tenant.ListNamespaces(username, conditions, orderBy, reverse, limit, offset)
+	result, err := tenant.ListNamespaces(identity, conditions, orderBy, reverse, limit, offset)
 
 	if err != nil {
 		resp.WriteHeaderAndEntity(http.StatusInternalServerError, errors.Wrap(err))
@@ -297,9 +295,8 @@ func CreateDevopsProject(req *restful.Request, resp *restful.Response) {
 
 func ListNamespaceRules(req *restful.Request, resp *restful.Response) {
 	namespace := req.PathParameter("namespace")
-	username := req.HeaderParameter(constants.UserNameHeader)
 
-	rules, err := iam.GetUserNamespaceSimpleRules(namespace, username)
+	rules, err := iam.GetUserNamespaceSimpleRules(namespace, params.ParseIdentity(req))
 
 	if err != nil {
 		resp.WriteError(http.StatusInternalServerError, err)
@@ -327,13 +324,13 @@ func ListDevopsRules(req *restful.Request, resp *restful.Response) {
 
 func LogQuery(req *restful.Request, resp *restful.Response) {
 
-	username := req.HeaderParameter(constants.UserNameHeader)
+	identity := params.ParseIdentity(req)
 
 	// regenerate the request for log query
 	newUrl := net.FormatURL("http", "127.0.0.1", 80, "/kapis/logging.kubesphere.io/v1alpha2/cluster")
 	values := req.Request.URL.Query()
 
-	clusterRules, err := iam.GetUserClusterRules(username)
+	clusterRules, err := iam.GetUserClusterRules(identity)
 	if err != nil {
 		resp.WriteHeaderAndEntity(http.StatusInternalServerError, errors.Wrap(err))
 		glog.Errorln(err)
@@ -346,7 +343,7 @@ func LogQuery(req *restful.Request, resp *restful.Response) {
 		queryNamespaces := strings.Split(req.QueryParameter("namespaces"), ",")
 		// then the user can only view logs of namespaces he belongs to
 		namespaces := make([]string, 0)
-		roles, err := iam.GetUserRoles("", username)
+		roles, err := iam.GetUserRoles("", identity)
 		if err != nil {
 			resp.WriteHeaderAndEntity(http.StatusInternalServerError, errors.Wrap(err))
 			glog.Errorln(err)
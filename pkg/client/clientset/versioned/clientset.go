@@ -21,7 +21,10 @@ import (
 	discovery "k8s.io/client-go/discovery"
 	rest "k8s.io/client-go/rest"
 	flowcontrol "k8s.io/client-go/util/flowcontrol"
+	alertingv1alpha1 "kubesphere.io/kubesphere/pkg/client/clientset/versioned/typed/alerting/v1alpha1"
 	devopsv1alpha1 "kubesphere.io/kubesphere/pkg/client/clientset/versioned/typed/devops/v1alpha1"
+	loggingv1alpha1 "kubesphere.io/kubesphere/pkg/client/clientset/versioned/typed/logging/v1alpha1"
+	monitoringv1alpha1 "kubesphere.io/kubesphere/pkg/client/clientset/versioned/typed/monitoring/v1alpha1"
 	networkv1alpha1 "kubesphere.io/kubesphere/pkg/client/clientset/versioned/typed/network/v1alpha1"
 	servicemeshv1alpha2 "kubesphere.io/kubesphere/pkg/client/clientset/versioned/typed/servicemesh/v1alpha2"
 	tenantv1alpha1 "kubesphere.io/kubesphere/pkg/client/clientset/versioned/typed/tenant/v1alpha1"
@@ -29,9 +32,18 @@ import (
 
 type Interface interface {
 	Discovery() discovery.DiscoveryInterface
+	AlertingV1alpha1() alertingv1alpha1.AlertingV1alpha1Interface
+	// Deprecated: please explicitly pick a version if possible.
+	Alerting() alertingv1alpha1.AlertingV1alpha1Interface
 	DevopsV1alpha1() devopsv1alpha1.DevopsV1alpha1Interface
 	// Deprecated: please explicitly pick a version if possible.
 	Devops() devopsv1alpha1.DevopsV1alpha1Interface
+	LoggingV1alpha1() loggingv1alpha1.LoggingV1alpha1Interface
+	// Deprecated: please explicitly pick a version if possible.
+	Logging() loggingv1alpha1.LoggingV1alpha1Interface
+	MonitoringV1alpha1() monitoringv1alpha1.MonitoringV1alpha1Interface
+	// Deprecated: please explicitly pick a version if possible.
+	Monitoring() monitoringv1alpha1.MonitoringV1alpha1Interface
 	NetworkV1alpha1() networkv1alpha1.NetworkV1alpha1Interface
 	// Deprecated: please explicitly pick a version if possible.
 	Network() networkv1alpha1.NetworkV1alpha1Interface
@@ -47,12 +59,26 @@ type Interface interface {
 // version included in a Clientset.
 type Clientset struct {
 	*discovery.DiscoveryClient
+	alertingV1alpha1    *alertingv1alpha1.AlertingV1alpha1Client
 	devopsV1alpha1      *devopsv1alpha1.DevopsV1alpha1Client
+	loggingV1alpha1     *loggingv1alpha1.LoggingV1alpha1Client
+	monitoringV1alpha1  *monitoringv1alpha1.MonitoringV1alpha1Client
 	networkV1alpha1     *networkv1alpha1.NetworkV1alpha1Client
 	servicemeshV1alpha2 *servicemeshv1alpha2.ServicemeshV1alpha2Client
 	tenantV1alpha1      *tenantv1alpha1.TenantV1alpha1Client
 }
 
+// AlertingV1alpha1 retrieves the AlertingV1alpha1Client
+func (c *Clientset) AlertingV1alpha1() alertingv1alpha1.AlertingV1alpha1Interface {
+	return c.alertingV1alpha1
+}
+
+// Deprecated: Alerting retrieves the default version of AlertingClient.
+// Please explicitly pick a version.
+func (c *Clientset) Alerting() alertingv1alpha1.AlertingV1alpha1Interface {
+	return c.alertingV1alpha1
+}
+
 // DevopsV1alpha1 retrieves the DevopsV1alpha1Client
 func (c *Clientset) DevopsV1alpha1() devopsv1alpha1.DevopsV1alpha1Interface {
 	return c.devopsV1alpha1
@@ -64,6 +90,28 @@ func (c *Clientset) Devops() devopsv1alpha1.DevopsV1alpha1Interface {
 	return c.devopsV1alpha1
 }
 
+// LoggingV1alpha1 retrieves the LoggingV1alpha1Client
+func (c *Clientset) LoggingV1alpha1() loggingv1alpha1.LoggingV1alpha1Interface {
+	return c.loggingV1alpha1
+}
+
+// Deprecated: Logging retrieves the default version of LoggingClient.
+// Please explicitly pick a version.
+func (c *Clientset) Logging() loggingv1alpha1.LoggingV1alpha1Interface {
+	return c.loggingV1alpha1
+}
+
+// MonitoringV1alpha1 retrieves the MonitoringV1alpha1Client
+func (c *Clientset) MonitoringV1alpha1() monitoringv1alpha1.MonitoringV1alpha1Interface {
+	return c.monitoringV1alpha1
+}
+
+// Deprecated: Monitoring retrieves the default version of MonitoringClient.
+// Please explicitly pick a version.
+func (c *Clientset) Monitoring() monitoringv1alpha1.MonitoringV1alpha1Interface {
+	return c.monitoringV1alpha1
+}
+
 // NetworkV1alpha1 retrieves the NetworkV1alpha1Client
 func (c *Clientset) NetworkV1alpha1() networkv1alpha1.NetworkV1alpha1Interface {
 	return c.networkV1alpha1
@@ -113,10 +161,22 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 	}
 	var cs Clientset
 	var err error
+	cs.alertingV1alpha1, err = alertingv1alpha1.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
 	cs.devopsV1alpha1, err = devopsv1alpha1.NewForConfig(&configShallowCopy)
 	if err != nil {
 		return nil, err
 	}
+	cs.loggingV1alpha1, err = loggingv1alpha1.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
+	cs.monitoringV1alpha1, err = monitoringv1alpha1.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
 	cs.networkV1alpha1, err = networkv1alpha1.NewForConfig(&configShallowCopy)
 	if err != nil {
 		return nil, err
@@ -141,7 +201,10 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 // panics if there is an error in the config.
 func NewForConfigOrDie(c *rest.Config) *Clientset {
 	var cs Clientset
+	cs.alertingV1alpha1 = alertingv1alpha1.NewForConfigOrDie(c)
 	cs.devopsV1alpha1 = devopsv1alpha1.NewForConfigOrDie(c)
+	cs.loggingV1alpha1 = loggingv1alpha1.NewForConfigOrDie(c)
+	cs.monitoringV1alpha1 = monitoringv1alpha1.NewForConfigOrDie(c)
 	cs.networkV1alpha1 = networkv1alpha1.NewForConfigOrDie(c)
 	cs.servicemeshV1alpha2 = servicemeshv1alpha2.NewForConfigOrDie(c)
 	cs.tenantV1alpha1 = tenantv1alpha1.NewForConfigOrDie(c)
@@ -153,7 +216,10 @@ func NewForConfigOrDie(c *rest.Config) *Clientset {
 // New creates a new Clientset for the given RESTClient.
 func New(c rest.Interface) *Clientset {
 	var cs Clientset
+	cs.alertingV1alpha1 = alertingv1alpha1.New(c)
 	cs.devopsV1alpha1 = devopsv1alpha1.New(c)
+	cs.loggingV1alpha1 = loggingv1alpha1.New(c)
+	cs.monitoringV1alpha1 = monitoringv1alpha1.New(c)
 	cs.networkV1alpha1 = networkv1alpha1.New(c)
 	cs.servicemeshV1alpha2 = servicemeshv1alpha2.New(c)
 	cs.tenantV1alpha1 = tenantv1alpha1.New(c)
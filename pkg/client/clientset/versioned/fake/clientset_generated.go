@@ -24,8 +24,14 @@ import (
 	fakediscovery "k8s.io/client-go/discovery/fake"
 	"k8s.io/client-go/testing"
 	clientset "kubesphere.io/kubesphere/pkg/client/clientset/versioned"
+	alertingv1alpha1 "kubesphere.io/kubesphere/pkg/client/clientset/versioned/typed/alerting/v1alpha1"
+	fakealertingv1alpha1 "kubesphere.io/kubesphere/pkg/client/clientset/versioned/typed/alerting/v1alpha1/fake"
 	devopsv1alpha1 "kubesphere.io/kubesphere/pkg/client/clientset/versioned/typed/devops/v1alpha1"
 	fakedevopsv1alpha1 "kubesphere.io/kubesphere/pkg/client/clientset/versioned/typed/devops/v1alpha1/fake"
+	loggingv1alpha1 "kubesphere.io/kubesphere/pkg/client/clientset/versioned/typed/logging/v1alpha1"
+	fakeloggingv1alpha1 "kubesphere.io/kubesphere/pkg/client/clientset/versioned/typed/logging/v1alpha1/fake"
+	monitoringv1alpha1 "kubesphere.io/kubesphere/pkg/client/clientset/versioned/typed/monitoring/v1alpha1"
+	fakemonitoringv1alpha1 "kubesphere.io/kubesphere/pkg/client/clientset/versioned/typed/monitoring/v1alpha1/fake"
 	networkv1alpha1 "kubesphere.io/kubesphere/pkg/client/clientset/versioned/typed/network/v1alpha1"
 	fakenetworkv1alpha1 "kubesphere.io/kubesphere/pkg/client/clientset/versioned/typed/network/v1alpha1/fake"
 	servicemeshv1alpha2 "kubesphere.io/kubesphere/pkg/client/clientset/versioned/typed/servicemesh/v1alpha2"
@@ -76,6 +82,16 @@ func (c *Clientset) Discovery() discovery.DiscoveryInterface {
 
 var _ clientset.Interface = &Clientset{}
 
+// AlertingV1alpha1 retrieves the AlertingV1alpha1Client
+func (c *Clientset) AlertingV1alpha1() alertingv1alpha1.AlertingV1alpha1Interface {
+	return &fakealertingv1alpha1.FakeAlertingV1alpha1{Fake: &c.Fake}
+}
+
+// Alerting retrieves the AlertingV1alpha1Client
+func (c *Clientset) Alerting() alertingv1alpha1.AlertingV1alpha1Interface {
+	return &fakealertingv1alpha1.FakeAlertingV1alpha1{Fake: &c.Fake}
+}
+
 // DevopsV1alpha1 retrieves the DevopsV1alpha1Client
 func (c *Clientset) DevopsV1alpha1() devopsv1alpha1.DevopsV1alpha1Interface {
 	return &fakedevopsv1alpha1.FakeDevopsV1alpha1{Fake: &c.Fake}
@@ -86,6 +102,26 @@ func (c *Clientset) Devops() devopsv1alpha1.DevopsV1alpha1Interface {
 	return &fakedevopsv1alpha1.FakeDevopsV1alpha1{Fake: &c.Fake}
 }
 
+// LoggingV1alpha1 retrieves the LoggingV1alpha1Client
+func (c *Clientset) LoggingV1alpha1() loggingv1alpha1.LoggingV1alpha1Interface {
+	return &fakeloggingv1alpha1.FakeLoggingV1alpha1{Fake: &c.Fake}
+}
+
+// Logging retrieves the LoggingV1alpha1Client
+func (c *Clientset) Logging() loggingv1alpha1.LoggingV1alpha1Interface {
+	return &fakeloggingv1alpha1.FakeLoggingV1alpha1{Fake: &c.Fake}
+}
+
+// MonitoringV1alpha1 retrieves the MonitoringV1alpha1Client
+func (c *Clientset) MonitoringV1alpha1() monitoringv1alpha1.MonitoringV1alpha1Interface {
+	return &fakemonitoringv1alpha1.FakeMonitoringV1alpha1{Fake: &c.Fake}
+}
+
+// Monitoring retrieves the MonitoringV1alpha1Client
+func (c *Clientset) Monitoring() monitoringv1alpha1.MonitoringV1alpha1Interface {
+	return &fakemonitoringv1alpha1.FakeMonitoringV1alpha1{Fake: &c.Fake}
+}
+
 // NetworkV1alpha1 retrieves the NetworkV1alpha1Client
 func (c *Clientset) NetworkV1alpha1() networkv1alpha1.NetworkV1alpha1Interface {
 	return &fakenetworkv1alpha1.FakeNetworkV1alpha1{Fake: &c.Fake}
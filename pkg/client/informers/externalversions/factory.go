@@ -27,8 +27,11 @@ import (
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
 	cache "k8s.io/client-go/tools/cache"
 	versioned "kubesphere.io/kubesphere/pkg/client/clientset/versioned"
+	alerting "kubesphere.io/kubesphere/pkg/client/informers/externalversions/alerting"
 	devops "kubesphere.io/kubesphere/pkg/client/informers/externalversions/devops"
 	internalinterfaces "kubesphere.io/kubesphere/pkg/client/informers/externalversions/internalinterfaces"
+	logging "kubesphere.io/kubesphere/pkg/client/informers/externalversions/logging"
+	monitoring "kubesphere.io/kubesphere/pkg/client/informers/externalversions/monitoring"
 	network "kubesphere.io/kubesphere/pkg/client/informers/externalversions/network"
 	servicemesh "kubesphere.io/kubesphere/pkg/client/informers/externalversions/servicemesh"
 	tenant "kubesphere.io/kubesphere/pkg/client/informers/externalversions/tenant"
@@ -174,16 +177,31 @@ type SharedInformerFactory interface {
 	ForResource(resource schema.GroupVersionResource) (GenericInformer, error)
 	WaitForCacheSync(stopCh <-chan struct{}) map[reflect.Type]bool
 
+	Alerting() alerting.Interface
 	Devops() devops.Interface
+	Logging() logging.Interface
+	Monitoring() monitoring.Interface
 	Network() network.Interface
 	Servicemesh() servicemesh.Interface
 	Tenant() tenant.Interface
 }
 
+func (f *sharedInformerFactory) Alerting() alerting.Interface {
+	return alerting.New(f, f.namespace, f.tweakListOptions)
+}
+
 func (f *sharedInformerFactory) Devops() devops.Interface {
 	return devops.New(f, f.namespace, f.tweakListOptions)
 }
 
+func (f *sharedInformerFactory) Logging() logging.Interface {
+	return logging.New(f, f.namespace, f.tweakListOptions)
+}
+
+func (f *sharedInformerFactory) Monitoring() monitoring.Interface {
+	return monitoring.New(f, f.namespace, f.tweakListOptions)
+}
+
 func (f *sharedInformerFactory) Network() network.Interface {
 	return network.New(f, f.namespace, f.tweakListOptions)
 }
@@ -22,7 +22,10 @@ import (
 
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
 	cache "k8s.io/client-go/tools/cache"
-	v1alpha1 "kubesphere.io/kubesphere/pkg/apis/devops/v1alpha1"
+	v1alpha1 "kubesphere.io/kubesphere/pkg/apis/alerting/v1alpha1"
+	devopsv1alpha1 "kubesphere.io/kubesphere/pkg/apis/devops/v1alpha1"
+	loggingv1alpha1 "kubesphere.io/kubesphere/pkg/apis/logging/v1alpha1"
+	monitoringv1alpha1 "kubesphere.io/kubesphere/pkg/apis/monitoring/v1alpha1"
 	networkv1alpha1 "kubesphere.io/kubesphere/pkg/apis/network/v1alpha1"
 	v1alpha2 "kubesphere.io/kubesphere/pkg/apis/servicemesh/v1alpha2"
 	tenantv1alpha1 "kubesphere.io/kubesphere/pkg/apis/tenant/v1alpha1"
@@ -54,10 +57,30 @@ func (f *genericInformer) Lister() cache.GenericLister {
 // TODO extend this to unknown resources with a client pool
 func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource) (GenericInformer, error) {
 	switch resource {
-	// Group=devops.kubesphere.io, Version=v1alpha1
-	case v1alpha1.SchemeGroupVersion.WithResource("s2ibinaries"):
+	// Group=alerting.kubesphere.io, Version=v1alpha1
+	case v1alpha1.SchemeGroupVersion.WithResource("alertnotificationpolicies"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Alerting().V1alpha1().AlertNotificationPolicies().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("alertreceivers"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Alerting().V1alpha1().AlertReceivers().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("alertrules"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Alerting().V1alpha1().AlertRules().Informer()}, nil
+
+		// Group=devops.kubesphere.io, Version=v1alpha1
+	case devopsv1alpha1.SchemeGroupVersion.WithResource("s2ibinaries"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Devops().V1alpha1().S2iBinaries().Informer()}, nil
 
+		// Group=logging.kubesphere.io, Version=v1alpha1
+	case loggingv1alpha1.SchemeGroupVersion.WithResource("logalertrules"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Logging().V1alpha1().LogAlertRules().Informer()}, nil
+	case loggingv1alpha1.SchemeGroupVersion.WithResource("savedlogqueries"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Logging().V1alpha1().SavedLogQueries().Informer()}, nil
+
+		// Group=monitoring.kubesphere.io, Version=v1alpha1
+	case monitoringv1alpha1.SchemeGroupVersion.WithResource("dashboards"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Monitoring().V1alpha1().Dashboards().Informer()}, nil
+	case monitoringv1alpha1.SchemeGroupVersion.WithResource("meteringrollups"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Monitoring().V1alpha1().MeteringRollups().Informer()}, nil
+
 		// Group=network.kubesphere.io, Version=v1alpha1
 	case networkv1alpha1.SchemeGroupVersion.WithResource("workspacenetworkpolicies"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Network().V1alpha1().WorkspaceNetworkPolicies().Informer()}, nil
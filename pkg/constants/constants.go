@@ -48,6 +48,8 @@ const (
 	DevopsReporter                 = "reporter"
 
 	UserNameHeader = "X-Token-Username"
+	// UserKindHeader is ServiceAccount if the user was authenticated with a service account token
+	UserKindHeader = "X-Token-Kind"
 
 	TenantResourcesTag         = "Tenant Resources"
 	IdentityManagementTag      = "Identity Management"
@@ -72,7 +74,13 @@ const (
 	WorkloadMetricsTag         = "Workload Metrics"
 	WorkspaceMetricsTag        = "Workspace Metrics"
 	ComponentMetricsTag        = "Component Metrics"
+	PromQLQueryTag             = "PromQL Query"
+	DashboardTag               = "Dashboard"
+	AlertingTag                = "Alerting"
+	MeteringTag                = "Metering"
 	LogQueryTag                = "Log Query"
+	EventQueryTag              = "Event Query"
+	SavedLogQueryTag           = "Saved Log Query"
 	FluentBitSetting           = "Fluent Bit Setting"
 )
 
@@ -0,0 +1,313 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+
+package alerting
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	alertingv1alpha1 "kubesphere.io/kubesphere/pkg/apis/alerting/v1alpha1"
+	"kubesphere.io/kubesphere/pkg/constants"
+	"kubesphere.io/kubesphere/pkg/simple/client/kubesphere"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	prometheusRuleNamePrefix = "alertrule-"
+	defaultSeverity          = "warning"
+)
+
+var (
+	log = logf.Log.WithName("alertrule-controller")
+
+	prometheusRuleGVK = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "PrometheusRule"}
+
+	ruleLabels          string
+	componentRuleLabels string
+)
+
+func init() {
+	flag.StringVar(&ruleLabels, "alerting-rule-labels", "prometheus=k8s,role=alert-rules", "labels of PrometheusRules rendered from alert rules, selected by the ruleSelector of Prometheus")
+	flag.StringVar(&componentRuleLabels, "alerting-component-rule-labels", "prometheus=k8s-system,role=alert-rules", "labels of PrometheusRules rendered from component alert rules")
+}
+
+// AddAlertRule creates a new AlertRule Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func AddAlertRule(mgr manager.Manager) error {
+	return addAlertRule(mgr, newAlertRuleReconciler(mgr))
+}
+
+// newAlertRuleReconciler returns a new reconcile.Reconciler
+func newAlertRuleReconciler(mgr manager.Manager) reconcile.Reconciler {
+	return &ReconcileAlertRule{Client: mgr.GetClient(), scheme: mgr.GetScheme(),
+		recorder: mgr.GetRecorder("alertrule-controller"), ksclient: kubesphere.Client()}
+}
+
+// addAlertRule adds a new Controller to mgr with r as the reconcile.Reconciler
+func addAlertRule(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New("alertrule-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to AlertRule, PrometheusRules are owned by rules and garbage collected with them
+	err = c.Watch(&source.Kind{Type: &alertingv1alpha1.AlertRule{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+var _ reconcile.Reconciler = &ReconcileAlertRule{}
+
+// ReconcileAlertRule renders AlertRules into PrometheusRules
+type ReconcileAlertRule struct {
+	client.Client
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
+	ksclient kubesphere.Interface
+}
+
+// Reconcile renders the rule into a PrometheusRule with tenant labels of the rule
+// +kubebuilder:rbac:groups=alerting.kubesphere.io,resources=alertrules,verbs=get;list;watch
+// +kubebuilder:rbac:groups=alerting.kubesphere.io,resources=alertrules/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=prometheusrules,verbs=get;list;watch;create;update;patch;delete
+func (r *ReconcileAlertRule) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	instance := &alertingv1alpha1.AlertRule{}
+	err := r.Get(context.TODO(), request.NamespacedName, instance)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	// status updates trigger reconciliation as well
+	if instance.Status.ObservedGeneration == instance.Generation && instance.Status.State == alertingv1alpha1.AlertRuleStateRendered {
+		return reconcile.Result{}, nil
+	}
+
+	status := alertingv1alpha1.AlertRuleStatus{ObservedGeneration: instance.Generation}
+
+	expression, err := r.ksclient.RenderAlertRule(instance.Namespace, instance.Spec)
+	if err != nil {
+		if !kubesphere.IsBadRequest(err) {
+			return reconcile.Result{}, err
+		}
+		// invalid rules are not retried until they are updated
+		status.State = alertingv1alpha1.AlertRuleStateError
+		status.Message = err.Error()
+		r.recorder.Event(instance, corev1.EventTypeWarning, "RenderFailed", err.Error())
+		return reconcile.Result{}, r.updateStatus(instance, status)
+	}
+
+	status.Expression = expression
+
+	workspace, err := r.workspaceOf(instance)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	rule, err := newPrometheusRule(instance, expression, workspace)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if err := controllerutil.SetControllerReference(instance, rule, r.scheme); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if err := r.apply(rule); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	status.State = alertingv1alpha1.AlertRuleStateRendered
+	return reconcile.Result{}, r.updateStatus(instance, status)
+}
+
+func (r *ReconcileAlertRule) updateStatus(instance *alertingv1alpha1.AlertRule, status alertingv1alpha1.AlertRuleStatus) error {
+	if reflect.DeepEqual(instance.Status, status) {
+		return nil
+	}
+	instance.Status = status
+	return r.Status().Update(context.TODO(), instance)
+}
+
+// workspaceOf returns the workspace of alerts of the rule, workspace rules are in the monitoring system namespace,
+// alerts of them belong to the workspace in scope
+func (r *ReconcileAlertRule) workspaceOf(rule *alertingv1alpha1.AlertRule) (string, error) {
+	if rule.Spec.Level == alertingv1alpha1.AlertRuleLevelWorkspace {
+		return rule.Spec.Scope.Workspace, nil
+	}
+
+	if !isTenantLevel(rule.Spec.Level) {
+		return "", nil
+	}
+
+	namespace := &corev1.Namespace{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: rule.Namespace}, namespace); err != nil {
+		return "", err
+	}
+	return namespace.Labels[constants.WorkspaceLabelKey], nil
+}
+
+// apply creates the PrometheusRule or updates the existing one, the cache of the manager client is bypassed for unstructured objects
+func (r *ReconcileAlertRule) apply(rule *unstructured.Unstructured) error {
+	found := &unstructured.Unstructured{}
+	found.SetGroupVersionKind(prometheusRuleGVK)
+
+	err := r.Get(context.TODO(), types.NamespacedName{Namespace: rule.GetNamespace(), Name: rule.GetName()}, found)
+	if errors.IsNotFound(err) {
+		log.Info("Creating PrometheusRule", "namespace", rule.GetNamespace(), "name", rule.GetName())
+		return r.Create(context.TODO(), rule)
+	} else if err != nil {
+		return err
+	}
+
+	if reflect.DeepEqual(found.Object["spec"], rule.Object["spec"]) && reflect.DeepEqual(found.GetLabels(), rule.GetLabels()) {
+		return nil
+	}
+
+	found.Object["spec"] = rule.Object["spec"]
+	found.SetLabels(rule.GetLabels())
+	log.Info("Updating PrometheusRule", "namespace", rule.GetNamespace(), "name", rule.GetName())
+	return r.Update(context.TODO(), found)
+}
+
+func isTenantLevel(level alertingv1alpha1.AlertRuleLevel) bool {
+	switch level {
+	case alertingv1alpha1.AlertRuleLevelNamespace, alertingv1alpha1.AlertRuleLevelWorkload,
+		alertingv1alpha1.AlertRuleLevelPod, alertingv1alpha1.AlertRuleLevelContainer:
+		return true
+	}
+	return false
+}
+
+// alertLabels returns labels of alerts of the rule, tenant labels take precedence over labels of the rule,
+// so alerts can't be routed into other tenants
+func alertLabels(rule *alertingv1alpha1.AlertRule, workspace string) map[string]interface{} {
+	labels := make(map[string]interface{})
+	for k, v := range rule.Spec.Labels {
+		labels[k] = v
+	}
+
+	severity := rule.Spec.Severity
+	if severity == "" {
+		severity = defaultSeverity
+	}
+	labels[alertingv1alpha1.AlertSeverityLabel] = severity
+	labels[alertingv1alpha1.AlertRuleLabel] = fmt.Sprintf("%s/%s", rule.Namespace, rule.Name)
+
+	delete(labels, alertingv1alpha1.AlertNamespaceLabel)
+	delete(labels, alertingv1alpha1.AlertWorkspaceLabel)
+	if isTenantLevel(rule.Spec.Level) {
+		labels[alertingv1alpha1.AlertNamespaceLabel] = rule.Namespace
+	}
+	if workspace != "" {
+		labels[alertingv1alpha1.AlertWorkspaceLabel] = workspace
+	}
+
+	return labels
+}
+
+// promDuration formats the duration in seconds, Prometheus and Alertmanager don't accept compound durations like 5m0s
+func promDuration(d time.Duration) string {
+	return fmt.Sprintf("%ds", int64(d/time.Second))
+}
+
+// parseLabels parses labels in the form of k1=v1,k2=v2
+func parseLabels(s string) (map[string]string, error) {
+	labels := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("invalid label %q", pair)
+		}
+		labels[kv[0]] = kv[1]
+	}
+	return labels, nil
+}
+
+// newPrometheusRule returns the PrometheusRule of the alert rule with a single rule alerting on the expression
+func newPrometheusRule(rule *alertingv1alpha1.AlertRule, expression, workspace string) (*unstructured.Unstructured, error) {
+	selector := ruleLabels
+	if rule.Spec.Level == alertingv1alpha1.AlertRuleLevelComponent {
+		selector = componentRuleLabels
+	}
+	labels, err := parseLabels(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	alert := map[string]interface{}{
+		"alert":  rule.Name,
+		"expr":   expression,
+		"labels": alertLabels(rule, workspace),
+	}
+	if rule.Spec.For != nil && rule.Spec.For.Duration > 0 {
+		alert["for"] = promDuration(rule.Spec.For.Duration)
+	}
+	if len(rule.Spec.Annotations) > 0 {
+		annotations := make(map[string]interface{})
+		for k, v := range rule.Spec.Annotations {
+			annotations[k] = v
+		}
+		alert["annotations"] = annotations
+	}
+
+	prometheusRule := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"groups": []interface{}{
+				map[string]interface{}{
+					"name":  fmt.Sprintf("%s.%s", rule.Namespace, rule.Name),
+					"rules": []interface{}{alert},
+				},
+			},
+		},
+	}}
+	prometheusRule.SetGroupVersionKind(prometheusRuleGVK)
+	prometheusRule.SetNamespace(rule.Namespace)
+	prometheusRule.SetName(prometheusRuleNamePrefix + rule.Name)
+	prometheusRule.SetLabels(labels)
+
+	return prometheusRule, nil
+}
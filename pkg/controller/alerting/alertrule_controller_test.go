@@ -0,0 +1,146 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+
+package alerting
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	alertingv1alpha1 "kubesphere.io/kubesphere/pkg/apis/alerting/v1alpha1"
+)
+
+func TestNewPrometheusRule(t *testing.T) {
+	rule := &alertingv1alpha1.AlertRule{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: "memory-high"},
+		Spec: alertingv1alpha1.AlertRuleSpec{
+			Level:       alertingv1alpha1.AlertRuleLevelNamespace,
+			Metric:      "namespace_memory_usage",
+			Operator:    ">",
+			Threshold:   "1e9",
+			For:         &metav1.Duration{Duration: 5 * time.Minute},
+			Labels:      map[string]string{"team": "web", "namespace": "kube-system", "workspace": "other"},
+			Annotations: map[string]string{"summary": "memory usage is high"},
+		},
+	}
+
+	prometheusRule, err := newPrometheusRule(rule, "expr", "ws")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if prometheusRule.GetNamespace() != "demo" || prometheusRule.GetName() != "alertrule-memory-high" {
+		t.Errorf("unexpected PrometheusRule %s/%s", prometheusRule.GetNamespace(), prometheusRule.GetName())
+	}
+
+	if labels := prometheusRule.GetLabels(); !reflect.DeepEqual(labels, map[string]string{"prometheus": "k8s", "role": "alert-rules"}) {
+		t.Errorf("unexpected labels %v", labels)
+	}
+
+	expected := map[string]interface{}{
+		"groups": []interface{}{
+			map[string]interface{}{
+				"name": "demo.memory-high",
+				"rules": []interface{}{
+					map[string]interface{}{
+						"alert": "memory-high",
+						"expr":  "expr",
+						"for":   "300s",
+						"labels": map[string]interface{}{
+							"team":      "web",
+							"severity":  "warning",
+							"alertrule": "demo/memory-high",
+							"namespace": "demo",
+							"workspace": "ws",
+						},
+						"annotations": map[string]interface{}{"summary": "memory usage is high"},
+					},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(prometheusRule.Object["spec"], expected) {
+		t.Errorf("expected %v, got %v", expected, prometheusRule.Object["spec"])
+	}
+}
+
+func TestAlertLabels(t *testing.T) {
+	tests := []struct {
+		level     alertingv1alpha1.AlertRuleLevel
+		severity  string
+		workspace string
+		expected  map[string]interface{}
+	}{
+		{
+			level:    alertingv1alpha1.AlertRuleLevelNode,
+			severity: "critical",
+			expected: map[string]interface{}{"severity": "critical", "alertrule": "kubesphere-monitoring-system/rule"},
+		},
+		{
+			level:     alertingv1alpha1.AlertRuleLevelWorkspace,
+			workspace: "ws",
+			expected:  map[string]interface{}{"severity": "warning", "alertrule": "kubesphere-monitoring-system/rule", "workspace": "ws"},
+		},
+		{
+			level:    alertingv1alpha1.AlertRuleLevelPod,
+			expected: map[string]interface{}{"severity": "warning", "alertrule": "kubesphere-monitoring-system/rule", "namespace": "kubesphere-monitoring-system"},
+		},
+	}
+
+	for _, test := range tests {
+		rule := &alertingv1alpha1.AlertRule{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "kubesphere-monitoring-system", Name: "rule"},
+			Spec: alertingv1alpha1.AlertRuleSpec{
+				Level:    test.level,
+				Severity: test.severity,
+				Labels:   map[string]string{"namespace": "demo", "alertrule": "demo/other"},
+			},
+		}
+		if labels := alertLabels(rule, test.workspace); !reflect.DeepEqual(labels, test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.level, test.expected, labels)
+		}
+	}
+}
+
+func TestParseLabels(t *testing.T) {
+	tests := []struct {
+		s        string
+		expected map[string]string
+		err      bool
+	}{
+		{s: "prometheus=k8s, role=alert-rules", expected: map[string]string{"prometheus": "k8s", "role": "alert-rules"}},
+		{s: "", expected: map[string]string{}},
+		{s: "prometheus", err: true},
+		{s: "=k8s", err: true},
+	}
+
+	for _, test := range tests {
+		labels, err := parseLabels(test.s)
+		if test.err {
+			if err == nil {
+				t.Errorf("%q: expected error, got %v", test.s, labels)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(labels, test.expected) {
+			t.Errorf("%q: expected %v, got %v %v", test.s, test.expected, labels, err)
+		}
+	}
+}
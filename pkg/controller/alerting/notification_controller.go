@@ -0,0 +1,388 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+
+package alerting
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	alertingv1alpha1 "kubesphere.io/kubesphere/pkg/apis/alerting/v1alpha1"
+	"kubesphere.io/kubesphere/pkg/constants"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	"sigs.k8s.io/yaml"
+)
+
+// receivers and routes rendered from AlertReceivers and AlertNotificationPolicies are prefixed,
+// others in the config of Alertmanager are kept as they are
+const receiverPrefix = "kubesphere/"
+
+var (
+	alertmanagerNamespace string
+	alertmanagerSecret    string
+	alertmanagerKey       string
+
+	// lookupIP resolves hosts of webhooks
+	lookupIP = net.LookupIP
+)
+
+func init() {
+	flag.StringVar(&alertmanagerNamespace, "alertmanager-secret-namespace", constants.KubeSphereMonitoringNamespace, "namespace of the secret of Alertmanager config")
+	flag.StringVar(&alertmanagerSecret, "alertmanager-secret", "alertmanager-main", "secret of Alertmanager config, alert receivers and notification policies are rendered into it")
+	flag.StringVar(&alertmanagerKey, "alertmanager-secret-key", "alertmanager.yaml", "key of Alertmanager config in the secret")
+}
+
+// AddNotification creates a new notification Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func AddNotification(mgr manager.Manager) error {
+	return addNotification(mgr, &ReconcileNotification{Client: mgr.GetClient()})
+}
+
+// addNotification adds a new Controller to mgr with r as the reconcile.Reconciler
+func addNotification(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New("alertnotification-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	// all receivers and policies are rendered into the single config of Alertmanager
+	toConfig := &handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(func(handler.MapObject) []reconcile.Request {
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: alertmanagerNamespace, Name: alertmanagerSecret}}}
+	})}
+
+	err = c.Watch(&source.Kind{Type: &alertingv1alpha1.AlertReceiver{}}, toConfig)
+	if err != nil {
+		return err
+	}
+
+	err = c.Watch(&source.Kind{Type: &alertingv1alpha1.AlertNotificationPolicy{}}, toConfig)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+var _ reconcile.Reconciler = &ReconcileNotification{}
+
+// ReconcileNotification renders AlertReceivers and AlertNotificationPolicies into the config of Alertmanager
+type ReconcileNotification struct {
+	client.Client
+}
+
+// Reconcile renders receivers and policies of all namespaces into the secret of Alertmanager config
+// +kubebuilder:rbac:groups=alerting.kubesphere.io,resources=alertreceivers,verbs=get;list;watch
+// +kubebuilder:rbac:groups=alerting.kubesphere.io,resources=alertreceivers/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=alerting.kubesphere.io,resources=alertnotificationpolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
+func (r *ReconcileNotification) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	receivers := &alertingv1alpha1.AlertReceiverList{}
+	if err := r.List(context.TODO(), &client.ListOptions{}, receivers); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	policies := &alertingv1alpha1.AlertNotificationPolicyList{}
+	if err := r.List(context.TODO(), &client.ListOptions{}, policies); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.Get(context.TODO(), request.NamespacedName, secret); err != nil {
+		if errors.IsNotFound(err) {
+			log.Info("Alertmanager config not found, notifications are not rendered", "namespace", request.Namespace, "name", request.Name)
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	config, invalid, err := renderAlertmanagerConfig(secret.Data[alertmanagerKey], receivers.Items, policies.Items, constants.KubeSphereMonitoringNamespace)
+	if err != nil {
+		if _, ok := err.(*net.DNSError); ok {
+			return reconcile.Result{}, err
+		}
+		// the config is broken, it's fixed by administrators
+		log.Error(err, "failed to render Alertmanager config", "namespace", request.Namespace, "name", request.Name)
+		return reconcile.Result{}, nil
+	}
+
+	if string(secret.Data[alertmanagerKey]) != string(config) {
+		if secret.Data == nil {
+			secret.Data = make(map[string][]byte)
+		}
+		secret.Data[alertmanagerKey] = config
+		log.Info("Updating Alertmanager config", "namespace", request.Namespace, "name", request.Name)
+		if err := r.Update(context.TODO(), secret); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	// status updates trigger reconciliation as well, they are only updated when receivers change
+	for i := range receivers.Items {
+		receiver := &receivers.Items[i]
+		status := alertingv1alpha1.AlertReceiverStatus{State: alertingv1alpha1.AlertReceiverStateRendered, ObservedGeneration: receiver.Generation}
+		if err := invalid[receiverName(receiver.Namespace, receiver.Name)]; err != nil {
+			status.State = alertingv1alpha1.AlertReceiverStateInvalid
+			status.Message = err.Error()
+		}
+		if reflect.DeepEqual(receiver.Status, status) {
+			continue
+		}
+
+		receiver = receiver.DeepCopy()
+		receiver.Status = status
+		if err := r.Status().Update(context.TODO(), receiver); err != nil && !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+	}
+
+	return reconcile.Result{}, nil
+}
+
+func receiverName(namespace, name string) string {
+	return fmt.Sprintf("%s%s/%s", receiverPrefix, namespace, name)
+}
+
+func isRenderedReceiver(v interface{}) bool {
+	name, _ := v.(string)
+	return strings.HasPrefix(name, receiverPrefix)
+}
+
+// renderAlertmanagerConfig replaces receivers and routes rendered before in the base config with the ones
+// of receivers and policies. Policies out of the system namespace only match alerts of their namespaces.
+// Invalid receivers are left out and returned by their names with the reasons.
+func renderAlertmanagerConfig(base []byte, receivers []alertingv1alpha1.AlertReceiver, policies []alertingv1alpha1.AlertNotificationPolicy, systemNamespace string) ([]byte, map[string]error, error) {
+	config := make(map[string]interface{})
+	if err := yaml.Unmarshal(base, &config); err != nil {
+		return nil, nil, err
+	}
+	if config == nil {
+		config = make(map[string]interface{})
+	}
+
+	route, ok := config["route"].(map[string]interface{})
+	if !ok {
+		if config["route"] != nil {
+			return nil, nil, fmt.Errorf("invalid route in Alertmanager config")
+		}
+		route = make(map[string]interface{})
+		config["route"] = route
+	}
+
+	baseReceivers, _ := config["receivers"].([]interface{})
+	renderedReceivers := make([]interface{}, 0, len(baseReceivers)+len(receivers))
+	for _, receiver := range baseReceivers {
+		if m, ok := receiver.(map[string]interface{}); ok && isRenderedReceiver(m["name"]) {
+			continue
+		}
+		renderedReceivers = append(renderedReceivers, receiver)
+	}
+
+	// receivers and policies are sorted, so the config isn't updated while nothing changes
+	sort.Slice(receivers, func(i, j int) bool {
+		return receiverName(receivers[i].Namespace, receivers[i].Name) < receiverName(receivers[j].Namespace, receivers[j].Name)
+	})
+	sort.Slice(policies, func(i, j int) bool {
+		return receiverName(policies[i].Namespace, policies[i].Name) < receiverName(policies[j].Namespace, policies[j].Name)
+	})
+
+	global, _ := config["global"].(map[string]interface{})
+	smarthost, _ := global["smtp_smarthost"].(string)
+	from, _ := global["smtp_from"].(string)
+	smtp := smarthost != "" && from != ""
+
+	existing := make(map[string]bool)
+	invalid := make(map[string]error)
+	for _, receiver := range receivers {
+		name := receiverName(receiver.Namespace, receiver.Name)
+		if err := validateReceiver(receiver.Spec, smtp); err != nil {
+			if _, ok := err.(*net.DNSError); ok {
+				return nil, nil, err
+			}
+			invalid[name] = err
+			continue
+		}
+		existing[name] = true
+		renderedReceivers = append(renderedReceivers, newAlertmanagerReceiver(name, receiver.Spec))
+	}
+	config["receivers"] = renderedReceivers
+
+	baseRoutes, _ := route["routes"].([]interface{})
+	routes := make([]interface{}, 0, len(baseRoutes))
+	for _, policy := range policies {
+		for _, receiver := range policy.Spec.Receivers {
+			name := receiverName(policy.Namespace, receiver)
+			if !existing[name] {
+				continue
+			}
+			routes = append(routes, newAlertmanagerRoute(name, policy, systemNamespace))
+		}
+	}
+	for _, r := range baseRoutes {
+		if m, ok := r.(map[string]interface{}); ok && isRenderedReceiver(m["receiver"]) {
+			continue
+		}
+		routes = append(routes, r)
+	}
+	if len(routes) > 0 {
+		route["routes"] = routes
+	} else {
+		delete(route, "routes")
+	}
+
+	rendered, err := yaml.Marshal(config)
+	return rendered, invalid, err
+}
+
+// validateReceiver validates the receiver, errors resolving hosts of webhooks are returned as they are and retried
+func validateReceiver(spec alertingv1alpha1.AlertReceiverSpec, smtp bool) error {
+	if spec.Webhook == nil && spec.Email == nil {
+		return fmt.Errorf("at least one of webhook and email is required")
+	}
+
+	if spec.Webhook != nil {
+		if err := validateWebhookURL(spec.Webhook.URL); err != nil {
+			return err
+		}
+	}
+
+	if spec.Email != nil {
+		if !smtp {
+			return fmt.Errorf("email is not available, smtp_smarthost and smtp_from are not configured in Alertmanager")
+		}
+		if len(spec.Email.To) == 0 {
+			return fmt.Errorf("at least one email address is required")
+		}
+	}
+
+	return nil
+}
+
+// validateWebhookURL allows http and https URLs out of the cluster only, so receivers of tenants can't make
+// Alertmanager post to services in the cluster
+func validateWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL %s: %v", raw, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("scheme of webhook URL %s must be http or https", raw)
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "" {
+		return fmt.Errorf("host of webhook URL %s is required", raw)
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		if isInternalIP(ip) {
+			return fmt.Errorf("webhook URL %s is an address in the cluster", raw)
+		}
+		return nil
+	}
+
+	// names without dots and the ones of services are resolved in the cluster
+	if !strings.Contains(host, ".") || host == "localhost" || strings.HasSuffix(host, ".localhost") ||
+		strings.HasSuffix(host, ".svc") || strings.Contains(host, ".svc.") || strings.HasSuffix(host, ".cluster.local") {
+		return fmt.Errorf("webhook URL %s is an address in the cluster", raw)
+	}
+
+	ips, err := lookupIP(host)
+	if err != nil {
+		if dnsErr, ok := err.(*net.DNSError); ok && !dnsErr.IsNotFound {
+			return dnsErr
+		}
+		return fmt.Errorf("host of webhook URL %s is not found", raw)
+	}
+	for _, ip := range ips {
+		if isInternalIP(ip) {
+			return fmt.Errorf("webhook URL %s resolves to %s, an address in the cluster", raw, ip)
+		}
+	}
+
+	return nil
+}
+
+func isInternalIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast()
+}
+
+func newAlertmanagerReceiver(name string, spec alertingv1alpha1.AlertReceiverSpec) map[string]interface{} {
+	receiver := map[string]interface{}{"name": name}
+	if spec.Webhook != nil {
+		receiver["webhook_configs"] = []interface{}{
+			map[string]interface{}{"url": spec.Webhook.URL, "send_resolved": spec.SendResolved},
+		}
+	}
+	if spec.Email != nil {
+		receiver["email_configs"] = []interface{}{
+			map[string]interface{}{"to": strings.Join(spec.Email.To, ", "), "send_resolved": spec.SendResolved},
+		}
+	}
+	return receiver
+}
+
+// newAlertmanagerRoute returns the route of the receiver of the policy, routes continue so all policies matching alerts are notified
+func newAlertmanagerRoute(receiver string, policy alertingv1alpha1.AlertNotificationPolicy, systemNamespace string) map[string]interface{} {
+	match := make(map[string]interface{})
+	for k, v := range policy.Spec.Matchers {
+		match[k] = v
+	}
+	if policy.Namespace != systemNamespace {
+		match[alertingv1alpha1.AlertNamespaceLabel] = policy.Namespace
+	}
+
+	route := map[string]interface{}{
+		"receiver": receiver,
+		"continue": true,
+	}
+	if len(match) > 0 {
+		route["match"] = match
+	}
+	if len(policy.Spec.GroupBy) > 0 {
+		groupBy := make([]interface{}, 0, len(policy.Spec.GroupBy))
+		for _, label := range policy.Spec.GroupBy {
+			groupBy = append(groupBy, label)
+		}
+		route["group_by"] = groupBy
+	}
+	if policy.Spec.GroupWait != nil {
+		route["group_wait"] = promDuration(policy.Spec.GroupWait.Duration)
+	}
+	if policy.Spec.GroupInterval != nil {
+		route["group_interval"] = promDuration(policy.Spec.GroupInterval.Duration)
+	}
+	if policy.Spec.RepeatInterval != nil {
+		route["repeat_interval"] = promDuration(policy.Spec.RepeatInterval.Duration)
+	}
+	return route
+}
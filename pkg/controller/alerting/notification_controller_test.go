@@ -0,0 +1,209 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+
+package alerting
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	alertingv1alpha1 "kubesphere.io/kubesphere/pkg/apis/alerting/v1alpha1"
+)
+
+const baseConfig = `global:
+  resolve_timeout: 5m
+  smtp_from: alertmanager@example.com
+  smtp_smarthost: smtp.example.com:587
+receivers:
+- name: default
+- name: kubesphere/demo/removed
+route:
+  receiver: default
+  routes:
+  - receiver: kubesphere/demo/removed
+    continue: true
+  - receiver: default
+    match:
+      severity: none
+`
+
+const expectedConfig = `global:
+  resolve_timeout: 5m
+  smtp_from: alertmanager@example.com
+  smtp_smarthost: smtp.example.com:587
+receivers:
+- name: default
+- name: kubesphere/demo/webhook
+  webhook_configs:
+  - send_resolved: true
+    url: https://hooks.example.com/alerts
+- email_configs:
+  - send_resolved: false
+    to: ops@example.com, dev@example.com
+  name: kubesphere/kubesphere-monitoring-system/ops
+route:
+  receiver: default
+  routes:
+  - continue: true
+    group_by:
+    - alertname
+    group_wait: 30s
+    match:
+      namespace: demo
+      severity: critical
+    receiver: kubesphere/demo/webhook
+  - continue: true
+    match:
+      workspace: ws
+    receiver: kubesphere/kubesphere-monitoring-system/ops
+    repeat_interval: 14400s
+  - match:
+      severity: none
+    receiver: default
+`
+
+func TestRenderAlertmanagerConfig(t *testing.T) {
+	defer useLookupIP()()
+
+	receivers := []alertingv1alpha1.AlertReceiver{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "kubesphere-monitoring-system", Name: "ops"},
+			Spec: alertingv1alpha1.AlertReceiverSpec{
+				Email: &alertingv1alpha1.EmailReceiver{To: []string{"ops@example.com", "dev@example.com"}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: "webhook"},
+			Spec: alertingv1alpha1.AlertReceiverSpec{
+				Webhook:      &alertingv1alpha1.WebhookReceiver{URL: "https://hooks.example.com/alerts"},
+				SendResolved: true,
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: "email"},
+		},
+	}
+
+	policies := []alertingv1alpha1.AlertNotificationPolicy{
+		{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "kubesphere-monitoring-system", Name: "workspace"},
+			Spec: alertingv1alpha1.AlertNotificationPolicySpec{
+				Matchers:       map[string]string{"workspace": "ws"},
+				Receivers:      []string{"ops", "missing"},
+				RepeatInterval: &metav1.Duration{Duration: 4 * time.Hour},
+			},
+		},
+		{
+			// namespace matchers of tenants are overridden
+			ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: "critical"},
+			Spec: alertingv1alpha1.AlertNotificationPolicySpec{
+				Matchers:  map[string]string{"severity": "critical", "namespace": "kube-system"},
+				Receivers: []string{"webhook", "ops"},
+				GroupBy:   []string{"alertname"},
+				GroupWait: &metav1.Duration{Duration: 30 * time.Second},
+			},
+		},
+	}
+
+	config, invalid, err := renderAlertmanagerConfig([]byte(baseConfig), receivers, policies, "kubesphere-monitoring-system")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(config) != expectedConfig {
+		t.Errorf("expected:\n%s\ngot:\n%s", expectedConfig, config)
+	}
+	if len(invalid) != 1 || invalid["kubesphere/demo/email"] == nil {
+		t.Errorf("expected receiver without webhook and email to be invalid, got %v", invalid)
+	}
+
+	// rendering is idempotent
+	again, _, err := renderAlertmanagerConfig(config, receivers, policies, "kubesphere-monitoring-system")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != expectedConfig {
+		t.Errorf("expected:\n%s\ngot:\n%s", expectedConfig, again)
+	}
+}
+
+func TestRenderAlertmanagerConfigInvalid(t *testing.T) {
+	if _, _, err := renderAlertmanagerConfig([]byte("route: default"), nil, nil, "kubesphere-monitoring-system"); err == nil {
+		t.Errorf("expected error of invalid route")
+	}
+}
+
+func TestValidateReceiver(t *testing.T) {
+	defer useLookupIP()()
+
+	webhook := func(url string) alertingv1alpha1.AlertReceiverSpec {
+		return alertingv1alpha1.AlertReceiverSpec{Webhook: &alertingv1alpha1.WebhookReceiver{URL: url}}
+	}
+	email := alertingv1alpha1.AlertReceiverSpec{Email: &alertingv1alpha1.EmailReceiver{To: []string{"ops@example.com"}}}
+
+	tests := []struct {
+		spec  alertingv1alpha1.AlertReceiverSpec
+		smtp  bool
+		valid bool
+	}{
+		{webhook("https://hooks.example.com/alerts"), false, true},
+		{webhook("http://203.0.113.10:8080/alerts"), false, true},
+		{webhook("ftp://hooks.example.com/alerts"), false, false},
+		{webhook("https:///alerts"), false, false},
+		{webhook("http://hook.demo.svc/alerts"), false, false},
+		{webhook("http://hook.demo.svc.cluster.local:8080/alerts"), false, false},
+		{webhook("http://alertmanager-main:9093/"), false, false},
+		{webhook("http://localhost:8080/"), false, false},
+		{webhook("http://10.96.0.1/"), false, false},
+		{webhook("http://169.254.169.254/latest/meta-data"), false, false},
+		{webhook("http://[::1]/"), false, false},
+		{webhook("https://internal.example.com/alerts"), false, false},
+		{webhook("https://missing.example.com/alerts"), false, false},
+		{email, true, true},
+		{email, false, false},
+		{alertingv1alpha1.AlertReceiverSpec{Email: &alertingv1alpha1.EmailReceiver{}}, true, false},
+		{alertingv1alpha1.AlertReceiverSpec{}, true, false},
+	}
+
+	for i, test := range tests {
+		err := validateReceiver(test.spec, test.smtp)
+		if test.valid && err != nil {
+			t.Errorf("case %d: unexpected error %v", i, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+}
+
+func useLookupIP() func() {
+	origin := lookupIP
+	lookupIP = func(host string) ([]net.IP, error) {
+		switch host {
+		case "hooks.example.com":
+			return []net.IP{net.ParseIP("203.0.113.10")}, nil
+		case "internal.example.com":
+			return []net.IP{net.ParseIP("203.0.113.10"), net.ParseIP("10.0.0.8")}, nil
+		default:
+			return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+		}
+	}
+	return func() { lookupIP = origin }
+}
@@ -0,0 +1,345 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package events
+
+import (
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	appsv1informers "k8s.io/client-go/informers/apps/v1"
+	batchv1informers "k8s.io/client-go/informers/batch/v1"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	appsv1listers "k8s.io/client-go/listers/apps/v1"
+	batchv1listers "k8s.io/client-go/listers/batch/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"kubesphere.io/kubesphere/pkg/constants"
+	esclient "kubesphere.io/kubesphere/pkg/simple/client/elasticsearch"
+	fb "kubesphere.io/kubesphere/pkg/simple/client/fluentbit"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+const (
+	// maxRetries is the number of times an event will be retried before it is dropped out of the queue,
+	// events expire in kube-apiserver after --event-ttl, 1 hour by default
+	maxRetries = 15
+	// events are written to the log backend in batches
+	maxBatchSize = 500
+)
+
+var log = logf.Log.WithName("events-exporter")
+
+// EventsExporter persists kubernetes events to the log backend, enriched with workspace and workload
+// of the involved object, so events are kept after they expire in kube-apiserver
+type EventsExporter struct {
+	client clientset.Interface
+
+	eventLister      corev1listers.EventLister
+	namespaceLister  corev1listers.NamespaceLister
+	podLister        corev1listers.PodLister
+	replicaSetLister appsv1listers.ReplicaSetLister
+	jobLister        batchv1listers.JobLister
+	cacheSynced      []cache.InformerSynced
+
+	queue workqueue.RateLimitingInterface
+
+	flushInterval time.Duration
+	// configs of the log backend are read from outputs of fluent bit at the interval
+	configsInterval time.Duration
+	configsSynced   time.Time
+
+	// index writes events to the log backend
+	index func(events []esclient.Event) error
+}
+
+func NewEventsExporter(eventInformer corev1informers.EventInformer,
+	namespaceInformer corev1informers.NamespaceInformer,
+	podInformer corev1informers.PodInformer,
+	replicaSetInformer appsv1informers.ReplicaSetInformer,
+	jobInformer batchv1informers.JobInformer,
+	client clientset.Interface) *EventsExporter {
+
+	v := &EventsExporter{
+		client:          client,
+		queue:           workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "events"),
+		flushInterval:   5 * time.Second,
+		configsInterval: time.Minute,
+		index:           esclient.IndexEvents,
+	}
+
+	v.eventLister = eventInformer.Lister()
+	v.namespaceLister = namespaceInformer.Lister()
+	v.podLister = podInformer.Lister()
+	v.replicaSetLister = replicaSetInformer.Lister()
+	v.jobLister = jobInformer.Lister()
+	v.cacheSynced = []cache.InformerSynced{
+		eventInformer.Informer().HasSynced,
+		namespaceInformer.Informer().HasSynced,
+		podInformer.Informer().HasSynced,
+		replicaSetInformer.Informer().HasSynced,
+		jobInformer.Informer().HasSynced,
+	}
+
+	eventInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: v.enqueueEvent,
+		UpdateFunc: func(old, cur interface{}) {
+			// skip periodic resyncs, events of the same uid are overwritten anyway
+			if old.(*v1.Event).ResourceVersion != cur.(*v1.Event).ResourceVersion {
+				v.enqueueEvent(cur)
+			}
+		},
+	})
+
+	return v
+}
+
+func (v *EventsExporter) Start(stopCh <-chan struct{}) error {
+	return v.Run(stopCh)
+}
+
+func (v *EventsExporter) Run(stopCh <-chan struct{}) error {
+	defer utilruntime.HandleCrash()
+	defer v.queue.ShutDown()
+
+	log.Info("starting events exporter")
+	defer log.Info("shutting down events exporter")
+
+	if !cache.WaitForCacheSync(stopCh, v.cacheSynced...) {
+		return fmt.Errorf("failed to wait for caches to sync")
+	}
+
+	go wait.Until(v.flush, v.flushInterval, stopCh)
+
+	<-stopCh
+	return nil
+}
+
+func (v *EventsExporter) enqueueEvent(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("couldn't get key for object %+v: %v", obj, err))
+		return
+	}
+	v.queue.Add(key)
+}
+
+// flush writes events in the queue to the log backend in batches
+func (v *EventsExporter) flush() {
+	for v.queue.Len() > 0 {
+		keys := make([]interface{}, 0, maxBatchSize)
+		events := make([]esclient.Event, 0, maxBatchSize)
+
+		for v.queue.Len() > 0 && len(keys) < maxBatchSize {
+			key, quit := v.queue.Get()
+			if quit {
+				return
+			}
+			keys = append(keys, key)
+
+			event, err := v.getEvent(key.(string))
+			if err != nil {
+				log.Error(err, "get event failed", "key", key)
+				continue
+			}
+			if event != nil {
+				events = append(events, v.newEvent(event))
+			}
+		}
+
+		v.syncBackendConfigs()
+
+		err := v.index(events)
+		if err != nil {
+			log.Error(err, "export events failed", "count", len(events))
+		}
+
+		for _, key := range keys {
+			v.handleErr(err, key)
+			v.queue.Done(key)
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (v *EventsExporter) getEvent(key string) (*v1.Event, error) {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	event, err := v.eventLister.Events(namespace).Get(name)
+	if errors.IsNotFound(err) {
+		// expired before it's exported
+		return nil, nil
+	}
+
+	return event, err
+}
+
+func (v *EventsExporter) handleErr(err error, key interface{}) {
+	if err == nil {
+		v.queue.Forget(key)
+		return
+	}
+
+	if v.queue.NumRequeues(key) < maxRetries {
+		v.queue.AddRateLimited(key)
+		return
+	}
+
+	log.V(4).Info("Dropping event out of the queue", "key", key, "error", err)
+	v.queue.Forget(key)
+}
+
+// syncBackendConfigs points the log backend to the elasticsearch output of fluent bit,
+// which is where ks-apiserver queries logs and events from
+func (v *EventsExporter) syncBackendConfigs() {
+	if v.client == nil || time.Since(v.configsSynced) < v.configsInterval {
+		return
+	}
+
+	configs, err := fb.ReadESConfigs(v.client)
+	if err != nil {
+		log.Error(err, "read fluent bit outputs failed")
+		return
+	}
+
+	if configs != nil {
+		configs.WriteESConfigs()
+	}
+
+	v.configsSynced = time.Now()
+}
+
+func (v *EventsExporter) newEvent(event *v1.Event) esclient.Event {
+	lastTime := event.LastTimestamp.Time
+	if lastTime.IsZero() {
+		// events of events.k8s.io/v1beta1 are only set with event time
+		lastTime = event.EventTime.Time
+	}
+	if lastTime.IsZero() {
+		lastTime = event.CreationTimestamp.Time
+	}
+
+	firstTime := event.FirstTimestamp.Time
+	if firstTime.IsZero() {
+		firstTime = lastTime
+	}
+
+	count := event.Count
+	if event.Series != nil {
+		count = event.Series.Count
+	}
+	if count == 0 {
+		count = 1
+	}
+
+	source := event.Source.Component
+	if source == "" {
+		source = event.ReportingController
+	}
+
+	host := event.Source.Host
+	if host == "" {
+		host = event.ReportingInstance
+	}
+
+	object := event.InvolvedObject
+
+	record := esclient.Event{
+		UID:       string(event.UID),
+		Time:      lastTime.UTC().Format(time.RFC3339),
+		FirstTime: firstTime.UTC().Format(time.RFC3339),
+		Count:     count,
+		Type:      event.Type,
+		Reason:    event.Reason,
+		Message:   event.Message,
+		Source:    source,
+		Host:      host,
+		Namespace: object.Namespace,
+		InvolvedObject: esclient.InvolvedObject{
+			Kind:      object.Kind,
+			Name:      object.Name,
+			UID:       string(object.UID),
+			FieldPath: object.FieldPath,
+		},
+	}
+
+	if object.Namespace != "" {
+		if namespace, err := v.namespaceLister.Get(object.Namespace); err == nil {
+			record.Workspace = namespace.Labels[constants.WorkspaceLabelKey]
+		}
+		record.WorkloadKind, record.Workload = v.workloadOf(object.Namespace, object.Kind, object.Name)
+	}
+
+	return record
+}
+
+// workloadOf returns the workload the object belongs to, or the object itself if it's a workload
+func (v *EventsExporter) workloadOf(namespace, kind, name string) (string, string) {
+	switch kind {
+	case "Deployment", "StatefulSet", "DaemonSet", "CronJob":
+		return kind, name
+	case "ReplicaSet":
+		replicaSet, err := v.replicaSetLister.ReplicaSets(namespace).Get(name)
+		if err != nil {
+			return kind, name
+		}
+		return ownerOf(&replicaSet.ObjectMeta, kind)
+	case "Job":
+		job, err := v.jobLister.Jobs(namespace).Get(name)
+		if err != nil {
+			return kind, name
+		}
+		return ownerOf(&job.ObjectMeta, kind)
+	case "Pod":
+		pod, err := v.podLister.Pods(namespace).Get(name)
+		if err != nil {
+			return "", ""
+		}
+		owner := metav1.GetControllerOf(pod)
+		if owner == nil {
+			return "", ""
+		}
+		switch owner.Kind {
+		case "ReplicaSet", "Job":
+			return v.workloadOf(namespace, owner.Kind, owner.Name)
+		}
+		return owner.Kind, owner.Name
+	}
+
+	return "", ""
+}
+
+// ownerOf returns the controller of replicasets and jobs, or the object itself if it's not controlled
+func ownerOf(object *metav1.ObjectMeta, kind string) (string, string) {
+	if owner := metav1.GetControllerOf(object); owner != nil {
+		return owner.Kind, owner.Name
+	}
+	return kind, object.Name
+}
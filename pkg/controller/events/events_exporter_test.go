@@ -0,0 +1,195 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package events
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"kubesphere.io/kubesphere/pkg/constants"
+	esclient "kubesphere.io/kubesphere/pkg/simple/client/elasticsearch"
+)
+
+func controlledBy(kind, name string) []metav1.OwnerReference {
+	controller := true
+	return []metav1.OwnerReference{{Kind: kind, Name: name, Controller: &controller}}
+}
+
+func newExporter(objects ...runtime.Object) *EventsExporter {
+	client := fake.NewSimpleClientset(objects...)
+	informerFactory := informers.NewSharedInformerFactory(client, 0)
+
+	exporter := NewEventsExporter(informerFactory.Core().V1().Events(),
+		informerFactory.Core().V1().Namespaces(),
+		informerFactory.Core().V1().Pods(),
+		informerFactory.Apps().V1().ReplicaSets(),
+		informerFactory.Batch().V1().Jobs(),
+		nil)
+
+	for _, object := range objects {
+		var indexer interface{ Add(interface{}) error }
+		switch object.(type) {
+		case *v1.Event:
+			indexer = informerFactory.Core().V1().Events().Informer().GetIndexer()
+		case *v1.Namespace:
+			indexer = informerFactory.Core().V1().Namespaces().Informer().GetIndexer()
+		case *v1.Pod:
+			indexer = informerFactory.Core().V1().Pods().Informer().GetIndexer()
+		case *appsv1.ReplicaSet:
+			indexer = informerFactory.Apps().V1().ReplicaSets().Informer().GetIndexer()
+		case *batchv1.Job:
+			indexer = informerFactory.Batch().V1().Jobs().Informer().GetIndexer()
+		}
+		indexer.Add(object)
+	}
+
+	return exporter
+}
+
+func TestWorkloadOf(t *testing.T) {
+	exporter := newExporter(
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Namespace: "billing", Name: "invoice-5c9d7b8f6", OwnerReferences: controlledBy("Deployment", "invoice")}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Namespace: "billing", Name: "report-1571299200", OwnerReferences: controlledBy("CronJob", "report")}},
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "billing", Name: "invoice-5c9d7b8f6-x2x7k", OwnerReferences: controlledBy("ReplicaSet", "invoice-5c9d7b8f6")}},
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "billing", Name: "report-1571299200-m4k2p", OwnerReferences: controlledBy("Job", "report-1571299200")}},
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "billing", Name: "ledger-0", OwnerReferences: controlledBy("StatefulSet", "ledger")}},
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "billing", Name: "debug"}},
+	)
+
+	tests := []struct {
+		kind, name             string
+		workloadKind, workload string
+	}{
+		{"Pod", "invoice-5c9d7b8f6-x2x7k", "Deployment", "invoice"},
+		{"Pod", "report-1571299200-m4k2p", "CronJob", "report"},
+		{"Pod", "ledger-0", "StatefulSet", "ledger"},
+		{"Pod", "debug", "", ""},
+		{"Pod", "deleted", "", ""},
+		{"ReplicaSet", "invoice-5c9d7b8f6", "Deployment", "invoice"},
+		{"Job", "report-1571299200", "CronJob", "report"},
+		{"Job", "migrate", "Job", "migrate"},
+		{"Deployment", "invoice", "Deployment", "invoice"},
+		{"Service", "invoice", "", ""},
+	}
+
+	for _, test := range tests {
+		kind, name := exporter.workloadOf("billing", test.kind, test.name)
+		if kind != test.workloadKind || name != test.workload {
+			t.Errorf("%s %s: expected workload %s %s, got %s %s", test.kind, test.name, test.workloadKind, test.workload, kind, name)
+		}
+	}
+}
+
+func TestFlush(t *testing.T) {
+	firstTime := metav1.NewTime(time.Date(2019, 10, 17, 8, 2, 11, 0, time.UTC))
+	lastTime := metav1.NewTime(time.Date(2019, 10, 17, 8, 12, 40, 0, time.UTC))
+
+	event := &v1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: "billing", Name: "invoice-5c9d7b8f6-x2x7k.15cdb4d2c1b3e1a0", UID: "9f1e6b3c"},
+		InvolvedObject: v1.ObjectReference{Kind: "Pod", Namespace: "billing", Name: "invoice-5c9d7b8f6-x2x7k", UID: "1c0f5a2e", FieldPath: "spec.containers{invoice}"},
+		Reason:         "BackOff",
+		Message:        "Back-off restarting failed container",
+		Source:         v1.EventSource{Component: "kubelet", Host: "node1"},
+		FirstTimestamp: firstTime,
+		LastTimestamp:  lastTime,
+		Count:          12,
+		Type:           v1.EventTypeWarning,
+	}
+
+	nodeEvent := &v1.Event{
+		ObjectMeta:          metav1.ObjectMeta{Namespace: "default", Name: "node1.15cdb4d2c1b3e1a1", UID: "3b7d0c9e"},
+		InvolvedObject:      v1.ObjectReference{Kind: "Node", Name: "node1", UID: "node1"},
+		Reason:              "NodeNotReady",
+		EventTime:           metav1.NewMicroTime(lastTime.Time),
+		ReportingController: "node-controller",
+		Type:                v1.EventTypeNormal,
+	}
+
+	exporter := newExporter(
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "billing", Labels: map[string]string{constants.WorkspaceLabelKey: "finance"}}},
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Namespace: "billing", Name: "invoice-5c9d7b8f6", OwnerReferences: controlledBy("Deployment", "invoice")}},
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "billing", Name: "invoice-5c9d7b8f6-x2x7k", OwnerReferences: controlledBy("ReplicaSet", "invoice-5c9d7b8f6")}},
+		event,
+		nodeEvent,
+	)
+
+	var exported []esclient.Event
+	exporter.index = func(events []esclient.Event) error {
+		exported = append(exported, events...)
+		return nil
+	}
+
+	exporter.enqueueEvent(event)
+	exporter.enqueueEvent(nodeEvent)
+	// updates before the flush are exported once
+	exporter.enqueueEvent(event)
+	// expired before the flush
+	exporter.enqueueEvent(&v1.Event{ObjectMeta: metav1.ObjectMeta{Namespace: "billing", Name: "expired"}})
+
+	exporter.flush()
+
+	expected := []esclient.Event{
+		{
+			UID:          "9f1e6b3c",
+			Time:         "2019-10-17T08:12:40Z",
+			FirstTime:    "2019-10-17T08:02:11Z",
+			Count:        12,
+			Type:         "Warning",
+			Reason:       "BackOff",
+			Message:      "Back-off restarting failed container",
+			Source:       "kubelet",
+			Host:         "node1",
+			Workspace:    "finance",
+			Namespace:    "billing",
+			WorkloadKind: "Deployment",
+			Workload:     "invoice",
+			InvolvedObject: esclient.InvolvedObject{
+				Kind:      "Pod",
+				Name:      "invoice-5c9d7b8f6-x2x7k",
+				UID:       "1c0f5a2e",
+				FieldPath: "spec.containers{invoice}",
+			},
+		},
+		{
+			UID:            "3b7d0c9e",
+			Time:           "2019-10-17T08:12:40Z",
+			FirstTime:      "2019-10-17T08:12:40Z",
+			Count:          1,
+			Type:           "Normal",
+			Reason:         "NodeNotReady",
+			Source:         "node-controller",
+			InvolvedObject: esclient.InvolvedObject{Kind: "Node", Name: "node1", UID: "node1"},
+		},
+	}
+
+	if !reflect.DeepEqual(exported, expected) {
+		t.Errorf("expected events %+v, got %+v", expected, exported)
+	}
+
+	if exporter.queue.Len() != 0 {
+		t.Errorf("expected queue drained, %d left", exporter.queue.Len())
+	}
+}
@@ -0,0 +1,230 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+
+package logalertrule
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	loggingv1alpha1 "kubesphere.io/kubesphere/pkg/apis/logging/v1alpha1"
+	"kubesphere.io/kubesphere/pkg/simple/client/kubesphere"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const defaultInterval = time.Minute
+
+var log = logf.Log.WithName("logalertrule-controller")
+
+// Add creates a new LogAlertRule Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	return add(mgr, newReconciler(mgr))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+	return &ReconcileLogAlertRule{Client: mgr.GetClient(), scheme: mgr.GetScheme(),
+		recorder: mgr.GetRecorder("logalertrule-controller"), ksclient: kubesphere.Client(), notifier: newNotifier()}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r reconcile.Reconciler) error {
+	// Create a new controller
+	c, err := controller.New("logalertrule-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to LogAlertRule
+	err = c.Watch(&source.Kind{Type: &loggingv1alpha1.LogAlertRule{}}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+var _ reconcile.Reconciler = &ReconcileLogAlertRule{}
+
+// ReconcileLogAlertRule evaluates LogAlertRules periodically
+type ReconcileLogAlertRule struct {
+	client.Client
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
+	ksclient kubesphere.Interface
+	notifier notifier
+}
+
+// Reconcile evaluates the rule if the interval has passed since the last evaluation or the spec has changed,
+// and requeues the rule for the next evaluation
+// +kubebuilder:rbac:groups=logging.kubesphere.io,resources=logalertrules,verbs=get;list;watch
+// +kubebuilder:rbac:groups=logging.kubesphere.io,resources=logalertrules/status,verbs=get;update;patch
+func (r *ReconcileLogAlertRule) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	instance := &loggingv1alpha1.LogAlertRule{}
+	err := r.Get(context.TODO(), request.NamespacedName, instance)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	now := time.Now()
+	interval := evaluationInterval(instance)
+
+	// status updates trigger reconciliation as well
+	if instance.Status.ObservedGeneration == instance.Generation && instance.Status.LastEvaluationTime != nil {
+		if next := instance.Status.LastEvaluationTime.Add(interval); next.After(now) {
+			return reconcile.Result{RequeueAfter: next.Sub(now)}, nil
+		}
+	}
+
+	instance.Status = r.evaluate(instance, now)
+
+	if err := r.Status().Update(context.TODO(), instance); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	return reconcile.Result{RequeueAfter: interval}, nil
+}
+
+func evaluationInterval(rule *loggingv1alpha1.LogAlertRule) time.Duration {
+	if rule.Spec.Interval != nil && rule.Spec.Interval.Duration > 0 {
+		return rule.Spec.Interval.Duration
+	}
+	return defaultInterval
+}
+
+// queryPath returns the path of logging api of the narrowest scope
+func queryPath(scope loggingv1alpha1.LogAlertRuleScope) (string, error) {
+	switch {
+	case scope.Workload != "":
+		if scope.Namespace == "" {
+			return "", fmt.Errorf("namespace of workload %s is required", scope.Workload)
+		}
+		return fmt.Sprintf("/namespaces/%s/workloads/%s", url.PathEscape(scope.Namespace), url.PathEscape(scope.Workload)), nil
+	case scope.Namespace != "":
+		return fmt.Sprintf("/namespaces/%s", url.PathEscape(scope.Namespace)), nil
+	case scope.Workspace != "":
+		return fmt.Sprintf("/workspaces/%s", url.PathEscape(scope.Workspace)), nil
+	default:
+		return "", fmt.Errorf("one of workspace, namespace and workload is required in scope")
+	}
+}
+
+// count returns the number of logs matched in the window before now
+func (r *ReconcileLogAlertRule) count(rule *loggingv1alpha1.LogAlertRule, now time.Time) (int64, error) {
+	path, err := queryPath(rule.Spec.Scope)
+	if err != nil {
+		return 0, err
+	}
+
+	if rule.Spec.Window.Duration <= 0 {
+		return 0, fmt.Errorf("window must be positive")
+	}
+
+	params := url.Values{}
+	params.Set("operation", "statistics")
+	params.Set("log_query", rule.Spec.Query)
+	params.Set("start_time", strconv.FormatInt(now.Add(-rule.Spec.Window.Duration).UnixNano()/int64(time.Millisecond), 10))
+	params.Set("end_time", strconv.FormatInt(now.UnixNano()/int64(time.Millisecond), 10))
+
+	result, err := r.ksclient.QueryLogs(path, params)
+	if err != nil {
+		return 0, err
+	}
+
+	if result.Status != http.StatusOK {
+		return 0, fmt.Errorf("failed to query logs: %s", result.Error)
+	}
+
+	if result.Statistics == nil {
+		return 0, nil
+	}
+
+	return result.Statistics.Logs, nil
+}
+
+// evaluate returns the status of the rule after evaluation at now, notifications are sent when the rule starts
+// or stops firing, and repeated every repeatInterval while firing
+func (r *ReconcileLogAlertRule) evaluate(rule *loggingv1alpha1.LogAlertRule, now time.Time) loggingv1alpha1.LogAlertRuleStatus {
+	status := *rule.Status.DeepCopy()
+	status.ObservedGeneration = rule.Generation
+	status.LastEvaluationTime = &metav1.Time{Time: now}
+	status.Message = ""
+
+	value, err := r.count(rule, now)
+	if err != nil {
+		// firingSince is kept, so notifications are not sent again when the rule recovers from errors
+		status.State = loggingv1alpha1.LogAlertStateError
+		status.Message = err.Error()
+		r.recorder.Event(rule, corev1.EventTypeWarning, "EvaluationFailed", err.Error())
+		return status
+	}
+
+	status.Value = value
+	firing := value > rule.Spec.Threshold
+	wasFiring := status.FiringSince != nil
+
+	var notification *Notification
+
+	switch {
+	case firing && !wasFiring:
+		status.FiringSince = &metav1.Time{Time: now}
+		notification = newNotification(rule, StatusFiring, status.FiringSince.Time, value, now)
+	case firing && rule.Spec.RepeatInterval != nil && (status.LastNotificationTime == nil || !now.Before(status.LastNotificationTime.Add(rule.Spec.RepeatInterval.Duration))):
+		notification = newNotification(rule, StatusFiring, status.FiringSince.Time, value, now)
+	case !firing && wasFiring:
+		notification = newNotification(rule, StatusResolved, status.FiringSince.Time, value, now)
+		status.FiringSince = nil
+	}
+
+	if firing {
+		status.State = loggingv1alpha1.LogAlertStateFiring
+	} else {
+		status.State = loggingv1alpha1.LogAlertStateInactive
+	}
+
+	if notification != nil {
+		status.LastNotificationTime = &metav1.Time{Time: now}
+		if err := r.notifier.Notify(rule.Spec.Receivers, notification); err != nil {
+			status.Message = fmt.Sprintf("failed to notify: %s", err)
+			r.recorder.Event(rule, corev1.EventTypeWarning, "NotificationFailed", err.Error())
+		} else {
+			r.recorder.Eventf(rule, corev1.EventTypeNormal, "Notified", "%s notification sent", notification.Status)
+		}
+	}
+
+	return status
+}
@@ -0,0 +1,223 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+
+package logalertrule
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	loggingv1alpha1 "kubesphere.io/kubesphere/pkg/apis/logging/v1alpha1"
+	esclient "kubesphere.io/kubesphere/pkg/simple/client/elasticsearch"
+	"kubesphere.io/kubesphere/pkg/simple/client/kubesphere"
+)
+
+type fakeKsClient struct {
+	kubesphere.Interface
+	logs  int64
+	err   error
+	paths []string
+	query url.Values
+}
+
+func (c *fakeKsClient) QueryLogs(path string, params url.Values) (*esclient.QueryResult, error) {
+	c.paths = append(c.paths, path)
+	c.query = params
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &esclient.QueryResult{Status: http.StatusOK, Statistics: &esclient.StatisticsResult{Logs: c.logs}}, nil
+}
+
+type fakeNotifier struct {
+	notifications []*Notification
+	err           error
+}
+
+func (n *fakeNotifier) Notify(receivers []loggingv1alpha1.LogAlertReceiver, notification *Notification) error {
+	n.notifications = append(n.notifications, notification)
+	return n.err
+}
+
+func newRule() *loggingv1alpha1.LogAlertRule {
+	return &loggingv1alpha1.LogAlertRule{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-errors", Generation: 1},
+		Spec: loggingv1alpha1.LogAlertRuleSpec{
+			Scope:     loggingv1alpha1.LogAlertRuleScope{Namespace: "demo", Workload: "web"},
+			Query:     "ERROR",
+			Window:    metav1.Duration{Duration: 5 * time.Minute},
+			Threshold: 50,
+		},
+	}
+}
+
+func TestQueryPath(t *testing.T) {
+	tests := []struct {
+		scope    loggingv1alpha1.LogAlertRuleScope
+		expected string
+	}{
+		{loggingv1alpha1.LogAlertRuleScope{Workspace: "ws", Namespace: "demo", Workload: "web"}, "/namespaces/demo/workloads/web"},
+		{loggingv1alpha1.LogAlertRuleScope{Workspace: "ws", Namespace: "demo"}, "/namespaces/demo"},
+		{loggingv1alpha1.LogAlertRuleScope{Workspace: "ws"}, "/workspaces/ws"},
+		{loggingv1alpha1.LogAlertRuleScope{Workload: "web"}, ""},
+		{loggingv1alpha1.LogAlertRuleScope{}, ""},
+	}
+
+	for _, test := range tests {
+		path, err := queryPath(test.scope)
+		if test.expected == "" {
+			if err == nil {
+				t.Errorf("%+v: expected error, got %s", test.scope, path)
+			}
+			continue
+		}
+		if err != nil || path != test.expected {
+			t.Errorf("%+v: expected %s, got %s %v", test.scope, test.expected, path, err)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	ksclient := &fakeKsClient{}
+	notifier := &fakeNotifier{}
+	r := &ReconcileLogAlertRule{ksclient: ksclient, notifier: notifier, recorder: record.NewFakeRecorder(10)}
+
+	rule := newRule()
+	rule.Spec.RepeatInterval = &metav1.Duration{Duration: 10 * time.Minute}
+	start := time.Date(2019, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		logs     int64
+		err      error
+		state    loggingv1alpha1.LogAlertState
+		notified string
+	}{
+		{logs: 10, state: loggingv1alpha1.LogAlertStateInactive},
+		{logs: 51, state: loggingv1alpha1.LogAlertStateFiring, notified: StatusFiring},
+		{logs: 80, state: loggingv1alpha1.LogAlertStateFiring},
+		{err: fmt.Errorf("connection refused"), state: loggingv1alpha1.LogAlertStateError},
+		{logs: 60, state: loggingv1alpha1.LogAlertStateFiring},
+		// repeat interval passed since the firing notification
+		{logs: 60, state: loggingv1alpha1.LogAlertStateFiring, notified: StatusFiring},
+		{logs: 50, state: loggingv1alpha1.LogAlertStateInactive, notified: StatusResolved},
+		{logs: 0, state: loggingv1alpha1.LogAlertStateInactive},
+	}
+
+	for i, step := range steps {
+		ksclient.logs, ksclient.err = step.logs, step.err
+		notifier.notifications = nil
+		now := start.Add(time.Duration(i) * 3 * time.Minute)
+
+		rule.Status = r.evaluate(rule, now)
+
+		if rule.Status.State != step.state {
+			t.Errorf("step %d: expected state %s, got %s", i, step.state, rule.Status.State)
+		}
+		if !rule.Status.LastEvaluationTime.Time.Equal(now) || rule.Status.ObservedGeneration != 1 {
+			t.Errorf("step %d: unexpected status %+v", i, rule.Status)
+		}
+
+		switch {
+		case step.notified == "" && len(notifier.notifications) > 0:
+			t.Errorf("step %d: unexpected notification %+v", i, notifier.notifications[0])
+		case step.notified != "" && (len(notifier.notifications) != 1 || notifier.notifications[0].Status != step.notified):
+			t.Errorf("step %d: expected %s notification, got %v", i, step.notified, notifier.notifications)
+		}
+	}
+
+	if ksclient.paths[0] != "/namespaces/demo/workloads/web" {
+		t.Errorf("unexpected path %s", ksclient.paths[0])
+	}
+
+	end := start.Add(21 * time.Minute)
+	expected := url.Values{
+		"operation":  {"statistics"},
+		"log_query":  {"ERROR"},
+		"start_time": {fmt.Sprint(end.Add(-5*time.Minute).UnixNano() / int64(time.Millisecond))},
+		"end_time":   {fmt.Sprint(end.UnixNano() / int64(time.Millisecond))},
+	}
+	if ksclient.query.Encode() != expected.Encode() {
+		t.Errorf("expected query %s, got %s", expected.Encode(), ksclient.query.Encode())
+	}
+}
+
+func TestEvaluateNotificationFailure(t *testing.T) {
+	r := &ReconcileLogAlertRule{ksclient: &fakeKsClient{logs: 100}, notifier: &fakeNotifier{err: fmt.Errorf("webhook down")}, recorder: record.NewFakeRecorder(10)}
+
+	rule := newRule()
+	rule.Status = r.evaluate(rule, time.Now())
+
+	if rule.Status.State != loggingv1alpha1.LogAlertStateFiring || !strings.Contains(rule.Status.Message, "webhook down") || rule.Status.LastNotificationTime == nil {
+		t.Errorf("unexpected status %+v", rule.Status)
+	}
+}
+
+func TestNotify(t *testing.T) {
+	var received Notification
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+
+	oldServer, oldFrom := smtpServer, smtpFrom
+	smtpServer, smtpFrom = "smtp.example.com:25", "alerts@example.com"
+	defer func() { smtpServer, smtpFrom = oldServer, oldFrom }()
+
+	var mail string
+	n := &defaultNotifier{client: http.DefaultClient, sendMail: func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		mail = fmt.Sprintf("%s %s %v\n%s", addr, from, to, msg)
+		return nil
+	}}
+
+	now := time.Date(2019, 10, 18, 12, 0, 0, 0, time.UTC)
+	notification := newNotification(newRule(), StatusFiring, now, 51, now)
+
+	err := n.Notify([]loggingv1alpha1.LogAlertReceiver{
+		{Webhook: &loggingv1alpha1.WebhookReceiver{URL: server.URL}},
+		{Email: &loggingv1alpha1.EmailReceiver{To: []string{"ops@example.com"}}},
+		{Webhook: &loggingv1alpha1.WebhookReceiver{URL: failing.URL}},
+	}, notification)
+
+	if err == nil || !strings.Contains(err.Error(), "status 503") {
+		t.Errorf("expected error of the failing webhook, got %v", err)
+	}
+
+	if received.Rule != "web-errors" || received.Status != StatusFiring || received.Value != 51 || received.Window != "5m0s" {
+		t.Errorf("unexpected notification %+v", received)
+	}
+
+	if !strings.HasPrefix(mail, "smtp.example.com:25 alerts@example.com [ops@example.com]") || !strings.Contains(mail, "Subject: [FIRING] log alert rule web-errors") {
+		t.Errorf("unexpected mail %s", mail)
+	}
+}
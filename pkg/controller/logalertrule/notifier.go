@@ -0,0 +1,186 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+
+package logalertrule
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+
+	loggingv1alpha1 "kubesphere.io/kubesphere/pkg/apis/logging/v1alpha1"
+)
+
+const (
+	StatusFiring   = "firing"
+	StatusResolved = "resolved"
+)
+
+var (
+	smtpServer   string
+	smtpFrom     string
+	smtpUsername string
+	smtpPassword string
+)
+
+func init() {
+	flag.StringVar(&smtpServer, "smtp-server", "", "SMTP server of email receivers of log alert rules, host:port")
+	flag.StringVar(&smtpFrom, "smtp-from", "", "sender address of log alert emails")
+	flag.StringVar(&smtpUsername, "smtp-username", "", "username of the SMTP server, authentication is skipped if it's empty")
+	flag.StringVar(&smtpPassword, "smtp-password", "", "password of the SMTP server")
+}
+
+// Notification is posted to webhook receivers in JSON
+type Notification struct {
+	Rule      string                            `json:"rule"`
+	Status    string                            `json:"status"`
+	Scope     loggingv1alpha1.LogAlertRuleScope `json:"scope"`
+	Query     string                            `json:"query"`
+	Window    string                            `json:"window"`
+	Threshold int64                             `json:"threshold"`
+	// Value is the number of logs matched in the window at the evaluation
+	Value       int64      `json:"value"`
+	StartsAt    time.Time  `json:"startsAt"`
+	EndsAt      *time.Time `json:"endsAt,omitempty"`
+	EvaluatedAt time.Time  `json:"evaluatedAt"`
+}
+
+func newNotification(rule *loggingv1alpha1.LogAlertRule, status string, startsAt time.Time, value int64, now time.Time) *Notification {
+	notification := &Notification{
+		Rule:        rule.Name,
+		Status:      status,
+		Scope:       rule.Spec.Scope,
+		Query:       rule.Spec.Query,
+		Window:      rule.Spec.Window.Duration.String(),
+		Threshold:   rule.Spec.Threshold,
+		Value:       value,
+		StartsAt:    startsAt,
+		EvaluatedAt: now,
+	}
+	if status == StatusResolved {
+		notification.EndsAt = &now
+	}
+	return notification
+}
+
+func (n *Notification) subject() string {
+	return fmt.Sprintf("[%s] log alert rule %s", strings.ToUpper(n.Status), n.Rule)
+}
+
+func (n *Notification) text() string {
+	var scope []string
+	for _, item := range [][2]string{{"workspace", n.Scope.Workspace}, {"namespace", n.Scope.Namespace}, {"workload", n.Scope.Workload}} {
+		if item[1] != "" {
+			scope = append(scope, fmt.Sprintf("%s=%s", item[0], item[1]))
+		}
+	}
+
+	text := fmt.Sprintf("Rule: %s\nStatus: %s\nScope: %s\nQuery: %s\nLogs in the last %s: %d (threshold %d)\nStarts at: %s\n",
+		n.Rule, n.Status, strings.Join(scope, ", "), n.Query, n.Window, n.Value, n.Threshold, n.StartsAt.Format(time.RFC3339))
+	if n.EndsAt != nil {
+		text += fmt.Sprintf("Ends at: %s\n", n.EndsAt.Format(time.RFC3339))
+	}
+	return text
+}
+
+type notifier interface {
+	// Notify sends the notification to all receivers, errors of receivers are joined
+	Notify(receivers []loggingv1alpha1.LogAlertReceiver, notification *Notification) error
+}
+
+type defaultNotifier struct {
+	client   *http.Client
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+func newNotifier() notifier {
+	return &defaultNotifier{client: &http.Client{Timeout: 10 * time.Second}, sendMail: smtp.SendMail}
+}
+
+func (n *defaultNotifier) Notify(receivers []loggingv1alpha1.LogAlertReceiver, notification *Notification) error {
+	var errs []string
+
+	for _, receiver := range receivers {
+		if receiver.Webhook != nil {
+			if err := n.webhook(receiver.Webhook, notification); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+		if receiver.Email != nil {
+			if err := n.email(receiver.Email, notification); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+func (n *defaultNotifier) webhook(receiver *loggingv1alpha1.WebhookReceiver, notification *Notification) error {
+	data, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	resp, err := n.client.Post(receiver.URL, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("webhook %s: %s", receiver.URL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("webhook %s: status %d: %s", receiver.URL, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return nil
+}
+
+func (n *defaultNotifier) email(receiver *loggingv1alpha1.EmailReceiver, notification *Notification) error {
+	if smtpServer == "" || smtpFrom == "" {
+		return fmt.Errorf("email %s: smtp server is not configured", strings.Join(receiver.To, ","))
+	}
+
+	var auth smtp.Auth
+	if smtpUsername != "" {
+		host := smtpServer
+		if i := strings.LastIndex(host, ":"); i >= 0 {
+			host = host[:i]
+		}
+		auth = smtp.PlainAuth("", smtpUsername, smtpPassword, host)
+	}
+
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s",
+		smtpFrom, strings.Join(receiver.To, ", "), notification.subject(), strings.Replace(notification.text(), "\n", "\r\n", -1))
+
+	if err := n.sendMail(smtpServer, auth, smtpFrom, receiver.To, []byte(msg)); err != nil {
+		return fmt.Errorf("email %s: %s", strings.Join(receiver.To, ","), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,314 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package logindex
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	corev1informers "k8s.io/client-go/informers/core/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"kubesphere.io/kubesphere/pkg/constants"
+	esclient "kubesphere.io/kubesphere/pkg/simple/client/elasticsearch"
+	fb "kubesphere.io/kubesphere/pkg/simple/client/fluentbit"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+const (
+	fluentbitName = "fluent-bit"
+
+	// dedicated indices are written in logstash format
+	indexDateLayout = "2006.01.02"
+)
+
+var (
+	log = logf.Log.WithName("log-index-lifecycle")
+
+	lifecycleInterval time.Duration
+)
+
+func init() {
+	flag.DurationVar(&lifecycleInterval, "log-index-lifecycle-interval", time.Hour, "interval of deleting expired logs of workspaces and syncing fluent bit with workspace log settings")
+}
+
+// fluentbitClient reads and updates the fluent bit CRD
+type fluentbitClient interface {
+	Get(name string) (*fb.FluentBit, error)
+	Update(name string, obj *fb.FluentBit) (*fb.FluentBit, error)
+}
+
+// IndexLifecycleController deletes expired logs of workspaces periodically, and routes logs of namespaces
+// added to workspaces with dedicated indices. Logs are deleted by only one replica of ks-controller-manager,
+// which holds the leader lock.
+type IndexLifecycleController struct {
+	client    clientset.Interface
+	fluentbit fluentbitClient
+
+	namespaceLister corev1listers.NamespaceLister
+	namespaceSynced cache.InformerSynced
+
+	// fluent bit is synced in a queue of a single key, changes of namespaces are merged
+	queue workqueue.RateLimitingInterface
+
+	interval time.Duration
+	now      func() time.Time
+}
+
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups=logging.kubesphere.io,resources=fluentbits,verbs=get;update
+func NewIndexLifecycleController(namespaceInformer corev1informers.NamespaceInformer, fluentbit fluentbitClient, client clientset.Interface) *IndexLifecycleController {
+	v := &IndexLifecycleController{
+		client:    client,
+		fluentbit: fluentbit,
+		queue:     workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "log-index-lifecycle"),
+		interval:  lifecycleInterval,
+		now:       time.Now,
+	}
+
+	v.namespaceLister = namespaceInformer.Lister()
+	v.namespaceSynced = namespaceInformer.Informer().HasSynced
+
+	namespaceInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: v.enqueueSync,
+		UpdateFunc: func(old, cur interface{}) {
+			if old.(*v1.Namespace).Labels[constants.WorkspaceLabelKey] != cur.(*v1.Namespace).Labels[constants.WorkspaceLabelKey] {
+				v.enqueueSync(cur)
+			}
+		},
+		DeleteFunc: v.enqueueSync,
+	})
+
+	return v
+}
+
+func (v *IndexLifecycleController) Start(stopCh <-chan struct{}) error {
+	return v.Run(stopCh)
+}
+
+func (v *IndexLifecycleController) Run(stopCh <-chan struct{}) error {
+	defer utilruntime.HandleCrash()
+	defer v.queue.ShutDown()
+
+	log.Info("starting log index lifecycle controller")
+	defer log.Info("shutting down log index lifecycle controller")
+
+	if !cache.WaitForCacheSync(stopCh, v.namespaceSynced) {
+		return fmt.Errorf("failed to wait for caches to sync")
+	}
+
+	go wait.Until(v.worker, time.Second, stopCh)
+	go wait.Until(v.deleteExpiredLogs, v.interval, stopCh)
+
+	<-stopCh
+	return nil
+}
+
+func (v *IndexLifecycleController) enqueueSync(obj interface{}) {
+	v.queue.Add(fluentbitName)
+}
+
+func (v *IndexLifecycleController) worker() {
+	for v.processNextWorkItem() {
+	}
+}
+
+func (v *IndexLifecycleController) processNextWorkItem() bool {
+	key, quit := v.queue.Get()
+	if quit {
+		return false
+	}
+	defer v.queue.Done(key)
+
+	if err := v.syncFluentbit(); err != nil {
+		log.Error(err, "sync fluent bit with workspace log settings failed")
+		v.queue.AddRateLimited(key)
+		return true
+	}
+
+	v.queue.Forget(key)
+	return true
+}
+
+func (v *IndexLifecycleController) workspaceLogSettings() ([]fb.WorkspaceLogSettings, error) {
+	configMap, err := v.client.CoreV1().ConfigMaps(fb.LoggingNamespace).Get(fb.WorkspaceConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return fb.ParseWorkspaceLogSettings(configMap.Data[fb.WorkspaceConfigMapData])
+}
+
+// workspaceNamespaces returns namespaces of each workspace
+func (v *IndexLifecycleController) workspaceNamespaces() (map[string][]string, error) {
+	namespaces, err := v.namespaceLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string][]string)
+	for _, namespace := range namespaces {
+		if workspace := namespace.Labels[constants.WorkspaceLabelKey]; workspace != "" {
+			result[workspace] = append(result[workspace], namespace.Name)
+		}
+	}
+
+	for _, names := range result {
+		sort.Strings(names)
+	}
+
+	return result, nil
+}
+
+// syncFluentbit routes logs of namespaces of workspaces with dedicated indices, changes of the settings
+// are synced by ks-apiserver when they are saved
+func (v *IndexLifecycleController) syncFluentbit() error {
+	settings, err := v.workspaceLogSettings()
+	if err != nil || len(settings) == 0 {
+		return err
+	}
+
+	namespaces, err := v.workspaceNamespaces()
+	if err != nil {
+		return err
+	}
+
+	fluentbit, err := v.fluentbit.Get(fluentbitName)
+	if err != nil {
+		return err
+	}
+
+	spec := fluentbit.Spec
+	fb.ApplyWorkspaceLogSettings(&spec, settings, namespaces)
+
+	if reflect.DeepEqual(spec, fluentbit.Spec) {
+		return nil
+	}
+
+	fluentbit.Spec = spec
+	_, err = v.fluentbit.Update(fluentbitName, fluentbit)
+	return err
+}
+
+// deleteExpiredLogs deletes logs of workspaces older than their retention days, from the shared index
+// by namespaces and from dedicated indices by days
+func (v *IndexLifecycleController) deleteExpiredLogs() {
+	// missed changes of namespaces are synced as well
+	v.queue.Add(fluentbitName)
+
+	settings, err := v.workspaceLogSettings()
+	if err != nil {
+		log.Error(err, "get workspace log settings failed")
+		return
+	}
+
+	if len(settings) == 0 {
+		return
+	}
+
+	configs, err := fb.ReadESConfigs(v.client)
+	if err != nil {
+		log.Error(err, "read fluent bit outputs failed")
+		return
+	}
+
+	if configs == nil {
+		return
+	}
+	configs.WriteESConfigs()
+
+	namespaces, err := v.workspaceNamespaces()
+	if err != nil {
+		log.Error(err, "list namespaces failed")
+		return
+	}
+
+	for _, item := range settings {
+		if item.RetentionDays <= 0 {
+			continue
+		}
+
+		before := retentionStart(v.now(), item.RetentionDays)
+
+		// logs written before the dedicated index was configured stay in the shared index
+		deleted, err := esclient.DeleteNamespaceLogs(namespaces[item.Workspace], before)
+		if err != nil {
+			log.Error(err, "delete expired logs failed", "workspace", item.Workspace)
+		} else if deleted > 0 {
+			log.Info("deleted expired logs", "workspace", item.Workspace, "count", deleted)
+		}
+
+		if item.IndexPrefix == "" {
+			continue
+		}
+
+		indices, err := esclient.Indices(item.IndexPrefix + "-*")
+		if err != nil {
+			log.Error(err, "list indices failed", "workspace", item.Workspace)
+			continue
+		}
+
+		for _, index := range expiredIndices(indices, item.IndexPrefix, before) {
+			if err := esclient.DeleteIndex(index); err != nil {
+				log.Error(err, "delete expired index failed", "workspace", item.Workspace, "index", index)
+			} else {
+				log.Info("deleted expired index", "workspace", item.Workspace, "index", index)
+			}
+		}
+	}
+}
+
+// retentionStart returns the start of the day, in UTC like daily indices, from which logs are kept
+func retentionStart(now time.Time, days int) time.Time {
+	year, month, day := now.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC).AddDate(0, 0, -days+1)
+}
+
+// expiredIndices returns daily indices of the prefix before the time, other indices are ignored
+func expiredIndices(indices []string, prefix string, before time.Time) []string {
+	var expired []string
+	for _, index := range indices {
+		if !strings.HasPrefix(index, prefix+"-") {
+			continue
+		}
+		day, err := time.Parse(indexDateLayout, strings.TrimPrefix(index, prefix+"-"))
+		if err != nil {
+			continue
+		}
+		if day.Before(before) {
+			expired = append(expired, index)
+		}
+	}
+	sort.Strings(expired)
+	return expired
+}
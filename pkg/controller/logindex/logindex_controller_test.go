@@ -0,0 +1,120 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package logindex
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"kubesphere.io/kubesphere/pkg/constants"
+	fb "kubesphere.io/kubesphere/pkg/simple/client/fluentbit"
+)
+
+type fakeFluentbit struct {
+	fluentbit *fb.FluentBit
+	updates   int
+}
+
+func (f *fakeFluentbit) Get(name string) (*fb.FluentBit, error) {
+	return f.fluentbit.DeepCopy(), nil
+}
+
+func (f *fakeFluentbit) Update(name string, obj *fb.FluentBit) (*fb.FluentBit, error) {
+	f.fluentbit = obj
+	f.updates++
+	return obj, nil
+}
+
+func newNamespace(name, workspace string) *v1.Namespace {
+	return &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{constants.WorkspaceLabelKey: workspace}}}
+}
+
+func newController(fluentbit *fakeFluentbit, objects ...runtime.Object) *IndexLifecycleController {
+	client := fake.NewSimpleClientset(objects...)
+	informerFactory := informers.NewSharedInformerFactory(client, 0)
+
+	controller := NewIndexLifecycleController(informerFactory.Core().V1().Namespaces(), fluentbit, client)
+
+	for _, object := range objects {
+		if namespace, ok := object.(*v1.Namespace); ok {
+			informerFactory.Core().V1().Namespaces().Informer().GetIndexer().Add(namespace)
+		}
+	}
+
+	return controller
+}
+
+func TestSyncFluentbit(t *testing.T) {
+	es := fb.Plugin{Type: "fluentbit_output", Name: "fluentbit-output-es", Parameters: []fb.Parameter{{Name: "Name", Value: "es"}, {Name: "Match", Value: "kube.*"}}}
+	fluentbit := &fakeFluentbit{fluentbit: &fb.FluentBit{Spec: fb.FluentBitSpec{Output: []fb.Plugin{es}}}}
+
+	settings := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: fb.LoggingNamespace, Name: fb.WorkspaceConfigMapName},
+		Data:       map[string]string{fb.WorkspaceConfigMapData: `[{"workspace":"finance","index_prefix":"ws-finance"}]`},
+	}
+
+	controller := newController(fluentbit, settings, newNamespace("billing", "finance"), newNamespace("payroll", "finance"), newNamespace("demo", "shared"))
+
+	if err := controller.syncFluentbit(); err != nil {
+		t.Fatal(err)
+	}
+
+	filters := fluentbit.fluentbit.Spec.Filter
+	if len(filters) != 1 || filters[0].Name != fb.WorkspaceFilterPrefix+"finance" {
+		t.Fatalf("expected the filter of finance, got %+v", filters)
+	}
+	if rule := filters[0].Parameters[2].Value; rule != "$kubernetes['namespace_name'] ^(billing|payroll)$ workspace.finance.$TAG false" {
+		t.Errorf("unexpected rule %s", rule)
+	}
+
+	// fluent bit isn't updated if nothing changed
+	if err := controller.syncFluentbit(); err != nil || fluentbit.updates != 1 {
+		t.Errorf("expected 1 update, got %d %v", fluentbit.updates, err)
+	}
+}
+
+func TestSyncFluentbitWithoutSettings(t *testing.T) {
+	fluentbit := &fakeFluentbit{fluentbit: &fb.FluentBit{}}
+	controller := newController(fluentbit, newNamespace("billing", "finance"))
+
+	if err := controller.syncFluentbit(); err != nil || fluentbit.updates != 0 {
+		t.Errorf("expected no updates, got %d %v", fluentbit.updates, err)
+	}
+}
+
+func TestExpiredIndices(t *testing.T) {
+	now := time.Date(2019, 10, 18, 9, 30, 0, 0, time.UTC)
+
+	before := retentionStart(now, 7)
+	if expected := time.Date(2019, 10, 12, 0, 0, 0, 0, time.UTC); !before.Equal(expected) {
+		t.Errorf("expected retention start %s, got %s", expected, before)
+	}
+
+	indices := []string{"ws-finance-2019.10.18", "ws-finance-2019.10.12", "ws-finance-2019.10.11", "ws-finance-2019.09.30", "ws-finance-archive", "ws-finance-eu-2019.09.01"}
+	expected := []string{"ws-finance-2019.09.30", "ws-finance-2019.10.11"}
+
+	if expired := expiredIndices(indices, "ws-finance", before); !reflect.DeepEqual(expired, expected) {
+		t.Errorf("expected %v, got %v", expected, expired)
+	}
+}
@@ -0,0 +1,148 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package metering
+
+import (
+	"flag"
+	"net/http"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"kubesphere.io/kubesphere/pkg/apis/monitoring/v1alpha1"
+	ksclient "kubesphere.io/kubesphere/pkg/client/clientset/versioned"
+	"kubesphere.io/kubesphere/pkg/simple/client/metering"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+// queries of a day take longer than the ones of ks-apiserver
+const queryTimeout = time.Minute
+
+var (
+	log = logf.Log.WithName("metering-rollup")
+
+	rollupInterval     time.Duration
+	meteringStep       time.Duration
+	backfillDays       int
+	gpuResource        string
+	prometheusEndpoint string
+)
+
+func init() {
+	flag.DurationVar(&rollupInterval, "metering-rollup-interval", time.Hour, "interval of persisting daily rollups of resource usage")
+	flag.DurationVar(&meteringStep, "metering-step", 5*time.Minute, "resolution of integrating resource usage over a day, the same as the one of ks-apiserver")
+	flag.IntVar(&backfillDays, "metering-backfill-days", 7, "days before today rolled up from Prometheus if their rollups are missing, should be within the retention of Prometheus")
+	flag.StringVar(&gpuResource, "metering-gpu-resource", "nvidia_com_gpu", "resource label of GPUs in kube_pod_container_resource_requests")
+	flag.StringVar(&prometheusEndpoint, "prometheus-endpoint", "http://prometheus-k8s.kubesphere-monitoring-system.svc:9090/api/v1/", "Prometheus API resource usage is metered from")
+}
+
+// RollupController persists daily rollups of resource usage periodically. Rollups are created by only one replica
+// of ks-controller-manager, which holds the leader lock, and rollups of a day are checked by listing them from
+// the API server, so the ones being created are never deleted as incomplete.
+type RollupController struct {
+	client ksclient.Interface
+	meter  *metering.Meter
+
+	interval     time.Duration
+	backfillDays int
+	now          func() time.Time
+}
+
+// +kubebuilder:rbac:groups=monitoring.kubesphere.io,resources=meteringrollups,verbs=get;list;create;delete;deletecollection
+func NewRollupController(client ksclient.Interface) *RollupController {
+	return &RollupController{
+		client: client,
+		meter: &metering.Meter{
+			Query:       metering.NewPrometheusQuerier(prometheusEndpoint, &http.Client{Timeout: queryTimeout + 10*time.Second}),
+			Step:        meteringStep,
+			GPUResource: gpuResource,
+			Timeout:     queryTimeout,
+		},
+		interval:     rollupInterval,
+		backfillDays: backfillDays,
+		now:          time.Now,
+	}
+}
+
+func (c *RollupController) Start(stopCh <-chan struct{}) error {
+	return c.Run(stopCh)
+}
+
+func (c *RollupController) Run(stopCh <-chan struct{}) error {
+	defer utilruntime.HandleCrash()
+
+	log.Info("starting metering rollup controller")
+	defer log.Info("shutting down metering rollup controller")
+
+	go wait.Until(c.rollup, c.interval, stopCh)
+
+	<-stopCh
+	return nil
+}
+
+// rollup rolls up days in the backfill window without complete rollups, today is rolled up after it ends
+func (c *RollupController) rollup() {
+	today := metering.Date(c.now())
+	rollups := c.client.MonitoringV1alpha1().MeteringRollups()
+
+	for i := c.backfillDays; i > 0; i-- {
+		day := today.AddDate(0, 0, -i)
+		date := day.Format(v1alpha1.MeteringRollupDateLayout)
+		selector := metering.RollupSelector(day).String()
+
+		list, err := rollups.List(metav1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			log.Error(err, "failed to list metering rollups", "date", date)
+			continue
+		}
+		existing := make([]*v1alpha1.MeteringRollup, 0, len(list.Items))
+		for j := range list.Items {
+			existing = append(existing, &list.Items[j])
+		}
+		if _, complete := metering.MergeRollups(existing); complete {
+			continue
+		}
+
+		entries, err := c.meter.MeterUsage(day, day.AddDate(0, 0, 1))
+		if err != nil {
+			log.Error(err, "failed to meter resource usage", "date", date)
+			continue
+		}
+
+		// nothing is metered before Prometheus is ready, try again later
+		if len(entries) == 0 {
+			continue
+		}
+
+		// rollups left by an interrupted rollup may be split differently
+		if len(existing) > 0 {
+			if err := rollups.DeleteCollection(&metav1.DeleteOptions{}, metav1.ListOptions{LabelSelector: selector}); err != nil {
+				log.Error(err, "failed to delete incomplete metering rollups", "date", date)
+				continue
+			}
+		}
+
+		for _, rollup := range metering.BuildRollups(day, entries) {
+			if _, err := rollups.Create(rollup); err != nil && !errors.IsAlreadyExists(err) {
+				log.Error(err, "failed to create metering rollup", "name", rollup.Name)
+			}
+		}
+	}
+}
@@ -0,0 +1,95 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package metering
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+	"kubesphere.io/kubesphere/pkg/apis/monitoring/v1alpha1"
+	"kubesphere.io/kubesphere/pkg/client/clientset/versioned/fake"
+	"kubesphere.io/kubesphere/pkg/simple/client/metering"
+)
+
+func TestRollup(t *testing.T) {
+	rollup := func(name, date string, shards int) *v1alpha1.MeteringRollup {
+		return &v1alpha1.MeteringRollup{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{v1alpha1.MeteringRollupDateLabel: date}},
+			Spec:       v1alpha1.MeteringRollupSpec{Date: date, Shards: shards},
+		}
+	}
+
+	client := fake.NewSimpleClientset(
+		rollup("daily-2019-06-04-0", "2019-06-04", 1),
+		// the first shard is missing
+		rollup("daily-2019-06-05-1", "2019-06-05", 2),
+	)
+
+	var deleted []string
+	client.PrependReactor("delete-collection", "meteringrollups", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		deleted = append(deleted, action.(k8stesting.DeleteCollectionAction).GetListRestrictions().Labels.String())
+		return true, nil, nil
+	})
+
+	var metered []string
+	c := &RollupController{
+		client: client,
+		meter: &metering.Meter{
+			Query: func(expr string, params url.Values) (model.Matrix, error) {
+				metered = append(metered, params.Get("start"))
+				return model.Matrix{
+					&model.SampleStream{Metric: model.Metric{"namespace": "demo"}, Values: []model.SamplePair{{Timestamp: 3600000, Value: 1}}},
+				}, nil
+			},
+			Step: time.Hour,
+		},
+		backfillDays: 2,
+		now:          func() time.Time { return time.Date(2019, 6, 6, 12, 0, 0, 0, time.UTC) },
+	}
+
+	c.rollup()
+
+	if len(deleted) != 1 || deleted[0] != metering.RollupSelector(time.Date(2019, 6, 5, 0, 0, 0, 0, time.UTC)).String() {
+		t.Errorf("expected incomplete rollups of 2019-06-05 deleted, got %v", deleted)
+	}
+
+	// only the incomplete day is metered, by each query
+	if len(metered) == 0 {
+		t.Fatalf("expected the incomplete day metered")
+	}
+	for _, start := range metered {
+		if start != "1559696400" {
+			t.Errorf("expected queries start at 2019-06-05T01:00:00Z, got %s", start)
+		}
+	}
+
+	var created []string
+	for _, action := range client.Actions() {
+		if create, ok := action.(k8stesting.CreateAction); ok {
+			created = append(created, create.GetObject().(*v1alpha1.MeteringRollup).Name)
+		}
+	}
+	if len(created) != 1 || created[0] != "daily-2019-06-05-0" {
+		t.Errorf("expected rollups of 2019-06-05 created, got %v", created)
+	}
+}
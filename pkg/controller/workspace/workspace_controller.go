@@ -587,6 +587,24 @@ func getWorkspaceRegular(workspaceName string) *rbac.ClusterRole {
 			ResourceNames: []string{workspaceName},
 			Resources:     []string{"workspaces/members"},
 		},
+		{
+			Verbs:         []string{"get", "create"},
+			APIGroups:     []string{"logging.kubesphere.io"},
+			ResourceNames: []string{workspaceName},
+			Resources:     []string{"workspaces/savedlogqueries"},
+		},
+		{
+			Verbs:         []string{"get"},
+			APIGroups:     []string{"monitoring.kubesphere.io"},
+			ResourceNames: []string{workspaceName},
+			Resources:     []string{"workspaces/promql"},
+		},
+		{
+			Verbs:         []string{"get", "create"},
+			APIGroups:     []string{"monitoring.kubesphere.io"},
+			ResourceNames: []string{workspaceName},
+			Resources:     []string{"workspaces/dashboards"},
+		},
 	}
 
 	return regular
@@ -0,0 +1,130 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package informers
+
+import (
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+
+	"kubesphere.io/kubesphere/pkg/simple/client/k8s"
+)
+
+var (
+	dynamicOnce            sync.Once
+	dynamicInformerFactory *DynamicInformerFactory
+)
+
+// DynamicInformerFactory caches unstructured objects of any GroupVersionResource,
+// informers are only created for resources requested by ForResource
+type DynamicInformerFactory struct {
+	client dynamic.Interface
+	resync time.Duration
+
+	lock      sync.Mutex
+	informers map[schema.GroupVersionResource]cache.SharedIndexInformer
+	started   map[schema.GroupVersionResource]bool
+}
+
+func NewDynamicInformerFactory(client dynamic.Interface, resync time.Duration) *DynamicInformerFactory {
+	return &DynamicInformerFactory{
+		client:    client,
+		resync:    resync,
+		informers: make(map[schema.GroupVersionResource]cache.SharedIndexInformer),
+		started:   make(map[schema.GroupVersionResource]bool),
+	}
+}
+
+func DynamicSharedInformerFactory() *DynamicInformerFactory {
+	dynamicOnce.Do(func() {
+		dynamicInformerFactory = NewDynamicInformerFactory(k8s.DynamicClient(), defaultResync)
+	})
+	return dynamicInformerFactory
+}
+
+// ForResource returns the shared informer of the resource, objects in the store are *unstructured.Unstructured
+func (f *DynamicInformerFactory) ForResource(gvr schema.GroupVersionResource) cache.SharedIndexInformer {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if informer, ok := f.informers[gvr]; ok {
+		return informer
+	}
+
+	resource := f.client.Resource(gvr)
+
+	informer := cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return resource.List(options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return resource.Watch(options)
+			},
+		},
+		&unstructured.Unstructured{},
+		f.resync,
+		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
+	)
+
+	f.informers[gvr] = informer
+
+	return informer
+}
+
+// Start runs all informers which are not started yet
+func (f *DynamicInformerFactory) Start(stopCh <-chan struct{}) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for gvr, informer := range f.informers {
+		if !f.started[gvr] {
+			go informer.Run(stopCh)
+			f.started[gvr] = true
+		}
+	}
+}
+
+// WaitForCacheSync waits for all started informers, returns whether each of them is synced
+func (f *DynamicInformerFactory) WaitForCacheSync(stopCh <-chan struct{}) map[schema.GroupVersionResource]bool {
+	informers := func() map[schema.GroupVersionResource]cache.SharedIndexInformer {
+		f.lock.Lock()
+		defer f.lock.Unlock()
+
+		informers := make(map[schema.GroupVersionResource]cache.SharedIndexInformer)
+		for gvr, informer := range f.informers {
+			if f.started[gvr] {
+				informers[gvr] = informer
+			}
+		}
+		return informers
+	}()
+
+	res := make(map[schema.GroupVersionResource]bool)
+	for gvr, informer := range informers {
+		res[gvr] = cache.WaitForCacheSync(stopCh, informer.HasSynced)
+	}
+	return res
+}
@@ -0,0 +1,178 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+
+package alerting
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"kubesphere.io/kubesphere/pkg/apis/alerting/v1alpha1"
+	am "kubesphere.io/kubesphere/pkg/simple/client/alertmanager"
+)
+
+// Scope is the tenant of alerts and silences, alerts of the cluster if both are empty
+type Scope struct {
+	Workspace string
+	Namespace string
+}
+
+type AlertsResult struct {
+	Status int        `json:"status" description:"response status"`
+	Error  string     `json:"error,omitempty" description:"debug information"`
+	Alerts []am.Alert `json:"alerts,omitempty" description:"firing alerts"`
+}
+
+type SilencesResult struct {
+	Status   int          `json:"status" description:"response status"`
+	Error    string       `json:"error,omitempty" description:"debug information"`
+	Silences []am.Silence `json:"silences,omitempty" description:"silences"`
+}
+
+// matcher is the tenant label every alert and silence of the scope has
+func (s Scope) matcher() *am.Matcher {
+	switch {
+	case s.Namespace != "":
+		return &am.Matcher{Name: v1alpha1.AlertNamespaceLabel, Value: s.Namespace}
+	case s.Workspace != "":
+		return &am.Matcher{Name: v1alpha1.AlertWorkspaceLabel, Value: s.Workspace}
+	default:
+		return nil
+	}
+}
+
+// ListAlerts lists firing alerts of the scope matching the filters, eg. severity="critical"
+func ListAlerts(scope Scope, filters []string, muted bool) *AlertsResult {
+	// filters are ANDed by Alertmanager, other filters can't widen the scope
+	if m := scope.matcher(); m != nil {
+		filters = append(filters, fmt.Sprintf("%s=%s", m.Name, strconv.Quote(m.Value)))
+	}
+
+	alerts, err := am.ListAlerts(filters, muted)
+	if err != nil {
+		return &AlertsResult{Status: statusOf(err), Error: err.Error()}
+	}
+
+	return &AlertsResult{Status: http.StatusOK, Alerts: alerts}
+}
+
+func ListSilences(scope Scope) *SilencesResult {
+	silences, err := am.ListSilences(nil)
+	if err != nil {
+		return &SilencesResult{Status: statusOf(err), Error: err.Error()}
+	}
+
+	result := make([]am.Silence, 0)
+	for _, silence := range silences {
+		if inScope(scope, silence) {
+			result = append(result, silence)
+		}
+	}
+
+	return &SilencesResult{Status: http.StatusOK, Silences: result}
+}
+
+// CreateSilence creates a silence of the scope, it only mutes alerts of the scope whatever matchers it has
+func CreateSilence(scope Scope, silence am.Silence, creator string) *SilencesResult {
+	if len(silence.Matchers) == 0 {
+		return &SilencesResult{Status: http.StatusBadRequest, Error: "at least one matcher is required"}
+	}
+
+	now := time.Now()
+	if silence.StartsAt.IsZero() {
+		silence.StartsAt = now
+	}
+	if !silence.EndsAt.After(silence.StartsAt) || !silence.EndsAt.After(now) {
+		return &SilencesResult{Status: http.StatusBadRequest, Error: "endsAt must be after startsAt and now"}
+	}
+
+	silence = scopeSilence(scope, silence)
+	silence.CreatedBy = creator
+
+	id, err := am.CreateSilence(silence)
+	if err != nil {
+		return &SilencesResult{Status: statusOf(err), Error: err.Error()}
+	}
+
+	silence.ID = id
+	return &SilencesResult{Status: http.StatusOK, Silences: []am.Silence{silence}}
+}
+
+// ExpireSilence expires the silence if it's of the scope
+func ExpireSilence(scope Scope, id string) *SilencesResult {
+	silence, err := am.GetSilence(id)
+	if err != nil {
+		return &SilencesResult{Status: statusOf(err), Error: err.Error()}
+	}
+
+	if !inScope(scope, *silence) {
+		return &SilencesResult{Status: http.StatusNotFound, Error: fmt.Sprintf("silence %s not found", id)}
+	}
+
+	if err := am.ExpireSilence(id); err != nil {
+		return &SilencesResult{Status: statusOf(err), Error: err.Error()}
+	}
+
+	return &SilencesResult{Status: http.StatusOK}
+}
+
+// scopeSilence replaces matchers of the tenant label with the one of the scope,
+// ids are dropped so that silences of other tenants can't be updated
+func scopeSilence(scope Scope, silence am.Silence) am.Silence {
+	silence.ID = ""
+	silence.Status = nil
+	silence.UpdatedAt = nil
+
+	m := scope.matcher()
+	if m == nil {
+		return silence
+	}
+
+	matchers := make([]am.Matcher, 0, len(silence.Matchers)+1)
+	for _, matcher := range silence.Matchers {
+		if matcher.Name != m.Name {
+			matchers = append(matchers, matcher)
+		}
+	}
+	silence.Matchers = append(matchers, *m)
+
+	return silence
+}
+
+func inScope(scope Scope, silence am.Silence) bool {
+	m := scope.matcher()
+	if m == nil {
+		return true
+	}
+
+	for _, matcher := range silence.Matchers {
+		if matcher == *m {
+			return true
+		}
+	}
+	return false
+}
+
+func statusOf(err error) int {
+	if e, ok := err.(am.Error); ok && e.Status >= http.StatusBadRequest && e.Status < http.StatusInternalServerError {
+		return e.Status
+	}
+	return http.StatusBadGateway
+}
@@ -0,0 +1,79 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+
+package alerting
+
+import (
+	"reflect"
+	"testing"
+
+	am "kubesphere.io/kubesphere/pkg/simple/client/alertmanager"
+)
+
+func TestScopeSilence(t *testing.T) {
+	silence := am.Silence{
+		ID: "other",
+		Matchers: []am.Matcher{
+			{Name: "alertname", Value: "memory-high"},
+			{Name: "namespace", Value: ".*", IsRegex: true},
+		},
+		Status: &am.SilenceStatus{State: "active"},
+	}
+
+	tests := []struct {
+		scope    Scope
+		expected []am.Matcher
+	}{
+		{
+			scope:    Scope{},
+			expected: silence.Matchers,
+		},
+		{
+			scope: Scope{Namespace: "demo"},
+			expected: []am.Matcher{
+				{Name: "alertname", Value: "memory-high"},
+				{Name: "namespace", Value: "demo"},
+			},
+		},
+		{
+			scope: Scope{Workspace: "ws"},
+			expected: []am.Matcher{
+				{Name: "alertname", Value: "memory-high"},
+				{Name: "namespace", Value: ".*", IsRegex: true},
+				{Name: "workspace", Value: "ws"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		scoped := scopeSilence(test.scope, silence)
+		if scoped.ID != "" || scoped.Status != nil {
+			t.Errorf("%+v: id and status should be dropped, got %s %v", test.scope, scoped.ID, scoped.Status)
+		}
+		if !reflect.DeepEqual(scoped.Matchers, test.expected) {
+			t.Errorf("%+v: expected %v, got %v", test.scope, test.expected, scoped.Matchers)
+		}
+		if !inScope(test.scope, scoped) {
+			t.Errorf("%+v: scoped silence should be in scope", test.scope)
+		}
+	}
+
+	if inScope(Scope{Namespace: "demo"}, silence) {
+		t.Errorf("silence with regex matcher of namespaces should be out of scope of namespace demo")
+	}
+}
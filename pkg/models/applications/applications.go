@@ -273,26 +273,13 @@ func getIng(namespace string, services []v1.Service) []v1beta1.Ingress {
 			return nil
 		}
 
-		glog.Error(result)
 		for _, i := range result.Items {
 			ingress := i.(*v1beta1.Ingress)
 
-			exist := false
-			var tmpRules []v1beta1.IngressRule
-			for _, rule := range ingress.Spec.Rules {
-				for _, p := range rule.HTTP.Paths {
-					if p.Backend.ServiceName == svc.Name {
-						exist = true
-						tmpRules = append(tmpRules, rule)
-					}
-				}
-
-			}
-
-			if exist {
+			if rules := resources.IngressRulesForService(ingress, svc.Name); len(rules) > 0 {
 				ing := v1beta1.Ingress{}
 				ing.Name = ingress.Name
-				ing.Spec.Rules = tmpRules
+				ing.Spec.Rules = rules
 				ings = append(ings, ing)
 			}
 		}
@@ -26,6 +26,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/authentication/user"
 	"kubesphere.io/kubesphere/pkg/constants"
 	"kubesphere.io/kubesphere/pkg/informers"
 	"kubesphere.io/kubesphere/pkg/models"
@@ -92,7 +93,8 @@ func GetDevopsRoleSimpleRules(role string) []models.SimpleRule {
 }
 
 // Get user roles in namespace
-func GetUserRoles(namespace, username string) ([]*rbacv1.Role, error) {
+func GetUserRoles(namespace string, usr user.Info) ([]*rbacv1.Role, error) {
+	username := usr.GetName()
 	clusterRoleLister := informers.SharedInformerFactory().Rbac().V1().ClusterRoles().Lister()
 	roleBindingLister := informers.SharedInformerFactory().Rbac().V1().RoleBindings().Lister()
 	roleLister := informers.SharedInformerFactory().Rbac().V1().Roles().Lister()
@@ -106,7 +108,7 @@ func GetUserRoles(namespace, username string) ([]*rbacv1.Role, error) {
 	roles := make([]*rbacv1.Role, 0)
 
 	for _, roleBinding := range roleBindings {
-		if k8sutil.ContainsUser(roleBinding.Subjects, username) {
+		if k8sutil.ContainsIdentity(roleBinding.Subjects, usr) {
 			if roleBinding.RoleRef.Kind == ClusterRoleKind {
 				clusterRole, err := clusterRoleLister.Get(roleBinding.RoleRef.Name)
 				if err != nil {
@@ -144,7 +146,8 @@ func GetUserRoles(namespace, username string) ([]*rbacv1.Role, error) {
 	return roles, nil
 }
 
-func GetUserClusterRoles(username string) (*rbacv1.ClusterRole, []*rbacv1.ClusterRole, error) {
+func GetUserClusterRoles(usr user.Info) (*rbacv1.ClusterRole, []*rbacv1.ClusterRole, error) {
+	username := usr.GetName()
 	clusterRoleLister := informers.SharedInformerFactory().Rbac().V1().ClusterRoles().Lister()
 	clusterRoleBindingLister := informers.SharedInformerFactory().Rbac().V1().ClusterRoleBindings().Lister()
 	clusterRoleBindings, err := clusterRoleBindingLister.List(labels.Everything())
@@ -157,7 +160,7 @@ func GetUserClusterRoles(username string) (*rbacv1.ClusterRole, []*rbacv1.Cluste
 	clusterRoles := make([]*rbacv1.ClusterRole, 0)
 	userFacingClusterRole := &rbacv1.ClusterRole{}
 	for _, clusterRoleBinding := range clusterRoleBindings {
-		if k8sutil.ContainsUser(clusterRoleBinding.Subjects, username) {
+		if k8sutil.ContainsIdentity(clusterRoleBinding.Subjects, usr) {
 			clusterRole, err := clusterRoleLister.Get(clusterRoleBinding.RoleRef.Name)
 			if err != nil {
 				if apierrors.IsNotFound(err) {
@@ -178,16 +181,16 @@ func GetUserClusterRoles(username string) (*rbacv1.ClusterRole, []*rbacv1.Cluste
 	return userFacingClusterRole, clusterRoles, nil
 }
 
-func GetUserClusterRole(username string) (*rbacv1.ClusterRole, error) {
-	userFacingClusterRole, _, err := GetUserClusterRoles(username)
+func GetUserClusterRole(usr user.Info) (*rbacv1.ClusterRole, error) {
+	userFacingClusterRole, _, err := GetUserClusterRoles(usr)
 	if err != nil {
 		return nil, err
 	}
 	return userFacingClusterRole, nil
 }
 
-func GetUserClusterRules(username string) ([]rbacv1.PolicyRule, error) {
-	_, clusterRoles, err := GetUserClusterRoles(username)
+func GetUserClusterRules(usr user.Info) ([]rbacv1.PolicyRule, error) {
+	_, clusterRoles, err := GetUserClusterRoles(usr)
 
 	if err != nil {
 		return nil, err
@@ -201,8 +204,8 @@ func GetUserClusterRules(username string) ([]rbacv1.PolicyRule, error) {
 	return rules, nil
 }
 
-func GetUserRules(namespace, username string) ([]rbacv1.PolicyRule, error) {
-	roles, err := GetUserRoles(namespace, username)
+func GetUserRules(namespace string, usr user.Info) ([]rbacv1.PolicyRule, error) {
+	roles, err := GetUserRoles(namespace, usr)
 
 	if err != nil {
 		return nil, err
@@ -244,7 +247,7 @@ func GetWorkspaceRole(workspace, role string) (*rbacv1.ClusterRole, error) {
 	return informers.SharedInformerFactory().Rbac().V1().ClusterRoles().Lister().Get(role)
 }
 
-func GetUserWorkspaceRoleMap(username string) (map[string]string, error) {
+func GetUserWorkspaceRoleMap(usr user.Info) (map[string]string, error) {
 
 	clusterRoleBindings, err := informers.SharedInformerFactory().Rbac().V1().ClusterRoleBindings().Lister().List(labels.Everything())
 
@@ -257,7 +260,7 @@ func GetUserWorkspaceRoleMap(username string) (map[string]string, error) {
 
 	for _, roleBinding := range clusterRoleBindings {
 		if workspace := k8sutil.GetControlledWorkspace(roleBinding.OwnerReferences); workspace != "" &&
-			k8sutil.ContainsUser(roleBinding.Subjects, username) {
+			k8sutil.ContainsIdentity(roleBinding.Subjects, usr) {
 			result[workspace] = roleBinding.RoleRef.Name
 		}
 	}
@@ -265,8 +268,8 @@ func GetUserWorkspaceRoleMap(username string) (map[string]string, error) {
 	return result, nil
 }
 
-func GetUserWorkspaceRole(workspace, username string) (*rbacv1.ClusterRole, error) {
-	workspaceRoleMap, err := GetUserWorkspaceRoleMap(username)
+func GetUserWorkspaceRole(workspace string, usr user.Info) (*rbacv1.ClusterRole, error) {
+	workspaceRoleMap, err := GetUserWorkspaceRoleMap(usr)
 
 	if err != nil {
 		return nil, err
@@ -276,7 +279,7 @@ func GetUserWorkspaceRole(workspace, username string) (*rbacv1.ClusterRole, erro
 		return informers.SharedInformerFactory().Rbac().V1().ClusterRoles().Lister().Get(workspaceRole)
 	}
 
-	return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "workspace user"}, username)
+	return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "workspace user"}, usr.GetName())
 }
 
 func GetRoleBindings(namespace string, roleName string) ([]*rbacv1.RoleBinding, error) {
@@ -475,8 +478,8 @@ func NamespaceUsers(namespaceName string) ([]*models.User, error) {
 	return users, nil
 }
 
-func GetUserWorkspaceSimpleRules(workspace, username string) ([]models.SimpleRule, error) {
-	clusterRules, err := GetUserClusterRules(username)
+func GetUserWorkspaceSimpleRules(workspace string, usr user.Info) ([]models.SimpleRule, error) {
+	clusterRules, err := GetUserClusterRules(usr)
 	if err != nil {
 		return nil, err
 	}
@@ -490,7 +493,7 @@ func GetUserWorkspaceSimpleRules(workspace, username string) ([]models.SimpleRul
 		return GetWorkspaceRoleSimpleRules(workspace, constants.WorkspaceAdmin), nil
 	}
 
-	workspaceRole, err := GetUserWorkspaceRole(workspace, username)
+	workspaceRole, err := GetUserWorkspaceRole(workspace, usr)
 
 	if err != nil {
 		if apierrors.IsNotFound(err) {
@@ -547,20 +550,20 @@ func GetClusterRoleSimpleRules(clusterRoleName string) ([]models.SimpleRule, err
 	return getClusterSimpleRule(clusterRole.Rules), nil
 }
 
-func GetUserClusterSimpleRules(username string) ([]models.SimpleRule, error) {
-	clusterRules, err := GetUserClusterRules(username)
+func GetUserClusterSimpleRules(usr user.Info) ([]models.SimpleRule, error) {
+	clusterRules, err := GetUserClusterRules(usr)
 	if err != nil {
 		return nil, err
 	}
 	return getClusterSimpleRule(clusterRules), nil
 }
 
-func GetUserNamespaceSimpleRules(namespace, username string) ([]models.SimpleRule, error) {
-	clusterRules, err := GetUserClusterRules(username)
+func GetUserNamespaceSimpleRules(namespace string, usr user.Info) ([]models.SimpleRule, error) {
+	clusterRules, err := GetUserClusterRules(usr)
 	if err != nil {
 		return nil, err
 	}
-	rules, err := GetUserRules(namespace, username)
+	rules, err := GetUserRules(namespace, usr)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,391 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package bulk
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/go-ldap/ldap"
+	"github.com/golang/glog"
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	"kubesphere.io/kubesphere/pkg/models"
+	"kubesphere.io/kubesphere/pkg/models/iam"
+	"kubesphere.io/kubesphere/pkg/models/workspaces"
+	"kubesphere.io/kubesphere/pkg/params"
+	"kubesphere.io/kubesphere/pkg/utils/k8sutil"
+	"kubesphere.io/kubesphere/pkg/utils/sliceutil"
+)
+
+const (
+	KindUser      = "user"
+	KindGroup     = "group"
+	KindMember    = "member"
+	KindWorkspace = "workspace"
+
+	ActionCreated   = "created"
+	ActionUpdated   = "updated"
+	ActionUnchanged = "unchanged"
+	ActionFailed    = "failed"
+)
+
+// columns of csv, groups and workspaces are separated by semicolon, such as "dev;ops" and "demo=workspace-admin;test=workspace-viewer"
+var csvHeader = []string{"username", "email", "password", "description", "lang", "cluster_role", "groups", "workspaces"}
+
+type User struct {
+	Username    string `json:"username"`
+	Email       string `json:"email"`
+	Password    string `json:"password,omitempty"`
+	Description string `json:"description,omitempty"`
+	Lang        string `json:"lang,omitempty"`
+	ClusterRole string `json:"cluster_role,omitempty"`
+	// paths of groups
+	Groups []string `json:"groups,omitempty"`
+	// workspace name to workspace role, such as workspace-admin
+	Workspaces map[string]string `json:"workspaces,omitempty"`
+}
+
+type Group struct {
+	Path        string   `json:"path"`
+	Description string   `json:"description,omitempty"`
+	Members     []string `json:"members,omitempty"`
+}
+
+type Document struct {
+	Users  []User  `json:"users"`
+	Groups []Group `json:"groups,omitempty"`
+}
+
+type Result struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Action string `json:"action"`
+	Error  string `json:"error,omitempty"`
+}
+
+type Report struct {
+	Created int      `json:"created"`
+	Updated int      `json:"updated"`
+	Failed  int      `json:"failed"`
+	Results []Result `json:"results"`
+}
+
+func (r *Report) add(kind, name, action string, err error) {
+	result := Result{Kind: kind, Name: name, Action: action}
+
+	if err != nil {
+		glog.Errorf("import %s %s failed: %v", kind, name, err)
+		result.Action = ActionFailed
+		result.Error = err.Error()
+	}
+
+	switch result.Action {
+	case ActionCreated:
+		r.Created++
+	case ActionUpdated:
+		r.Updated++
+	case ActionFailed:
+		r.Failed++
+	}
+
+	r.Results = append(r.Results, result)
+}
+
+// ReadCSV parses users from csv, the first row is the header, columns are matched by name
+func ReadCSV(r io.Reader) (*Document, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+
+	if err == io.EOF {
+		return nil, errors.New("header is required")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int)
+
+	for i, column := range header {
+		columns[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+
+	if _, ok := columns["username"]; !ok {
+		return nil, errors.New("username column is required")
+	}
+
+	doc := &Document{Users: make([]User, 0)}
+
+	for {
+		record, err := reader.Read()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		value := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		user := User{
+			Username:    value("username"),
+			Email:       value("email"),
+			Password:    value("password"),
+			Description: value("description"),
+			Lang:        value("lang"),
+			ClusterRole: value("cluster_role"),
+			Groups:      splitList(value("groups")),
+		}
+
+		for _, item := range splitList(value("workspaces")) {
+			parts := strings.SplitN(item, "=", 2)
+			if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+				return nil, fmt.Errorf("invalid workspace membership %s of user %s", item, user.Username)
+			}
+			if user.Workspaces == nil {
+				user.Workspaces = make(map[string]string)
+			}
+			user.Workspaces[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+
+		doc.Users = append(doc.Users, user)
+	}
+
+	return doc, nil
+}
+
+// WriteCSV writes users of the document, passwords are never exported
+func WriteCSV(w io.Writer, doc *Document) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, user := range doc.Users {
+		memberships := make([]string, 0)
+		for workspace, role := range user.Workspaces {
+			memberships = append(memberships, fmt.Sprintf("%s=%s", workspace, role))
+		}
+		sort.Strings(memberships)
+
+		record := []string{user.Username, user.Email, "", user.Description, user.Lang, user.ClusterRole,
+			strings.Join(user.Groups, ";"), strings.Join(memberships, ";")}
+
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+func splitList(value string) []string {
+	result := make([]string, 0)
+	for _, item := range strings.Split(value, ";") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// Export collects users with their groups and workspace memberships, and all groups
+func Export() (*Document, error) {
+	users, err := iam.ListUsers(&params.Conditions{}, "username", false, math.MaxInt32, 0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &Document{Users: make([]User, 0), Groups: make([]Group, 0)}
+
+	for _, item := range users.Items {
+		user := item.(models.User)
+
+		groups, err := iam.GetUserGroups(user.Username)
+
+		if err != nil {
+			return nil, err
+		}
+
+		workspaceRoles, err := iam.GetUserWorkspaceRoleMap(k8sutil.NewIdentity(user.Username, rbacv1.UserKind))
+
+		if err != nil {
+			return nil, err
+		}
+
+		exported := User{
+			Username:    user.Username,
+			Email:       user.Email,
+			Description: user.Description,
+			Lang:        user.Lang,
+			ClusterRole: user.ClusterRole,
+			Groups:      groups,
+		}
+
+		for workspace, role := range workspaceRoles {
+			if exported.Workspaces == nil {
+				exported.Workspaces = make(map[string]string)
+			}
+			// the name of workspace role is workspace:<workspace>:<role>
+			exported.Workspaces[workspace] = fmt.Sprintf("workspace-%s", role[strings.LastIndex(role, ":")+1:])
+		}
+
+		doc.Users = append(doc.Users, exported)
+	}
+
+	groups, err := iam.ListAllGroups()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, group := range groups {
+		doc.Groups = append(doc.Groups, Group{Path: group.Path, Description: group.Description, Members: group.Members})
+	}
+
+	return doc, nil
+}
+
+// Import creates or updates groups, users and memberships of the document.
+// Nothing is removed, existing members and memberships not listed in the document are kept.
+func Import(doc *Document) *Report {
+	report := &Report{Results: make([]Result, 0)}
+
+	groups := make([]Group, len(doc.Groups))
+	copy(groups, doc.Groups)
+
+	// parent groups must be created first
+	sort.SliceStable(groups, func(i, j int) bool {
+		return strings.Count(groups[i].Path, ":") < strings.Count(groups[j].Path, ":")
+	})
+
+	for _, group := range groups {
+		action, err := importGroup(group)
+		report.add(KindGroup, group.Path, action, err)
+	}
+
+	for _, user := range doc.Users {
+		action, err := importUser(user)
+		report.add(KindUser, user.Username, action, err)
+
+		if err != nil {
+			continue
+		}
+
+		for _, path := range user.Groups {
+			action, err := importGroup(Group{Path: path, Members: []string{user.Username}})
+			report.add(KindMember, fmt.Sprintf("%s/%s", path, user.Username), action, err)
+		}
+
+		for workspace, role := range user.Workspaces {
+			err := workspaces.InviteUser(workspace, &models.User{Username: user.Username, WorkspaceRole: role})
+			report.add(KindWorkspace, fmt.Sprintf("%s/%s", workspace, user.Username), ActionUpdated, err)
+		}
+	}
+
+	return report
+}
+
+func importGroup(group Group) (string, error) {
+	if group.Path == "" {
+		return "", errors.New("path is required")
+	}
+
+	existing, err := iam.DescribeGroup(group.Path)
+
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+		paths := strings.Split(group.Path, ":")
+		_, err = iam.CreateGroup(&models.Group{Path: group.Path, Name: paths[len(paths)-1], Description: group.Description, Members: group.Members})
+		return ActionCreated, err
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	changed := false
+
+	for _, member := range group.Members {
+		if !sliceutil.HasString(existing.Members, member) {
+			existing.Members = append(existing.Members, member)
+			changed = true
+		}
+	}
+
+	if group.Description != "" && group.Description != existing.Description {
+		existing.Description = group.Description
+		changed = true
+	}
+
+	if !changed {
+		return ActionUnchanged, nil
+	}
+
+	_, err = iam.UpdateGroup(existing)
+
+	return ActionUpdated, err
+}
+
+func importUser(user User) (string, error) {
+	if user.Username == "" {
+		return "", errors.New("username is required")
+	}
+
+	if err := iam.ValidateUsername(user.Username); err != nil {
+		return "", err
+	}
+
+	_, err := iam.GetUserInfo(user.Username)
+
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+		if user.Password == "" {
+			return "", errors.New("password is required for new users")
+		}
+		_, err = iam.CreateUser(&models.User{Username: user.Username, Email: user.Email, Password: user.Password,
+			Description: user.Description, Lang: user.Lang, ClusterRole: user.ClusterRole})
+		return ActionCreated, err
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	_, err = iam.UpdateUser(&models.User{Username: user.Username, Email: user.Email, Password: user.Password,
+		Description: user.Description, Lang: user.Lang, ClusterRole: user.ClusterRole})
+
+	return ActionUpdated, err
+}
@@ -0,0 +1,96 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package bulk
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadCSV(t *testing.T) {
+	data := `email, username, groups, workspaces, extra
+john@kubesphere.io, john, dev;dev:backend, demo=workspace-admin;test=workspace-viewer, ignored
+alice@kubesphere.io, alice, , ,
+`
+
+	doc, err := ReadCSV(strings.NewReader(data))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []User{
+		{Username: "john", Email: "john@kubesphere.io", Groups: []string{"dev", "dev:backend"},
+			Workspaces: map[string]string{"demo": "workspace-admin", "test": "workspace-viewer"}},
+		{Username: "alice", Email: "alice@kubesphere.io", Groups: []string{}},
+	}
+
+	if !reflect.DeepEqual(doc.Users, expected) {
+		t.Errorf("expect %+v, got %+v", expected, doc.Users)
+	}
+}
+
+func TestReadInvalidCSV(t *testing.T) {
+	tests := []string{
+		"",
+		"email\njohn@kubesphere.io\n",
+		"username,workspaces\njohn,demo\n",
+	}
+
+	for _, test := range tests {
+		if _, err := ReadCSV(strings.NewReader(test)); err == nil {
+			t.Errorf("%q: expect error", test)
+		}
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	doc := &Document{Users: []User{
+		{Username: "john", Email: "john@kubesphere.io", Password: "secret", Description: "a, b", ClusterRole: "cluster-admin",
+			Groups: []string{"dev", "ops"}, Workspaces: map[string]string{"test": "workspace-viewer", "demo": "workspace-admin"}},
+	}}
+
+	var buf bytes.Buffer
+
+	if err := WriteCSV(&buf, doc); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `username,email,password,description,lang,cluster_role,groups,workspaces
+john,john@kubesphere.io,,"a, b",,cluster-admin,dev;ops,demo=workspace-admin;test=workspace-viewer
+`
+
+	if buf.String() != expected {
+		t.Errorf("expect %s, got %s", expected, buf.String())
+	}
+
+	// exported csv can be imported again
+	imported, err := ReadCSV(&buf)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc.Users[0].Password = ""
+
+	if !reflect.DeepEqual(imported, doc) {
+		t.Errorf("expect %+v, got %+v", doc, imported)
+	}
+}
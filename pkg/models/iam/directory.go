@@ -0,0 +1,405 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package iam
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap"
+	"github.com/golang/glog"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"kubesphere.io/kubesphere/pkg/constants"
+	"kubesphere.io/kubesphere/pkg/informers"
+	"kubesphere.io/kubesphere/pkg/models"
+	ldapclient "kubesphere.io/kubesphere/pkg/simple/client/ldap"
+)
+
+func readOnlyError(operation string) error {
+	return ldap.NewError(ldap.LDAPResultUnwillingToPerform, fmt.Errorf("%s is not allowed, users and groups are managed by an external directory", operation))
+}
+
+// checkDirectory makes sure the search bases of an external directory are readable, nothing is created
+func checkDirectory(conn ldap.Client) error {
+	for _, base := range []string{ldapclient.UserSearchBase, ldapclient.GroupSearchBase} {
+		searchRequest := ldap.NewSearchRequest(
+			base,
+			ldap.ScopeBaseObject, ldap.NeverDerefAliases, 0, 0, false,
+			"(objectClass=*)",
+			[]string{"dn"},
+			nil,
+		)
+
+		if _, err := conn.Search(searchRequest); err != nil {
+			return fmt.Errorf("search base %s is not available: %s", base, err)
+		}
+	}
+
+	return nil
+}
+
+// bootstrapAdmin binds the user of the read only directory to cluster-admin unless the user already has a cluster role,
+// cluster role bindings are managed by KubeSphere after that
+func bootstrapAdmin(conn ldap.Client, username string) error {
+	if username == "" {
+		glog.Warningln("no administrator is bootstrapped for the read only directory, bind a user to cluster-admin manually or set --ldap-admin-user")
+		return nil
+	}
+
+	searchRequest := ldap.NewSearchRequest(
+		ldapclient.UserSearchBase,
+		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+		userFilter(fmt.Sprintf("(%s=%s)", ldapclient.UserNameAttribute, ldap.EscapeFilter(username))),
+		[]string{ldapclient.UserNameAttribute},
+		nil,
+	)
+
+	result, err := conn.Search(searchRequest)
+
+	if err != nil {
+		return err
+	}
+
+	if len(result.Entries) != 1 {
+		return fmt.Errorf("administrator %s is not found in the directory", username)
+	}
+
+	clusterRoleBindingLister := informers.SharedInformerFactory().Rbac().V1().ClusterRoleBindings().Lister()
+
+	if _, err := clusterRoleBindingLister.Get(username); err == nil || !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	return CreateClusterRoleBinding(username, constants.ClusterAdmin)
+}
+
+// userFilter combines the configured user search filter with extra conditions
+func userFilter(conditions ...string) string {
+	return fmt.Sprintf("(&%s%s)", ldapclient.UserSearchFilter, strings.Join(conditions, ""))
+}
+
+// groupFilter combines the configured group search filter with extra conditions
+func groupFilter(conditions ...string) string {
+	return fmt.Sprintf("(&%s%s)", ldapclient.GroupSearchFilter, strings.Join(conditions, ""))
+}
+
+func userAttributes() []string {
+	return []string{ldapclient.UserNameAttribute, ldapclient.MailAttribute, ldapclient.DescriptionAttribute, "preferredLanguage", "createTimestamp", "whenCreated"}
+}
+
+func groupAttributes() []string {
+	return []string{"cn", "gidNumber", ldapclient.GroupMemberAttribute, "description"}
+}
+
+// userFromEntry maps attributes of the directory to user, the language is kept in the side store of a read only directory
+func userFromEntry(entry *ldap.Entry) models.User {
+	user := models.User{
+		Username:    entry.GetAttributeValue(ldapclient.UserNameAttribute),
+		Email:       entry.GetAttributeValue(ldapclient.MailAttribute),
+		Description: entry.GetAttributeValue(ldapclient.DescriptionAttribute),
+		CreateTime:  createTime(entry),
+	}
+
+	if !ldapclient.ReadOnly {
+		user.Lang = entry.GetAttributeValue("preferredLanguage")
+	}
+
+	return user
+}
+
+// createTime reads createTimestamp of OpenLDAP or whenCreated of Active Directory, such as 20190101000000.0Z
+func createTime(entry *ldap.Entry) time.Time {
+	if createTimestamp, err := time.Parse("20060102150405Z", entry.GetAttributeValue("createTimestamp")); err == nil {
+		return createTimestamp
+	}
+	whenCreated, _ := time.Parse("20060102150405.0Z", entry.GetAttributeValue("whenCreated"))
+	return whenCreated
+}
+
+// userGroups returns DNs of groups the user belongs to, parent groups are included if nested groups are enabled
+func userGroups(conn ldap.Client, dn, username string) ([]string, error) {
+	queue, err := directGroups(conn, dn, username)
+
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]string, 0)
+	visited := make(map[string]bool)
+
+	for len(queue) > 0 {
+		group := queue[0]
+		queue = queue[1:]
+
+		if visited[strings.ToLower(group)] {
+			continue
+		}
+
+		visited[strings.ToLower(group)] = true
+		groups = append(groups, group)
+
+		if ldapclient.NestedGroups {
+			parents, err := directGroups(conn, group, "")
+			if err != nil {
+				return nil, err
+			}
+			queue = append(queue, parents...)
+		}
+	}
+
+	return groups, nil
+}
+
+// directGroups returns DNs of groups which the entry is a direct member of,
+// members are identified by DN or by user name like memberUid of posixGroup
+func directGroups(conn ldap.Client, dn, name string) ([]string, error) {
+	if ldapclient.MemberOfAttribute != "" {
+		searchRequest := ldap.NewSearchRequest(
+			dn,
+			ldap.ScopeBaseObject, ldap.NeverDerefAliases, 0, 0, false,
+			"(objectClass=*)",
+			[]string{ldapclient.MemberOfAttribute},
+			nil,
+		)
+
+		result, err := conn.Search(searchRequest)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if len(result.Entries) != 1 {
+			return nil, nil
+		}
+
+		return result.Entries[0].GetAttributeValues(ldapclient.MemberOfAttribute), nil
+	}
+
+	memberFilter := fmt.Sprintf("(%s=%s)", ldapclient.GroupMemberAttribute, ldap.EscapeFilter(dn))
+
+	if name != "" {
+		memberFilter = fmt.Sprintf("(|%s(%s=%s))", memberFilter, ldapclient.GroupMemberAttribute, ldap.EscapeFilter(name))
+	}
+
+	searchRequest := ldap.NewSearchRequest(
+		ldapclient.GroupSearchBase,
+		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+		groupFilter(memberFilter),
+		[]string{"cn"},
+		nil,
+	)
+
+	result, err := conn.Search(searchRequest)
+
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]string, 0)
+
+	for _, entry := range result.Entries {
+		groups = append(groups, entry.DN)
+	}
+
+	return groups, nil
+}
+
+// groupPath converts DN of group to path, groups out of the group search base are ignored
+func groupPath(dn string) (string, bool) {
+	if !strings.HasSuffix(strings.ToLower(dn), strings.ToLower(ldapclient.GroupSearchBase)) {
+		return "", false
+	}
+
+	if path := convertDNToPath(dn); path != "" {
+		return path, true
+	}
+
+	// names of groups in Active Directory are not restricted, such as "Domain Users"
+	parsed, err := ldap.ParseDN(dn)
+
+	if err != nil || len(parsed.RDNs) == 0 || len(parsed.RDNs[0].Attributes) == 0 {
+		return "", false
+	}
+
+	return parsed.RDNs[0].Attributes[0].Value, true
+}
+
+// memberSearchBatch limits the number of DNs resolved by a single search
+const memberSearchBatch = 100
+
+// groupMembers returns user names of the group, members of nested groups are included if nested groups are enabled.
+// Member DNs are resolved by a search per batch of DNs and per nesting level instead of a search per member.
+func groupMembers(conn ldap.Client, entry *ldap.Entry) ([]string, error) {
+	members := make([]string, 0)
+	visited := map[string]bool{normalizeDN(entry.DN): true}
+	values := entry.GetAttributeValues(ldapclient.GroupMemberAttribute)
+
+	for len(values) > 0 {
+		dns := make([]string, 0)
+
+		for _, value := range values {
+			// memberUid holds user names
+			if !strings.Contains(value, "=") {
+				members = appendMember(members, value)
+				continue
+			}
+
+			if !visited[normalizeDN(value)] {
+				visited[normalizeDN(value)] = true
+				dns = append(dns, value)
+			}
+		}
+
+		users, err := searchEntries(conn, ldapclient.UserSearchBase, userFilter, dns, ldapclient.UserNameAttribute)
+
+		if err != nil {
+			return nil, err
+		}
+
+		groups := make([]string, 0)
+
+		for _, dn := range dns {
+			if user, ok := users[normalizeDN(dn)]; ok {
+				members = appendMember(members, user.GetAttributeValue(ldapclient.UserNameAttribute))
+			} else {
+				groups = append(groups, dn)
+			}
+		}
+
+		values = nil
+
+		if !ldapclient.NestedGroups || len(groups) == 0 {
+			break
+		}
+
+		nested, err := searchEntries(conn, ldapclient.GroupSearchBase, groupFilter, groups, ldapclient.GroupMemberAttribute)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, dn := range groups {
+			if group, ok := nested[normalizeDN(dn)]; ok {
+				values = append(values, group.GetAttributeValues(ldapclient.GroupMemberAttribute)...)
+			}
+		}
+	}
+
+	return members, nil
+}
+
+// searchEntries returns entries of the DNs under the search base which match the filter, keyed by normalized DN.
+// Entries are searched by the first RDN of the DNs and matched by the DN, DNs out of the search base are ignored.
+func searchEntries(conn ldap.Client, base string, filter func(conditions ...string) string, dns []string, attributes ...string) (map[string]*ldap.Entry, error) {
+	entries := make(map[string]*ldap.Entry)
+	conditions := make([]string, 0)
+
+	for _, dn := range dns {
+		if !strings.HasSuffix(normalizeDN(dn), ","+normalizeDN(base)) {
+			continue
+		}
+		if condition, ok := rdnFilter(dn); ok {
+			conditions = append(conditions, condition)
+		}
+	}
+
+	for start := 0; start < len(conditions); start += memberSearchBatch {
+		end := start + memberSearchBatch
+
+		if end > len(conditions) {
+			end = len(conditions)
+		}
+
+		searchRequest := ldap.NewSearchRequest(
+			base,
+			ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+			filter(fmt.Sprintf("(|%s)", strings.Join(conditions[start:end], ""))),
+			attributes,
+			nil,
+		)
+
+		result, err := conn.Search(searchRequest)
+
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+			return entries, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range result.Entries {
+			entries[normalizeDN(entry.DN)] = entry
+		}
+	}
+
+	return entries, nil
+}
+
+// rdnFilter matches entries by the first RDN of the DN, such as (uid=admin) of uid=admin,ou=Users,dc=example,dc=org
+func rdnFilter(dn string) (string, bool) {
+	parsed, err := ldap.ParseDN(dn)
+
+	if err != nil || len(parsed.RDNs) == 0 || len(parsed.RDNs[0].Attributes) == 0 {
+		return "", false
+	}
+
+	conditions := ""
+
+	for _, attribute := range parsed.RDNs[0].Attributes {
+		conditions += fmt.Sprintf("(%s=%s)", attribute.Type, ldap.EscapeFilter(attribute.Value))
+	}
+
+	if len(parsed.RDNs[0].Attributes) > 1 {
+		return fmt.Sprintf("(&%s)", conditions), true
+	}
+
+	return conditions, true
+}
+
+// normalizeDN makes DNs comparable, attribute types and values are case insensitive and spaces around separators are ignored
+func normalizeDN(dn string) string {
+	parsed, err := ldap.ParseDN(dn)
+
+	if err != nil {
+		return strings.ToLower(dn)
+	}
+
+	rdns := make([]string, 0, len(parsed.RDNs))
+
+	for _, rdn := range parsed.RDNs {
+		attributes := make([]string, 0, len(rdn.Attributes))
+		for _, attribute := range rdn.Attributes {
+			attributes = append(attributes, fmt.Sprintf("%s=%s", strings.ToLower(attribute.Type), strings.ToLower(attribute.Value)))
+		}
+		rdns = append(rdns, strings.Join(attributes, "+"))
+	}
+
+	return strings.Join(rdns, ",")
+}
+
+func appendMember(members []string, name string) []string {
+	for _, member := range members {
+		if member == name {
+			return members
+		}
+	}
+	return append(members, name)
+}
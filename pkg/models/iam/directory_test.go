@@ -0,0 +1,290 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package iam
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/go-ldap/ldap"
+
+	"kubesphere.io/kubesphere/pkg/models"
+	ldapclient "kubesphere.io/kubesphere/pkg/simple/client/ldap"
+	"kubesphere.io/kubesphere/pkg/simple/client/ldap/fake"
+)
+
+const (
+	usersBase  = "ou=Users,dc=example,dc=org"
+	groupsBase = "ou=Groups,dc=example,dc=org"
+)
+
+// activeDirectory configures the directory like Active Directory, members are DNs and users are named by sAMAccountName
+func activeDirectory(t *testing.T, nested bool) {
+	readOnly, userBase, groupBase := ldapclient.ReadOnly, ldapclient.UserSearchBase, ldapclient.GroupSearchBase
+	userSearchFilter, groupSearchFilter := ldapclient.UserSearchFilter, ldapclient.GroupSearchFilter
+	userNameAttribute, memberOfAttribute, groupMemberAttribute := ldapclient.UserNameAttribute, ldapclient.MemberOfAttribute, ldapclient.GroupMemberAttribute
+	nestedGroups := ldapclient.NestedGroups
+
+	ldapclient.ReadOnly = true
+	ldapclient.UserSearchBase = usersBase
+	ldapclient.GroupSearchBase = groupsBase
+	ldapclient.UserSearchFilter = "(&(objectCategory=person)(objectClass=user))"
+	ldapclient.GroupSearchFilter = "(objectClass=group)"
+	ldapclient.UserNameAttribute = "sAMAccountName"
+	ldapclient.MemberOfAttribute = ""
+	ldapclient.GroupMemberAttribute = "member"
+	ldapclient.NestedGroups = nested
+
+	t.Cleanup(func() {
+		ldapclient.ReadOnly, ldapclient.UserSearchBase, ldapclient.GroupSearchBase = readOnly, userBase, groupBase
+		ldapclient.UserSearchFilter, ldapclient.GroupSearchFilter = userSearchFilter, groupSearchFilter
+		ldapclient.UserNameAttribute, ldapclient.MemberOfAttribute, ldapclient.GroupMemberAttribute = userNameAttribute, memberOfAttribute, groupMemberAttribute
+		ldapclient.NestedGroups = nestedGroups
+	})
+}
+
+func userEntry(name string) (string, map[string][]string) {
+	return fmt.Sprintf("CN=%s,%s", name, usersBase), map[string][]string{
+		"objectClass":    {"top", "person", "user"},
+		"objectCategory": {"person"},
+		"cn":             {name},
+		"sAMAccountName": {name},
+	}
+}
+
+// newDirectory starts a fake server with 150 users, developers has all of them and the ops group as members,
+// ops has alice and developers as members, the contact and the group out of the search base are not users
+func newDirectory(t *testing.T) (*fake.Server, ldap.Client) {
+	server, err := fake.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Stop)
+
+	server.Add(usersBase, map[string][]string{"objectClass": {"organizationalUnit"}})
+	server.Add(groupsBase, map[string][]string{"objectClass": {"organizationalUnit"}})
+
+	developers := make([]string, 0)
+
+	for i := 0; i < 150; i++ {
+		dn, attributes := userEntry(fmt.Sprintf("user%03d", i))
+		server.Add(dn, attributes)
+		developers = append(developers, dn)
+	}
+
+	alice, attributes := userEntry("alice")
+	server.Add(alice, attributes)
+
+	contact := "CN=Printer,ou=Users,dc=example,dc=org"
+	server.Add(contact, map[string][]string{"objectClass": {"top", "contact"}, "cn": {"Printer"}, "sAMAccountName": {"printer"}})
+
+	developers = append(developers, contact, "CN=ops, ou=Groups,dc=example,dc=org", "CN=partners,ou=Groups,dc=other,dc=org")
+
+	server.Add("CN=developers,"+groupsBase, map[string][]string{"objectClass": {"top", "group"}, "cn": {"developers"}, "member": developers})
+	server.Add("CN=ops,"+groupsBase, map[string][]string{"objectClass": {"top", "group"}, "cn": {"ops"}, "member": {alice, "cn=developers,ou=groups,dc=example,dc=org"}})
+
+	conn, err := ldap.Dial("tcp", server.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(conn.Close)
+
+	return server, conn
+}
+
+func searchGroup(t *testing.T, conn ldap.Client, name string) *ldap.Entry {
+	result, err := conn.Search(ldap.NewSearchRequest(groupsBase, ldap.ScopeSingleLevel, ldap.NeverDerefAliases, 0, 0, false,
+		groupFilter(fmt.Sprintf("(cn=%s)", name)), groupAttributes(), nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Entries) != 1 {
+		t.Fatalf("expected group %s, got %d entries", name, len(result.Entries))
+	}
+	return result.Entries[0]
+}
+
+func TestGroupMembers(t *testing.T) {
+	tests := []struct {
+		nested   bool
+		group    string
+		members  int
+		searches int
+		alice    bool
+	}{
+		// users are resolved in batches of 100, the nested group in a single search
+		{nested: true, group: "developers", members: 151, searches: 4, alice: true},
+		{nested: false, group: "developers", members: 150, searches: 2, alice: false},
+		// members of developers are resolved once although developers is a member of ops
+		{nested: true, group: "ops", members: 151, searches: 4, alice: true},
+	}
+
+	for i, test := range tests {
+		activeDirectory(t, test.nested)
+		server, conn := newDirectory(t)
+
+		entry := searchGroup(t, conn, test.group)
+		server.ResetSearches()
+
+		members, err := groupMembers(conn, entry)
+
+		if err != nil {
+			t.Errorf("case %d: %v", i, err)
+			continue
+		}
+
+		if len(members) != test.members {
+			t.Errorf("case %d: expected %d members, got %d", i, test.members, len(members))
+		}
+
+		searches := server.Searches()
+
+		if len(searches) != test.searches {
+			t.Errorf("case %d: expected %d searches, got %d", i, test.searches, len(searches))
+		}
+
+		hasAlice := false
+		for _, member := range members {
+			if member == "alice" {
+				hasAlice = true
+			}
+			if member == "printer" {
+				t.Errorf("case %d: expected the contact not to be a member", i)
+			}
+		}
+
+		if hasAlice != test.alice {
+			t.Errorf("case %d: expected alice to be a member %t", i, test.alice)
+		}
+	}
+}
+
+func TestGroupMembersFilter(t *testing.T) {
+	activeDirectory(t, true)
+	server, conn := newDirectory(t)
+
+	entry := searchGroup(t, conn, "ops")
+	server.ResetSearches()
+
+	if _, err := groupMembers(conn, entry); err != nil {
+		t.Fatal(err)
+	}
+
+	searches := server.Searches()
+
+	if len(searches) == 0 {
+		t.Fatal("expected searches")
+	}
+
+	expected := []struct {
+		base   string
+		filter string
+	}{
+		{usersBase, "(&(&(objectCategory=person)(objectClass=user))(|(CN=alice)))"},
+		{groupsBase, "(&(objectClass=group)(|(cn=developers)))"},
+	}
+
+	for i, test := range expected {
+		if searches[i].BaseDN != test.base || searches[i].Filter != test.filter {
+			t.Errorf("case %d: expected search of %s under %s, got %s under %s", i, test.filter, test.base, searches[i].Filter, searches[i].BaseDN)
+		}
+	}
+}
+
+func TestUserGroups(t *testing.T) {
+	tests := []struct {
+		nested   bool
+		expected []string
+	}{
+		{false, []string{"CN=ops,ou=Groups,dc=example,dc=org"}},
+		// developers is a member of ops and ops is a member of developers
+		{true, []string{"CN=developers,ou=Groups,dc=example,dc=org", "CN=ops,ou=Groups,dc=example,dc=org"}},
+	}
+
+	for i, test := range tests {
+		activeDirectory(t, test.nested)
+		_, conn := newDirectory(t)
+
+		dn, _ := userEntry("alice")
+		groups, err := userGroups(conn, dn, "alice")
+
+		if err != nil {
+			t.Errorf("case %d: %v", i, err)
+			continue
+		}
+
+		sort.Strings(groups)
+
+		if !reflect.DeepEqual(groups, test.expected) {
+			t.Errorf("case %d: expected %v, got %v", i, test.expected, groups)
+		}
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	activeDirectory(t, false)
+	server, conn := newDirectory(t)
+
+	if err := checkDirectory(conn); err != nil {
+		t.Errorf("expected search bases to be available, got %v", err)
+	}
+
+	ldapclient.GroupSearchBase = "ou=Missing,dc=example,dc=org"
+
+	if err := checkDirectory(conn); err == nil {
+		t.Errorf("expected missing search base to be reported")
+	}
+
+	writes := []error{
+		func() error { _, err := CreateUser(&models.User{Username: "bob"}); return err }(),
+		DeleteUser("alice"),
+		func() error { _, err := CreateGroup(&models.Group{Name: "qa"}); return err }(),
+		func() error { _, err := UpdateGroup(&models.Group{Path: "ops"}); return err }(),
+		DeleteGroup("ops"),
+	}
+
+	for i, err := range writes {
+		if !ldap.IsErrorWithCode(err, ldap.LDAPResultUnwillingToPerform) {
+			t.Errorf("case %d: expected the directory not to be written, got %v", i, err)
+		}
+	}
+
+	// nothing is created in the directory, not even the administrator if it isn't configured
+	server.ResetSearches()
+
+	if err := bootstrapAdmin(conn, ""); err != nil {
+		t.Errorf("expected no administrator to be bootstrapped, got %v", err)
+	}
+
+	if len(server.Searches()) != 0 {
+		t.Errorf("expected no searches, got %d", len(server.Searches()))
+	}
+
+	if err := bootstrapAdmin(conn, "nobody"); err == nil {
+		t.Errorf("expected unknown administrator to be reported")
+	}
+
+	// the language is kept in the side store of a read only directory
+	entry := ldap.NewEntry("CN=alice,"+usersBase, map[string][]string{"sAMAccountName": {"alice"}, "preferredLanguage": {"zh"}})
+
+	if user := userFromEntry(entry); user.Username != "alice" || user.Lang != "" {
+		t.Errorf("unexpected user %+v", user)
+	}
+}
@@ -49,6 +49,7 @@ import (
 	"k8s.io/api/rbac/v1"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apiserver/pkg/authentication/serviceaccount"
 	ldapclient "kubesphere.io/kubesphere/pkg/simple/client/ldap"
 
 	"kubesphere.io/kubesphere/pkg/models"
@@ -89,6 +90,15 @@ func Init(email, password string, expireTime time.Duration, authRateLimit string
 
 	defer conn.Close()
 
+	// users and groups of an external directory are not managed by KubeSphere,
+	// default users are not created and only the configured administrator is bootstrapped
+	if ldapclient.ReadOnly {
+		if err := checkDirectory(conn); err != nil {
+			return err
+		}
+		return bootstrapAdmin(conn, ldapclient.AdminUser)
+	}
+
 	err = checkAndCreateDefaultUser(conn)
 
 	if err != nil {
@@ -250,8 +260,8 @@ func Login(username string, password string, ip string) (*models.Token, error) {
 	userSearchRequest := ldap.NewSearchRequest(
 		ldapclient.UserSearchBase,
 		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
-		fmt.Sprintf("(&(objectClass=inetOrgPerson)(|(uid=%s)(mail=%s)))", username, username),
-		[]string{"uid", "mail"},
+		userFilter(fmt.Sprintf("(|(%s=%s)(%s=%s))", ldapclient.UserNameAttribute, ldap.EscapeFilter(username), ldapclient.MailAttribute, ldap.EscapeFilter(username))),
+		[]string{ldapclient.UserNameAttribute, ldapclient.MailAttribute},
 		nil,
 	)
 
@@ -265,8 +275,8 @@ func Login(username string, password string, ip string) (*models.Token, error) {
 		return nil, ldap.NewError(ldap.LDAPResultInvalidCredentials, errors.New("incorrect password"))
 	}
 
-	uid := result.Entries[0].GetAttributeValue("uid")
-	email := result.Entries[0].GetAttributeValue("mail")
+	uid := result.Entries[0].GetAttributeValue(ldapclient.UserNameAttribute)
+	email := result.Entries[0].GetAttributeValue(ldapclient.MailAttribute)
 	dn := result.Entries[0].DN
 
 	// bind as the user to verify their password
@@ -332,26 +342,27 @@ func ListUsers(conditions *params.Conditions, orderBy string, reverse bool, limi
 
 	users := make([]models.User, 0)
 
-	filter := "(&(objectClass=inetOrgPerson))"
+	filter := userFilter()
 
 	if keyword := conditions.Match["keyword"]; keyword != "" {
-		filter = fmt.Sprintf("(&(objectClass=inetOrgPerson)(|(uid=*%s*)(mail=*%s*)(description=*%s*)))", keyword, keyword, keyword)
+		keyword = ldap.EscapeFilter(keyword)
+		filter = userFilter(fmt.Sprintf("(|(%s=*%s*)(%s=*%s*)(%s=*%s*))", ldapclient.UserNameAttribute, keyword, ldapclient.MailAttribute, keyword, ldapclient.DescriptionAttribute, keyword))
 	}
 
 	if username := conditions.Match["username"]; username != "" {
 		uidFilter := ""
 		for _, username := range strings.Split(username, "|") {
-			uidFilter += fmt.Sprintf("(uid=%s)", username)
+			uidFilter += fmt.Sprintf("(%s=%s)", ldapclient.UserNameAttribute, ldap.EscapeFilter(username))
 		}
-		filter = fmt.Sprintf("(&(objectClass=inetOrgPerson)(|%s))", uidFilter)
+		filter = userFilter(fmt.Sprintf("(|%s)", uidFilter))
 	}
 
 	if email := conditions.Match["email"]; email != "" {
 		emailFilter := ""
 		for _, username := range strings.Split(email, "|") {
-			emailFilter += fmt.Sprintf("(mail=%s)", username)
+			emailFilter += fmt.Sprintf("(%s=%s)", ldapclient.MailAttribute, ldap.EscapeFilter(username))
 		}
-		filter = fmt.Sprintf("(&(objectClass=inetOrgPerson)(|%s))", emailFilter)
+		filter = userFilter(fmt.Sprintf("(|%s)", emailFilter))
 	}
 
 	for {
@@ -359,7 +370,7 @@ func ListUsers(conditions *params.Conditions, orderBy string, reverse bool, limi
 			ldapclient.UserSearchBase,
 			ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
 			filter,
-			[]string{"uid", "mail", "description", "preferredLanguage", "createTimestamp"},
+			userAttributes(),
 			[]ldap.Control{pageControl},
 		)
 
@@ -372,13 +383,7 @@ func ListUsers(conditions *params.Conditions, orderBy string, reverse bool, limi
 
 		for _, entry := range response.Entries {
 
-			uid := entry.GetAttributeValue("uid")
-			email := entry.GetAttributeValue("mail")
-			description := entry.GetAttributeValue("description")
-			lang := entry.GetAttributeValue("preferredLanguage")
-			createTimestamp, _ := time.Parse("20060102150405Z", entry.GetAttributeValue("createTimestamp"))
-
-			user := models.User{Username: uid, Email: email, Description: description, Lang: lang, CreateTime: createTimestamp}
+			user := userFromEntry(entry)
 
 			if !shouldHidden(user) {
 				users = append(users, user)
@@ -417,8 +422,11 @@ func ListUsers(conditions *params.Conditions, orderBy string, reverse bool, limi
 		if i >= offset && len(items) < limit {
 
 			user.AvatarUrl = getAvatar(user.Username)
+			if ldapclient.ReadOnly {
+				user.Lang = getLang(user.Username)
+			}
 			user.LastLoginTime = getLastLoginTime(user.Username)
-			clusterRole, err := GetUserClusterRole(user.Username)
+			clusterRole, err := GetUserClusterRole(k8sutil.NewIdentity(user.Username, v1.UserKind))
 			if err != nil {
 				return nil, err
 			}
@@ -467,11 +475,13 @@ func GetUserInfo(username string) (*models.User, error) {
 		return nil, err
 	}
 
+	defer conn.Close()
+
 	userSearchRequest := ldap.NewSearchRequest(
 		ldapclient.UserSearchBase,
 		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
-		fmt.Sprintf("(&(objectClass=inetOrgPerson)(uid=%s))", username),
-		[]string{"mail", "description", "preferredLanguage", "createTimestamp"},
+		userFilter(fmt.Sprintf("(%s=%s)", ldapclient.UserNameAttribute, ldap.EscapeFilter(username))),
+		userAttributes(),
 		nil,
 	)
 
@@ -486,15 +496,15 @@ func GetUserInfo(username string) (*models.User, error) {
 		return nil, ldap.NewError(ldap.LDAPResultNoSuchObject, fmt.Errorf("user %s does not exist", username))
 	}
 
-	email := result.Entries[0].GetAttributeValue("mail")
-	description := result.Entries[0].GetAttributeValue("description")
-	lang := result.Entries[0].GetAttributeValue("preferredLanguage")
-	createTimestamp, _ := time.Parse("20060102150405Z", result.Entries[0].GetAttributeValue("createTimestamp"))
-	user := &models.User{Username: username, Email: email, Description: description, Lang: lang, CreateTime: createTimestamp}
+	user := userFromEntry(result.Entries[0])
+
+	if ldapclient.ReadOnly {
+		user.Lang = getLang(username)
+	}
 
 	user.LastLoginTime = getLastLoginTime(username)
 
-	return user, nil
+	return &user, nil
 }
 
 func GetUserGroups(username string) ([]string, error) {
@@ -506,15 +516,25 @@ func GetUserGroups(username string) ([]string, error) {
 
 	defer conn.Close()
 
-	groupSearchRequest := ldap.NewSearchRequest(
-		ldapclient.GroupSearchBase,
-		ldap.ScopeBaseObject, ldap.NeverDerefAliases, 0, 0, false,
-		fmt.Sprintf("(&(objectClass=posixGroup)(memberUid=%s))", username),
-		nil,
+	userSearchRequest := ldap.NewSearchRequest(
+		ldapclient.UserSearchBase,
+		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+		userFilter(fmt.Sprintf("(%s=%s)", ldapclient.UserNameAttribute, ldap.EscapeFilter(username))),
+		[]string{ldapclient.UserNameAttribute},
 		nil,
 	)
 
-	result, err := conn.Search(groupSearchRequest)
+	result, err := conn.Search(userSearchRequest)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result.Entries) != 1 {
+		return nil, ldap.NewError(ldap.LDAPResultNoSuchObject, fmt.Errorf("user %s does not exist", username))
+	}
+
+	dns, err := userGroups(conn, result.Entries[0].DN, username)
 
 	if err != nil {
 		return nil, err
@@ -522,9 +542,10 @@ func GetUserGroups(username string) ([]string, error) {
 
 	groups := make([]string, 0)
 
-	for _, group := range result.Entries {
-		groupName := convertDNToPath(group.DN)
-		groups = append(groups, groupName)
+	for _, dn := range dns {
+		if path, ok := groupPath(dn); ok {
+			groups = append(groups, path)
+		}
 	}
 
 	return groups, nil
@@ -545,10 +566,26 @@ func getLastLoginTime(username string) string {
 }
 
 func setAvatar(username, avatar string) error {
-	_, err := redis.Client().HMSet("kubesphere:users:avatar", map[string]interface{}{"username": avatar}).Result()
+	_, err := redis.Client().HSet("kubesphere:users:avatar", username, avatar).Result()
+	return err
+}
+
+// setLang keeps the language of users in a read only directory
+func setLang(username, lang string) error {
+	_, err := redis.Client().HSet("kubesphere:users:lang", username, lang).Result()
 	return err
 }
 
+func getLang(username string) string {
+	lang, err := redis.Client().HGet("kubesphere:users:lang", username).Result()
+
+	if err != nil {
+		return ""
+	}
+
+	return lang
+}
+
 func getAvatar(username string) string {
 
 	avatar, err := redis.Client().HMGet("kubesphere:users:avatar", username).Result()
@@ -568,6 +605,10 @@ func getAvatar(username string) string {
 
 func DeleteUser(username string) error {
 
+	if ldapclient.ReadOnly {
+		return readOnlyError("deleting users")
+	}
+
 	conn, err := ldapclient.Client()
 
 	if err != nil {
@@ -712,8 +753,8 @@ func UserCreateCheck(check string) (exist bool, err error) {
 	userSearchRequest := ldap.NewSearchRequest(
 		ldapclient.UserSearchBase,
 		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
-		fmt.Sprintf("(&(objectClass=inetOrgPerson)(|(uid=%s)(mail=%s)))", check, check),
-		[]string{"uid", "mail"},
+		userFilter(fmt.Sprintf("(|(%s=%s)(%s=%s))", ldapclient.UserNameAttribute, ldap.EscapeFilter(check), ldapclient.MailAttribute, ldap.EscapeFilter(check))),
+		[]string{ldapclient.UserNameAttribute, ldapclient.MailAttribute},
 		nil,
 	)
 
@@ -727,7 +768,27 @@ func UserCreateCheck(check string) (exist bool, err error) {
 	return len(result.Entries) > 0, nil
 }
 
+// reservedUsernames are paths of bulk operations under /users, such users couldn't be retrieved
+var reservedUsernames = []string{"export", "import"}
+
+// ValidateUsername rejects names of kubernetes service accounts, such users would be
+// mistaken for the service account by components authenticating service account tokens
+func ValidateUsername(username string) error {
+	username = strings.TrimSpace(username)
+	if strings.HasPrefix(username, serviceaccount.ServiceAccountUsernamePrefix) {
+		return fmt.Errorf("invalid username: %s, the prefix %s is reserved for service accounts", username, serviceaccount.ServiceAccountUsernamePrefix)
+	}
+	if sliceutil.HasString(reservedUsernames, username) {
+		return fmt.Errorf("invalid username: %s is reserved", username)
+	}
+	return nil
+}
+
 func CreateUser(user *models.User) (*models.User, error) {
+	if ldapclient.ReadOnly {
+		return nil, readOnlyError("creating users")
+	}
+
 	user.Username = strings.TrimSpace(user.Username)
 	user.Email = strings.TrimSpace(user.Email)
 	user.Password = strings.TrimSpace(user.Password)
@@ -875,6 +936,10 @@ func getMaxGid(conn ldap.Client) (int, error) {
 
 func UpdateUser(user *models.User) (*models.User, error) {
 
+	if ldapclient.ReadOnly {
+		return updateSideAttributes(user)
+	}
+
 	conn, err := ldapclient.Client()
 
 	if err != nil {
@@ -939,11 +1004,14 @@ func UpdateUser(user *models.User) (*models.User, error) {
 		return nil, err
 	}
 
-	err = CreateClusterRoleBinding(user.Username, user.ClusterRole)
+	// the cluster role is kept if not specified
+	if user.ClusterRole != "" {
+		err = CreateClusterRoleBinding(user.Username, user.ClusterRole)
 
-	if err != nil {
-		glog.Errorln("create cluster role binding filed", err)
-		return nil, err
+		if err != nil {
+			glog.Errorln("create cluster role binding filed", err)
+			return nil, err
+		}
 	}
 
 	// clear auth failed record
@@ -959,8 +1027,53 @@ func UpdateUser(user *models.User) (*models.User, error) {
 
 	return GetUserInfo(user.Username)
 }
+
+// updateSideAttributes updates data kept by KubeSphere, attributes of a read only directory can't be changed
+func updateSideAttributes(user *models.User) (*models.User, error) {
+	current, err := GetUserInfo(user.Username)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Password != "" ||
+		(user.Email != "" && user.Email != current.Email) ||
+		(user.Description != "" && user.Description != current.Description) {
+		return nil, readOnlyError("changing password, email or description")
+	}
+
+	if user.Lang != "" {
+		if err := setLang(user.Username, user.Lang); err != nil {
+			glog.Error(err)
+			return nil, err
+		}
+	}
+
+	if user.AvatarUrl != "" {
+		if err := setAvatar(user.Username, user.AvatarUrl); err != nil {
+			glog.Error(err)
+			return nil, err
+		}
+	}
+
+	if user.ClusterRole != "" {
+		err = CreateClusterRoleBinding(user.Username, user.ClusterRole)
+
+		if err != nil {
+			glog.Errorln("create cluster role binding filed", err)
+			return nil, err
+		}
+	}
+
+	return GetUserInfo(user.Username)
+}
+
 func DeleteGroup(path string) error {
 
+	if ldapclient.ReadOnly {
+		return readOnlyError("deleting groups")
+	}
+
 	// bind root DN
 	conn, err := ldapclient.Client()
 	if err != nil {
@@ -983,6 +1096,10 @@ func DeleteGroup(path string) error {
 
 func CreateGroup(group *models.Group) (*models.Group, error) {
 
+	if ldapclient.ReadOnly {
+		return nil, readOnlyError("creating groups")
+	}
+
 	conn, err := ldapclient.Client()
 
 	if err != nil {
@@ -1033,6 +1150,10 @@ func CreateGroup(group *models.Group) (*models.Group, error) {
 
 func UpdateGroup(group *models.Group) (*models.Group, error) {
 
+	if ldapclient.ReadOnly {
+		return nil, readOnlyError("updating groups")
+	}
+
 	// bind root DN
 	conn, err := ldapclient.Client()
 	if err != nil {
@@ -1091,15 +1212,15 @@ func ChildList(path string) ([]models.Group, error) {
 	if path == "" {
 		groupSearchRequest = ldap.NewSearchRequest(ldapclient.GroupSearchBase,
 			ldap.ScopeSingleLevel, ldap.NeverDerefAliases, 0, 0, false,
-			"(&(objectClass=posixGroup))",
-			[]string{"cn", "gidNumber", "memberUid", "description"},
+			groupFilter(),
+			groupAttributes(),
 			nil)
 	} else {
 		searchBase, cn := splitPath(path)
 		groupSearchRequest = ldap.NewSearchRequest(fmt.Sprintf("cn=%s,%s", cn, searchBase),
 			ldap.ScopeSingleLevel, ldap.NeverDerefAliases, 0, 0, false,
-			"(&(objectClass=posixGroup))",
-			[]string{"cn", "gidNumber", "memberUid", "description"},
+			groupFilter(),
+			groupAttributes(),
 			nil)
 	}
 
@@ -1115,18 +1236,25 @@ func ChildList(path string) ([]models.Group, error) {
 		dn := v.DN
 		cn := v.GetAttributeValue("cn")
 		gid := v.GetAttributeValue("gidNumber")
-		members := v.GetAttributeValues("memberUid")
 		description := v.GetAttributeValue("description")
 
-		group := models.Group{Path: convertDNToPath(dn), Name: cn, Gid: gid, Members: members, Description: description}
+		members, err := groupMembers(conn, v)
+
+		if err != nil {
+			return nil, err
+		}
+
+		path, _ := groupPath(dn)
+
+		group := models.Group{Path: path, Name: cn, Gid: gid, Members: members, Description: description}
 
 		childSearchRequest := ldap.NewSearchRequest(dn,
 			ldap.ScopeSingleLevel, ldap.NeverDerefAliases, 0, 0, false,
-			"(&(objectClass=posixGroup))",
+			groupFilter(),
 			[]string{""},
 			nil)
 
-		result, err = conn.Search(childSearchRequest)
+		children, err := conn.Search(childSearchRequest)
 
 		if err != nil {
 			return nil, err
@@ -1134,9 +1262,10 @@ func ChildList(path string) ([]models.Group, error) {
 
 		childGroups := make([]string, 0)
 
-		for _, v := range result.Entries {
-			child := convertDNToPath(v.DN)
-			childGroups = append(childGroups, child)
+		for _, v := range children.Entries {
+			if child, ok := groupPath(v.DN); ok {
+				childGroups = append(childGroups, child)
+			}
 		}
 
 		group.ChildGroups = childGroups
@@ -1147,6 +1276,34 @@ func ChildList(path string) ([]models.Group, error) {
 	return groups, nil
 }
 
+// ListAllGroups returns all groups of the tree, parents are followed by their children
+func ListAllGroups() ([]models.Group, error) {
+	return listGroupTree("")
+}
+
+func listGroupTree(path string) ([]models.Group, error) {
+	groups, err := ChildList(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.Group, 0)
+
+	for _, group := range groups {
+		result = append(result, group)
+		if len(group.ChildGroups) > 0 {
+			children, err := listGroupTree(group.Path)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, children...)
+		}
+	}
+
+	return result, nil
+}
+
 func DescribeGroup(path string) (*models.Group, error) {
 
 	searchBase, cn := splitPath(path)
@@ -1157,10 +1314,12 @@ func DescribeGroup(path string) (*models.Group, error) {
 		return nil, err
 	}
 
+	defer conn.Close()
+
 	groupSearchRequest := ldap.NewSearchRequest(searchBase,
 		ldap.ScopeSingleLevel, ldap.NeverDerefAliases, 0, 0, false,
-		fmt.Sprintf("(&(objectClass=posixGroup)(cn=%s))", cn),
-		[]string{"cn", "gidNumber", "memberUid", "description"},
+		groupFilter(fmt.Sprintf("(cn=%s)", cn)),
+		groupAttributes(),
 		nil)
 
 	result, err := conn.Search(groupSearchRequest)
@@ -1177,10 +1336,17 @@ func DescribeGroup(path string) (*models.Group, error) {
 	dn := result.Entries[0].DN
 	cn = result.Entries[0].GetAttributeValue("cn")
 	gid := result.Entries[0].GetAttributeValue("gidNumber")
-	members := result.Entries[0].GetAttributeValues("memberUid")
 	description := result.Entries[0].GetAttributeValue("description")
 
-	group := models.Group{Path: convertDNToPath(dn), Name: cn, Gid: gid, Members: members, Description: description}
+	members, err := groupMembers(conn, result.Entries[0])
+
+	if err != nil {
+		return nil, err
+	}
+
+	path, _ = groupPath(dn)
+
+	group := models.Group{Path: path, Name: cn, Gid: gid, Members: members, Description: description}
 
 	childGroups := make([]string, 0)
 
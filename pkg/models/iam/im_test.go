@@ -0,0 +1,41 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package iam
+
+import "testing"
+
+func TestValidateUsername(t *testing.T) {
+	tests := []struct {
+		username string
+		valid    bool
+	}{
+		{"admin", true},
+		{"exporter", true},
+		{"system:serviceaccount:demo:builder", false},
+		{" system:serviceaccount:demo:builder", false},
+		// paths of bulk operations under /users
+		{"export", false},
+		{"import", false},
+	}
+
+	for i, test := range tests {
+		if err := ValidateUsername(test.username); (err == nil) != test.valid {
+			t.Errorf("case %d: expected valid %t, got %v", i, test.valid, err)
+		}
+	}
+}
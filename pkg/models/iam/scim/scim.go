@@ -0,0 +1,635 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package scim
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-ldap/ldap"
+
+	"kubesphere.io/kubesphere/pkg/models"
+	"kubesphere.io/kubesphere/pkg/models/iam"
+	"kubesphere.io/kubesphere/pkg/params"
+)
+
+// MaxResults is the max number of resources in a page
+const MaxResults = 200
+
+var (
+	filterRegex    = regexp.MustCompile(`^\s*([\w.:\[\]]+)\s+(?i:eq)\s+("(?:[^"\\]|\\.)*")\s*$`)
+	groupNameRegex = regexp.MustCompile(`[^a-z0-9-]+`)
+)
+
+func NewError(status int, scimType, detail string) *Error {
+	return &Error{Schemas: []string{ErrorSchema}, Status: strconv.Itoa(status), ScimType: scimType, Detail: detail}
+}
+
+func (e *Error) Error() string {
+	return e.Detail
+}
+
+// Filter is an equality filter, such as userName eq "john", other operators are not supported
+type Filter struct {
+	Attribute string
+	Value     string
+}
+
+func ParseFilter(filter string) (*Filter, error) {
+	groups := filterRegex.FindStringSubmatch(filter)
+
+	if len(groups) != 3 {
+		return nil, NewError(http.StatusBadRequest, "invalidFilter", fmt.Sprintf("unsupported filter %s", filter))
+	}
+
+	value, err := strconv.Unquote(groups[2])
+
+	if err != nil {
+		return nil, NewError(http.StatusBadRequest, "invalidFilter", fmt.Sprintf("invalid filter %s", filter))
+	}
+
+	return &Filter{Attribute: strings.ToLower(groups[1]), Value: value}, nil
+}
+
+func ServiceProvider() *ServiceProviderConfig {
+	return &ServiceProviderConfig{
+		Schemas: []string{ServiceProviderConfigSchema},
+		Patch:   Supported{Supported: true},
+		Filter:  FilterSupported{Supported: true, MaxResults: MaxResults},
+		AuthenticationSchemes: []AuthenticationScheme{{
+			Type:        "oauthbearertoken",
+			Name:        "OAuth Bearer Token",
+			Description: "Authentication with the token issued by KubeSphere",
+		}},
+	}
+}
+
+func userFromModel(user *models.User) *User {
+	active := true
+	result := &User{
+		Schemas:           []string{UserSchema},
+		ID:                user.Username,
+		UserName:          user.Username,
+		DisplayName:       user.Username,
+		PreferredLanguage: user.Lang,
+		Active:            &active,
+		Meta:              &Meta{ResourceType: UserResourceType},
+	}
+
+	if user.Email != "" {
+		result.Emails = []Email{{Value: user.Email, Type: "work", Primary: true}}
+	}
+
+	if !user.CreateTime.IsZero() {
+		result.Meta.Created = &user.CreateTime
+	}
+
+	for _, group := range user.Groups {
+		result.Groups = append(result.Groups, Member{Value: group, Display: group})
+	}
+
+	return result
+}
+
+func userToModel(user *User) *models.User {
+	return &models.User{
+		Username: user.UserName,
+		Email:    primaryEmail(user.Emails),
+		Lang:     user.PreferredLanguage,
+		Password: user.Password,
+	}
+}
+
+func primaryEmail(emails []Email) string {
+	for _, email := range emails {
+		if email.Primary {
+			return email.Value
+		}
+	}
+	if len(emails) > 0 {
+		return emails[0].Value
+	}
+	return ""
+}
+
+// names of groups are restricted, the display name given by the identity provider is kept as description
+func groupFromModel(group *models.Group) *Group {
+	result := &Group{
+		Schemas:     []string{GroupSchema},
+		ID:          group.Path,
+		DisplayName: group.Name,
+		Meta:        &Meta{ResourceType: GroupResourceType},
+	}
+
+	if group.Description != "" {
+		result.DisplayName = group.Description
+	}
+
+	for _, member := range group.Members {
+		result.Members = append(result.Members, Member{Value: member, Display: member})
+	}
+
+	return result
+}
+
+func groupName(displayName string) (string, error) {
+	name := strings.Trim(groupNameRegex.ReplaceAllString(strings.ToLower(displayName), "-"), "-")
+
+	if name == "" {
+		return "", NewError(http.StatusBadRequest, "invalidValue", fmt.Sprintf("invalid group name %s", displayName))
+	}
+
+	return name, nil
+}
+
+func memberValues(members []Member) []string {
+	values := make([]string, 0)
+	for _, member := range members {
+		values = append(values, member.Value)
+	}
+	return values
+}
+
+func isActive(user *User) bool {
+	return user.Active == nil || *user.Active
+}
+
+func page(resources []interface{}, total, startIndex int) *ListResponse {
+	return &ListResponse{
+		Schemas:      []string{ListResponseSchema},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	}
+}
+
+// ListUsers supports filters on userName and emails, startIndex is 1-based
+func ListUsers(filter string, startIndex, count int) (*ListResponse, error) {
+	conditions := &params.Conditions{Match: make(map[string]string)}
+
+	if filter != "" {
+		f, err := ParseFilter(filter)
+
+		if err != nil {
+			return nil, err
+		}
+
+		switch f.Attribute {
+		case "username":
+			user, err := GetUser(f.Value)
+			if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+				return page(make([]interface{}, 0), 0, startIndex), nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			return page([]interface{}{user}, 1, startIndex), nil
+		case "emails", "emails.value":
+			conditions.Match["email"] = f.Value
+		default:
+			// attributes such as externalId are not stored, nothing matches
+			return page(make([]interface{}, 0), 0, startIndex), nil
+		}
+	}
+
+	result, err := iam.ListUsers(conditions, "username", false, count, startIndex-1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]interface{}, 0)
+
+	for _, item := range result.Items {
+		user := item.(models.User)
+		resources = append(resources, userFromModel(&user))
+	}
+
+	return page(resources, result.TotalCount, startIndex), nil
+}
+
+func GetUser(id string) (*User, error) {
+	user, err := iam.DescribeUser(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return userFromModel(user), nil
+}
+
+// CreateUser provisions a user, a random password is generated if the identity provider doesn't push one
+func CreateUser(user *User) (*User, error) {
+	if user.UserName == "" {
+		return nil, NewError(http.StatusBadRequest, "invalidValue", "userName is required")
+	}
+
+	if err := iam.ValidateUsername(user.UserName); err != nil {
+		return nil, NewError(http.StatusBadRequest, "invalidValue", err.Error())
+	}
+
+	if primaryEmail(user.Emails) == "" {
+		return nil, NewError(http.StatusBadRequest, "invalidValue", "email is required")
+	}
+
+	model := userToModel(user)
+
+	if model.Password == "" {
+		password, err := randomPassword()
+		if err != nil {
+			return nil, err
+		}
+		model.Password = password
+	}
+
+	created, err := iam.CreateUser(model)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return userFromModel(created), nil
+}
+
+// ReplaceUser updates the user, users deactivated by the identity provider are deprovisioned
+func ReplaceUser(id string, user *User) (*User, error) {
+	if user.UserName != "" && user.UserName != id {
+		return nil, NewError(http.StatusBadRequest, "mutability", "userName is immutable")
+	}
+
+	if !isActive(user) {
+		return deprovision(id)
+	}
+
+	model := userToModel(user)
+	model.Username = id
+
+	if _, err := iam.UpdateUser(model); err != nil {
+		return nil, err
+	}
+
+	return GetUser(id)
+}
+
+func PatchUser(id string, patch *PatchOp) (*User, error) {
+	user, err := GetUser(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyUserPatch(user, patch); err != nil {
+		return nil, err
+	}
+
+	return ReplaceUser(id, user)
+}
+
+func DeleteUser(id string) error {
+	return iam.DeleteUser(id)
+}
+
+func deprovision(id string) (*User, error) {
+	user, err := GetUser(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := iam.DeleteUser(id); err != nil {
+		return nil, err
+	}
+
+	active := false
+	user.Active = &active
+
+	return user, nil
+}
+
+func applyUserPatch(user *User, patch *PatchOp) error {
+	for _, operation := range patch.Operations {
+		op := strings.ToLower(operation.Op)
+
+		if op != "add" && op != "replace" {
+			// removing attributes of users is not supported, such as the email is required
+			continue
+		}
+
+		// the value is a partial resource if path is not specified
+		if operation.Path == "" {
+			attributes := make(map[string]json.RawMessage)
+			if err := json.Unmarshal(operation.Value, &attributes); err != nil {
+				return NewError(http.StatusBadRequest, "invalidValue", err.Error())
+			}
+			for path, value := range attributes {
+				if err := setUserAttribute(user, path, value); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if err := setUserAttribute(user, operation.Path, operation.Value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setUserAttribute sets attributes which are stored by KubeSphere, others are ignored
+func setUserAttribute(user *User, path string, value json.RawMessage) error {
+	path = strings.ToLower(path)
+
+	switch {
+	case path == "active":
+		active, err := parseBool(value)
+		if err != nil {
+			return err
+		}
+		user.Active = &active
+	case path == "password":
+		return unmarshalValue(value, &user.Password)
+	case path == "preferredlanguage":
+		return unmarshalValue(value, &user.PreferredLanguage)
+	case path == "emails":
+		return unmarshalValue(value, &user.Emails)
+	case strings.HasPrefix(path, "emails[") && strings.HasSuffix(path, "].value"):
+		var email string
+		if err := unmarshalValue(value, &email); err != nil {
+			return err
+		}
+		user.Emails = []Email{{Value: email, Type: "work", Primary: true}}
+	}
+
+	return nil
+}
+
+// parseBool accepts both true and "True", some identity providers send booleans as strings
+func parseBool(value json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(value, &b); err == nil {
+		return b, nil
+	}
+
+	var s string
+	if err := json.Unmarshal(value, &s); err != nil {
+		return false, NewError(http.StatusBadRequest, "invalidValue", err.Error())
+	}
+
+	b, err := strconv.ParseBool(strings.ToLower(s))
+
+	if err != nil {
+		return false, NewError(http.StatusBadRequest, "invalidValue", err.Error())
+	}
+
+	return b, nil
+}
+
+func unmarshalValue(value json.RawMessage, v interface{}) error {
+	if err := json.Unmarshal(value, v); err != nil {
+		return NewError(http.StatusBadRequest, "invalidValue", err.Error())
+	}
+	return nil
+}
+
+func randomPassword() (string, error) {
+	data := make([]byte, 16)
+
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(data), nil
+}
+
+// ListGroups supports filters on displayName, startIndex is 1-based
+func ListGroups(filter string, startIndex, count int) (*ListResponse, error) {
+	var f *Filter
+
+	if filter != "" {
+		var err error
+		if f, err = ParseFilter(filter); err != nil {
+			return nil, err
+		}
+	}
+
+	groups, err := iam.ListAllGroups()
+
+	if err != nil {
+		return nil, err
+	}
+
+	matched := make([]interface{}, 0)
+
+	for i := range groups {
+		group := groupFromModel(&groups[i])
+		if f == nil ||
+			(f.Attribute == "displayname" && group.DisplayName == f.Value) ||
+			(f.Attribute == "id" && group.ID == f.Value) {
+			matched = append(matched, group)
+		}
+	}
+
+	resources := make([]interface{}, 0)
+
+	for i := startIndex - 1; i >= 0 && i < len(matched) && len(resources) < count; i++ {
+		resources = append(resources, matched[i])
+	}
+
+	return page(resources, len(matched), startIndex), nil
+}
+
+func GetGroup(id string) (*Group, error) {
+	group, err := iam.DescribeGroup(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return groupFromModel(group), nil
+}
+
+func CreateGroup(group *Group) (*Group, error) {
+	name, err := groupName(group.DisplayName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	model := &models.Group{Name: name, Path: name, Members: memberValues(group.Members)}
+
+	if name != group.DisplayName {
+		model.Description = group.DisplayName
+	}
+
+	created, err := iam.CreateGroup(model)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return groupFromModel(created), nil
+}
+
+func ReplaceGroup(id string, group *Group) (*Group, error) {
+	current, err := iam.DescribeGroup(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if group.DisplayName != "" && group.DisplayName != groupFromModel(current).DisplayName {
+		if name, err := groupName(group.DisplayName); err != nil || name != current.Name {
+			return nil, NewError(http.StatusBadRequest, "mutability", "renaming groups is not supported")
+		}
+		current.Description = group.DisplayName
+	}
+
+	current.Members = memberValues(group.Members)
+
+	if _, err := iam.UpdateGroup(current); err != nil {
+		return nil, err
+	}
+
+	return GetGroup(id)
+}
+
+func PatchGroup(id string, patch *PatchOp) (*Group, error) {
+	group, err := GetGroup(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyGroupPatch(group, patch); err != nil {
+		return nil, err
+	}
+
+	return ReplaceGroup(id, group)
+}
+
+func DeleteGroup(id string) error {
+	return iam.DeleteGroup(id)
+}
+
+func applyGroupPatch(group *Group, patch *PatchOp) error {
+	for _, operation := range patch.Operations {
+		op := strings.ToLower(operation.Op)
+		path := strings.ToLower(operation.Path)
+
+		switch {
+		case path == "":
+			attributes := make(map[string]json.RawMessage)
+			if err := json.Unmarshal(operation.Value, &attributes); err != nil {
+				return NewError(http.StatusBadRequest, "invalidValue", err.Error())
+			}
+			for name, value := range attributes {
+				if err := patchGroupAttribute(group, op, strings.ToLower(name), value); err != nil {
+					return err
+				}
+			}
+		case strings.HasPrefix(path, "members[") && strings.HasSuffix(path, "]"):
+			if op != "remove" {
+				return NewError(http.StatusBadRequest, "invalidPath", fmt.Sprintf("unsupported path %s", operation.Path))
+			}
+			f, err := ParseFilter(operation.Path[len("members[") : len(operation.Path)-1])
+			if err != nil {
+				return err
+			}
+			if f.Attribute != "value" {
+				return NewError(http.StatusBadRequest, "invalidFilter", fmt.Sprintf("unsupported path %s", operation.Path))
+			}
+			group.Members = removeMembers(group.Members, []Member{{Value: f.Value}})
+		default:
+			if err := patchGroupAttribute(group, op, path, operation.Value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func patchGroupAttribute(group *Group, op, path string, value json.RawMessage) error {
+	switch path {
+	case "members":
+		members := make([]Member, 0)
+		if len(value) > 0 {
+			if err := unmarshalValue(value, &members); err != nil {
+				return err
+			}
+		}
+		switch op {
+		case "add":
+			group.Members = addMembers(group.Members, members)
+		case "replace":
+			group.Members = members
+		case "remove":
+			if len(value) == 0 {
+				group.Members = nil
+			} else {
+				group.Members = removeMembers(group.Members, members)
+			}
+		default:
+			return NewError(http.StatusBadRequest, "invalidSyntax", fmt.Sprintf("unsupported operation %s", op))
+		}
+	case "displayname":
+		if op == "remove" {
+			return NewError(http.StatusBadRequest, "mutability", "displayName is required")
+		}
+		return unmarshalValue(value, &group.DisplayName)
+	}
+
+	return nil
+}
+
+func addMembers(members []Member, added []Member) []Member {
+	for _, member := range added {
+		if !containsMember(members, member.Value) {
+			members = append(members, member)
+		}
+	}
+	return members
+}
+
+func removeMembers(members []Member, removed []Member) []Member {
+	result := make([]Member, 0)
+	for _, member := range members {
+		if !containsMember(removed, member.Value) {
+			result = append(result, member)
+		}
+	}
+	return result
+}
+
+func containsMember(members []Member, value string) bool {
+	for _, member := range members {
+		if member.Value == value {
+			return true
+		}
+	}
+	return false
+}
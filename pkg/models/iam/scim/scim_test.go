@@ -0,0 +1,158 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package scim
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		filter   string
+		expected *Filter
+	}{
+		{`userName eq "john"`, &Filter{Attribute: "username", Value: "john"}},
+		{`emails.value EQ "john@kubesphere.io"`, &Filter{Attribute: "emails.value", Value: "john@kubesphere.io"}},
+		{`displayName eq "dev \"ops\""`, &Filter{Attribute: "displayname", Value: `dev "ops"`}},
+		{`userName sw "j"`, nil},
+		{`userName eq john`, nil},
+		{`userName eq "john" and active eq true`, nil},
+	}
+
+	for _, test := range tests {
+		f, err := ParseFilter(test.filter)
+		if test.expected == nil {
+			if err == nil {
+				t.Errorf("%s: expect error, got %v", test.filter, f)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.filter, err)
+			continue
+		}
+		if !reflect.DeepEqual(f, test.expected) {
+			t.Errorf("%s: expect %v, got %v", test.filter, test.expected, f)
+		}
+	}
+}
+
+func TestApplyUserPatch(t *testing.T) {
+	tests := []struct {
+		patch    string
+		expected User
+	}{
+		{
+			patch:    `{"Operations":[{"op":"replace","path":"active","value":false}]}`,
+			expected: User{Active: boolPtr(false)},
+		},
+		{
+			// some identity providers send booleans as strings and omit the path
+			patch:    `{"Operations":[{"op":"Replace","value":{"active":"False","preferredLanguage":"en"}}]}`,
+			expected: User{Active: boolPtr(false), PreferredLanguage: "en"},
+		},
+		{
+			patch:    `{"Operations":[{"op":"replace","path":"emails[type eq \"work\"].value","value":"john@kubesphere.io"},{"op":"add","path":"name.givenName","value":"John"}]}`,
+			expected: User{Emails: []Email{{Value: "john@kubesphere.io", Type: "work", Primary: true}}},
+		},
+		{
+			patch:    `{"Operations":[{"op":"remove","path":"preferredLanguage"}]}`,
+			expected: User{},
+		},
+	}
+
+	for _, test := range tests {
+		var patch PatchOp
+		if err := json.Unmarshal([]byte(test.patch), &patch); err != nil {
+			t.Fatal(err)
+		}
+		user := User{}
+		if err := applyUserPatch(&user, &patch); err != nil {
+			t.Errorf("%s: unexpected error %v", test.patch, err)
+			continue
+		}
+		if !reflect.DeepEqual(user, test.expected) {
+			t.Errorf("%s: expect %+v, got %+v", test.patch, test.expected, user)
+		}
+	}
+}
+
+func TestApplyGroupPatch(t *testing.T) {
+	tests := []struct {
+		patch    string
+		expected []string
+		hasError bool
+	}{
+		{patch: `{"Operations":[{"op":"add","path":"members","value":[{"value":"bob"},{"value":"alice"}]}]}`, expected: []string{"alice", "bob"}},
+		{patch: `{"Operations":[{"op":"remove","path":"members[value eq \"alice\"]"}]}`, expected: []string{}},
+		{patch: `{"Operations":[{"op":"remove","path":"members","value":[{"value":"alice"}]}]}`, expected: []string{}},
+		{patch: `{"Operations":[{"op":"replace","path":"members","value":[{"value":"bob"}]}]}`, expected: []string{"bob"}},
+		{patch: `{"Operations":[{"op":"add","value":{"members":[{"value":"bob"}]}}]}`, expected: []string{"alice", "bob"}},
+		{patch: `{"Operations":[{"op":"add","path":"members[value eq \"bob\"]"}]}`, hasError: true},
+	}
+
+	for _, test := range tests {
+		var patch PatchOp
+		if err := json.Unmarshal([]byte(test.patch), &patch); err != nil {
+			t.Fatal(err)
+		}
+		group := Group{DisplayName: "dev", Members: []Member{{Value: "alice"}}}
+		err := applyGroupPatch(&group, &patch)
+		if test.hasError {
+			if err == nil {
+				t.Errorf("%s: expect error", test.patch)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.patch, err)
+			continue
+		}
+		if members := memberValues(group.Members); !reflect.DeepEqual(members, test.expected) {
+			t.Errorf("%s: expect %v, got %v", test.patch, test.expected, members)
+		}
+	}
+}
+
+func TestGroupName(t *testing.T) {
+	tests := map[string]string{
+		"dev":              "dev",
+		"Engineering Team": "engineering-team",
+		"  QA / Ops ":      "qa-ops",
+		"!!!":              "",
+	}
+
+	for displayName, expected := range tests {
+		name, err := groupName(displayName)
+		if expected == "" {
+			if err == nil {
+				t.Errorf("%s: expect error", displayName)
+			}
+			continue
+		}
+		if name != expected {
+			t.Errorf("%s: expect %s, got %s", displayName, expected, name)
+		}
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
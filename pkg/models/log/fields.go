@@ -0,0 +1,71 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+
+package log
+
+import (
+	"flag"
+	"regexp"
+	"strings"
+
+	es "kubesphere.io/kubesphere/pkg/simple/client/elasticsearch"
+)
+
+var (
+	// keys of JSON logs, nested keys are joined by dots, eg. http.status
+	fieldRegexp = regexp.MustCompile(`^[A-Za-z_@][A-Za-z0-9_@.-]*$`)
+
+	traceField string
+	levelField string
+)
+
+func init() {
+	flag.StringVar(&traceField, "logging-trace-field", "trace_id", "structured field of logs holding the trace id, used to query logs of a trace")
+	flag.StringVar(&levelField, "logging-level-field", "level", "structured field of logs holding the log level, used by levels of saved log queries")
+}
+
+// ParseFieldFilters parses filters of structured fields in the format field:value,field:value,
+// filters of invalid field names are ignored
+func ParseFieldFilters(value string) []es.FieldFilter {
+	var filters []es.FieldFilter
+
+	for _, item := range strings.Split(value, ",") {
+		parts := strings.SplitN(item, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		field, value := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		if !IsValidField(field) || value == "" {
+			continue
+		}
+
+		filters = append(filters, es.FieldFilter{Field: field, Value: value})
+	}
+
+	return filters
+}
+
+func IsValidField(field string) bool {
+	return fieldRegexp.MatchString(field)
+}
+
+// TraceFieldFilter matches logs of the trace, trace ids are found in traces returned by jaeger
+func TraceFieldFilter(trace string) es.FieldFilter {
+	return es.FieldFilter{Field: traceField, Value: trace}
+}
@@ -0,0 +1,44 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+
+package log
+
+import (
+	"reflect"
+	"testing"
+
+	es "kubesphere.io/kubesphere/pkg/simple/client/elasticsearch"
+)
+
+func TestParseFieldFilters(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected []es.FieldFilter
+	}{
+		{"", nil},
+		{"level:error,trace_id:abc", []es.FieldFilter{{Field: "level", Value: "error"}, {Field: "trace_id", Value: "abc"}}},
+		{" http.status : 504 ,url:http://demo/api", []es.FieldFilter{{Field: "http.status", Value: "504"}, {Field: "url", Value: "http://demo/api"}}},
+		{"level,level:,:error,le vel:error,level\":error,user_id:42", []es.FieldFilter{{Field: "user_id", Value: "42"}}},
+	}
+
+	for _, test := range tests {
+		if filters := ParseFieldFilters(test.value); !reflect.DeepEqual(filters, test.expected) {
+			t.Errorf("%q: expected %v, got %v", test.value, test.expected, filters)
+		}
+	}
+}
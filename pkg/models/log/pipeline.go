@@ -0,0 +1,686 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+
+package log
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/golang/glog"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fb "kubesphere.io/kubesphere/pkg/simple/client/fluentbit"
+	"kubesphere.io/kubesphere/pkg/simple/client/k8s"
+)
+
+// sections of the fluent bit pipeline managed through the API
+const (
+	PipelineInputs  = "inputs"
+	PipelineParsers = "parsers"
+	PipelineFilters = "filters"
+)
+
+const (
+	// plugins managed through the API are kept in the fluent bit CRD with the prefixes,
+	// other plugins are managed by KubeSphere and read only
+	customInputPrefix  = "fluentbit-input-custom-"
+	customParserPrefix = "fluentbit-parser-custom-"
+	customFilterPrefix = "fluentbit-filter-custom-"
+
+	// custom filters run before kubernetes metadata is nested back under the kubernetes key
+	nestFilterName = "fluentbit-filter-input-nest"
+
+	pluginTypeInput           = "fluentbit_input"
+	pluginTypeParser          = "fluentbit_parser"
+	pluginTypeMultilineParser = fb.PluginTypeMultilineParser
+	pluginTypeFilter          = "fluentbit_filter"
+
+	defaultFilterMatch = "kube.*"
+
+	fluentbitDaemonSet = "fluent-bit"
+)
+
+var (
+	pluginNameRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+	// onigmo named groups (?<name>...) of fluent bit are written as (?P<name>...) in go
+	namedGroupRegexp = regexp.MustCompile(`\(\?<([A-Za-z_])`)
+	namedGroupNames  = regexp.MustCompile(`\(\?<([A-Za-z_][A-Za-z0-9_]*)>`)
+	// lookarounds, atomic groups, backreferences, possessive quantifiers and escapes of onigmo which go doesn't support
+	onigmoOnlyRegexp = regexp.MustCompile(`\(\?<?[=!]|\(\?>|\\[1-9]|\\k<|\\[hHRXK]|[*+?}]\+`)
+	// fluent bit supports multiline parsers since 1.8
+	multilineMinVersion = []int{1, 8}
+
+	pipelinePlugins = map[string][]string{
+		PipelineInputs:  {"tail", "systemd", "forward"},
+		PipelineParsers: {"regex", "json", "multiline"},
+		PipelineFilters: {"grep", "modify", "nest", "kubernetes"},
+	}
+
+	// fluentbitImage returns the image fluent bit runs
+	fluentbitImage = func() (string, error) {
+		daemonSet, err := k8s.Client().AppsV1().DaemonSets(LoggingNamespace).Get(fluentbitDaemonSet, metav1.GetOptions{})
+		if err != nil {
+			return "", err
+		}
+		for _, container := range daemonSet.Spec.Template.Spec.Containers {
+			if container.Name == fluentbitDaemonSet {
+				return container.Image, nil
+			}
+		}
+		return "", fmt.Errorf("container %s not found in daemonset %s", fluentbitDaemonSet, fluentbitDaemonSet)
+	}
+)
+
+type pipelineError struct {
+	status  int
+	message string
+}
+
+func (e *pipelineError) Error() string {
+	return e.message
+}
+
+func pipelineErrorf(status int, format string, args ...interface{}) error {
+	return &pipelineError{status: status, message: fmt.Sprintf(format, args...)}
+}
+
+func pipelineResult(spec *fb.FluentBitSpec, section string, err error) *FluentbitPipelineResult {
+	if err != nil {
+		status := http.StatusInternalServerError
+		if e, ok := err.(*pipelineError); ok {
+			status = e.status
+		}
+		return &FluentbitPipelineResult{Status: status, Error: err.Error()}
+	}
+
+	return &FluentbitPipelineResult{Status: http.StatusOK, Plugins: listPipelinePlugins(spec, section)}
+}
+
+func FluentbitPipelineQuery(section string) *FluentbitPipelineResult {
+	if _, ok := pipelinePlugins[section]; !ok {
+		return pipelineResult(nil, section, pipelineErrorf(http.StatusNotFound, "unknown section %s", section))
+	}
+
+	crdcs, scheme, err := createCRDClientSet()
+	if err != nil {
+		return pipelineResult(nil, section, err)
+	}
+
+	item, err := fb.CrdClient(crdcs, scheme, LoggingNamespace).Get("fluent-bit")
+	if err != nil {
+		return pipelineResult(nil, section, err)
+	}
+
+	return pipelineResult(&item.Spec, section, nil)
+}
+
+func FluentbitPipelineInsert(section string, plugin FluentbitPipelinePlugin) *FluentbitPipelineResult {
+	return updatePipeline(section, func(spec *fb.FluentBitSpec) error {
+		return insertPipelinePlugin(spec, section, plugin)
+	})
+}
+
+func FluentbitPipelineUpdate(section, name string, plugin FluentbitPipelinePlugin) *FluentbitPipelineResult {
+	return updatePipeline(section, func(spec *fb.FluentBitSpec) error {
+		return updatePipelinePlugin(spec, section, name, plugin)
+	})
+}
+
+func FluentbitPipelineDelete(section, name string) *FluentbitPipelineResult {
+	return updatePipeline(section, func(spec *fb.FluentBitSpec) error {
+		return deletePipelinePlugin(spec, section, name)
+	})
+}
+
+// updatePipeline applies the change to the spec of the fluent bit CRD
+func updatePipeline(section string, change func(spec *fb.FluentBitSpec) error) *FluentbitPipelineResult {
+	if _, ok := pipelinePlugins[section]; !ok {
+		return pipelineResult(nil, section, pipelineErrorf(http.StatusNotFound, "unknown section %s", section))
+	}
+
+	crdcs, scheme, err := createCRDClientSet()
+	if err != nil {
+		return pipelineResult(nil, section, err)
+	}
+
+	crdclient := fb.CrdClient(crdcs, scheme, LoggingNamespace)
+
+	item, err := crdclient.Get("fluent-bit")
+	if err != nil {
+		return pipelineResult(nil, section, err)
+	}
+
+	if err := change(&item.Spec); err != nil {
+		return pipelineResult(nil, section, err)
+	}
+
+	// parsers are rendered before fluent bit reloads with inputs and filters referring to them
+	if section == PipelineParsers {
+		fb.SetParsersFile(&item.Spec)
+		if err := updateParsersConfigMap(item.Spec.Parser); err != nil {
+			glog.Errorln(err)
+			return pipelineResult(nil, section, err)
+		}
+	}
+
+	updated, err := crdclient.Update("fluent-bit", item)
+	if err != nil {
+		glog.Errorln(err)
+		return pipelineResult(nil, section, err)
+	}
+
+	return pipelineResult(&updated.Spec, section, nil)
+}
+
+// updateParsersConfigMap renders parsers to the configmap mounted by fluent bit
+func updateParsersConfigMap(parsers []fb.Plugin) error {
+	client := k8s.Client().CoreV1().ConfigMaps(LoggingNamespace)
+	data := map[string]string{fb.ParsersConfigMapData: fb.RenderParsers(parsers)}
+
+	configMap, err := client.Get(fb.ParsersConfigMapName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = client.Create(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: fb.ParsersConfigMapName}, Data: data})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	configMap.Data = data
+	_, err = client.Update(configMap)
+	return err
+}
+
+func sectionOf(spec *fb.FluentBitSpec, section string) *[]fb.Plugin {
+	switch section {
+	case PipelineInputs:
+		return &spec.Input
+	case PipelineParsers:
+		return &spec.Parser
+	default:
+		return &spec.Filter
+	}
+}
+
+func customPrefix(section string) string {
+	switch section {
+	case PipelineInputs:
+		return customInputPrefix
+	case PipelineParsers:
+		return customParserPrefix
+	default:
+		return customFilterPrefix
+	}
+}
+
+func listPipelinePlugins(spec *fb.FluentBitSpec, section string) []FluentbitPipelinePlugin {
+	plugins := make([]FluentbitPipelinePlugin, 0)
+	for _, plugin := range *sectionOf(spec, section) {
+		plugins = append(plugins, fromCRDPlugin(section, plugin))
+	}
+	return plugins
+}
+
+func insertPipelinePlugin(spec *fb.FluentBitSpec, section string, plugin FluentbitPipelinePlugin) error {
+	if err := validatePipelinePlugin(spec, section, plugin); err != nil {
+		return err
+	}
+
+	plugins := sectionOf(spec, section)
+	if indexOfPlugin(*plugins, customPrefix(section)+plugin.Name) >= 0 {
+		return pipelineErrorf(http.StatusConflict, "%s %s already exists", strings.TrimSuffix(section, "s"), plugin.Name)
+	}
+
+	item := toCRDPlugin(section, plugin)
+
+	// filters are applied in order, custom filters go before kubernetes metadata is nested
+	// and logs of workspaces are retagged, so they see the same records as the built-in filters
+	position := len(*plugins)
+	if section == PipelineFilters {
+		for i, filter := range *plugins {
+			if filter.Name == nestFilterName || strings.HasPrefix(filter.Name, fb.WorkspaceFilterPrefix) {
+				position = i
+				break
+			}
+		}
+	}
+
+	*plugins = append((*plugins)[:position], append([]fb.Plugin{item}, (*plugins)[position:]...)...)
+	return nil
+}
+
+func updatePipelinePlugin(spec *fb.FluentBitSpec, section, name string, plugin FluentbitPipelinePlugin) error {
+	plugin.Name = name
+	if err := validatePipelinePlugin(spec, section, plugin); err != nil {
+		return err
+	}
+
+	plugins := *sectionOf(spec, section)
+	index := indexOfPlugin(plugins, customPrefix(section)+name)
+	if index < 0 {
+		return pipelineErrorf(http.StatusNotFound, "%s %s not found", strings.TrimSuffix(section, "s"), name)
+	}
+
+	plugins[index] = toCRDPlugin(section, plugin)
+	return nil
+}
+
+func deletePipelinePlugin(spec *fb.FluentBitSpec, section, name string) error {
+	plugins := sectionOf(spec, section)
+	index := indexOfPlugin(*plugins, customPrefix(section)+name)
+	if index < 0 {
+		return pipelineErrorf(http.StatusNotFound, "%s %s not found", strings.TrimSuffix(section, "s"), name)
+	}
+
+	if section == PipelineParsers {
+		if user := parserUser(spec, name); user != "" {
+			return pipelineErrorf(http.StatusConflict, "parser %s is still used by %s", name, user)
+		}
+	}
+
+	*plugins = append((*plugins)[:index], (*plugins)[index+1:]...)
+	return nil
+}
+
+func indexOfPlugin(plugins []fb.Plugin, name string) int {
+	for i, plugin := range plugins {
+		if plugin.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// parserUser returns the input or filter referring to the parser
+func parserUser(spec *fb.FluentBitSpec, parser string) string {
+	for _, plugins := range [][]fb.Plugin{spec.Input, spec.Filter} {
+		for _, plugin := range plugins {
+			for _, parameter := range plugin.Parameters {
+				switch parameter.Name {
+				case "Parser", "Multiline.Parser", "Merge_Parser":
+					for _, name := range strings.Split(parameter.Value, ",") {
+						if strings.TrimSpace(name) == parser {
+							return plugin.Name
+						}
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// toCRDPlugin converts the plugin to the fluent bit CRD, the plugin is set to the Name parameter for inputs and filters,
+// parsers are named by the Name parameter with the plugin set to Format
+func toCRDPlugin(section string, plugin FluentbitPipelinePlugin) fb.Plugin {
+	item := fb.Plugin{Name: customPrefix(section) + plugin.Name}
+
+	switch section {
+	case PipelineInputs:
+		item.Type = pluginTypeInput
+		item.Parameters = []fb.Parameter{{Name: "Name", Value: plugin.Plugin}}
+	case PipelineParsers:
+		if plugin.Plugin == "multiline" {
+			item.Type = pluginTypeMultilineParser
+			item.Parameters = []fb.Parameter{{Name: "Name", Value: plugin.Name}, {Name: "Type", Value: "regex"}}
+		} else {
+			item.Type = pluginTypeParser
+			item.Parameters = []fb.Parameter{{Name: "Name", Value: plugin.Name}, {Name: "Format", Value: plugin.Plugin}}
+		}
+	case PipelineFilters:
+		item.Type = pluginTypeFilter
+		item.Parameters = []fb.Parameter{{Name: "Name", Value: plugin.Plugin}}
+		if getParameterValue(plugin.Parameters, "Match") == "" {
+			item.Parameters = append(item.Parameters, fb.Parameter{Name: "Match", Value: defaultFilterMatch})
+		}
+	}
+
+	for _, parameter := range plugin.Parameters {
+		if !isReservedParameter(section, parameter.Name) {
+			item.Parameters = append(item.Parameters, parameter)
+		}
+	}
+
+	return item
+}
+
+func fromCRDPlugin(section string, item fb.Plugin) FluentbitPipelinePlugin {
+	plugin := FluentbitPipelinePlugin{Name: item.Name, Parameters: make([]fb.Parameter, 0, len(item.Parameters))}
+
+	if strings.HasPrefix(item.Name, customPrefix(section)) {
+		plugin.Name = strings.TrimPrefix(item.Name, customPrefix(section))
+	} else {
+		plugin.ReadOnly = true
+	}
+
+	switch {
+	case item.Type == pluginTypeMultilineParser:
+		plugin.Plugin = "multiline"
+	case section == PipelineParsers:
+		plugin.Plugin = getParameterValue(item.Parameters, "Format")
+	default:
+		plugin.Plugin = getParameterValue(item.Parameters, "Name")
+	}
+
+	for _, parameter := range item.Parameters {
+		if !isReservedParameter(section, parameter.Name) {
+			plugin.Parameters = append(plugin.Parameters, parameter)
+		}
+	}
+
+	return plugin
+}
+
+// isReservedParameter tells parameters set from name and plugin of the plugin
+func isReservedParameter(section, name string) bool {
+	switch section {
+	case PipelineParsers:
+		return name == "Name" || name == "Format" || name == "Type"
+	default:
+		return name == "Name"
+	}
+}
+
+func getParameterValues(parameters []fb.Parameter, name string) []string {
+	var values []string
+	for _, parameter := range parameters {
+		if parameter.Name == name {
+			values = append(values, parameter.Value)
+		}
+	}
+	return values
+}
+
+func validatePipelinePlugin(spec *fb.FluentBitSpec, section string, plugin FluentbitPipelinePlugin) error {
+	if !pluginNameRegexp.MatchString(plugin.Name) || strings.HasPrefix(plugin.Name, "fluentbit-") {
+		return pipelineErrorf(http.StatusBadRequest, "invalid name %q, it must consist of lower case alphanumeric characters or '-', and must not start with fluentbit-", plugin.Name)
+	}
+
+	supported := false
+	for _, name := range pipelinePlugins[section] {
+		if plugin.Plugin == name {
+			supported = true
+		}
+	}
+	if !supported {
+		return pipelineErrorf(http.StatusBadRequest, "unsupported plugin %q, supported %s are %s", plugin.Plugin, section, strings.Join(pipelinePlugins[section], ", "))
+	}
+
+	// parsers are referred to by name, names of parsers built in fluent bit would be shadowed
+	if section == PipelineParsers && isBuiltinParser(plugin.Name) {
+		return pipelineErrorf(http.StatusBadRequest, "name %q is reserved by parsers built in fluent bit", plugin.Name)
+	}
+
+	for _, parameter := range plugin.Parameters {
+		if parameter.Name == "" {
+			return pipelineErrorf(http.StatusBadRequest, "parameters must be named")
+		}
+	}
+
+	var err error
+	switch section {
+	case PipelineInputs:
+		err = validateInput(spec, plugin)
+	case PipelineParsers:
+		err = validateParser(plugin)
+	case PipelineFilters:
+		err = validateFilter(spec, plugin)
+	}
+
+	if err != nil {
+		return pipelineErrorf(http.StatusBadRequest, "invalid %s %s: %v", strings.TrimSuffix(section, "s"), plugin.Name, err)
+	}
+
+	if requiresMultiline(section, plugin) {
+		image, err := fluentbitImage()
+		if err != nil {
+			return pipelineErrorf(http.StatusInternalServerError, "failed to get the version of fluent bit: %v", err)
+		}
+		if !supportsMultiline(image) {
+			return pipelineErrorf(http.StatusBadRequest, "multiline parsers require fluent bit %d.%d or later, fluent bit runs %s", multilineMinVersion[0], multilineMinVersion[1], image)
+		}
+	}
+
+	return nil
+}
+
+func requiresMultiline(section string, plugin FluentbitPipelinePlugin) bool {
+	if section == PipelineParsers {
+		return plugin.Plugin == "multiline"
+	}
+	return getParameterValue(plugin.Parameters, "Multiline.Parser") != ""
+}
+
+// supportsMultiline tells whether the fluent bit image supports multiline parsers by its tag,
+// images tagged other than versions, eg. latest, are taken as supported
+func supportsMultiline(image string) bool {
+	tag := image[strings.LastIndex(image, "/")+1:]
+	if i := strings.LastIndex(tag, ":"); i >= 0 {
+		tag = tag[i+1:]
+	} else {
+		return true
+	}
+
+	fields := strings.FieldsFunc(strings.TrimPrefix(tag, "v"), func(r rune) bool { return r == '.' || r == '-' })
+	for i, min := range multilineMinVersion {
+		if i >= len(fields) {
+			return true
+		}
+		version, err := strconv.Atoi(fields[i])
+		if err != nil {
+			return true
+		}
+		if version != min {
+			return version > min
+		}
+	}
+	return true
+}
+
+func validateInput(spec *fb.FluentBitSpec, plugin FluentbitPipelinePlugin) error {
+	if getParameterValue(plugin.Parameters, "Tag") == "" {
+		return fmt.Errorf("Tag is required to route records of the input")
+	}
+
+	if plugin.Plugin == "tail" && getParameterValue(plugin.Parameters, "Path") == "" {
+		return fmt.Errorf("Path is required by tail inputs")
+	}
+
+	return validateParserReferences(spec, plugin.Parameters)
+}
+
+func validateFilter(spec *fb.FluentBitSpec, plugin FluentbitPipelinePlugin) error {
+	switch plugin.Plugin {
+	case "grep":
+		rules := 0
+		for _, name := range []string{"Regex", "Exclude"} {
+			for _, value := range getParameterValues(plugin.Parameters, name) {
+				fields := strings.SplitN(strings.TrimSpace(value), " ", 2)
+				if len(fields) != 2 || strings.TrimSpace(fields[1]) == "" {
+					return fmt.Errorf("%s must be in the format KEY REGEX", name)
+				}
+				if _, err := validateRegex(strings.TrimSpace(fields[1])); err != nil {
+					return fmt.Errorf("%s of %s: %v", name, fields[0], err)
+				}
+				rules++
+			}
+		}
+		if rules == 0 {
+			return fmt.Errorf("at least one Regex or Exclude is required by grep filters")
+		}
+	case "modify":
+		rules := 0
+		for _, parameter := range plugin.Parameters {
+			switch parameter.Name {
+			case "Set", "Add", "Rename", "Hard_rename", "Copy", "Hard_copy":
+				if len(strings.Fields(parameter.Value)) < 2 {
+					return fmt.Errorf("%s must be in the format KEY VALUE", parameter.Name)
+				}
+				rules++
+			case "Remove", "Remove_wildcard", "Remove_regex":
+				if strings.TrimSpace(parameter.Value) == "" {
+					return fmt.Errorf("%s requires a key", parameter.Name)
+				}
+				rules++
+			}
+		}
+		if rules == 0 {
+			return fmt.Errorf("at least one of Set, Add, Remove, Remove_wildcard, Remove_regex, Rename, Hard_rename, Copy and Hard_copy is required by modify filters")
+		}
+	case "nest":
+		switch getParameterValue(plugin.Parameters, "Operation") {
+		case "nest":
+			if getParameterValue(plugin.Parameters, "Wildcard") == "" || getParameterValue(plugin.Parameters, "Nest_under") == "" {
+				return fmt.Errorf("Wildcard and Nest_under are required by the nest operation")
+			}
+		case "lift":
+			if getParameterValue(plugin.Parameters, "Nested_under") == "" {
+				return fmt.Errorf("Nested_under is required by the lift operation")
+			}
+		default:
+			return fmt.Errorf("Operation must be one of nest and lift")
+		}
+	case "kubernetes":
+		return validateParserReferences(spec, plugin.Parameters)
+	}
+
+	return nil
+}
+
+func validateParser(plugin FluentbitPipelinePlugin) error {
+	switch plugin.Plugin {
+	case "regex":
+		expression := getParameterValue(plugin.Parameters, "Regex")
+		if expression == "" {
+			return fmt.Errorf("Regex is required by regex parsers")
+		}
+		names, err := validateRegex(expression)
+		if err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			return fmt.Errorf("Regex must have named groups, eg. (?<message>.*)")
+		}
+	case "multiline":
+		if _, err := parseMultilineRules(plugin.Parameters); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateParserReferences checks parsers referred to by inputs and filters exist
+func validateParserReferences(spec *fb.FluentBitSpec, parameters []fb.Parameter) error {
+	parsers := make(map[string]bool)
+	for _, parser := range spec.Parser {
+		parsers[getParameterValue(parser.Parameters, "Name")] = true
+	}
+
+	for _, parameter := range parameters {
+		switch parameter.Name {
+		case "Parser", "Multiline.Parser", "Merge_Parser":
+			for _, name := range strings.Split(parameter.Value, ",") {
+				name = strings.TrimSpace(name)
+				// parsers built in fluent bit, eg. docker and cri, are not kept in the CRD
+				if name != "" && !parsers[name] && !isBuiltinParser(name) {
+					return fmt.Errorf("parser %s not found", name)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func isBuiltinParser(name string) bool {
+	switch name {
+	case "docker", "docker-daemon", "cri", "go", "python", "java", "ruby", "json", "syslog-rfc5424", "syslog-rfc3164", "syslog-rfc3164-local",
+		"apache", "apache2", "apache_error", "nginx", "k8s-nginx-ingress", "mongodb", "envoy", "istio-envoy-proxy", "kube-custom":
+		return true
+	}
+	return false
+}
+
+func compileRegex(expression string) (*regexp.Regexp, error) {
+	return regexp.Compile(namedGroupRegexp.ReplaceAllString(expression, "(?P<$1"))
+}
+
+// validateRegex validates the onigmo regex run by fluent bit and returns names of its groups.
+// Regexes using syntax go doesn't support are only checked for balanced groups.
+func validateRegex(expression string) ([]string, error) {
+	re, err := compileRegex(expression)
+	if err == nil {
+		var names []string
+		for _, name := range re.SubexpNames() {
+			if name != "" {
+				names = append(names, name)
+			}
+		}
+		return names, nil
+	}
+
+	if !onigmoOnlyRegexp.MatchString(expression) {
+		return nil, err
+	}
+
+	if err := checkGroups(expression); err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, match := range namedGroupNames.FindAllStringSubmatch(expression, -1) {
+		names = append(names, match[1])
+	}
+	return names, nil
+}
+
+// checkGroups checks parentheses out of character classes are balanced
+func checkGroups(expression string) error {
+	depth := 0
+	class := false
+	for i := 0; i < len(expression); i++ {
+		switch c := expression[i]; {
+		case c == '\\':
+			i++
+		case class:
+			class = c != ']'
+		case c == '[':
+			class = true
+		case c == '(':
+			depth++
+		case c == ')':
+			if depth--; depth < 0 {
+				return fmt.Errorf("unexpected ) in %s", expression)
+			}
+		}
+	}
+
+	if class {
+		return fmt.Errorf("missing closing ] in %s", expression)
+	}
+	if depth > 0 {
+		return fmt.Errorf("missing closing ) in %s", expression)
+	}
+	return nil
+}
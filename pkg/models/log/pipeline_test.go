@@ -0,0 +1,304 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+
+package log
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	fb "kubesphere.io/kubesphere/pkg/simple/client/fluentbit"
+)
+
+func TestInsertPipelinePlugin(t *testing.T) {
+	kubernetes := fb.Plugin{Type: pluginTypeFilter, Name: "fluentbit-filter-kubernetes", Parameters: []fb.Parameter{{Name: "Name", Value: "kubernetes"}}}
+	nest := fb.Plugin{Type: pluginTypeFilter, Name: nestFilterName, Parameters: []fb.Parameter{{Name: "Name", Value: "nest"}}}
+	workspace := fb.Plugin{Type: pluginTypeFilter, Name: fb.WorkspaceFilterPrefix + "finance", Parameters: []fb.Parameter{{Name: "Name", Value: "rewrite_tag"}}}
+
+	grep := FluentbitPipelinePlugin{Name: "drop-debug", Plugin: "grep", Parameters: []fb.Parameter{{Name: "Exclude", Value: "log DEBUG"}}}
+	expectedGrep := fb.Plugin{Type: pluginTypeFilter, Name: customFilterPrefix + "drop-debug", Parameters: []fb.Parameter{
+		{Name: "Name", Value: "grep"},
+		{Name: "Match", Value: defaultFilterMatch},
+		{Name: "Exclude", Value: "log DEBUG"},
+	}}
+
+	tests := []struct {
+		name     string
+		filters  []fb.Plugin
+		expected []fb.Plugin
+	}{
+		{"before nest", []fb.Plugin{kubernetes, nest, workspace}, []fb.Plugin{kubernetes, expectedGrep, nest, workspace}},
+		{"before workspaces", []fb.Plugin{kubernetes, workspace}, []fb.Plugin{kubernetes, expectedGrep, workspace}},
+		{"append", []fb.Plugin{kubernetes}, []fb.Plugin{kubernetes, expectedGrep}},
+	}
+
+	for _, test := range tests {
+		spec := &fb.FluentBitSpec{Filter: test.filters}
+		if err := insertPipelinePlugin(spec, PipelineFilters, grep); err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(spec.Filter, test.expected) {
+			t.Errorf("%s: expected filters %v, got %v", test.name, test.expected, spec.Filter)
+		}
+	}
+
+	spec := &fb.FluentBitSpec{Filter: []fb.Plugin{kubernetes, expectedGrep}}
+	if err := insertPipelinePlugin(spec, PipelineFilters, grep); statusOf(err) != http.StatusConflict {
+		t.Errorf("expected conflict inserting an existing filter, got %v", err)
+	}
+}
+
+func TestUpdateAndDeletePipelinePlugin(t *testing.T) {
+	parser := FluentbitPipelinePlugin{Name: "nginx-access", Plugin: "regex", Parameters: []fb.Parameter{{Name: "Regex", Value: `^(?<remote>[^ ]*) (?<code>\d+)$`}}}
+	input := FluentbitPipelinePlugin{Name: "nginx", Plugin: "tail", Parameters: []fb.Parameter{
+		{Name: "Path", Value: "/var/log/nginx/access.log"},
+		{Name: "Tag", Value: "nginx.*"},
+		{Name: "Parser", Value: "nginx-access"},
+	}}
+
+	spec := &fb.FluentBitSpec{}
+	if err := insertPipelinePlugin(spec, PipelineInputs, input); statusOf(err) != http.StatusBadRequest {
+		t.Errorf("expected bad request referring to a missing parser, got %v", err)
+	}
+	if err := insertPipelinePlugin(spec, PipelineParsers, parser); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := insertPipelinePlugin(spec, PipelineInputs, input); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if err := deletePipelinePlugin(spec, PipelineParsers, "nginx-access"); statusOf(err) != http.StatusConflict {
+		t.Errorf("expected conflict deleting a parser in use, got %v", err)
+	}
+	if err := updatePipelinePlugin(spec, PipelineInputs, "missing", input); statusOf(err) != http.StatusNotFound {
+		t.Errorf("expected not found updating a missing input, got %v", err)
+	}
+
+	input.Parameters = input.Parameters[:2]
+	if err := updatePipelinePlugin(spec, PipelineInputs, "nginx", input); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := deletePipelinePlugin(spec, PipelineParsers, "nginx-access"); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err := deletePipelinePlugin(spec, PipelineParsers, "nginx-access"); statusOf(err) != http.StatusNotFound {
+		t.Errorf("expected not found deleting a deleted parser, got %v", err)
+	}
+
+	expected := []FluentbitPipelinePlugin{input}
+	if plugins := listPipelinePlugins(spec, PipelineInputs); !reflect.DeepEqual(plugins, expected) {
+		t.Errorf("expected inputs %v, got %v", expected, plugins)
+	}
+}
+
+func TestListPipelinePlugins(t *testing.T) {
+	spec := &fb.FluentBitSpec{Parser: []fb.Plugin{
+		{Type: pluginTypeParser, Name: "fluentbit-parser-docker", Parameters: []fb.Parameter{{Name: "Name", Value: "docker"}, {Name: "Format", Value: "json"}}},
+		{Type: pluginTypeMultilineParser, Name: customParserPrefix + "java-stack", Parameters: []fb.Parameter{
+			{Name: "Name", Value: "java-stack"},
+			{Name: "Type", Value: "regex"},
+			{Name: "Rule", Value: `"start_state" "/^\d{4}/" "cont"`},
+		}},
+	}}
+
+	expected := []FluentbitPipelinePlugin{
+		{Name: "fluentbit-parser-docker", Plugin: "json", Parameters: []fb.Parameter{}, ReadOnly: true},
+		{Name: "java-stack", Plugin: "multiline", Parameters: []fb.Parameter{{Name: "Rule", Value: `"start_state" "/^\d{4}/" "cont"`}}},
+	}
+
+	if plugins := listPipelinePlugins(spec, PipelineParsers); !reflect.DeepEqual(plugins, expected) {
+		t.Errorf("expected parsers %v, got %v", expected, plugins)
+	}
+}
+
+func TestValidatePipelinePlugin(t *testing.T) {
+	defer useFluentbitImage("kubesphere/fluent-bit:v1.8.3")()
+
+	tests := []struct {
+		section string
+		plugin  FluentbitPipelinePlugin
+		valid   bool
+	}{
+		{PipelineInputs, FluentbitPipelinePlugin{Name: "journal", Plugin: "systemd", Parameters: []fb.Parameter{{Name: "Tag", Value: "service.*"}}}, true},
+		{PipelineInputs, FluentbitPipelinePlugin{Name: "journal", Plugin: "systemd"}, false},
+		{PipelineInputs, FluentbitPipelinePlugin{Name: "files", Plugin: "tail", Parameters: []fb.Parameter{{Name: "Tag", Value: "files"}}}, false},
+		{PipelineInputs, FluentbitPipelinePlugin{Name: "metrics", Plugin: "cpu", Parameters: []fb.Parameter{{Name: "Tag", Value: "cpu"}}}, false},
+		{PipelineInputs, FluentbitPipelinePlugin{Name: "fluentbit-tail", Plugin: "systemd", Parameters: []fb.Parameter{{Name: "Tag", Value: "service.*"}}}, false},
+		{PipelineInputs, FluentbitPipelinePlugin{Name: "Journal", Plugin: "systemd", Parameters: []fb.Parameter{{Name: "Tag", Value: "service.*"}}}, false},
+		{PipelineParsers, FluentbitPipelinePlugin{Name: "json-log", Plugin: "json"}, true},
+		{PipelineParsers, FluentbitPipelinePlugin{Name: "access", Plugin: "regex", Parameters: []fb.Parameter{{Name: "Regex", Value: `^(?<code>\d+)$`}}}, true},
+		{PipelineParsers, FluentbitPipelinePlugin{Name: "access", Plugin: "regex", Parameters: []fb.Parameter{{Name: "Regex", Value: `^(\d+)$`}}}, false},
+		{PipelineParsers, FluentbitPipelinePlugin{Name: "access", Plugin: "regex", Parameters: []fb.Parameter{{Name: "Regex", Value: `^(?<code>\d+$`}}}, false},
+		{PipelineParsers, FluentbitPipelinePlugin{Name: "access", Plugin: "regex", Parameters: []fb.Parameter{{Name: "Regex", Value: `^(?<path>(?!/healthz)[^ ]+) (?<code>\d+)$`}}}, true},
+		{PipelineParsers, FluentbitPipelinePlugin{Name: "access", Plugin: "regex", Parameters: []fb.Parameter{{Name: "Regex", Value: `^(?<quote>["'])(?<value>.*)\1$`}}}, true},
+		{PipelineParsers, FluentbitPipelinePlugin{Name: "access", Plugin: "regex", Parameters: []fb.Parameter{{Name: "Regex", Value: `^(?<path>(?!/healthz)[^ ]+ (?<code>\d+)$`}}}, false},
+		{PipelineParsers, FluentbitPipelinePlugin{Name: "docker", Plugin: "json"}, false},
+		{PipelineParsers, FluentbitPipelinePlugin{Name: "java-stack", Plugin: "multiline", Parameters: []fb.Parameter{
+			{Name: "Rule", Value: `"start_state" "/^\d{4}/" "cont"`},
+			{Name: "Rule", Value: `"cont" "/^\s+at /" "cont"`},
+		}}, true},
+		{PipelineParsers, FluentbitPipelinePlugin{Name: "java-stack", Plugin: "multiline", Parameters: []fb.Parameter{{Name: "Rule", Value: `"start_state" "/^\d{4}/" "cont"`}}}, false},
+		{PipelineParsers, FluentbitPipelinePlugin{Name: "java-stack", Plugin: "multiline", Parameters: []fb.Parameter{
+			{Name: "Rule", Value: `"start_state" "/^(?!\s)/" "cont"`},
+			{Name: "Rule", Value: `"cont" "/^\s+at /" "cont"`},
+		}}, true},
+		{PipelineParsers, FluentbitPipelinePlugin{Name: "java-stack", Plugin: "multiline", Parameters: []fb.Parameter{{Name: "Rule", Value: `"cont" "/^\s+at /" "cont"`}}}, false},
+		{PipelineFilters, FluentbitPipelinePlugin{Name: "keep-errors", Plugin: "grep", Parameters: []fb.Parameter{{Name: "Regex", Value: "log ERROR"}}}, true},
+		{PipelineFilters, FluentbitPipelinePlugin{Name: "keep-errors", Plugin: "grep", Parameters: []fb.Parameter{{Name: "Regex", Value: "log"}}}, false},
+		{PipelineFilters, FluentbitPipelinePlugin{Name: "keep-errors", Plugin: "grep"}, false},
+		{PipelineFilters, FluentbitPipelinePlugin{Name: "cluster", Plugin: "modify", Parameters: []fb.Parameter{{Name: "Add", Value: "cluster host"}}}, true},
+		{PipelineFilters, FluentbitPipelinePlugin{Name: "cluster", Plugin: "modify", Parameters: []fb.Parameter{{Name: "Add", Value: "cluster"}}}, false},
+		{PipelineFilters, FluentbitPipelinePlugin{Name: "lift", Plugin: "nest", Parameters: []fb.Parameter{{Name: "Operation", Value: "lift"}, {Name: "Nested_under", Value: "kubernetes"}}}, true},
+		{PipelineFilters, FluentbitPipelinePlugin{Name: "lift", Plugin: "nest", Parameters: []fb.Parameter{{Name: "Operation", Value: "nest"}}}, false},
+		{PipelineFilters, FluentbitPipelinePlugin{Name: "metadata", Plugin: "kubernetes", Parameters: []fb.Parameter{{Name: "Merge_Parser", Value: "docker"}}}, true},
+		{PipelineFilters, FluentbitPipelinePlugin{Name: "metadata", Plugin: "kubernetes", Parameters: []fb.Parameter{{Name: "Merge_Parser", Value: "missing"}}}, false},
+	}
+
+	for i, test := range tests {
+		err := validatePipelinePlugin(&fb.FluentBitSpec{}, test.section, test.plugin)
+		if test.valid && err != nil {
+			t.Errorf("case %d: unexpected error %v", i, err)
+		}
+		if !test.valid && statusOf(err) != http.StatusBadRequest {
+			t.Errorf("case %d: expected bad request, got %v", i, err)
+		}
+	}
+}
+
+func TestValidateMultilineVersion(t *testing.T) {
+	java := FluentbitPipelinePlugin{Name: "java-stack", Plugin: "multiline", Parameters: []fb.Parameter{
+		{Name: "Rule", Value: `"start_state" "/^\d{4}/" "cont"`},
+		{Name: "Rule", Value: `"cont" "/^\s+at /" "cont"`},
+	}}
+	spec := &fb.FluentBitSpec{Parser: []fb.Plugin{toCRDPlugin(PipelineParsers, java)}}
+	input := FluentbitPipelinePlugin{Name: "files", Plugin: "tail", Parameters: []fb.Parameter{
+		{Name: "Tag", Value: "files"},
+		{Name: "Path", Value: "/var/log/*.log"},
+		{Name: "Multiline.Parser", Value: "java-stack"},
+	}}
+
+	tests := []struct {
+		image string
+		valid bool
+	}{
+		{"kubesphere/fluent-bit:v1.8.3", true},
+		{"registry.local:5000/fluent/fluent-bit:1.9", true},
+		{"fluent/fluent-bit:latest", true},
+		{"kubesphere/fluent-bit:v1.4.6", false},
+		{"registry.local:5000/fluent/fluent-bit:1.7.9-debug", false},
+	}
+
+	for i, test := range tests {
+		restore := useFluentbitImage(test.image)
+		for _, check := range []struct {
+			section string
+			plugin  FluentbitPipelinePlugin
+		}{{PipelineParsers, java}, {PipelineInputs, input}} {
+			err := validatePipelinePlugin(spec, check.section, check.plugin)
+			if test.valid && err != nil {
+				t.Errorf("case %d: unexpected error %v", i, err)
+			}
+			if !test.valid && statusOf(err) != http.StatusBadRequest {
+				t.Errorf("case %d: expected bad request, got %v", i, err)
+			}
+		}
+		restore()
+	}
+}
+
+func TestPreviewParsers(t *testing.T) {
+	defer useFluentbitImage("kubesphere/fluent-bit:v1.8.3")()
+
+	spec := &fb.FluentBitSpec{}
+	parsers := []FluentbitPipelinePlugin{
+		{Name: "access", Plugin: "regex", Parameters: []fb.Parameter{
+			{Name: "Regex", Value: `^(?<method>[A-Z]+) (?<path>[^ ]+) (?<code>\d+) (?<time>[\d.]+)$`},
+			{Name: "Types", Value: "code:integer time:float"},
+		}},
+		{Name: "json-log", Plugin: "json"},
+		{Name: "java-stack", Plugin: "multiline", Parameters: []fb.Parameter{
+			{Name: "Rule", Value: `"start_state" "/^\d{4}-\d{2}-\d{2}/" "cont"`},
+			{Name: "Rule", Value: `"cont" "/^\s+at /" "cont"`},
+		}},
+	}
+	for _, parser := range parsers {
+		if err := insertPipelinePlugin(spec, PipelineParsers, parser); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+
+	tests := []struct {
+		request  ParserPreviewRequest
+		expected []ParserPreview
+	}{
+		{
+			ParserPreviewRequest{Log: "GET /healthz 200 0.25", Parsers: []string{"access", "json-log"}},
+			[]ParserPreview{
+				{Parser: "access", Matched: true, Fields: map[string]interface{}{"method": "GET", "path": "/healthz", "code": int64(200), "time": 0.25}},
+				{Parser: "json-log"},
+			},
+		},
+		{
+			ParserPreviewRequest{Log: `{"level":"info","msg":"started"}`, Parsers: []string{"json-log"}},
+			[]ParserPreview{{Parser: "json-log", Matched: true, Fields: map[string]interface{}{"level": "info", "msg": "started"}}},
+		},
+		{
+			ParserPreviewRequest{Log: "2019-05-01 error\n  at Main.run\n  at Main.main\n2019-05-01 done\n", Parsers: []string{"java-stack"}},
+			[]ParserPreview{{Parser: "java-stack", Matched: true, Records: []string{"2019-05-01 error\n  at Main.run\n  at Main.main", "2019-05-01 done"}}},
+		},
+	}
+
+	for i, test := range tests {
+		results, err := previewParsers(spec.Parser, test.request)
+		if err != nil {
+			t.Errorf("case %d: unexpected error %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(results, test.expected) {
+			t.Errorf("case %d: expected %v, got %v", i, test.expected, results)
+		}
+	}
+
+	if _, err := previewParsers(spec.Parser, ParserPreviewRequest{Log: "line", Parsers: []string{"missing"}}); err == nil {
+		t.Errorf("expected error previewing a missing parser")
+	}
+
+	lookahead := FluentbitPipelinePlugin{Name: "not-health", Plugin: "regex", Parameters: []fb.Parameter{{Name: "Regex", Value: `^(?<path>(?!/healthz)[^ ]+)$`}}}
+	if err := insertPipelinePlugin(spec, PipelineParsers, lookahead); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	results, err := previewParsers(spec.Parser, ParserPreviewRequest{Log: "/metrics", Parsers: []string{"not-health"}})
+	if err != nil || len(results) != 1 || results[0].Error == "" {
+		t.Errorf("expected the preview to report regexes go doesn't support, got %v, %v", results, err)
+	}
+}
+
+func useFluentbitImage(image string) func() {
+	origin := fluentbitImage
+	fluentbitImage = func() (string, error) { return image, nil }
+	return func() { fluentbitImage = origin }
+}
+
+func statusOf(err error) int {
+	if e, ok := err.(*pipelineError); ok {
+		return e.status
+	}
+	return 0
+}
@@ -0,0 +1,294 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	fb "kubesphere.io/kubesphere/pkg/simple/client/fluentbit"
+)
+
+const multilineStartState = "start_state"
+
+type multilineRule struct {
+	from       string
+	expression string
+	// re is nil if the regex uses syntax of onigmo go doesn't support
+	re *regexp.Regexp
+	to string
+}
+
+// FluentbitParserPreview runs the sample log through parsers configured in the fluent bit CRD
+func FluentbitParserPreview(request ParserPreviewRequest) *ParserPreviewResult {
+	crdcs, scheme, err := createCRDClientSet()
+	if err != nil {
+		return &ParserPreviewResult{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	item, err := fb.CrdClient(crdcs, scheme, LoggingNamespace).Get("fluent-bit")
+	if err != nil {
+		return &ParserPreviewResult{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	results, err := previewParsers(item.Spec.Parser, request)
+	if err != nil {
+		return &ParserPreviewResult{Status: http.StatusNotFound, Error: err.Error()}
+	}
+
+	return &ParserPreviewResult{Status: http.StatusOK, Results: results}
+}
+
+func previewParsers(parsers []fb.Plugin, request ParserPreviewRequest) ([]ParserPreview, error) {
+	byName := make(map[string]FluentbitPipelinePlugin)
+	names := make([]string, 0, len(parsers))
+	for _, item := range parsers {
+		plugin := fromCRDPlugin(PipelineParsers, item)
+		name := getParameterValue(item.Parameters, "Name")
+		byName[name] = plugin
+		names = append(names, name)
+	}
+
+	if len(request.Parsers) > 0 {
+		names = request.Parsers
+	}
+
+	results := make([]ParserPreview, 0, len(names))
+	for _, name := range names {
+		plugin, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("parser %s not found", name)
+		}
+
+		preview := runParser(plugin, request.Log)
+		preview.Parser = name
+		results = append(results, preview)
+	}
+
+	return results, nil
+}
+
+func runParser(plugin FluentbitPipelinePlugin, log string) ParserPreview {
+	var preview ParserPreview
+
+	switch plugin.Plugin {
+	case "regex":
+		re, err := compileRegex(getParameterValue(plugin.Parameters, "Regex"))
+		if err != nil {
+			preview.Error = unsupportedPreview(getParameterValue(plugin.Parameters, "Regex"), err)
+			return preview
+		}
+
+		match := re.FindStringSubmatchIndex(strings.TrimRight(log, "\n"))
+		if match == nil {
+			return preview
+		}
+
+		types := parseTypes(getParameterValue(plugin.Parameters, "Types"))
+		preview.Matched = true
+		preview.Fields = make(map[string]interface{})
+		for i, name := range re.SubexpNames() {
+			if name == "" || match[2*i] < 0 {
+				continue
+			}
+			preview.Fields[name] = convertType(log[match[2*i]:match[2*i+1]], types[name])
+		}
+
+	case "json":
+		fields := make(map[string]interface{})
+		if err := json.Unmarshal([]byte(log), &fields); err == nil {
+			preview.Matched = true
+			preview.Fields = fields
+		}
+
+	case "multiline":
+		rules, err := parseMultilineRules(plugin.Parameters)
+		if err != nil {
+			preview.Error = err.Error()
+			return preview
+		}
+		for _, rule := range rules {
+			if rule.re == nil {
+				preview.Error = unsupportedPreview(rule.expression, nil)
+				return preview
+			}
+		}
+		preview.Matched, preview.Records = joinLines(rules, strings.Split(strings.TrimRight(log, "\n"), "\n"))
+
+	default:
+		preview.Error = fmt.Sprintf("unsupported parser %s", plugin.Plugin)
+	}
+
+	return preview
+}
+
+func unsupportedPreview(expression string, err error) string {
+	if err == nil || onigmoOnlyRegexp.MatchString(expression) {
+		return fmt.Sprintf("regex %s is run by fluent bit but can't be previewed", expression)
+	}
+	return err.Error()
+}
+
+// parseTypes parses Types of regex parsers, eg. code:integer size:float
+func parseTypes(value string) map[string]string {
+	types := make(map[string]string)
+	for _, field := range strings.Fields(value) {
+		if parts := strings.SplitN(field, ":", 2); len(parts) == 2 {
+			types[parts[0]] = parts[1]
+		}
+	}
+	return types
+}
+
+func convertType(value, typ string) interface{} {
+	switch typ {
+	case "integer":
+		if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return i
+		}
+	case "float":
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	case "bool":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+// parseMultilineRules parses rules of multiline parsers, each rule is three quoted strings of the state,
+// the regex between slashes and the next state, eg. "start_state" "/^\d{4}-\d{2}-\d{2}/" "cont"
+func parseMultilineRules(parameters []fb.Parameter) ([]multilineRule, error) {
+	var rules []multilineRule
+	states := make(map[string]bool)
+
+	for _, value := range getParameterValues(parameters, "Rule") {
+		fields, err := splitQuoted(value)
+		if err != nil {
+			return nil, err
+		}
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("Rule %s must be in the format \"state\" \"/regex/\" \"next state\"", value)
+		}
+
+		expression := fields[1]
+		if len(expression) < 2 || !strings.HasPrefix(expression, "/") || !strings.HasSuffix(expression, "/") {
+			return nil, fmt.Errorf("regex of Rule %s must be enclosed by slashes", value)
+		}
+
+		expression = expression[1 : len(expression)-1]
+		if _, err := validateRegex(expression); err != nil {
+			return nil, err
+		}
+		re, _ := compileRegex(expression)
+
+		rules = append(rules, multilineRule{from: fields[0], expression: expression, re: re, to: fields[2]})
+		states[fields[0]] = true
+	}
+
+	if !states[multilineStartState] {
+		return nil, fmt.Errorf("a Rule of %s is required by multiline parsers", multilineStartState)
+	}
+
+	for _, rule := range rules {
+		if !states[rule.to] {
+			return nil, fmt.Errorf("state %s has no rules", rule.to)
+		}
+	}
+
+	return rules, nil
+}
+
+func splitQuoted(value string) ([]string, error) {
+	var fields []string
+
+	for value = strings.TrimSpace(value); value != ""; value = strings.TrimSpace(value) {
+		if value[0] != '"' {
+			return nil, fmt.Errorf("Rule %s must consist of quoted strings", value)
+		}
+
+		end := 1
+		for ; end < len(value) && value[end] != '"'; end++ {
+			if value[end] == '\\' {
+				end++
+			}
+		}
+		if end >= len(value) {
+			return nil, fmt.Errorf("unterminated quoted string %s", value)
+		}
+
+		fields = append(fields, strings.Replace(value[1:end], `\"`, `"`, -1))
+		value = value[end+1:]
+	}
+
+	return fields, nil
+}
+
+// joinLines joins lines into records the way fluent bit does, a line matching a rule of the current state
+// continues the record, otherwise it starts a new record. It returns whether any record is started by the rules.
+func joinLines(rules []multilineRule, lines []string) (bool, []string) {
+	var records []string
+	var record []string
+	var state string
+	matched := false
+
+	next := func(from, line string) (string, bool) {
+		for _, rule := range rules {
+			if rule.from == from && rule.re.MatchString(line) {
+				return rule.to, true
+			}
+		}
+		return "", false
+	}
+
+	flush := func() {
+		if len(record) > 0 {
+			records = append(records, strings.Join(record, "\n"))
+			record = nil
+		}
+	}
+
+	for _, line := range lines {
+		if state != "" {
+			if to, ok := next(state, line); ok {
+				record = append(record, line)
+				state = to
+				continue
+			}
+		}
+
+		flush()
+		record = []string{line}
+		state = ""
+
+		if to, ok := next(multilineStartState, line); ok {
+			matched = true
+			state = to
+		}
+	}
+	flush()
+
+	return matched, records
+}
@@ -0,0 +1,331 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+
+package log
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"kubesphere.io/kubesphere/pkg/constants"
+	"kubesphere.io/kubesphere/pkg/informers"
+	es "kubesphere.io/kubesphere/pkg/simple/client/elasticsearch"
+	fb "kubesphere.io/kubesphere/pkg/simple/client/fluentbit"
+	"kubesphere.io/kubesphere/pkg/simple/client/k8s"
+)
+
+const (
+	WorkspaceConfigMapName = fb.WorkspaceConfigMapName
+	WorkspaceConfigMapData = fb.WorkspaceConfigMapData
+)
+
+var indexPrefixRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+func GetWorkspaceLogSettings() ([]WorkspaceLogSettings, error) {
+	configMap, err := informers.SharedInformerFactory().Core().V1().ConfigMaps().Lister().ConfigMaps(LoggingNamespace).Get(WorkspaceConfigMapName)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return fb.ParseWorkspaceLogSettings(configMap.Data[WorkspaceConfigMapData])
+}
+
+func WorkspaceLogSettingsQuery() *WorkspaceLogSettingsResult {
+	settings, err := GetWorkspaceLogSettings()
+	if err != nil {
+		return &WorkspaceLogSettingsResult{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	return &WorkspaceLogSettingsResult{Status: http.StatusOK, Settings: settings}
+}
+
+// WorkspaceLogSettingsUpdate creates or replaces settings of the workspace, and reconfigures fluent bit
+func WorkspaceLogSettingsUpdate(workspace string, settings WorkspaceLogSettings) *WorkspaceLogSettingsResult {
+	all, err := GetWorkspaceLogSettings()
+	if err != nil {
+		return &WorkspaceLogSettingsResult{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	settings.Workspace = workspace
+	settings.Updatetime = time.Now()
+	settings.IndexTime = time.Time{}
+
+	others := make([]WorkspaceLogSettings, 0, len(all))
+	for _, item := range all {
+		if item.Workspace != workspace {
+			others = append(others, item)
+		} else if item.IndexPrefix == settings.IndexPrefix {
+			settings.IndexTime = item.IndexTime
+		}
+	}
+
+	// logs are written to the dedicated indices from now on, earlier logs stay in the shared index
+	if settings.IndexPrefix != "" && settings.IndexTime.IsZero() {
+		settings.IndexTime = settings.Updatetime
+	}
+
+	if err := validateWorkspaceLogSettings(settings, others, es.SharedIndex()); err != nil {
+		return &WorkspaceLogSettingsResult{Status: http.StatusBadRequest, Error: err.Error()}
+	}
+
+	return saveWorkspaceLogSettings(append(others, settings))
+}
+
+func WorkspaceLogSettingsDelete(workspace string) *WorkspaceLogSettingsResult {
+	all, err := GetWorkspaceLogSettings()
+	if err != nil {
+		return &WorkspaceLogSettingsResult{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	others := make([]WorkspaceLogSettings, 0, len(all))
+	for _, item := range all {
+		if item.Workspace != workspace {
+			others = append(others, item)
+		}
+	}
+
+	if len(others) == len(all) {
+		return &WorkspaceLogSettingsResult{Status: http.StatusNotFound, Error: fmt.Sprintf("log settings of workspace %s not found", workspace)}
+	}
+
+	return saveWorkspaceLogSettings(others)
+}
+
+func saveWorkspaceLogSettings(settings []WorkspaceLogSettings) *WorkspaceLogSettingsResult {
+	sort.Slice(settings, func(i, j int) bool {
+		return settings[i].Workspace < settings[j].Workspace
+	})
+
+	if err := updateWorkspaceConfigMap(settings); err != nil {
+		return &WorkspaceLogSettingsResult{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	if err := syncFluentbitWorkspaces(settings); err != nil {
+		return &WorkspaceLogSettingsResult{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	return &WorkspaceLogSettingsResult{Status: http.StatusOK, Settings: settings}
+}
+
+// validateWorkspaceLogSettings makes sure index patterns of workspaces don't overlap,
+// otherwise queries of a workspace may return logs of others
+func validateWorkspaceLogSettings(settings WorkspaceLogSettings, others []WorkspaceLogSettings, sharedIndex string) error {
+	if settings.RetentionDays < 0 {
+		return fmt.Errorf("retention days must not be negative")
+	}
+
+	prefix := settings.IndexPrefix
+	if prefix == "" {
+		return nil
+	}
+
+	if !indexPrefixRegexp.MatchString(prefix) {
+		return fmt.Errorf("invalid index prefix %s, only lowercase letters, digits, '-' and '_' are allowed", prefix)
+	}
+
+	// the shared index is searched by shared*, dedicated indices by prefix-*
+	if sharedIndex != "" && (strings.HasPrefix(prefix, sharedIndex) || strings.HasPrefix(sharedIndex, prefix+"-")) {
+		return fmt.Errorf("index prefix %s overlaps with the shared index %s", prefix, sharedIndex)
+	}
+
+	for _, other := range others {
+		if other.IndexPrefix == "" {
+			continue
+		}
+		if other.IndexPrefix == prefix || strings.HasPrefix(other.IndexPrefix, prefix+"-") || strings.HasPrefix(prefix, other.IndexPrefix+"-") {
+			return fmt.Errorf("index prefix %s overlaps with %s of workspace %s", prefix, other.IndexPrefix, other.Workspace)
+		}
+	}
+
+	return nil
+}
+
+func updateWorkspaceConfigMap(settings []WorkspaceLogSettings) error {
+	data, err := jsonIter.MarshalToString(settings)
+	if err != nil {
+		return err
+	}
+
+	configMapClient := k8s.Client().CoreV1().ConfigMaps(LoggingNamespace)
+
+	configMap, err := configMapClient.Get(WorkspaceConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: WorkspaceConfigMapName},
+			Data:       map[string]string{WorkspaceConfigMapData: data},
+		}
+		_, err = configMapClient.Create(configMap)
+		return err
+	}
+
+	configMap.Data = map[string]string{WorkspaceConfigMapData: data}
+	_, err = configMapClient.Update(configMap)
+	return err
+}
+
+// workspaceNamespaces returns namespaces of each workspace
+func workspaceNamespaces() (map[string][]string, error) {
+	namespaces, err := informers.SharedInformerFactory().Core().V1().Namespaces().Lister().List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string][]string)
+	for _, namespace := range namespaces {
+		if workspace := namespace.Labels[constants.WorkspaceLabelKey]; workspace != "" {
+			result[workspace] = append(result[workspace], namespace.Name)
+		}
+	}
+
+	for _, names := range result {
+		sort.Strings(names)
+	}
+
+	return result, nil
+}
+
+// syncFluentbitWorkspaces reconfigures fluent bit with the settings, namespaces created in workspaces later are
+// synced by the index lifecycle controller of ks-controller-manager
+func syncFluentbitWorkspaces(settings []WorkspaceLogSettings) error {
+	namespaces, err := workspaceNamespaces()
+	if err != nil {
+		return err
+	}
+
+	crdcs, scheme, err := createCRDClientSet()
+	if err != nil {
+		return err
+	}
+
+	crdclient := fb.CrdClient(crdcs, scheme, LoggingNamespace)
+
+	fluentbit, err := crdclient.Get("fluent-bit")
+	if err != nil {
+		return err
+	}
+
+	spec := fluentbit.Spec
+	fb.ApplyWorkspaceLogSettings(&spec, settings, namespaces)
+
+	if reflect.DeepEqual(spec, fluentbit.Spec) {
+		return nil
+	}
+
+	fluentbit.Spec = spec
+	_, err = crdclient.Update("fluent-bit", fluentbit)
+	return err
+}
+
+// keepWorkspaceLogSettings applies workspace log settings to the spec to be updated, so that changes
+// of filters and outputs don't drop them
+func keepWorkspaceLogSettings(spec *fb.FluentBitSpec) {
+	settings, err := GetWorkspaceLogSettings()
+	if err != nil {
+		glog.Errorln(err)
+		return
+	}
+
+	namespaces, err := workspaceNamespaces()
+	if err != nil {
+		glog.Errorln(err)
+		return
+	}
+
+	fb.ApplyWorkspaceLogSettings(spec, settings, namespaces)
+}
+
+// QueryIndices returns dedicated indices of workspaces of the namespaces queried, or of all workspaces
+// if namespaces are not filtered
+func QueryIndices(filled bool, namespaces map[string]string) []es.DedicatedIndex {
+	settings, err := GetWorkspaceLogSettings()
+	if err != nil {
+		glog.Errorln(err)
+		return nil
+	}
+
+	indices := make(map[string]*es.DedicatedIndex)
+	for _, item := range settings {
+		if item.IndexPrefix == "" {
+			continue
+		}
+		// settings saved before the time was recorded, logs may be in the shared index at any time
+		since := int64(math.MaxInt64)
+		if !item.IndexTime.IsZero() {
+			since = item.IndexTime.UnixNano() / int64(time.Millisecond)
+		}
+		indices[item.Workspace] = &es.DedicatedIndex{Prefix: item.IndexPrefix, Since: since}
+	}
+
+	if len(indices) == 0 || filled && len(namespaces) == 0 {
+		return nil
+	}
+
+	found := make(map[string]bool)
+
+	if !filled {
+		for workspace := range indices {
+			found[workspace] = true
+		}
+	}
+
+	nsLister := informers.SharedInformerFactory().Core().V1().Namespaces().Lister()
+
+	for name := range namespaces {
+		ns, err := nsLister.Get(name)
+		if err != nil {
+			continue
+		}
+		workspace := ns.Labels[constants.WorkspaceLabelKey]
+		if index, ok := indices[workspace]; ok {
+			index.Namespaces = append(index.Namespaces, name)
+			found[workspace] = true
+		}
+	}
+
+	workspaces := make([]string, 0, len(found))
+	for workspace := range found {
+		workspaces = append(workspaces, workspace)
+	}
+	sort.Strings(workspaces)
+
+	result := make([]es.DedicatedIndex, 0, len(workspaces))
+	for _, workspace := range workspaces {
+		index := indices[workspace]
+		sort.Strings(index.Namespaces)
+		result = append(result, *index)
+	}
+
+	return result
+}
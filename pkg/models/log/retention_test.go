@@ -0,0 +1,57 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+
+package log
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateWorkspaceLogSettings(t *testing.T) {
+	others := []WorkspaceLogSettings{{Workspace: "finance", IndexPrefix: "ws-finance"}, {Workspace: "shared"}}
+
+	tests := []struct {
+		settings WorkspaceLogSettings
+		err      string
+	}{
+		{settings: WorkspaceLogSettings{RetentionDays: 7}},
+		{settings: WorkspaceLogSettings{IndexPrefix: "ws-hr"}},
+		{settings: WorkspaceLogSettings{RetentionDays: -1}, err: "negative"},
+		{settings: WorkspaceLogSettings{IndexPrefix: "WS"}, err: "invalid index prefix"},
+		{settings: WorkspaceLogSettings{IndexPrefix: "ws-finance"}, err: "overlaps with ws-finance"},
+		{settings: WorkspaceLogSettings{IndexPrefix: "ws"}, err: "overlaps with ws-finance"},
+		{settings: WorkspaceLogSettings{IndexPrefix: "ws-finance-eu"}, err: "overlaps with ws-finance"},
+		{settings: WorkspaceLogSettings{IndexPrefix: "logstash-hr"}, err: "shared index"},
+		{settings: WorkspaceLogSettings{IndexPrefix: "ks"}, err: ""},
+	}
+
+	for _, test := range tests {
+		err := validateWorkspaceLogSettings(test.settings, others, "logstash")
+		switch {
+		case test.err == "" && err != nil:
+			t.Errorf("%+v: unexpected error %v", test.settings, err)
+		case test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)):
+			t.Errorf("%+v: expected error %q, got %v", test.settings, test.err, err)
+		}
+	}
+
+	if err := validateWorkspaceLogSettings(WorkspaceLogSettings{IndexPrefix: "ks"}, nil, "ks-logstash"); err == nil {
+		t.Errorf("expected overlapping with shared index ks-logstash")
+	}
+}
@@ -0,0 +1,264 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+
+package log
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"kubesphere.io/kubesphere/pkg/apis/logging/v1alpha1"
+	"kubesphere.io/kubesphere/pkg/constants"
+	"kubesphere.io/kubesphere/pkg/informers"
+	"kubesphere.io/kubesphere/pkg/models/scoped"
+	"kubesphere.io/kubesphere/pkg/simple/client/k8s"
+)
+
+// saved queries are kept no longer than logs are usually kept
+const maxSavedLogQueryWindow = 30 * 24 * time.Hour
+
+var (
+	// names of namespaces, workloads, pods and containers
+	resourceNameRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9.]*[a-z0-9])?$`)
+)
+
+// SavedLogQueryScope is the workspace or namespace a saved query is shared in
+type SavedLogQueryScope = v1alpha1.SavedLogQueryScope
+
+func savedLogQueryObjectName(scope SavedLogQueryScope, name string) string {
+	return scoped.ObjectName(scope.Workspace, scope.Namespace, name)
+}
+
+func SavedLogQueriesQuery(scope SavedLogQueryScope) *SavedLogQueryResult {
+	objects, err := informers.KsSharedInformerFactory().Logging().V1alpha1().SavedLogQueries().Lister().List(labels.Everything())
+	if err != nil {
+		glog.Errorln(err)
+		return &SavedLogQueryResult{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	queries := make([]SavedLogQuery, 0)
+	for _, object := range objects {
+		if object.Spec.Scope == scope {
+			queries = append(queries, fromSavedLogQueryObject(object))
+		}
+	}
+
+	return &SavedLogQueryResult{Status: http.StatusOK, Queries: queries}
+}
+
+func SavedLogQueryGet(scope SavedLogQueryScope, name string) *SavedLogQueryResult {
+	object, err := informers.KsSharedInformerFactory().Logging().V1alpha1().SavedLogQueries().Lister().Get(savedLogQueryObjectName(scope, name))
+	if err != nil {
+		return savedLogQueryError(err)
+	}
+
+	return &SavedLogQueryResult{Status: http.StatusOK, Queries: []SavedLogQuery{fromSavedLogQueryObject(object)}}
+}
+
+func SavedLogQueryCreate(scope SavedLogQueryScope, query SavedLogQuery, creator string) *SavedLogQueryResult {
+	if err := validateSavedLogQuery(scope, query); err != nil {
+		return &SavedLogQueryResult{Status: http.StatusBadRequest, Error: err.Error()}
+	}
+
+	object := toSavedLogQueryObject(scope, query)
+	if creator != "" {
+		object.Annotations = map[string]string{constants.CreatorAnnotationKey: creator}
+	}
+
+	owners, err := scoped.OwnerReferences(scope.Workspace, scope.Namespace)
+	if err != nil {
+		return savedLogQueryError(err)
+	}
+	object.OwnerReferences = owners
+
+	created, err := k8s.KsClient().LoggingV1alpha1().SavedLogQueries().Create(object)
+	if err != nil {
+		return savedLogQueryError(err)
+	}
+
+	return &SavedLogQueryResult{Status: http.StatusOK, Queries: []SavedLogQuery{fromSavedLogQueryObject(created)}}
+}
+
+// SavedLogQueryUpdate replaces the query, its creator is kept
+func SavedLogQueryUpdate(scope SavedLogQueryScope, name string, query SavedLogQuery) *SavedLogQueryResult {
+	query.Name = name
+	if err := validateSavedLogQuery(scope, query); err != nil {
+		return &SavedLogQueryResult{Status: http.StatusBadRequest, Error: err.Error()}
+	}
+
+	client := k8s.KsClient().LoggingV1alpha1().SavedLogQueries()
+
+	object, err := client.Get(savedLogQueryObjectName(scope, name), metav1.GetOptions{})
+	if err != nil {
+		return savedLogQueryError(err)
+	}
+
+	// queries saved before they were owned by their workspaces and namespaces are adopted
+	owners, err := scoped.OwnerReferences(scope.Workspace, scope.Namespace)
+	if err != nil {
+		return savedLogQueryError(err)
+	}
+
+	object = object.DeepCopy()
+	object.Spec = toSavedLogQueryObject(scope, query).Spec
+	object.OwnerReferences = owners
+
+	updated, err := client.Update(object)
+	if err != nil {
+		return savedLogQueryError(err)
+	}
+
+	return &SavedLogQueryResult{Status: http.StatusOK, Queries: []SavedLogQuery{fromSavedLogQueryObject(updated)}}
+}
+
+func SavedLogQueryDelete(scope SavedLogQueryScope, name string) *SavedLogQueryResult {
+	err := k8s.KsClient().LoggingV1alpha1().SavedLogQueries().Delete(savedLogQueryObjectName(scope, name), &metav1.DeleteOptions{})
+	if err != nil {
+		return savedLogQueryError(err)
+	}
+
+	return &SavedLogQueryResult{Status: http.StatusOK}
+}
+
+func savedLogQueryError(err error) *SavedLogQueryResult {
+	status := scoped.ErrorStatus(err)
+	if status == http.StatusInternalServerError {
+		glog.Errorln(err)
+	}
+	return &SavedLogQueryResult{Status: status, Error: err.Error()}
+}
+
+func validateSavedLogQuery(scope SavedLogQueryScope, query SavedLogQuery) error {
+	if !pluginNameRegexp.MatchString(query.Name) || len(query.Name) > 63 {
+		return fmt.Errorf("invalid query name %q, a DNS-1123 label is required", query.Name)
+	}
+
+	if scope.Workspace == "" && len(query.Namespaces) > 0 {
+		return fmt.Errorf("namespaces are only allowed in queries of workspaces")
+	}
+
+	for _, names := range [][]string{query.Namespaces, query.Workloads, query.Pods, query.Containers} {
+		for _, name := range names {
+			if !resourceNameRegexp.MatchString(name) {
+				return fmt.Errorf("invalid resource name %q", name)
+			}
+		}
+	}
+
+	for _, level := range query.Levels {
+		if level == "" || strings.ContainsAny(level, ",:") {
+			return fmt.Errorf("invalid level %q", level)
+		}
+	}
+
+	window, err := time.ParseDuration(query.Window)
+	if err != nil {
+		return fmt.Errorf("invalid window %q: %v", query.Window, err)
+	}
+	if window <= 0 || window > maxSavedLogQueryWindow {
+		return fmt.Errorf("window must be positive and no longer than %s", maxSavedLogQueryWindow)
+	}
+
+	return nil
+}
+
+func toSavedLogQueryObject(scope SavedLogQueryScope, query SavedLogQuery) *v1alpha1.SavedLogQuery {
+	window, _ := time.ParseDuration(query.Window)
+
+	object := &v1alpha1.SavedLogQuery{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   savedLogQueryObjectName(scope, query.Name),
+			Labels: map[string]string{v1alpha1.SavedLogQueryNameLabel: query.Name},
+		},
+		Spec: v1alpha1.SavedLogQuerySpec{
+			Scope:       scope,
+			Description: query.Description,
+			Namespaces:  query.Namespaces,
+			Workloads:   query.Workloads,
+			Pods:        query.Pods,
+			Containers:  query.Containers,
+			Levels:      query.Levels,
+			LogQuery:    query.LogQuery,
+			Window:      metav1.Duration{Duration: window},
+		},
+	}
+
+	if scope.Workspace != "" {
+		object.Labels[constants.WorkspaceLabelKey] = scope.Workspace
+	}
+
+	return object
+}
+
+func fromSavedLogQueryObject(object *v1alpha1.SavedLogQuery) SavedLogQuery {
+	return SavedLogQuery{
+		Name:        object.Labels[v1alpha1.SavedLogQueryNameLabel],
+		Description: object.Spec.Description,
+		Namespaces:  object.Spec.Namespaces,
+		Workloads:   object.Spec.Workloads,
+		Pods:        object.Spec.Pods,
+		Containers:  object.Spec.Containers,
+		Levels:      object.Spec.Levels,
+		LogQuery:    object.Spec.LogQuery,
+		Window:      object.Spec.Window.Duration.String(),
+		Creator:     object.Annotations[constants.CreatorAnnotationKey],
+		CreateTime:  object.CreationTimestamp.Time,
+	}
+}
+
+// SavedLogQueryValues converts the query to parameters of log queries over the window before now
+func SavedLogQueryValues(query SavedLogQuery, now time.Time) url.Values {
+	values := url.Values{}
+
+	for key, items := range map[string][]string{
+		"namespaces": query.Namespaces,
+		"workloads":  query.Workloads,
+		"pods":       query.Pods,
+		"containers": query.Containers,
+	} {
+		if len(items) > 0 {
+			values.Set(key, strings.Join(items, ","))
+		}
+	}
+
+	if query.LogQuery != "" {
+		values.Set("log_query", query.LogQuery)
+	}
+
+	if len(query.Levels) > 0 {
+		filters := make([]string, 0, len(query.Levels))
+		for _, level := range query.Levels {
+			filters = append(filters, levelField+":"+level)
+		}
+		values.Set("fields", strings.Join(filters, ","))
+	}
+
+	window, _ := time.ParseDuration(query.Window)
+	values.Set("start_time", strconv.FormatInt(now.Add(-window).UnixNano()/int64(time.Millisecond), 10))
+	values.Set("end_time", strconv.FormatInt(now.UnixNano()/int64(time.Millisecond), 10))
+
+	return values
+}
@@ -0,0 +1,111 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+
+package log
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"kubesphere.io/kubesphere/pkg/constants"
+)
+
+func TestValidateSavedLogQuery(t *testing.T) {
+	workspace := SavedLogQueryScope{Workspace: "demo"}
+	namespace := SavedLogQueryScope{Namespace: "payment"}
+
+	tests := []struct {
+		scope SavedLogQueryScope
+		query SavedLogQuery
+		valid bool
+	}{
+		{workspace, SavedLogQuery{Name: "payment-errors", Namespaces: []string{"payment"}, Levels: []string{"error"}, Window: "1h"}, true},
+		{namespace, SavedLogQuery{Name: "api", Workloads: []string{"api-server"}, Containers: []string{"api"}, LogQuery: "timeout", Window: "30m"}, true},
+		{namespace, SavedLogQuery{Name: "api", Namespaces: []string{"payment"}, Window: "30m"}, false},
+		{workspace, SavedLogQuery{Name: "Payment", Window: "1h"}, false},
+		{workspace, SavedLogQuery{Name: "payment", Pods: []string{"API"}, Window: "1h"}, false},
+		{workspace, SavedLogQuery{Name: "payment", Levels: []string{"error,warn"}, Window: "1h"}, false},
+		{workspace, SavedLogQuery{Name: "payment"}, false},
+		{workspace, SavedLogQuery{Name: "payment", Window: "-1h"}, false},
+		{workspace, SavedLogQuery{Name: "payment", Window: "1000h"}, false},
+	}
+
+	for i, test := range tests {
+		err := validateSavedLogQuery(test.scope, test.query)
+		if (err == nil) != test.valid {
+			t.Errorf("case %d: expected valid %v, got error %v", i, test.valid, err)
+		}
+	}
+}
+
+func TestSavedLogQueryObject(t *testing.T) {
+	query := SavedLogQuery{
+		Name:       "payment-errors",
+		Namespaces: []string{"payment"},
+		Workloads:  []string{"api"},
+		Levels:     []string{"error"},
+		LogQuery:   "timeout",
+		Window:     "1h0m0s",
+	}
+
+	object := toSavedLogQueryObject(SavedLogQueryScope{Workspace: "demo"}, query)
+	if object.Name != "workspace.demo.payment-errors" {
+		t.Errorf("unexpected object name %s", object.Name)
+	}
+	if object.Labels[constants.WorkspaceLabelKey] != "demo" {
+		t.Errorf("expected workspace label of demo, got %v", object.Labels)
+	}
+	if got := fromSavedLogQueryObject(object); !reflect.DeepEqual(got, query) {
+		t.Errorf("expected %+v, got %+v", query, got)
+	}
+
+	object = toSavedLogQueryObject(SavedLogQueryScope{Namespace: "demo"}, query)
+	if object.Name != "namespace.demo.payment-errors" {
+		t.Errorf("unexpected object name %s", object.Name)
+	}
+	if _, ok := object.Labels[constants.WorkspaceLabelKey]; ok {
+		t.Errorf("unexpected workspace label of namespace queries")
+	}
+}
+
+func TestSavedLogQueryValues(t *testing.T) {
+	now := time.Unix(1559664000, 0)
+	query := SavedLogQuery{
+		Name:       "payment-errors",
+		Workloads:  []string{"api", "worker"},
+		Containers: []string{"app"},
+		Levels:     []string{"error", "fatal"},
+		LogQuery:   "timeout,refused",
+		Window:     "1h",
+	}
+
+	expected := url.Values{
+		"workloads":  {"api,worker"},
+		"containers": {"app"},
+		"log_query":  {"timeout,refused"},
+		"fields":     {levelField + ":error," + levelField + ":fatal"},
+		"start_time": {"1559660400000"},
+		"end_time":   {"1559664000000"},
+	}
+
+	if got := SavedLogQueryValues(query, now); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
@@ -20,6 +20,7 @@ package log
 
 import (
 	fb "kubesphere.io/kubesphere/pkg/simple/client/fluentbit"
+	"time"
 )
 
 type FluentbitCRDResult struct {
@@ -36,19 +37,71 @@ type FluentbitSettingsResult struct {
 	Enable string `json:"Enable,omitempty"`
 }
 
-type FluentbitFilter struct {
-	Type       string `json:"type"`
-	Field      string `json:"field"`
-	Expression string `json:"expression"`
-}
-
-type FluentbitFiltersResult struct {
-	Status  int               `json:"status"`
-	Filters []FluentbitFilter `json:"filters,omitempty"`
-}
-
 type FluentbitOutputsResult struct {
 	Status  int               `json:"status" description:"response status"`
 	Error   string            `json:"error,omitempty" description:"debug information"`
 	Outputs []fb.OutputPlugin `json:"outputs,omitempty" description:"array of fluent bit output plugins"`
 }
+
+// WorkspaceLogSettings are shared with the index lifecycle controller of ks-controller-manager
+type WorkspaceLogSettings = fb.WorkspaceLogSettings
+
+type WorkspaceLogSettingsResult struct {
+	Status   int                    `json:"status" description:"response status"`
+	Error    string                 `json:"error,omitempty" description:"debug information"`
+	Settings []WorkspaceLogSettings `json:"settings,omitempty" description:"log settings of workspaces"`
+}
+
+// FluentbitPipelinePlugin is an input, parser or filter of fluent bit
+type FluentbitPipelinePlugin struct {
+	Name       string         `json:"name" description:"name of the plugin, unique among plugins of the same section, eg. nginx-access"`
+	Plugin     string         `json:"plugin" description:"one of tail, systemd and forward for inputs, regex, json and multiline for parsers, grep, modify, nest and kubernetes for filters"`
+	Parameters []fb.Parameter `json:"parameters,omitempty" description:"configuration parameters of the plugin, eg. Path of tail inputs. refer to Fluent bit's documents for more configuration parameters."`
+	ReadOnly   bool           `json:"readonly,omitempty" description:"plugins managed by KubeSphere can't be changed through the API"`
+}
+
+type FluentbitPipelineResult struct {
+	Status  int                       `json:"status" description:"response status"`
+	Error   string                    `json:"error,omitempty" description:"debug information"`
+	Plugins []FluentbitPipelinePlugin `json:"plugins,omitempty" description:"plugins of the section in the order they are applied"`
+}
+
+type ParserPreviewRequest struct {
+	Log     string   `json:"log" description:"sample log, multiline parsers join its lines into records"`
+	Parsers []string `json:"parsers,omitempty" description:"names of parsers to run, all parsers configured if it's empty"`
+}
+
+type ParserPreview struct {
+	Parser  string                 `json:"parser" description:"name of the parser"`
+	Matched bool                   `json:"matched" description:"whether the sample log is matched by the parser"`
+	Fields  map[string]interface{} `json:"fields,omitempty" description:"fields parsed by regex and json parsers"`
+	Records []string               `json:"records,omitempty" description:"records joined by multiline parsers"`
+	Error   string                 `json:"error,omitempty" description:"why the parser failed to run"`
+}
+
+type ParserPreviewResult struct {
+	Status  int             `json:"status" description:"response status"`
+	Error   string          `json:"error,omitempty" description:"debug information"`
+	Results []ParserPreview `json:"results,omitempty" description:"results of each parser"`
+}
+
+// SavedLogQuery is a log query shared in a workspace or namespace, eg. a search of runbooks
+type SavedLogQuery struct {
+	Name        string    `json:"name" description:"name of the query, unique in the workspace or namespace, eg. payment-errors"`
+	Description string    `json:"description,omitempty" description:"description of the query"`
+	Namespaces  []string  `json:"namespaces,omitempty" description:"namespaces to query, only for queries of workspaces. all namespaces of the workspace are queried if it's empty"`
+	Workloads   []string  `json:"workloads,omitempty" description:"workloads to query"`
+	Pods        []string  `json:"pods,omitempty" description:"pods to query"`
+	Containers  []string  `json:"containers,omitempty" description:"containers to query"`
+	Levels      []string  `json:"levels,omitempty" description:"values of the level field of structured logs, eg. error"`
+	LogQuery    string    `json:"log_query,omitempty" description:"a comma-separated list of keywords matching log messages, the same as log_query of log queries"`
+	Window      string    `json:"window" description:"time range before the execution to query, eg. 30m, 1h"`
+	Creator     string    `json:"creator,omitempty" description:"user creating the query"`
+	CreateTime  time.Time `json:"create_time,omitempty" description:"creation time"`
+}
+
+type SavedLogQueryResult struct {
+	Status  int             `json:"status" description:"response status"`
+	Error   string          `json:"error,omitempty" description:"debug information"`
+	Queries []SavedLogQuery `json:"queries,omitempty" description:"saved log queries"`
+}
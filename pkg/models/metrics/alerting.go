@@ -0,0 +1,130 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"kubesphere.io/kubesphere/pkg/apis/alerting/v1alpha1"
+	"kubesphere.io/kubesphere/pkg/constants"
+	"kubesphere.io/kubesphere/pkg/utils/sliceutil"
+)
+
+var (
+	alertRuleOperators = []string{">", ">=", "<", "<=", "==", "!="}
+
+	// resource names are put into PromQL, anything else is rejected
+	alertResourceNameRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9.]*[a-z0-9])?$`)
+
+	alertRuleMetrics = map[v1alpha1.AlertRuleLevel][]string{
+		v1alpha1.AlertRuleLevelCluster:   ClusterMetricsNames,
+		v1alpha1.AlertRuleLevelNode:      NodeMetricsNames,
+		v1alpha1.AlertRuleLevelWorkspace: WorkspaceMetricsNames,
+		v1alpha1.AlertRuleLevelNamespace: NamespaceMetricsNames,
+		v1alpha1.AlertRuleLevelWorkload:  WorkloadMetricsNames,
+		v1alpha1.AlertRuleLevelPod:       PodMetricsNames,
+		v1alpha1.AlertRuleLevelContainer: ContainerMetricsNames,
+		v1alpha1.AlertRuleLevelComponent: ComponentMetricsNames,
+	}
+)
+
+type AlertRuleExpression struct {
+	Expression string `json:"expression" description:"PromQL expression of the alert rule"`
+}
+
+// MakeAlertRulePromQL renders the alert rule in the namespace into a PromQL expression.
+// Rules in namespaces of tenants are restricted to metrics of their namespaces,
+// rules of other levels are only allowed in the monitoring system namespace.
+func MakeAlertRulePromQL(namespace string, spec v1alpha1.AlertRuleSpec) (string, error) {
+	if !sliceutil.HasString(alertRuleOperators, spec.Operator) {
+		return "", fmt.Errorf("invalid operator %q, one of %s is required", spec.Operator, strings.Join(alertRuleOperators, " "))
+	}
+
+	if _, err := strconv.ParseFloat(spec.Threshold, 64); err != nil {
+		return "", fmt.Errorf("invalid threshold %q, a number is required", spec.Threshold)
+	}
+
+	names, ok := alertRuleMetrics[spec.Level]
+	if !ok {
+		return "", fmt.Errorf("invalid level %q", spec.Level)
+	}
+
+	if _, ok := RulePromQLTmplMap[spec.Metric]; !ok || !sliceutil.HasString(names, spec.Metric) {
+		return "", fmt.Errorf("metric %q isn't a metric of level %s", spec.Metric, spec.Level)
+	}
+
+	if !isTenantAlertLevel(spec.Level) && namespace != constants.KubeSphereMonitoringNamespace {
+		return "", fmt.Errorf("%s rules are only allowed in namespace %s", spec.Level, constants.KubeSphereMonitoringNamespace)
+	}
+
+	scope := spec.Scope
+	for _, name := range []string{namespace, scope.Workspace, scope.Node, scope.Workload, scope.Pod, scope.Container} {
+		if name != "" && !alertResourceNameRegexp.MatchString(name) {
+			return "", fmt.Errorf("invalid resource name %q", name)
+		}
+	}
+
+	var promql string
+
+	switch spec.Level {
+	case v1alpha1.AlertRuleLevelCluster:
+		promql = MakeClusterRule(spec.Metric)
+	case v1alpha1.AlertRuleLevelNode:
+		promql = MakeNodeRule(scope.Node, "", spec.Metric)
+	case v1alpha1.AlertRuleLevelWorkspace:
+		if scope.Workspace == "" {
+			return "", fmt.Errorf("workspace is required by workspace rules")
+		}
+		promql = MakeSpecificWorkspacePromQL(spec.Metric, Any, scope.Workspace)
+	case v1alpha1.AlertRuleLevelNamespace:
+		promql = MakeNamespacePromQL(namespace, "", spec.Metric)
+	case v1alpha1.AlertRuleLevelWorkload:
+		kind := strings.ToLower(scope.WorkloadKind)
+		if kind != "deployment" && kind != "statefulset" && kind != "daemonset" {
+			return "", fmt.Errorf("workload kind is required by workload rules, one of deployment, statefulset and daemonset")
+		}
+		// replica metrics are of the workload kind in their names
+		if !strings.Contains(spec.Metric, "_pod_") && !strings.Contains(spec.Metric, kind) {
+			return "", fmt.Errorf("metric %s isn't a metric of %s", spec.Metric, kind)
+		}
+		promql = MakeWorkloadPromQL(spec.Metric, namespace, scope.Workload, kind)
+	case v1alpha1.AlertRuleLevelPod:
+		promql = MakePodPromQL(spec.Metric, namespace, "", scope.Pod, "")
+	case v1alpha1.AlertRuleLevelContainer:
+		if scope.Pod == "" {
+			return "", fmt.Errorf("pod is required by container rules")
+		}
+		promql = MakeContainerPromQL(namespace, "", scope.Pod, scope.Container, spec.Metric, "")
+	case v1alpha1.AlertRuleLevelComponent:
+		promql = MakeComponentRule(spec.Metric)
+	}
+
+	return fmt.Sprintf("(%s) %s %s", promql, spec.Operator, spec.Threshold), nil
+}
+
+func isTenantAlertLevel(level v1alpha1.AlertRuleLevel) bool {
+	switch level {
+	case v1alpha1.AlertRuleLevelNamespace, v1alpha1.AlertRuleLevelWorkload, v1alpha1.AlertRuleLevelPod, v1alpha1.AlertRuleLevelContainer:
+		return true
+	}
+	return false
+}
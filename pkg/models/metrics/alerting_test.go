@@ -0,0 +1,80 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+
+package metrics
+
+import (
+	"testing"
+
+	"kubesphere.io/kubesphere/pkg/apis/alerting/v1alpha1"
+)
+
+func TestMakeAlertRulePromQL(t *testing.T) {
+	tests := []struct {
+		namespace string
+		spec      v1alpha1.AlertRuleSpec
+		expected  string
+		valid     bool
+	}{
+		{
+			namespace: "demo",
+			spec:      v1alpha1.AlertRuleSpec{Level: "namespace", Metric: "namespace_memory_usage", Operator: ">", Threshold: "1e9"},
+			expected:  `(namespace:container_memory_usage_bytes:sum{namespace!="", namespace=~"demo"}) > 1e9`,
+			valid:     true,
+		},
+		{
+			namespace: "demo",
+			spec:      v1alpha1.AlertRuleSpec{Level: "workload", Metric: "workload_deployment_unavailable_replicas_ratio", Operator: ">=", Threshold: "0.5", Scope: v1alpha1.AlertRuleScope{WorkloadKind: "Deployment", Workload: "api"}},
+			expected:  `(namespace:deployment_unavailable_replicas:ratio{namespace="demo", deployment=~"^api$"}) >= 0.5`,
+			valid:     true,
+		},
+		{
+			namespace: "demo",
+			spec:      v1alpha1.AlertRuleSpec{Level: "container", Metric: "container_memory_usage", Operator: ">", Threshold: "1e8", Scope: v1alpha1.AlertRuleScope{Pod: "api-0"}},
+			expected:  `(sum(container_memory_usage_bytes{namespace="demo", pod_name="api-0",  container_name!="POD", container_name=~".*"}) by (namespace, pod_name, container_name)) > 1e8`,
+			valid:     true,
+		},
+		{
+			namespace: "kubesphere-monitoring-system",
+			spec:      v1alpha1.AlertRuleSpec{Level: "node", Metric: "node_cpu_utilisation", Operator: ">", Threshold: "0.9", Scope: v1alpha1.AlertRuleScope{Node: "node1"}},
+			expected:  `(node:node_cpu_utilisation:avg1m{node="node1"}) > 0.9`,
+			valid:     true,
+		},
+		// tenants can't alert on metrics of other namespaces
+		{namespace: "demo", spec: v1alpha1.AlertRuleSpec{Level: "node", Metric: "node_cpu_utilisation", Operator: ">", Threshold: "0.9"}},
+		{namespace: "demo", spec: v1alpha1.AlertRuleSpec{Level: "workspace", Metric: "workspace_cpu_usage", Operator: ">", Threshold: "1", Scope: v1alpha1.AlertRuleScope{Workspace: "demo"}}},
+		{namespace: "kubesphere-monitoring-system", spec: v1alpha1.AlertRuleSpec{Level: "workspace", Metric: "workspace_cpu_usage", Operator: ">", Threshold: "1"}},
+		{namespace: "demo", spec: v1alpha1.AlertRuleSpec{Level: "namespace", Metric: "node_cpu_utilisation", Operator: ">", Threshold: "0.9"}},
+		{namespace: "demo", spec: v1alpha1.AlertRuleSpec{Level: "namespace", Metric: "namespace_cpu_usage", Operator: "=~", Threshold: "1"}},
+		{namespace: "demo", spec: v1alpha1.AlertRuleSpec{Level: "namespace", Metric: "namespace_cpu_usage", Operator: ">", Threshold: "1 or vector(1)"}},
+		{namespace: "demo", spec: v1alpha1.AlertRuleSpec{Level: "pod", Metric: "pod_cpu_usage", Operator: ">", Threshold: "1", Scope: v1alpha1.AlertRuleScope{Pod: `a"}`}}},
+		{namespace: "demo", spec: v1alpha1.AlertRuleSpec{Level: "workload", Metric: "workload_statefulset_replica", Operator: "<", Threshold: "1", Scope: v1alpha1.AlertRuleScope{WorkloadKind: "deployment"}}},
+		{namespace: "demo", spec: v1alpha1.AlertRuleSpec{Level: "container", Metric: "container_cpu_usage", Operator: ">", Threshold: "1"}},
+	}
+
+	for i, test := range tests {
+		expression, err := MakeAlertRulePromQL(test.namespace, test.spec)
+		if (err == nil) != test.valid {
+			t.Errorf("case %d: expected valid %v, got error %v", i, test.valid, err)
+			continue
+		}
+		if expression != test.expected {
+			t.Errorf("case %d: expected %s, got %s", i, test.expected, expression)
+		}
+	}
+}
@@ -0,0 +1,342 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apiserver/pkg/authentication/user"
+
+	"kubesphere.io/kubesphere/pkg/apis/monitoring/v1alpha1"
+	"kubesphere.io/kubesphere/pkg/constants"
+	"kubesphere.io/kubesphere/pkg/informers"
+	"kubesphere.io/kubesphere/pkg/models/scoped"
+	"kubesphere.io/kubesphere/pkg/models/workspaces"
+	"kubesphere.io/kubesphere/pkg/simple/client/k8s"
+	client "kubesphere.io/kubesphere/pkg/simple/client/prometheus"
+	"kubesphere.io/kubesphere/pkg/utils/sliceutil"
+)
+
+// panels of a dashboard are evaluated concurrently in one render request
+const maxDashboardPanels = 50
+
+var dashboardNameRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// DashboardScope is the workspace or namespace a dashboard is shared in
+type DashboardScope = v1alpha1.DashboardScope
+
+type Dashboard struct {
+	Name        string           `json:"name" description:"name of the dashboard, unique in its workspace or namespace"`
+	Description string           `json:"description,omitempty" description:"description of the dashboard"`
+	Panels      []v1alpha1.Panel `json:"panels,omitempty" description:"panels of the dashboard, each one shows a metric name of the level of the dashboard or a PromQL expression"`
+	Creator     string           `json:"creator,omitempty" description:"creator of the dashboard"`
+	CreateTime  time.Time        `json:"create_time,omitempty" description:"creation time of the dashboard"`
+}
+
+type DashboardResult struct {
+	Status     int         `json:"status" description:"response status"`
+	Error      string      `json:"error,omitempty" description:"debug information"`
+	Dashboards []Dashboard `json:"dashboards,omitempty" description:"dashboards"`
+}
+
+type PanelResult struct {
+	Title  string               `json:"title" description:"title of the panel"`
+	Unit   string               `json:"unit,omitempty" description:"unit of values"`
+	Layout v1alpha1.PanelLayout `json:"layout" description:"position and size of the panel"`
+	Error  string               `json:"error,omitempty" description:"error of evaluating the panel, other panels are still rendered"`
+	Query  string               `json:"query,omitempty" description:"PromQL expression evaluated by Prometheus"`
+	Data   *FormatedMetricData  `json:"data,omitempty" description:"result of the panel, the same as the one of Prometheus"`
+}
+
+type DashboardRenderResult struct {
+	Status int           `json:"status" description:"response status"`
+	Error  string        `json:"error,omitempty" description:"debug information"`
+	Panels []PanelResult `json:"panels,omitempty" description:"results of panels, in the order of panels of the dashboard"`
+}
+
+func dashboardObjectName(scope DashboardScope, name string) string {
+	return scoped.ObjectName(scope.Workspace, scope.Namespace, name)
+}
+
+func ListDashboards(scope DashboardScope) *DashboardResult {
+	objects, err := informers.KsSharedInformerFactory().Monitoring().V1alpha1().Dashboards().Lister().List(labels.Everything())
+	if err != nil {
+		glog.Errorln(err)
+		return &DashboardResult{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	dashboards := make([]Dashboard, 0)
+	for _, object := range objects {
+		if object.Spec.Scope == scope {
+			dashboards = append(dashboards, fromDashboardObject(object))
+		}
+	}
+
+	return &DashboardResult{Status: http.StatusOK, Dashboards: dashboards}
+}
+
+func GetDashboard(scope DashboardScope, name string) *DashboardResult {
+	object, err := informers.KsSharedInformerFactory().Monitoring().V1alpha1().Dashboards().Lister().Get(dashboardObjectName(scope, name))
+	if err != nil {
+		return dashboardError(err)
+	}
+
+	return &DashboardResult{Status: http.StatusOK, Dashboards: []Dashboard{fromDashboardObject(object)}}
+}
+
+func CreateDashboard(scope DashboardScope, dashboard Dashboard, creator string) *DashboardResult {
+	if err := validateDashboard(scope, dashboard); err != nil {
+		return &DashboardResult{Status: http.StatusBadRequest, Error: err.Error()}
+	}
+
+	object := toDashboardObject(scope, dashboard)
+	if creator != "" {
+		object.Annotations = map[string]string{constants.CreatorAnnotationKey: creator}
+	}
+
+	owners, err := scoped.OwnerReferences(scope.Workspace, scope.Namespace)
+	if err != nil {
+		return dashboardError(err)
+	}
+	object.OwnerReferences = owners
+
+	created, err := k8s.KsClient().MonitoringV1alpha1().Dashboards().Create(object)
+	if err != nil {
+		return dashboardError(err)
+	}
+
+	return &DashboardResult{Status: http.StatusOK, Dashboards: []Dashboard{fromDashboardObject(created)}}
+}
+
+// UpdateDashboard replaces the dashboard, its creator is kept
+func UpdateDashboard(scope DashboardScope, name string, dashboard Dashboard) *DashboardResult {
+	dashboard.Name = name
+	if err := validateDashboard(scope, dashboard); err != nil {
+		return &DashboardResult{Status: http.StatusBadRequest, Error: err.Error()}
+	}
+
+	dashboards := k8s.KsClient().MonitoringV1alpha1().Dashboards()
+
+	object, err := dashboards.Get(dashboardObjectName(scope, name), metav1.GetOptions{})
+	if err != nil {
+		return dashboardError(err)
+	}
+
+	// dashboards created before they were owned by their workspaces and namespaces are adopted
+	owners, err := scoped.OwnerReferences(scope.Workspace, scope.Namespace)
+	if err != nil {
+		return dashboardError(err)
+	}
+
+	object = object.DeepCopy()
+	object.Spec = toDashboardObject(scope, dashboard).Spec
+	object.OwnerReferences = owners
+
+	updated, err := dashboards.Update(object)
+	if err != nil {
+		return dashboardError(err)
+	}
+
+	return &DashboardResult{Status: http.StatusOK, Dashboards: []Dashboard{fromDashboardObject(updated)}}
+}
+
+func DeleteDashboard(scope DashboardScope, name string) *DashboardResult {
+	err := k8s.KsClient().MonitoringV1alpha1().Dashboards().Delete(dashboardObjectName(scope, name), &metav1.DeleteOptions{})
+	if err != nil {
+		return dashboardError(err)
+	}
+
+	return &DashboardResult{Status: http.StatusOK}
+}
+
+// RenderDashboard evaluates all panels of the dashboard concurrently at the time or over the time range of the query,
+// PromQL expressions of panels are restricted to namespaces the user can access in the scope
+func RenderDashboard(usr user.Info, scope DashboardScope, name string, query PromQLQuery) *DashboardRenderResult {
+	saved := GetDashboard(scope, name)
+	if saved.Status != http.StatusOK {
+		return &DashboardRenderResult{Status: saved.Status, Error: saved.Error}
+	}
+	dashboard := saved.Dashboards[0]
+
+	queryType, values, err := promQLQueryParams(query)
+	if err != nil {
+		return &DashboardRenderResult{Status: http.StatusBadRequest, Error: err.Error()}
+	}
+
+	// namespaces of PromQL panels
+	var accessible []string
+	for _, panel := range dashboard.Panels {
+		if panel.Query != "" {
+			accessible, err = promQLNamespaces(usr, PromQLScope{Workspace: scope.Workspace, Namespace: scope.Namespace})
+			if err != nil {
+				glog.Errorln(err)
+				return &DashboardRenderResult{Status: http.StatusInternalServerError, Error: err.Error()}
+			}
+			break
+		}
+	}
+
+	// namespaces of workspace metrics, the same as workspace level metrics
+	var workspaceNamespaces []string
+	if scope.Workspace != "" {
+		workspaceNamespaces, err = workspaces.WorkspaceNamespaces(scope.Workspace)
+		if err != nil {
+			glog.Errorln(err)
+			return &DashboardRenderResult{Status: http.StatusInternalServerError, Error: err.Error()}
+		}
+	}
+
+	monitoringRequest := &client.MonitoringRequestParams{QueryType: queryType, Params: values}
+
+	results := make([]PanelResult, len(dashboard.Panels))
+	var wg sync.WaitGroup
+
+	for i, panel := range dashboard.Panels {
+		wg.Add(1)
+		go func(i int, panel v1alpha1.Panel) {
+			defer wg.Done()
+
+			result := PanelResult{Title: panel.Title, Unit: panel.Unit, Layout: panel.Layout}
+
+			if panel.Query != "" {
+				if len(accessible) == 0 {
+					result.Error = "no namespaces are accessible"
+				} else {
+					res := evaluatePromQL(panel.Query, accessible, queryType, values)
+					result.Query, result.Data, result.Error = res.Query, res.Data, res.Error
+				}
+				results[i] = result
+				return
+			}
+
+			var metricQueryType, params string
+			if scope.Workspace != "" {
+				metricQueryType, params = AssembleSpecificWorkspaceMetricRequestInfo(monitoringRequest, workspaceNamespaces, scope.Workspace, panel.Metric)
+			} else {
+				metricQueryType, params = AssembleNamespaceMetricRequestInfoByNamesapce(monitoringRequest, scope.Namespace, panel.Metric)
+			}
+
+			metric := ReformatJson(client.SendMonitoringRequest(client.PrometheusEndpoint, metricQueryType, params), panel.Metric, nil)
+			if metric.Status == MetricStatusSuccess {
+				result.Data = &metric.Data
+			} else {
+				result.Error = fmt.Sprintf("failed to query metric %s", panel.Metric)
+			}
+			results[i] = result
+		}(i, panel)
+	}
+
+	wg.Wait()
+
+	return &DashboardRenderResult{Status: http.StatusOK, Panels: results}
+}
+
+func dashboardError(err error) *DashboardResult {
+	status := scoped.ErrorStatus(err)
+	if status == http.StatusInternalServerError {
+		glog.Errorln(err)
+	}
+	return &DashboardResult{Status: status, Error: err.Error()}
+}
+
+// dashboardMetrics returns metric names available to panels of dashboards of the scope
+func dashboardMetrics(scope DashboardScope) []string {
+	if scope.Workspace != "" {
+		return WorkspaceMetricsNames
+	}
+	return NamespaceMetricsNames
+}
+
+func validateDashboard(scope DashboardScope, dashboard Dashboard) error {
+	if !dashboardNameRegexp.MatchString(dashboard.Name) || len(dashboard.Name) > 63 {
+		return fmt.Errorf("invalid dashboard name %q, a DNS-1123 label is required", dashboard.Name)
+	}
+
+	if len(dashboard.Panels) > maxDashboardPanels {
+		return fmt.Errorf("a dashboard has at most %d panels", maxDashboardPanels)
+	}
+
+	metrics := dashboardMetrics(scope)
+
+	for i, panel := range dashboard.Panels {
+		if panel.Title == "" {
+			return fmt.Errorf("title of panel %d is required", i)
+		}
+
+		switch {
+		case (panel.Metric == "") == (panel.Query == ""):
+			return fmt.Errorf("exactly one of metric and query of panel %q is required", panel.Title)
+		case panel.Metric != "":
+			if _, ok := RulePromQLTmplMap[panel.Metric]; !ok || !sliceutil.HasString(metrics, panel.Metric) || panel.Metric == MetricNameWorkspaceAllProjectCount {
+				return fmt.Errorf("metric %q of panel %q isn't a metric of the dashboard level", panel.Metric, panel.Title)
+			}
+		default:
+			if len(panel.Query) > promqlMaxQueryLength {
+				return fmt.Errorf("query of panel %q exceeds the limit of %d characters", panel.Title, promqlMaxQueryLength)
+			}
+			// namespaces are injected when the dashboard is rendered, only the expression is validated here
+			if _, err := InjectLabelMatcher(panel.Query, namespaceMatcher(nil), promqlMaxLookback); err != nil {
+				return fmt.Errorf("invalid query of panel %q: %s", panel.Title, err)
+			}
+		}
+
+		if layout := panel.Layout; layout.X < 0 || layout.Y < 0 || layout.Width < 0 || layout.Height < 0 {
+			return fmt.Errorf("layout of panel %q must not be negative", panel.Title)
+		}
+	}
+
+	return nil
+}
+
+func toDashboardObject(scope DashboardScope, dashboard Dashboard) *v1alpha1.Dashboard {
+	object := &v1alpha1.Dashboard{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   dashboardObjectName(scope, dashboard.Name),
+			Labels: map[string]string{v1alpha1.DashboardNameLabel: dashboard.Name},
+		},
+		Spec: v1alpha1.DashboardSpec{
+			Scope:       scope,
+			Description: dashboard.Description,
+			Panels:      dashboard.Panels,
+		},
+	}
+
+	if scope.Workspace != "" {
+		object.Labels[constants.WorkspaceLabelKey] = scope.Workspace
+	}
+
+	return object
+}
+
+func fromDashboardObject(object *v1alpha1.Dashboard) Dashboard {
+	return Dashboard{
+		Name:        object.Labels[v1alpha1.DashboardNameLabel],
+		Description: object.Spec.Description,
+		Panels:      object.Spec.Panels,
+		Creator:     object.Annotations[constants.CreatorAnnotationKey],
+		CreateTime:  object.CreationTimestamp.Time,
+	}
+}
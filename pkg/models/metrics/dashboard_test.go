@@ -0,0 +1,119 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+
+package metrics
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"kubesphere.io/kubesphere/pkg/apis/monitoring/v1alpha1"
+)
+
+func TestValidateDashboard(t *testing.T) {
+	namespace := DashboardScope{Namespace: "demo"}
+	workspace := DashboardScope{Workspace: "ws"}
+
+	tests := []struct {
+		scope     DashboardScope
+		dashboard Dashboard
+		valid     bool
+	}{
+		{
+			scope: namespace,
+			dashboard: Dashboard{Name: "web", Panels: []v1alpha1.Panel{
+				{Title: "Memory", Metric: "namespace_memory_usage", Unit: "bytes", Layout: v1alpha1.PanelLayout{Width: 6, Height: 4}},
+				{Title: "Requests", Query: `sum(rate(http_requests_total[5m])) by (pod)`},
+			}},
+			valid: true,
+		},
+		{
+			scope:     workspace,
+			dashboard: Dashboard{Name: "overview", Panels: []v1alpha1.Panel{{Title: "Memory", Metric: "workspace_memory_usage"}}},
+			valid:     true,
+		},
+		{
+			scope:     workspace,
+			dashboard: Dashboard{Name: "overview", Panels: []v1alpha1.Panel{{Title: "Memory", Metric: "namespace_memory_usage"}}},
+		},
+		{
+			scope:     namespace,
+			dashboard: Dashboard{Name: "web", Panels: []v1alpha1.Panel{{Title: "Nodes", Metric: "node_cpu_utilisation"}}},
+		},
+		{
+			scope:     namespace,
+			dashboard: Dashboard{Name: "Web"},
+		},
+		{
+			scope:     namespace,
+			dashboard: Dashboard{Name: "web", Panels: []v1alpha1.Panel{{Metric: "namespace_memory_usage"}}},
+		},
+		{
+			scope:     namespace,
+			dashboard: Dashboard{Name: "web", Panels: []v1alpha1.Panel{{Title: "Both", Metric: "namespace_memory_usage", Query: "up"}}},
+		},
+		{
+			scope:     namespace,
+			dashboard: Dashboard{Name: "web", Panels: []v1alpha1.Panel{{Title: "None"}}},
+		},
+		{
+			scope:     namespace,
+			dashboard: Dashboard{Name: "web", Panels: []v1alpha1.Panel{{Title: "Invalid", Query: "up{"}}},
+		},
+		{
+			scope:     namespace,
+			dashboard: Dashboard{Name: "web", Panels: []v1alpha1.Panel{{Title: "Negative", Query: "up", Layout: v1alpha1.PanelLayout{X: -1}}}},
+		},
+		{
+			scope:     namespace,
+			dashboard: Dashboard{Name: "web", Panels: make([]v1alpha1.Panel, maxDashboardPanels+1)},
+		},
+	}
+
+	for i, test := range tests {
+		err := validateDashboard(test.scope, test.dashboard)
+		if test.valid != (err == nil) {
+			t.Errorf("case %d: expected valid %v, got %v", i, test.valid, err)
+		}
+	}
+}
+
+func TestDashboardObject(t *testing.T) {
+	dashboard := Dashboard{
+		Name:        "overview",
+		Description: "overview of the workspace",
+		Panels:      []v1alpha1.Panel{{Title: "Memory", Metric: "workspace_memory_usage"}},
+	}
+
+	object := toDashboardObject(DashboardScope{Workspace: "ws"}, dashboard)
+	if object.Name != "workspace.ws.overview" {
+		t.Errorf("unexpected object name %s", object.Name)
+	}
+	if object.Labels["kubesphere.io/workspace"] != "ws" {
+		t.Errorf("unexpected labels %v", object.Labels)
+	}
+
+	if converted := fromDashboardObject(object); !reflect.DeepEqual(converted, dashboard) {
+		t.Errorf("expected %+v, got %+v", dashboard, converted)
+	}
+
+	if name := dashboardObjectName(DashboardScope{Namespace: "demo"}, "overview"); !strings.HasPrefix(name, "namespace.demo.") {
+		t.Errorf("unexpected object name %s", name)
+	}
+}
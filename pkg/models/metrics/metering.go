@@ -0,0 +1,398 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+
+package metrics
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/prometheus/common/model"
+	"k8s.io/apimachinery/pkg/api/errors"
+
+	"kubesphere.io/kubesphere/pkg/apis/monitoring/v1alpha1"
+	"kubesphere.io/kubesphere/pkg/constants"
+	"kubesphere.io/kubesphere/pkg/informers"
+	"kubesphere.io/kubesphere/pkg/simple/client/metering"
+	client "kubesphere.io/kubesphere/pkg/simple/client/prometheus"
+)
+
+const (
+	MeteringPeriodDaily   = "daily"
+	MeteringPeriodMonthly = "monthly"
+
+	meteringMonthLayout   = "2006-01"
+	meteringPriceSheetKey = "prices"
+	maxMeteringDays       = 366
+)
+
+var (
+	meteringStep           time.Duration
+	meteringBackfillDays   int
+	meteringPriceConfigMap string
+	meteringGPUResource    string
+)
+
+func init() {
+	flag.DurationVar(&meteringStep, "metering-step", 5*time.Minute, "resolution of integrating resource usage over a day, network usage of namespaces is integrated from rates over 5m")
+	flag.IntVar(&meteringBackfillDays, "metering-backfill-days", 7, "days before today metered from Prometheus if their rollups are missing, should be within the retention of Prometheus")
+	flag.StringVar(&meteringPriceConfigMap, "metering-price-configmap", "metering-price-sheet", "configmap in kubesphere-system with the price sheet of chargeback reports")
+	flag.StringVar(&meteringGPUResource, "metering-gpu-resource", "nvidia_com_gpu", "resource label of GPUs in kube_pod_container_resource_requests")
+}
+
+// MeteringScope restricts records of reports, records of all workspaces if both are empty
+type MeteringScope struct {
+	Workspace string
+	Namespace string
+}
+
+// MeteringQuery selects days of reports, start and end are dates in the format of 2006-01-02 and both are included
+type MeteringQuery struct {
+	Start  string
+	End    string
+	Period string
+	Level  string
+}
+
+// PriceSheet is the price of units of resource usage in reports, it's the json in the key prices of the configmap,
+// eg. {"currency": "USD", "cpu_core_hour": 0.03, "memory_gib_hour": 0.004}
+type PriceSheet struct {
+	Currency       string  `json:"currency,omitempty" description:"currency of prices, eg. USD"`
+	CPUCoreHour    float64 `json:"cpu_core_hour" description:"price of a CPU core hour"`
+	MemoryGiBHour  float64 `json:"memory_gib_hour" description:"price of a GiB hour of memory"`
+	StorageGiBHour float64 `json:"storage_gib_hour" description:"price of a GiB hour of storage requested by persistent volume claims"`
+	NetworkGiB     float64 `json:"network_gib" description:"price of a GiB of network traffic"`
+	GPUHour        float64 `json:"gpu_hour" description:"price of a GPU hour"`
+}
+
+type ResourceCost struct {
+	CPU     float64 `json:"cpu" description:"cost of CPU usage"`
+	Memory  float64 `json:"memory" description:"cost of memory usage"`
+	Storage float64 `json:"storage" description:"cost of storage requests"`
+	Network float64 `json:"network" description:"cost of network traffic"`
+	GPU     float64 `json:"gpu" description:"cost of GPU requests"`
+	Total   float64 `json:"total" description:"total cost"`
+}
+
+type MeteringRecord struct {
+	Period    string                 `json:"period" description:"day or month of the record, eg. 2019-06-01 and 2019-06"`
+	Level     string                 `json:"level" description:"one of workspace, namespace and workload"`
+	Workspace string                 `json:"workspace,omitempty" description:"workspace of the namespace when it was metered"`
+	Namespace string                 `json:"namespace,omitempty" description:"namespace"`
+	Workload  string                 `json:"workload,omitempty" description:"workload in the format of <kind>:<name>"`
+	Usage     v1alpha1.ResourceUsage `json:"usage" description:"resource usage over the period"`
+	Cost      ResourceCost           `json:"cost" description:"cost of the usage by the price sheet"`
+}
+
+type MeteringReport struct {
+	Status  int              `json:"status" description:"response status"`
+	Error   string           `json:"error,omitempty" description:"debug information"`
+	Prices  *PriceSheet      `json:"prices,omitempty" description:"price sheet of costs"`
+	Records []MeteringRecord `json:"records,omitempty" description:"records ordered by period, workspace, namespace and workload"`
+	Total   ResourceCost     `json:"total" description:"total cost of records"`
+}
+
+// meteringDay is the usage of a day, from its rollup or metered from Prometheus
+type meteringDay struct {
+	date    time.Time
+	entries []v1alpha1.MeteringEntry
+}
+
+// GetMeteringReport returns usage and costs of the scope per day or month, days without rollups
+// are metered from Prometheus if they are in the backfill window, eg. today
+func GetMeteringReport(scope MeteringScope, query MeteringQuery) *MeteringReport {
+	start, end, err := parseMeteringQuery(scope, &query)
+	if err != nil {
+		return &MeteringReport{Status: http.StatusBadRequest, Error: err.Error()}
+	}
+
+	prices, err := GetPriceSheet()
+	if err != nil {
+		glog.Errorln(err)
+		return &MeteringReport{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	now := time.Now()
+	today := metering.Date(now)
+	meter := &metering.Meter{Query: queryMeteringMatrix, Step: meteringStep, GPUResource: meteringGPUResource, Timeout: promqlMaxTimeout}
+
+	var days []meteringDay
+	for day := start; !day.After(end) && !day.After(today); day = day.AddDate(0, 0, 1) {
+		rollups, err := listMeteringRollups(day)
+		if err != nil {
+			glog.Errorln(err)
+			return &MeteringReport{Status: http.StatusInternalServerError, Error: err.Error()}
+		}
+		if entries, complete := metering.MergeRollups(rollups); complete {
+			days = append(days, meteringDay{date: day, entries: entries})
+			continue
+		}
+
+		if day.Before(today.AddDate(0, 0, -meteringBackfillDays)) {
+			continue
+		}
+
+		dayEnd := day.AddDate(0, 0, 1)
+		if dayEnd.After(now) {
+			dayEnd = now
+		}
+		entries, err := meter.MeterUsage(day, dayEnd)
+		if err != nil {
+			glog.Errorln(err)
+			return &MeteringReport{Status: http.StatusBadGateway, Error: err.Error()}
+		}
+		days = append(days, meteringDay{date: day, entries: entries})
+	}
+
+	records := buildMeteringRecords(days, scope, query, prices)
+
+	report := &MeteringReport{Status: http.StatusOK, Prices: &prices, Records: records}
+	for _, record := range records {
+		report.Total = addCost(report.Total, record.Cost)
+	}
+
+	return report
+}
+
+// GetPriceSheet returns the price sheet in the configmap, all prices are zero if it doesn't exist
+func GetPriceSheet() (PriceSheet, error) {
+	var prices PriceSheet
+
+	configMap, err := informers.SharedInformerFactory().Core().V1().ConfigMaps().Lister().ConfigMaps(constants.KubeSphereNamespace).Get(meteringPriceConfigMap)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return prices, nil
+		}
+		return prices, err
+	}
+
+	return parsePriceSheet(configMap.Data[meteringPriceSheetKey])
+}
+
+func parsePriceSheet(data string) (PriceSheet, error) {
+	var prices PriceSheet
+	if data == "" {
+		return prices, nil
+	}
+
+	if err := jsonIter.UnmarshalFromString(data, &prices); err != nil {
+		return prices, fmt.Errorf("invalid price sheet: %s", err)
+	}
+
+	for _, price := range []float64{prices.CPUCoreHour, prices.MemoryGiBHour, prices.StorageGiBHour, prices.NetworkGiB, prices.GPUHour} {
+		if price < 0 {
+			return prices, fmt.Errorf("invalid price sheet: prices must not be negative")
+		}
+	}
+
+	return prices, nil
+}
+
+// WriteMeteringCSV writes records of the report as CSV with a header line
+func WriteMeteringCSV(w io.Writer, report *MeteringReport) error {
+	currency := ""
+	if report.Prices != nil {
+		currency = report.Prices.Currency
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"period", "level", "workspace", "namespace", "workload",
+		"cpu_core_hours", "memory_gib_hours", "storage_gib_hours", "network_gib", "gpu_hours",
+		"cpu_cost", "memory_cost", "storage_cost", "network_cost", "gpu_cost", "total_cost", "currency"})
+
+	for _, record := range report.Records {
+		usage, cost := record.Usage, record.Cost
+		writer.Write([]string{record.Period, record.Level, record.Workspace, record.Namespace, record.Workload,
+			formatMeteringValue(usage.CPUCoreHours), formatMeteringValue(usage.MemoryGiBHours), formatMeteringValue(usage.StorageGiBHours),
+			formatMeteringValue(usage.NetworkGiB), formatMeteringValue(usage.GPUHours),
+			formatMeteringValue(cost.CPU), formatMeteringValue(cost.Memory), formatMeteringValue(cost.Storage),
+			formatMeteringValue(cost.Network), formatMeteringValue(cost.GPU), formatMeteringValue(cost.Total), currency})
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// parseMeteringQuery validates the query and fills in the default period and level of the scope
+func parseMeteringQuery(scope MeteringScope, query *MeteringQuery) (time.Time, time.Time, error) {
+	if query.Start == "" || query.End == "" {
+		return time.Time{}, time.Time{}, fmt.Errorf("both start and end are required")
+	}
+
+	start, err := time.Parse(v1alpha1.MeteringRollupDateLayout, query.Start)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid start %q, dates are in the format of %s", query.Start, v1alpha1.MeteringRollupDateLayout)
+	}
+	end, err := time.Parse(v1alpha1.MeteringRollupDateLayout, query.End)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid end %q, dates are in the format of %s", query.End, v1alpha1.MeteringRollupDateLayout)
+	}
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("end must not be before start")
+	}
+	if days := int(end.Sub(start).Hours()/24) + 1; days > maxMeteringDays {
+		return time.Time{}, time.Time{}, fmt.Errorf("%d days exceed the limit %d", days, maxMeteringDays)
+	}
+
+	switch query.Period {
+	case "":
+		query.Period = MeteringPeriodDaily
+	case MeteringPeriodDaily, MeteringPeriodMonthly:
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid period %q, one of %s and %s", query.Period, MeteringPeriodDaily, MeteringPeriodMonthly)
+	}
+
+	switch query.Level {
+	case "":
+		query.Level = v1alpha1.MeteringLevelWorkspace
+		if scope.Namespace != "" {
+			query.Level = v1alpha1.MeteringLevelNamespace
+		}
+	case v1alpha1.MeteringLevelNamespace, v1alpha1.MeteringLevelWorkload:
+	case v1alpha1.MeteringLevelWorkspace:
+		if scope.Namespace != "" {
+			return time.Time{}, time.Time{}, fmt.Errorf("level of namespace reports is one of namespace and workload")
+		}
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid level %q, one of workspace, namespace and workload", query.Level)
+	}
+
+	return start, end, nil
+}
+
+func queryMeteringMatrix(expr string, params url.Values) (model.Matrix, error) {
+	values := url.Values{}
+	for key, value := range params {
+		values[key] = value
+	}
+	values.Set("query", expr)
+
+	res := client.SendMonitoringRequest(client.PrometheusEndpoint, client.RangeQueryType, values.Encode())
+	if res == "" {
+		return nil, fmt.Errorf("failed to query Prometheus")
+	}
+
+	return metering.DecodeMatrix([]byte(res))
+}
+
+// buildMeteringRecords aggregates entries of the level in the scope by period and prices them
+func buildMeteringRecords(days []meteringDay, scope MeteringScope, query MeteringQuery, prices PriceSheet) []MeteringRecord {
+	records := make([]MeteringRecord, 0)
+	index := make(map[string]int)
+
+	for _, day := range days {
+		period := day.date.Format(v1alpha1.MeteringRollupDateLayout)
+		if query.Period == MeteringPeriodMonthly {
+			period = day.date.Format(meteringMonthLayout)
+		}
+
+		for _, entry := range day.entries {
+			if entry.Level != query.Level {
+				continue
+			}
+			if scope.Workspace != "" && entry.Workspace != scope.Workspace {
+				continue
+			}
+			if scope.Namespace != "" && entry.Namespace != scope.Namespace {
+				continue
+			}
+
+			key := strings.Join([]string{period, entry.Workspace, entry.Namespace, entry.Workload}, "/")
+			i, ok := index[key]
+			if !ok {
+				i = len(records)
+				index[key] = i
+				records = append(records, MeteringRecord{Period: period, Level: entry.Level, Workspace: entry.Workspace, Namespace: entry.Namespace, Workload: entry.Workload})
+			}
+			records[i].Usage = metering.AddUsage(records[i].Usage, entry.Usage)
+		}
+	}
+
+	for i := range records {
+		records[i].Usage = roundUsage(records[i].Usage)
+		records[i].Cost = priceUsage(records[i].Usage, prices)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Period != records[j].Period {
+			return records[i].Period < records[j].Period
+		}
+		return metering.LessEntry(
+			v1alpha1.MeteringEntry{Workspace: records[i].Workspace, Namespace: records[i].Namespace, Workload: records[i].Workload},
+			v1alpha1.MeteringEntry{Workspace: records[j].Workspace, Namespace: records[j].Namespace, Workload: records[j].Workload})
+	})
+
+	return records
+}
+
+func roundUsage(usage v1alpha1.ResourceUsage) v1alpha1.ResourceUsage {
+	return v1alpha1.ResourceUsage{
+		CPUCoreHours:    roundMeteringValue(usage.CPUCoreHours),
+		MemoryGiBHours:  roundMeteringValue(usage.MemoryGiBHours),
+		StorageGiBHours: roundMeteringValue(usage.StorageGiBHours),
+		NetworkGiB:      roundMeteringValue(usage.NetworkGiB),
+		GPUHours:        roundMeteringValue(usage.GPUHours),
+	}
+}
+
+// priceUsage rounds costs of resources, the total is the sum of rounded costs
+func priceUsage(usage v1alpha1.ResourceUsage, prices PriceSheet) ResourceCost {
+	cost := ResourceCost{
+		CPU:     roundMeteringValue(usage.CPUCoreHours * prices.CPUCoreHour),
+		Memory:  roundMeteringValue(usage.MemoryGiBHours * prices.MemoryGiBHour),
+		Storage: roundMeteringValue(usage.StorageGiBHours * prices.StorageGiBHour),
+		Network: roundMeteringValue(usage.NetworkGiB * prices.NetworkGiB),
+		GPU:     roundMeteringValue(usage.GPUHours * prices.GPUHour),
+	}
+	cost.Total = roundMeteringValue(cost.CPU + cost.Memory + cost.Storage + cost.Network + cost.GPU)
+	return cost
+}
+
+func addCost(a, b ResourceCost) ResourceCost {
+	return ResourceCost{
+		CPU:     roundMeteringValue(a.CPU + b.CPU),
+		Memory:  roundMeteringValue(a.Memory + b.Memory),
+		Storage: roundMeteringValue(a.Storage + b.Storage),
+		Network: roundMeteringValue(a.Network + b.Network),
+		GPU:     roundMeteringValue(a.GPU + b.GPU),
+		Total:   roundMeteringValue(a.Total + b.Total),
+	}
+}
+
+// usage and costs are kept to 4 decimal places
+func roundMeteringValue(value float64) float64 {
+	return math.Round(value*10000) / 10000
+}
+
+func formatMeteringValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func listMeteringRollups(day time.Time) ([]*v1alpha1.MeteringRollup, error) {
+	return informers.KsSharedInformerFactory().Monitoring().V1alpha1().MeteringRollups().Lister().List(metering.RollupSelector(day))
+}
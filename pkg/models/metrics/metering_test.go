@@ -0,0 +1,172 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+
+package metrics
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"kubesphere.io/kubesphere/pkg/apis/monitoring/v1alpha1"
+)
+
+func TestBuildMeteringRecords(t *testing.T) {
+	day := func(date string, entries ...v1alpha1.MeteringEntry) meteringDay {
+		d, _ := time.Parse(v1alpha1.MeteringRollupDateLayout, date)
+		return meteringDay{date: d, entries: entries}
+	}
+	usage := v1alpha1.ResourceUsage{CPUCoreHours: 24, MemoryGiBHours: 48, StorageGiBHours: 240, NetworkGiB: 1, GPUHours: 2}
+	demo := v1alpha1.MeteringEntry{Level: v1alpha1.MeteringLevelNamespace, Workspace: "ws", Namespace: "demo", Usage: usage}
+	other := v1alpha1.MeteringEntry{Level: v1alpha1.MeteringLevelNamespace, Workspace: "other", Namespace: "other", Usage: usage}
+	workspace := v1alpha1.MeteringEntry{Level: v1alpha1.MeteringLevelWorkspace, Workspace: "ws", Usage: usage}
+
+	days := []meteringDay{
+		day("2019-06-01", workspace, demo, other),
+		day("2019-06-02", workspace, demo, other),
+		day("2019-07-01", workspace, demo, other),
+	}
+	prices := PriceSheet{CPUCoreHour: 0.5, MemoryGiBHour: 0.1, StorageGiBHour: 0.01, NetworkGiB: 1, GPUHour: 2}
+
+	tests := []struct {
+		scope    MeteringScope
+		query    MeteringQuery
+		expected []MeteringRecord
+	}{
+		{
+			scope: MeteringScope{Workspace: "ws"},
+			query: MeteringQuery{Period: MeteringPeriodMonthly, Level: v1alpha1.MeteringLevelNamespace},
+			expected: []MeteringRecord{
+				{Period: "2019-06", Level: v1alpha1.MeteringLevelNamespace, Workspace: "ws", Namespace: "demo",
+					Usage: v1alpha1.ResourceUsage{CPUCoreHours: 48, MemoryGiBHours: 96, StorageGiBHours: 480, NetworkGiB: 2, GPUHours: 4},
+					Cost:  ResourceCost{CPU: 24, Memory: 9.6, Storage: 4.8, Network: 2, GPU: 8, Total: 48.4}},
+				{Period: "2019-07", Level: v1alpha1.MeteringLevelNamespace, Workspace: "ws", Namespace: "demo",
+					Usage: usage,
+					Cost:  ResourceCost{CPU: 12, Memory: 4.8, Storage: 2.4, Network: 1, GPU: 4, Total: 24.2}},
+			},
+		},
+		{
+			scope: MeteringScope{Namespace: "other"},
+			query: MeteringQuery{Period: MeteringPeriodDaily, Level: v1alpha1.MeteringLevelNamespace},
+			expected: []MeteringRecord{
+				{Period: "2019-06-01", Level: v1alpha1.MeteringLevelNamespace, Workspace: "other", Namespace: "other", Usage: usage, Cost: ResourceCost{CPU: 12, Memory: 4.8, Storage: 2.4, Network: 1, GPU: 4, Total: 24.2}},
+				{Period: "2019-06-02", Level: v1alpha1.MeteringLevelNamespace, Workspace: "other", Namespace: "other", Usage: usage, Cost: ResourceCost{CPU: 12, Memory: 4.8, Storage: 2.4, Network: 1, GPU: 4, Total: 24.2}},
+				{Period: "2019-07-01", Level: v1alpha1.MeteringLevelNamespace, Workspace: "other", Namespace: "other", Usage: usage, Cost: ResourceCost{CPU: 12, Memory: 4.8, Storage: 2.4, Network: 1, GPU: 4, Total: 24.2}},
+			},
+		},
+		{
+			scope: MeteringScope{},
+			query: MeteringQuery{Period: MeteringPeriodMonthly, Level: v1alpha1.MeteringLevelWorkspace},
+			expected: []MeteringRecord{
+				{Period: "2019-06", Level: v1alpha1.MeteringLevelWorkspace, Workspace: "ws",
+					Usage: v1alpha1.ResourceUsage{CPUCoreHours: 48, MemoryGiBHours: 96, StorageGiBHours: 480, NetworkGiB: 2, GPUHours: 4},
+					Cost:  ResourceCost{CPU: 24, Memory: 9.6, Storage: 4.8, Network: 2, GPU: 8, Total: 48.4}},
+				{Period: "2019-07", Level: v1alpha1.MeteringLevelWorkspace, Workspace: "ws", Usage: usage,
+					Cost: ResourceCost{CPU: 12, Memory: 4.8, Storage: 2.4, Network: 1, GPU: 4, Total: 24.2}},
+			},
+		},
+		{
+			scope:    MeteringScope{Workspace: "none"},
+			query:    MeteringQuery{Period: MeteringPeriodDaily, Level: v1alpha1.MeteringLevelWorkspace},
+			expected: []MeteringRecord{},
+		},
+	}
+
+	for i, test := range tests {
+		records := buildMeteringRecords(days, test.scope, test.query, prices)
+		if !reflect.DeepEqual(records, test.expected) {
+			t.Errorf("case %d: expected %+v, got %+v", i, test.expected, records)
+		}
+	}
+}
+
+func TestParseMeteringQuery(t *testing.T) {
+	tests := []struct {
+		scope MeteringScope
+		query MeteringQuery
+		level string
+		valid bool
+	}{
+		{scope: MeteringScope{Workspace: "ws"}, query: MeteringQuery{Start: "2019-06-01", End: "2019-06-30"}, level: v1alpha1.MeteringLevelWorkspace, valid: true},
+		{scope: MeteringScope{Namespace: "demo"}, query: MeteringQuery{Start: "2019-06-01", End: "2019-06-01", Period: MeteringPeriodMonthly}, level: v1alpha1.MeteringLevelNamespace, valid: true},
+		{scope: MeteringScope{Namespace: "demo"}, query: MeteringQuery{Start: "2019-06-01", End: "2019-06-01", Level: v1alpha1.MeteringLevelWorkload}, level: v1alpha1.MeteringLevelWorkload, valid: true},
+		{scope: MeteringScope{Namespace: "demo"}, query: MeteringQuery{Start: "2019-06-01", End: "2019-06-01", Level: v1alpha1.MeteringLevelWorkspace}},
+		{query: MeteringQuery{Start: "2019-06-01"}},
+		{query: MeteringQuery{Start: "2019-06-02", End: "2019-06-01"}},
+		{query: MeteringQuery{Start: "2019-06-01T00:00:00Z", End: "2019-06-02"}},
+		{query: MeteringQuery{Start: "2018-01-01", End: "2019-06-01"}},
+		{query: MeteringQuery{Start: "2019-06-01", End: "2019-06-01", Period: "weekly"}},
+		{query: MeteringQuery{Start: "2019-06-01", End: "2019-06-01", Level: "pod"}},
+	}
+
+	for i, test := range tests {
+		_, _, err := parseMeteringQuery(test.scope, &test.query)
+		if test.valid != (err == nil) {
+			t.Errorf("case %d: expected valid %t, got %v", i, test.valid, err)
+		}
+		if test.valid && test.query.Level != test.level {
+			t.Errorf("case %d: expected level %s, got %s", i, test.level, test.query.Level)
+		}
+	}
+}
+
+func TestParsePriceSheet(t *testing.T) {
+	tests := []struct {
+		data     string
+		expected PriceSheet
+		valid    bool
+	}{
+		{data: "", valid: true},
+		{data: `{"currency": "USD", "cpu_core_hour": 0.03, "gpu_hour": 1.2}`, expected: PriceSheet{Currency: "USD", CPUCoreHour: 0.03, GPUHour: 1.2}, valid: true},
+		{data: `{"cpu_core_hour": -1}`},
+		{data: `cpu_core_hour: 1`},
+	}
+
+	for i, test := range tests {
+		prices, err := parsePriceSheet(test.data)
+		if test.valid != (err == nil) {
+			t.Errorf("case %d: expected valid %t, got %v", i, test.valid, err)
+		}
+		if test.valid && prices != test.expected {
+			t.Errorf("case %d: expected %+v, got %+v", i, test.expected, prices)
+		}
+	}
+}
+
+func TestWriteMeteringCSV(t *testing.T) {
+	report := &MeteringReport{
+		Prices: &PriceSheet{Currency: "USD"},
+		Records: []MeteringRecord{
+			{Period: "2019-06", Level: v1alpha1.MeteringLevelWorkload, Workspace: "ws", Namespace: "demo", Workload: "Deployment:web",
+				Usage: v1alpha1.ResourceUsage{CPUCoreHours: 1.5, MemoryGiBHours: 2},
+				Cost:  ResourceCost{CPU: 0.75, Memory: 0.2, Total: 0.95}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMeteringCSV(&buf, report); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "period,level,workspace,namespace,workload,cpu_core_hours,memory_gib_hours,storage_gib_hours,network_gib,gpu_hours,cpu_cost,memory_cost,storage_cost,network_cost,gpu_cost,total_cost,currency\n" +
+		"2019-06,workload,ws,demo,Deployment:web,1.5,2,0,0,0,0.75,0.2,0,0,0,0.95,USD\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
@@ -13,6 +13,8 @@ limitations under the License.
 
 package metrics
 
+import "kubesphere.io/kubesphere/pkg/simple/client/metering"
+
 const (
 	ResultTypeVector             = "vector"
 	ResultTypeMatrix             = "matrix"
@@ -85,7 +87,7 @@ const (
 	NamespaceLabelRule               = `kube_namespace_labels`
 	WorkloadReplicaSetOwnerRule      = `kube_pod_owner{namespace="$1", owner_name!="<none>", owner_kind="ReplicaSet"}`
 	WorkspaceNamespaceLabelRule      = `sum(kube_namespace_labels{label_kubesphere_io_workspace != ""}) by (label_kubesphere_io_workspace)`
-	ExcludedVirtualNetworkInterfaces = `interface!~"^(cali.+|tunl.+|dummy.+|kube.+|flannel.+|cni.+|docker.+|veth.+|lo.*)"`
+	ExcludedVirtualNetworkInterfaces = metering.ExcludedVirtualNetworkInterfaces
 )
 
 const (
@@ -0,0 +1,272 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+
+package metrics
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/prometheus/common/model"
+	"k8s.io/api/core/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
+
+	"kubesphere.io/kubesphere/pkg/constants"
+	"kubesphere.io/kubesphere/pkg/models/tenant"
+	"kubesphere.io/kubesphere/pkg/params"
+	client "kubesphere.io/kubesphere/pkg/simple/client/prometheus"
+)
+
+// namespace label of metrics, every selector of PromQL queries of tenants is restricted by it
+const promqlNamespaceLabel = "namespace"
+
+var (
+	promqlMaxQueryLength int
+	promqlMaxRange       time.Duration
+	promqlMaxLookback    time.Duration
+	promqlMaxPoints      int
+	promqlMaxTimeout     time.Duration
+)
+
+func init() {
+	flag.IntVar(&promqlMaxQueryLength, "promql-max-query-length", 4096, "max length of PromQL expressions of the query api")
+	flag.DurationVar(&promqlMaxRange, "promql-max-range", 7*24*time.Hour, "max time range of PromQL range queries")
+	flag.DurationVar(&promqlMaxLookback, "promql-max-lookback", 24*time.Hour, "max range of range selectors and subqueries in PromQL expressions, eg. [1d]")
+	flag.IntVar(&promqlMaxPoints, "promql-max-points", 1100, "max points per series of PromQL range queries")
+	flag.DurationVar(&promqlMaxTimeout, "promql-max-timeout", 30*time.Second, "max evaluation timeout of PromQL queries")
+}
+
+// PromQLQuery is a PromQL query of tenants, it's a range query if both start and end are set
+type PromQLQuery struct {
+	Query   string
+	Time    string
+	Start   string
+	End     string
+	Step    string
+	Timeout string
+}
+
+// PromQLScope restricts namespaces of queries, all namespaces the user can access if both are empty
+type PromQLScope struct {
+	Workspace string
+	Namespace string
+}
+
+type PromQLResult struct {
+	Status int    `json:"status" description:"response status"`
+	Error  string `json:"error,omitempty" description:"debug information"`
+	// Query is sent to Prometheus with namespace matchers injected
+	Query string              `json:"query,omitempty" description:"PromQL expression evaluated by Prometheus, with namespace matchers injected"`
+	Data  *FormatedMetricData `json:"data,omitempty" description:"result of the query, the same as the one of Prometheus"`
+}
+
+// prometheusResponse is the response of query api of Prometheus
+type prometheusResponse struct {
+	Status    string             `json:"status"`
+	ErrorType string             `json:"errorType,omitempty"`
+	Error     string             `json:"error,omitempty"`
+	Data      FormatedMetricData `json:"data"`
+}
+
+// QueryPromQL evaluates the expression against metrics of namespaces the user can access in the scope
+func QueryPromQL(usr user.Info, scope PromQLScope, query PromQLQuery) *PromQLResult {
+	if query.Query == "" {
+		return &PromQLResult{Status: http.StatusBadRequest, Error: "query is required"}
+	}
+	if len(query.Query) > promqlMaxQueryLength {
+		return &PromQLResult{Status: http.StatusBadRequest, Error: fmt.Sprintf("query exceeds the limit of %d characters", promqlMaxQueryLength)}
+	}
+
+	queryType, values, err := promQLQueryParams(query)
+	if err != nil {
+		return &PromQLResult{Status: http.StatusBadRequest, Error: err.Error()}
+	}
+
+	namespaces, err := promQLNamespaces(usr, scope)
+	if err != nil {
+		glog.Errorln(err)
+		return &PromQLResult{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+	if len(namespaces) == 0 {
+		return &PromQLResult{Status: http.StatusForbidden, Error: "no namespaces are accessible"}
+	}
+
+	return evaluatePromQL(query.Query, namespaces, queryType, values)
+}
+
+// evaluatePromQL injects the namespace matcher into the expression and sends it to Prometheus with the parameters
+func evaluatePromQL(query string, namespaces []string, queryType string, params url.Values) *PromQLResult {
+	expr, err := InjectLabelMatcher(query, namespaceMatcher(namespaces), promqlMaxLookback)
+	if err != nil {
+		return &PromQLResult{Status: http.StatusBadRequest, Error: err.Error()}
+	}
+
+	values := url.Values{}
+	for key, value := range params {
+		values[key] = value
+	}
+	values.Set("query", expr)
+
+	res := client.SendMonitoringRequest(client.PrometheusEndpoint, queryType, values.Encode())
+	if res == "" {
+		return &PromQLResult{Status: http.StatusBadGateway, Query: expr, Error: "failed to query Prometheus"}
+	}
+
+	var response prometheusResponse
+	if err := jsonIter.Unmarshal([]byte(res), &response); err != nil {
+		glog.Errorln(err, res)
+		return &PromQLResult{Status: http.StatusBadGateway, Query: expr, Error: err.Error()}
+	}
+
+	if response.Status != MetricStatusSuccess {
+		status := http.StatusBadGateway
+		if response.ErrorType == "bad_data" || response.ErrorType == "execution" {
+			status = http.StatusBadRequest
+		}
+		return &PromQLResult{Status: status, Query: expr, Error: fmt.Sprintf("%s: %s", response.ErrorType, response.Error)}
+	}
+
+	return &PromQLResult{Status: http.StatusOK, Query: expr, Data: &response.Data}
+}
+
+// promQLNamespaces returns sorted names of namespaces the user can access in the scope
+func promQLNamespaces(usr user.Info, scope PromQLScope) ([]string, error) {
+	result, err := tenant.ListNamespaces(usr, &params.Conditions{}, "", false, math.MaxInt32, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces := make([]string, 0, len(result.Items))
+	for _, item := range result.Items {
+		namespace := item.(*v1.Namespace)
+		if scope.Namespace != "" && namespace.Name != scope.Namespace {
+			continue
+		}
+		if scope.Workspace != "" && namespace.Labels[constants.WorkspaceLabelKey] != scope.Workspace {
+			continue
+		}
+		namespaces = append(namespaces, namespace.Name)
+	}
+	sort.Strings(namespaces)
+
+	return namespaces, nil
+}
+
+// namespaceMatcher never matches series without namespaces, eg. metrics of nodes
+func namespaceMatcher(namespaces []string) string {
+	return fmt.Sprintf("%s=~%s", promqlNamespaceLabel, strconv.Quote(strings.Join(namespaces, "|")))
+}
+
+// promQLQueryParams validates the time range, step and timeout of the query against cost limits,
+// and returns the query type and parameters of Prometheus
+func promQLQueryParams(query PromQLQuery) (string, url.Values, error) {
+	values := url.Values{}
+
+	timeout := promqlMaxTimeout
+	if query.Timeout != "" {
+		t, err := parsePromQLDuration(query.Timeout)
+		if err != nil || t <= 0 {
+			return "", nil, fmt.Errorf("invalid timeout %q", query.Timeout)
+		}
+		if t < timeout {
+			timeout = t
+		}
+	}
+	values.Set("timeout", model.Duration(timeout).String())
+
+	if query.Start == "" && query.End == "" {
+		if query.Time != "" {
+			t, err := parsePromQLTime(query.Time)
+			if err != nil {
+				return "", nil, err
+			}
+			values.Set("time", formatPromQLTime(t))
+		}
+		return client.DefaultQueryType, values, nil
+	}
+
+	if query.Start == "" || query.End == "" {
+		return "", nil, fmt.Errorf("both start and end are required by range queries")
+	}
+
+	start, err := parsePromQLTime(query.Start)
+	if err != nil {
+		return "", nil, err
+	}
+	end, err := parsePromQLTime(query.End)
+	if err != nil {
+		return "", nil, err
+	}
+	if end.Before(start) {
+		return "", nil, fmt.Errorf("end must not be before start")
+	}
+	if end.Sub(start) > promqlMaxRange {
+		return "", nil, fmt.Errorf("time range exceeds the limit %s", model.Duration(promqlMaxRange))
+	}
+
+	stepString := query.Step
+	if stepString == "" {
+		stepString = client.DefaultQueryStep
+	}
+	step, err := parsePromQLDuration(stepString)
+	if err != nil || step <= 0 {
+		return "", nil, fmt.Errorf("invalid step %q", query.Step)
+	}
+	if points := int64(end.Sub(start)/step) + 1; points > int64(promqlMaxPoints) {
+		return "", nil, fmt.Errorf("%d points per series exceed the limit %d, increase the step", points, promqlMaxPoints)
+	}
+
+	values.Set("start", formatPromQLTime(start))
+	values.Set("end", formatPromQLTime(end))
+	values.Set("step", strconv.FormatFloat(step.Seconds(), 'f', -1, 64))
+
+	return client.RangeQueryType, values, nil
+}
+
+// parsePromQLTime parses unix timestamps in seconds and RFC3339 times, the same as Prometheus
+func parsePromQLTime(s string) (time.Time, error) {
+	if t, err := strconv.ParseFloat(s, 64); err == nil {
+		sec, frac := math.Modf(t)
+		return time.Unix(int64(sec), int64(frac*float64(time.Second))), nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", s)
+}
+
+func formatPromQLTime(t time.Time) string {
+	return strconv.FormatFloat(float64(t.UnixNano())/float64(time.Second), 'f', -1, 64)
+}
+
+// parsePromQLDuration parses durations in seconds and Prometheus durations, eg. 30 and 5m
+func parsePromQLDuration(s string) (time.Duration, error) {
+	if d, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Duration(d * float64(time.Second)), nil
+	}
+	d, err := model.ParseDuration(s)
+	return time.Duration(d), err
+}
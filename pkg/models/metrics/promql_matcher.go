@@ -0,0 +1,290 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+type promqlTokenKind int
+
+const (
+	promqlIdentifier promqlTokenKind = iota
+	promqlString
+	// numbers and durations, eg. 1e3 and 5m
+	promqlNumber
+	// [5m] and [1h:5m] of range selectors and subqueries
+	promqlRange
+	promqlLeftBrace
+	promqlRightBrace
+	promqlLeftParen
+	promqlRightParen
+	promqlComma
+	promqlOperator
+)
+
+type promqlToken struct {
+	kind  promqlTokenKind
+	value string
+	// offset of the token in the expression
+	pos int
+}
+
+func (t promqlToken) end() int {
+	return t.pos + len(t.value)
+}
+
+const promqlOperatorChars = "+-*/%^=!<>~"
+
+var (
+	// identifiers followed by label lists, eg. by (namespace)
+	promqlLabelListKeywords = map[string]bool{"by": true, "without": true, "on": true, "ignoring": true, "group_left": true, "group_right": true}
+	promqlKeywords          = map[string]bool{"and": true, "or": true, "unless": true, "offset": true, "bool": true, "inf": true, "nan": true}
+	promqlAggregations      = map[string]bool{"sum": true, "min": true, "max": true, "avg": true, "stddev": true, "stdvar": true, "count": true,
+		"count_values": true, "bottomk": true, "topk": true, "quantile": true}
+	promqlMatchOperators = map[string]bool{"=": true, "!=": true, "=~": true, "!~": true}
+)
+
+func isPromQLIdentifierStart(c byte) bool {
+	return c == '_' || c == ':' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isPromQLIdentifierChar(c byte) bool {
+	return isPromQLIdentifierStart(c) || (c >= '0' && c <= '9')
+}
+
+func isPromQLDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// lexPromQL splits the expression into tokens, only tokens needed to find vector selectors are distinguished
+func lexPromQL(expr string) ([]promqlToken, error) {
+	var tokens []promqlToken
+
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		start := i
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+			continue
+		case c == '#':
+			for i < len(expr) && expr[i] != '\n' {
+				i++
+			}
+			continue
+		case c == '"' || c == '\'' || c == '`':
+			i++
+			for ; i < len(expr) && expr[i] != c; i++ {
+				if expr[i] == '\\' && c != '`' {
+					i++
+				}
+			}
+			if i >= len(expr) {
+				return nil, fmt.Errorf("unterminated string at position %d", start)
+			}
+			i++
+			tokens = append(tokens, promqlToken{promqlString, expr[start:i], start})
+		case isPromQLIdentifierStart(c):
+			for i < len(expr) && isPromQLIdentifierChar(expr[i]) {
+				i++
+			}
+			tokens = append(tokens, promqlToken{promqlIdentifier, expr[start:i], start})
+		case isPromQLDigit(c) || (c == '.' && i+1 < len(expr) && isPromQLDigit(expr[i+1])):
+			for i < len(expr) && (isPromQLIdentifierChar(expr[i]) && expr[i] != ':' || expr[i] == '.') {
+				// exponents, eg. 1e-3
+				if (expr[i] == 'e' || expr[i] == 'E') && i+2 < len(expr) && (expr[i+1] == '+' || expr[i+1] == '-') && isPromQLDigit(expr[i+2]) {
+					i += 2
+				}
+				i++
+			}
+			tokens = append(tokens, promqlToken{promqlNumber, expr[start:i], start})
+		case c == '[':
+			end := strings.IndexByte(expr[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated range at position %d", start)
+			}
+			i += end + 1
+			tokens = append(tokens, promqlToken{promqlRange, expr[start:i], start})
+		case c == '{':
+			i++
+			tokens = append(tokens, promqlToken{promqlLeftBrace, "{", start})
+		case c == '}':
+			i++
+			tokens = append(tokens, promqlToken{promqlRightBrace, "}", start})
+		case c == '(':
+			i++
+			tokens = append(tokens, promqlToken{promqlLeftParen, "(", start})
+		case c == ')':
+			i++
+			tokens = append(tokens, promqlToken{promqlRightParen, ")", start})
+		case c == ',':
+			i++
+			tokens = append(tokens, promqlToken{promqlComma, ",", start})
+		case strings.IndexByte(promqlOperatorChars, c) >= 0:
+			for i < len(expr) && strings.IndexByte(promqlOperatorChars, expr[i]) >= 0 {
+				i++
+			}
+			tokens = append(tokens, promqlToken{promqlOperator, expr[start:i], start})
+		default:
+			return nil, fmt.Errorf("unexpected character %q at position %d", c, start)
+		}
+	}
+
+	return tokens, nil
+}
+
+// parsePromQLRange parses ranges of range selectors and subqueries, eg. [5m] and [1h:5m], and returns the range
+func parsePromQLRange(value string) (time.Duration, error) {
+	parts := strings.Split(value[1:len(value)-1], ":")
+	if len(parts) > 2 {
+		return 0, fmt.Errorf("invalid range %s", value)
+	}
+
+	r, err := model.ParseDuration(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return 0, fmt.Errorf("invalid range %s: %s", value, err)
+	}
+
+	// steps of subqueries are optional
+	if len(parts) == 2 && strings.TrimSpace(parts[1]) != "" {
+		if _, err := model.ParseDuration(strings.TrimSpace(parts[1])); err != nil {
+			return 0, fmt.Errorf("invalid range %s: %s", value, err)
+		}
+	}
+
+	return time.Duration(r), nil
+}
+
+// InjectLabelMatcher adds the matcher to every vector selector of the expression, eg. with namespace=~"a|b",
+// sum(rate(http_requests_total{code="500"}[5m])) is rewritten into sum(rate(http_requests_total{code="500", namespace=~"a|b"}[5m])).
+// Matchers of a selector are ANDed, other matchers of the same label can't widen the selector.
+// Ranges of range selectors and subqueries are limited to maxRange.
+func InjectLabelMatcher(expr, matcher string, maxRange time.Duration) (string, error) {
+	tokens, err := lexPromQL(expr)
+	if err != nil {
+		return "", err
+	}
+
+	// insertions[i] is inserted before the i-th byte of the expression
+	insertions := make(map[int]string)
+
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		var next *promqlToken
+		if i+1 < len(tokens) {
+			next = &tokens[i+1]
+		}
+
+		switch token.kind {
+		case promqlRange:
+			r, err := parsePromQLRange(token.value)
+			if err != nil {
+				return "", err
+			}
+			if r > maxRange {
+				return "", fmt.Errorf("range %s exceeds the limit %s", token.value, model.Duration(maxRange))
+			}
+		case promqlIdentifier:
+			keyword := strings.ToLower(token.value)
+			switch {
+			case promqlLabelListKeywords[keyword]:
+				// label names in the list aren't selectors
+				if next != nil && next.kind == promqlLeftParen {
+					for i++; i < len(tokens) && tokens[i].kind != promqlRightParen; i++ {
+						if tokens[i].kind != promqlIdentifier && tokens[i].kind != promqlComma && tokens[i].kind != promqlLeftParen {
+							return "", fmt.Errorf("unexpected %s in label list at position %d", tokens[i].value, tokens[i].pos)
+						}
+					}
+				}
+				continue
+			case promqlKeywords[keyword]:
+				continue
+			case next != nil && next.kind == promqlLeftParen:
+				// calls of functions and aggregations
+				continue
+			case promqlAggregations[keyword] && next != nil && next.kind == promqlIdentifier &&
+				(strings.ToLower(next.value) == "by" || strings.ToLower(next.value) == "without"):
+				continue
+			}
+
+			if next == nil || next.kind != promqlLeftBrace {
+				insertions[token.end()] = fmt.Sprintf("{%s}", matcher)
+				continue
+			}
+			i++
+			fallthrough
+		case promqlLeftBrace:
+			end, err := matchersEnd(tokens, i)
+			if err != nil {
+				return "", err
+			}
+			if last := tokens[end-1]; last.kind == promqlLeftBrace || last.kind == promqlComma {
+				insertions[tokens[end].pos] = matcher
+			} else {
+				insertions[tokens[end].pos] = ", " + matcher
+			}
+			i = end
+		case promqlRightBrace:
+			return "", fmt.Errorf("unexpected } at position %d", token.pos)
+		}
+	}
+
+	var b strings.Builder
+	for i := 0; i <= len(expr); i++ {
+		if s, ok := insertions[i]; ok {
+			b.WriteString(s)
+		}
+		if i < len(expr) {
+			b.WriteByte(expr[i])
+		}
+	}
+
+	return b.String(), nil
+}
+
+// matchersEnd validates label matchers of the selector starting with the left brace at start,
+// and returns the index of the right brace
+func matchersEnd(tokens []promqlToken, start int) (int, error) {
+	i := start + 1
+	for i < len(tokens) && tokens[i].kind != promqlRightBrace {
+		if i+2 >= len(tokens) || tokens[i].kind != promqlIdentifier || tokens[i+1].kind != promqlOperator ||
+			!promqlMatchOperators[tokens[i+1].value] || tokens[i+2].kind != promqlString {
+			return 0, fmt.Errorf("invalid label matcher at position %d", tokens[i].pos)
+		}
+		i += 3
+
+		if i < len(tokens) && tokens[i].kind == promqlComma {
+			i++
+		} else if i < len(tokens) && tokens[i].kind != promqlRightBrace {
+			return 0, fmt.Errorf("unexpected %s at position %d", tokens[i].value, tokens[i].pos)
+		}
+	}
+
+	if i >= len(tokens) {
+		return 0, fmt.Errorf("unterminated label matchers at position %d", tokens[start].pos)
+	}
+
+	return i, nil
+}
@@ -0,0 +1,139 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+
+package metrics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInjectLabelMatcher(t *testing.T) {
+	const matcher = `namespace=~"a|b"`
+
+	tests := []struct {
+		expr     string
+		expected string
+	}{
+		{
+			expr:     `http_requests_total`,
+			expected: `http_requests_total{namespace=~"a|b"}`,
+		},
+		{
+			expr:     `sum(rate(http_requests_total{code="500", namespace="c"}[5m])) by (pod)`,
+			expected: `sum(rate(http_requests_total{code="500", namespace="c", namespace=~"a|b"}[5m])) by (pod)`,
+		},
+		{
+			expr:     `sum by (namespace, pod) (rate(http_requests_total{}[5m] offset 1h))`,
+			expected: `sum by (namespace, pod) (rate(http_requests_total{namespace=~"a|b"}[5m] offset 1h))`,
+		},
+		{
+			expr:     `{__name__=~"http_.+",}`,
+			expected: `{__name__=~"http_.+",namespace=~"a|b"}`,
+		},
+		{
+			expr:     `a / on(pod) group_left(node) b > bool 1e-3 unless c`,
+			expected: `a{namespace=~"a|b"} / on(pod) group_left(node) b{namespace=~"a|b"} > bool 1e-3 unless c{namespace=~"a|b"}`,
+		},
+		{
+			expr:     `topk(5, max_over_time(job:latency:rate5m{job='x{y}'}[1h:5m])) # comment {`,
+			expected: `topk(5, max_over_time(job:latency:rate5m{job='x{y}', namespace=~"a|b"}[1h:5m])) # comment {`,
+		},
+		{
+			expr:     `label_replace(up, "dst", "$1", "src", "(.*)") * -Inf`,
+			expected: `label_replace(up{namespace=~"a|b"}, "dst", "$1", "src", "(.*)") * -Inf`,
+		},
+		{
+			expr:     `vector(1)`,
+			expected: `vector(1)`,
+		},
+		{expr: `up{namespace="a"`},
+		{expr: `up{namespace}`},
+		{expr: `up{namespace="a" or namespace="b"}`},
+		{expr: `rate(up[30d])`},
+		{expr: `rate(up[5x])`},
+		{expr: `up}`},
+		{expr: `"unterminated`},
+		{expr: `up @ 1`},
+	}
+
+	for _, test := range tests {
+		result, err := InjectLabelMatcher(test.expr, matcher, 24*time.Hour)
+		if test.expected == "" {
+			if err == nil {
+				t.Errorf("%s: expected error, got %s", test.expr, result)
+			}
+			continue
+		}
+		if err != nil || result != test.expected {
+			t.Errorf("%s: expected %s, got %s %v", test.expr, test.expected, result, err)
+		}
+	}
+}
+
+func TestPromQLQueryParams(t *testing.T) {
+	tests := []struct {
+		query     PromQLQuery
+		queryType string
+		expected  string
+	}{
+		{
+			query:     PromQLQuery{Query: "up"},
+			queryType: "query?",
+			expected:  "timeout=30s",
+		},
+		{
+			query:     PromQLQuery{Query: "up", Time: "2019-10-18T12:00:00Z", Timeout: "5"},
+			queryType: "query?",
+			expected:  "time=1571400000&timeout=5s",
+		},
+		{
+			query:     PromQLQuery{Query: "up", Start: "1571400000", End: "1571403600.5", Step: "1m", Timeout: "1h"},
+			queryType: "query_range?",
+			expected:  "end=1571403600.5&start=1571400000&step=60&timeout=30s",
+		},
+		{query: PromQLQuery{Query: "up", Start: "1571400000"}},
+		{query: PromQLQuery{Query: "up", Start: "1571400000", End: "1571390000"}},
+		// 8 days
+		{query: PromQLQuery{Query: "up", Start: "1571400000", End: "1572091200", Step: "1h"}},
+		// 1441 points
+		{query: PromQLQuery{Query: "up", Start: "1571400000", End: "1571486400", Step: "60"}},
+		{query: PromQLQuery{Query: "up", Start: "1571400000", End: "1571486400", Step: "0"}},
+		{query: PromQLQuery{Query: "up", Time: "yesterday"}},
+		{query: PromQLQuery{Query: "up", Timeout: "-1s"}},
+	}
+
+	for _, test := range tests {
+		queryType, values, err := promQLQueryParams(test.query)
+		if test.expected == "" {
+			if err == nil {
+				t.Errorf("%+v: expected error, got %s", test.query, values.Encode())
+			}
+			continue
+		}
+		if err != nil || queryType != test.queryType || values.Encode() != test.expected {
+			t.Errorf("%+v: expected %s%s, got %s%s %v", test.query, test.queryType, test.expected, queryType, values.Encode(), err)
+		}
+	}
+}
+
+func TestNamespaceMatcher(t *testing.T) {
+	if matcher := namespaceMatcher([]string{"demo", "kube-system"}); matcher != `namespace=~"demo|kube-system"` {
+		t.Errorf("unexpected matcher %s", matcher)
+	}
+}
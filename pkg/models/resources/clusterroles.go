@@ -38,6 +38,11 @@ func (*clusterRoleSearcher) get(namespace, name string) (interface{}, error) {
 }
 
 // exactly Match
+func (s *clusterRoleSearcher) matches(conditions *params.Conditions, item interface{}) bool {
+	object, ok := item.(*rbac.ClusterRole)
+	return ok && s.match(conditions.Match, object) && s.fuzzy(conditions.Fuzzy, object)
+}
+
 func (*clusterRoleSearcher) match(match map[string]string, item *rbac.ClusterRole) bool {
 	for k, v := range match {
 		switch k {
@@ -37,6 +37,11 @@ func (*configMapSearcher) get(namespace, name string) (interface{}, error) {
 }
 
 // exactly Match
+func (s *configMapSearcher) matches(conditions *params.Conditions, item interface{}) bool {
+	object, ok := item.(*v1.ConfigMap)
+	return ok && s.match(conditions.Match, object) && s.fuzzy(conditions.Fuzzy, object)
+}
+
 func (*configMapSearcher) match(match map[string]string, item *v1.ConfigMap) bool {
 	for k, v := range match {
 		switch k {
@@ -45,6 +45,11 @@ func cronJobStatus(item *v1beta1.CronJob) string {
 }
 
 // Exactly Match
+func (s *cronJobSearcher) matches(conditions *params.Conditions, item interface{}) bool {
+	object, ok := item.(*v1beta1.CronJob)
+	return ok && s.match(conditions.Match, object) && s.fuzzy(conditions.Fuzzy, object)
+}
+
 func (*cronJobSearcher) match(match map[string]string, item *v1beta1.CronJob) bool {
 	for k, v := range match {
 		switch k {
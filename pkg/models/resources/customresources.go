@@ -0,0 +1,277 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package resources
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/tools/cache"
+
+	"kubesphere.io/kubesphere/pkg/constants"
+	"kubesphere.io/kubesphere/pkg/informers"
+	"kubesphere.io/kubesphere/pkg/params"
+	"kubesphere.io/kubesphere/pkg/simple/client/k8s"
+	"kubesphere.io/kubesphere/pkg/utils/sliceutil"
+)
+
+// unstructuredSearcher searches any resource cached by dynamic informers, such as CRDs
+type unstructuredSearcher struct {
+	resource   schema.GroupVersionResource
+	namespaced bool
+	informer   cache.SharedIndexInformer
+}
+
+// RegisterCustomResources registers generic searchers for resources like "virtualservices.networking.istio.io",
+// or "horizontalpodautoscalers.v2beta2.autoscaling" with an explicit version, the preferred version is used if omitted.
+// Resources are resolved by discovery, they are searched by "<resource>.<group>" and also by the plural name
+// if there is no built-in searcher with the same name. Informers must be started by DynamicSharedInformerFactory.
+func RegisterCustomResources(names []string) error {
+	discoveryClient := k8s.Client().Discovery()
+
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+
+		if name == "" {
+			continue
+		}
+
+		apiResource, gvr, err := discoverResource(discoveryClient, name)
+
+		if err != nil {
+			return err
+		}
+
+		registerResource(gvr, apiResource.Namespaced, informers.DynamicSharedInformerFactory().ForResource(gvr))
+	}
+
+	return nil
+}
+
+func registerResource(gvr schema.GroupVersionResource, namespaced bool, informer cache.SharedIndexInformer) {
+	searcher := &unstructuredSearcher{resource: gvr, namespaced: namespaced, informer: informer}
+
+	keys := []string{gvr.GroupResource().String()}
+
+	if gvr.Group != "" {
+		keys = append(keys, gvr.Resource)
+	}
+
+	for _, key := range keys {
+		if _, ok := resources[key]; ok {
+			glog.Warningf("resource %s is already registered, %s is only searchable by %s", key, gvr, keys[0])
+			continue
+		}
+		resources[key] = searcher
+		if !namespaced {
+			clusterResources = append(clusterResources, key)
+		}
+		glog.Infof("resource %s registered as %s", gvr, key)
+	}
+}
+
+func discoverResource(client discovery.DiscoveryInterface, name string) (*metav1.APIResource, schema.GroupVersionResource, error) {
+	fullySpecified, groupResource := schema.ParseResourceArg(name)
+
+	if fullySpecified != nil {
+		if list, err := client.ServerResourcesForGroupVersion(fullySpecified.GroupVersion().String()); err == nil {
+			if apiResource := findResource(list, fullySpecified.Resource); apiResource != nil {
+				return apiResource, *fullySpecified, nil
+			}
+		}
+	}
+
+	lists, err := client.ServerPreferredResources()
+
+	// some aggregated apis may be unavailable, resources of other groups are still usable
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, schema.GroupVersionResource{}, err
+	}
+
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+
+		if err != nil || gv.Group != groupResource.Group {
+			continue
+		}
+
+		if apiResource := findResource(list, groupResource.Resource); apiResource != nil {
+			return apiResource, gv.WithResource(apiResource.Name), nil
+		}
+	}
+
+	return nil, schema.GroupVersionResource{}, fmt.Errorf("resource %s not found in discovery", name)
+}
+
+func findResource(list *metav1.APIResourceList, resource string) *metav1.APIResource {
+	for i := range list.APIResources {
+		apiResource := &list.APIResources[i]
+		// skip sub resources like status and scale
+		if apiResource.Name == resource && sliceutil.HasString(apiResource.Verbs, "watch") {
+			return apiResource
+		}
+	}
+	return nil
+}
+
+func (s *unstructuredSearcher) get(namespace, name string) (interface{}, error) {
+	key := name
+
+	if s.namespaced {
+		key = fmt.Sprintf("%s/%s", namespace, name)
+	}
+
+	obj, exists, err := s.informer.GetIndexer().GetByKey(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		return nil, errors.NewNotFound(s.resource.GroupResource(), name)
+	}
+
+	return obj, nil
+}
+
+// unstructuredStatus returns the lower case status.phase, which is the common convention of CRDs
+func unstructuredStatus(item *unstructured.Unstructured) string {
+	phase, _, _ := unstructured.NestedString(item.Object, "status", "phase")
+	return strings.ToLower(phase)
+}
+
+// Exactly Match
+func (s *unstructuredSearcher) matches(conditions *params.Conditions, item interface{}) bool {
+	object, ok := item.(*unstructured.Unstructured)
+	return ok && s.match(conditions.Match, object) && s.fuzzy(conditions.Fuzzy, object)
+}
+
+func (*unstructuredSearcher) match(match map[string]string, item *unstructured.Unstructured) bool {
+	for k, v := range match {
+		switch k {
+		case Status:
+			if unstructuredStatus(item) != v {
+				return false
+			}
+		case Name:
+			names := strings.Split(v, "|")
+			if !sliceutil.HasString(names, item.GetName()) {
+				return false
+			}
+		case Keyword:
+			if !strings.Contains(item.GetName(), v) && !searchFuzzy(item.GetLabels(), "", v) && !searchFuzzy(item.GetAnnotations(), "", v) {
+				return false
+			}
+		default:
+			if item.GetLabels()[k] != v {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (*unstructuredSearcher) fuzzy(fuzzy map[string]string, item *unstructured.Unstructured) bool {
+
+	for k, v := range fuzzy {
+		switch k {
+		case Name:
+			if !strings.Contains(item.GetName(), v) && !strings.Contains(item.GetAnnotations()[constants.DisplayNameAnnotationKey], v) {
+				return false
+			}
+		case Label:
+			if !searchFuzzy(item.GetLabels(), "", v) {
+				return false
+			}
+		case annotation:
+			if !searchFuzzy(item.GetAnnotations(), "", v) {
+				return false
+			}
+		case app:
+			if !strings.Contains(item.GetLabels()[chart], v) && !strings.Contains(item.GetLabels()[release], v) {
+				return false
+			}
+		default:
+			if !searchFuzzy(item.GetLabels(), k, v) && !searchFuzzy(item.GetAnnotations(), k, v) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func (*unstructuredSearcher) compare(a, b *unstructured.Unstructured, orderBy string) bool {
+	switch orderBy {
+	case CreateTime:
+		return a.GetCreationTimestamp().Time.Before(b.GetCreationTimestamp().Time)
+	case Name:
+		fallthrough
+	default:
+		return strings.Compare(a.GetName(), b.GetName()) <= 0
+	}
+}
+
+func (s *unstructuredSearcher) search(namespace string, conditions *params.Conditions, orderBy string, reverse bool) ([]interface{}, error) {
+	var objects []interface{}
+	var err error
+
+	if s.namespaced && namespace != "" {
+		objects, err = s.informer.GetIndexer().ByIndex(cache.NamespaceIndex, namespace)
+	} else {
+		objects = s.informer.GetIndexer().List()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*unstructured.Unstructured, 0)
+
+	for _, obj := range objects {
+		item, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+		if s.match(conditions.Match, item) && s.fuzzy(conditions.Fuzzy, item) {
+			result = append(result, item)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if reverse {
+			tmp := i
+			i = j
+			j = tmp
+		}
+		return s.compare(result[i], result[j], orderBy)
+	})
+
+	r := make([]interface{}, 0)
+	for _, i := range result {
+		r = append(r, i)
+	}
+	return r, nil
+}
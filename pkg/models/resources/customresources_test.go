@@ -0,0 +1,154 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package resources
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+
+	"kubesphere.io/kubesphere/pkg/params"
+)
+
+func newUnstructured(namespace, name, phase string, labels map[string]string, created time.Time) *unstructured.Unstructured {
+	item := &unstructured.Unstructured{}
+	item.SetAPIVersion("networking.istio.io/v1alpha3")
+	item.SetKind("VirtualService")
+	item.SetNamespace(namespace)
+	item.SetName(name)
+	item.SetLabels(labels)
+	item.SetCreationTimestamp(metav1.NewTime(created))
+	if phase != "" {
+		unstructured.SetNestedField(item.Object, phase, "status", "phase")
+	}
+	return item
+}
+
+func newTestSearcher(t *testing.T, namespaced bool, items ...*unstructured.Unstructured) *unstructuredSearcher {
+	informer := cache.NewSharedIndexInformer(&cache.ListWatch{}, &unstructured.Unstructured{}, 0,
+		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+
+	for _, item := range items {
+		if err := informer.GetIndexer().Add(item); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	gvr := schema.GroupVersionResource{Group: "networking.istio.io", Version: "v1alpha3", Resource: "virtualservices"}
+
+	return &unstructuredSearcher{resource: gvr, namespaced: namespaced, informer: informer}
+}
+
+func names(items []interface{}) []string {
+	result := make([]string, 0)
+	for _, item := range items {
+		result = append(result, item.(*unstructured.Unstructured).GetName())
+	}
+	return result
+}
+
+func TestUnstructuredSearch(t *testing.T) {
+	now := time.Now()
+
+	searcher := newTestSearcher(t, true,
+		newUnstructured("default", "reviews", "Running", map[string]string{"app": "bookinfo"}, now.Add(-time.Hour)),
+		newUnstructured("default", "ratings", "Failed", map[string]string{"app": "bookinfo", "tier": "db"}, now),
+		newUnstructured("kube-system", "details", "", map[string]string{"app": "details"}, now.Add(-2*time.Hour)),
+	)
+
+	tests := []struct {
+		namespace  string
+		conditions *params.Conditions
+		orderBy    string
+		reverse    bool
+		expected   []string
+	}{
+		{"", &params.Conditions{}, Name, false, []string{"details", "ratings", "reviews"}},
+		{"", &params.Conditions{}, CreateTime, true, []string{"ratings", "reviews", "details"}},
+		{"default", &params.Conditions{}, Name, true, []string{"reviews", "ratings"}},
+		{"", &params.Conditions{Match: map[string]string{Status: StatusRunning}}, Name, false, []string{"reviews"}},
+		{"", &params.Conditions{Match: map[string]string{Name: "details|ratings"}}, Name, false, []string{"details", "ratings"}},
+		{"", &params.Conditions{Match: map[string]string{"app": "bookinfo"}}, Name, false, []string{"ratings", "reviews"}},
+		{"", &params.Conditions{Match: map[string]string{Keyword: "db"}}, Name, false, []string{"ratings"}},
+		{"", &params.Conditions{Fuzzy: map[string]string{Name: "ra"}}, Name, false, []string{"ratings"}},
+		{"", &params.Conditions{Fuzzy: map[string]string{Label: "detail"}}, Name, false, []string{"details"}},
+		{"", &params.Conditions{Fuzzy: map[string]string{"tier": "d"}}, Name, false, []string{"ratings"}},
+	}
+
+	for i, test := range tests {
+		result, err := searcher.search(test.namespace, test.conditions, test.orderBy, test.reverse)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := names(result)
+		if len(got) != len(test.expected) {
+			t.Errorf("case %d: expected %v, got %v", i, test.expected, got)
+			continue
+		}
+		for j := range got {
+			if got[j] != test.expected[j] {
+				t.Errorf("case %d: expected %v, got %v", i, test.expected, got)
+				break
+			}
+		}
+	}
+}
+
+func TestUnstructuredGet(t *testing.T) {
+	searcher := newTestSearcher(t, true, newUnstructured("default", "reviews", "", nil, time.Now()))
+
+	if _, err := searcher.get("default", "reviews"); err != nil {
+		t.Errorf("expected reviews, got %v", err)
+	}
+
+	if _, err := searcher.get("kube-system", "reviews"); err == nil {
+		t.Error("expected not found error")
+	}
+}
+
+func TestRegisterResource(t *testing.T) {
+	searcher := newTestSearcher(t, false)
+	gvr := schema.GroupVersionResource{Group: "example.io", Version: "v1", Resource: "widgets"}
+
+	registerResource(gvr, false, searcher.informer)
+	defer func() {
+		delete(resources, "widgets.example.io")
+		delete(resources, "widgets")
+		clusterResources = clusterResources[:len(clusterResources)-2]
+	}()
+
+	for _, key := range []string{"widgets.example.io", "widgets"} {
+		if _, ok := resources[key]; !ok {
+			t.Errorf("expected %s to be registered", key)
+		}
+	}
+
+	if _, err := ListResources("default", "widgets", &params.Conditions{}, Name, false, -1, 0); err == nil {
+		t.Error("expected cluster resource not found in namespace")
+	}
+
+	result, err := ListResources("", "widgets.example.io", &params.Conditions{}, Name, false, 10, 0)
+
+	if err != nil || result.TotalCount != 0 {
+		t.Errorf("expected empty result, got %v %v", result, err)
+	}
+}
@@ -47,6 +47,11 @@ func daemonSetStatus(item *v1.DaemonSet) string {
 }
 
 // Exactly Match
+func (s *daemonSetSearcher) matches(conditions *params.Conditions, item interface{}) bool {
+	object, ok := item.(*v1.DaemonSet)
+	return ok && s.match(conditions.Match, object) && s.fuzzy(conditions.Fuzzy, object)
+}
+
 func (*daemonSetSearcher) match(match map[string]string, item *v1.DaemonSet) bool {
 	for k, v := range match {
 		switch k {
@@ -51,6 +51,11 @@ func deploymentStatus(item *v1.Deployment) string {
 }
 
 // Exactly Match
+func (s *deploymentSearcher) matches(conditions *params.Conditions, item interface{}) bool {
+	object, ok := item.(*v1.Deployment)
+	return ok && s.match(conditions.Match, object) && s.fuzzy(conditions.Fuzzy, object)
+}
+
 func (*deploymentSearcher) match(match map[string]string, item *v1.Deployment) bool {
 	for k, v := range match {
 		switch k {
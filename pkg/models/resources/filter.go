@@ -0,0 +1,193 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package resources
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	s2iv1alpha1 "github.com/kubesphere/s2ioperator/pkg/apis/devops/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"kubesphere.io/kubesphere/pkg/params"
+)
+
+const (
+	labelsPrefix      = "labels."
+	annotationsPrefix = "annotations."
+)
+
+// objectFields resolves fields of any kubernetes object for params.Filter:
+// name, namespace, status, createTime, ownerKind, ownerName, labels.<key>, annotations.<key>,
+// any other field is a dot separated path of the object, such as spec.nodeName or spec.template.spec.containers.image
+type objectFields struct {
+	item     interface{}
+	object   metav1.Object
+	content  map[string]interface{}
+	resolved bool
+}
+
+func newObjectFields(item interface{}) (*objectFields, error) {
+	object, err := meta.Accessor(item)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &objectFields{item: item, object: object}, nil
+}
+
+func (f *objectFields) Values(field string) []string {
+	switch {
+	case field == Name:
+		return []string{f.object.GetName()}
+	case field == "namespace":
+		return []string{f.object.GetNamespace()}
+	case field == CreateTime:
+		return []string{f.object.GetCreationTimestamp().UTC().Format(time.RFC3339)}
+	case field == Status:
+		if status, ok := objectStatus(f.item); ok {
+			return []string{status}
+		}
+		return nil
+	case field == OwnerKind, field == OwnerName:
+		values := make([]string, 0)
+		for _, owner := range f.object.GetOwnerReferences() {
+			if field == OwnerKind {
+				values = append(values, owner.Kind)
+			} else {
+				values = append(values, owner.Name)
+			}
+		}
+		if len(values) == 0 {
+			return nil
+		}
+		return values
+	case strings.HasPrefix(field, labelsPrefix):
+		return mapValue(f.object.GetLabels(), strings.TrimPrefix(field, labelsPrefix))
+	case strings.HasPrefix(field, annotationsPrefix):
+		return mapValue(f.object.GetAnnotations(), strings.TrimPrefix(field, annotationsPrefix))
+	default:
+		return pathValues(f.unstructuredContent(), strings.Split(field, "."))
+	}
+}
+
+func (f *objectFields) unstructuredContent() map[string]interface{} {
+	if !f.resolved {
+		f.resolved = true
+		if item, ok := f.item.(*unstructured.Unstructured); ok {
+			f.content = item.Object
+		} else if content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(f.item); err == nil {
+			f.content = content
+		} else {
+			glog.Warningln("convert to unstructured", err)
+		}
+	}
+	return f.content
+}
+
+func mapValue(m map[string]string, key string) []string {
+	if value, ok := m[key]; ok {
+		return []string{value}
+	}
+	return nil
+}
+
+// pathValues collects values of the path, elements of slices are all visited
+func pathValues(value interface{}, path []string) []string {
+	if len(path) == 0 {
+		switch v := value.(type) {
+		case nil:
+			return nil
+		case map[string]interface{}:
+			return nil
+		case []interface{}:
+			var values []string
+			for _, item := range v {
+				values = append(values, pathValues(item, path)...)
+			}
+			return values
+		default:
+			return []string{fmt.Sprint(v)}
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return pathValues(v[path[0]], path[1:])
+	case []interface{}:
+		var values []string
+		for _, item := range v {
+			values = append(values, pathValues(item, path)...)
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+// objectStatus returns the same status as the status condition of searchers
+func objectStatus(item interface{}) (string, bool) {
+	switch v := item.(type) {
+	case *appsv1.Deployment:
+		return deploymentStatus(v), true
+	case *appsv1.StatefulSet:
+		return statefulSetStatus(v), true
+	case *appsv1.DaemonSet:
+		return daemonSetStatus(v), true
+	case *batchv1.Job:
+		return jobStatus(v), true
+	case *batchv1beta1.CronJob:
+		return cronJobStatus(v), true
+	case *corev1.PersistentVolumeClaim:
+		return pvcStatus(v), true
+	case *s2iv1alpha1.S2iRun:
+		return string(v.Status.RunState), true
+	case *unstructured.Unstructured:
+		return unstructuredStatus(v), true
+	}
+	return "", false
+}
+
+func filterResources(items []interface{}, filter *params.Filter) []interface{} {
+	result := make([]interface{}, 0)
+
+	for _, item := range items {
+		fields, err := newObjectFields(item)
+
+		if err != nil {
+			glog.Warningln("filter resources", err)
+			continue
+		}
+
+		if filter.Match(fields) {
+			result = append(result, item)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,91 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package resources
+
+import (
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"kubesphere.io/kubesphere/pkg/params"
+)
+
+func newDeployment(name string, replicas, ready int32, labels map[string]string, image string, created time.Time) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels, CreationTimestamp: metav1.NewTime(created)},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: name, Image: image}}},
+			},
+		},
+		Status: appsv1.DeploymentStatus{ReadyReplicas: ready},
+	}
+}
+
+func TestFilterResources(t *testing.T) {
+	created := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	items := []interface{}{
+		newDeployment("web", 2, 2, map[string]string{"app": "web"}, "nginx:1.15", created),
+		newDeployment("api", 2, 1, map[string]string{"app": "api", "tier": "backend"}, "golang:1.12", created.Add(24*time.Hour)),
+		newDeployment("db", 0, 0, map[string]string{"app": "db", "tier": "backend"}, "mysql:5.7", created.Add(48*time.Hour)),
+	}
+
+	tests := []struct {
+		filter   string
+		expected []string
+	}{
+		{"status=running || status=stopped", []string{"web", "db"}},
+		{"labels.tier=backend, status!=stopped", []string{"api"}},
+		{"labels.app in (web, db)", []string{"web", "db"}},
+		{"!labels.tier", []string{"web"}},
+		{"createTime>2019-06-01", []string{"api", "db"}},
+		{"createTime>=2019-06-02, createTime<2019-06-03", []string{"api"}},
+		{"spec.template.spec.containers.image~nginx", []string{"web"}},
+		{"spec.replicas>=2", []string{"web", "api"}},
+		{"namespace=default, !(name=web)", []string{"api", "db"}},
+		{"ownerKind", []string{}},
+	}
+
+	for _, test := range tests {
+		filter, err := params.ParseFilter(test.filter)
+		if err != nil {
+			t.Fatalf("%s: %v", test.filter, err)
+		}
+
+		got := make([]string, 0)
+		for _, item := range filterResources(items, filter) {
+			got = append(got, item.(*appsv1.Deployment).Name)
+		}
+
+		if len(got) != len(test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.filter, test.expected, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != test.expected[i] {
+				t.Errorf("%s: expected %v, got %v", test.filter, test.expected, got)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,301 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package resources
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/tools/cache"
+
+	"kubesphere.io/kubesphere/pkg/constants"
+	"kubesphere.io/kubesphere/pkg/utils/sliceutil"
+)
+
+const (
+	// prefixes of tokens are indexed for partial match, such as "pay" for "payments"
+	minPrefixLength = 2
+	maxPrefixLength = 32
+)
+
+var (
+	searchIndex = newInvertedIndex()
+	indexOnce   sync.Once
+
+	// api groups of built-in resources, the group of custom resources is registered with them
+	resourceGroups = map[string]string{
+		ConfigMaps:             "",
+		CronJobs:               "batch",
+		DaemonSets:             "apps",
+		Deployments:            "apps",
+		Ingresses:              "extensions",
+		Jobs:                   "batch",
+		PersistentVolumeClaims: "",
+		Secrets:                "",
+		Services:               "",
+		StatefulSets:           "apps",
+		Pods:                   "",
+		Roles:                  "rbac.authorization.k8s.io",
+		S2iBuilders:            "devops.kubesphere.io",
+		S2iRuns:                "devops.kubesphere.io",
+		Nodes:                  "",
+		Namespaces:             "",
+		ClusterRoles:           "rbac.authorization.k8s.io",
+		StorageClasses:         "storage.k8s.io",
+		S2iBuilderTemplates:    "devops.kubesphere.io",
+		Workspaces:             "tenant.kubesphere.io",
+	}
+)
+
+// IndexedObject is a candidate of index search
+type IndexedObject struct {
+	Resource    string
+	Namespace   string
+	Name        string
+	DisplayName string
+	Object      interface{}
+}
+
+type indexedDocument struct {
+	IndexedObject
+	tokens []string
+}
+
+// invertedIndex maps tokens of names, display names and labels to objects
+type invertedIndex struct {
+	lock      sync.RWMutex
+	documents map[string]*indexedDocument
+	postings  map[string]map[string]bool
+}
+
+func newInvertedIndex() *invertedIndex {
+	return &invertedIndex{documents: make(map[string]*indexedDocument), postings: make(map[string]map[string]bool)}
+}
+
+func documentKey(resource, namespace, name string) string {
+	return resource + "/" + namespace + "/" + name
+}
+
+// tokenize splits text into lower case words, such as "payments" and "api" of "Payments-API"
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func prefixes(token string) []string {
+	runes := []rune(token)
+	result := make([]string, 0)
+	for i := minPrefixLength; i <= len(runes) && i <= maxPrefixLength; i++ {
+		result = append(result, string(runes[:i]))
+	}
+	if len(runes) < minPrefixLength || len(runes) > maxPrefixLength {
+		result = append(result, token)
+	}
+	return result
+}
+
+func (i *invertedIndex) update(resource string, obj interface{}) {
+	object, err := meta.Accessor(obj)
+
+	if err != nil {
+		glog.Warningln("index", resource, err)
+		return
+	}
+
+	doc := &indexedDocument{IndexedObject: IndexedObject{
+		Resource:    resource,
+		Namespace:   object.GetNamespace(),
+		Name:        object.GetName(),
+		DisplayName: object.GetAnnotations()[constants.DisplayNameAnnotationKey],
+		Object:      obj,
+	}}
+
+	words := append(tokenize(doc.Name), tokenize(doc.DisplayName)...)
+	for _, value := range object.GetLabels() {
+		words = append(words, tokenize(value)...)
+	}
+
+	tokens := make(map[string]bool)
+	for _, word := range words {
+		for _, prefix := range prefixes(word) {
+			tokens[prefix] = true
+		}
+	}
+
+	for token := range tokens {
+		doc.tokens = append(doc.tokens, token)
+	}
+
+	key := documentKey(resource, doc.Namespace, doc.Name)
+
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	i.removeLocked(key)
+
+	i.documents[key] = doc
+
+	for _, token := range doc.tokens {
+		posting, ok := i.postings[token]
+		if !ok {
+			posting = make(map[string]bool)
+			i.postings[token] = posting
+		}
+		posting[key] = true
+	}
+}
+
+func (i *invertedIndex) delete(resource string, obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+
+	object, err := meta.Accessor(obj)
+
+	if err != nil {
+		glog.Warningln("index", resource, err)
+		return
+	}
+
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	i.removeLocked(documentKey(resource, object.GetNamespace(), object.GetName()))
+}
+
+func (i *invertedIndex) removeLocked(key string) {
+	doc, ok := i.documents[key]
+
+	if !ok {
+		return
+	}
+
+	for _, token := range doc.tokens {
+		if posting, ok := i.postings[token]; ok {
+			delete(posting, key)
+			if len(posting) == 0 {
+				delete(i.postings, token)
+			}
+		}
+	}
+
+	delete(i.documents, key)
+}
+
+// search returns objects containing all words of the query, as whole words or prefixes
+func (i *invertedIndex) search(query string, accept func(object *IndexedObject) bool) []IndexedObject {
+	words := tokenize(query)
+
+	result := make([]IndexedObject, 0)
+
+	if len(words) == 0 {
+		return result
+	}
+
+	for j, word := range words {
+		if len([]rune(word)) > maxPrefixLength {
+			words[j] = string([]rune(word)[:maxPrefixLength])
+		}
+	}
+
+	i.lock.RLock()
+	defer i.lock.RUnlock()
+
+	// start from the shortest posting list
+	sort.Slice(words, func(a, b int) bool {
+		return len(i.postings[words[a]]) < len(i.postings[words[b]])
+	})
+
+	for key := range i.postings[words[0]] {
+		matched := true
+		for _, word := range words[1:] {
+			if !i.postings[word][key] {
+				matched = false
+				break
+			}
+		}
+
+		if doc := i.documents[key]; matched && accept(&doc.IndexedObject) {
+			result = append(result, doc.IndexedObject)
+		}
+	}
+
+	return result
+}
+
+// StartIndex maintains the index of all registered resources by informer events,
+// it must be called after informers and custom resources are registered.
+func StartIndex() {
+	indexOnce.Do(func() {
+		names := make([]string, 0, len(resources))
+		for name := range resources {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		// custom resources are registered by more than one name
+		indexed := make(map[cache.SharedIndexInformer]bool)
+
+		for _, name := range names {
+			informer := resourceInformer(name)
+
+			if informer == nil || indexed[informer] {
+				continue
+			}
+
+			indexed[informer] = true
+			resource := name
+
+			informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+				AddFunc: func(obj interface{}) {
+					searchIndex.update(resource, obj)
+				},
+				UpdateFunc: func(oldObj, newObj interface{}) {
+					searchIndex.update(resource, newObj)
+				},
+				DeleteFunc: func(obj interface{}) {
+					searchIndex.delete(resource, obj)
+				},
+			})
+		}
+	})
+}
+
+// SearchIndex returns indexed objects containing all words of the query in their names, display names or labels,
+// objects are filtered by accept, which is called with the index locked and must not block.
+func SearchIndex(query string, accept func(object *IndexedObject) bool) []IndexedObject {
+	return searchIndex.search(query, accept)
+}
+
+// IsClusterResource reports whether the resource is not namespaced
+func IsClusterResource(resource string) bool {
+	return sliceutil.HasString(clusterResources, resource)
+}
+
+// ResourceGroup returns the api group of the resource
+func ResourceGroup(resource string) string {
+	if searcher, ok := resources[resource].(*unstructuredSearcher); ok {
+		return searcher.resource.Group
+	}
+	return resourceGroups[resource]
+}
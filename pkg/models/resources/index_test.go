@@ -0,0 +1,102 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package resources
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/tools/cache"
+
+	"kubesphere.io/kubesphere/pkg/constants"
+)
+
+func indexedNames(objects []IndexedObject) []string {
+	result := make([]string, 0)
+	for _, object := range objects {
+		result = append(result, object.Namespace+"/"+object.Name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func TestInvertedIndex(t *testing.T) {
+	index := newInvertedIndex()
+	all := func(*IndexedObject) bool { return true }
+
+	payments := newUnstructured("shop", "payments-api", "", map[string]string{"app": "billing"}, time.Now())
+	payments.SetAnnotations(map[string]string{constants.DisplayNameAnnotationKey: "Payment Gateway"})
+
+	index.update(Deployments, payments)
+	index.update(Deployments, newUnstructured("shop", "orders", "", map[string]string{"app": "billing"}, time.Now()))
+	index.update(Services, newUnstructured("dev", "payments", "", nil, time.Now()))
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"payments", []string{"dev/payments", "shop/payments-api"}},
+		{"PAY", []string{"dev/payments", "shop/payments-api"}},
+		{"payments api", []string{"shop/payments-api"}},
+		{"gateway", []string{"shop/payments-api"}},
+		{"billing", []string{"shop/orders", "shop/payments-api"}},
+		{"p", []string{}},
+		{"-", []string{}},
+		{"refund", []string{}},
+	}
+
+	for _, test := range tests {
+		if got := indexedNames(index.search(test.query, all)); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.query, test.expected, got)
+		}
+	}
+
+	got := indexedNames(index.search("payments", func(object *IndexedObject) bool { return object.Namespace == "dev" }))
+	if !reflect.DeepEqual(got, []string{"dev/payments"}) {
+		t.Errorf("expected objects to be filtered, got %v", got)
+	}
+
+	// renamed labels are reindexed
+	updated := newUnstructured("shop", "orders", "", map[string]string{"app": "checkout"}, time.Now())
+	index.update(Deployments, updated)
+
+	if got := indexedNames(index.search("billing", all)); !reflect.DeepEqual(got, []string{"shop/payments-api"}) {
+		t.Errorf("expected orders to be reindexed, got %v", got)
+	}
+
+	index.delete(Deployments, cache.DeletedFinalStateUnknown{Key: "shop/orders", Obj: updated})
+	index.delete(Deployments, payments)
+
+	if got := indexedNames(index.search("payments", all)); !reflect.DeepEqual(got, []string{"dev/payments"}) {
+		t.Errorf("expected deleted objects to be removed, got %v", got)
+	}
+
+	if len(index.documents) != 1 {
+		t.Errorf("expected 1 document, got %d", len(index.documents))
+	}
+
+	for token, posting := range index.postings {
+		for key := range posting {
+			if _, ok := index.documents[key]; !ok {
+				t.Errorf("token %s refers to removed document %s", token, key)
+			}
+		}
+	}
+}
@@ -38,6 +38,11 @@ func (*ingressSearcher) get(namespace, name string) (interface{}, error) {
 }
 
 // exactly Match
+func (s *ingressSearcher) matches(conditions *params.Conditions, item interface{}) bool {
+	object, ok := item.(*extensions.Ingress)
+	return ok && s.match(conditions.Match, object) && s.fuzzy(conditions.Fuzzy, object)
+}
+
 func (*ingressSearcher) match(match map[string]string, item *extensions.Ingress) bool {
 	for k, v := range match {
 		switch k {
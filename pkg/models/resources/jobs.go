@@ -51,6 +51,11 @@ func jobStatus(item *batchv1.Job) string {
 }
 
 // Exactly Match
+func (s *jobSearcher) matches(conditions *params.Conditions, item interface{}) bool {
+	object, ok := item.(*batchv1.Job)
+	return ok && s.match(conditions.Match, object) && s.fuzzy(conditions.Fuzzy, object)
+}
+
 func (*jobSearcher) match(match map[string]string, item *batchv1.Job) bool {
 	for k, v := range match {
 		switch k {
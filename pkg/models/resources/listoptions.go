@@ -0,0 +1,355 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package resources
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"kubesphere.io/kubesphere/pkg/models"
+	"kubesphere.io/kubesphere/pkg/params"
+	"kubesphere.io/kubesphere/pkg/utils/idutils"
+	"kubesphere.io/kubesphere/pkg/utils/sliceutil"
+)
+
+const (
+	// snapshots not accessed within the ttl are released, continue tokens of them are expired
+	snapshotTTL  = 5 * time.Minute
+	maxSnapshots = 256
+)
+
+var (
+	ErrInvalidContinue = errors.New("invalid continue token")
+	ErrContinueExpired = errors.New("continue token is expired, list again without continue")
+
+	snapshots = &snapshotStore{snapshots: make(map[string]*snapshot)}
+)
+
+type ListOptions struct {
+	// page size, -1 means no limit
+	Limit  int
+	Offset int
+	// Cursor enables continue tokens, pages are listed from a snapshot of the first page,
+	// so they do not shift when resources are created or deleted. Offset is ignored.
+	Cursor bool
+	// token of the next page returned by the previous page
+	Continue string
+	// paths of fields to return, such as metadata.name, empty means the whole object
+	Fields []string
+	// return rows of fields rather than objects
+	Table bool
+}
+
+type continueToken struct {
+	ID     string `json:"id"`
+	Offset int    `json:"offset"`
+}
+
+type snapshot struct {
+	query           string
+	items           []interface{}
+	resourceVersion string
+	expires         time.Time
+}
+
+type snapshotStore struct {
+	lock      sync.Mutex
+	snapshots map[string]*snapshot
+}
+
+func (s *snapshotStore) add(snap *snapshot) string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := time.Now()
+
+	for id, item := range s.snapshots {
+		if item.expires.Before(now) {
+			delete(s.snapshots, id)
+		}
+	}
+
+	// release the snapshot expiring first
+	if len(s.snapshots) >= maxSnapshots {
+		oldest := ""
+		for id, item := range s.snapshots {
+			if oldest == "" || item.expires.Before(s.snapshots[oldest].expires) {
+				oldest = id
+			}
+		}
+		delete(s.snapshots, oldest)
+	}
+
+	id := idutils.GetUuid("")
+	snap.expires = now.Add(snapshotTTL)
+	s.snapshots[id] = snap
+
+	return id
+}
+
+func (s *snapshotStore) get(id string) (*snapshot, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	snap, ok := s.snapshots[id]
+
+	if !ok || snap.expires.Before(time.Now()) {
+		delete(s.snapshots, id)
+		return nil, false
+	}
+
+	snap.expires = time.Now().Add(snapshotTTL)
+
+	return snap, true
+}
+
+func (s *snapshotStore) remove(id string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.snapshots, id)
+}
+
+func encodeContinue(token continueToken) string {
+	data, _ := json.Marshal(token)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeContinue(value string) (*continueToken, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+
+	if err != nil {
+		return nil, ErrInvalidContinue
+	}
+
+	token := &continueToken{}
+
+	if err := json.Unmarshal(data, token); err != nil || token.ID == "" || token.Offset < 0 {
+		return nil, ErrInvalidContinue
+	}
+
+	return token, nil
+}
+
+// queryKey identifies a list, a continue token is only valid for the same list
+func queryKey(namespace, resource string, conditions *params.Conditions, orderBy string, reverse bool) string {
+	sortedPairs := func(m map[string]string, op string) string {
+		pairs := make([]string, 0, len(m))
+		for k, v := range m {
+			pairs = append(pairs, k+op+v)
+		}
+		sort.Strings(pairs)
+		return strings.Join(pairs, ",")
+	}
+
+	return fmt.Sprintf("%s/%s?%s,%s&%s&%s&%t", namespace, resource,
+		sortedPairs(conditions.Match, "="), sortedPairs(conditions.Fuzzy, "~"), conditions.Filter, orderBy, reverse)
+}
+
+// maxResourceVersion returns the largest resource version of items, which is the version the snapshot is consistent with
+func maxResourceVersion(items []interface{}) string {
+	var max uint64
+	for _, item := range items {
+		if object, err := meta.Accessor(item); err == nil {
+			if version, err := strconv.ParseUint(object.GetResourceVersion(), 10, 64); err == nil && version > max {
+				max = version
+			}
+		}
+	}
+	if max == 0 {
+		return ""
+	}
+	return strconv.FormatUint(max, 10)
+}
+
+// ListResourcesWithOptions lists resources like ListResources, with continue tokens, projection of fields and table output,
+// the result is *models.PageableResponse or *models.TableResponse if options.Table is set.
+func ListResourcesWithOptions(namespace, resource string, conditions *params.Conditions, orderBy string, reverse bool, options *ListOptions) (interface{}, error) {
+	var result []interface{}
+	var resourceVersion, next string
+
+	query := queryKey(namespace, resource, conditions, orderBy, reverse)
+	offset := options.Offset
+
+	if options.Continue != "" {
+		token, err := decodeContinue(options.Continue)
+
+		if err != nil {
+			return nil, err
+		}
+
+		snap, ok := snapshots.get(token.ID)
+
+		if !ok {
+			return nil, ErrContinueExpired
+		}
+
+		if snap.query != query {
+			return nil, ErrInvalidContinue
+		}
+
+		result, resourceVersion, offset = snap.items, snap.resourceVersion, token.Offset
+
+		if options.Limit == -1 || offset+options.Limit >= len(result) {
+			snapshots.remove(token.ID)
+		} else {
+			next = encodeContinue(continueToken{ID: token.ID, Offset: offset + options.Limit})
+		}
+	} else {
+		var err error
+		result, err = searchResources(namespace, resource, conditions, orderBy, reverse)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if options.Cursor {
+			offset = 0
+			resourceVersion = maxResourceVersion(result)
+			if options.Limit != -1 && options.Limit < len(result) {
+				id := snapshots.add(&snapshot{query: query, items: result, resourceVersion: resourceVersion})
+				next = encodeContinue(continueToken{ID: id, Offset: options.Limit})
+			}
+		}
+	}
+
+	items := make([]interface{}, 0)
+
+	for i, item := range result {
+		if i >= offset && (options.Limit == -1 || len(items) < options.Limit) {
+			items = append(items, injector.addExtraAnnotations(item))
+		}
+	}
+
+	if options.Table {
+		columns := options.Fields
+		if len(columns) == 0 {
+			columns = defaultColumns(resource)
+		}
+		return &models.TableResponse{Columns: columns, Rows: tableRows(items, columns), TotalCount: len(result), Continue: next, ResourceVersion: resourceVersion}, nil
+	}
+
+	if len(options.Fields) > 0 {
+		for i, item := range items {
+			items[i] = projectFields(item, options.Fields)
+		}
+	}
+
+	return &models.PageableResponse{Items: items, TotalCount: len(result), Continue: next, ResourceVersion: resourceVersion}, nil
+}
+
+func defaultColumns(resource string) []string {
+	if sliceutil.HasString(clusterResources, resource) {
+		return []string{Name, Status, CreateTime}
+	}
+	return []string{Name, "namespace", Status, CreateTime}
+}
+
+// tableRows resolves cells like fields of filters, such as name, status, labels.app and spec.nodeName
+func tableRows(items []interface{}, columns []string) [][]interface{} {
+	rows := make([][]interface{}, 0, len(items))
+
+	for _, item := range items {
+		row := make([]interface{}, len(columns))
+
+		if fields, err := newObjectFields(item); err == nil {
+			for i, column := range columns {
+				values := fields.Values(column)
+				switch len(values) {
+				case 0:
+					row[i] = nil
+				case 1:
+					row[i] = values[0]
+				default:
+					row[i] = values
+				}
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+// projectFields returns the object with only the fields of paths, elements of lists are projected respectively
+func projectFields(item interface{}, paths []string) interface{} {
+	fields, err := newObjectFields(item)
+
+	if err != nil {
+		return item
+	}
+
+	content := fields.unstructuredContent()
+	result := make(map[string]interface{})
+
+	for _, path := range paths {
+		copyPath(result, content, strings.Split(path, "."))
+	}
+
+	return result
+}
+
+func copyPath(dst map[string]interface{}, src map[string]interface{}, path []string) {
+	value, ok := src[path[0]]
+
+	if !ok {
+		return
+	}
+
+	// values may be shared with the informer cache
+	if len(path) == 1 {
+		dst[path[0]] = runtime.DeepCopyJSONValue(value)
+		return
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		child, ok := dst[path[0]].(map[string]interface{})
+		if !ok {
+			child = make(map[string]interface{})
+			dst[path[0]] = child
+		}
+		copyPath(child, v, path[1:])
+	case []interface{}:
+		list, ok := dst[path[0]].([]interface{})
+		if !ok || len(list) != len(v) {
+			list = make([]interface{}, len(v))
+			dst[path[0]] = list
+		}
+		for i, element := range v {
+			if element, ok := element.(map[string]interface{}); ok {
+				child, ok := list[i].(map[string]interface{})
+				if !ok {
+					child = make(map[string]interface{})
+					list[i] = child
+				}
+				copyPath(child, element, path[1:])
+			}
+		}
+	}
+}
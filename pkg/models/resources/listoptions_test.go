@@ -0,0 +1,193 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package resources
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"kubesphere.io/kubesphere/pkg/models"
+	"kubesphere.io/kubesphere/pkg/params"
+)
+
+func registerTestResource(t *testing.T, items ...*unstructured.Unstructured) (*unstructuredSearcher, func()) {
+	searcher := newTestSearcher(t, true, items...)
+	gvr := schema.GroupVersionResource{Group: "example.io", Version: "v1", Resource: "gadgets"}
+
+	registerResource(gvr, true, searcher.informer)
+
+	return searcher, func() {
+		delete(resources, "gadgets.example.io")
+		delete(resources, "gadgets")
+	}
+}
+
+func pageNames(t *testing.T, result interface{}) []string {
+	page, ok := result.(*models.PageableResponse)
+	if !ok {
+		t.Fatalf("expected pageable response, got %T", result)
+	}
+	return names(page.Items)
+}
+
+func TestListWithContinue(t *testing.T) {
+	now := time.Now()
+	items := make([]*unstructured.Unstructured, 0)
+	for i, name := range []string{"a", "b", "c", "d", "e"} {
+		item := newUnstructured("default", name, "", nil, now.Add(time.Duration(i)*time.Second))
+		item.SetResourceVersion(string(rune('1' + i)))
+		items = append(items, item)
+	}
+
+	searcher, cleanup := registerTestResource(t, items...)
+	defer cleanup()
+
+	conditions := &params.Conditions{}
+	options := &ListOptions{Limit: 2, Cursor: true}
+
+	result, err := ListResourcesWithOptions("default", "gadgets", conditions, Name, false, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	page := result.(*models.PageableResponse)
+	if got := names(page.Items); !reflect.DeepEqual(got, []string{"a", "b"}) || page.Continue == "" || page.TotalCount != 5 || page.ResourceVersion != "5" {
+		t.Fatalf("unexpected first page %v %+v", got, page)
+	}
+
+	// pages do not shift when resources are created
+	if err := searcher.informer.GetIndexer().Add(newUnstructured("default", "0", "", nil, now)); err != nil {
+		t.Fatal(err)
+	}
+
+	options.Continue = page.Continue
+	result, err = ListResourcesWithOptions("default", "gadgets", conditions, Name, false, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	page = result.(*models.PageableResponse)
+	if got := names(page.Items); !reflect.DeepEqual(got, []string{"c", "d"}) || page.Continue == "" {
+		t.Fatalf("unexpected second page %v %+v", got, page)
+	}
+
+	// a token is only valid for the same query
+	options.Continue = page.Continue
+	if _, err := ListResourcesWithOptions("default", "gadgets", conditions, CreateTime, false, options); err != ErrInvalidContinue {
+		t.Errorf("expected invalid continue, got %v", err)
+	}
+
+	result, err = ListResourcesWithOptions("default", "gadgets", conditions, Name, false, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	page = result.(*models.PageableResponse)
+	if got := names(page.Items); !reflect.DeepEqual(got, []string{"e"}) || page.Continue != "" {
+		t.Fatalf("unexpected last page %v %+v", got, page)
+	}
+
+	// the snapshot is released after the last page
+	if _, err := ListResourcesWithOptions("default", "gadgets", conditions, Name, false, options); err != ErrContinueExpired {
+		t.Errorf("expected expired continue, got %v", err)
+	}
+
+	options.Continue = "invalid"
+	if _, err := ListResourcesWithOptions("default", "gadgets", conditions, Name, false, options); err != ErrInvalidContinue {
+		t.Errorf("expected invalid continue, got %v", err)
+	}
+
+	// offset paging without cursor
+	result, err = ListResourcesWithOptions("default", "gadgets", conditions, Name, false, &ListOptions{Limit: 2, Offset: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := pageNames(t, result); !reflect.DeepEqual(got, []string{"b", "c"}) {
+		t.Errorf("unexpected page %v", got)
+	}
+}
+
+func TestListWithFields(t *testing.T) {
+	item := newUnstructured("default", "a", "Running", map[string]string{"app": "web"}, time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC))
+	unstructured.SetNestedSlice(item.Object, []interface{}{
+		map[string]interface{}{"name": "web", "image": "nginx"},
+		map[string]interface{}{"name": "sidecar", "image": "envoy"},
+	}, "spec", "containers")
+
+	_, cleanup := registerTestResource(t, item)
+	defer cleanup()
+
+	options := &ListOptions{Limit: -1, Fields: []string{"metadata.name", "spec.containers.image", "status"}}
+
+	result, err := ListResourcesWithOptions("default", "gadgets", &params.Conditions{}, Name, false, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "a"},
+		"spec": map[string]interface{}{"containers": []interface{}{
+			map[string]interface{}{"image": "nginx"},
+			map[string]interface{}{"image": "envoy"},
+		}},
+		"status": map[string]interface{}{"phase": "Running"},
+	}
+
+	page := result.(*models.PageableResponse)
+	if len(page.Items) != 1 || !reflect.DeepEqual(page.Items[0], expected) {
+		t.Errorf("expected %v, got %v", expected, page.Items)
+	}
+
+	// the cached object is not modified
+	if _, ok := item.Object["metadata"].(map[string]interface{})["labels"]; !ok {
+		t.Error("cached object is modified")
+	}
+
+	options = &ListOptions{Limit: -1, Table: true}
+
+	result, err = ListResourcesWithOptions("default", "gadgets", &params.Conditions{}, Name, false, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	table, ok := result.(*models.TableResponse)
+	if !ok {
+		t.Fatalf("expected table response, got %T", result)
+	}
+
+	if !reflect.DeepEqual(table.Columns, []string{Name, "namespace", Status, CreateTime}) ||
+		!reflect.DeepEqual(table.Rows, [][]interface{}{{"a", "default", "running", "2019-06-01T00:00:00Z"}}) {
+		t.Errorf("unexpected table %+v", table)
+	}
+
+	options.Fields = []string{"labels.app", "spec.containers.name", "spec.nodeName"}
+
+	result, err = ListResourcesWithOptions("default", "gadgets", &params.Conditions{}, Name, false, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	table = result.(*models.TableResponse)
+	if !reflect.DeepEqual(table.Rows, [][]interface{}{{"web", []string{"web", "sidecar"}, nil}}) {
+		t.Errorf("unexpected table rows %v", table.Rows)
+	}
+}
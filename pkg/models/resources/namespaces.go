@@ -37,6 +37,11 @@ func (*namespaceSearcher) get(namespace, name string) (interface{}, error) {
 }
 
 // exactly Match
+func (s *namespaceSearcher) matches(conditions *params.Conditions, item interface{}) bool {
+	object, ok := item.(*v1.Namespace)
+	return ok && s.match(conditions.Match, object) && s.fuzzy(conditions.Fuzzy, object)
+}
+
 func (*namespaceSearcher) match(match map[string]string, item *v1.Namespace) bool {
 	for k, v := range match {
 		switch k {
@@ -38,6 +38,11 @@ func (*nodeSearcher) get(namespace, name string) (interface{}, error) {
 }
 
 // exactly Match
+func (s *nodeSearcher) matches(conditions *params.Conditions, item interface{}) bool {
+	object, ok := item.(*v1.Node)
+	return ok && s.match(conditions.Match, object) && s.fuzzy(conditions.Fuzzy, object)
+}
+
 func (*nodeSearcher) match(match map[string]string, item *v1.Node) bool {
 	for k, v := range match {
 		switch k {
@@ -49,6 +49,11 @@ func pvcStatus(item *v1.PersistentVolumeClaim) string {
 }
 
 // exactly Match
+func (s *persistentVolumeClaimSearcher) matches(conditions *params.Conditions, item interface{}) bool {
+	object, ok := item.(*v1.PersistentVolumeClaim)
+	return ok && s.match(conditions.Match, object) && s.fuzzy(conditions.Fuzzy, object)
+}
+
 func (*persistentVolumeClaimSearcher) match(match map[string]string, item *v1.PersistentVolumeClaim) bool {
 	for k, v := range match {
 		switch k {
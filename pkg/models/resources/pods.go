@@ -22,6 +22,7 @@ import (
 	"kubesphere.io/kubesphere/pkg/constants"
 	"kubesphere.io/kubesphere/pkg/informers"
 	"kubesphere.io/kubesphere/pkg/params"
+	"kubesphere.io/kubesphere/pkg/utils/k8sutil"
 	"kubesphere.io/kubesphere/pkg/utils/sliceutil"
 	"sort"
 	"strings"
@@ -39,23 +40,23 @@ func (*podSearcher) get(namespace, name string) (interface{}, error) {
 
 func podBelongTo(item *v1.Pod, kind string, name string) bool {
 	switch kind {
-	case "Deployment":
+	case deploymentKind:
 		if podBelongToDeployment(item, name) {
 			return true
 		}
-	case "ReplicaSet":
+	case replicaSetKind:
 		if podBelongToReplicaSet(item, name) {
 			return true
 		}
-	case "DaemonSet":
+	case daemonSetKind:
 		if podBelongToDaemonSet(item, name) {
 			return true
 		}
-	case "StatefulSet":
+	case statefulSetKind:
 		if podBelongToStatefulSet(item, name) {
 			return true
 		}
-	case "Job":
+	case jobKind:
 		if podBelongToJob(item, name) {
 			return true
 		}
@@ -64,48 +65,23 @@ func podBelongTo(item *v1.Pod, kind string, name string) bool {
 }
 
 func replicaSetBelongToDeployment(replicaSet *appsv1.ReplicaSet, deploymentName string) bool {
-	for _, owner := range replicaSet.OwnerReferences {
-		if owner.Kind == "Deployment" && owner.Name == deploymentName {
-			return true
-		}
-	}
-	return false
+	return k8sutil.IsControlledBy(replicaSet.OwnerReferences, deploymentKind, deploymentName)
 }
 
 func podBelongToDaemonSet(item *v1.Pod, name string) bool {
-	for _, owner := range item.OwnerReferences {
-		if owner.Kind == "DaemonSet" && owner.Name == name {
-			return true
-		}
-	}
-	return false
+	return k8sutil.IsControlledBy(item.OwnerReferences, daemonSetKind, name)
 }
 
 func podBelongToJob(item *v1.Pod, name string) bool {
-	for _, owner := range item.OwnerReferences {
-		if owner.Kind == "Job" && owner.Name == name {
-			return true
-		}
-	}
-	return false
+	return k8sutil.IsControlledBy(item.OwnerReferences, jobKind, name)
 }
 
 func podBelongToReplicaSet(item *v1.Pod, replicaSetName string) bool {
-	for _, owner := range item.OwnerReferences {
-		if owner.Kind == "ReplicaSet" && owner.Name == replicaSetName {
-			return true
-		}
-	}
-	return false
+	return k8sutil.IsControlledBy(item.OwnerReferences, replicaSetKind, replicaSetName)
 }
 
 func podBelongToStatefulSet(item *v1.Pod, statefulSetName string) bool {
-	for _, owner := range item.OwnerReferences {
-		if owner.Kind == "StatefulSet" && owner.Name == statefulSetName {
-			return true
-		}
-	}
-	return false
+	return k8sutil.IsControlledBy(item.OwnerReferences, statefulSetKind, statefulSetName)
 }
 
 func podBelongToDeployment(item *v1.Pod, deploymentName string) bool {
@@ -124,13 +100,7 @@ func podBelongToDeployment(item *v1.Pod, deploymentName string) bool {
 }
 
 func podBindPVC(item *v1.Pod, pvcName string) bool {
-	for _, v := range item.Spec.Volumes {
-		if v.VolumeSource.PersistentVolumeClaim != nil &&
-			v.VolumeSource.PersistentVolumeClaim.ClaimName == pvcName {
-			return true
-		}
-	}
-	return false
+	return sliceutil.HasString(getPodReferences(item).persistentVolumeClaims, pvcName)
 }
 
 func podBelongToService(item *v1.Pod, serviceName string) bool {
@@ -139,14 +109,15 @@ func podBelongToService(item *v1.Pod, serviceName string) bool {
 		return false
 	}
 
-	selector := labels.Set(service.Spec.Selector).AsSelectorPreValidated()
-	if !selector.Matches(labels.Set(item.Labels)) {
-		return false
-	}
-	return true
+	return serviceSelectsPod(service, item)
 }
 
 // exactly Match
+func (s *podSearcher) matches(conditions *params.Conditions, item interface{}) bool {
+	object, ok := item.(*v1.Pod)
+	return ok && s.match(conditions.Match, object) && s.fuzzy(conditions.Fuzzy, object)
+}
+
 func (*podSearcher) match(match map[string]string, item *v1.Pod) bool {
 	for k, v := range match {
 		switch k {
@@ -0,0 +1,157 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package resources
+
+import (
+	"k8s.io/api/core/v1"
+	"k8s.io/api/extensions/v1beta1"
+	"k8s.io/apimachinery/pkg/labels"
+	"kubesphere.io/kubesphere/pkg/utils/sliceutil"
+)
+
+// podReferences collects names of objects referenced by the pod, optional references are reported separately
+// because a missing optional object doesn't break the pod
+type podReferences struct {
+	persistentVolumeClaims []string
+	configMaps             []string
+	secrets                []string
+	optional               []string
+}
+
+func getPodReferences(item *v1.Pod) *podReferences {
+	refs := &podReferences{}
+
+	addConfigMap := func(name string, optional *bool) {
+		if optional != nil && *optional {
+			refs.optional = append(refs.optional, configMapKind+"/"+name)
+		}
+		if !sliceutil.HasString(refs.configMaps, name) {
+			refs.configMaps = append(refs.configMaps, name)
+		}
+	}
+
+	addSecret := func(name string, optional *bool) {
+		if optional != nil && *optional {
+			refs.optional = append(refs.optional, secretKind+"/"+name)
+		}
+		if !sliceutil.HasString(refs.secrets, name) {
+			refs.secrets = append(refs.secrets, name)
+		}
+	}
+
+	for _, volume := range item.Spec.Volumes {
+		switch {
+		case volume.PersistentVolumeClaim != nil:
+			if !sliceutil.HasString(refs.persistentVolumeClaims, volume.PersistentVolumeClaim.ClaimName) {
+				refs.persistentVolumeClaims = append(refs.persistentVolumeClaims, volume.PersistentVolumeClaim.ClaimName)
+			}
+		case volume.ConfigMap != nil:
+			addConfigMap(volume.ConfigMap.Name, volume.ConfigMap.Optional)
+		case volume.Secret != nil:
+			addSecret(volume.Secret.SecretName, volume.Secret.Optional)
+		case volume.Projected != nil:
+			for _, source := range volume.Projected.Sources {
+				if source.ConfigMap != nil {
+					addConfigMap(source.ConfigMap.Name, source.ConfigMap.Optional)
+				}
+				if source.Secret != nil {
+					addSecret(source.Secret.Name, source.Secret.Optional)
+				}
+			}
+		}
+	}
+
+	containers := append(append([]v1.Container{}, item.Spec.InitContainers...), item.Spec.Containers...)
+
+	for _, container := range containers {
+		for _, from := range container.EnvFrom {
+			if from.ConfigMapRef != nil {
+				addConfigMap(from.ConfigMapRef.Name, from.ConfigMapRef.Optional)
+			}
+			if from.SecretRef != nil {
+				addSecret(from.SecretRef.Name, from.SecretRef.Optional)
+			}
+		}
+		for _, env := range container.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			if env.ValueFrom.ConfigMapKeyRef != nil {
+				addConfigMap(env.ValueFrom.ConfigMapKeyRef.Name, env.ValueFrom.ConfigMapKeyRef.Optional)
+			}
+			if env.ValueFrom.SecretKeyRef != nil {
+				addSecret(env.ValueFrom.SecretKeyRef.Name, env.ValueFrom.SecretKeyRef.Optional)
+			}
+		}
+	}
+
+	for _, secret := range item.Spec.ImagePullSecrets {
+		addSecret(secret.Name, nil)
+	}
+
+	return refs
+}
+
+// serviceSelectsPod reports whether the pod is selected by the service, services without selector select nothing
+func serviceSelectsPod(service *v1.Service, item *v1.Pod) bool {
+	if service.Namespace != item.Namespace || len(service.Spec.Selector) == 0 {
+		return false
+	}
+	return labels.Set(service.Spec.Selector).AsSelectorPreValidated().Matches(labels.Set(item.Labels))
+}
+
+// ingressBackendServices returns names of services the ingress routes to, including the default backend
+func ingressBackendServices(ingress *v1beta1.Ingress) []string {
+	services := make([]string, 0)
+
+	if ingress.Spec.Backend != nil && ingress.Spec.Backend.ServiceName != "" {
+		services = append(services, ingress.Spec.Backend.ServiceName)
+	}
+
+	for _, rule := range ingress.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			if !sliceutil.HasString(services, path.Backend.ServiceName) {
+				services = append(services, path.Backend.ServiceName)
+			}
+		}
+	}
+
+	return services
+}
+
+// IngressRulesForService returns rules of the ingress which have at least one path routed to the service
+func IngressRulesForService(ingress *v1beta1.Ingress, serviceName string) []v1beta1.IngressRule {
+	rules := make([]v1beta1.IngressRule, 0)
+
+	for _, rule := range ingress.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			if path.Backend.ServiceName == serviceName {
+				rules = append(rules, rule)
+				break
+			}
+		}
+	}
+
+	return rules
+}
@@ -111,6 +111,8 @@ const (
 type resourceSearchInterface interface {
 	get(namespace, name string) (interface{}, error)
 	search(namespace string, conditions *params.Conditions, orderBy string, reverse bool) ([]interface{}, error)
+	// matches evaluates the match and fuzzy conditions of search on a single object
+	matches(conditions *params.Conditions, item interface{}) bool
 }
 
 func GetResource(namespace, resource, name string) (interface{}, error) {
@@ -127,6 +129,23 @@ func GetResource(namespace, resource, name string) (interface{}, error) {
 
 func ListResources(namespace, resource string, conditions *params.Conditions, orderBy string, reverse bool, limit, offset int) (*models.PageableResponse, error) {
 	items := make([]interface{}, 0)
+
+	result, err := searchResources(namespace, resource, conditions, orderBy, reverse)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i, item := range result {
+		if i >= offset && (limit == -1 || len(items) < limit) {
+			items = append(items, injector.addExtraAnnotations(item))
+		}
+	}
+
+	return &models.PageableResponse{TotalCount: len(result), Items: items}, nil
+}
+
+func searchResources(namespace, resource string, conditions *params.Conditions, orderBy string, reverse bool) ([]interface{}, error) {
 	var err error
 	var result []interface{}
 
@@ -148,13 +167,11 @@ func ListResources(namespace, resource string, conditions *params.Conditions, or
 		return nil, err
 	}
 
-	for i, item := range result {
-		if i >= offset && (limit == -1 || len(items) < limit) {
-			items = append(items, injector.addExtraAnnotations(item))
-		}
+	if conditions.Filter != nil {
+		result = filterResources(result, conditions.Filter)
 	}
 
-	return &models.PageableResponse{TotalCount: len(result), Items: items}, nil
+	return result, nil
 }
 
 func searchFuzzy(m map[string]string, key, value string) bool {
@@ -37,6 +37,11 @@ func (*roleSearcher) get(namespace, name string) (interface{}, error) {
 }
 
 // exactly Match
+func (s *roleSearcher) matches(conditions *params.Conditions, item interface{}) bool {
+	object, ok := item.(*rbac.Role)
+	return ok && s.match(conditions.Match, object) && s.fuzzy(conditions.Fuzzy, object)
+}
+
 func (*roleSearcher) match(match map[string]string, item *rbac.Role) bool {
 	for k, v := range match {
 		switch k {
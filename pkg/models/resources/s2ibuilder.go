@@ -37,6 +37,11 @@ func (*s2iBuilderSearcher) get(namespace, name string) (interface{}, error) {
 }
 
 // exactly Match
+func (s *s2iBuilderSearcher) matches(conditions *params.Conditions, item interface{}) bool {
+	object, ok := item.(*v1alpha1.S2iBuilder)
+	return ok && s.match(conditions.Match, object) && s.fuzzy(conditions.Fuzzy, object)
+}
+
 func (*s2iBuilderSearcher) match(match map[string]string, item *v1alpha1.S2iBuilder) bool {
 	for k, v := range match {
 		switch k {
@@ -37,6 +37,11 @@ func (*s2iBuilderTemplateSearcher) get(namespace, name string) (interface{}, err
 }
 
 // exactly Match
+func (s *s2iBuilderTemplateSearcher) matches(conditions *params.Conditions, item interface{}) bool {
+	object, ok := item.(*v1alpha1.S2iBuilderTemplate)
+	return ok && s.match(conditions.Match, object) && s.fuzzy(conditions.Fuzzy, object)
+}
+
 func (*s2iBuilderTemplateSearcher) match(match map[string]string, item *v1alpha1.S2iBuilderTemplate) bool {
 	for k, v := range match {
 		switch k {
@@ -40,6 +40,11 @@ func (*s2iRunSearcher) get(namespace, name string) (interface{}, error) {
 }
 
 // exactly Match
+func (s *s2iRunSearcher) matches(conditions *params.Conditions, item interface{}) bool {
+	object, ok := item.(*v1alpha1.S2iRun)
+	return ok && s.match(conditions.Match, object) && s.fuzzy(conditions.Fuzzy, object)
+}
+
 func (*s2iRunSearcher) match(match map[string]string, item *v1alpha1.S2iRun) bool {
 	for k, v := range match {
 		switch k {
@@ -37,6 +37,11 @@ func (*secretSearcher) get(namespace, name string) (interface{}, error) {
 }
 
 // exactly Match
+func (s *secretSearcher) matches(conditions *params.Conditions, item interface{}) bool {
+	object, ok := item.(*v1.Secret)
+	return ok && s.match(conditions.Match, object) && s.fuzzy(conditions.Fuzzy, object)
+}
+
 func (*secretSearcher) match(match map[string]string, item *v1.Secret) bool {
 	for k, v := range match {
 		switch k {
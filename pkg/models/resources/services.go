@@ -37,6 +37,11 @@ func (*serviceSearcher) get(namespace, name string) (interface{}, error) {
 }
 
 // exactly Match
+func (s *serviceSearcher) matches(conditions *params.Conditions, item interface{}) bool {
+	object, ok := item.(*v1.Service)
+	return ok && s.match(conditions.Match, object) && s.fuzzy(conditions.Fuzzy, object)
+}
+
 func (*serviceSearcher) match(match map[string]string, item *v1.Service) bool {
 	for k, v := range match {
 		switch k {
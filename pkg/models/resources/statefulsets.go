@@ -50,6 +50,11 @@ func statefulSetStatus(item *v1.StatefulSet) string {
 }
 
 // Exactly Match
+func (s *statefulSetSearcher) matches(conditions *params.Conditions, item interface{}) bool {
+	object, ok := item.(*v1.StatefulSet)
+	return ok && s.match(conditions.Match, object) && s.fuzzy(conditions.Fuzzy, object)
+}
+
 func (*statefulSetSearcher) match(match map[string]string, item *v1.StatefulSet) bool {
 	for k, v := range match {
 		switch k {
@@ -37,6 +37,11 @@ func (*storageClassesSearcher) get(namespace, name string) (interface{}, error)
 }
 
 // exactly Match
+func (s *storageClassesSearcher) matches(conditions *params.Conditions, item interface{}) bool {
+	object, ok := item.(*v1.StorageClass)
+	return ok && s.match(conditions.Match, object) && s.fuzzy(conditions.Fuzzy, object)
+}
+
 func (*storageClassesSearcher) match(match map[string]string, item *v1.StorageClass) bool {
 	for k, v := range match {
 		switch k {
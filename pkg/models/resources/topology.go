@@ -0,0 +1,634 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package resources
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	autoscalinglisters "k8s.io/client-go/listers/autoscaling/v1"
+	batchlisters "k8s.io/client-go/listers/batch/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	extensionslisters "k8s.io/client-go/listers/extensions/v1beta1"
+	"kubesphere.io/kubesphere/pkg/informers"
+	"kubesphere.io/kubesphere/pkg/utils/sliceutil"
+)
+
+const (
+	deploymentKind            = "Deployment"
+	replicaSetKind            = "ReplicaSet"
+	statefulSetKind           = "StatefulSet"
+	daemonSetKind             = "DaemonSet"
+	jobKind                   = "Job"
+	podKind                   = "Pod"
+	serviceKind               = "Service"
+	ingressKind               = "Ingress"
+	configMapKind             = "ConfigMap"
+	secretKind                = "Secret"
+	persistentVolumeClaimKind = "PersistentVolumeClaim"
+	hpaKind                   = "HorizontalPodAutoscaler"
+
+	EdgeOwns       = "owns"
+	EdgeSelects    = "selects"
+	EdgeRoutes     = "routes"
+	EdgeMounts     = "mounts"
+	EdgeReferences = "references"
+	EdgeScales     = "scales"
+
+	StatusMissing = "missing"
+)
+
+// kinds of resources the topology of which can be queried
+var topologyKinds = map[string]string{
+	Deployments:   deploymentKind,
+	"replicasets": replicaSetKind,
+	StatefulSets:  statefulSetKind,
+	DaemonSets:    daemonSetKind,
+	Jobs:          jobKind,
+	Pods:          podKind,
+	Services:      serviceKind,
+	Ingresses:     ingressKind,
+}
+
+type TopologyNode struct {
+	// Kind/name, unique in the namespace
+	ID        string `json:"id"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Status    string `json:"status,omitempty"`
+	Healthy   bool   `json:"healthy"`
+	Message   string `json:"message,omitempty"`
+}
+
+// TopologyEdge points from the owner, selector, router, consumer or scaler to the other end
+type TopologyEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Type string `json:"type"`
+}
+
+type Topology struct {
+	Nodes []TopologyNode `json:"nodes"`
+	Edges []TopologyEdge `json:"edges"`
+}
+
+type topologyListers struct {
+	deployments            appslisters.DeploymentLister
+	replicaSets            appslisters.ReplicaSetLister
+	statefulSets           appslisters.StatefulSetLister
+	daemonSets             appslisters.DaemonSetLister
+	jobs                   batchlisters.JobLister
+	pods                   corelisters.PodLister
+	services               corelisters.ServiceLister
+	configMaps             corelisters.ConfigMapLister
+	secrets                corelisters.SecretLister
+	persistentVolumeClaims corelisters.PersistentVolumeClaimLister
+	ingresses              extensionslisters.IngressLister
+	hpas                   autoscalinglisters.HorizontalPodAutoscalerLister
+}
+
+func sharedTopologyListers() *topologyListers {
+	factory := informers.SharedInformerFactory()
+	return &topologyListers{
+		deployments:            factory.Apps().V1().Deployments().Lister(),
+		replicaSets:            factory.Apps().V1().ReplicaSets().Lister(),
+		statefulSets:           factory.Apps().V1().StatefulSets().Lister(),
+		daemonSets:             factory.Apps().V1().DaemonSets().Lister(),
+		jobs:                   factory.Batch().V1().Jobs().Lister(),
+		pods:                   factory.Core().V1().Pods().Lister(),
+		services:               factory.Core().V1().Services().Lister(),
+		configMaps:             factory.Core().V1().ConfigMaps().Lister(),
+		secrets:                factory.Core().V1().Secrets().Lister(),
+		persistentVolumeClaims: factory.Core().V1().PersistentVolumeClaims().Lister(),
+		ingresses:              factory.Extensions().V1beta1().Ingresses().Lister(),
+		hpas:                   factory.Autoscaling().V1().HorizontalPodAutoscalers().Lister(),
+	}
+}
+
+// GetNamespaceTopology returns the graph of workloads, pods, services, ingresses and HPAs of the namespace,
+// config maps, secrets and persistent volume claims are included only when they are used by pods
+func GetNamespaceTopology(namespace string) (*Topology, error) {
+	graph, err := sharedTopologyListers().build(namespace)
+	if err != nil {
+		return nil, err
+	}
+	return graph.topology(nil), nil
+}
+
+// GetTopology returns the part of the namespace graph related to the object: its owners and owned objects,
+// objects used by the pods, services selecting the pods, ingresses routing to those services and HPAs scaling them
+func GetTopology(namespace, resource, name string) (*Topology, error) {
+	kind, ok := topologyKinds[resource]
+	if !ok {
+		return nil, errors.NewBadRequest(fmt.Sprintf("topology of %s is not supported", resource))
+	}
+
+	graph, err := sharedTopologyListers().build(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	root := nodeID(kind, name)
+
+	if node, ok := graph.nodes[root]; !ok || node.Status == StatusMissing {
+		return nil, errors.NewNotFound(v1.Resource(resource), name)
+	}
+
+	return graph.topology(graph.related(root)), nil
+}
+
+func nodeID(kind, name string) string {
+	return fmt.Sprintf("%s/%s", kind, name)
+}
+
+type topologyGraph struct {
+	namespace string
+	nodes     map[string]*TopologyNode
+	edges     []TopologyEdge
+	// edgeSet dedupes edges added from owner references, pod references and selectors
+	edgeSet map[TopologyEdge]bool
+	// object of each node, used to resolve owner references
+	objects map[string]metav1.Object
+}
+
+func (g *topologyGraph) addNode(kind string, object metav1.Object, status string, healthy bool, message string) {
+	id := nodeID(kind, object.GetName())
+	g.nodes[id] = &TopologyNode{ID: id, Kind: kind, Namespace: g.namespace, Name: object.GetName(), Status: status, Healthy: healthy, Message: message}
+	g.objects[id] = object
+}
+
+// ensureNode adds a placeholder for objects referenced but not found
+func (g *topologyGraph) ensureNode(kind, name string, healthy bool, message string) string {
+	id := nodeID(kind, name)
+	if _, ok := g.nodes[id]; !ok {
+		g.nodes[id] = &TopologyNode{ID: id, Kind: kind, Namespace: g.namespace, Name: name, Status: StatusMissing, Healthy: healthy, Message: message}
+	}
+	return id
+}
+
+func (g *topologyGraph) addEdge(from, to, edgeType string) {
+	edge := TopologyEdge{From: from, To: to, Type: edgeType}
+	if g.edgeSet[edge] {
+		return
+	}
+	g.edgeSet[edge] = true
+	g.edges = append(g.edges, edge)
+}
+
+func (l *topologyListers) build(namespace string) (*topologyGraph, error) {
+	g := &topologyGraph{namespace: namespace, nodes: make(map[string]*TopologyNode), edgeSet: make(map[TopologyEdge]bool), objects: make(map[string]metav1.Object)}
+
+	deployments, err := l.deployments.Deployments(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range deployments {
+		status := deploymentStatus(item)
+		g.addNode(deploymentKind, item, status, status != StatusUpdating, replicasMessage(item.Status.ReadyReplicas, item.Spec.Replicas))
+	}
+
+	replicaSets, err := l.replicaSets.ReplicaSets(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range replicaSets {
+		// replica sets scaled down by rollouts are history of the deployment
+		if item.Spec.Replicas != nil && *item.Spec.Replicas == 0 && item.Status.Replicas == 0 {
+			continue
+		}
+		healthy := item.Spec.Replicas == nil || item.Status.ReadyReplicas == *item.Spec.Replicas
+		status := StatusRunning
+		if !healthy {
+			status = StatusUpdating
+		}
+		g.addNode(replicaSetKind, item, status, healthy, replicasMessage(item.Status.ReadyReplicas, item.Spec.Replicas))
+	}
+
+	statefulSets, err := l.statefulSets.StatefulSets(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range statefulSets {
+		status := statefulSetStatus(item)
+		g.addNode(statefulSetKind, item, status, status != StatusUpdating, replicasMessage(item.Status.ReadyReplicas, item.Spec.Replicas))
+	}
+
+	daemonSets, err := l.daemonSets.DaemonSets(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range daemonSets {
+		status := daemonSetStatus(item)
+		desired := item.Status.DesiredNumberScheduled
+		g.addNode(daemonSetKind, item, status, status != StatusUpdating, replicasMessage(item.Status.NumberAvailable, &desired))
+	}
+
+	jobs, err := l.jobs.Jobs(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range jobs {
+		status := jobStatus(item)
+		g.addNode(jobKind, item, status, status != StatusFailed, "")
+	}
+
+	pods, err := l.pods.Pods(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range pods {
+		status, healthy, message := podHealth(item)
+		g.addNode(podKind, item, status, healthy, message)
+	}
+
+	services, err := l.services.Services(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range services {
+		g.addNode(serviceKind, item, "", true, "")
+	}
+
+	ingresses, err := l.ingresses.Ingresses(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range ingresses {
+		g.addNode(ingressKind, item, "", true, "")
+	}
+
+	hpas, err := l.hpas.HorizontalPodAutoscalers(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range hpas {
+		g.addNode(hpaKind, item, "", true, fmt.Sprintf("%d/%d replicas", item.Status.CurrentReplicas, item.Status.DesiredReplicas))
+	}
+
+	if err := g.addPodReferences(l, pods); err != nil {
+		return nil, err
+	}
+
+	g.addOwnerReferences()
+
+	podsByLabel := indexPodsByLabel(pods)
+
+	for _, service := range services {
+		for _, pod := range podsByLabel.candidates(service.Spec.Selector) {
+			if serviceSelectsPod(service, pod) {
+				g.addEdge(nodeID(serviceKind, service.Name), nodeID(podKind, pod.Name), EdgeSelects)
+			}
+		}
+	}
+
+	for _, ingress := range ingresses {
+		for _, service := range ingressBackendServices(ingress) {
+			to := g.ensureNode(serviceKind, service, false, "service not found")
+			g.addEdge(nodeID(ingressKind, ingress.Name), to, EdgeRoutes)
+		}
+	}
+
+	for _, hpa := range hpas {
+		g.addHPA(hpa)
+	}
+
+	g.annotateHealth()
+
+	return g, nil
+}
+
+// podLabelIndex maps key=value labels to pods having them
+type podLabelIndex map[string][]*v1.Pod
+
+func indexPodsByLabel(pods []*v1.Pod) podLabelIndex {
+	index := make(podLabelIndex)
+	for _, pod := range pods {
+		for key, value := range pod.Labels {
+			label := key + "=" + value
+			index[label] = append(index[label], pod)
+		}
+	}
+	return index
+}
+
+// candidates returns pods having the least common label of the selector, they are to be matched by the whole selector
+func (index podLabelIndex) candidates(selector map[string]string) []*v1.Pod {
+	var result []*v1.Pod
+	for key, value := range selector {
+		pods := index[key+"="+value]
+		if len(pods) == 0 {
+			return nil
+		}
+		if result == nil || len(pods) < len(result) {
+			result = pods
+		}
+	}
+	return result
+}
+
+func (g *topologyGraph) addPodReferences(l *topologyListers, pods []*v1.Pod) error {
+	for _, pod := range pods {
+		refs := getPodReferences(pod)
+		from := nodeID(podKind, pod.Name)
+
+		for _, name := range refs.persistentVolumeClaims {
+			item, err := l.persistentVolumeClaims.PersistentVolumeClaims(g.namespace).Get(name)
+			if err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+			if item != nil {
+				status := pvcStatus(item)
+				g.addNode(persistentVolumeClaimKind, item, status, status == StatusBound, "")
+			}
+			g.addEdge(from, g.ensureNode(persistentVolumeClaimKind, name, false, "persistent volume claim not found"), EdgeMounts)
+		}
+
+		for _, name := range refs.configMaps {
+			item, err := l.configMaps.ConfigMaps(g.namespace).Get(name)
+			if err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+			if item == nil && sliceutil.HasString(refs.optional, nodeID(configMapKind, name)) {
+				continue
+			}
+			if item != nil {
+				g.addNode(configMapKind, item, "", true, "")
+			}
+			g.addEdge(from, g.ensureNode(configMapKind, name, false, "config map not found"), podReferenceType(pod, configMapKind, name))
+		}
+
+		for _, name := range refs.secrets {
+			item, err := l.secrets.Secrets(g.namespace).Get(name)
+			if err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+			if item == nil && sliceutil.HasString(refs.optional, nodeID(secretKind, name)) {
+				continue
+			}
+			if item != nil {
+				g.addNode(secretKind, item, "", true, "")
+			}
+			g.addEdge(from, g.ensureNode(secretKind, name, false, "secret not found"), podReferenceType(pod, secretKind, name))
+		}
+	}
+
+	return nil
+}
+
+// podReferenceType tells volumes from environment variables and image pull secrets
+func podReferenceType(item *v1.Pod, kind, name string) string {
+	for _, volume := range item.Spec.Volumes {
+		if kind == configMapKind && volume.ConfigMap != nil && volume.ConfigMap.Name == name ||
+			kind == secretKind && volume.Secret != nil && volume.Secret.SecretName == name {
+			return EdgeMounts
+		}
+		if volume.Projected != nil {
+			for _, source := range volume.Projected.Sources {
+				if kind == configMapKind && source.ConfigMap != nil && source.ConfigMap.Name == name ||
+					kind == secretKind && source.Secret != nil && source.Secret.Name == name {
+					return EdgeMounts
+				}
+			}
+		}
+	}
+	return EdgeReferences
+}
+
+// addOwnerReferences links owners to owned objects, owners of kinds not collected such as CronJob are added
+// as nodes without status
+func (g *topologyGraph) addOwnerReferences() {
+	ids := make(map[string]bool)
+	for id := range g.objects {
+		ids[id] = true
+	}
+
+	for _, id := range keys(ids) {
+		for _, owner := range g.objects[id].GetOwnerReferences() {
+			from := nodeID(owner.Kind, owner.Name)
+			if _, ok := g.nodes[from]; !ok {
+				g.nodes[from] = &TopologyNode{ID: from, Kind: owner.Kind, Namespace: g.namespace, Name: owner.Name, Healthy: true}
+			}
+			g.addEdge(from, id, EdgeOwns)
+		}
+	}
+}
+
+func (g *topologyGraph) addHPA(hpa *autoscalingv1.HorizontalPodAutoscaler) {
+	from := nodeID(hpaKind, hpa.Name)
+	target := hpa.Spec.ScaleTargetRef
+
+	to := nodeID(target.Kind, target.Name)
+	if _, ok := g.nodes[to]; !ok {
+		to = g.ensureNode(target.Kind, target.Name, false, "scale target not found")
+		g.nodes[from].Healthy = false
+		g.nodes[from].Message = "scale target not found"
+	}
+
+	g.addEdge(from, to, EdgeScales)
+}
+
+// annotateHealth marks services without ready endpoints and ingresses routing to unhealthy services,
+// it runs after pods are linked to services
+func (g *topologyGraph) annotateHealth() {
+	for _, node := range g.nodes {
+		if node.Kind != serviceKind || node.Status == StatusMissing {
+			continue
+		}
+		selected, ready := 0, 0
+		for _, edge := range g.edges {
+			if edge.From == node.ID && edge.Type == EdgeSelects {
+				selected++
+				if g.nodes[edge.To].Healthy {
+					ready++
+				}
+			}
+		}
+		if service, ok := g.objects[node.ID].(*v1.Service); ok && len(service.Spec.Selector) == 0 {
+			continue
+		}
+		if ready == 0 {
+			node.Healthy = false
+			node.Message = "no ready endpoints"
+		} else {
+			node.Message = fmt.Sprintf("%d/%d endpoints ready", ready, selected)
+		}
+	}
+
+	for _, node := range g.nodes {
+		if node.Kind != ingressKind {
+			continue
+		}
+		unhealthy := make([]string, 0)
+		for _, edge := range g.edges {
+			if edge.From == node.ID && edge.Type == EdgeRoutes && !g.nodes[edge.To].Healthy {
+				unhealthy = append(unhealthy, g.nodes[edge.To].Name)
+			}
+		}
+		if len(unhealthy) > 0 {
+			sort.Strings(unhealthy)
+			node.Healthy = false
+			node.Message = fmt.Sprintf("unhealthy backends: %s", strings.Join(unhealthy, ", "))
+		}
+	}
+}
+
+// related collects the nodes related to the root
+func (g *topologyGraph) related(root string) map[string]bool {
+	keep := map[string]bool{root: true}
+
+	// owners and owned objects, transitively
+	g.walk(root, EdgeOwns, false, keep)
+	g.walk(root, EdgeOwns, true, keep)
+
+	// the graph of a service or ingress includes the selected pods and their owners
+	if kind := g.nodes[root].Kind; kind == serviceKind || kind == ingressKind {
+		g.walk(root, EdgeRoutes, true, keep)
+		for _, id := range keys(keep) {
+			g.walk(id, EdgeSelects, true, keep)
+		}
+		for _, id := range keys(keep) {
+			if g.nodes[id].Kind == podKind {
+				g.walk(id, EdgeOwns, false, keep)
+			}
+		}
+	}
+
+	for _, id := range keys(keep) {
+		if g.nodes[id].Kind != podKind {
+			continue
+		}
+		for _, edge := range g.edges {
+			switch {
+			case edge.From == id && (edge.Type == EdgeMounts || edge.Type == EdgeReferences):
+				keep[edge.To] = true
+			case edge.To == id && edge.Type == EdgeSelects:
+				keep[edge.From] = true
+				g.walk(edge.From, EdgeRoutes, false, keep)
+			}
+		}
+	}
+
+	for _, edge := range g.edges {
+		if edge.Type == EdgeScales && keep[edge.To] {
+			keep[edge.From] = true
+		}
+	}
+
+	return keep
+}
+
+// walk follows edges of the type forward or backward, nodes already kept are not expanded again
+func (g *topologyGraph) walk(from, edgeType string, forward bool, keep map[string]bool) {
+	for _, edge := range g.edges {
+		if edge.Type != edgeType {
+			continue
+		}
+		next := ""
+		if forward && edge.From == from {
+			next = edge.To
+		} else if !forward && edge.To == from {
+			next = edge.From
+		}
+		if next == "" || keep[next] {
+			continue
+		}
+		keep[next] = true
+		g.walk(next, edgeType, forward, keep)
+	}
+}
+
+func keys(set map[string]bool) []string {
+	result := make([]string, 0, len(set))
+	for key := range set {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// topology returns nodes in keep, or all nodes if keep is nil, sorted by id
+func (g *topologyGraph) topology(keep map[string]bool) *Topology {
+	result := &Topology{Nodes: make([]TopologyNode, 0), Edges: make([]TopologyEdge, 0)}
+
+	for id, node := range g.nodes {
+		if keep == nil || keep[id] {
+			result.Nodes = append(result.Nodes, *node)
+		}
+	}
+
+	for _, edge := range g.edges {
+		if keep == nil || keep[edge.From] && keep[edge.To] {
+			result.Edges = append(result.Edges, edge)
+		}
+	}
+
+	sort.Slice(result.Nodes, func(i, j int) bool {
+		return result.Nodes[i].ID < result.Nodes[j].ID
+	})
+
+	sort.SliceStable(result.Edges, func(i, j int) bool {
+		if result.Edges[i].From != result.Edges[j].From {
+			return result.Edges[i].From < result.Edges[j].From
+		}
+		return result.Edges[i].To < result.Edges[j].To
+	})
+
+	return result
+}
+
+func replicasMessage(ready int32, desired *int32) string {
+	if desired == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d ready", ready, *desired)
+}
+
+// podHealth returns the lowercased phase, pods are healthy when running with all containers ready or succeeded
+func podHealth(item *v1.Pod) (string, bool, string) {
+	status := strings.ToLower(string(item.Status.Phase))
+
+	switch item.Status.Phase {
+	case v1.PodSucceeded:
+		return status, true, ""
+	case v1.PodFailed:
+		return status, false, item.Status.Reason
+	}
+
+	for _, container := range item.Status.ContainerStatuses {
+		if container.State.Waiting != nil && container.State.Waiting.Reason != "" {
+			return status, false, fmt.Sprintf("%s: %s", container.Name, container.State.Waiting.Reason)
+		}
+		if !container.Ready {
+			return status, false, fmt.Sprintf("%s: not ready", container.Name)
+		}
+	}
+
+	if item.Status.Phase != v1.PodRunning {
+		return status, false, item.Status.Reason
+	}
+
+	return status, true, ""
+}
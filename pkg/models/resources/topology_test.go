@@ -0,0 +1,304 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package resources
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/api/core/v1"
+	"k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	autoscalinglisters "k8s.io/client-go/listers/autoscaling/v1"
+	batchlisters "k8s.io/client-go/listers/batch/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	extensionslisters "k8s.io/client-go/listers/extensions/v1beta1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func newTopologyListers(t *testing.T, objects ...runtime.Object) *topologyListers {
+	indexers := make(map[reflect.Type]cache.Indexer)
+
+	indexer := func(object runtime.Object) cache.Indexer {
+		typ := reflect.TypeOf(object)
+		if _, ok := indexers[typ]; !ok {
+			indexers[typ] = cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+		}
+		return indexers[typ]
+	}
+
+	for _, object := range objects {
+		if err := indexer(object).Add(object); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return &topologyListers{
+		deployments:            appslisters.NewDeploymentLister(indexer(&appsv1.Deployment{})),
+		replicaSets:            appslisters.NewReplicaSetLister(indexer(&appsv1.ReplicaSet{})),
+		statefulSets:           appslisters.NewStatefulSetLister(indexer(&appsv1.StatefulSet{})),
+		daemonSets:             appslisters.NewDaemonSetLister(indexer(&appsv1.DaemonSet{})),
+		jobs:                   batchlisters.NewJobLister(indexer(&batchv1.Job{})),
+		pods:                   corelisters.NewPodLister(indexer(&v1.Pod{})),
+		services:               corelisters.NewServiceLister(indexer(&v1.Service{})),
+		configMaps:             corelisters.NewConfigMapLister(indexer(&v1.ConfigMap{})),
+		secrets:                corelisters.NewSecretLister(indexer(&v1.Secret{})),
+		persistentVolumeClaims: corelisters.NewPersistentVolumeClaimLister(indexer(&v1.PersistentVolumeClaim{})),
+		ingresses:              extensionslisters.NewIngressLister(indexer(&v1beta1.Ingress{})),
+		hpas:                   autoscalinglisters.NewHorizontalPodAutoscalerLister(indexer(&autoscalingv1.HorizontalPodAutoscaler{})),
+	}
+}
+
+func objectMeta(name string, owner ...metav1.OwnerReference) metav1.ObjectMeta {
+	return metav1.ObjectMeta{Namespace: "default", Name: name, Labels: map[string]string{"app": "web"}, OwnerReferences: owner}
+}
+
+func ownedBy(kind, name string) metav1.OwnerReference {
+	return metav1.OwnerReference{Kind: kind, Name: name}
+}
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+// web deployment with one ready pod and one crashing pod, exposed by a service and an ingress, and an unrelated worker pod
+func topologyObjects() []runtime.Object {
+	ready := v1.PodStatus{Phase: v1.PodRunning, ContainerStatuses: []v1.ContainerStatus{{Name: "web", Ready: true}}}
+	crashing := v1.PodStatus{Phase: v1.PodRunning, ContainerStatuses: []v1.ContainerStatus{{Name: "web",
+		State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}}}}
+
+	podSpec := v1.PodSpec{
+		Volumes: []v1.Volume{
+			{Name: "data", VolumeSource: v1.VolumeSource{PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: "data"}}},
+			{Name: "config", VolumeSource: v1.VolumeSource{ConfigMap: &v1.ConfigMapVolumeSource{LocalObjectReference: v1.LocalObjectReference{Name: "web-config"}}}},
+		},
+		Containers: []v1.Container{{Name: "web", EnvFrom: []v1.EnvFromSource{
+			{SecretRef: &v1.SecretEnvSource{LocalObjectReference: v1.LocalObjectReference{Name: "web-secret"}}},
+			{ConfigMapRef: &v1.ConfigMapEnvSource{LocalObjectReference: v1.LocalObjectReference{Name: "extra"}, Optional: &[]bool{true}[0]}},
+		}}},
+	}
+
+	return []runtime.Object{
+		&appsv1.Deployment{ObjectMeta: objectMeta("web"), Spec: appsv1.DeploymentSpec{Replicas: int32Ptr(2)}, Status: appsv1.DeploymentStatus{ReadyReplicas: 1}},
+		&appsv1.ReplicaSet{ObjectMeta: objectMeta("web-1", ownedBy("Deployment", "web")), Spec: appsv1.ReplicaSetSpec{Replicas: int32Ptr(0)}},
+		&appsv1.ReplicaSet{ObjectMeta: objectMeta("web-2", ownedBy("Deployment", "web")), Spec: appsv1.ReplicaSetSpec{Replicas: int32Ptr(2)}, Status: appsv1.ReplicaSetStatus{Replicas: 2, ReadyReplicas: 1}},
+		&v1.Pod{ObjectMeta: objectMeta("web-2-a", ownedBy("ReplicaSet", "web-2")), Spec: podSpec, Status: ready},
+		&v1.Pod{ObjectMeta: objectMeta("web-2-b", ownedBy("ReplicaSet", "web-2")), Spec: podSpec, Status: crashing},
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "worker", Labels: map[string]string{"app": "worker"}}, Status: ready},
+		&v1.Service{ObjectMeta: objectMeta("web"), Spec: v1.ServiceSpec{Selector: map[string]string{"app": "web"}}},
+		&v1.Service{ObjectMeta: objectMeta("external")},
+		&v1beta1.Ingress{ObjectMeta: objectMeta("web"), Spec: v1beta1.IngressSpec{Rules: []v1beta1.IngressRule{{IngressRuleValue: v1beta1.IngressRuleValue{
+			HTTP: &v1beta1.HTTPIngressRuleValue{Paths: []v1beta1.HTTPIngressPath{
+				{Path: "/", Backend: v1beta1.IngressBackend{ServiceName: "web"}},
+				{Path: "/legacy", Backend: v1beta1.IngressBackend{ServiceName: "legacy"}},
+			}}}}}}},
+		&v1.PersistentVolumeClaim{ObjectMeta: objectMeta("data"), Status: v1.PersistentVolumeClaimStatus{Phase: v1.ClaimBound}},
+		&v1.ConfigMap{ObjectMeta: objectMeta("web-config")},
+		&autoscalingv1.HorizontalPodAutoscaler{ObjectMeta: objectMeta("web"), Spec: autoscalingv1.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv1.CrossVersionObjectReference{Kind: "Deployment", Name: "web"}}},
+		// belongs to a different namespace
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "web-2-c", Labels: map[string]string{"app": "web"}}},
+	}
+}
+
+func nodeIDs(topology *Topology) []string {
+	ids := make([]string, 0)
+	for _, node := range topology.Nodes {
+		ids = append(ids, node.ID)
+	}
+	return ids
+}
+
+func findNode(t *testing.T, topology *Topology, id string) TopologyNode {
+	for _, node := range topology.Nodes {
+		if node.ID == id {
+			return node
+		}
+	}
+	t.Fatalf("node %s not found in %v", id, nodeIDs(topology))
+	return TopologyNode{}
+}
+
+func hasEdge(topology *Topology, from, to, edgeType string) bool {
+	for _, edge := range topology.Edges {
+		if edge.From == from && edge.To == to && edge.Type == edgeType {
+			return true
+		}
+	}
+	return false
+}
+
+func TestNamespaceTopology(t *testing.T) {
+	graph, err := newTopologyListers(t, topologyObjects()...).build("default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	topology := graph.topology(nil)
+
+	expectedNodes := []string{"ConfigMap/web-config", "Deployment/web", "HorizontalPodAutoscaler/web", "Ingress/web",
+		"PersistentVolumeClaim/data", "Pod/web-2-a", "Pod/web-2-b", "Pod/worker", "ReplicaSet/web-2", "Secret/web-secret",
+		"Service/external", "Service/legacy", "Service/web"}
+
+	if ids := nodeIDs(topology); !reflect.DeepEqual(ids, expectedNodes) {
+		t.Errorf("expected nodes %v, got %v", expectedNodes, ids)
+	}
+
+	edges := []TopologyEdge{
+		{From: "Deployment/web", To: "ReplicaSet/web-2", Type: EdgeOwns},
+		{From: "ReplicaSet/web-2", To: "Pod/web-2-a", Type: EdgeOwns},
+		{From: "Service/web", To: "Pod/web-2-b", Type: EdgeSelects},
+		{From: "Ingress/web", To: "Service/web", Type: EdgeRoutes},
+		{From: "Ingress/web", To: "Service/legacy", Type: EdgeRoutes},
+		{From: "Pod/web-2-a", To: "PersistentVolumeClaim/data", Type: EdgeMounts},
+		{From: "Pod/web-2-a", To: "ConfigMap/web-config", Type: EdgeMounts},
+		{From: "Pod/web-2-a", To: "Secret/web-secret", Type: EdgeReferences},
+		{From: "HorizontalPodAutoscaler/web", To: "Deployment/web", Type: EdgeScales},
+	}
+
+	for _, edge := range edges {
+		if !hasEdge(topology, edge.From, edge.To, edge.Type) {
+			t.Errorf("expected edge %v", edge)
+		}
+	}
+
+	for _, edge := range topology.Edges {
+		if edge.From == "Service/external" || edge.To == "Pod/worker" {
+			t.Errorf("unexpected edge %v", edge)
+		}
+	}
+
+	tests := []struct {
+		id      string
+		status  string
+		healthy bool
+		message string
+	}{
+		{"Deployment/web", StatusUpdating, false, "1/2 ready"},
+		{"Pod/web-2-a", "running", true, ""},
+		{"Pod/web-2-b", "running", false, "web: CrashLoopBackOff"},
+		{"Service/web", "", true, "1/2 endpoints ready"},
+		{"Service/external", "", true, ""},
+		{"Service/legacy", StatusMissing, false, "service not found"},
+		{"Ingress/web", "", false, "unhealthy backends: legacy"},
+		{"PersistentVolumeClaim/data", StatusBound, true, ""},
+	}
+
+	for _, test := range tests {
+		node := findNode(t, topology, test.id)
+		if node.Status != test.status || node.Healthy != test.healthy || node.Message != test.message {
+			t.Errorf("%s: expected %s/%v/%q, got %s/%v/%q", test.id, test.status, test.healthy, test.message, node.Status, node.Healthy, node.Message)
+		}
+	}
+}
+
+func TestRelatedTopology(t *testing.T) {
+	graph, err := newTopologyListers(t, topologyObjects()...).build("default")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	workload := []string{"ConfigMap/web-config", "Deployment/web", "HorizontalPodAutoscaler/web", "Ingress/web",
+		"PersistentVolumeClaim/data", "Pod/web-2-a", "Pod/web-2-b", "ReplicaSet/web-2", "Secret/web-secret", "Service/web"}
+
+	tests := []struct {
+		root     string
+		expected []string
+	}{
+		{"Deployment/web", workload},
+		{"Pod/web-2-a", []string{"ConfigMap/web-config", "Deployment/web", "HorizontalPodAutoscaler/web", "Ingress/web",
+			"PersistentVolumeClaim/data", "Pod/web-2-a", "ReplicaSet/web-2", "Secret/web-secret", "Service/web"}},
+		{"Service/web", workload},
+		{"Ingress/web", append([]string{"Service/legacy"}, workload...)},
+		{"Pod/worker", []string{"Pod/worker"}},
+	}
+
+	for _, test := range tests {
+		ids := nodeIDs(graph.topology(graph.related(test.root)))
+		sort.Strings(test.expected)
+		if !reflect.DeepEqual(ids, test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.root, test.expected, ids)
+		}
+	}
+}
+
+func TestIngressRulesForService(t *testing.T) {
+	ingress := &v1beta1.Ingress{Spec: v1beta1.IngressSpec{
+		Backend: &v1beta1.IngressBackend{ServiceName: "default"},
+		Rules: []v1beta1.IngressRule{
+			{Host: "a.example.com", IngressRuleValue: v1beta1.IngressRuleValue{HTTP: &v1beta1.HTTPIngressRuleValue{Paths: []v1beta1.HTTPIngressPath{
+				{Path: "/", Backend: v1beta1.IngressBackend{ServiceName: "web"}},
+				{Path: "/api", Backend: v1beta1.IngressBackend{ServiceName: "web"}},
+			}}}},
+			{Host: "b.example.com"},
+			{Host: "c.example.com", IngressRuleValue: v1beta1.IngressRuleValue{HTTP: &v1beta1.HTTPIngressRuleValue{Paths: []v1beta1.HTTPIngressPath{
+				{Path: "/", Backend: v1beta1.IngressBackend{ServiceName: "api"}},
+			}}}},
+		},
+	}}
+
+	if rules := IngressRulesForService(ingress, "web"); len(rules) != 1 || rules[0].Host != "a.example.com" {
+		t.Errorf("expected rule of a.example.com, got %v", rules)
+	}
+
+	if services := ingressBackendServices(ingress); !reflect.DeepEqual(services, []string{"default", "web", "api"}) {
+		t.Errorf("expected services default, web and api, got %v", services)
+	}
+}
+
+func TestPodLabelIndex(t *testing.T) {
+	newPod := func(name string, labels map[string]string) *v1.Pod {
+		return &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop", Labels: labels}}
+	}
+
+	index := indexPodsByLabel([]*v1.Pod{
+		newPod("web-1", map[string]string{"app": "web", "tier": "frontend"}),
+		newPod("web-2", map[string]string{"app": "web", "tier": "frontend"}),
+		newPod("api-1", map[string]string{"app": "api", "tier": "frontend"}),
+		newPod("db-1", map[string]string{"app": "db"}),
+	})
+
+	tests := []struct {
+		selector map[string]string
+		expected []string
+	}{
+		{map[string]string{"app": "web"}, []string{"web-1", "web-2"}},
+		// the least common label is used
+		{map[string]string{"app": "api", "tier": "frontend"}, []string{"api-1"}},
+		// candidates are matched by the whole selector later
+		{map[string]string{"app": "db", "tier": "frontend"}, []string{"db-1"}},
+		{map[string]string{"app": "db", "tier": "backend"}, nil},
+		{map[string]string{"app": "cache"}, nil},
+		{map[string]string{}, nil},
+	}
+
+	for i, test := range tests {
+		var names []string
+		for _, pod := range index.candidates(test.selector) {
+			names = append(names, pod.Name)
+		}
+		if !reflect.DeepEqual(names, test.expected) {
+			t.Errorf("case %d: expected %v, got %v", i, test.expected, names)
+		}
+	}
+}
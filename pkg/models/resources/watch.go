@@ -0,0 +1,372 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package resources
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/tools/cache"
+
+	"kubesphere.io/kubesphere/pkg/informers"
+	"kubesphere.io/kubesphere/pkg/params"
+	"kubesphere.io/kubesphere/pkg/utils/sliceutil"
+)
+
+const (
+	Added    = "ADDED"
+	Modified = "MODIFIED"
+	Deleted  = "DELETED"
+
+	// watchers falling behind are stopped rather than blocking the informer
+	watchBufferSize = 100
+)
+
+var (
+	broadcastersLock sync.Mutex
+	broadcasters     = make(map[string]*broadcaster)
+)
+
+type WatchEvent struct {
+	Type   string      `json:"type" description:"ADDED, MODIFIED or DELETED"`
+	Object interface{} `json:"object" description:"the resource with extra annotations"`
+	// computed by kubesphere, the same as the status condition of resources api
+	Status string `json:"status,omitempty" description:"status of the resource, e.g. running"`
+}
+
+// Watcher receives events of resources matching the conditions, objects which no longer match are sent as DELETED,
+// objects which start to match are sent as ADDED.
+type Watcher struct {
+	namespace   string
+	conditions  *params.Conditions
+	searcher    resourceSearchInterface
+	result      chan WatchEvent
+	done        chan struct{}
+	broadcaster *broadcaster
+	stopOnce    sync.Once
+
+	// lock is held while existing objects are sent, events dispatched meanwhile wait for it
+	lock sync.Mutex
+	// listed holds resource versions of existing objects by key, the informer may dispatch events of
+	// these or older versions after the watcher is registered, such events are dropped
+	listed map[string]string
+}
+
+func (w *Watcher) ResultChan() <-chan WatchEvent {
+	return w.result
+}
+
+// Done is closed when the watcher is stopped, by Stop or because it falls behind
+func (w *Watcher) Done() <-chan struct{} {
+	return w.done
+}
+
+func (w *Watcher) Stop() {
+	w.broadcaster.remove(w)
+}
+
+// send delivers the event unless the object was already listed with the same or a newer version,
+// it reports false if the watcher falls behind
+func (w *Watcher) send(event WatchEvent, item interface{}) bool {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if key, err := cache.MetaNamespaceKeyFunc(item); err == nil {
+		if listed, ok := w.listed[key]; ok {
+			if object, err := meta.Accessor(item); err == nil && notNewer(object.GetResourceVersion(), listed) {
+				return true
+			}
+			// later events are newer than the listed version
+			delete(w.listed, key)
+		}
+	}
+
+	select {
+	case w.result <- event:
+	case <-w.done:
+	default:
+		return false
+	}
+
+	return true
+}
+
+// notNewer compares resource versions as numbers like etcd revisions, unknown versions are always newer
+func notNewer(resourceVersion, listed string) bool {
+	version, err := strconv.ParseUint(resourceVersion, 10, 64)
+	if err != nil {
+		return false
+	}
+	listedVersion, err := strconv.ParseUint(listed, 10, 64)
+	if err != nil {
+		return false
+	}
+	return version <= listedVersion
+}
+
+func (w *Watcher) matches(item interface{}) bool {
+	if w.namespace != "" {
+		if object, err := meta.Accessor(item); err != nil || object.GetNamespace() != w.namespace {
+			return false
+		}
+	}
+	if !w.searcher.matches(w.conditions, item) {
+		return false
+	}
+	if w.conditions.Filter != nil {
+		fields, err := newObjectFields(item)
+		return err == nil && w.conditions.Filter.Match(fields)
+	}
+	return true
+}
+
+// broadcaster dispatches events of a shared informer to watchers, handlers can't be removed from shared informers
+type broadcaster struct {
+	lock     sync.RWMutex
+	watchers map[*Watcher]bool
+}
+
+func (b *broadcaster) add(w *Watcher) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.watchers[w] = true
+}
+
+func (b *broadcaster) remove(w *Watcher) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	delete(b.watchers, w)
+	w.stopOnce.Do(func() { close(w.done) })
+}
+
+func (b *broadcaster) dispatch(oldObj, newObj interface{}) {
+	b.lock.RLock()
+	watchers := make([]*Watcher, 0, len(b.watchers))
+	for w := range b.watchers {
+		watchers = append(watchers, w)
+	}
+	b.lock.RUnlock()
+
+	for _, w := range watchers {
+		wasMatched := oldObj != nil && w.matches(oldObj)
+		isMatched := newObj != nil && w.matches(newObj)
+
+		var event WatchEvent
+		switch {
+		case !wasMatched && isMatched:
+			event = newWatchEvent(Added, newObj)
+		case wasMatched && isMatched:
+			event = newWatchEvent(Modified, newObj)
+		case wasMatched && !isMatched:
+			if newObj != nil {
+				event = newWatchEvent(Deleted, newObj)
+			} else {
+				// deletions are always newer than listed objects
+				event = newWatchEvent(Deleted, oldObj)
+				w.forget(oldObj)
+			}
+		default:
+			continue
+		}
+
+		if !w.send(event, event.Object) {
+			glog.Warningln("watcher is too slow, stop it")
+			b.remove(w)
+		}
+	}
+}
+
+func (w *Watcher) forget(item interface{}) {
+	if key, err := cache.MetaNamespaceKeyFunc(item); err == nil {
+		w.lock.Lock()
+		delete(w.listed, key)
+		w.lock.Unlock()
+	}
+}
+
+// unchanged reports whether both objects are of the same resource version
+func unchanged(oldObj, newObj interface{}) bool {
+	oldObject, err := meta.Accessor(oldObj)
+	if err != nil {
+		return false
+	}
+	newObject, err := meta.Accessor(newObj)
+	if err != nil {
+		return false
+	}
+	return oldObject.GetResourceVersion() != "" && oldObject.GetResourceVersion() == newObject.GetResourceVersion()
+}
+
+func newWatchEvent(eventType string, item interface{}) WatchEvent {
+	item = injector.addExtraAnnotations(item)
+	status, _ := objectStatus(item)
+	return WatchEvent{Type: eventType, Object: item, Status: status}
+}
+
+func getBroadcaster(resource string) (*broadcaster, cache.SharedIndexInformer, error) {
+	broadcastersLock.Lock()
+	defer broadcastersLock.Unlock()
+
+	informer := resourceInformer(resource)
+
+	if informer == nil {
+		return nil, nil, fmt.Errorf("resource %s can not be watched", resource)
+	}
+
+	if b, ok := broadcasters[resource]; ok {
+		return b, informer, nil
+	}
+
+	b := &broadcaster{watchers: make(map[*Watcher]bool)}
+
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			b.dispatch(nil, obj)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			// resyncs of informers dispatch updates of unchanged objects
+			if unchanged(oldObj, newObj) {
+				return
+			}
+			b.dispatch(oldObj, newObj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			b.dispatch(obj, nil)
+		},
+	})
+
+	broadcasters[resource] = b
+
+	return b, informer, nil
+}
+
+// Watch starts watching resources matching the conditions, existing resources are sent as ADDED first.
+// The watcher must be stopped by Stop.
+func Watch(namespace, resource string, conditions *params.Conditions) (*Watcher, error) {
+	searcher, ok := resources[resource]
+	if !ok {
+		return nil, fmt.Errorf("not found")
+	}
+
+	if namespace != "" && sliceutil.HasString(clusterResources, resource) {
+		return nil, fmt.Errorf("not found")
+	}
+
+	b, informer, err := getBroadcaster(resource)
+
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Watcher{namespace: namespace, conditions: conditions, searcher: searcher, broadcaster: b, done: make(chan struct{}),
+		listed: make(map[string]string)}
+
+	// the watcher is registered before listing, so that no event is lost in between,
+	// events dispatched meanwhile wait for existing objects and are deduplicated by resource version
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	b.add(w)
+
+	var existing []interface{}
+
+	if namespace != "" {
+		existing, err = informer.GetIndexer().ByIndex(cache.NamespaceIndex, namespace)
+	} else {
+		existing = informer.GetIndexer().List()
+	}
+
+	if err != nil {
+		b.remove(w)
+		return nil, err
+	}
+
+	w.result = make(chan WatchEvent, len(existing)+watchBufferSize)
+
+	for _, item := range existing {
+		if key, err := cache.MetaNamespaceKeyFunc(item); err == nil {
+			if object, err := meta.Accessor(item); err == nil {
+				w.listed[key] = object.GetResourceVersion()
+			}
+		}
+		if w.matches(item) {
+			w.result <- newWatchEvent(Added, item)
+		}
+	}
+
+	return w, nil
+}
+
+func resourceInformer(resource string) cache.SharedIndexInformer {
+	if searcher, ok := resources[resource].(*unstructuredSearcher); ok {
+		return searcher.informer
+	}
+
+	factory := informers.SharedInformerFactory()
+
+	switch resource {
+	case ConfigMaps:
+		return factory.Core().V1().ConfigMaps().Informer()
+	case CronJobs:
+		return factory.Batch().V1beta1().CronJobs().Informer()
+	case DaemonSets:
+		return factory.Apps().V1().DaemonSets().Informer()
+	case Deployments:
+		return factory.Apps().V1().Deployments().Informer()
+	case Ingresses:
+		return factory.Extensions().V1beta1().Ingresses().Informer()
+	case Jobs:
+		return factory.Batch().V1().Jobs().Informer()
+	case PersistentVolumeClaims:
+		return factory.Core().V1().PersistentVolumeClaims().Informer()
+	case Secrets:
+		return factory.Core().V1().Secrets().Informer()
+	case Services:
+		return factory.Core().V1().Services().Informer()
+	case StatefulSets:
+		return factory.Apps().V1().StatefulSets().Informer()
+	case Pods:
+		return factory.Core().V1().Pods().Informer()
+	case Roles:
+		return factory.Rbac().V1().Roles().Informer()
+	case Nodes:
+		return factory.Core().V1().Nodes().Informer()
+	case Namespaces:
+		return factory.Core().V1().Namespaces().Informer()
+	case ClusterRoles:
+		return factory.Rbac().V1().ClusterRoles().Informer()
+	case StorageClasses:
+		return factory.Storage().V1().StorageClasses().Informer()
+	case S2iBuilders:
+		return informers.S2iSharedInformerFactory().Devops().V1alpha1().S2iBuilders().Informer()
+	case S2iRuns:
+		return informers.S2iSharedInformerFactory().Devops().V1alpha1().S2iRuns().Informer()
+	case S2iBuilderTemplates:
+		return informers.S2iSharedInformerFactory().Devops().V1alpha1().S2iBuilderTemplates().Informer()
+	case Workspaces:
+		return informers.KsSharedInformerFactory().Tenant().V1alpha1().Workspaces().Informer()
+	}
+
+	return nil
+}
@@ -0,0 +1,278 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package resources
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"kubesphere.io/kubesphere/pkg/params"
+)
+
+func receive(t *testing.T, w *Watcher) *WatchEvent {
+	select {
+	case event := <-w.ResultChan():
+		return &event
+	default:
+		return nil
+	}
+}
+
+func expectEvent(t *testing.T, w *Watcher, eventType, name, status string) {
+	event := receive(t, w)
+	if event == nil {
+		t.Fatalf("expected %s %s, got nothing", eventType, name)
+	}
+	if got := event.Object.(*unstructured.Unstructured).GetName(); event.Type != eventType || got != name || event.Status != status {
+		t.Fatalf("expected %s %s %s, got %s %s %s", eventType, name, status, event.Type, got, event.Status)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	now := time.Now()
+	running := newUnstructured("default", "a", "Running", nil, now)
+	failed := newUnstructured("default", "b", "Failed", nil, now)
+
+	_, cleanup := registerTestResource(t, running, failed, newUnstructured("other", "c", "Running", nil, now))
+	defer cleanup()
+	defer delete(broadcasters, "gadgets")
+
+	conditions := &params.Conditions{Match: map[string]string{Status: StatusRunning}}
+
+	w, err := Watch("default", "gadgets", conditions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectEvent(t, w, Added, "a", StatusRunning)
+
+	if event := receive(t, w); event != nil {
+		t.Fatalf("unexpected event %+v", event)
+	}
+
+	b := broadcasters["gadgets"]
+
+	recovered := newUnstructured("default", "b", "Running", nil, now)
+	b.dispatch(failed, recovered)
+	expectEvent(t, w, Added, "b", StatusRunning)
+
+	updated := newUnstructured("default", "a", "Running", map[string]string{"app": "web"}, now)
+	b.dispatch(running, updated)
+	expectEvent(t, w, Modified, "a", StatusRunning)
+
+	// no longer matches the conditions
+	b.dispatch(recovered, failed)
+	expectEvent(t, w, Deleted, "b", "failed")
+
+	b.dispatch(updated, nil)
+	expectEvent(t, w, Deleted, "a", StatusRunning)
+
+	b.dispatch(nil, newUnstructured("other", "d", "Running", nil, now))
+	b.dispatch(nil, newUnstructured("default", "e", "Pending", nil, now))
+	if event := receive(t, w); event != nil {
+		t.Fatalf("unexpected event %+v", event)
+	}
+
+	w.Stop()
+
+	select {
+	case <-w.Done():
+	default:
+		t.Fatal("expected watcher to be done")
+	}
+
+	if len(b.watchers) != 0 {
+		t.Errorf("expected watcher to be removed")
+	}
+
+	// stopping twice is harmless
+	w.Stop()
+}
+
+func TestWatchSlowWatcher(t *testing.T) {
+	_, cleanup := registerTestResource(t)
+	defer cleanup()
+	defer delete(broadcasters, "gadgets")
+
+	w, err := Watch("", "gadgets", &params.Conditions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := broadcasters["gadgets"]
+
+	for i := 0; i <= watchBufferSize; i++ {
+		b.dispatch(nil, newUnstructured("default", "a", "", nil, time.Now()))
+	}
+
+	select {
+	case <-w.Done():
+	default:
+		t.Fatal("expected slow watcher to be stopped")
+	}
+
+	if _, err := Watch("", "unknown", &params.Conditions{}); err == nil {
+		t.Error("expected error of unknown resource")
+	}
+}
+
+func withVersion(item *unstructured.Unstructured, resourceVersion string) *unstructured.Unstructured {
+	item.SetResourceVersion(resourceVersion)
+	return item
+}
+
+func TestWatchDeduplicatesListed(t *testing.T) {
+	now := time.Now()
+	a := withVersion(newUnstructured("default", "a", "Running", nil, now), "5")
+	b := withVersion(newUnstructured("default", "b", "Running", nil, now), "3")
+
+	_, cleanup := registerTestResource(t, a, b)
+	defer cleanup()
+	defer delete(broadcasters, "gadgets")
+
+	w, err := Watch("default", "gadgets", &params.Conditions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	received := make(map[string]bool)
+	for i := 0; i < 2; i++ {
+		if event := receive(t, w); event != nil && event.Type == Added {
+			received[event.Object.(*unstructured.Unstructured).GetName()] = true
+		}
+	}
+	if !received["a"] || !received["b"] {
+		t.Fatalf("expected existing objects to be added, got %v", received)
+	}
+
+	broadcaster := broadcasters["gadgets"]
+
+	// dispatched by the informer after the watcher was registered, but already listed
+	broadcaster.dispatch(withVersion(newUnstructured("default", "a", "Running", nil, now), "4"), a)
+	broadcaster.dispatch(nil, b)
+	if event := receive(t, w); event != nil {
+		t.Fatalf("unexpected event %+v", event)
+	}
+
+	updated := withVersion(newUnstructured("default", "a", "Running", map[string]string{"app": "web"}, now), "6")
+	broadcaster.dispatch(a, updated)
+	expectEvent(t, w, Modified, "a", StatusRunning)
+
+	// deletions are newer than listed objects
+	broadcaster.dispatch(b, nil)
+	expectEvent(t, w, Deleted, "b", StatusRunning)
+
+	// objects without numeric versions are never dropped
+	c := newUnstructured("default", "c", "Running", nil, now)
+	broadcaster.dispatch(nil, c)
+	expectEvent(t, w, Added, "c", StatusRunning)
+}
+
+func TestWatchConcurrentEvents(t *testing.T) {
+	_, cleanup := registerTestResource(t)
+	defer cleanup()
+	defer delete(broadcasters, "gadgets")
+
+	// register the broadcaster first, events are dispatched while watchers are listing
+	if _, _, err := getBroadcaster("gadgets"); err != nil {
+		t.Fatal(err)
+	}
+	broadcaster := broadcasters["gadgets"]
+
+	stop := make(chan struct{})
+	dispatched := make(chan struct{})
+
+	go func() {
+		defer close(dispatched)
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+				broadcaster.dispatch(nil, newUnstructured("default", fmt.Sprintf("item-%d", i), "Running", nil, time.Now()))
+			}
+		}
+	}()
+
+	for i := 0; i < 10; i++ {
+		w, err := Watch("default", "gadgets", &params.Conditions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for len(w.ResultChan()) > 0 {
+			<-w.ResultChan()
+		}
+		w.Stop()
+	}
+
+	close(stop)
+	<-dispatched
+}
+
+func TestUnchanged(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		oldObj   interface{}
+		newObj   interface{}
+		expected bool
+	}{
+		// resyncs dispatch the same object again
+		{withVersion(newUnstructured("default", "a", "Running", nil, now), "5"), withVersion(newUnstructured("default", "a", "Running", nil, now), "5"), true},
+		{withVersion(newUnstructured("default", "a", "Running", nil, now), "5"), withVersion(newUnstructured("default", "a", "Failed", nil, now), "6"), false},
+		{newUnstructured("default", "a", "Running", nil, now), newUnstructured("default", "a", "Failed", nil, now), false},
+		{"a", "a", false},
+	}
+
+	for i, test := range tests {
+		if result := unchanged(test.oldObj, test.newObj); result != test.expected {
+			t.Errorf("case %d: expected %t, got %t", i, test.expected, result)
+		}
+	}
+}
+
+func TestSearcherMatches(t *testing.T) {
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web", Labels: map[string]string{"app": "web"}}}
+
+	tests := []struct {
+		resource   string
+		conditions *params.Conditions
+		item       interface{}
+		expected   bool
+	}{
+		{Deployments, &params.Conditions{Match: map[string]string{Name: "web"}}, deployment, true},
+		{Deployments, &params.Conditions{Match: map[string]string{Name: "api"}}, deployment, false},
+		{Deployments, &params.Conditions{Fuzzy: map[string]string{Name: "we"}}, deployment, true},
+		// objects of other resources never match
+		{Services, &params.Conditions{Match: map[string]string{Name: "web"}}, deployment, false},
+		{Services, &params.Conditions{}, &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "web"}}, true},
+	}
+
+	for i, test := range tests {
+		if result := resources[test.resource].matches(test.conditions, test.item); result != test.expected {
+			t.Errorf("case %d: expected %t, got %t", i, test.expected, result)
+		}
+	}
+}
@@ -37,6 +37,11 @@ func (*workspaceSearcher) get(namespace, name string) (interface{}, error) {
 }
 
 // exactly Match
+func (s *workspaceSearcher) matches(conditions *params.Conditions, item interface{}) bool {
+	object, ok := item.(*tenantv1alpha1.Workspace)
+	return ok && s.match(conditions.Match, object) && s.fuzzy(conditions.Fuzzy, object)
+}
+
 func (*workspaceSearcher) match(match map[string]string, item *tenantv1alpha1.Workspace) bool {
 	for k, v := range match {
 		switch k {
@@ -0,0 +1,80 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package scoped
+
+import (
+	"fmt"
+	"net/http"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	tenantv1alpha1 "kubesphere.io/kubesphere/pkg/apis/tenant/v1alpha1"
+	"kubesphere.io/kubesphere/pkg/informers"
+)
+
+// Objects shared in a workspace or namespace, eg. saved log queries and dashboards, are cluster scoped,
+// so the ones of workspaces and namespaces are kept the same way and listed together.
+
+// ObjectName names the cluster scoped object shared in the workspace or the namespace,
+// objects of workspaces and namespaces with the same name don't conflict
+func ObjectName(workspace, namespace, name string) string {
+	if workspace != "" {
+		return fmt.Sprintf("workspace.%s.%s", workspace, name)
+	}
+	return fmt.Sprintf("namespace.%s.%s", namespace, name)
+}
+
+// OwnerReferences refers to the workspace or the namespace sharing the object, so the object is garbage collected
+// with it and not inherited by a workspace or namespace created later with the same name
+func OwnerReferences(workspace, namespace string) ([]metav1.OwnerReference, error) {
+	if workspace != "" {
+		owner, err := informers.KsSharedInformerFactory().Tenant().V1alpha1().Workspaces().Lister().Get(workspace)
+		if err != nil {
+			return nil, err
+		}
+		return []metav1.OwnerReference{{
+			APIVersion: tenantv1alpha1.SchemeGroupVersion.String(),
+			Kind:       "Workspace",
+			Name:       owner.Name,
+			UID:        owner.UID,
+		}}, nil
+	}
+
+	owner, err := informers.SharedInformerFactory().Core().V1().Namespaces().Lister().Get(namespace)
+	if err != nil {
+		return nil, err
+	}
+	return []metav1.OwnerReference{{
+		APIVersion: "v1",
+		Kind:       "Namespace",
+		Name:       owner.Name,
+		UID:        owner.UID,
+	}}, nil
+}
+
+// ErrorStatus returns the response status of errors reading or writing objects
+func ErrorStatus(err error) int {
+	switch {
+	case errors.IsNotFound(err):
+		return http.StatusNotFound
+	case errors.IsAlreadyExists(err), errors.IsConflict(err):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,64 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package scoped
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestObjectName(t *testing.T) {
+	tests := []struct {
+		workspace string
+		namespace string
+		expected  string
+	}{
+		{"finance", "", "workspace.finance.errors"},
+		{"", "finance", "namespace.finance.errors"},
+	}
+
+	for i, test := range tests {
+		if name := ObjectName(test.workspace, test.namespace, "errors"); name != test.expected {
+			t.Errorf("case %d: expected %s, got %s", i, test.expected, name)
+		}
+	}
+}
+
+func TestErrorStatus(t *testing.T) {
+	resource := schema.GroupResource{Group: "logging.kubesphere.io", Resource: "savedlogqueries"}
+
+	tests := []struct {
+		err      error
+		expected int
+	}{
+		{errors.NewNotFound(resource, "errors"), http.StatusNotFound},
+		{errors.NewAlreadyExists(resource, "errors"), http.StatusConflict},
+		{errors.NewConflict(resource, "errors", fmt.Errorf("modified")), http.StatusConflict},
+		{fmt.Errorf("connection refused"), http.StatusInternalServerError},
+	}
+
+	for i, test := range tests {
+		if status := ErrorStatus(test.err); status != test.expected {
+			t.Errorf("case %d: expected %d, got %d", i, test.expected, status)
+		}
+	}
+}
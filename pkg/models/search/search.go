@@ -0,0 +1,365 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/golang/glog"
+	"k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
+
+	"kubesphere.io/kubesphere/pkg/constants"
+	"kubesphere.io/kubesphere/pkg/models"
+	"kubesphere.io/kubesphere/pkg/models/devops"
+	"kubesphere.io/kubesphere/pkg/models/iam"
+	"kubesphere.io/kubesphere/pkg/models/resources"
+	"kubesphere.io/kubesphere/pkg/models/tenant"
+	"kubesphere.io/kubesphere/pkg/params"
+	"kubesphere.io/kubesphere/pkg/simple/client/openpitrix"
+)
+
+const (
+	Pipelines    = "pipelines"
+	Applications = "applications"
+
+	// results of remote services are limited, the index is used for kubernetes resources
+	maxRemoteResults = 100
+)
+
+// listClusters is replaced in tests
+var listClusters = openpitrix.ListClusters
+
+type Result struct {
+	Kind        string      `json:"kind" description:"type of the result, such as deployments, pipelines and applications"`
+	Namespace   string      `json:"namespace,omitempty" description:"namespace of the resource, or devops project of pipelines"`
+	Name        string      `json:"name" description:"name of the resource"`
+	DisplayName string      `json:"display_name,omitempty" description:"display name of the resource"`
+	Score       int         `json:"score" description:"relevance of the result, higher is better"`
+	Object      interface{} `json:"object" description:"the resource, empty for secrets"`
+}
+
+// scope is what the user can see
+type scope struct {
+	username      string
+	namespaces    map[string]bool
+	workspaces    map[string]bool
+	runtimes      map[string]string
+	clusterRules  []rbacv1.PolicyRule
+	clusterChecks map[string]bool
+	// namespaceRules are rules of roles bound to the user in namespaces, checked like cluster rules
+	namespaceRules  map[string][]rbacv1.PolicyRule
+	namespaceChecks map[string]bool
+}
+
+func newScope(usr user.Info) (*scope, error) {
+	s := &scope{username: usr.GetName(), namespaces: make(map[string]bool), workspaces: make(map[string]bool),
+		runtimes: make(map[string]string), clusterChecks: make(map[string]bool),
+		namespaceRules: make(map[string][]rbacv1.PolicyRule), namespaceChecks: make(map[string]bool)}
+
+	namespaces, err := tenant.ListNamespaces(usr, &params.Conditions{}, "", false, math.MaxInt32, 0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range namespaces.Items {
+		namespace := item.(*v1.Namespace)
+		s.namespaces[namespace.Name] = true
+		if runtime := namespace.Annotations[constants.OpenPitrixRuntimeAnnotationKey]; runtime != "" {
+			s.runtimes[runtime] = namespace.Name
+		}
+		if s.namespaceRules[namespace.Name], err = iam.GetUserRules(namespace.Name, usr); err != nil {
+			return nil, err
+		}
+	}
+
+	workspaces, err := tenant.GetWorkspaces(usr)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, workspace := range workspaces {
+		s.workspaces[workspace.Name] = true
+	}
+
+	if s.clusterRules, err = iam.GetUserClusterRules(usr); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// canSee is called with the index locked, resources are checked against rules collected in advance
+func (s *scope) canSee(object *resources.IndexedObject) bool {
+	switch {
+	case object.Resource == resources.Namespaces:
+		return s.namespaces[object.Name]
+	case object.Resource == resources.Workspaces:
+		return s.workspaces[object.Name]
+	case object.Namespace != "":
+		return s.namespaces[object.Namespace] && s.canList(object.Namespace, object.Resource)
+	default:
+		return s.canListCluster(object.Resource)
+	}
+}
+
+// canListCluster reports whether the user can list the cluster scoped resource by cluster roles,
+// custom resources are checked by their api groups like built-in ones
+func (s *scope) canListCluster(resource string) bool {
+	if permitted, ok := s.clusterChecks[resource]; ok {
+		return permitted
+	}
+
+	permitted := iam.RulesMatchesRequired(s.clusterRules, listRule(resource))
+	s.clusterChecks[resource] = permitted
+
+	return permitted
+}
+
+// canList reports whether the user can list the resource in the namespace, by roles of the namespace or cluster roles
+func (s *scope) canList(namespace, resource string) bool {
+	key := namespace + "/" + resource
+
+	if permitted, ok := s.namespaceChecks[key]; ok {
+		return permitted
+	}
+
+	required := listRule(resource)
+	permitted := iam.RulesMatchesRequired(s.namespaceRules[namespace], required) || iam.RulesMatchesRequired(s.clusterRules, required)
+	s.namespaceChecks[key] = permitted
+
+	return permitted
+}
+
+func listRule(resource string) rbacv1.PolicyRule {
+	return rbacv1.PolicyRule{
+		Verbs:     []string{"list"},
+		APIGroups: []string{resources.ResourceGroup(resource)},
+		Resources: []string{resource},
+	}
+}
+
+// newResult returns the indexed object, contents of secrets are never returned by search
+func newResult(object resources.IndexedObject) Result {
+	result := Result{Kind: object.Resource, Namespace: object.Namespace, Name: object.Name, DisplayName: object.DisplayName}
+	if object.Resource != resources.Secrets {
+		result.Object = object.Object
+	}
+	return result
+}
+
+// Search finds resources across namespaces and kinds, devops pipelines and applications by names, display names and labels.
+// Kinds are resource names such as deployments, pipelines or applications, empty means all.
+func Search(usr user.Info, query string, kinds []string, limit, offset int, req *http.Request) (*models.PageableResponse, error) {
+	query = strings.TrimSpace(query)
+
+	if query == "" {
+		return nil, fmt.Errorf("query is required")
+	}
+
+	s, err := newScope(usr)
+
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := func(kind string) bool {
+		if len(kinds) == 0 {
+			return true
+		}
+		for _, k := range kinds {
+			if k == kind {
+				return true
+			}
+		}
+		return false
+	}
+
+	results := make([]Result, 0)
+
+	for _, object := range resources.SearchIndex(query, func(object *resources.IndexedObject) bool {
+		return wanted(object.Resource) && s.canSee(object)
+	}) {
+		results = append(results, newResult(object))
+	}
+
+	// remote services are optional, they are skipped if unavailable
+	if wanted(Pipelines) {
+		pipelines, err := searchPipelines(s, query, req)
+		if err != nil {
+			glog.Warningln("search pipelines", err)
+		}
+		results = append(results, pipelines...)
+	}
+
+	if wanted(Applications) {
+		applications, err := searchApplications(s, query)
+		if err != nil {
+			glog.Warningln("search applications", err)
+		}
+		results = append(results, applications...)
+	}
+
+	for i := range results {
+		results[i].Score = score(query, results[i].Name, results[i].DisplayName)
+	}
+
+	sortResults(results)
+
+	items := make([]interface{}, 0)
+
+	for i, result := range results {
+		if i >= offset && (limit == -1 || len(items) < limit) {
+			items = append(items, result)
+		}
+	}
+
+	return &models.PageableResponse{Items: items, TotalCount: len(results)}, nil
+}
+
+// score ranks exact names first, then prefixes of names, then names and display names containing the query,
+// other results are matched by words of names or labels
+func score(query, name, displayName string) int {
+	query = strings.ToLower(query)
+	name = strings.ToLower(name)
+	displayName = strings.ToLower(displayName)
+
+	switch {
+	case name == query:
+		return 100
+	case displayName == query:
+		return 90
+	case strings.HasPrefix(name, query):
+		return 80
+	case strings.HasPrefix(displayName, query):
+		return 70
+	case strings.Contains(name, query):
+		return 60
+	case strings.Contains(displayName, query):
+		return 50
+	default:
+		return 10
+	}
+}
+
+func sortResults(results []Result) {
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if len(a.Name) != len(b.Name) {
+			return len(a.Name) < len(b.Name)
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+}
+
+// searchPipelines searches pipelines of devops projects the user can see
+func searchPipelines(s *scope, query string, req *http.Request) ([]Result, error) {
+	projects := make(map[string]bool)
+
+	for workspace := range s.workspaces {
+		list, err := tenant.ListDevopsProjects(workspace, s.username, &params.Conditions{Match: map[string]string{}}, "", false, math.MaxInt32, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range list.Items {
+			project := item.(*devops.DevOpsProject)
+			projects[project.ProjectId] = true
+		}
+	}
+
+	results := make([]Result, 0)
+
+	if len(projects) == 0 {
+		return results, nil
+	}
+
+	searchReq := *req
+	searchURL := *req.URL
+	searchURL.RawQuery = fmt.Sprintf("q=type:pipeline;organization:jenkins;pipeline:*%s*;excludedFromFlattening:jenkins.branch.MultiBranchProject,hudson.matrix.MatrixProject&filter=no-folders&start=0&limit=%d",
+		url.QueryEscape(query), maxRemoteResults)
+	searchReq.URL = &searchURL
+
+	data, err := devops.SearchPipelines(&searchReq)
+
+	if err != nil {
+		return nil, err
+	}
+
+	pipelines := make([]devops.Pipeline, 0)
+
+	if err := json.Unmarshal(data, &pipelines); err != nil {
+		return nil, err
+	}
+
+	for _, pipeline := range pipelines {
+		// full name of a pipeline is <project>/<pipeline>
+		project := strings.SplitN(pipeline.FullName, "/", 2)[0]
+		if projects[project] {
+			results = append(results, Result{Kind: Pipelines, Namespace: project, Name: pipeline.Name, DisplayName: pipeline.DisplayName, Object: pipeline})
+		}
+	}
+
+	return results, nil
+}
+
+// searchApplications searches applications of namespaces the user can see
+func searchApplications(s *scope, query string) ([]Result, error) {
+	results := make([]Result, 0)
+
+	if len(s.runtimes) == 0 {
+		return results, nil
+	}
+
+	// clusters are filtered by runtimes after listing, all pages are walked so that
+	// applications of the user are not hidden by clusters of other runtimes
+	for offset := 0; ; offset += maxRemoteResults {
+		clusters, err := listClusters("", url.QueryEscape(query), "", maxRemoteResults, offset)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, cluster := range clusters.Clusters {
+			if namespace, ok := s.runtimes[cluster.RunTimeId]; ok {
+				results = append(results, Result{Kind: Applications, Namespace: namespace, Name: cluster.Name, Object: cluster})
+			}
+		}
+
+		if len(clusters.Clusters) < maxRemoteResults || offset+len(clusters.Clusters) >= clusters.Total {
+			return results, nil
+		}
+	}
+}
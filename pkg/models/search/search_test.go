@@ -0,0 +1,173 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package search
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	"kubesphere.io/kubesphere/pkg/models/resources"
+	"kubesphere.io/kubesphere/pkg/simple/client/openpitrix"
+)
+
+func TestSortResults(t *testing.T) {
+	results := []Result{
+		{Kind: "services", Namespace: "dev", Name: "payments-gateway"},
+		{Kind: "pipelines", Namespace: "project-x", Name: "build-payments"},
+		{Kind: "deployments", Namespace: "shop", Name: "payments"},
+		{Kind: "services", Namespace: "shop", Name: "payments"},
+		{Kind: "deployments", Namespace: "shop", Name: "api", DisplayName: "Payments"},
+		{Kind: "configmaps", Namespace: "shop", Name: "payments-api"},
+		{Kind: "configmaps", Namespace: "shop", Name: "config", DisplayName: "billing"},
+	}
+
+	for i := range results {
+		results[i].Score = score("payments", results[i].Name, results[i].DisplayName)
+	}
+
+	sortResults(results)
+
+	got := make([]string, 0)
+	for _, result := range results {
+		got = append(got, result.Kind+"/"+result.Namespace+"/"+result.Name)
+	}
+
+	expected := []string{
+		"deployments/shop/payments",
+		"services/shop/payments",
+		"deployments/shop/api",
+		"configmaps/shop/payments-api",
+		"services/dev/payments-gateway",
+		"pipelines/project-x/build-payments",
+		"configmaps/shop/config",
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestCanSee(t *testing.T) {
+	s := &scope{
+		namespaces: map[string]bool{"shop": true, "dev": true},
+		workspaces: map[string]bool{"demo": true},
+		namespaceRules: map[string][]rbacv1.PolicyRule{
+			// viewer of shop can't list secrets and roles
+			"shop": {{Verbs: []string{"get", "list", "watch"}, APIGroups: []string{"", "apps"}, Resources: []string{"deployments", "services", "configmaps"}}},
+			"dev":  {{Verbs: []string{"*"}, APIGroups: []string{"*"}, Resources: []string{"*"}}},
+		},
+		clusterRules: []rbacv1.PolicyRule{
+			{Verbs: []string{"list"}, APIGroups: []string{""}, Resources: []string{"nodes"}},
+			{Verbs: []string{"list"}, APIGroups: []string{"storage.k8s.io"}, Resources: []string{"storageclasses"}},
+			{Verbs: []string{"list"}, APIGroups: []string{""}, Resources: []string{"widgets"}},
+		},
+		clusterChecks:   make(map[string]bool),
+		namespaceChecks: make(map[string]bool),
+	}
+
+	tests := []struct {
+		object   resources.IndexedObject
+		expected bool
+	}{
+		{resources.IndexedObject{Resource: resources.Deployments, Namespace: "shop", Name: "payments"}, true},
+		{resources.IndexedObject{Resource: resources.Secrets, Namespace: "shop", Name: "payments-token"}, false},
+		{resources.IndexedObject{Resource: resources.Roles, Namespace: "shop", Name: "admin"}, false},
+		{resources.IndexedObject{Resource: resources.Secrets, Namespace: "dev", Name: "payments-token"}, true},
+		// namespaces the user is not a member of
+		{resources.IndexedObject{Resource: resources.Deployments, Namespace: "kube-system", Name: "payments"}, false},
+		{resources.IndexedObject{Resource: resources.Namespaces, Name: "shop"}, true},
+		{resources.IndexedObject{Resource: resources.Namespaces, Name: "kube-system"}, false},
+		{resources.IndexedObject{Resource: resources.Workspaces, Name: "demo"}, true},
+		{resources.IndexedObject{Resource: resources.Nodes, Name: "node1"}, true},
+		{resources.IndexedObject{Resource: resources.StorageClasses, Name: "local"}, true},
+		{resources.IndexedObject{Resource: resources.ClusterRoles, Name: "admin"}, false},
+		// cluster scoped resources out of the built-in list
+		{resources.IndexedObject{Resource: "widgets", Name: "widget"}, true},
+		{resources.IndexedObject{Resource: "gadgets", Name: "gadget"}, false},
+	}
+
+	for i, test := range tests {
+		if result := s.canSee(&test.object); result != test.expected {
+			t.Errorf("case %d: expected %t, got %t", i, test.expected, result)
+		}
+	}
+
+	// cluster roles are also permitted in namespaces
+	s.clusterRules = append(s.clusterRules, rbacv1.PolicyRule{Verbs: []string{"list"}, APIGroups: []string{"rbac.authorization.k8s.io"}, Resources: []string{"roles"}})
+	s.namespaceChecks = make(map[string]bool)
+
+	if !s.canSee(&resources.IndexedObject{Resource: resources.Roles, Namespace: "shop", Name: "admin"}) {
+		t.Errorf("expected roles to be visible by cluster rules")
+	}
+}
+
+func TestNewResult(t *testing.T) {
+	secret := &corev1.Secret{Data: map[string][]byte{"token": []byte("secret")}}
+	result := newResult(resources.IndexedObject{Resource: resources.Secrets, Namespace: "shop", Name: "payments-token", Object: secret})
+
+	if result.Object != nil || result.Name != "payments-token" || result.Kind != resources.Secrets {
+		t.Errorf("expected secret without object, got %+v", result)
+	}
+
+	service := &corev1.Service{}
+	result = newResult(resources.IndexedObject{Resource: resources.Services, Namespace: "shop", Name: "payments", Object: service})
+
+	if result.Object != service {
+		t.Errorf("expected service object, got %+v", result.Object)
+	}
+}
+
+func TestSearchApplications(t *testing.T) {
+	list := listClusters
+	defer func() { listClusters = list }()
+
+	// clusters of the user's runtime are on the last page
+	all := make([]openpitrix.Cluster, 0)
+	for i := 0; i < 2*maxRemoteResults; i++ {
+		all = append(all, openpitrix.Cluster{Name: fmt.Sprintf("other-%d", i), RunTimeId: "other"})
+	}
+	all = append(all, openpitrix.Cluster{Name: "payments", RunTimeId: "shop-runtime"})
+
+	requests := 0
+	listClusters = func(runtimeId, searchWord, status string, limit, offset int) (*openpitrix.ClusterList, error) {
+		requests++
+		end := offset + limit
+		if end > len(all) {
+			end = len(all)
+		}
+		return &openpitrix.ClusterList{Total: len(all), Clusters: all[offset:end]}, nil
+	}
+
+	results, err := searchApplications(&scope{runtimes: map[string]string{"shop-runtime": "shop"}}, "pay")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 1 || results[0].Name != "payments" || results[0].Namespace != "shop" {
+		t.Errorf("expected payments in shop, got %+v", results)
+	}
+
+	if requests != 3 {
+		t.Errorf("expected 3 pages, got %d", requests)
+	}
+}
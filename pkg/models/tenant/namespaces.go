@@ -22,6 +22,7 @@ import (
 	"k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apiserver/pkg/authentication/user"
 	"kubesphere.io/kubesphere/pkg/constants"
 	"kubesphere.io/kubesphere/pkg/informers"
 	"kubesphere.io/kubesphere/pkg/models/iam"
@@ -84,9 +85,9 @@ func (*namespaceSearcher) compare(a, b *v1.Namespace, orderBy string) bool {
 	}
 }
 
-func (*namespaceSearcher) GetNamespaces(username string) ([]*v1.Namespace, error) {
+func (*namespaceSearcher) GetNamespaces(usr user.Info) ([]*v1.Namespace, error) {
 
-	roles, err := iam.GetUserRoles("", username)
+	roles, err := iam.GetUserRoles("", usr)
 
 	if err != nil {
 		return nil, err
@@ -116,9 +117,9 @@ func containsNamespace(namespaces []*v1.Namespace, namespace *v1.Namespace) bool
 	return false
 }
 
-func (s *namespaceSearcher) search(username string, conditions *params.Conditions, orderBy string, reverse bool) ([]*v1.Namespace, error) {
+func (s *namespaceSearcher) search(usr user.Info, conditions *params.Conditions, orderBy string, reverse bool) ([]*v1.Namespace, error) {
 
-	rules, err := iam.GetUserClusterRules(username)
+	rules, err := iam.GetUserClusterRules(usr)
 
 	if err != nil {
 		return nil, err
@@ -129,7 +130,7 @@ func (s *namespaceSearcher) search(username string, conditions *params.Condition
 	if iam.RulesMatchesRequired(rules, rbacv1.PolicyRule{Verbs: []string{"list"}, APIGroups: []string{"tenant.kubesphere.io"}, Resources: []string{"namespaces"}}) {
 		namespaces, err = informers.SharedInformerFactory().Core().V1().Namespaces().Lister().List(labels.Everything())
 	} else {
-		namespaces, err = s.GetNamespaces(username)
+		namespaces, err = s.GetNamespaces(usr)
 	}
 
 	if err != nil {
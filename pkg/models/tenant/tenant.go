@@ -19,6 +19,7 @@ package tenant
 
 import (
 	"k8s.io/api/core/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
 	"kubesphere.io/kubesphere/pkg/apis/tenant/v1alpha1"
 	"kubesphere.io/kubesphere/pkg/constants"
 	"kubesphere.io/kubesphere/pkg/informers"
@@ -47,21 +48,21 @@ func CreateNamespace(workspaceName string, namespace *v1.Namespace, username str
 	return k8s.Client().CoreV1().Namespaces().Create(namespace)
 }
 
-func DescribeWorkspace(username, workspaceName string) (*v1alpha1.Workspace, error) {
+func DescribeWorkspace(usr user.Info, workspaceName string) (*v1alpha1.Workspace, error) {
 	workspace, err := informers.KsSharedInformerFactory().Tenant().V1alpha1().Workspaces().Lister().Get(workspaceName)
 
 	if err != nil {
 		return nil, err
 	}
 
-	workspace = appendAnnotations(username, workspace)
+	workspace = appendAnnotations(usr, workspace)
 
 	return workspace, nil
 }
 
-func ListWorkspaces(username string, conditions *params.Conditions, orderBy string, reverse bool, limit, offset int) (*models.PageableResponse, error) {
+func ListWorkspaces(usr user.Info, conditions *params.Conditions, orderBy string, reverse bool, limit, offset int) (*models.PageableResponse, error) {
 
-	workspaces, err := workspaces.search(username, conditions, orderBy, reverse)
+	workspaces, err := workspaces.search(usr, conditions, orderBy, reverse)
 
 	if err != nil {
 		return nil, err
@@ -71,7 +72,7 @@ func ListWorkspaces(username string, conditions *params.Conditions, orderBy stri
 	result := make([]interface{}, 0)
 	for i, workspace := range workspaces {
 		if len(result) < limit && i >= offset {
-			workspace := appendAnnotations(username, workspace)
+			workspace := appendAnnotations(usr, workspace)
 			result = append(result, workspace)
 		}
 	}
@@ -79,16 +80,21 @@ func ListWorkspaces(username string, conditions *params.Conditions, orderBy stri
 	return &models.PageableResponse{Items: result, TotalCount: len(workspaces)}, nil
 }
 
-func appendAnnotations(username string, workspace *v1alpha1.Workspace) *v1alpha1.Workspace {
+// GetWorkspaces returns workspaces the user can see, without extra annotations
+func GetWorkspaces(usr user.Info) ([]*v1alpha1.Workspace, error) {
+	return workspaces.search(usr, &params.Conditions{}, "", false)
+}
+
+func appendAnnotations(usr user.Info, workspace *v1alpha1.Workspace) *v1alpha1.Workspace {
 	workspace = workspace.DeepCopy()
 	if workspace.Annotations == nil {
 		workspace.Annotations = make(map[string]string)
 	}
-	ns, err := ListNamespaces(username, &params.Conditions{Match: map[string]string{constants.WorkspaceLabelKey: workspace.Name}}, "", false, 1, 0)
+	ns, err := ListNamespaces(usr, &params.Conditions{Match: map[string]string{constants.WorkspaceLabelKey: workspace.Name}}, "", false, 1, 0)
 	if err == nil {
 		workspace.Annotations["kubesphere.io/namespace-count"] = strconv.Itoa(ns.TotalCount)
 	}
-	devops, err := ListDevopsProjects(workspace.Name, username, &params.Conditions{}, "", false, 1, 0)
+	devops, err := ListDevopsProjects(workspace.Name, usr.GetName(), &params.Conditions{}, "", false, 1, 0)
 	if err == nil {
 		workspace.Annotations["kubesphere.io/devops-count"] = strconv.Itoa(devops.TotalCount)
 	}
@@ -99,9 +105,9 @@ func appendAnnotations(username string, workspace *v1alpha1.Workspace) *v1alpha1
 	return workspace
 }
 
-func ListNamespaces(username string, conditions *params.Conditions, orderBy string, reverse bool, limit, offset int) (*models.PageableResponse, error) {
+func ListNamespaces(usr user.Info, conditions *params.Conditions, orderBy string, reverse bool, limit, offset int) (*models.PageableResponse, error) {
 
-	namespaces, err := namespaces.search(username, conditions, orderBy, reverse)
+	namespaces, err := namespaces.search(usr, conditions, orderBy, reverse)
 
 	if err != nil {
 		return nil, err
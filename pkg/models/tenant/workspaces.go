@@ -20,6 +20,7 @@ package tenant
 import (
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apiserver/pkg/authentication/user"
 	"kubesphere.io/kubesphere/pkg/apis/tenant/v1alpha1"
 	"kubesphere.io/kubesphere/pkg/constants"
 	"kubesphere.io/kubesphere/pkg/informers"
@@ -83,8 +84,8 @@ func (*workspaceSearcher) compare(a, b *v1alpha1.Workspace, orderBy string) bool
 	}
 }
 
-func (s *workspaceSearcher) search(username string, conditions *params.Conditions, orderBy string, reverse bool) ([]*v1alpha1.Workspace, error) {
-	rules, err := iam.GetUserClusterRules(username)
+func (s *workspaceSearcher) search(usr user.Info, conditions *params.Conditions, orderBy string, reverse bool) ([]*v1alpha1.Workspace, error) {
+	rules, err := iam.GetUserClusterRules(usr)
 
 	if err != nil {
 		return nil, err
@@ -98,7 +99,7 @@ func (s *workspaceSearcher) search(username string, conditions *params.Condition
 			return nil, err
 		}
 	} else {
-		workspaceRoles, err := iam.GetUserWorkspaceRoleMap(username)
+		workspaceRoles, err := iam.GetUserWorkspaceRoleMap(usr)
 		if err != nil {
 			return nil, err
 		}
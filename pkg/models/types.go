@@ -25,8 +25,18 @@ import (
 )
 
 type PageableResponse struct {
-	Items      []interface{} `json:"items" description:"paging data"`
-	TotalCount int           `json:"total_count" description:"total count"`
+	Items           []interface{} `json:"items" description:"paging data"`
+	TotalCount      int           `json:"total_count" description:"total count"`
+	Continue        string        `json:"continue,omitempty" description:"token to fetch the next page, empty if there are no more items"`
+	ResourceVersion string        `json:"resource_version,omitempty" description:"resource version of the snapshot which pages are listed from"`
+}
+
+type TableResponse struct {
+	Columns         []string        `json:"columns" description:"fields of each column"`
+	Rows            [][]interface{} `json:"rows" description:"cells of each row, a cell is a string, a list of strings or null"`
+	TotalCount      int             `json:"total_count" description:"total count"`
+	Continue        string          `json:"continue,omitempty" description:"token to fetch the next page, empty if there are no more items"`
+	ResourceVersion string          `json:"resource_version,omitempty" description:"resource version of the snapshot which pages are listed from"`
 }
 
 type Workspace struct {
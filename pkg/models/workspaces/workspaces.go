@@ -80,7 +80,7 @@ func DeleteNamespace(workspace string, namespaceName string) error {
 }
 
 func RemoveUser(workspaceName string, username string) error {
-	workspaceRole, err := iam.GetUserWorkspaceRole(workspaceName, username)
+	workspaceRole, err := iam.GetUserWorkspaceRole(workspaceName, k8sutil.NewIdentity(username, v1.UserKind))
 	if err != nil {
 		return err
 	}
@@ -93,7 +93,7 @@ func RemoveUser(workspaceName string, username string) error {
 
 func InviteUser(workspaceName string, user *models.User) error {
 
-	workspaceRole, err := iam.GetUserWorkspaceRole(workspaceName, user.Username)
+	workspaceRole, err := iam.GetUserWorkspaceRole(workspaceName, k8sutil.NewIdentity(user.Username, v1.UserKind))
 
 	if err != nil && !apierrors.IsNotFound(err) {
 		glog.Errorf("get workspace role failed: %+v", err)
@@ -0,0 +1,545 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package params
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const FilterParam = "filter"
+
+// Operators of filter predicates
+const (
+	Equals         = "="
+	DoubleEquals   = "=="
+	NotEquals      = "!="
+	Contains       = "~"
+	NotContains    = "!~"
+	GreaterThan    = ">"
+	GreaterOrEqual = ">="
+	LessThan       = "<"
+	LessOrEqual    = "<="
+	In             = "in"
+	NotIn          = "notin"
+	Exists         = "exists"
+	DoesNotExist   = "!exists"
+)
+
+// Fields resolves the values of a field of an object, nil means the field does not exist.
+// A field may have multiple values, such as names of owners or images of containers.
+type Fields interface {
+	Values(field string) []string
+}
+
+// Filter is a boolean expression of predicates on fields, the syntax is a superset of label selectors:
+//
+//	name=nginx, labels.app in (web, api)          predicates separated by "," or "&&" are all required
+//	status=running || status=updating             either side is required
+//	!(labels.tier=db), not ownerKind=Job          negation
+//	labels.app, !labels.app                       existence of a field
+//	name~"my app", annotations.desc!~"a=b"        contains and not contains, quoted values may contain any character
+//	createTime>=2019-06-01, createTime<2019-06-01T12:00:00Z
+//
+// Fields are resolved by Fields, values of time, numbers and strings are compared in that order of precedence.
+type Filter struct {
+	expression expression
+}
+
+// Match reports whether fields satisfy the filter, an empty filter matches everything
+func (f *Filter) Match(fields Fields) bool {
+	if f == nil || f.expression == nil {
+		return true
+	}
+	return f.expression.eval(fields)
+}
+
+func (f *Filter) String() string {
+	if f == nil || f.expression == nil {
+		return ""
+	}
+	return f.expression.String()
+}
+
+type expression interface {
+	eval(fields Fields) bool
+	String() string
+}
+
+type andExpression []expression
+
+func (e andExpression) eval(fields Fields) bool {
+	for _, item := range e {
+		if !item.eval(fields) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e andExpression) String() string {
+	return joinExpressions(e, " && ")
+}
+
+type orExpression []expression
+
+func (e orExpression) eval(fields Fields) bool {
+	for _, item := range e {
+		if item.eval(fields) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e orExpression) String() string {
+	return joinExpressions(e, " || ")
+}
+
+type notExpression struct {
+	expression expression
+}
+
+func (e notExpression) eval(fields Fields) bool {
+	return !e.expression.eval(fields)
+}
+
+func (e notExpression) String() string {
+	return fmt.Sprintf("!(%s)", e.expression)
+}
+
+func joinExpressions(expressions []expression, separator string) string {
+	items := make([]string, 0, len(expressions))
+	for _, item := range expressions {
+		items = append(items, fmt.Sprintf("(%s)", item))
+	}
+	return strings.Join(items, separator)
+}
+
+type predicate struct {
+	field    string
+	operator string
+	values   []string
+}
+
+func (p predicate) String() string {
+	switch p.operator {
+	case Exists:
+		return p.field
+	case DoesNotExist:
+		return "!" + p.field
+	case In, NotIn:
+		values := make([]string, 0, len(p.values))
+		for _, value := range p.values {
+			values = append(values, strconv.Quote(value))
+		}
+		return fmt.Sprintf("%s %s (%s)", p.field, p.operator, strings.Join(values, ", "))
+	default:
+		return fmt.Sprintf("%s%s%s", p.field, p.operator, strconv.Quote(p.values[0]))
+	}
+}
+
+// negative predicates are satisfied by missing fields, as label selectors do
+func (p predicate) eval(fields Fields) bool {
+	values := fields.Values(p.field)
+
+	switch p.operator {
+	case Exists:
+		return values != nil
+	case DoesNotExist:
+		return values == nil
+	case NotEquals:
+		return !anyValue(values, func(v string) bool { return v == p.values[0] })
+	case NotContains:
+		return !anyValue(values, func(v string) bool { return strings.Contains(v, p.values[0]) })
+	case NotIn:
+		return !anyValue(values, func(v string) bool { return hasString(p.values, v) })
+	case Equals, DoubleEquals:
+		return anyValue(values, func(v string) bool { return v == p.values[0] })
+	case Contains:
+		return anyValue(values, func(v string) bool { return strings.Contains(v, p.values[0]) })
+	case In:
+		return anyValue(values, func(v string) bool { return hasString(p.values, v) })
+	case GreaterThan:
+		return anyValue(values, func(v string) bool { return compareValues(v, p.values[0]) > 0 })
+	case GreaterOrEqual:
+		return anyValue(values, func(v string) bool { return compareValues(v, p.values[0]) >= 0 })
+	case LessThan:
+		return anyValue(values, func(v string) bool { return compareValues(v, p.values[0]) < 0 })
+	case LessOrEqual:
+		return anyValue(values, func(v string) bool { return compareValues(v, p.values[0]) <= 0 })
+	}
+
+	return false
+}
+
+func anyValue(values []string, f func(string) bool) bool {
+	for _, value := range values {
+		if f(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasString(values []string, s string) bool {
+	for _, value := range values {
+		if value == s {
+			return true
+		}
+	}
+	return false
+}
+
+var timeLayouts = []string{time.RFC3339Nano, time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"}
+
+func parseTime(value string) (time.Time, bool) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// compareValues compares a and b as times, numbers or strings
+func compareValues(a, b string) int {
+	if ta, ok := parseTime(a); ok {
+		if tb, ok := parseTime(b); ok {
+			switch {
+			case ta.Before(tb):
+				return -1
+			case ta.After(tb):
+				return 1
+			default:
+				return 0
+			}
+		}
+	}
+
+	if fa, err := strconv.ParseFloat(a, 64); err == nil {
+		if fb, err := strconv.ParseFloat(b, 64); err == nil {
+			switch {
+			case fa < fb:
+				return -1
+			case fa > fb:
+				return 1
+			default:
+				return 0
+			}
+		}
+	}
+
+	return strings.Compare(a, b)
+}
+
+// ParseFilter parses the filter expression, nil is returned for an empty expression
+func ParseFilter(filterStr string) (*Filter, error) {
+	tokens, err := tokenize(filterStr)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	p := &filterParser{tokens: tokens}
+
+	expr, err := p.parseOr()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.done() {
+		return nil, fmt.Errorf("invalid filter: unexpected %s at %d", p.peek().value, p.peek().pos)
+	}
+
+	return &Filter{expression: expr}, nil
+}
+
+const (
+	tokenWord = iota
+	tokenString
+	tokenOperator
+	tokenLeftParen
+	tokenRightParen
+	tokenComma
+	tokenAnd
+	tokenOr
+	tokenNot
+)
+
+type token struct {
+	kind  int
+	value string
+	pos   int
+}
+
+func isWordRune(r rune) bool {
+	return !unicode.IsSpace(r) && !strings.ContainsRune(`(),!=<>~&|"`, r)
+}
+
+func tokenize(s string) ([]token, error) {
+	tokens := make([]token, 0)
+	runes := []rune(s)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		next := rune(0)
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{tokenLeftParen, "(", i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{tokenRightParen, ")", i})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{tokenComma, ",", i})
+			i++
+		case r == '&' && next == '&':
+			tokens = append(tokens, token{tokenAnd, "&&", i})
+			i += 2
+		case r == '|' && next == '|':
+			tokens = append(tokens, token{tokenOr, "||", i})
+			i += 2
+		case r == '=' && next == '=', r == '!' && next == '=', r == '!' && next == '~', r == '>' && next == '=', r == '<' && next == '=':
+			tokens = append(tokens, token{tokenOperator, string(runes[i : i+2]), i})
+			i += 2
+		case r == '=', r == '~', r == '>', r == '<':
+			tokens = append(tokens, token{tokenOperator, string(r), i})
+			i++
+		case r == '!':
+			tokens = append(tokens, token{tokenNot, "!", i})
+			i++
+		case r == '"':
+			j := i + 1
+			for ; j < len(runes) && runes[j] != '"'; j++ {
+				if runes[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(runes) {
+				return nil, fmt.Errorf("invalid filter: unterminated string at %d", i)
+			}
+			value, err := strconv.Unquote(string(runes[i : j+1]))
+			if err != nil {
+				return nil, fmt.Errorf("invalid filter: invalid string at %d", i)
+			}
+			tokens = append(tokens, token{tokenString, value, i})
+			i = j + 1
+		case isWordRune(r):
+			j := i
+			for j < len(runes) && isWordRune(runes[j]) {
+				j++
+			}
+			tokens = append(tokens, token{tokenWord, string(runes[i:j]), i})
+			i = j
+		default:
+			return nil, fmt.Errorf("invalid filter: unexpected %c at %d", r, i)
+		}
+	}
+
+	return tokens, nil
+}
+
+type filterParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *filterParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *filterParser) peek() token {
+	if p.done() {
+		return token{kind: -1, value: "end of filter", pos: -1}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *filterParser) isKeyword(keyword string) bool {
+	t := p.peek()
+	return t.kind == tokenWord && strings.EqualFold(t.value, keyword)
+}
+
+func (p *filterParser) expect(kind int, value string) error {
+	if t := p.peek(); t.kind != kind {
+		return fmt.Errorf("invalid filter: expected %s, got %s", value, t.value)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *filterParser) parseOr() (expression, error) {
+	expr, err := p.parseAnd()
+
+	if err != nil {
+		return nil, err
+	}
+
+	or := orExpression{expr}
+
+	for p.peek().kind == tokenOr || p.isKeyword("or") {
+		p.pos++
+		expr, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		or = append(or, expr)
+	}
+
+	if len(or) == 1 {
+		return or[0], nil
+	}
+
+	return or, nil
+}
+
+func (p *filterParser) parseAnd() (expression, error) {
+	expr, err := p.parseUnary()
+
+	if err != nil {
+		return nil, err
+	}
+
+	and := andExpression{expr}
+
+	for p.peek().kind == tokenComma || p.peek().kind == tokenAnd || p.isKeyword("and") {
+		p.pos++
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		and = append(and, expr)
+	}
+
+	if len(and) == 1 {
+		return and[0], nil
+	}
+
+	return and, nil
+}
+
+func (p *filterParser) parseUnary() (expression, error) {
+	t := p.peek()
+
+	switch {
+	case t.kind == tokenNot:
+		p.pos++
+		// !field is the negation of existence, as label selectors do
+		if next := p.peek(); next.kind == tokenWord || next.kind == tokenString {
+			p.pos++
+			return predicate{field: next.value, operator: DoesNotExist}, nil
+		}
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpression{expr}, nil
+	case p.isKeyword("not"):
+		p.pos++
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpression{expr}, nil
+	case t.kind == tokenLeftParen:
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenRightParen, ")"); err != nil {
+			return nil, err
+		}
+		return expr, nil
+	default:
+		return p.parsePredicate()
+	}
+}
+
+func (p *filterParser) parsePredicate() (expression, error) {
+	field := p.peek()
+
+	if field.kind != tokenWord && field.kind != tokenString {
+		return nil, fmt.Errorf("invalid filter: expected field, got %s", field.value)
+	}
+
+	p.pos++
+
+	switch t := p.peek(); {
+	case t.kind == tokenOperator:
+		p.pos++
+		value := p.peek()
+		if value.kind != tokenWord && value.kind != tokenString {
+			return nil, fmt.Errorf("invalid filter: expected value of %s, got %s", field.value, value.value)
+		}
+		p.pos++
+		return predicate{field: field.value, operator: t.value, values: []string{value.value}}, nil
+	case p.isKeyword(In), p.isKeyword(NotIn):
+		p.pos++
+		values, err := p.parseValues()
+		if err != nil {
+			return nil, err
+		}
+		return predicate{field: field.value, operator: strings.ToLower(t.value), values: values}, nil
+	default:
+		return predicate{field: field.value, operator: Exists}, nil
+	}
+}
+
+func (p *filterParser) parseValues() ([]string, error) {
+	if err := p.expect(tokenLeftParen, "("); err != nil {
+		return nil, err
+	}
+
+	values := make([]string, 0)
+
+	for {
+		value := p.peek()
+		if value.kind != tokenWord && value.kind != tokenString {
+			return nil, fmt.Errorf("invalid filter: expected value, got %s", value.value)
+		}
+		p.pos++
+		values = append(values, value.value)
+
+		if p.peek().kind != tokenComma {
+			break
+		}
+		p.pos++
+	}
+
+	if err := p.expect(tokenRightParen, ")"); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
@@ -0,0 +1,124 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package params
+
+import "testing"
+
+type fakeFields map[string][]string
+
+func (f fakeFields) Values(field string) []string {
+	return f[field]
+}
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		filter   string
+		expected string
+		err      bool
+	}{
+		{"", "", false},
+		{"name=nginx", `name="nginx"`, false},
+		{`name = "my app"`, `name="my app"`, false},
+		{`annotations.desc!~"a=b,c"`, `annotations.desc!~"a=b,c"`, false},
+		{"labels.app in (web, api)", `labels.app in ("web", "api")`, false},
+		{"labels.app NOTIN (web)", `labels.app notin ("web")`, false},
+		{"labels.app, !labels.tier", `(labels.app) && (!labels.tier)`, false},
+		{"a=1 || b=2 && c=3", `(a="1") || ((b="2") && (c="3"))`, false},
+		{"(a=1 or b=2) and c=3", `((a="1") || (b="2")) && (c="3")`, false},
+		{"!(a=1), not b=2", `(!(a="1")) && (!(b="2"))`, false},
+		{"createTime>=2019-06-01T00:00:00Z", `createTime>="2019-06-01T00:00:00Z"`, false},
+		{"name=", "", true},
+		{"name=a)", "", true},
+		{"(name=a", "", true},
+		{`name="a`, "", true},
+		{"labels.app in (web,", "", true},
+		{"a=1 ||", "", true},
+	}
+
+	for _, test := range tests {
+		filter, err := ParseFilter(test.filter)
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: expected error, got %s", test.filter, filter)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.filter, err)
+			continue
+		}
+		if got := filter.String(); got != test.expected {
+			t.Errorf("%s: expected %s, got %s", test.filter, test.expected, got)
+		}
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	fields := fakeFields{
+		"name":       {"nginx"},
+		"status":     {"running"},
+		"labels.app": {"web"},
+		"ownerKind":  {"ReplicaSet"},
+		"createTime": {"2019-06-01T10:00:00Z"},
+		"replicas":   {"3"},
+		"images":     {"nginx:1.15", "busybox"},
+	}
+
+	tests := []struct {
+		filter   string
+		expected bool
+	}{
+		{"", true},
+		{"name=nginx", true},
+		{"name==nginx", true},
+		{"name!=nginx", false},
+		{"name~gin", true},
+		{"name!~gin", false},
+		{"labels.app in (web, api)", true},
+		{"labels.app notin (web, api)", false},
+		{"labels.app", true},
+		{"!labels.app", false},
+		{"labels.tier", false},
+		{"!labels.tier", true},
+		{"labels.tier!=db", true},
+		{"labels.tier notin (db)", true},
+		{"labels.tier=db", false},
+		{"ownerKind=ReplicaSet, status=running", true},
+		{"ownerKind=Job || status=running", true},
+		{"ownerKind=Job || status=stopped", false},
+		{"!(ownerKind=Job)", true},
+		{"createTime>=2019-06-01", true},
+		{"createTime>2019-06-01T10:00:00Z", false},
+		{"createTime>=2019-06-01, createTime<2019-06-02", true},
+		{"replicas>=3 && replicas<10", true},
+		{"replicas>10", false},
+		{"images=busybox", true},
+		{"images!=busybox", false},
+	}
+
+	for _, test := range tests {
+		filter, err := ParseFilter(test.filter)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.filter, err)
+			continue
+		}
+		if got := filter.Match(fields); got != test.expected {
+			t.Errorf("%s: expected %v, got %v", test.filter, test.expected, got)
+		}
+	}
+}
@@ -20,6 +20,9 @@ package params
 import (
 	"fmt"
 	"github.com/emicklei/go-restful"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"kubesphere.io/kubesphere/pkg/constants"
+	"kubesphere.io/kubesphere/pkg/utils/k8sutil"
 	"regexp"
 	"strconv"
 	"strings"
@@ -31,6 +34,13 @@ const (
 	ConditionsParam = "conditions"
 	ReverseParam    = "reverse"
 	NameParam       = "name"
+	LimitParam      = "limit"
+	ContinueParam   = "continue"
+	FieldsParam     = "fields"
+	OutputParam     = "output"
+	OutputTable     = "table"
+	WatchParam      = "watch"
+	TimeoutParam    = "timeoutSeconds"
 )
 
 func ParsePaging(paging string) (limit, offset int) {
@@ -73,6 +83,17 @@ func ParseConditions(conditionsStr string) (*Conditions, error) {
 	return conditions, nil
 }
 
+// ParseFields parses comma separated fields, such as metadata.name,status.phase
+func ParseFields(fieldsStr string) []string {
+	fields := make([]string, 0)
+	for _, field := range strings.Split(fieldsStr, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
 func ParseReverse(req *restful.Request) bool {
 	reverse := req.QueryParameter(ReverseParam)
 	b, err := strconv.ParseBool(reverse)
@@ -82,7 +103,14 @@ func ParseReverse(req *restful.Request) bool {
 	return b
 }
 
+// ParseIdentity returns the identity of the request forwarded by the api gateway in X-Token-* headers
+func ParseIdentity(req *restful.Request) user.Info {
+	return k8sutil.NewIdentity(req.HeaderParameter(constants.UserNameHeader), req.HeaderParameter(constants.UserKindHeader))
+}
+
 type Conditions struct {
 	Match map[string]string
 	Fuzzy map[string]string
+	// evaluated after Match and Fuzzy, only supported by resources api
+	Filter *Filter
 }
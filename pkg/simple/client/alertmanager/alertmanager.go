@@ -0,0 +1,171 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+
+package alertmanager
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+var endpoint string
+
+func init() {
+	flag.StringVar(&endpoint, "alertmanager-endpoint", "http://alertmanager-main.kubesphere-monitoring-system.svc:9093", "Alertmanager receiving alerts of alert rules, firing alerts and silences are queried from it")
+}
+
+var client = &http.Client{Timeout: 10 * time.Second}
+
+// Matcher matches alerts by a label, the same as matchers of Alertmanager API v2
+type Matcher struct {
+	Name    string `json:"name" description:"label name"`
+	Value   string `json:"value" description:"label value, or a regular expression if isRegex is true"`
+	IsRegex bool   `json:"isRegex" description:"whether the value is a regular expression"`
+}
+
+type AlertStatus struct {
+	State       string   `json:"state" description:"one of active, suppressed and unprocessed"`
+	SilencedBy  []string `json:"silencedBy" description:"ids of silences muting the alert"`
+	InhibitedBy []string `json:"inhibitedBy" description:"fingerprints of alerts inhibiting the alert"`
+}
+
+type Alert struct {
+	Labels       map[string]string `json:"labels" description:"labels of the alert, alertrule, namespace, workspace and severity are set by alert rules"`
+	Annotations  map[string]string `json:"annotations,omitempty" description:"annotations of the alert, eg. summary"`
+	StartsAt     time.Time         `json:"startsAt" description:"time the alert started firing"`
+	EndsAt       time.Time         `json:"endsAt,omitempty" description:"time the alert is resolved if it isn't updated"`
+	UpdatedAt    time.Time         `json:"updatedAt,omitempty" description:"last time the alert is updated"`
+	Fingerprint  string            `json:"fingerprint" description:"identity of the alert"`
+	GeneratorURL string            `json:"generatorURL,omitempty" description:"url of the Prometheus expression"`
+	Status       AlertStatus       `json:"status" description:"status of the alert"`
+}
+
+type SilenceStatus struct {
+	State string `json:"state" description:"one of active, pending and expired"`
+}
+
+type Silence struct {
+	ID        string         `json:"id,omitempty" description:"id of the silence"`
+	Matchers  []Matcher      `json:"matchers" description:"alerts matching all of the matchers are silenced"`
+	StartsAt  time.Time      `json:"startsAt" description:"start time of the silence"`
+	EndsAt    time.Time      `json:"endsAt" description:"end time of the silence"`
+	CreatedBy string         `json:"createdBy" description:"user creating the silence"`
+	Comment   string         `json:"comment" description:"why alerts are silenced"`
+	Status    *SilenceStatus `json:"status,omitempty" description:"status of the silence"`
+	UpdatedAt *time.Time     `json:"updatedAt,omitempty" description:"last time the silence is updated"`
+}
+
+type Error struct {
+	Status  int
+	Message string
+}
+
+func (e Error) Error() string {
+	return fmt.Sprintf("alertmanager: status %d, %s", e.Status, e.Message)
+}
+
+// ListAlerts lists alerts matching all of the filters, which are matchers of Alertmanager, eg. namespace="demo".
+// Silenced and inhibited alerts are listed only if muted is true.
+func ListAlerts(filters []string, muted bool) ([]Alert, error) {
+	params := url.Values{"filter": filters}
+	params.Set("silenced", fmt.Sprint(muted))
+	params.Set("inhibited", fmt.Sprint(muted))
+
+	var alerts []Alert
+	err := do(http.MethodGet, "/api/v2/alerts?"+params.Encode(), nil, &alerts)
+	return alerts, err
+}
+
+func ListSilences(filters []string) ([]Silence, error) {
+	var silences []Silence
+	err := do(http.MethodGet, "/api/v2/silences?"+url.Values{"filter": filters}.Encode(), nil, &silences)
+	return silences, err
+}
+
+func GetSilence(id string) (*Silence, error) {
+	var silence Silence
+	if err := do(http.MethodGet, "/api/v2/silence/"+url.PathEscape(id), nil, &silence); err != nil {
+		return nil, err
+	}
+	return &silence, nil
+}
+
+// CreateSilence creates the silence and returns its id
+func CreateSilence(silence Silence) (string, error) {
+	var result struct {
+		SilenceID string `json:"silenceID"`
+	}
+	err := do(http.MethodPost, "/api/v2/silences", silence, &result)
+	return result.SilenceID, err
+}
+
+// ExpireSilence expires the silence, expired silences are kept by Alertmanager for a while
+func ExpireSilence(id string) error {
+	return do(http.MethodDelete, "/api/v2/silence/"+url.PathEscape(id), nil, nil)
+}
+
+func do(method, path string, body interface{}, result interface{}) error {
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequest(method, endpoint+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		glog.Errorln(err)
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return Error{Status: resp.StatusCode, Message: string(data)}
+	}
+
+	if result == nil || len(data) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(data, result)
+}
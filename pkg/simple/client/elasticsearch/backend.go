@@ -0,0 +1,119 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package esclient
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// elasticsearch 6
+	BackendElasticsearch = "elasticsearch"
+	// opensearch, also for elasticsearch 7 and later
+	BackendOpenSearch = "opensearch"
+)
+
+// Backend is a storage of logs collected by fluent bit
+type Backend interface {
+	// Query runs the query, statistics or histogram operation of the parameters
+	Query(param QueryParameters) *QueryResult
+	// Search returns the page of records from the offset param.From, sorted by time in the order of param.Sort
+	Search(param QueryParameters) ([]LogRecord, error)
+	// Export streams all records matching the parameters, regardless of the size of the result
+	Export(param QueryParameters, handler RecordHandler) error
+}
+
+var (
+	backendName string
+
+	backendsMutex sync.RWMutex
+	backends      = make(map[string]func() Backend)
+)
+
+func init() {
+	flag.StringVar(&backendName, "logging-backend", BackendElasticsearch, "Storage of logs, one of elasticsearch (6.x), opensearch (also for elasticsearch 7.x and later) and loki.")
+
+	RegisterBackend(BackendElasticsearch, func() Backend {
+		return &elasticsearch{flavor: BackendElasticsearch}
+	})
+	RegisterBackend(BackendOpenSearch, func() Backend {
+		return &elasticsearch{flavor: BackendOpenSearch}
+	})
+}
+
+// RegisterBackend makes the backend selectable by --logging-backend
+func RegisterBackend(name string, factory func() Backend) {
+	backendsMutex.Lock()
+	defer backendsMutex.Unlock()
+
+	backends[name] = factory
+}
+
+// SetBackend selects the backend by name, it's set from --logging-backend by default
+func SetBackend(name string) error {
+	backendsMutex.Lock()
+	defer backendsMutex.Unlock()
+
+	if _, ok := backends[name]; !ok {
+		return unknownBackendError(name)
+	}
+
+	backendName = name
+	return nil
+}
+
+func getBackend() (Backend, error) {
+	backendsMutex.RLock()
+	defer backendsMutex.RUnlock()
+
+	factory, ok := backends[backendName]
+	if !ok {
+		return nil, unknownBackendError(backendName)
+	}
+
+	return factory(), nil
+}
+
+func unknownBackendError(name string) error {
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return fmt.Errorf("unknown logging backend %s, supported backends are %s", name, strings.Join(names, ", "))
+}
+
+// Query runs the operation of the parameters against the configured backend
+func Query(param QueryParameters) *QueryResult {
+	backend, err := getBackend()
+	if err != nil {
+		return &QueryResult{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	return backend.Query(param)
+}
+
+// elasticsearch queries indices written by the es output of fluent bit
+type elasticsearch struct {
+	flavor string
+}
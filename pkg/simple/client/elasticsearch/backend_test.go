@@ -0,0 +1,174 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package esclient
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func useBackend(t *testing.T, name string) func() {
+	previous := backendName
+	if err := SetBackend(name); err != nil {
+		t.Fatal(err)
+	}
+	return func() {
+		backendName = previous
+	}
+}
+
+func TestQuery(t *testing.T) {
+	tests := []struct {
+		backend  string
+		fixture  string
+		param    QueryParameters
+		expected QueryResult
+		// aggregation sent to elasticsearch
+		aggs map[string]interface{}
+	}{
+		{
+			backend: BackendElasticsearch,
+			fixture: "es6-query.json",
+			param:   QueryParameters{LogQuery: "error", From: 0, Size: 2},
+			expected: QueryResult{Status: http.StatusOK, Read: &ReadResult{Total: 1024, Size: 2, Records: []LogRecord{
+				{ID: "Qd3iJWsBiB8HQ9j8b7xB", Time: 1559666401263, Log: "2019/06/04 16:40:01 [error] 7#7: *42 open() \"/usr/share/nginx/html/favicon.ico\" failed\n",
+					Namespace: "demo", Pod: "nginx-6db489d4b7-k7r5j", Container: "nginx", Host: "node1",
+					HighLight: HighLight{LogHighLights: []string{"2019/06/04 16:40:01 [@highlighted-field@error@/highlighted-field@] 7#7"}}},
+				{ID: "Pt3iJWsBiB8HQ9j8XLzr", Time: 1559666398071, Log: "2019/06/04 16:39:58 [error] 7#7: *41 connect() failed (111: Connection refused) while connecting to upstream\n",
+					Namespace: "demo", Pod: "nginx-6db489d4b7-k7r5j", Container: "nginx", Host: "node1",
+					HighLight: HighLight{LogHighLights: []string{"2019/06/04 16:39:58 [@highlighted-field@error@/highlighted-field@] 7#7"}}},
+			}}},
+		},
+		{
+			backend: BackendOpenSearch,
+			fixture: "opensearch-query.json",
+			param:   QueryParameters{From: 10, Size: 1},
+			expected: QueryResult{Status: http.StatusOK, Read: &ReadResult{Total: 10000, From: 10, Size: 1, Records: []LogRecord{
+				{ID: "j3NZRn8BQ5e2wS1Xk0aU", Time: 1646121600125, Log: "level=info ts=2022-03-01T08:00:00.125Z caller=main.go:54 msg=\"starting server\"\n",
+					Namespace: "demo", Pod: "api-5f8d7b9c4-x2x7q", Container: "api", Host: "node2"},
+			}}},
+		},
+		{
+			backend:  BackendElasticsearch,
+			fixture:  "es6-statistics.json",
+			param:    QueryParameters{Operation: "statistics"},
+			expected: QueryResult{Status: http.StatusOK, Statistics: &StatisticsResult{Containers: 7, Logs: 5128}},
+			aggs:     map[string]interface{}{"containers": map[string]interface{}{"cardinality": map[string]interface{}{"field": "kubernetes.docker_id.keyword"}}},
+		},
+		{
+			backend: BackendElasticsearch,
+			fixture: "opensearch-histogram.json",
+			param:   QueryParameters{Operation: "histogram", Interval: "1d", StartTime: "1646121600000", EndTime: "1646294400000"},
+			expected: QueryResult{Status: http.StatusOK, Histogram: &HistogramResult{Total: 300, StartTime: 1646121600000, EndTime: 1646294400000, Interval: "1d",
+				Histograms: []HistogramRecord{{Time: 1646121600000, Count: 120}, {Time: 1646208000000, Count: 180}}}},
+			aggs: map[string]interface{}{"histogram": map[string]interface{}{"date_histogram": map[string]interface{}{"field": "time", "interval": "1d"}}},
+		},
+		{
+			backend: BackendOpenSearch,
+			fixture: "opensearch-histogram.json",
+			param:   QueryParameters{Operation: "histogram", Interval: "1d", StartTime: "1646121600000", EndTime: "1646294400000"},
+			expected: QueryResult{Status: http.StatusOK, Histogram: &HistogramResult{Total: 300, StartTime: 1646121600000, EndTime: 1646294400000, Interval: "1d",
+				Histograms: []HistogramRecord{{Time: 1646121600000, Count: 120}, {Time: 1646208000000, Count: 180}}}},
+			aggs: map[string]interface{}{"histogram": map[string]interface{}{"date_histogram": map[string]interface{}{"field": "time", "fixed_interval": "1d"}}},
+		},
+		{
+			backend: BackendOpenSearch,
+			fixture: "opensearch-histogram.json",
+			param:   QueryParameters{Operation: "histogram", Interval: "1M", StartTime: "1646121600000", EndTime: "1646294400000"},
+			expected: QueryResult{Status: http.StatusOK, Histogram: &HistogramResult{Total: 300, StartTime: 1646121600000, EndTime: 1646294400000, Interval: "1M",
+				Histograms: []HistogramRecord{{Time: 1646121600000, Count: 120}, {Time: 1646208000000, Count: 180}}}},
+			aggs: map[string]interface{}{"histogram": map[string]interface{}{"date_histogram": map[string]interface{}{"field": "time", "calendar_interval": "1M"}}},
+		},
+		{
+			backend: BackendOpenSearch,
+			fixture: "opensearch-fields.json",
+			param:   QueryParameters{From: 0, Size: 1},
+			expected: QueryResult{Status: http.StatusOK, Read: &ReadResult{Total: 1, Size: 1, Records: []LogRecord{
+				{ID: "r8NZRn8BQ5e2wS1Xl1bE", Time: 1646121601500, Log: "{\"level\":\"error\",\"trace_id\":\"4bf92f3577b34da6\",\"user_id\":42,\"msg\":\"payment declined\"}\n",
+					Namespace: "demo", Pod: "api-5f8d7b9c4-x2x7q", Container: "api", Host: "node2",
+					Fields: map[string]interface{}{"level": "error", "trace_id": "4bf92f3577b34da6", "user_id": float64(42), "msg": "payment declined"}},
+			}}},
+		},
+		{
+			backend: BackendOpenSearch,
+			fixture: "opensearch-aggregation.json",
+			param:   QueryParameters{Operation: "aggregation", AggregationField: "level", Size: 2},
+			expected: QueryResult{Status: http.StatusOK, Aggregation: &AggregationResult{Field: "level", Others: 12,
+				Buckets: []AggregationBucket{{Value: "info", Count: 720}, {Value: "error", Count: 128}}}},
+			aggs: map[string]interface{}{"fields": map[string]interface{}{"terms": map[string]interface{}{"field": "level.keyword", "size": float64(2)}}},
+		},
+	}
+
+	for i, test := range tests {
+		restore := useBackend(t, test.backend)
+		fake, stop := startFakeES(t, map[string][]string{"GET /logstash*/_search": {test.fixture}})
+
+		result := Query(test.param)
+
+		stop()
+		restore()
+
+		if !reflect.DeepEqual(*result, test.expected) {
+			t.Errorf("case %d: expected %+v, got %+v", i, test.expected, *result)
+		}
+
+		if test.aggs != nil && !reflect.DeepEqual(fake.bodies[0]["aggs"], test.aggs) {
+			t.Errorf("case %d: expected aggregation %v, got %v", i, test.aggs, fake.bodies[0]["aggs"])
+		}
+	}
+}
+
+func TestFieldFilters(t *testing.T) {
+	defer useBackend(t, BackendOpenSearch)()
+
+	fake, stop := startFakeES(t, map[string][]string{"GET /logstash*/_search": {"opensearch-fields.json"}})
+	defer stop()
+
+	Query(QueryParameters{FieldFilters: []FieldFilter{{Field: "level", Value: "error"}, {Field: "trace_id", Value: "4bf92f3577b34da6"}, {Field: "level", Value: "warn"}}, Size: 10})
+
+	// filters of the same field match any of the values
+	expected := `{"bool":{"must":[` +
+		`{"bool":{"minimum_should_match":1,"should":[{"match_phrase":{"level":{"query":"error"}}},{"match_phrase":{"level":{"query":"warn"}}}]}},` +
+		`{"bool":{"minimum_should_match":1,"should":[{"match_phrase":{"trace_id":{"query":"4bf92f3577b34da6"}}}]}},` +
+		`{"range":{"time":{}}}]}}`
+	if query, _ := json.Marshal(fake.bodies[0]["query"]); string(query) != expected {
+		t.Errorf("expected query %s, got %s", expected, query)
+	}
+}
+
+func TestAggregationSize(t *testing.T) {
+	for size, expected := range map[int64]int64{0: defaultAggregationSize, 5: 5, 1000: maxAggregationSize} {
+		if actual := AggregationSize(QueryParameters{Size: size}); actual != expected {
+			t.Errorf("expected size %d of %d, got %d", expected, size, actual)
+		}
+	}
+}
+
+func TestSetBackend(t *testing.T) {
+	defer useBackend(t, BackendElasticsearch)()
+
+	if err := SetBackend("solr"); err == nil {
+		t.Error("expected error of unknown backend")
+	}
+
+	if backendName != BackendElasticsearch {
+		t.Errorf("expected backend unchanged, got %s", backendName)
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/golang/glog"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"strconv"
@@ -55,12 +56,12 @@ func (configs *ESConfigs) WriteESConfigs() {
 }
 
 type Request struct {
-	From          int64         `json:"from"`
-	Size          int64         `json:"size"`
-	Sorts         []Sort        `json:"sort,omitempty"`
-	MainQuery     BoolQuery     `json:"query"`
-	Aggs          interface{}   `json:"aggs,omitempty"`
-	MainHighLight MainHighLight `json:"highlight,omitempty"`
+	From          int64          `json:"from"`
+	Size          int64          `json:"size"`
+	Sorts         []Sort         `json:"sort,omitempty"`
+	MainQuery     BoolQuery      `json:"query"`
+	Aggs          interface{}    `json:"aggs,omitempty"`
+	MainHighLight *MainHighLight `json:"highlight,omitempty"`
 }
 
 type Sort struct {
@@ -160,11 +161,28 @@ type HistogramAgg struct {
 
 type DateHistogram struct {
 	Field    string `json:"field"`
-	Interval string `json:"interval"`
+	Interval string `json:"interval,omitempty"`
+	// interval is replaced by fixed_interval and calendar_interval since elasticsearch 7.2
+	FixedInterval    string `json:"fixed_interval,omitempty"`
+	CalendarInterval string `json:"calendar_interval,omitempty"`
 }
 
-func createQueryRequest(param QueryParameters) (int, []byte, error) {
-	var request Request
+func newDateHistogram(interval string, flavor string) DateHistogram {
+	if flavor == BackendElasticsearch {
+		return DateHistogram{Field: "time", Interval: interval}
+	}
+
+	// calendar units can only be used with a quantity of 1
+	switch interval[len(interval)-1] {
+	case 'w', 'M', 'q', 'y':
+		return DateHistogram{Field: "time", CalendarInterval: interval}
+	}
+
+	return DateHistogram{Field: "time", FixedInterval: interval}
+}
+
+// createBoolQuery translates filters of the parameters to clauses of the bool query
+func createBoolQuery(param QueryParameters) BoolMusts {
 	var mainBoolQuery BoolMusts
 
 	if param.NamespaceFilled {
@@ -235,9 +253,26 @@ func createQueryRequest(param QueryParameters) (int, []byte, error) {
 		mainBoolQuery.Musts = append(mainBoolQuery.Musts, match)
 	}
 
+	for _, filters := range GroupFieldFilters(param.FieldFilters) {
+		var shouldMatchPhrase ShouldMatchPhrase
+		for _, filter := range filters {
+			matchPhrase := MatchPhrase{map[string]interface{}{filter.Field: QueryWord{filter.Value}}}
+			shouldMatchPhrase.Shoulds = append(shouldMatchPhrase.Shoulds, matchPhrase)
+		}
+		shouldMatchPhrase.MinimumShouldMatch = 1
+		mainBoolQuery.Musts = append(mainBoolQuery.Musts, BoolShouldMatchPhrase{shouldMatchPhrase})
+	}
+
 	rangeQuery := RangeQuery{RangeSpec{TimeRange{param.StartTime, param.EndTime}}}
 	mainBoolQuery.Musts = append(mainBoolQuery.Musts, rangeQuery)
 
+	return mainBoolQuery
+}
+
+func createQueryRequest(param QueryParameters, flavor string) (int, []byte, error) {
+	var request Request
+	mainBoolQuery := createBoolQuery(param)
+
 	var operation int
 
 	if param.Operation == "statistics" {
@@ -255,7 +290,11 @@ func createQueryRequest(param QueryParameters) (int, []byte, error) {
 			interval = "15m"
 		}
 		param.Interval = interval
-		request.Aggs = HistogramAggs{HistogramAgg{DateHistogram{"time", interval}}}
+		request.Aggs = HistogramAggs{HistogramAgg{newDateHistogram(interval, flavor)}}
+		request.Size = 0
+	} else if param.Operation == "aggregation" {
+		operation = OperationAggregation
+		request.Aggs = newTermsAggs(param)
 		request.Size = 0
 	} else {
 		operation = OperationQuery
@@ -275,7 +314,7 @@ func createQueryRequest(param QueryParameters) (int, []byte, error) {
 		mainHighLight.Fields = append(mainHighLight.Fields, PodHighLightField{})
 		mainHighLight.Fields = append(mainHighLight.Fields, ContainerHighLightField{})
 		mainHighLight.FragmentSize = 0
-		request.MainHighLight = mainHighLight
+		request.MainHighLight = &mainHighLight
 	}
 
 	request.MainQuery = BoolQuery{mainBoolQuery}
@@ -289,6 +328,7 @@ func createQueryRequest(param QueryParameters) (int, []byte, error) {
 // Response from the elasticsearch engine
 type Response struct {
 	Status       int             `json:"status"`
+	ScrollID     string          `json:"_scroll_id,omitempty"`
 	Workspace    string          `json:"workspace,omitempty"`
 	Shards       Shards          `json:"_shards"`
 	Hits         Hits            `json:"hits"`
@@ -303,20 +343,42 @@ type Shards struct {
 }
 
 type Hits struct {
-	Total int64 `json:"total"`
-	Hits  []Hit `json:"hits"`
+	Total HitsTotal `json:"total"`
+	Hits  []Hit     `json:"hits"`
+}
+
+// HitsTotal is a number before elasticsearch 7, and an object of value and relation since then
+type HitsTotal int64
+
+func (t *HitsTotal) UnmarshalJSON(data []byte) error {
+	var total struct {
+		Value int64 `json:"value"`
+	}
+
+	if len(data) > 0 && data[0] == '{' {
+		if err := json.Unmarshal(data, &total); err != nil {
+			return err
+		}
+	} else if err := json.Unmarshal(data, &total.Value); err != nil {
+		return err
+	}
+
+	*t = HitsTotal(total.Value)
+	return nil
 }
 
 type Hit struct {
+	ID        string    `json:"_id"`
 	Source    Source    `json:"_source"`
 	HighLight HighLight `json:"highlight"`
 	Sort      []int64   `json:"sort"`
 }
 
 type Source struct {
-	Log        string     `json:"log"`
-	Time       string     `json:"time"`
-	Kubernetes Kubernetes `json:"kubernetes"`
+	Log        string                 `json:"log"`
+	Time       string                 `json:"time"`
+	Kubernetes Kubernetes             `json:"kubernetes"`
+	Fields     map[string]interface{} `json:"-"`
 }
 
 type Kubernetes struct {
@@ -334,13 +396,16 @@ type HighLight struct {
 }
 
 type LogRecord struct {
-	Time      int64     `json:"time,omitempty" description:"log timestamp"`
-	Log       string    `json:"log,omitempty" description:"log message"`
-	Namespace string    `json:"namespace,omitempty" description:"namespace"`
-	Pod       string    `json:"pod,omitempty" description:"pod name"`
-	Container string    `json:"container,omitempty" description:"container name"`
-	Host      string    `json:"host,omitempty" description:"node id"`
-	HighLight HighLight `json:"highlight,omitempty" description:"highlighted log fragment"`
+	// id of the record in the storage, used to skip records sent in follow mode
+	ID        string                 `json:"-"`
+	Time      int64                  `json:"time,omitempty" description:"log timestamp"`
+	Log       string                 `json:"log,omitempty" description:"log message"`
+	Namespace string                 `json:"namespace,omitempty" description:"namespace"`
+	Pod       string                 `json:"pod,omitempty" description:"pod name"`
+	Container string                 `json:"container,omitempty" description:"container name"`
+	Host      string                 `json:"host,omitempty" description:"node id"`
+	HighLight HighLight              `json:"highlight,omitempty" description:"highlighted log fragment"`
+	Fields    map[string]interface{} `json:"fields,omitempty" description:"structured fields decoded from JSON logs"`
 }
 
 type ReadResult struct {
@@ -392,18 +457,20 @@ type HistogramResult struct {
 
 // Wrap elasticsearch response
 type QueryResult struct {
-	Status     int               `json:"status,omitempty" description:"query status"`
-	Error      string            `json:"error,omitempty" description:"debugging information"`
-	Workspace  string            `json:"workspace,omitempty" description:"the name of the workspace where logs come from"`
-	Read       *ReadResult       `json:"query,omitempty" description:"query results"`
-	Statistics *StatisticsResult `json:"statistics,omitempty" description:"statistics results"`
-	Histogram  *HistogramResult  `json:"histogram,omitempty" description:"histogram results"`
+	Status      int                `json:"status,omitempty" description:"query status"`
+	Error       string             `json:"error,omitempty" description:"debugging information"`
+	Workspace   string             `json:"workspace,omitempty" description:"the name of the workspace where logs come from"`
+	Read        *ReadResult        `json:"query,omitempty" description:"query results"`
+	Statistics  *StatisticsResult  `json:"statistics,omitempty" description:"statistics results"`
+	Histogram   *HistogramResult   `json:"histogram,omitempty" description:"histogram results"`
+	Aggregation *AggregationResult `json:"aggregation,omitempty" description:"aggregation results"`
 }
 
 const (
 	OperationQuery int = iota
 	OperationStatistics
 	OperationHistogram
+	OperationAggregation
 )
 
 func calcTimestamp(input string) int64 {
@@ -427,6 +494,20 @@ func calcTimestamp(input string) int64 {
 	return ret
 }
 
+func newLogRecord(hit Hit) LogRecord {
+	var logRecord LogRecord
+	logRecord.ID = hit.ID
+	logRecord.Time = calcTimestamp(hit.Source.Time)
+	logRecord.Log = hit.Source.Log
+	logRecord.Namespace = hit.Source.Kubernetes.Namespace
+	logRecord.Pod = hit.Source.Kubernetes.Pod
+	logRecord.Container = hit.Source.Kubernetes.Container
+	logRecord.Host = hit.Source.Kubernetes.Host
+	logRecord.HighLight = hit.HighLight
+	logRecord.Fields = hit.Source.Fields
+	return logRecord
+}
+
 func parseQueryResult(operation int, param QueryParameters, body []byte, query []byte) *QueryResult {
 	var queryResult QueryResult
 
@@ -457,19 +538,11 @@ func parseQueryResult(operation int, param QueryParameters, body []byte, query [
 	switch operation {
 	case OperationQuery:
 		var readResult ReadResult
-		readResult.Total = response.Hits.Total
+		readResult.Total = int64(response.Hits.Total)
 		readResult.From = param.From
 		readResult.Size = param.Size
 		for _, hit := range response.Hits.Hits {
-			var logRecord LogRecord
-			logRecord.Time = calcTimestamp(hit.Source.Time)
-			logRecord.Log = hit.Source.Log
-			logRecord.Namespace = hit.Source.Kubernetes.Namespace
-			logRecord.Pod = hit.Source.Kubernetes.Pod
-			logRecord.Container = hit.Source.Kubernetes.Container
-			logRecord.Host = hit.Source.Kubernetes.Host
-			logRecord.HighLight = hit.HighLight
-			readResult.Records = append(readResult.Records, logRecord)
+			readResult.Records = append(readResult.Records, newLogRecord(hit))
 		}
 		queryResult.Read = &readResult
 
@@ -482,11 +555,11 @@ func parseQueryResult(operation int, param QueryParameters, body []byte, query [
 			queryResult.Error = err.Error()
 			return &queryResult
 		}
-		queryResult.Statistics = &StatisticsResult{Containers: statisticsResponse.ContainerCount.Value, Logs: response.Hits.Total}
+		queryResult.Statistics = &StatisticsResult{Containers: statisticsResponse.ContainerCount.Value, Logs: int64(response.Hits.Total)}
 
 	case OperationHistogram:
 		var histogramResult HistogramResult
-		histogramResult.Total = response.Hits.Total
+		histogramResult.Total = int64(response.Hits.Total)
 		histogramResult.StartTime = calcTimestamp(param.StartTime)
 		histogramResult.EndTime = calcTimestamp(param.EndTime)
 		histogramResult.Interval = param.Interval
@@ -508,6 +581,17 @@ func parseQueryResult(operation int, param QueryParameters, body []byte, query [
 		}
 
 		queryResult.Histogram = &histogramResult
+
+	case OperationAggregation:
+		var termsAggregations TermsAggregations
+		err := jsonIter.Unmarshal(response.Aggregations, &termsAggregations)
+		if err != nil {
+			glog.Errorln(err)
+			queryResult.Status = http.StatusInternalServerError
+			queryResult.Error = err.Error()
+			return &queryResult
+		}
+		queryResult.Aggregation = newAggregationResult(param.AggregationField, termsAggregations.TermsAggregation)
 	}
 
 	queryResult.Status = http.StatusOK
@@ -516,6 +600,16 @@ func parseQueryResult(operation int, param QueryParameters, body []byte, query [
 	return &queryResult
 }
 
+// DedicatedIndex is the indices of a workspace with an index prefix, written by fluent bit in logstash format
+type DedicatedIndex struct {
+	Prefix string
+	// Since is the time in milliseconds logs of the workspace are written to the dedicated indices,
+	// logs before it are in the shared index
+	Since int64
+	// Namespaces are namespaces of the workspace queried
+	Namespaces []string
+}
+
 type QueryParameters struct {
 	NamespaceFilled           bool
 	Namespaces                []string
@@ -531,6 +625,15 @@ type QueryParameters struct {
 
 	Workspace string
 
+	// DedicatedIndices are indices of workspaces of the namespaces queried, the shared index is only searched
+	// for logs which may be there
+	DedicatedIndices []DedicatedIndex
+
+	// FieldFilters match structured fields of records, all of them must match
+	FieldFilters []FieldFilter
+	// AggregationField is the field to count logs by of the aggregation operation
+	AggregationField string
+
 	Operation string
 	LogQuery  string
 	Interval  string
@@ -549,12 +652,12 @@ func stubResult() *QueryResult {
 	return &queryResult
 }
 
-func Query(param QueryParameters) *QueryResult {
+func (c *elasticsearch) Query(param QueryParameters) *QueryResult {
 	var queryResult *QueryResult
 
 	client := &http.Client{}
 
-	operation, query, err := createQueryRequest(param)
+	operation, query, err := createQueryRequest(param, c.flavor)
 	if err != nil {
 		queryResult = new(QueryResult)
 		queryResult.Status = http.StatusInternalServerError
@@ -570,7 +673,7 @@ func Query(param QueryParameters) *QueryResult {
 		return queryResult
 	}
 
-	url := fmt.Sprintf("http://%s:%s/%s*/_search", es.Host, es.Port, es.Index)
+	url := fmt.Sprintf("http://%s:%s/%s/_search", es.Host, es.Port, indexPattern(es, param))
 
 	request, err := http.NewRequest("GET", url, bytes.NewBuffer(query))
 	if err != nil {
@@ -605,3 +708,87 @@ func Query(param QueryParameters) *QueryResult {
 
 	return queryResult
 }
+
+// Bulk indexes documents into the given index of the Elasticsearch of the configs, instead of the one of logging,
+// the index is created by elasticsearch on demand
+func (configs *ESConfigs) Bulk(index string, documents []interface{}) error {
+	var buffer bytes.Buffer
+
+	for _, document := range documents {
+		if err := writeBulkAction(&buffer, bulkAction{Index: index, Type: "_doc"}, document); err != nil {
+			return err
+		}
+	}
+
+	failed, err := postBulk(fmt.Sprintf("http://%s:%s/_bulk", configs.Host, configs.Port), &buffer)
+	if err != nil {
+		return err
+	}
+
+	if failed {
+		return fmt.Errorf("some documents failed to be indexed into %s", index)
+	}
+
+	return nil
+}
+
+type bulkAction struct {
+	Index string `json:"_index"`
+	// mapping types are removed since elasticsearch 7, it must be left empty for opensearch
+	Type string `json:"_type,omitempty"`
+	// documents with the same id are overwritten, elasticsearch generates one if it's empty
+	ID string `json:"_id,omitempty"`
+}
+
+func writeBulkAction(buffer *bytes.Buffer, action bulkAction, document interface{}) error {
+	header, err := json.Marshal(struct {
+		Index bulkAction `json:"index"`
+	}{action})
+	if err != nil {
+		return err
+	}
+
+	source, err := json.Marshal(document)
+	if err != nil {
+		return err
+	}
+
+	buffer.Write(header)
+	buffer.WriteByte('\n')
+	buffer.Write(source)
+	buffer.WriteByte('\n')
+	return nil
+}
+
+// postBulk sends the bulk request, it returns true if any of the actions failed
+func postBulk(url string, body io.Reader) (bool, error) {
+	request, err := http.NewRequest("POST", url, body)
+	if err != nil {
+		return false, err
+	}
+	request.Header.Set("Content-Type", "application/x-ndjson")
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return false, err
+	}
+	defer response.Body.Close()
+
+	data, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return false, err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("bulk request failed with status %d: %s", response.StatusCode, string(data))
+	}
+
+	var result struct {
+		Errors bool `json:"errors"`
+	}
+	if err := jsonIter.Unmarshal(data, &result); err != nil {
+		return false, err
+	}
+
+	return result.Errors, nil
+}
@@ -0,0 +1,345 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package esclient
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// EventsIndexPrefix is the prefix of daily indices kubernetes events are written to,
+// it's out of the index pattern of logs so events don't turn up in log queries
+const EventsIndexPrefix = "ks-logstash-events"
+
+// Event is a kubernetes event enriched with the workspace and workload of the involved object
+type Event struct {
+	UID            string         `json:"uid" description:"uid of the event"`
+	Time           string         `json:"time" description:"the time the event last occurred, in RFC3339"`
+	FirstTime      string         `json:"first_time,omitempty" description:"the time the event first occurred, in RFC3339"`
+	Count          int32          `json:"count,omitempty" description:"the number of times the event occurred"`
+	Type           string         `json:"type,omitempty" description:"type of the event, Normal or Warning"`
+	Reason         string         `json:"reason,omitempty" description:"short reason of the event, eg. FailedScheduling"`
+	Message        string         `json:"message,omitempty" description:"human-readable description of the event"`
+	Source         string         `json:"source,omitempty" description:"the component reporting the event"`
+	Host           string         `json:"host,omitempty" description:"the node reporting the event"`
+	Workspace      string         `json:"workspace,omitempty" description:"workspace of the namespace"`
+	Namespace      string         `json:"namespace,omitempty" description:"namespace of the involved object"`
+	WorkloadKind   string         `json:"workload_kind,omitempty" description:"kind of the workload the involved object belongs to"`
+	Workload       string         `json:"workload,omitempty" description:"name of the workload the involved object belongs to"`
+	InvolvedObject InvolvedObject `json:"involved_object" description:"the object the event is about"`
+}
+
+type InvolvedObject struct {
+	Kind      string `json:"kind,omitempty" description:"kind of the object"`
+	Name      string `json:"name,omitempty" description:"name of the object"`
+	UID       string `json:"uid,omitempty" description:"uid of the object"`
+	FieldPath string `json:"field_path,omitempty" description:"part of the object the event is about, eg. spec.containers{nginx}"`
+}
+
+type EventQueryParameters struct {
+	NamespaceFilled           bool
+	NamespaceWithCreationTime map[string]string
+	WorkloadFilled            bool
+	Workloads                 []string
+	InvolvedObjectKinds       []string
+	InvolvedObjectNames       []string
+	Reasons                   []string
+	Types                     []string
+
+	NamespaceQuery string
+	WorkloadQuery  string
+	MessageQuery   string
+
+	Operation string
+	Interval  string
+	StartTime string
+	EndTime   string
+	Sort      string
+	From      int64
+	Size      int64
+}
+
+type EventsReadResult struct {
+	Total   int64   `json:"total" description:"total number of matched events"`
+	From    int64   `json:"from" description:"the offset from the result set"`
+	Size    int64   `json:"size" description:"the amount of hits to be returned"`
+	Records []Event `json:"records,omitempty" description:"actual array of events"`
+}
+
+type EventStatisticsResult struct {
+	Events   int64 `json:"events" description:"total number of events"`
+	Warnings int64 `json:"warnings" description:"total number of warning events"`
+}
+
+type EventsResult struct {
+	Status     int                    `json:"status,omitempty" description:"query status"`
+	Error      string                 `json:"error,omitempty" description:"debugging information"`
+	Read       *EventsReadResult      `json:"query,omitempty" description:"query results"`
+	Statistics *EventStatisticsResult `json:"statistics,omitempty" description:"statistics results"`
+	Histogram  *HistogramResult       `json:"histogram,omitempty" description:"histogram results"`
+}
+
+// EventsBackend is implemented by backends able to store kubernetes events
+type EventsBackend interface {
+	// IndexEvents writes the events, events of the same uid are overwritten
+	IndexEvents(events []Event) error
+	// QueryEvents runs the query, statistics or histogram operation of the parameters
+	QueryEvents(param EventQueryParameters) *EventsResult
+}
+
+func getEventsBackend() (EventsBackend, error) {
+	backend, err := getBackend()
+	if err != nil {
+		return nil, err
+	}
+
+	events, ok := backend.(EventsBackend)
+	if !ok {
+		return nil, fmt.Errorf("logging backend %s doesn't support events", backendName)
+	}
+
+	return events, nil
+}
+
+// IndexEvents writes the events to the configured backend
+func IndexEvents(events []Event) error {
+	backend, err := getEventsBackend()
+	if err != nil {
+		return err
+	}
+
+	return backend.IndexEvents(events)
+}
+
+// QueryEvents runs the operation of the parameters against the configured backend
+func QueryEvents(param EventQueryParameters) *EventsResult {
+	backend, err := getEventsBackend()
+	if err != nil {
+		return &EventsResult{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	return backend.QueryEvents(param)
+}
+
+// eventsIndex returns the daily index of the event by the time it first occurred,
+// so updates of the event are written to the same index
+func eventsIndex(event Event) string {
+	t, err := time.Parse(time.RFC3339, event.FirstTime)
+	if err != nil {
+		t, _ = time.Parse(time.RFC3339, event.Time)
+	}
+	return fmt.Sprintf("%s-%s", EventsIndexPrefix, t.UTC().Format("2006.01.02"))
+}
+
+func (c *elasticsearch) IndexEvents(events []Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	var buffer bytes.Buffer
+
+	for _, event := range events {
+		action := bulkAction{Index: eventsIndex(event), ID: event.UID}
+		if c.flavor == BackendElasticsearch {
+			action.Type = "_doc"
+		}
+		if err := writeBulkAction(&buffer, action, event); err != nil {
+			return err
+		}
+	}
+
+	url, err := esURL("_bulk")
+	if err != nil {
+		return err
+	}
+
+	failed, err := postBulk(url, &buffer)
+	if err != nil {
+		return err
+	}
+
+	if failed {
+		return fmt.Errorf("some events failed to be indexed into %s", EventsIndexPrefix)
+	}
+
+	return nil
+}
+
+func (c *elasticsearch) QueryEvents(param EventQueryParameters) *EventsResult {
+	url, err := esURL(EventsIndexPrefix + "-*/_search")
+	if err != nil {
+		return &EventsResult{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	request := map[string]interface{}{
+		"query": map[string]interface{}{"bool": map[string]interface{}{"filter": eventFilters(param)}},
+	}
+
+	switch param.Operation {
+	case "statistics":
+		request["size"] = 0
+		request["aggs"] = map[string]interface{}{
+			"warnings": map[string]interface{}{"filter": terms("type.keyword", []string{"Warning"})},
+		}
+	case "histogram":
+		if param.Interval == "" {
+			param.Interval = "15m"
+		}
+		request["size"] = 0
+		request["aggs"] = map[string]interface{}{
+			"histogram": map[string]interface{}{"date_histogram": newDateHistogram(param.Interval, c.flavor)},
+		}
+	default:
+		request["from"] = param.From
+		request["size"] = param.Size
+		request["sort"] = []interface{}{map[string]interface{}{"time": map[string]string{"order": sortOrder(QueryParameters{Sort: param.Sort}, "desc")}}}
+	}
+
+	var response struct {
+		Hits struct {
+			Total HitsTotal `json:"total"`
+			Hits  []struct {
+				Source Event `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+		Aggregations struct {
+			Warnings struct {
+				Count int64 `json:"doc_count"`
+			} `json:"warnings"`
+			Histogram HistogramAggregation `json:"histogram"`
+		} `json:"aggregations"`
+	}
+
+	if err := doRawRequest(http.MethodPost, url, request, &response); err != nil {
+		return &EventsResult{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	result := &EventsResult{Status: http.StatusOK}
+
+	switch param.Operation {
+	case "statistics":
+		result.Statistics = &EventStatisticsResult{Events: int64(response.Hits.Total), Warnings: response.Aggregations.Warnings.Count}
+	case "histogram":
+		histogram := &HistogramResult{
+			Total:     int64(response.Hits.Total),
+			StartTime: calcTimestamp(param.StartTime),
+			EndTime:   calcTimestamp(param.EndTime),
+			Interval:  param.Interval,
+		}
+		for _, bucket := range response.Aggregations.Histogram.Histograms {
+			histogram.Histograms = append(histogram.Histograms, HistogramRecord{Time: bucket.Time, Count: bucket.Count})
+		}
+		result.Histogram = histogram
+	default:
+		read := &EventsReadResult{Total: int64(response.Hits.Total), From: param.From, Size: param.Size}
+		for _, hit := range response.Hits.Hits {
+			read.Records = append(read.Records, hit.Source)
+		}
+		result.Read = read
+	}
+
+	return result
+}
+
+// eventFilters translates filters of the parameters to clauses of the bool query
+func eventFilters(param EventQueryParameters) []interface{} {
+	var filters []interface{}
+
+	if param.NamespaceFilled && len(param.NamespaceWithCreationTime) == 0 {
+		filters = append(filters, terms("namespace.keyword", nil))
+	} else if param.NamespaceFilled {
+		namespaces := make([]string, 0, len(param.NamespaceWithCreationTime))
+		for namespace := range param.NamespaceWithCreationTime {
+			namespaces = append(namespaces, namespace)
+		}
+		sort.Strings(namespaces)
+
+		var shoulds []interface{}
+		for _, namespace := range namespaces {
+			// events of a deleted namespace of the same name are left out
+			shoulds = append(shoulds, map[string]interface{}{
+				"bool": map[string]interface{}{
+					"filter": []interface{}{
+						terms("namespace.keyword", []string{namespace}),
+						timeRange(param.NamespaceWithCreationTime[namespace], ""),
+					},
+				},
+			})
+		}
+		filters = append(filters, map[string]interface{}{
+			"bool": map[string]interface{}{"should": shoulds, "minimum_should_match": 1},
+		})
+	}
+
+	if param.WorkloadFilled {
+		filters = append(filters, terms("workload.keyword", param.Workloads))
+	}
+
+	for _, clause := range []struct {
+		field  string
+		values []string
+	}{
+		{"involved_object.kind.keyword", param.InvolvedObjectKinds},
+		{"involved_object.name.keyword", param.InvolvedObjectNames},
+		{"reason.keyword", param.Reasons},
+		{"type.keyword", param.Types},
+	} {
+		if len(clause.values) > 0 {
+			filters = append(filters, terms(clause.field, clause.values))
+		}
+	}
+
+	for _, clause := range []struct {
+		field    string
+		keywords string
+	}{
+		{"namespace", param.NamespaceQuery},
+		{"workload", param.WorkloadQuery},
+		{"message", param.MessageQuery},
+	} {
+		if clause.keywords != "" {
+			filters = append(filters, map[string]interface{}{
+				"match": map[string]interface{}{clause.field: QueryWord{clause.keywords}},
+			})
+		}
+	}
+
+	return append(filters, timeRange(param.StartTime, param.EndTime))
+}
+
+// terms matches none of the documents if values are empty
+func terms(field string, values []string) map[string]interface{} {
+	if values == nil {
+		values = []string{}
+	}
+	return map[string]interface{}{"terms": map[string]interface{}{field: values}}
+}
+
+func timeRange(gte, lte string) map[string]interface{} {
+	spec := map[string]string{}
+	if gte != "" {
+		spec["gte"] = gte
+	}
+	if lte != "" {
+		spec["lte"] = lte
+	}
+	return map[string]interface{}{"range": map[string]interface{}{"time": spec}}
+}
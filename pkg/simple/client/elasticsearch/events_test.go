@@ -0,0 +1,135 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package esclient
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestIndexEvents(t *testing.T) {
+	tests := []struct {
+		flavor  string
+		actions []string
+	}{
+		{
+			BackendElasticsearch,
+			[]string{
+				`{"index":{"_index":"ks-logstash-events-2019.10.16","_type":"_doc","_id":"a"}}`,
+				`{"index":{"_index":"ks-logstash-events-2019.10.17","_type":"_doc","_id":"b"}}`,
+			},
+		},
+		{
+			BackendOpenSearch,
+			[]string{
+				`{"index":{"_index":"ks-logstash-events-2019.10.16","_id":"a"}}`,
+				`{"index":{"_index":"ks-logstash-events-2019.10.17","_id":"b"}}`,
+			},
+		},
+	}
+
+	events := []Event{
+		// updates are written to the index of the day the event first occurred
+		{UID: "a", Time: "2019-10-17T00:10:00Z", FirstTime: "2019-10-16T23:50:00Z", Reason: "BackOff"},
+		{UID: "b", Time: "2019-10-17T08:00:00Z", Reason: "Scheduled"},
+	}
+
+	for _, test := range tests {
+		fake, stop := startFakeES(t, map[string][]string{"POST /_bulk": {`{"errors":false}`}})
+
+		if err := (&elasticsearch{flavor: test.flavor}).IndexEvents(events); err != nil {
+			t.Errorf("%s: %v", test.flavor, err)
+		}
+
+		var actions []string
+		lines := strings.Split(strings.TrimSpace(fake.payloads[0]), "\n")
+		for i := 0; i < len(lines); i += 2 {
+			actions = append(actions, lines[i])
+
+			var event Event
+			if err := json.Unmarshal([]byte(lines[i+1]), &event); err != nil || event.Reason != events[i/2].Reason {
+				t.Errorf("%s: unexpected document %s", test.flavor, lines[i+1])
+			}
+		}
+
+		if !reflect.DeepEqual(actions, test.actions) {
+			t.Errorf("%s: expected actions %v, got %v", test.flavor, test.actions, actions)
+		}
+
+		stop()
+	}
+}
+
+func TestQueryEvents(t *testing.T) {
+	fake, stop := startFakeES(t, map[string][]string{
+		"POST /ks-logstash-events-*/_search": {"events-query.json", "events-statistics.json"},
+	})
+	defer stop()
+
+	es := &elasticsearch{flavor: BackendOpenSearch}
+
+	result := es.QueryEvents(EventQueryParameters{
+		NamespaceFilled:           true,
+		NamespaceWithCreationTime: map[string]string{"billing": "1571270400000"},
+		WorkloadFilled:            true,
+		Workloads:                 []string{"invoice"},
+		Types:                     []string{"Warning"},
+		MessageQuery:              "back-off",
+		StartTime:                 "1571270400000",
+		Size:                      10,
+	})
+
+	if result.Read == nil || result.Read.Total != 1 || len(result.Read.Records) != 1 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+
+	record := result.Read.Records[0]
+	if record.Workload != "invoice" || record.InvolvedObject.Kind != "Pod" || record.Count != 12 {
+		t.Errorf("unexpected event %+v", record)
+	}
+
+	expected := `{"bool":{"filter":[` +
+		`{"bool":{"minimum_should_match":1,"should":[{"bool":{"filter":[{"terms":{"namespace.keyword":["billing"]}},{"range":{"time":{"gte":"1571270400000"}}}]}}]}},` +
+		`{"terms":{"workload.keyword":["invoice"]}},` +
+		`{"terms":{"type.keyword":["Warning"]}},` +
+		`{"match":{"message":{"query":"back-off"}}},` +
+		`{"range":{"time":{"gte":"1571270400000"}}}]}}`
+	if query, _ := json.Marshal(fake.bodies[0]["query"]); string(query) != expected {
+		t.Errorf("expected query %s, got %s", expected, query)
+	}
+	if sort, _ := json.Marshal(fake.bodies[0]["sort"]); string(sort) != `[{"time":{"order":"desc"}}]` {
+		t.Errorf("unexpected sort %s", sort)
+	}
+
+	result = es.QueryEvents(EventQueryParameters{Operation: "statistics"})
+	if result.Statistics == nil || *result.Statistics != (EventStatisticsResult{Events: 42, Warnings: 7}) {
+		t.Errorf("unexpected statistics %+v", result.Statistics)
+	}
+}
+
+func TestEventFiltersOfNoNamespace(t *testing.T) {
+	// namespaces filtered out by workspaces match no events
+	filters := eventFilters(EventQueryParameters{NamespaceFilled: true, WorkloadFilled: true})
+
+	expected := `[{"terms":{"namespace.keyword":[]}},{"terms":{"workload.keyword":[]}},{"range":{"time":{}}}]`
+	if data, _ := json.Marshal(filters); string(data) != expected {
+		t.Errorf("expected filters %s, got %s", expected, data)
+	}
+}
@@ -0,0 +1,360 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package esclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+const (
+	// scroll contexts are kept alive between pages of export
+	scrollKeepAlive = "1m"
+	exportPageSize  = 1000
+	followPageSize  = 1000
+	// index.max_result_window of elasticsearch by default
+	maxResultWindow = 10000
+)
+
+var (
+	// interval of polling new records in follow mode
+	followInterval = 2 * time.Second
+	// records are shipped to elasticsearch with delay, polls overlap by the window to pick up late records
+	followWindow = int64(10 * time.Second / time.Millisecond)
+)
+
+// RecordHandler is called for each record of export and follow, an error returned stops the stream
+type RecordHandler func(record LogRecord) error
+
+// Export streams all records matching the parameters from the configured backend.
+// Records are sorted by time ascending unless sort is desc.
+func Export(param QueryParameters, handler RecordHandler) error {
+	backend, err := getBackend()
+	if err != nil {
+		return err
+	}
+
+	return backend.Export(param, handler)
+}
+
+func sortOrder(param QueryParameters, defaultOrder string) string {
+	switch strings.ToLower(param.Sort) {
+	case "asc":
+		return "asc"
+	case "desc":
+		return "desc"
+	}
+	return defaultOrder
+}
+
+// Export uses the scroll api, so the time range is not limited by the result window of elasticsearch
+func (c *elasticsearch) Export(param QueryParameters, handler RecordHandler) error {
+	request := Request{Size: exportPageSize, Sorts: []Sort{{Order{sortOrder(param, "asc")}}}, MainQuery: BoolQuery{createBoolQuery(param)}}
+
+	url, err := searchURL(param, "?scroll="+scrollKeepAlive)
+	if err != nil {
+		return err
+	}
+
+	response, err := doRequest(http.MethodPost, url, request)
+	if err != nil {
+		return err
+	}
+
+	scrollID := response.ScrollID
+	defer func() {
+		clearScroll(scrollID)
+	}()
+
+	for len(response.Hits.Hits) > 0 {
+		for _, hit := range response.Hits.Hits {
+			if err := handler(newLogRecord(hit)); err != nil {
+				return err
+			}
+		}
+
+		url, err := esURL("_search/scroll")
+		if err != nil {
+			return err
+		}
+
+		response, err = doRequest(http.MethodPost, url, map[string]string{"scroll": scrollKeepAlive, "scroll_id": scrollID})
+		if err != nil {
+			return err
+		}
+
+		if response.ScrollID != "" {
+			scrollID = response.ScrollID
+		}
+	}
+
+	return nil
+}
+
+func clearScroll(scrollID string) {
+	if scrollID == "" {
+		return
+	}
+
+	url, err := esURL("_search/scroll")
+	if err != nil {
+		return
+	}
+
+	if _, err := doRequest(http.MethodDelete, url, map[string][]string{"scroll_id": {scrollID}}); err != nil {
+		glog.Warningln("clear scroll", err)
+	}
+}
+
+// Follow sends at most tail latest records first, up to 1000, then polls records newer than the last one sent until stop is closed.
+// Parameters are resolved before every poll, so pods created after following started are included.
+func Follow(params func() QueryParameters, tail int64, stop <-chan struct{}, handler RecordHandler) error {
+	backend, err := getBackend()
+	if err != nil {
+		return err
+	}
+
+	cursor := &followCursor{time: time.Now().UnixNano() / int64(time.Millisecond), sentIDs: make(map[string]int64)}
+
+	if tail > followPageSize {
+		tail = followPageSize
+	}
+
+	if tail > 0 {
+		param := params()
+		param.From, param.Size, param.Sort = 0, tail, "desc"
+
+		records, err := backend.Search(param)
+		if err != nil {
+			return err
+		}
+
+		for i := len(records) - 1; i >= 0; i-- {
+			if err := handler(records[i]); err != nil {
+				return err
+			}
+			cursor.advance(records[i])
+		}
+	}
+
+	ticker := time.NewTicker(followInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+		}
+
+		param := params()
+		param.StartTime = strconv.FormatInt(cursor.time-followWindow, 10)
+		param.EndTime = ""
+		param.Size, param.Sort = followPageSize, "asc"
+
+		// pages of a poll are fetched by offset within the result window, records shifted between pages are
+		// picked up by the next poll
+		for param.From = 0; param.From+followPageSize <= maxResultWindow; param.From += followPageSize {
+			records, err := backend.Search(param)
+			if err != nil {
+				glog.Warningln("follow logs", err)
+				break
+			}
+
+			for _, record := range records {
+				if cursor.sent(record) {
+					continue
+				}
+				if err := handler(record); err != nil {
+					return err
+				}
+				cursor.advance(record)
+			}
+
+			if len(records) < followPageSize {
+				break
+			}
+		}
+
+		cursor.prune()
+	}
+}
+
+type followCursor struct {
+	// timestamp in milliseconds of the latest record sent
+	time int64
+	// ids of records sent in the window to timestamps
+	sentIDs map[string]int64
+}
+
+func (c *followCursor) sent(record LogRecord) bool {
+	if record.Time < c.time-followWindow {
+		return true
+	}
+	_, ok := c.sentIDs[record.ID]
+	return ok
+}
+
+func (c *followCursor) advance(record LogRecord) {
+	if record.Time > c.time {
+		c.time = record.Time
+	}
+	c.sentIDs[record.ID] = record.Time
+}
+
+// prune forgets records out of the window
+func (c *followCursor) prune() {
+	for id, t := range c.sentIDs {
+		if t < c.time-followWindow {
+			delete(c.sentIDs, id)
+		}
+	}
+}
+
+func (c *elasticsearch) Search(param QueryParameters) ([]LogRecord, error) {
+	url, err := searchURL(param, "")
+	if err != nil {
+		return nil, err
+	}
+
+	request := Request{From: param.From, Size: param.Size, Sorts: []Sort{{Order{sortOrder(param, "desc")}}}, MainQuery: BoolQuery{createBoolQuery(param)}}
+
+	response, err := doRequest(http.MethodPost, url, request)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]LogRecord, 0, len(response.Hits.Hits))
+	for _, hit := range response.Hits.Hits {
+		records = append(records, newLogRecord(hit))
+	}
+
+	return records, nil
+}
+
+func esURL(path string) (string, error) {
+	es := readESConfigs()
+	if es == nil {
+		return "", fmt.Errorf("Elasticsearch configurations not found. Please check if they are properly configured.")
+	}
+	return fmt.Sprintf("http://%s:%s/%s", es.Host, es.Port, path), nil
+}
+
+func searchURL(param QueryParameters, query string) (string, error) {
+	es := readESConfigs()
+	if es == nil {
+		return "", fmt.Errorf("Elasticsearch configurations not found. Please check if they are properly configured.")
+	}
+	return esURL(fmt.Sprintf("%s/_search%s", indexPattern(es, param), query))
+}
+
+// indexPattern returns the shared index and dedicated indices of workspaces to search,
+// dedicated indices are written by fluent bit in logstash format, prefix-YYYY.MM.DD
+func indexPattern(es *ESConfigs, param QueryParameters) string {
+	patterns := make([]string, 0, len(param.DedicatedIndices)+1)
+	if searchSharedIndex(param) {
+		patterns = append(patterns, es.Index+"*")
+	}
+	for _, index := range param.DedicatedIndices {
+		patterns = append(patterns, index.Prefix+"-*")
+	}
+	return strings.Join(patterns, ",")
+}
+
+// searchSharedIndex reports whether logs queried may be in the shared index, that is namespaces without dedicated
+// indices are queried, or the time range starts before logs of a workspace were written to its dedicated indices.
+// Logs of the shared index are filtered by namespaces of the query like other indices.
+func searchSharedIndex(param QueryParameters) bool {
+	if !param.NamespaceFilled || len(param.DedicatedIndices) == 0 {
+		return true
+	}
+
+	start := calcTimestamp(param.StartTime)
+	dedicated := make(map[string]bool)
+
+	for _, index := range param.DedicatedIndices {
+		if start < index.Since {
+			return true
+		}
+		for _, namespace := range index.Namespaces {
+			dedicated[namespace] = true
+		}
+	}
+
+	for namespace := range param.NamespaceWithCreationTime {
+		if !dedicated[namespace] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func doRequest(method, url string, body interface{}) (*Response, error) {
+	var result Response
+	if err := doRawRequest(method, url, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// doRawRequest sends the body in json if it's not nil, and decodes the response into result
+func doRawRequest(method, url string, body interface{}, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	request, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json; charset=utf-8")
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	data, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("elasticsearch request failed with status %d: %s", response.StatusCode, string(data))
+	}
+
+	return json.Unmarshal(data, result)
+}
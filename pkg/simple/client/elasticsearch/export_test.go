@@ -0,0 +1,265 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package esclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeES replays responses recorded in testdata, or generated responses starting with {
+type fakeES struct {
+	sync.Mutex
+	t        *testing.T
+	requests []string
+	bodies   []map[string]interface{}
+	payloads []string
+	// responses by method and path, consumed in order
+	responses map[string][]string
+}
+
+func (f *fakeES) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+
+	key := r.Method + " " + r.URL.RequestURI()
+	f.requests = append(f.requests, key)
+
+	data, _ := ioutil.ReadAll(r.Body)
+	body := make(map[string]interface{})
+	json.Unmarshal(data, &body)
+	f.bodies = append(f.bodies, body)
+	f.payloads = append(f.payloads, string(data))
+
+	responses := f.responses[key]
+	if len(responses) == 0 {
+		w.Write([]byte(`{"hits":{"total":0,"hits":[]}}`))
+		return
+	}
+
+	f.responses[key] = responses[1:]
+
+	if strings.HasPrefix(responses[0], "{") {
+		w.Write([]byte(responses[0]))
+		return
+	}
+
+	fixture, err := ioutil.ReadFile(filepath.Join("testdata", responses[0]))
+	if err != nil {
+		f.t.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(fixture)
+}
+
+func startFakeES(t *testing.T, responses map[string][]string) (*fakeES, func()) {
+	fake := &fakeES{t: t, responses: responses}
+	server := httptest.NewServer(fake)
+
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	(&ESConfigs{Host: host, Port: port, Index: "logstash"}).WriteESConfigs()
+
+	return fake, func() {
+		server.Close()
+		(&ESConfigs{}).WriteESConfigs()
+	}
+}
+
+// hits generates a search response of logs relative to now, each log is id:timestamp
+func hits(logs ...string) string {
+	items := make([]map[string]interface{}, 0)
+	for _, log := range logs {
+		var id string
+		var t int64
+		fmt.Sscanf(log, "%1s:%d", &id, &t)
+		items = append(items, map[string]interface{}{
+			"_id":     id,
+			"_source": map[string]interface{}{"log": "log " + id, "time": time.Unix(0, t*int64(time.Millisecond)).UTC().Format(time.RFC3339Nano), "kubernetes": map[string]string{"pod_name": "web"}},
+			"sort":    []int64{t},
+		})
+	}
+	data, _ := json.Marshal(map[string]interface{}{"hits": map[string]interface{}{"total": len(items), "hits": items}})
+	return string(data)
+}
+
+func logsOf(records []LogRecord) []string {
+	logs := make([]string, 0)
+	for _, record := range records {
+		logs = append(logs, record.Log)
+	}
+	return logs
+}
+
+func TestExport(t *testing.T) {
+	fake, stop := startFakeES(t, map[string][]string{
+		"POST /logstash*/_search?scroll=1m": {"scroll-1.json"},
+		"POST /_search/scroll":              {"scroll-2.json", "scroll-end.json"},
+		"DELETE /_search/scroll":            {"clear-scroll.json"},
+	})
+	defer stop()
+
+	records := make([]LogRecord, 0)
+	err := Export(QueryParameters{PodFilled: true, Pods: []string{"web"}, StartTime: "0", EndTime: "10"}, func(record LogRecord) error {
+		records = append(records, record)
+		return nil
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if logs := logsOf(records); !reflect.DeepEqual(logs, []string{"log a", "log b", "log c"}) {
+		t.Errorf("expected logs a, b and c, got %v", logs)
+	}
+
+	expected := []string{"POST /logstash*/_search?scroll=1m", "POST /_search/scroll", "POST /_search/scroll", "DELETE /_search/scroll"}
+	if !reflect.DeepEqual(fake.requests, expected) {
+		t.Errorf("expected requests %v, got %v", expected, fake.requests)
+	}
+
+	if size := fake.bodies[0]["size"]; size != float64(exportPageSize) {
+		t.Errorf("expected page size %d, got %v", exportPageSize, size)
+	}
+
+	if scrollID := fake.bodies[2]["scroll_id"]; scrollID != "DnF1ZXJ5VGhlbkZldGNoBQAAAAAAAAB9FmJ3" {
+		t.Errorf("expected scroll id of the second page, got %v", scrollID)
+	}
+
+	if cleared := fake.bodies[3]["scroll_id"]; !reflect.DeepEqual(cleared, []interface{}{"DnF1ZXJ5VGhlbkZldGNoBQAAAAAAAAB9FmJ3"}) {
+		t.Errorf("expected scroll of the second page cleared, got %v", cleared)
+	}
+}
+
+func TestExportHandlerError(t *testing.T) {
+	fake, stop := startFakeES(t, map[string][]string{
+		"POST /logstash*/_search?scroll=1m": {"scroll-1.json"},
+		"POST /_search/scroll":              {"scroll-2.json"},
+	})
+	defer stop()
+
+	count := 0
+	err := Export(QueryParameters{}, func(record LogRecord) error {
+		count++
+		return fmt.Errorf("client gone")
+	})
+
+	if err == nil || count != 1 {
+		t.Errorf("expected export stopped at the first record, got %v after %d records", err, count)
+	}
+
+	if last := fake.requests[len(fake.requests)-1]; last != "DELETE /_search/scroll" {
+		t.Errorf("expected scroll cleared, got %s", last)
+	}
+}
+
+func TestExportFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	(&ESConfigs{Host: host, Port: port, Index: "logstash"}).WriteESConfigs()
+	defer (&ESConfigs{}).WriteESConfigs()
+
+	if err := Export(QueryParameters{}, func(LogRecord) error { return nil }); err == nil {
+		t.Error("expected error of unavailable elasticsearch")
+	}
+}
+
+func TestFollow(t *testing.T) {
+	interval, window := followInterval, followWindow
+	followInterval, followWindow = 10*time.Millisecond, 5
+	defer func() {
+		followInterval, followWindow = interval, window
+	}()
+
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	at := func(id string, offset int64) string {
+		return fmt.Sprintf("%s:%d", id, now+offset)
+	}
+
+	fake, stop := startFakeES(t, map[string][]string{
+		"POST /logstash*/_search": {
+			// latest records, newest first
+			hits(at("b", 2), at("a", 1)),
+			// b was sent, d arrived late but is in the window
+			hits(at("b", 2), at("d", 1), at("c", 3)),
+			hits(at("c", 3), at("e", 10)),
+		},
+	})
+	defer stop()
+
+	resolved := 0
+	params := func() QueryParameters {
+		resolved++
+		return QueryParameters{PodFilled: true, Pods: []string{"web"}, EndTime: "1"}
+	}
+
+	stopCh := make(chan struct{})
+	records := make([]LogRecord, 0)
+
+	err := Follow(params, 2, stopCh, func(record LogRecord) error {
+		records = append(records, record)
+		if len(records) == 5 {
+			close(stopCh)
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if logs := logsOf(records); !reflect.DeepEqual(logs, []string{"log a", "log b", "log d", "log c", "log e"}) {
+		t.Errorf("expected logs a, b, d, c and e, got %v", logs)
+	}
+
+	if resolved < 3 {
+		t.Errorf("expected parameters resolved before every poll, resolved %d times", resolved)
+	}
+
+	fake.Lock()
+	defer fake.Unlock()
+
+	if size := fake.bodies[0]["size"]; size != float64(2) {
+		t.Errorf("expected tail of 2 records, got %v", size)
+	}
+
+	// the first poll starts from the window before the latest record sent
+	query, _ := json.Marshal(fake.bodies[1]["query"])
+	if expected := fmt.Sprintf(`{"gte":"%d"}`, now+2-5); !strings.Contains(string(query), expected) {
+		t.Errorf("expected time range %s without end time, got %s", expected, query)
+	}
+}
@@ -0,0 +1,155 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package esclient
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	// the number of values returned by the aggregation operation by default
+	defaultAggregationSize = 10
+	// the aggregation over all values of a high cardinality field, eg. trace_id, is expensive
+	maxAggregationSize = 100
+)
+
+// FieldFilter matches records with the structured field, fields are decoded from JSON logs
+// by the kubernetes filter of fluent bit with Merge_Log on, and kept at the top level of records.
+// Records match filters of the same field if they match any of them.
+type FieldFilter struct {
+	Field string
+	Value string
+}
+
+// GroupFieldFilters groups filters by field in the order of the first filter of each field
+func GroupFieldFilters(filters []FieldFilter) [][]FieldFilter {
+	var groups [][]FieldFilter
+	index := make(map[string]int)
+
+	for _, filter := range filters {
+		i, ok := index[filter.Field]
+		if !ok {
+			i = len(groups)
+			index[filter.Field] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], filter)
+	}
+
+	return groups
+}
+
+// AggregationResult holds the most frequent values of the field
+type AggregationResult struct {
+	Field   string              `json:"field" description:"the field aggregated by"`
+	Buckets []AggregationBucket `json:"buckets" description:"values of the field, sorted by the number of logs descending"`
+	Others  int64               `json:"others" description:"total number of logs of the values not returned"`
+}
+
+type AggregationBucket struct {
+	Value string `json:"value" description:"value of the field"`
+	Count int64  `json:"count" description:"total number of logs with the value"`
+}
+
+type TermsAggs struct {
+	TermsAgg TermsAgg `json:"fields"`
+}
+
+type TermsAgg struct {
+	Terms Terms `json:"terms"`
+}
+
+type Terms struct {
+	Field string `json:"field"`
+	Size  int64  `json:"size"`
+}
+
+type TermsAggregations struct {
+	TermsAggregation TermsAggregation `json:"fields"`
+}
+
+type TermsAggregation struct {
+	SumOtherDocCount int64               `json:"sum_other_doc_count"`
+	Buckets          []TermsBucketResult `json:"buckets"`
+}
+
+type TermsBucketResult struct {
+	Key      interface{} `json:"key"`
+	DocCount int64       `json:"doc_count"`
+}
+
+// AggregationSize returns the number of values of the aggregation operation
+func AggregationSize(param QueryParameters) int64 {
+	switch {
+	case param.Size <= 0:
+		return defaultAggregationSize
+	case param.Size > maxAggregationSize:
+		return maxAggregationSize
+	default:
+		return param.Size
+	}
+}
+
+// newTermsAggs aggregates by the keyword sub-field, which is created for string fields by dynamic mapping
+func newTermsAggs(param QueryParameters) TermsAggs {
+	return TermsAggs{TermsAgg{Terms{Field: param.AggregationField + ".keyword", Size: AggregationSize(param)}}}
+}
+
+func newAggregationResult(field string, aggregation TermsAggregation) *AggregationResult {
+	result := &AggregationResult{Field: field, Buckets: make([]AggregationBucket, 0, len(aggregation.Buckets)), Others: aggregation.SumOtherDocCount}
+	for _, bucket := range aggregation.Buckets {
+		result.Buckets = append(result.Buckets, AggregationBucket{Value: fmt.Sprint(bucket.Key), Count: bucket.DocCount})
+	}
+	return result
+}
+
+// isMetadataField tells keys of records written by fluent bit, the other keys are structured fields of the log
+func isMetadataField(key string) bool {
+	switch key {
+	case "log", "time", "kubernetes", "stream", "@timestamp":
+		return true
+	}
+	return false
+}
+
+// UnmarshalJSON decodes the record, keys other than the metadata are kept in Fields
+func (s *Source) UnmarshalJSON(data []byte) error {
+	type source Source
+	if err := json.Unmarshal(data, (*source)(s)); err != nil {
+		return err
+	}
+
+	fields := make(map[string]interface{})
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	for key := range fields {
+		if isMetadataField(key) {
+			delete(fields, key)
+		}
+	}
+
+	s.Fields = nil
+	if len(fields) > 0 {
+		s.Fields = fields
+	}
+
+	return nil
+}
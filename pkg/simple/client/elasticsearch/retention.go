@@ -0,0 +1,109 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package esclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// SharedIndex returns the prefix of indices shared by all workspaces, empty if elasticsearch is not configured
+func SharedIndex() string {
+	es := readESConfigs()
+	if es == nil {
+		return ""
+	}
+	return es.Index
+}
+
+// Indices returns names of indices matching the pattern
+func Indices(pattern string) ([]string, error) {
+	u, err := esURL(fmt.Sprintf("_cat/indices/%s?format=json&h=index", url.PathEscape(pattern)))
+	if err != nil {
+		return nil, err
+	}
+
+	var indices []struct {
+		Index string `json:"index"`
+	}
+	if err := doRawRequest(http.MethodGet, u, nil, &indices); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(indices))
+	for _, index := range indices {
+		names = append(names, index.Index)
+	}
+
+	return names, nil
+}
+
+// DeleteIndex deletes the index by name, wildcards are not expanded
+func DeleteIndex(name string) error {
+	if name == "" || strings.ContainsAny(name, "*,") {
+		return fmt.Errorf("invalid index name %q", name)
+	}
+
+	u, err := esURL(url.PathEscape(name))
+	if err != nil {
+		return err
+	}
+
+	var result map[string]interface{}
+	return doRawRequest(http.MethodDelete, u, nil, &result)
+}
+
+// DeleteNamespaceLogs deletes logs of the namespaces before the time from the shared index, returns the number of logs deleted
+func DeleteNamespaceLogs(namespaces []string, before time.Time) (int64, error) {
+	es := readESConfigs()
+	if es == nil {
+		return 0, fmt.Errorf("Elasticsearch configurations not found. Please check if they are properly configured.")
+	}
+
+	if len(namespaces) == 0 {
+		return 0, nil
+	}
+
+	u, err := esURL(fmt.Sprintf("%s*/_delete_by_query?conflicts=proceed", es.Index))
+	if err != nil {
+		return 0, err
+	}
+
+	query := map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": []interface{}{
+					map[string]interface{}{"terms": map[string]interface{}{"kubernetes.namespace_name.keyword": namespaces}},
+					map[string]interface{}{"range": map[string]interface{}{"time": map[string]interface{}{"lt": before.UnixNano() / int64(time.Millisecond), "format": "epoch_millis"}}},
+				},
+			},
+		},
+	}
+
+	var result struct {
+		Deleted int64 `json:"deleted"`
+	}
+	if err := doRawRequest(http.MethodPost, u, query, &result); err != nil {
+		return 0, err
+	}
+
+	return result.Deleted, nil
+}
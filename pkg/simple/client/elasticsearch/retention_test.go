@@ -0,0 +1,109 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package esclient
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestIndexPattern(t *testing.T) {
+	es := &ESConfigs{Index: "logstash"}
+
+	namespaces := map[string]string{"billing": "0"}
+	dedicated := []DedicatedIndex{{Prefix: "ws-a", Since: 1571350000000, Namespaces: []string{"billing"}}}
+
+	tests := []struct {
+		param    QueryParameters
+		expected string
+	}{
+		{QueryParameters{}, "logstash*"},
+		// namespaces are not filtered
+		{QueryParameters{DedicatedIndices: []DedicatedIndex{{Prefix: "ws-a"}, {Prefix: "ws-b"}}}, "logstash*,ws-a-*,ws-b-*"},
+		// all namespaces have dedicated indices since before the start time
+		{QueryParameters{NamespaceFilled: true, NamespaceWithCreationTime: namespaces, StartTime: "1571400000000", DedicatedIndices: dedicated}, "ws-a-*"},
+		// logs before the dedicated index are in the shared index
+		{QueryParameters{NamespaceFilled: true, NamespaceWithCreationTime: namespaces, StartTime: "1571300000000", DedicatedIndices: dedicated}, "logstash*,ws-a-*"},
+		{QueryParameters{NamespaceFilled: true, NamespaceWithCreationTime: namespaces, DedicatedIndices: dedicated}, "logstash*,ws-a-*"},
+		// namespaces without dedicated indices
+		{QueryParameters{NamespaceFilled: true, NamespaceWithCreationTime: map[string]string{"billing": "0", "demo": "0"}, StartTime: "1571400000000", DedicatedIndices: dedicated}, "logstash*,ws-a-*"},
+	}
+
+	for _, test := range tests {
+		if pattern := indexPattern(es, test.param); pattern != test.expected {
+			t.Errorf("%+v: expected %s, got %s", test.param, test.expected, pattern)
+		}
+	}
+}
+
+func TestRetention(t *testing.T) {
+	fake, stop := startFakeES(t, map[string][]string{
+		"GET /_cat/indices/ws-finance-%2A?format=json&h=index": {"cat-indices.json"},
+		"DELETE /ws-finance-2019.10.17":                        {`{"acknowledged":true}`},
+		"POST /logstash*/_delete_by_query?conflicts=proceed":   {"delete-by-query.json"},
+		"POST /logstash*,ws-finance-*/_search":                 {`{"hits":{"total":0,"hits":[]}}`},
+	})
+	defer stop()
+
+	indices, err := Indices("ws-finance-*")
+	if err != nil || !reflect.DeepEqual(indices, []string{"ws-finance-2019.10.17", "ws-finance-2019.10.18"}) {
+		t.Errorf("unexpected indices %v %v", indices, err)
+	}
+
+	if err := DeleteIndex("ws-finance-2019.10.17"); err != nil {
+		t.Error(err)
+	}
+
+	if err := DeleteIndex("ws-finance-*"); err == nil {
+		t.Error("expected wildcards rejected")
+	}
+
+	before := time.Date(2019, 10, 12, 0, 0, 0, 0, time.UTC)
+	deleted, err := DeleteNamespaceLogs([]string{"billing", "payroll"}, before)
+	if err != nil || deleted != 1203 {
+		t.Errorf("expected 1203 logs deleted, got %d %v", deleted, err)
+	}
+
+	expected := map[string]interface{}{
+		"bool": map[string]interface{}{
+			"filter": []interface{}{
+				map[string]interface{}{"terms": map[string]interface{}{"kubernetes.namespace_name.keyword": []interface{}{"billing", "payroll"}}},
+				map[string]interface{}{"range": map[string]interface{}{"time": map[string]interface{}{"lt": float64(1570838400000), "format": "epoch_millis"}}},
+			},
+		},
+	}
+	if query := fake.bodies[2]["query"]; !reflect.DeepEqual(query, expected) {
+		t.Errorf("expected query %v, got %v", expected, query)
+	}
+
+	// queries of workspaces with dedicated indices search the shared index for logs before them
+	if _, err := (&elasticsearch{}).Search(QueryParameters{DedicatedIndices: []DedicatedIndex{{Prefix: "ws-finance", Since: 1571350000000}}, Size: 10}); err != nil {
+		t.Error(err)
+	}
+
+	requests := []string{
+		"GET /_cat/indices/ws-finance-%2A?format=json&h=index",
+		"DELETE /ws-finance-2019.10.17",
+		"POST /logstash*/_delete_by_query?conflicts=proceed",
+		"POST /logstash*,ws-finance-*/_search",
+	}
+	if !reflect.DeepEqual(fake.requests, requests) {
+		t.Errorf("expected requests %v, got %v", requests, fake.requests)
+	}
+}
@@ -55,6 +55,8 @@ type FluentBitSpec struct {
 	Filter   []Plugin `json:"filter"`
 	Output   []Plugin `json:"output"`
 	Settings []Plugin `json:"settings"`
+	// Parser is rendered to the parsers file, parsers are referred to by name from inputs and filters
+	Parser []Plugin `json:"parser,omitempty"`
 }
 
 // FluentBitStatus holds the status info for the operator
@@ -0,0 +1,108 @@
+/*
+Copyright 2018 The KubeSphere Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package fluentbitclient
+
+import (
+	"encoding/json"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	esclient "kubesphere.io/kubesphere/pkg/simple/client/elasticsearch"
+)
+
+const (
+	// outputs of fluent bit are kept in the configmap by ks-apiserver
+	OutputsConfigMapName = "fluent-bit-output-config"
+	OutputsConfigMapData = "outputs"
+	LoggingNamespace     = "kubesphere-logging-system"
+)
+
+// ParseOutputs parses outputs kept in data of the configmap
+func ParseOutputs(data string) ([]OutputPlugin, error) {
+	var outputs []OutputPlugin
+	if err := json.Unmarshal([]byte(data), &outputs); err != nil {
+		return nil, err
+	}
+	return outputs, nil
+}
+
+// Parse es host, port and index
+func ParseEsOutputParams(params []Parameter) *esclient.ESConfigs {
+
+	var (
+		isEsFound bool
+
+		host           = "127.0.0.1"
+		port           = "9200"
+		index          = "logstash"
+		logstashFormat string
+		logstashPrefix string
+	)
+
+	for _, param := range params {
+		switch param.Name {
+		case "Name":
+			if param.Value == "es" {
+				isEsFound = true
+			}
+		case "Host":
+			host = param.Value
+		case "Port":
+			port = param.Value
+		case "Index":
+			index = param.Value
+		case "Logstash_Format":
+			logstashFormat = strings.ToLower(param.Value)
+		case "Logstash_Prefix":
+			logstashPrefix = param.Value
+		}
+	}
+
+	if !isEsFound {
+		return nil
+	}
+
+	// If Logstash_Format is On/True, ignore Index
+	if logstashFormat == "on" || logstashFormat == "true" {
+		if logstashPrefix != "" {
+			index = logstashPrefix
+		} else {
+			index = "logstash"
+		}
+	}
+
+	return &esclient.ESConfigs{Host: host, Port: port, Index: index}
+}
+
+// ReadESConfigs returns configs of the first elasticsearch output kept in the configmap, nil if there is none
+func ReadESConfigs(client kubernetes.Interface) (*esclient.ESConfigs, error) {
+	configMap, err := client.CoreV1().ConfigMaps(LoggingNamespace).Get(OutputsConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	outputs, err := ParseOutputs(configMap.Data[OutputsConfigMapData])
+	if err != nil {
+		return nil, err
+	}
+
+	for _, output := range outputs {
+		if configs := ParseEsOutputParams(output.Parameters); configs != nil {
+			return configs, nil
+		}
+	}
+
+	return nil, nil
+}
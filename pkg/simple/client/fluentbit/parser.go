@@ -0,0 +1,68 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package fluentbitclient
+
+import (
+	"bytes"
+	"fmt"
+)
+
+const (
+	// parsers of the fluent bit CRD are rendered to the configmap, which is mounted by fluent bit
+	// and loaded through the Parsers_File of the service section
+	ParsersConfigMapName = "fluent-bit-parsers-config"
+	ParsersConfigMapData = "parsers_custom.conf"
+	ParsersFile          = "/fluent-bit/parsers/" + ParsersConfigMapData
+
+	PluginTypeMultilineParser = "fluentbit_multiline_parser"
+)
+
+// RenderParsers renders parsers to the parsers file of fluent bit, multiline parsers are rendered
+// to MULTILINE_PARSER sections, the others to PARSER sections
+func RenderParsers(parsers []Plugin) string {
+	var buf bytes.Buffer
+	for _, parser := range parsers {
+		section := "PARSER"
+		if parser.Type == PluginTypeMultilineParser {
+			section = "MULTILINE_PARSER"
+		}
+
+		if buf.Len() > 0 {
+			buf.WriteString("\n")
+		}
+		fmt.Fprintf(&buf, "[%s]\n", section)
+		for _, parameter := range parser.Parameters {
+			fmt.Fprintf(&buf, "    %s %s\n", parameter.Name, parameter.Value)
+		}
+	}
+	return buf.String()
+}
+
+// SetParsersFile makes the service section load the rendered parsers file besides the default one
+func SetParsersFile(spec *FluentBitSpec) {
+	if len(spec.Service) == 0 {
+		return
+	}
+
+	for _, parameter := range spec.Service[0].Parameters {
+		if parameter.Name == "Parsers_File" && parameter.Value == ParsersFile {
+			return
+		}
+	}
+	spec.Service[0].Parameters = append(spec.Service[0].Parameters, Parameter{Name: "Parsers_File", Value: ParsersFile})
+}
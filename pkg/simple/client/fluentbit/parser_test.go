@@ -0,0 +1,65 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package fluentbitclient
+
+import (
+	"testing"
+)
+
+func TestRenderParsers(t *testing.T) {
+	parsers := []Plugin{
+		{Type: "fluentbit_parser", Name: "fluentbit-parser-custom-access", Parameters: []Parameter{
+			{Name: "Name", Value: "access"},
+			{Name: "Format", Value: "regex"},
+			{Name: "Regex", Value: `^(?<code>\d+)$`},
+		}},
+		{Type: PluginTypeMultilineParser, Name: "fluentbit-parser-custom-java", Parameters: []Parameter{
+			{Name: "Name", Value: "java"},
+			{Name: "Type", Value: "regex"},
+			{Name: "Rule", Value: `"start_state" "/^\d{4}/" "cont"`},
+			{Name: "Rule", Value: `"cont" "/^\s+at /" "cont"`},
+		}},
+	}
+
+	expected := `[PARSER]
+    Name access
+    Format regex
+    Regex ^(?<code>\d+)$
+
+[MULTILINE_PARSER]
+    Name java
+    Type regex
+    Rule "start_state" "/^\d{4}/" "cont"
+    Rule "cont" "/^\s+at /" "cont"
+`
+	if rendered := RenderParsers(parsers); rendered != expected {
+		t.Errorf("expected parsers\n%s\ngot\n%s", expected, rendered)
+	}
+}
+
+func TestSetParsersFile(t *testing.T) {
+	spec := &FluentBitSpec{Service: []Plugin{{Type: "fluentbit_service", Name: "fluentbit-service", Parameters: []Parameter{{Name: "Parsers_File", Value: "parsers.conf"}}}}}
+
+	SetParsersFile(spec)
+	SetParsersFile(spec)
+
+	parameters := spec.Service[0].Parameters
+	if len(parameters) != 2 || parameters[1].Value != ParsersFile {
+		t.Errorf("expected the parsers file to be loaded once besides the default one, got %v", parameters)
+	}
+}
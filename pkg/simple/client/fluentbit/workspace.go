@@ -0,0 +1,139 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package fluentbitclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// log settings of workspaces are kept in the configmap by ks-apiserver
+	WorkspaceConfigMapName = "fluent-bit-workspace-config"
+	WorkspaceConfigMapData = "workspaces"
+
+	WorkspaceFilterPrefix = "fluentbit-filter-workspace-"
+	workspaceOutputPrefix = "fluentbit-output-workspace-"
+	// logs of workspaces with dedicated indices are retagged from kube.* to workspace.<workspace>.kube.*
+	workspaceTagPrefix = "workspace."
+)
+
+type WorkspaceLogSettings struct {
+	Workspace     string    `json:"workspace" description:"workspace name"`
+	RetentionDays int       `json:"retention_days,omitempty" description:"days to keep logs of the workspace, logs are kept forever if it's 0"`
+	IndexPrefix   string    `json:"index_prefix,omitempty" description:"prefix of dedicated elasticsearch indices of the workspace, logs are written to the shared indices if it's empty"`
+	IndexTime     time.Time `json:"index_time,omitempty" description:"time the dedicated indices were configured, logs before it are kept in the shared indices"`
+	Updatetime    time.Time `json:"updatetime,omitempty" description:"last updatetime"`
+}
+
+// ParseWorkspaceLogSettings parses settings kept in data of the configmap
+func ParseWorkspaceLogSettings(data string) ([]WorkspaceLogSettings, error) {
+	var settings []WorkspaceLogSettings
+	if data == "" {
+		return settings, nil
+	}
+	if err := json.Unmarshal([]byte(data), &settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// ApplyWorkspaceLogSettings routes logs of workspaces with dedicated indices to their own elasticsearch outputs.
+// A rewrite_tag filter retags logs of the namespaces of each workspace, and a copy of each elasticsearch output
+// matching the new tag writes them to indices with the prefix. Other outputs should match kube.* instead of *
+// to not receive retagged logs.
+func ApplyWorkspaceLogSettings(spec *FluentBitSpec, settings []WorkspaceLogSettings, namespaces map[string][]string) {
+	filters := make([]Plugin, 0, len(spec.Filter))
+	for _, filter := range spec.Filter {
+		if !strings.HasPrefix(filter.Name, WorkspaceFilterPrefix) {
+			filters = append(filters, filter)
+		}
+	}
+
+	outputs := make([]Plugin, 0, len(spec.Output))
+	var esOutputs []Plugin
+	for _, output := range spec.Output {
+		if strings.HasPrefix(output.Name, workspaceOutputPrefix) {
+			continue
+		}
+		outputs = append(outputs, output)
+		if parameterValue(output.Parameters, "Name") == "es" {
+			esOutputs = append(esOutputs, output)
+		}
+	}
+
+	for _, item := range settings {
+		// workspaces without namespaces have no logs to route
+		if item.IndexPrefix == "" || len(namespaces[item.Workspace]) == 0 {
+			continue
+		}
+
+		tag := workspaceTagPrefix + item.Workspace + "."
+
+		filters = append(filters, Plugin{
+			Type: "fluentbit_filter",
+			Name: WorkspaceFilterPrefix + item.Workspace,
+			Parameters: []Parameter{
+				{Name: "Name", Value: "rewrite_tag"},
+				{Name: "Match", Value: "kube.*"},
+				{Name: "Rule", Value: fmt.Sprintf("$kubernetes['namespace_name'] ^(%s)$ %s$TAG false", strings.Join(namespaces[item.Workspace], "|"), tag)},
+				{Name: "Emitter_Name", Value: "re_emitted_" + item.Workspace},
+			},
+		})
+
+		for i, output := range esOutputs {
+			name := workspaceOutputPrefix + item.Workspace
+			if i > 0 {
+				name = fmt.Sprintf("%s-%d", name, i)
+			}
+			outputs = append(outputs, Plugin{Type: output.Type, Name: name, Parameters: dedicatedIndexParameters(output.Parameters, tag+"*", item.IndexPrefix)})
+		}
+	}
+
+	spec.Filter = filters
+	spec.Output = outputs
+}
+
+func dedicatedIndexParameters(parameters []Parameter, match, prefix string) []Parameter {
+	result := make([]Parameter, 0, len(parameters)+2)
+	for _, parameter := range parameters {
+		switch parameter.Name {
+		case "Match", "Index", "Logstash_Format", "Logstash_Prefix":
+		default:
+			result = append(result, parameter)
+		}
+	}
+
+	return append(result,
+		Parameter{Name: "Match", Value: match},
+		Parameter{Name: "Logstash_Format", Value: "On"},
+		Parameter{Name: "Logstash_Prefix", Value: prefix})
+}
+
+// parameterValue returns the last value of the parameter, the same as fluent bit
+func parameterValue(parameters []Parameter, name string) string {
+	value := ""
+	for _, parameter := range parameters {
+		if parameter.Name == name {
+			value = parameter.Value
+		}
+	}
+	return value
+}
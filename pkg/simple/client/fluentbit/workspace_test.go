@@ -0,0 +1,85 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package fluentbitclient
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplyWorkspaceLogSettings(t *testing.T) {
+	kubernetes := Plugin{Type: "fluentbit_filter", Name: "fluentbit-filter-kubernetes", Parameters: []Parameter{{Name: "Name", Value: "kubernetes"}}}
+	es := Plugin{Type: "fluentbit_output", Name: "fluentbit-output-es", Parameters: []Parameter{
+		{Name: "Name", Value: "es"},
+		{Name: "Match", Value: "kube.*"},
+		{Name: "Host", Value: "elasticsearch"},
+		{Name: "Logstash_Format", Value: "On"},
+		{Name: "Logstash_Prefix", Value: "logstash"},
+	}}
+	forward := Plugin{Type: "fluentbit_output", Name: "fluentbit-output-forward", Parameters: []Parameter{{Name: "Name", Value: "forward"}, {Name: "Match", Value: "kube.*"}}}
+
+	spec := FluentBitSpec{
+		Filter: []Plugin{kubernetes, {Name: WorkspaceFilterPrefix + "removed"}},
+		Output: []Plugin{es, forward, {Name: workspaceOutputPrefix + "removed"}},
+	}
+
+	settings := []WorkspaceLogSettings{
+		{Workspace: "finance", IndexPrefix: "ws-finance", RetentionDays: 7},
+		{Workspace: "shared", RetentionDays: 30},
+		{Workspace: "empty", IndexPrefix: "ws-empty"},
+	}
+	namespaces := map[string][]string{"finance": {"billing", "payroll"}, "shared": {"demo"}}
+
+	ApplyWorkspaceLogSettings(&spec, settings, namespaces)
+
+	expectedFilters := []Plugin{kubernetes, {
+		Type: "fluentbit_filter",
+		Name: "fluentbit-filter-workspace-finance",
+		Parameters: []Parameter{
+			{Name: "Name", Value: "rewrite_tag"},
+			{Name: "Match", Value: "kube.*"},
+			{Name: "Rule", Value: "$kubernetes['namespace_name'] ^(billing|payroll)$ workspace.finance.$TAG false"},
+			{Name: "Emitter_Name", Value: "re_emitted_finance"},
+		},
+	}}
+	if !reflect.DeepEqual(spec.Filter, expectedFilters) {
+		t.Errorf("expected filters %+v, got %+v", expectedFilters, spec.Filter)
+	}
+
+	expectedOutputs := []Plugin{es, forward, {
+		Type: "fluentbit_output",
+		Name: "fluentbit-output-workspace-finance",
+		Parameters: []Parameter{
+			{Name: "Name", Value: "es"},
+			{Name: "Host", Value: "elasticsearch"},
+			{Name: "Match", Value: "workspace.finance.*"},
+			{Name: "Logstash_Format", Value: "On"},
+			{Name: "Logstash_Prefix", Value: "ws-finance"},
+		},
+	}}
+	if !reflect.DeepEqual(spec.Output, expectedOutputs) {
+		t.Errorf("expected outputs %+v, got %+v", expectedOutputs, spec.Output)
+	}
+
+	// applying again doesn't change anything
+	again := spec
+	ApplyWorkspaceLogSettings(&again, settings, namespaces)
+	if !reflect.DeepEqual(again, spec) {
+		t.Errorf("expected the same spec, got %+v", again)
+	}
+}
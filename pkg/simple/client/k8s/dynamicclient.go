@@ -0,0 +1,46 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package k8s
+
+import (
+	"log"
+	"sync"
+
+	"k8s.io/client-go/dynamic"
+)
+
+var (
+	dynamicClient     dynamic.Interface
+	dynamicClientOnce sync.Once
+)
+
+func DynamicClient() dynamic.Interface {
+
+	dynamicClientOnce.Do(func() {
+
+		config, err := Config()
+
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		dynamicClient = dynamic.NewForConfigOrDie(config)
+	})
+
+	return dynamicClient
+}
@@ -24,10 +24,13 @@ import (
 	"fmt"
 	"github.com/golang/glog"
 	"io/ioutil"
+	alertingv1alpha1 "kubesphere.io/kubesphere/pkg/apis/alerting/v1alpha1"
 	"kubesphere.io/kubesphere/pkg/constants"
 	"kubesphere.io/kubesphere/pkg/models"
 	"kubesphere.io/kubesphere/pkg/models/devops"
+	esclient "kubesphere.io/kubesphere/pkg/simple/client/elasticsearch"
 	"net/http"
+	"net/url"
 	"strings"
 	"sync"
 )
@@ -47,6 +50,8 @@ type Interface interface {
 	ListUsers() (*models.PageableResponse, error)
 	ListWorkspaceDevOpsProjects(workspace string) (*devops.PageableDevOpsProject, error)
 	DeleteWorkspaceDevOpsProjects(workspace, devops string) error
+	QueryLogs(path string, params url.Values) (*esclient.QueryResult, error)
+	RenderAlertRule(namespace string, spec alertingv1alpha1.AlertRuleSpec) (string, error)
 }
 
 type client struct {
@@ -331,6 +336,92 @@ func (c client) DeleteWorkspaceDevOpsProjects(workspace, devops string) error {
 	return nil
 }
 
+// QueryLogs queries the logging api of the path, e.g. /namespaces/default
+func (c client) QueryLogs(path string, params url.Values) (*esclient.QueryResult, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/kapis/logging.kubesphere.io/v1alpha2%s?%s", ksAPIServer, path, params.Encode()), nil)
+
+	if err != nil {
+		glog.Error(err)
+		return nil, err
+	}
+	req.Header.Add(constants.UserNameHeader, constants.AdminUserName)
+
+	resp, err := c.client.Do(req)
+
+	if err != nil {
+		glog.Error(err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+
+	if err != nil {
+		glog.Error(err)
+		return nil, err
+	}
+	if resp.StatusCode > http.StatusOK {
+		glog.Error(req.Method, req.URL, resp.StatusCode, string(data))
+		return nil, Error{resp.StatusCode, string(data)}
+	}
+
+	var result esclient.QueryResult
+	err = json.Unmarshal(data, &result)
+
+	if err != nil {
+		glog.Error(err)
+		return nil, err
+	}
+	return &result, nil
+}
+
+// RenderAlertRule renders the spec of an alert rule in the namespace into a PromQL expression
+func (c client) RenderAlertRule(namespace string, spec alertingv1alpha1.AlertRuleSpec) (string, error) {
+	data, err := json.Marshal(spec)
+
+	if err != nil {
+		glog.Error(err)
+		return "", err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/kapis/monitoring.kubesphere.io/v1alpha2/namespaces/%s/alertrules/render", ksAPIServer, namespace), bytes.NewReader(data))
+
+	if err != nil {
+		glog.Error(err)
+		return "", err
+	}
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add(constants.UserNameHeader, constants.AdminUserName)
+
+	resp, err := c.client.Do(req)
+
+	if err != nil {
+		glog.Error(err)
+		return "", err
+	}
+	defer resp.Body.Close()
+	data, err = ioutil.ReadAll(resp.Body)
+
+	if err != nil {
+		glog.Error(err)
+		return "", err
+	}
+	if resp.StatusCode > http.StatusOK {
+		glog.Error(req.Method, req.URL, resp.StatusCode, string(data))
+		return "", Error{resp.StatusCode, string(data)}
+	}
+
+	var result struct {
+		Expression string `json:"expression"`
+	}
+	err = json.Unmarshal(data, &result)
+
+	if err != nil {
+		glog.Error(err)
+		return "", err
+	}
+	return result.Expression, nil
+}
+
 func IsNotFound(err error) bool {
 	if e, ok := err.(Error); ok {
 		if e.status == http.StatusNotFound {
@@ -346,6 +437,13 @@ func IsNotFound(err error) bool {
 	return false
 }
 
+func IsBadRequest(err error) bool {
+	if e, ok := err.(Error); ok {
+		return e.status == http.StatusBadRequest
+	}
+	return false
+}
+
 func IsExist(err error) bool {
 	if e, ok := err.(Error); ok {
 		if e.status == http.StatusConflict {
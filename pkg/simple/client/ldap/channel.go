@@ -87,18 +87,22 @@ func (c *channelPool) Get() (*PoolConn, error) {
 
 	// wrap our connections with our ldap.Client implementation (wrapConn
 	// method) that puts the connection back to the pool if it's closed.
-	select {
-	case conn := <-conns:
-		if conn == nil {
-			return nil, ErrClosed
-		}
-		if !c.aliveChecks || isAlive(conn) {
-			return c.wrapConn(conn, c.closeAt), nil
+	// idle connections are probably all broken after the server restarts,
+	// keep discarding them until a healthy one is found.
+	for {
+		select {
+		case conn := <-conns:
+			if conn == nil {
+				return nil, ErrClosed
+			}
+			if !c.aliveChecks || isAlive(conn) {
+				return c.wrapConn(conn, c.closeAt), nil
+			}
+			connectionsDiscarded.WithLabelValues("unhealthy").Inc()
+			conn.Close()
+		default:
+			return c.NewConn()
 		}
-		conn.Close()
-		return c.NewConn()
-	default:
-		return c.NewConn()
 	}
 }
 
@@ -139,6 +143,7 @@ func (c *channelPool) put(conn ldap.Client) {
 		return
 	default:
 		// pool is full, close passed connection
+		connectionsDiscarded.WithLabelValues("pool_full").Inc()
 		conn.Close()
 		return
 	}
@@ -167,5 +172,6 @@ func (c *channelPool) Len() int { return len(c.getConns()) }
 func (c *channelPool) wrapConn(conn ldap.Client, closeAt []uint16) *PoolConn {
 	p := &PoolConn{c: c, closeAt: closeAt}
 	p.Conn = conn
+	connectionsInUse.Inc()
 	return p
 }
@@ -13,6 +13,7 @@ type PoolConn struct {
 	Conn     ldap.Client
 	c        *channelPool
 	unusable bool
+	closed   bool
 	closeAt  []uint16
 }
 
@@ -22,13 +23,21 @@ func (p *PoolConn) Start() {
 
 func (p *PoolConn) StartTLS(config *tls.Config) error {
 	// FIXME - check if already TLS and then ignore?
-	return p.Conn.StartTLS(config)
+	err := p.Conn.StartTLS(config)
+	p.autoClose(err)
+	return err
 }
 
 // Close() puts the given connects back to the pool instead of closing it.
 func (p *PoolConn) Close() {
+	if p.closed {
+		return
+	}
+	p.closed = true
+	connectionsInUse.Dec()
 	if p.unusable {
 		log.Printf("Closing unusable connection")
+		connectionsDiscarded.WithLabelValues("unusable").Inc()
 		if p.Conn != nil {
 			p.Conn.Close()
 		}
@@ -38,15 +47,21 @@ func (p *PoolConn) Close() {
 }
 
 func (p *PoolConn) SimpleBind(simpleBindRequest *ldap.SimpleBindRequest) (*ldap.SimpleBindResult, error) {
-	return p.Conn.SimpleBind(simpleBindRequest)
+	result, err := p.Conn.SimpleBind(simpleBindRequest)
+	p.autoClose(err)
+	return result, err
 }
 
 func (p *PoolConn) Bind(username, password string) error {
-	return p.Conn.Bind(username, password)
+	err := p.Conn.Bind(username, password)
+	p.autoClose(err)
+	return err
 }
 
 func (p *PoolConn) ModifyDN(modifyDNRequest *ldap.ModifyDNRequest) error {
-	return p.Conn.ModifyDN(modifyDNRequest)
+	err := p.Conn.ModifyDN(modifyDNRequest)
+	p.autoClose(err)
+	return err
 }
 
 // MarkUnusable() marks the connection not usable any more, to let the pool close it
@@ -69,28 +84,42 @@ func (p *PoolConn) SetTimeout(t time.Duration) {
 }
 
 func (p *PoolConn) Add(addRequest *ldap.AddRequest) error {
-	return p.Conn.Add(addRequest)
+	err := p.Conn.Add(addRequest)
+	p.autoClose(err)
+	return err
 }
 
 func (p *PoolConn) Del(delRequest *ldap.DelRequest) error {
-	return p.Conn.Del(delRequest)
+	err := p.Conn.Del(delRequest)
+	p.autoClose(err)
+	return err
 }
 
 func (p *PoolConn) Modify(modifyRequest *ldap.ModifyRequest) error {
-	return p.Conn.Modify(modifyRequest)
+	err := p.Conn.Modify(modifyRequest)
+	p.autoClose(err)
+	return err
 }
 
 func (p *PoolConn) Compare(dn, attribute, value string) (bool, error) {
-	return p.Conn.Compare(dn, attribute, value)
+	result, err := p.Conn.Compare(dn, attribute, value)
+	p.autoClose(err)
+	return result, err
 }
 
 func (p *PoolConn) PasswordModify(passwordModifyRequest *ldap.PasswordModifyRequest) (*ldap.PasswordModifyResult, error) {
-	return p.Conn.PasswordModify(passwordModifyRequest)
+	result, err := p.Conn.PasswordModify(passwordModifyRequest)
+	p.autoClose(err)
+	return result, err
 }
 
 func (p *PoolConn) Search(searchRequest *ldap.SearchRequest) (*ldap.SearchResult, error) {
-	return p.Conn.Search(searchRequest)
+	result, err := p.Conn.Search(searchRequest)
+	p.autoClose(err)
+	return result, err
 }
 func (p *PoolConn) SearchWithPaging(searchRequest *ldap.SearchRequest, pagingSize uint32) (*ldap.SearchResult, error) {
-	return p.Conn.SearchWithPaging(searchRequest, pagingSize)
+	result, err := p.Conn.SearchWithPaging(searchRequest, pagingSize)
+	p.autoClose(err)
+	return result, err
 }
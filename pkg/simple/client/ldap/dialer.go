@@ -0,0 +1,190 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package ldap
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"strings"
+	"sync/atomic"
+
+	"github.com/go-ldap/ldap"
+	"github.com/golang/glog"
+)
+
+const (
+	// SelectionFailover always prefers the first available server in the configured order
+	SelectionFailover = "failover"
+	// SelectionRoundRobin spreads new connections across all available servers
+	SelectionRoundRobin = "roundrobin"
+)
+
+type server struct {
+	// host:port
+	address string
+	// ldaps://
+	tls bool
+}
+
+// dialer creates connections to one of the configured servers, unavailable servers are skipped
+type dialer struct {
+	servers   []server
+	selection string
+	startTLS  bool
+	tlsConfig *tls.Config
+	next      uint32
+}
+
+// newDialer parses a comma separated list of servers, such as "ldap://openldap-0:389,ldaps://openldap-1",
+// servers without scheme are treated as ldap://
+func newDialer(hosts string, selection string, startTLS bool, tlsConfig *tls.Config) (*dialer, error) {
+	d := &dialer{selection: selection, startTLS: startTLS, tlsConfig: tlsConfig}
+
+	if selection != SelectionFailover && selection != SelectionRoundRobin {
+		return nil, fmt.Errorf("unknown server selection %s", selection)
+	}
+
+	for _, host := range strings.Split(hosts, ",") {
+		host = strings.TrimSpace(host)
+
+		if host == "" {
+			continue
+		}
+
+		if !strings.Contains(host, "://") {
+			host = "ldap://" + host
+		}
+
+		u, err := url.Parse(host)
+
+		if err != nil {
+			return nil, err
+		}
+
+		s := server{address: u.Host}
+
+		switch u.Scheme {
+		case "ldap":
+			if u.Port() == "" {
+				s.address = net.JoinHostPort(u.Hostname(), ldap.DefaultLdapPort)
+			}
+		case "ldaps":
+			s.tls = true
+			if u.Port() == "" {
+				s.address = net.JoinHostPort(u.Hostname(), ldap.DefaultLdapsPort)
+			}
+		default:
+			return nil, fmt.Errorf("unknown scheme %s of ldap server %s", u.Scheme, host)
+		}
+
+		if s.tls && startTLS {
+			return nil, fmt.Errorf("ldap server %s already uses ldaps, StartTLS is not supported", host)
+		}
+
+		d.servers = append(d.servers, s)
+	}
+
+	if len(d.servers) == 0 {
+		return nil, errors.New("no ldap server configured")
+	}
+
+	return d, nil
+}
+
+// Dial implements PoolFactory
+func (d *dialer) Dial(name string) (ldap.Client, error) {
+	start := 0
+
+	if d.selection == SelectionRoundRobin {
+		start = int(atomic.AddUint32(&d.next, 1)-1) % len(d.servers)
+	}
+
+	var lastErr error
+
+	for i := 0; i < len(d.servers); i++ {
+		s := d.servers[(start+i)%len(d.servers)]
+
+		conn, err := d.dial(s)
+
+		if err != nil {
+			glog.Warningf("%s: dial ldap server %s failed: %v", name, s.address, err)
+			dialFailures.WithLabelValues(s.address).Inc()
+			lastErr = err
+			continue
+		}
+
+		connectionsCreated.WithLabelValues(s.address).Inc()
+		return conn, nil
+	}
+
+	return nil, lastErr
+}
+
+func (d *dialer) dial(s server) (ldap.Client, error) {
+	if s.tls {
+		return ldap.DialTLS("tcp", s.address, d.serverTLSConfig(s))
+	}
+
+	conn, err := ldap.Dial("tcp", s.address)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if d.startTLS {
+		if err := conn.StartTLS(d.serverTLSConfig(s)); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	return conn, nil
+}
+
+func (d *dialer) serverTLSConfig(s server) *tls.Config {
+	config := d.tlsConfig.Clone()
+	if config.ServerName == "" {
+		config.ServerName, _, _ = net.SplitHostPort(s.address)
+	}
+	return config
+}
+
+func newTLSConfig(caFile string, insecureSkipVerify bool) (*tls.Config, error) {
+	config := &tls.Config{InsecureSkipVerify: insecureSkipVerify}
+
+	if caFile != "" {
+		ca, err := ioutil.ReadFile(caFile)
+
+		if err != nil {
+			return nil, err
+		}
+
+		config.RootCAs = x509.NewCertPool()
+
+		if !config.RootCAs.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificate found in %s", caFile)
+		}
+	}
+
+	return config, nil
+}
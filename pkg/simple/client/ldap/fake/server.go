@@ -0,0 +1,322 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package fake
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/go-ldap/ldap"
+	"gopkg.in/asn1-ber.v1"
+)
+
+// Server is an in-memory ldap server, binds always succeed and searches are evaluated against entries
+// added by Add, equality, presence and substring filters combined by and, or and not are supported
+type Server struct {
+	listener    net.Listener
+	mutex       sync.Mutex
+	conns       []net.Conn
+	connections int32
+	entries     []*ldap.Entry
+	searches    []*ldap.SearchRequest
+}
+
+func NewServer() (*Server, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{listener: listener}
+	go s.serve()
+	return s, nil
+}
+
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Add adds an entry to the directory, attributes are multi-valued like member of groups
+func (s *Server) Add(dn string, attributes map[string][]string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.entries = append(s.entries, ldap.NewEntry(dn, attributes))
+}
+
+// Searches returns search requests received by the server in order
+func (s *Server) Searches() []*ldap.SearchRequest {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	searches := make([]*ldap.SearchRequest, len(s.searches))
+	copy(searches, s.searches)
+	return searches
+}
+
+// ResetSearches forgets received search requests
+func (s *Server) ResetSearches() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.searches = nil
+}
+
+// Connections returns the number of accepted connections
+func (s *Server) Connections() int {
+	return int(atomic.LoadInt32(&s.connections))
+}
+
+// Stop closes the listener and all established connections, like a restarted server
+func (s *Server) Stop() {
+	s.listener.Close()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+}
+
+func (s *Server) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		atomic.AddInt32(&s.connections, 1)
+		s.mutex.Lock()
+		s.conns = append(s.conns, conn)
+		s.mutex.Unlock()
+		go s.handle(conn)
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+		messageID := packet.Children[0].Value
+		switch packet.Children[1].Tag {
+		case ldap.ApplicationBindRequest:
+			conn.Write(response(messageID, ldap.ApplicationBindResponse, ldap.LDAPResultSuccess).Bytes())
+		case ldap.ApplicationSearchRequest:
+			entries, code := s.search(packet.Children[1])
+			for _, entry := range entries {
+				conn.Write(entryResponse(messageID, entry).Bytes())
+			}
+			conn.Write(response(messageID, ldap.ApplicationSearchResultDone, code).Bytes())
+		default:
+			return
+		}
+	}
+}
+
+func (s *Server) search(request *ber.Packet) ([]*ldap.Entry, uint16) {
+	if len(request.Children) < 8 {
+		return nil, ldap.LDAPResultProtocolError
+	}
+
+	base, _ := request.Children[0].Value.(string)
+	scope, _ := request.Children[1].Value.(int64)
+	filter := request.Children[6]
+
+	attributes := make([]string, 0)
+	for _, attribute := range request.Children[7].Children {
+		if name, ok := attribute.Value.(string); ok {
+			attributes = append(attributes, name)
+		}
+	}
+
+	decompiled, _ := ldap.DecompileFilter(filter)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.searches = append(s.searches, ldap.NewSearchRequest(base, int(scope), ldap.NeverDerefAliases, 0, 0, false, decompiled, attributes, nil))
+
+	base = normalize(base)
+
+	if base != "" && !s.exists(base) {
+		return nil, ldap.LDAPResultNoSuchObject
+	}
+
+	entries := make([]*ldap.Entry, 0)
+
+	for _, entry := range s.entries {
+		if inScope(normalize(entry.DN), base, int(scope)) && matches(entry, filter) {
+			entries = append(entries, selectAttributes(entry, attributes))
+		}
+	}
+
+	return entries, ldap.LDAPResultSuccess
+}
+
+func (s *Server) exists(dn string) bool {
+	for _, entry := range s.entries {
+		if normalize(entry.DN) == dn {
+			return true
+		}
+	}
+	return false
+}
+
+func normalize(dn string) string {
+	parts := strings.Split(strings.ToLower(dn), ",")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return strings.Join(parts, ",")
+}
+
+func inScope(dn, base string, scope int) bool {
+	switch scope {
+	case ldap.ScopeBaseObject:
+		return dn == base
+	case ldap.ScopeSingleLevel:
+		return strings.Contains(dn, ",") && dn[strings.Index(dn, ",")+1:] == base
+	default:
+		return dn == base || base == "" || strings.HasSuffix(dn, ","+base)
+	}
+}
+
+func matches(entry *ldap.Entry, filter *ber.Packet) bool {
+	switch filter.Tag {
+	case ldap.FilterAnd:
+		for _, child := range filter.Children {
+			if !matches(entry, child) {
+				return false
+			}
+		}
+		return true
+	case ldap.FilterOr:
+		for _, child := range filter.Children {
+			if matches(entry, child) {
+				return true
+			}
+		}
+		return false
+	case ldap.FilterNot:
+		return len(filter.Children) == 1 && !matches(entry, filter.Children[0])
+	case ldap.FilterPresent:
+		name := ber.DecodeString(filter.Data.Bytes())
+		return strings.EqualFold(name, "objectClass") || len(values(entry, name)) > 0
+	case ldap.FilterEqualityMatch:
+		name := ber.DecodeString(filter.Children[0].Data.Bytes())
+		expected := ber.DecodeString(filter.Children[1].Data.Bytes())
+		// values of distinguished name syntax like member are compared as DNs
+		for _, value := range values(entry, name) {
+			if strings.EqualFold(value, expected) || normalize(value) == normalize(expected) {
+				return true
+			}
+		}
+		return false
+	case ldap.FilterSubstrings:
+		name := ber.DecodeString(filter.Children[0].Data.Bytes())
+		for _, value := range values(entry, name) {
+			if substringMatches(strings.ToLower(value), filter.Children[1].Children) {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+func substringMatches(value string, substrings []*ber.Packet) bool {
+	for _, substring := range substrings {
+		part := strings.ToLower(ber.DecodeString(substring.Data.Bytes()))
+		switch substring.Tag {
+		case ldap.FilterSubstringsInitial:
+			if !strings.HasPrefix(value, part) {
+				return false
+			}
+			value = value[len(part):]
+		case ldap.FilterSubstringsAny:
+			index := strings.Index(value, part)
+			if index < 0 {
+				return false
+			}
+			value = value[index+len(part):]
+		case ldap.FilterSubstringsFinal:
+			if !strings.HasSuffix(value, part) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// values returns values of the attribute, attribute names are case insensitive
+func values(entry *ldap.Entry, name string) []string {
+	for _, attribute := range entry.Attributes {
+		if strings.EqualFold(attribute.Name, name) {
+			return attribute.Values
+		}
+	}
+	return nil
+}
+
+// selectAttributes returns the entry with requested attributes, all attributes are returned if none is requested
+func selectAttributes(entry *ldap.Entry, attributes []string) *ldap.Entry {
+	if len(attributes) == 0 {
+		return entry
+	}
+	selected := &ldap.Entry{DN: entry.DN}
+	for _, attribute := range entry.Attributes {
+		for _, name := range attributes {
+			if name == "*" || strings.EqualFold(attribute.Name, name) {
+				selected.Attributes = append(selected.Attributes, attribute)
+				break
+			}
+		}
+	}
+	return selected
+}
+
+func entryResponse(messageID interface{}, entry *ldap.Entry) *ber.Packet {
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, "MessageID"))
+	result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, entry.DN, "objectName"))
+	attributes := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "attributes")
+	for _, attribute := range entry.Attributes {
+		partial := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "partialAttribute")
+		partial.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, attribute.Name, "type"))
+		vals := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "vals")
+		for _, value := range attribute.Values {
+			vals.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "value"))
+		}
+		partial.AppendChild(vals)
+		attributes.AppendChild(partial)
+	}
+	result.AppendChild(attributes)
+	packet.AppendChild(result)
+	return packet
+}
+
+func response(messageID interface{}, tag ber.Tag, code uint16) *ber.Packet {
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, "MessageID"))
+	result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Response")
+	result.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, uint64(code), "resultCode"))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "matchedDN"))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "diagnosticMessage"))
+	packet.AppendChild(result)
+	return packet
+}
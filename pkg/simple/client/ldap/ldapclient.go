@@ -26,40 +26,81 @@ import (
 )
 
 var (
-	once            sync.Once
-	pool            Pool
-	ldapHost        string
-	ManagerDN       string
-	ManagerPassword string
-	UserSearchBase  string
-	GroupSearchBase string
-	poolSize        int
+	once               sync.Once
+	pool               Pool
+	ldapHost           string
+	ManagerDN          string
+	ManagerPassword    string
+	UserSearchBase     string
+	GroupSearchBase    string
+	poolSize           int
+	serverSelection    string
+	startTLS           bool
+	caFile             string
+	insecureSkipVerify bool
+
+	// ReadOnly means the directory is managed outside of KubeSphere, such as Active Directory,
+	// users and groups are never written and KubeSphere specific data is kept in a side store
+	ReadOnly             bool
+	UserSearchFilter     string
+	GroupSearchFilter    string
+	UserNameAttribute    string
+	MailAttribute        string
+	DescriptionAttribute string
+	// MemberOfAttribute lists groups of a user on the user entry, such as memberOf, groups are searched by GroupMemberAttribute if empty
+	MemberOfAttribute string
+	// GroupMemberAttribute holds members of a group, either user names (memberUid) or DNs (member)
+	GroupMemberAttribute string
+	NestedGroups         bool
+	// AdminUser of a read only directory is bound to cluster-admin on start, nobody is able to manage the cluster otherwise
+	AdminUser string
 )
 
 func init() {
-	flag.StringVar(&ldapHost, "ldap-server", "localhost:389", "ldap server host")
+	flag.StringVar(&ldapHost, "ldap-server", "localhost:389", "ldap server host, multiple servers are separated by comma, such as ldap://openldap-0:389,ldaps://openldap-1:636")
 	flag.StringVar(&ManagerDN, "ldap-manager-dn", "cn=admin,dc=example,dc=org", "ldap manager dn")
 	flag.StringVar(&ManagerPassword, "ldap-manager-password", "admin", "ldap manager password")
 	flag.StringVar(&UserSearchBase, "ldap-user-search-base", "ou=Users,dc=example,dc=org", "ldap user search base")
 	flag.StringVar(&GroupSearchBase, "ldap-group-search-base", "ou=Groups,dc=example,dc=org", "ldap group search base")
 	flag.IntVar(&poolSize, "ldap-pool-size", 64, "ldap connection pool size")
+	flag.StringVar(&serverSelection, "ldap-server-selection", SelectionFailover, "how to choose ldap server for new connections, failover or roundrobin")
+	flag.BoolVar(&startTLS, "ldap-start-tls", false, "upgrade ldap:// connections with StartTLS")
+	flag.StringVar(&caFile, "ldap-ca-file", "", "CA certificate used to verify ldap servers, system roots are used if empty")
+	flag.BoolVar(&insecureSkipVerify, "ldap-insecure-skip-verify", false, "skip verification of ldap server certificates")
+	flag.BoolVar(&ReadOnly, "ldap-read-only", false, "use an external directory such as Active Directory as a read only user source")
+	flag.StringVar(&UserSearchFilter, "ldap-user-search-filter", "(objectClass=inetOrgPerson)", "ldap filter of user entries, such as (&(objectCategory=person)(objectClass=user))")
+	flag.StringVar(&GroupSearchFilter, "ldap-group-search-filter", "(objectClass=posixGroup)", "ldap filter of group entries, such as (objectClass=group)")
+	flag.StringVar(&UserNameAttribute, "ldap-user-name-attribute", "uid", "ldap attribute of user name, such as sAMAccountName")
+	flag.StringVar(&MailAttribute, "ldap-mail-attribute", "mail", "ldap attribute of user email")
+	flag.StringVar(&DescriptionAttribute, "ldap-description-attribute", "description", "ldap attribute of user description")
+	flag.StringVar(&MemberOfAttribute, "ldap-member-of-attribute", "", "ldap attribute of user groups, such as memberOf")
+	flag.StringVar(&GroupMemberAttribute, "ldap-group-member-attribute", "memberUid", "ldap attribute of group members, such as member")
+	flag.BoolVar(&NestedGroups, "ldap-nested-groups", false, "resolve groups of groups")
+	flag.StringVar(&AdminUser, "ldap-admin-user", "", "user of the read only directory bound to cluster-admin on start if it has no cluster role, no administrator is bootstrapped if empty")
 }
 
 func ldapClientPool() Pool {
 
 	once.Do(func() {
-		var err error
-		pool, err = NewChannelPool(8, poolSize, "kubesphere", func(s string) (ldap.Client, error) {
-			conn, err := ldap.Dial("tcp", ldapHost)
-			if err != nil {
-				return nil, err
-			}
-			return conn, nil
-		}, []uint16{ldap.LDAPResultTimeLimitExceeded, ldap.ErrorNetwork})
+		tlsConfig, err := newTLSConfig(caFile, insecureSkipVerify)
 
 		if err != nil {
 			log.Fatalln(err)
 		}
+
+		d, err := newDialer(ldapHost, serverSelection, startTLS, tlsConfig)
+
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		pool, err = NewChannelPool(8, poolSize, "kubesphere", d.Dial, []uint16{ldap.LDAPResultTimeLimitExceeded, ldap.ErrorNetwork})
+
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		registerIdleConnections(pool)
 	})
 	return pool
 }
@@ -74,6 +115,21 @@ func Client() (ldap.Client, error) {
 
 	err = conn.Bind(ManagerDN, ManagerPassword)
 
+	// the server may be closed after the health check, reconnect once
+	if err != nil && ldap.IsErrorWithCode(err, ldap.ErrorNetwork) {
+		conn.Close()
+		glog.Warningln("bind manager dn, reconnecting", err)
+
+		conn, err = ldapClientPool().Get()
+
+		if err != nil {
+			glog.Errorln("get ldap connection from pool", err)
+			return nil, err
+		}
+
+		err = conn.Bind(ManagerDN, ManagerPassword)
+	}
+
 	if err != nil {
 		conn.Close()
 		glog.Errorln("bind manager dn", err)
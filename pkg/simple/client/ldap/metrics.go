@@ -0,0 +1,73 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package ldap
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	metricsNamespace = "kubesphere"
+	metricsSubsystem = "ldap_pool"
+)
+
+var (
+	connectionsCreated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "connections_created_total",
+		Help:      "Number of connections established to each ldap server.",
+	}, []string{"server"})
+
+	dialFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "dial_failures_total",
+		Help:      "Number of failed attempts to connect to each ldap server.",
+	}, []string{"server"})
+
+	connectionsDiscarded = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "connections_discarded_total",
+		Help:      "Number of connections closed by the pool, by reason.",
+	}, []string{"reason"})
+
+	connectionsInUse = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "connections_in_use",
+		Help:      "Number of connections taken from the pool and not yet returned.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(connectionsCreated, dialFailures, connectionsDiscarded, connectionsInUse)
+}
+
+// registerIdleConnections exposes the number of idle connections of the pool
+func registerIdleConnections(p Pool) {
+	prometheus.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "connections_idle",
+		Help:      "Number of idle connections in the pool.",
+	}, func() float64 {
+		return float64(p.Len())
+	}))
+}
@@ -0,0 +1,208 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package ldap
+
+import (
+	"crypto/tls"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/go-ldap/ldap"
+	dto "github.com/prometheus/client_model/go"
+
+	"kubesphere.io/kubesphere/pkg/simple/client/ldap/fake"
+)
+
+func newFakeServer(t *testing.T) *fake.Server {
+	s, err := fake.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// waitConnections waits for connections to be accepted, dial returns before the server accepts them
+func waitConnections(servers []*fake.Server, expected []int) bool {
+	deadline := time.Now().Add(time.Second)
+	for {
+		matched := true
+		for i := range servers {
+			if servers[i].Connections() != expected[i] {
+				matched = false
+			}
+		}
+		if matched || time.Now().After(deadline) {
+			return matched
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// unavailableAddr returns an address nothing listens on
+func unavailableAddr(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+	return addr
+}
+
+func discarded(t *testing.T, reason string) float64 {
+	var metric dto.Metric
+	if err := connectionsDiscarded.WithLabelValues(reason).Write(&metric); err != nil {
+		t.Fatal(err)
+	}
+	return metric.GetCounter().GetValue()
+}
+
+func TestNewDialer(t *testing.T) {
+	tests := []struct {
+		hosts    string
+		startTLS bool
+		expected []server
+		hasError bool
+	}{
+		{hosts: "localhost:389", expected: []server{{address: "localhost:389"}}},
+		{hosts: "ldap://openldap-0, ldaps://openldap-1", expected: []server{{address: "openldap-0:389"}, {address: "openldap-1:636", tls: true}}},
+		{hosts: "ldaps://openldap:1636", startTLS: true, hasError: true},
+		{hosts: "http://openldap", hasError: true},
+		{hosts: " , ", hasError: true},
+	}
+
+	for _, test := range tests {
+		d, err := newDialer(test.hosts, SelectionFailover, test.startTLS, &tls.Config{})
+		if test.hasError {
+			if err == nil {
+				t.Errorf("%s: expect error", test.hosts)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.hosts, err)
+			continue
+		}
+		if len(d.servers) != len(test.expected) {
+			t.Errorf("%s: expect %v, got %v", test.hosts, test.expected, d.servers)
+			continue
+		}
+		for i := range d.servers {
+			if d.servers[i] != test.expected[i] {
+				t.Errorf("%s: expect %v, got %v", test.hosts, test.expected, d.servers)
+			}
+		}
+	}
+}
+
+func TestDialerFailover(t *testing.T) {
+	primary := newFakeServer(t)
+	defer primary.Stop()
+	secondary := newFakeServer(t)
+	defer secondary.Stop()
+
+	d, err := newDialer(unavailableAddr(t)+","+primary.Addr()+","+secondary.Addr(), SelectionFailover, false, &tls.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		conn, err := d.Dial("test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn.Close()
+	}
+
+	if !waitConnections([]*fake.Server{primary, secondary}, []int{3, 0}) {
+		t.Errorf("expect all connections to the first available server, got %d and %d", primary.Connections(), secondary.Connections())
+	}
+}
+
+func TestDialerRoundRobin(t *testing.T) {
+	first := newFakeServer(t)
+	defer first.Stop()
+	second := newFakeServer(t)
+	defer second.Stop()
+
+	d, err := newDialer(first.Addr()+","+second.Addr(), SelectionRoundRobin, false, &tls.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 4; i++ {
+		conn, err := d.Dial("test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn.Close()
+	}
+
+	if !waitConnections([]*fake.Server{first, second}, []int{2, 2}) {
+		t.Errorf("expect connections to be spread evenly, got %d and %d", first.Connections(), second.Connections())
+	}
+}
+
+func TestPoolReconnect(t *testing.T) {
+	primary := newFakeServer(t)
+	secondary := newFakeServer(t)
+	defer secondary.Stop()
+
+	d, err := newDialer(primary.Addr()+","+secondary.Addr(), SelectionFailover, false, &tls.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := NewChannelPool(2, 4, "test", d.Dial, []uint16{ldap.LDAPResultTimeLimitExceeded, ldap.ErrorNetwork})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	if !waitConnections([]*fake.Server{primary}, []int{2}) {
+		t.Fatalf("expect initial connections to the primary server, got %d", primary.Connections())
+	}
+
+	unhealthy := discarded(t, "unhealthy")
+
+	primary.Stop()
+
+	conn, err := p.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := conn.Bind("cn=admin,dc=example,dc=org", "admin"); err != nil {
+		t.Fatalf("expect to bind on the reconnected connection, got %v", err)
+	}
+
+	conn.Close()
+
+	if !waitConnections([]*fake.Server{secondary}, []int{1}) {
+		t.Errorf("expect to reconnect to the secondary server, got %d connections", secondary.Connections())
+	}
+
+	if discarded(t, "unhealthy")-unhealthy != 2 {
+		t.Errorf("expect both broken idle connections to be discarded")
+	}
+
+	if p.Len() != 1 {
+		t.Errorf("expect the healthy connection to be returned to the pool, got %d idle connections", p.Len())
+	}
+}
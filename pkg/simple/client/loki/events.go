@@ -0,0 +1,333 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package loki
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+
+	es "kubesphere.io/kubesphere/pkg/simple/client/elasticsearch"
+)
+
+const (
+	// events are pushed to streams of the job, separated from logs collected by fluent bit
+	eventsJob            = "kubesphere-events"
+	labelJob             = "job"
+	labelEventsNamespace = "namespace"
+)
+
+var _ es.EventsBackend = &Client{}
+
+type pushRequest struct {
+	Streams []stream `json:"streams"`
+}
+
+// IndexEvents pushes the events as JSON lines at the time they last occurred, loki can't overwrite lines,
+// so updates of an event are new lines of the same uid and queries keep the latest one
+func (c *Client) IndexEvents(events []es.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	streams := make(map[string]*stream)
+	namespaces := make([]string, 0)
+
+	for _, event := range events {
+		line, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+
+		at, ok := parseTime(event.Time)
+		if !ok {
+			at = time.Now()
+		}
+
+		s, ok := streams[event.Namespace]
+		if !ok {
+			s = &stream{Stream: map[string]string{labelJob: eventsJob}}
+			// events of cluster scoped objects have no namespace label
+			if event.Namespace != "" {
+				s.Stream[labelEventsNamespace] = event.Namespace
+			}
+			streams[event.Namespace] = s
+			namespaces = append(namespaces, event.Namespace)
+		}
+		s.Values = append(s.Values, [2]string{strconv.FormatInt(at.UnixNano(), 10), string(line)})
+	}
+
+	request := pushRequest{Streams: make([]stream, 0, len(streams))}
+	for _, namespace := range namespaces {
+		s := streams[namespace]
+		// entries of a stream are accepted in order of time
+		sort.SliceStable(s.Values, func(i, j int) bool {
+			a, _ := strconv.ParseInt(s.Values[i][0], 10, 64)
+			b, _ := strconv.ParseInt(s.Values[j][0], 10, 64)
+			return a < b
+		})
+		request.Streams = append(request.Streams, *s)
+	}
+
+	return c.post("/loki/api/v1/push", request)
+}
+
+func (c *Client) post(path string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	response, err := c.client.Post(c.endpoint+path, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNoContent {
+		message, _ := ioutil.ReadAll(response.Body)
+		return fmt.Errorf("loki request failed with status %d: %s", response.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	return nil
+}
+
+// newEventsQuery translates parameters to a stream selector and label filters of the json stage,
+// false is returned if nothing matches
+func newEventsQuery(param es.EventQueryParameters) (*query, bool) {
+	q := &query{namespaceCreationTime: make(map[string]int64)}
+
+	matchers := []string{fmt.Sprintf("%s=%s", labelJob, strconv.Quote(eventsJob))}
+
+	if param.NamespaceFilled {
+		if len(param.NamespaceWithCreationTime) == 0 {
+			return nil, false
+		}
+		namespaces := make([]string, 0, len(param.NamespaceWithCreationTime))
+		for namespace, creationTime := range param.NamespaceWithCreationTime {
+			namespaces = append(namespaces, namespace)
+			q.namespaceCreationTime[namespace] = timestamp(creationTime)
+		}
+		sort.Strings(namespaces)
+		matchers = append(matchers, fmt.Sprintf("%s=~%s", labelEventsNamespace, strconv.Quote(regexAlternation(namespaces, false))))
+	}
+
+	q.selector = fmt.Sprintf("{%s}", strings.Join(matchers, ", "))
+	q.parse()
+
+	if param.WorkloadFilled {
+		if len(param.Workloads) == 0 {
+			return nil, false
+		}
+		q.filter += fmt.Sprintf(" | workload=~%s", strconv.Quote(regexAlternation(param.Workloads, false)))
+	}
+
+	for _, clause := range []struct {
+		field  string
+		values []string
+	}{
+		{"involved_object.kind", param.InvolvedObjectKinds},
+		{"involved_object.name", param.InvolvedObjectNames},
+		{"reason", param.Reasons},
+		{"type", param.Types},
+	} {
+		if len(clause.values) > 0 {
+			q.filter += fmt.Sprintf(" | %s=~%s", fieldLabel(clause.field), strconv.Quote(regexAlternation(clause.values, false)))
+		}
+	}
+
+	for _, clause := range []struct {
+		field    string
+		keywords string
+	}{
+		// namespace of the line is renamed by the json stage, it conflicts with the stream label
+		{"namespace_extracted", param.NamespaceQuery},
+		{"workload", param.WorkloadQuery},
+		{"message", param.MessageQuery},
+	} {
+		if words := keywords(clause.keywords); len(words) > 0 {
+			q.filter += fmt.Sprintf(" | %s=~%s", clause.field, strconv.Quote(regexAlternation(words, true)))
+		}
+	}
+
+	q.end = time.Now()
+	if end, ok := parseTime(param.EndTime); ok {
+		q.end = end
+	}
+
+	q.start = q.end.Add(-defaultRange)
+	if start, ok := parseTime(param.StartTime); ok {
+		q.start = start
+	}
+
+	return q, true
+}
+
+// distinctLogQL counts events of the query in the range, updates of an event are counted once
+func (q *query) distinctLogQL(rng time.Duration) string {
+	return fmt.Sprintf("count(sum by (uid) (%s))", q.countLogQL(rng))
+}
+
+func (c *Client) QueryEvents(param es.EventQueryParameters) *es.EventsResult {
+	result, err := c.queryEvents(param)
+	if err != nil {
+		glog.Errorln(err)
+		return &es.EventsResult{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	result.Status = http.StatusOK
+
+	return result
+}
+
+func (c *Client) queryEvents(param es.EventQueryParameters) (*es.EventsResult, error) {
+	q, ok := newEventsQuery(param)
+
+	switch param.Operation {
+	case "statistics":
+		statistics := &es.EventStatisticsResult{}
+		if !ok {
+			return &es.EventsResult{Statistics: statistics}, nil
+		}
+
+		events, err := c.instant(q.distinctLogQL(q.end.Sub(q.start)), q.end)
+		if err != nil {
+			return nil, err
+		}
+
+		warnings := *q
+		warnings.filter += ` | type="Warning"`
+		warningEvents, err := c.instant(warnings.distinctLogQL(q.end.Sub(q.start)), q.end)
+		if err != nil {
+			return nil, err
+		}
+
+		statistics.Events, statistics.Warnings = events, warningEvents
+		return &es.EventsResult{Statistics: statistics}, nil
+
+	case "histogram":
+		interval := param.Interval
+		if interval == "" {
+			interval = defaultInterval
+		}
+
+		histogram := &es.HistogramResult{StartTime: timestamp(param.StartTime), EndTime: timestamp(param.EndTime), Interval: interval}
+		if !ok {
+			return &es.EventsResult{Histogram: histogram}, nil
+		}
+
+		step, err := parseInterval(interval)
+		if err != nil {
+			return nil, err
+		}
+
+		histograms, err := c.rangeHistogram(q.distinctLogQL(step), q, step)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, record := range histograms {
+			histogram.Total += record.Count
+		}
+		histogram.Histograms = histograms
+
+		return &es.EventsResult{Histogram: histogram}, nil
+
+	default:
+		read := &es.EventsReadResult{From: param.From, Size: param.Size}
+		if !ok {
+			return &es.EventsResult{Read: read}, nil
+		}
+
+		total, err := c.instant(q.distinctLogQL(q.end.Sub(q.start)), q.end)
+		if err != nil {
+			return nil, err
+		}
+		read.Total = total
+
+		events, err := c.searchEvents(q, param)
+		if err != nil {
+			return nil, err
+		}
+		if len(events) > 0 {
+			read.Records = events
+		}
+
+		return &es.EventsResult{Read: read}, nil
+	}
+}
+
+// searchEvents returns the page of the latest versions of events, sorted by the time they last occurred,
+// the same as Search the offset is limited by max entries of loki
+func (c *Client) searchEvents(q *query, param es.EventQueryParameters) ([]es.Event, error) {
+	// lines are fetched from the newest, so the first line of each uid is the latest version of the event
+	entries, err := c.queryRange(q, maxEntries, "backward")
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	events := make([]es.Event, 0)
+
+	for _, e := range entries {
+		var event es.Event
+		if err := json.Unmarshal([]byte(e.line), &event); err != nil {
+			glog.Warningf("invalid event line %s: %s", e.line, err)
+			continue
+		}
+		if seen[event.UID] {
+			continue
+		}
+		seen[event.UID] = true
+
+		// events of deleted namespaces with the same name are left out
+		if creationTime, ok := q.namespaceCreationTime[event.Namespace]; ok && timestamp(event.Time) < creationTime {
+			continue
+		}
+
+		events = append(events, event)
+	}
+
+	asc := param.Sort == "asc"
+	sort.SliceStable(events, func(i, j int) bool {
+		a, b := timestamp(events[i].Time), timestamp(events[j].Time)
+		if asc {
+			return a < b
+		}
+		return a > b
+	})
+
+	if param.From >= int64(len(events)) {
+		return nil, nil
+	}
+
+	end := param.From + param.Size
+	if end > int64(len(events)) {
+		end = int64(len(events))
+	}
+
+	return events[param.From:end], nil
+}
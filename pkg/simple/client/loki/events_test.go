@@ -0,0 +1,167 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package loki
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	es "kubesphere.io/kubesphere/pkg/simple/client/elasticsearch"
+)
+
+func eventLine(uid, namespace, time string, count int32) string {
+	data, _ := json.Marshal(es.Event{UID: uid, Namespace: namespace, Time: time, Count: count, Type: "Warning", Reason: "BackOff"})
+	return string(data)
+}
+
+func TestIndexEvents(t *testing.T) {
+	fake, client, stop := startFakeLoki(t, map[string][]string{
+		"/loki/api/v1/push": {"{}"},
+	})
+	defer stop()
+
+	events := []es.Event{
+		{UID: "b", Namespace: "default", Time: "2019-10-18T12:00:02Z"},
+		{UID: "a", Namespace: "default", Time: "2019-10-18T12:00:01Z"},
+		{UID: "c", Time: "2019-10-18T12:00:00Z", InvolvedObject: es.InvolvedObject{Kind: "Node", Name: "node1"}},
+	}
+
+	if err := client.IndexEvents(events); err != nil {
+		t.Fatal(err)
+	}
+
+	var request pushRequest
+	if err := json.Unmarshal([]byte(fake.bodies[0]), &request); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(request.Streams) != 2 {
+		t.Fatalf("expected streams of default and cluster events, got %+v", request.Streams)
+	}
+
+	// entries of a stream are sorted by time
+	expected := []stream{
+		{Stream: map[string]string{"job": "kubesphere-events", "namespace": "default"}, Values: [][2]string{
+			{"1571400001000000000", mustMarshal(events[1])},
+			{"1571400002000000000", mustMarshal(events[0])},
+		}},
+	}
+
+	if !reflect.DeepEqual(request.Streams[:1], expected) {
+		t.Errorf("expected %+v, got %+v", expected, request.Streams[:1])
+	}
+
+	if labels := request.Streams[1].Stream; !reflect.DeepEqual(labels, map[string]string{"job": "kubesphere-events"}) {
+		t.Errorf("expected no namespace label of cluster events, got %v", labels)
+	}
+}
+
+func mustMarshal(event es.Event) string {
+	data, _ := json.Marshal(event)
+	return string(data)
+}
+
+func TestNewEventsQuery(t *testing.T) {
+	tests := []struct {
+		param    es.EventQueryParameters
+		expected string
+		ok       bool
+	}{
+		{es.EventQueryParameters{}, `{job="kubesphere-events"} | json`, true},
+		{es.EventQueryParameters{NamespaceFilled: true}, "", false},
+		{es.EventQueryParameters{WorkloadFilled: true}, "", false},
+		{
+			es.EventQueryParameters{
+				NamespaceFilled:           true,
+				NamespaceWithCreationTime: map[string]string{"kube-system": "1571300000000", "default": "1571400001500"},
+				WorkloadFilled:            true,
+				Workloads:                 []string{"web"},
+				InvolvedObjectKinds:       []string{"Pod"},
+				Reasons:                   []string{"BackOff", "Failed"},
+				MessageQuery:              "image pull",
+			},
+			`{job="kubesphere-events", namespace=~"default|kube-system"} | json | workload=~"web" | involved_object_kind=~"Pod" | reason=~"BackOff|Failed" | message=~"(?i).*(image|pull).*"`,
+			true,
+		},
+	}
+
+	for i, test := range tests {
+		q, ok := newEventsQuery(test.param)
+		if ok != test.ok {
+			t.Errorf("case %d: expected %t, got %t", i, test.ok, ok)
+			continue
+		}
+		if ok && q.logQL() != test.expected {
+			t.Errorf("case %d: expected %s, got %s", i, test.expected, q.logQL())
+		}
+	}
+}
+
+func TestQueryEvents(t *testing.T) {
+	count := `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1571400010,"2"]}]}}`
+	data, _ := json.Marshal(map[string]interface{}{
+		"status": "success",
+		"data": map[string]interface{}{
+			"resultType": "streams",
+			"result": []interface{}{map[string]interface{}{
+				"stream": map[string]string{"job": "kubesphere-events", "namespace": "default"},
+				"values": [][2]string{
+					{"1571400004000000000", eventLine("a", "default", "2019-10-18T12:00:04Z", 2)},
+					{"1571400003000000000", eventLine("b", "default", "2019-10-18T12:00:03Z", 1)},
+					{"1571400002000000000", eventLine("a", "default", "2019-10-18T12:00:02Z", 1)},
+					// the event is of a deleted namespace of the same name
+					{"1571400000000000000", eventLine("c", "default", "2019-10-18T12:00:00Z", 1)},
+				},
+			}},
+		},
+	})
+
+	fake, client, stop := startFakeLoki(t, map[string][]string{
+		"/loki/api/v1/query":       {count},
+		"/loki/api/v1/query_range": {string(data)},
+	})
+	defer stop()
+
+	result := client.QueryEvents(es.EventQueryParameters{
+		NamespaceFilled:           true,
+		NamespaceWithCreationTime: map[string]string{"default": "1571400001000"},
+		StartTime:                 "1571400000000",
+		EndTime:                   "1571400010000",
+		Size:                      10,
+	})
+	if result.Status != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", result.Status, result.Error)
+	}
+
+	// updates of an event are merged into the latest one
+	var uids []string
+	var counts []int32
+	for _, event := range result.Read.Records {
+		uids = append(uids, event.UID)
+		counts = append(counts, event.Count)
+	}
+	if result.Read.Total != 2 || !reflect.DeepEqual(uids, []string{"a", "b"}) || !reflect.DeepEqual(counts, []int32{2, 1}) {
+		t.Errorf("unexpected result %d %+v", result.Read.Total, result.Read.Records)
+	}
+
+	if query := fake.queries[0].Get("query"); query != `count(sum by (uid) (count_over_time({job="kubesphere-events", namespace=~"default"} | json[10s])))` {
+		t.Errorf("unexpected count query %s", query)
+	}
+}
@@ -0,0 +1,765 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package loki
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+
+	es "kubesphere.io/kubesphere/pkg/simple/client/elasticsearch"
+)
+
+const (
+	BackendLoki = "loki"
+
+	// labels set by the loki output of fluent bit with auto_kubernetes_labels
+	labelNamespace = "namespace_name"
+	labelPod       = "pod_name"
+	labelContainer = "container_name"
+	labelHost      = "host"
+
+	// max_entries_limit_per_query of loki by default
+	maxEntries = 5000
+
+	// max_query_length of loki is 721h by default, queries without start time are limited to 30 days
+	defaultRange = 30 * 24 * time.Hour
+
+	defaultInterval = "15m"
+)
+
+var (
+	endpoint string
+
+	invalidLabelChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+	exportPageSize int64 = 1000
+)
+
+func init() {
+	flag.StringVar(&endpoint, "loki-endpoint", "http://loki.kubesphere-logging-system.svc:3100", "Loki endpoint, used when logging backend is loki.")
+
+	es.RegisterBackend(BackendLoki, func() es.Backend {
+		return NewClient(endpoint)
+	})
+}
+
+// Client queries logs with LogQL
+type Client struct {
+	endpoint string
+	client   *http.Client
+}
+
+func NewClient(endpoint string) *Client {
+	return &Client{endpoint: strings.TrimSuffix(endpoint, "/"), client: &http.Client{Timeout: 60 * time.Second}}
+}
+
+type response struct {
+	Status string `json:"status"`
+	Data   struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	} `json:"data"`
+}
+
+type stream struct {
+	Stream map[string]string `json:"stream"`
+	// pairs of timestamp in nanoseconds and log line
+	Values [][2]string `json:"values"`
+}
+
+type sample struct {
+	Metric map[string]string `json:"metric"`
+	// pair of timestamp in seconds and value of instant queries
+	Value []interface{} `json:"value"`
+	// pairs of range queries
+	Values [][]interface{} `json:"values"`
+}
+
+// entry is a log line with the labels of its stream
+type entry struct {
+	timestamp int64
+	labels    map[string]string
+	line      string
+}
+
+type query struct {
+	selector string
+	filter   string
+	// parsed is set if lines are parsed by the json stage for structured fields
+	parsed bool
+	start  time.Time
+	end    time.Time
+	// creation time in milliseconds of namespaces, logs of deleted namespaces with the same name are excluded
+	namespaceCreationTime map[string]int64
+}
+
+func (q *query) logQL() string {
+	return q.selector + q.filter
+}
+
+// rangeLogQL counts logs of the query in the range
+func (q *query) countLogQL(rng time.Duration) string {
+	seconds := int64(rng / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return fmt.Sprintf("count_over_time(%s[%ds])", q.logQL(), seconds)
+}
+
+func regexAlternation(values []string, fuzzy bool) string {
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			quoted = append(quoted, regexp.QuoteMeta(value))
+		}
+	}
+	if fuzzy {
+		return fmt.Sprintf("(?i).*(%s).*", strings.Join(quoted, "|"))
+	}
+	return strings.Join(quoted, "|")
+}
+
+func keywords(value string) []string {
+	return strings.Fields(strings.Replace(value, ",", " ", -1))
+}
+
+// newQuery translates parameters to a stream selector and line filter, false is returned if nothing matches
+func newQuery(param es.QueryParameters) (*query, bool) {
+	q := &query{namespaceCreationTime: make(map[string]int64)}
+
+	matchers := make([]string, 0)
+
+	if param.NamespaceFilled {
+		if len(param.NamespaceWithCreationTime) == 0 {
+			return nil, false
+		}
+		namespaces := make([]string, 0, len(param.NamespaceWithCreationTime))
+		for namespace, creationTime := range param.NamespaceWithCreationTime {
+			namespaces = append(namespaces, namespace)
+			q.namespaceCreationTime[namespace], _ = strconv.ParseInt(creationTime, 10, 64)
+		}
+		sort.Strings(namespaces)
+		matchers = append(matchers, fmt.Sprintf("%s=~%s", labelNamespace, strconv.Quote(regexAlternation(namespaces, false))))
+	}
+
+	if param.PodFilled {
+		if len(param.Pods) == 0 {
+			return nil, false
+		}
+		matchers = append(matchers, fmt.Sprintf("%s=~%s", labelPod, strconv.Quote(regexAlternation(param.Pods, false))))
+	}
+
+	if param.ContainerFilled {
+		if len(param.Containers) == 0 {
+			return nil, false
+		}
+		matchers = append(matchers, fmt.Sprintf("%s=~%s", labelContainer, strconv.Quote(regexAlternation(param.Containers, false))))
+	}
+
+	for label, value := range map[string]string{labelNamespace: param.NamespaceQuery, labelPod: param.PodQuery, labelContainer: param.ContainerQuery} {
+		if words := keywords(value); len(words) > 0 {
+			matchers = append(matchers, fmt.Sprintf("%s=~%s", label, strconv.Quote(regexAlternation(words, true))))
+		}
+	}
+
+	// the order of fuzzy matchers comes from the map
+	sort.Strings(matchers[len(matchers)-countFuzzy(param):])
+
+	// loki requires at least one matcher which doesn't match empty values
+	if len(matchers) == 0 {
+		matchers = append(matchers, fmt.Sprintf(`%s=~".+"`, labelNamespace))
+	}
+
+	q.selector = fmt.Sprintf("{%s}", strings.Join(matchers, ", "))
+
+	if words := keywords(param.LogQuery); len(words) > 0 {
+		q.filter = fmt.Sprintf(" |~ %s", strconv.Quote(fmt.Sprintf("(?i)(%s)", regexAlternation(words, false))))
+	}
+
+	for _, filters := range es.GroupFieldFilters(param.FieldFilters) {
+		q.parse()
+		if len(filters) == 1 {
+			q.filter += fmt.Sprintf(" | %s=%s", fieldLabel(filters[0].Field), strconv.Quote(filters[0].Value))
+			continue
+		}
+
+		values := make([]string, 0, len(filters))
+		for _, filter := range filters {
+			values = append(values, filter.Value)
+		}
+		q.filter += fmt.Sprintf(" | %s=~%s", fieldLabel(filters[0].Field), strconv.Quote(regexAlternation(values, false)))
+	}
+
+	q.end = time.Now()
+	if param.EndTime != "" {
+		if end, ok := parseTime(param.EndTime); ok {
+			q.end = end
+		}
+	}
+
+	q.start = q.end.Add(-defaultRange)
+	if param.StartTime != "" {
+		if start, ok := parseTime(param.StartTime); ok {
+			q.start = start
+		}
+	}
+
+	return q, true
+}
+
+// parse adds the json stage, which extracts keys of JSON lines as labels
+func (q *query) parse() {
+	if !q.parsed {
+		q.filter += " | json"
+		q.parsed = true
+	}
+}
+
+// fieldLabel returns the label extracted by the json stage, nested keys are joined and
+// characters not allowed in label names are replaced by underscores
+func fieldLabel(field string) string {
+	return invalidLabelChars.ReplaceAllString(field, "_")
+}
+
+func countFuzzy(param es.QueryParameters) int {
+	count := 0
+	for _, value := range []string{param.NamespaceQuery, param.PodQuery, param.ContainerQuery} {
+		if len(keywords(value)) > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// parseTime accepts milliseconds since the epoch or RFC3339, the same as the elasticsearch backend
+func parseTime(value string) (time.Time, bool) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, true
+	}
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(0, ms*int64(time.Millisecond)), true
+	}
+	return time.Time{}, false
+}
+
+// timestamp returns milliseconds of the time, 0 if it's invalid
+func timestamp(value string) int64 {
+	if t, ok := parseTime(value); ok {
+		return t.UnixNano() / int64(time.Millisecond)
+	}
+	return 0
+}
+
+// parseInterval parses intervals of elasticsearch, [0-9]+[smhdwMqy], months, quarters and years are of fixed length
+func parseInterval(interval string) (time.Duration, error) {
+	units := map[byte]time.Duration{
+		's': time.Second,
+		'm': time.Minute,
+		'h': time.Hour,
+		'd': 24 * time.Hour,
+		'w': 7 * 24 * time.Hour,
+		'M': 30 * 24 * time.Hour,
+		'q': 90 * 24 * time.Hour,
+		'y': 365 * 24 * time.Hour,
+	}
+
+	if len(interval) < 2 {
+		return 0, fmt.Errorf("invalid interval %s", interval)
+	}
+
+	unit, ok := units[interval[len(interval)-1]]
+	n, err := strconv.Atoi(interval[:len(interval)-1])
+	if !ok || err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid interval %s", interval)
+	}
+
+	return time.Duration(n) * unit, nil
+}
+
+func (c *Client) get(path string, values url.Values, result interface{}) error {
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s%s?%s", c.endpoint, path, values.Encode()), nil)
+	if err != nil {
+		return err
+	}
+
+	response, err := c.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	data, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("loki request failed with status %d: %s", response.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	return json.Unmarshal(data, result)
+}
+
+// queryRange returns at most limit entries of the query, sorted by time in the direction
+func (c *Client) queryRange(q *query, limit int64, direction string) ([]entry, error) {
+	values := url.Values{}
+	values.Set("query", q.logQL())
+	values.Set("start", strconv.FormatInt(q.start.UnixNano(), 10))
+	values.Set("end", strconv.FormatInt(q.end.UnixNano(), 10))
+	values.Set("limit", strconv.FormatInt(limit, 10))
+	values.Set("direction", direction)
+
+	var resp response
+	if err := c.get("/loki/api/v1/query_range", values, &resp); err != nil {
+		return nil, err
+	}
+
+	var streams []stream
+	if err := json.Unmarshal(resp.Data.Result, &streams); err != nil {
+		return nil, err
+	}
+
+	entries := make([]entry, 0)
+	for _, s := range streams {
+		for _, value := range s.Values {
+			timestamp, err := strconv.ParseInt(value[0], 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, entry{timestamp: timestamp, labels: s.Stream, line: value[1]})
+		}
+	}
+
+	// entries of streams are merged, ties are broken by labels and line to keep the order stable
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].timestamp != entries[j].timestamp {
+			if direction == "forward" {
+				return entries[i].timestamp < entries[j].timestamp
+			}
+			return entries[i].timestamp > entries[j].timestamp
+		}
+		return entryID(entries[i]) < entryID(entries[j])
+	})
+
+	if int64(len(entries)) > limit {
+		entries = entries[:limit]
+	}
+
+	return entries, nil
+}
+
+// instant returns the value of the metric query at the time
+func (c *Client) instant(logQL string, at time.Time) (int64, error) {
+	samples, err := c.vector(logQL, at)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(samples) == 0 || len(samples[0].Value) != 2 {
+		return 0, nil
+	}
+
+	return sampleValue(samples[0].Value[1])
+}
+
+// vector returns all samples of the metric query at the time
+func (c *Client) vector(logQL string, at time.Time) ([]sample, error) {
+	values := url.Values{}
+	values.Set("query", logQL)
+	values.Set("time", strconv.FormatInt(at.UnixNano(), 10))
+
+	var resp response
+	if err := c.get("/loki/api/v1/query", values, &resp); err != nil {
+		return nil, err
+	}
+
+	var samples []sample
+	if err := json.Unmarshal(resp.Data.Result, &samples); err != nil {
+		return nil, err
+	}
+
+	return samples, nil
+}
+
+func sampleValue(value interface{}) (int64, error) {
+	s, ok := value.(string)
+	if !ok {
+		return 0, fmt.Errorf("invalid sample value %v", value)
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	return int64(f), nil
+}
+
+func entryID(e entry) string {
+	keys := make([]string, 0, len(e.labels))
+	for key := range e.labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	hash := fnv.New64a()
+	for _, key := range keys {
+		fmt.Fprintf(hash, "%s=%s,", key, e.labels[key])
+	}
+	hash.Write([]byte(e.line))
+
+	return fmt.Sprintf("%d-%x", e.timestamp, hash.Sum64())
+}
+
+// toRecord converts the entry, lines written by fluent bit with line_format json are decoded
+func toRecord(e entry) es.LogRecord {
+	record := es.LogRecord{
+		ID:        entryID(e),
+		Time:      e.timestamp / int64(time.Millisecond),
+		Log:       e.line,
+		Namespace: e.labels[labelNamespace],
+		Pod:       e.labels[labelPod],
+		Container: e.labels[labelContainer],
+		Host:      e.labels[labelHost],
+	}
+
+	if strings.HasPrefix(e.line, "{") {
+		var source es.Source
+		if err := json.Unmarshal([]byte(e.line), &source); err == nil && source.Log != "" {
+			record.Log = source.Log
+			record.Fields = source.Fields
+			if record.Namespace == "" {
+				record.Namespace = source.Kubernetes.Namespace
+			}
+			if record.Pod == "" {
+				record.Pod = source.Kubernetes.Pod
+			}
+			if record.Container == "" {
+				record.Container = source.Kubernetes.Container
+			}
+			if record.Host == "" {
+				record.Host = source.Kubernetes.Host
+			}
+		}
+	}
+
+	return record
+}
+
+// visible excludes logs of namespaces written before the namespace was created,
+// metric queries can't tell them apart and count all of them
+func (q *query) visible(record es.LogRecord) bool {
+	creationTime, ok := q.namespaceCreationTime[record.Namespace]
+	return !ok || record.Time >= creationTime
+}
+
+func direction(param es.QueryParameters, defaultOrder string) string {
+	order := strings.ToLower(param.Sort)
+	if order != "asc" && order != "desc" {
+		order = defaultOrder
+	}
+	if order == "asc" {
+		return "forward"
+	}
+	return "backward"
+}
+
+// Search emulates offset by fetching the records before it, so the offset is limited by max entries of loki
+func (c *Client) Search(param es.QueryParameters) ([]es.LogRecord, error) {
+	records := make([]es.LogRecord, 0)
+
+	q, ok := newQuery(param)
+	if !ok || param.Size <= 0 || param.From+param.Size > maxEntries {
+		return records, nil
+	}
+
+	entries, err := c.queryRange(q, param.From+param.Size, direction(param, "desc"))
+	if err != nil {
+		return nil, err
+	}
+
+	for i := param.From; i < int64(len(entries)); i++ {
+		if record := toRecord(entries[i]); q.visible(record) {
+			records = append(records, record)
+		}
+	}
+
+	return records, nil
+}
+
+func (c *Client) Query(param es.QueryParameters) *es.QueryResult {
+	result, err := c.query(param)
+	if err != nil {
+		glog.Errorln(err)
+		return &es.QueryResult{Status: http.StatusInternalServerError, Error: err.Error()}
+	}
+
+	result.Status = http.StatusOK
+	result.Workspace = param.Workspace
+
+	return result
+}
+
+func (c *Client) query(param es.QueryParameters) (*es.QueryResult, error) {
+	q, ok := newQuery(param)
+
+	switch param.Operation {
+	case "statistics":
+		statistics := &es.StatisticsResult{}
+		if !ok {
+			return &es.QueryResult{Statistics: statistics}, nil
+		}
+
+		logs, err := c.instant(fmt.Sprintf("sum(%s)", q.countLogQL(q.end.Sub(q.start))), q.end)
+		if err != nil {
+			return nil, err
+		}
+
+		containers, err := c.instant(fmt.Sprintf("count(sum by (%s, %s, %s) (%s))", labelNamespace, labelPod, labelContainer, q.countLogQL(q.end.Sub(q.start))), q.end)
+		if err != nil {
+			return nil, err
+		}
+
+		statistics.Logs, statistics.Containers = logs, containers
+		return &es.QueryResult{Statistics: statistics}, nil
+
+	case "histogram":
+		interval := param.Interval
+		if interval == "" {
+			interval = defaultInterval
+		}
+
+		histogram := &es.HistogramResult{StartTime: timestamp(param.StartTime), EndTime: timestamp(param.EndTime), Interval: interval}
+		if !ok {
+			return &es.QueryResult{Histogram: histogram}, nil
+		}
+
+		step, err := parseInterval(interval)
+		if err != nil {
+			return nil, err
+		}
+
+		histograms, err := c.histogram(q, step)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, record := range histograms {
+			histogram.Total += record.Count
+		}
+		histogram.Histograms = histograms
+
+		return &es.QueryResult{Histogram: histogram}, nil
+
+	case "aggregation":
+		aggregation := &es.AggregationResult{Field: param.AggregationField, Buckets: make([]es.AggregationBucket, 0)}
+		if !ok || param.AggregationField == "" {
+			return &es.QueryResult{Aggregation: aggregation}, nil
+		}
+
+		if err := c.aggregate(q, aggregation, es.AggregationSize(param)); err != nil {
+			return nil, err
+		}
+
+		return &es.QueryResult{Aggregation: aggregation}, nil
+
+	default:
+		read := &es.ReadResult{From: param.From, Size: param.Size}
+		if !ok {
+			return &es.QueryResult{Read: read}, nil
+		}
+
+		total, err := c.instant(fmt.Sprintf("sum(%s)", q.countLogQL(q.end.Sub(q.start))), q.end)
+		if err != nil {
+			return nil, err
+		}
+		read.Total = total
+
+		records, err := c.Search(param)
+		if err != nil {
+			return nil, err
+		}
+		if len(records) > 0 {
+			read.Records = records
+		}
+
+		return &es.QueryResult{Read: read}, nil
+	}
+}
+
+// aggregate counts logs of the most frequent values of the field, logs without the field are left out
+func (c *Client) aggregate(q *query, aggregation *es.AggregationResult, size int64) error {
+	label := fieldLabel(aggregation.Field)
+
+	withField := *q
+	withField.parse()
+	withField.filter += fmt.Sprintf(` | %s!=""`, label)
+	count := withField.countLogQL(q.end.Sub(q.start))
+
+	samples, err := c.vector(fmt.Sprintf("topk(%d, sum by (%s) (%s))", size, label, count), q.end)
+	if err != nil {
+		return err
+	}
+
+	total, err := c.instant(fmt.Sprintf("sum(%s)", count), q.end)
+	if err != nil {
+		return err
+	}
+
+	aggregation.Others = total
+	for _, s := range samples {
+		if len(s.Value) != 2 {
+			continue
+		}
+		value, err := sampleValue(s.Value[1])
+		if err != nil {
+			return err
+		}
+		aggregation.Buckets = append(aggregation.Buckets, es.AggregationBucket{Value: s.Metric[label], Count: value})
+		aggregation.Others -= value
+	}
+
+	// topk doesn't sort the samples, ties are broken by value to keep the order stable
+	sort.Slice(aggregation.Buckets, func(i, j int) bool {
+		if aggregation.Buckets[i].Count != aggregation.Buckets[j].Count {
+			return aggregation.Buckets[i].Count > aggregation.Buckets[j].Count
+		}
+		return aggregation.Buckets[i].Value < aggregation.Buckets[j].Value
+	})
+
+	return nil
+}
+
+// histogram counts logs of each step, buckets are keyed by the start time like date_histogram of elasticsearch
+func (c *Client) histogram(q *query, step time.Duration) ([]es.HistogramRecord, error) {
+	return c.rangeHistogram(fmt.Sprintf("sum(%s)", q.countLogQL(step)), q, step)
+}
+
+// rangeHistogram evaluates the metric query of the step at the end of each bucket in the range of the query
+func (c *Client) rangeHistogram(logQL string, q *query, step time.Duration) ([]es.HistogramRecord, error) {
+	seconds := int64(step / time.Second)
+
+	values := url.Values{}
+	values.Set("query", logQL)
+	// samples are at the end of buckets
+	values.Set("start", strconv.FormatInt(q.start.Add(step).UnixNano(), 10))
+	values.Set("end", strconv.FormatInt(q.end.UnixNano(), 10))
+	values.Set("step", fmt.Sprintf("%ds", seconds))
+
+	var resp response
+	if err := c.get("/loki/api/v1/query_range", values, &resp); err != nil {
+		return nil, err
+	}
+
+	var samples []sample
+	if err := json.Unmarshal(resp.Data.Result, &samples); err != nil {
+		return nil, err
+	}
+
+	histograms := make([]es.HistogramRecord, 0)
+	if len(samples) == 0 {
+		return histograms, nil
+	}
+
+	for _, value := range samples[0].Values {
+		if len(value) != 2 {
+			continue
+		}
+		timestamp, ok := value[0].(float64)
+		if !ok {
+			return nil, fmt.Errorf("invalid sample time %v", value[0])
+		}
+		count, err := sampleValue(value[1])
+		if err != nil {
+			return nil, err
+		}
+		histograms = append(histograms, es.HistogramRecord{Time: (int64(timestamp) - seconds) * 1000, Count: count})
+	}
+
+	return histograms, nil
+}
+
+// Export pages through the time range, the next page starts from the timestamp of the last entry,
+// entries of the timestamp already sent are skipped
+func (c *Client) Export(param es.QueryParameters, handler es.RecordHandler) error {
+	q, ok := newQuery(param)
+	if !ok {
+		return nil
+	}
+
+	dir := direction(param, "asc")
+	sent := make(map[string]bool)
+
+	for {
+		entries, err := c.queryRange(q, exportPageSize, dir)
+		if err != nil {
+			return err
+		}
+
+		progressed := false
+
+		for _, e := range entries {
+			id := entryID(e)
+			if sent[id] {
+				continue
+			}
+
+			if record := toRecord(e); q.visible(record) {
+				if err := handler(record); err != nil {
+					return err
+				}
+			}
+
+			progressed = true
+
+			// only ids of the boundary timestamp are needed to skip entries of the next page
+			last := entries[len(entries)-1].timestamp
+			if e.timestamp == last {
+				sent[id] = true
+			}
+		}
+
+		if int64(len(entries)) < exportPageSize || !progressed {
+			return nil
+		}
+
+		boundary := entries[len(entries)-1].timestamp
+		for id := range sent {
+			if !strings.HasPrefix(id, strconv.FormatInt(boundary, 10)+"-") {
+				delete(sent, id)
+			}
+		}
+
+		// start is inclusive and end is exclusive
+		if dir == "forward" {
+			q.start = time.Unix(0, boundary)
+		} else {
+			q.end = time.Unix(0, boundary+1)
+		}
+	}
+}
@@ -0,0 +1,337 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package loki
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	es "kubesphere.io/kubesphere/pkg/simple/client/elasticsearch"
+)
+
+// fakeLoki replays responses recorded in testdata, or generated responses starting with {
+type fakeLoki struct {
+	sync.Mutex
+	t       *testing.T
+	queries []url.Values
+	// bodies of push requests
+	bodies []string
+	// responses by path, consumed in order
+	responses map[string][]string
+}
+
+func (f *fakeLoki) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+
+	f.queries = append(f.queries, r.URL.Query())
+	if r.Method == http.MethodPost {
+		body, _ := ioutil.ReadAll(r.Body)
+		f.bodies = append(f.bodies, string(body))
+	}
+
+	responses := f.responses[r.URL.Path]
+	if len(responses) == 0 {
+		f.t.Errorf("unexpected request %s", r.URL.RequestURI())
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	f.responses[r.URL.Path] = responses[1:]
+
+	if strings.HasPrefix(responses[0], "{") {
+		w.Write([]byte(responses[0]))
+		return
+	}
+
+	fixture, err := ioutil.ReadFile(filepath.Join("testdata", responses[0]))
+	if err != nil {
+		f.t.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(fixture)
+}
+
+func startFakeLoki(t *testing.T, responses map[string][]string) (*fakeLoki, *Client, func()) {
+	fake := &fakeLoki{t: t, responses: responses}
+	server := httptest.NewServer(fake)
+	return fake, NewClient(server.URL), server.Close
+}
+
+// streams generates a streams response of a pod, each value is a pair of timestamp and line
+func streams(values ...[2]string) string {
+	data, _ := json.Marshal(map[string]interface{}{
+		"status": "success",
+		"data": map[string]interface{}{
+			"resultType": "streams",
+			"result":     []interface{}{map[string]interface{}{"stream": map[string]string{"namespace_name": "default", "pod_name": "web-1"}, "values": values}},
+		},
+	})
+	return string(data)
+}
+
+func param() es.QueryParameters {
+	return es.QueryParameters{
+		NamespaceFilled:           true,
+		NamespaceWithCreationTime: map[string]string{"default": "1571400001500", "kube-system": "1571300000000"},
+		StartTime:                 "1571400000000",
+		EndTime:                   "1571400010000",
+		LogQuery:                  "GET,query",
+		From:                      0,
+		Size:                      10,
+	}
+}
+
+func TestNewQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		param    es.QueryParameters
+		expected string
+		ok       bool
+	}{
+		{
+			name:     "namespaces and keywords",
+			param:    param(),
+			expected: `{namespace_name=~"default|kube-system"} |~ "(?i)(GET|query)"`,
+			ok:       true,
+		},
+		{
+			name:     "fuzzy queries",
+			param:    es.QueryParameters{PodFilled: true, Pods: []string{"web-1", "web.2"}, PodQuery: "web", ContainerQuery: "nginx,envoy"},
+			expected: `{pod_name=~"web-1|web\\.2", container_name=~"(?i).*(nginx|envoy).*", pod_name=~"(?i).*(web).*"}`,
+			ok:       true,
+		},
+		{
+			name:     "no matchers",
+			param:    es.QueryParameters{},
+			expected: `{namespace_name=~".+"}`,
+			ok:       true,
+		},
+		{
+			name:     "structured fields",
+			param:    es.QueryParameters{LogQuery: "timeout", FieldFilters: []es.FieldFilter{{Field: "level", Value: "error"}, {Field: "http.status", Value: "504"}, {Field: "level", Value: "warn"}}},
+			expected: `{namespace_name=~".+"} |~ "(?i)(timeout)" | json | level=~"error|warn" | http_status="504"`,
+			ok:       true,
+		},
+		{
+			name:  "no namespaces",
+			param: es.QueryParameters{NamespaceFilled: true},
+		},
+	}
+
+	for _, test := range tests {
+		q, ok := newQuery(test.param)
+		if ok != test.ok {
+			t.Errorf("%s: expected ok %v, got %v", test.name, test.ok, ok)
+			continue
+		}
+		if ok && q.logQL() != test.expected {
+			t.Errorf("%s: expected %s, got %s", test.name, test.expected, q.logQL())
+		}
+	}
+}
+
+func TestQuery(t *testing.T) {
+	fake, client, stop := startFakeLoki(t, map[string][]string{
+		"/loki/api/v1/query":       {"query-count.json"},
+		"/loki/api/v1/query_range": {"query_range-streams.json"},
+	})
+	defer stop()
+
+	result := client.Query(param())
+	if result.Status != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", result.Status, result.Error)
+	}
+
+	// logs of default before the namespace was created are excluded
+	expected := []es.LogRecord{
+		{Time: 1571400003000, Log: "GET /index.html 200", Namespace: "default", Pod: "web-1", Container: "nginx", Host: "node1"},
+		{Time: 1571400002000, Log: "query ok\n", Namespace: "kube-system", Pod: "coredns-0", Container: "coredns", Host: "node2"},
+	}
+	for i := range result.Read.Records {
+		result.Read.Records[i].ID = ""
+	}
+	if result.Read.Total != 42 || !reflect.DeepEqual(result.Read.Records, expected) {
+		t.Errorf("unexpected result %d %+v", result.Read.Total, result.Read.Records)
+	}
+
+	if query := fake.queries[0].Get("query"); query != `sum(count_over_time({namespace_name=~"default|kube-system"} |~ "(?i)(GET|query)"[10s]))` {
+		t.Errorf("unexpected count query %s", query)
+	}
+	if q := fake.queries[1]; q.Get("direction") != "backward" || q.Get("limit") != "10" || q.Get("start") != "1571400000000000000" || q.Get("end") != "1571400010000000000" {
+		t.Errorf("unexpected range query %v", q)
+	}
+}
+
+func TestStatistics(t *testing.T) {
+	fake, client, stop := startFakeLoki(t, map[string][]string{
+		"/loki/api/v1/query": {"query-count.json", "query-containers.json"},
+	})
+	defer stop()
+
+	p := param()
+	p.Operation = "statistics"
+
+	result := client.Query(p)
+	if result.Status != http.StatusOK || result.Statistics.Logs != 42 || result.Statistics.Containers != 3 {
+		t.Errorf("unexpected result %+v %+v", result, result.Statistics)
+	}
+
+	if query := fake.queries[1].Get("query"); !strings.HasPrefix(query, "count(sum by (namespace_name, pod_name, container_name) (count_over_time(") {
+		t.Errorf("unexpected containers query %s", query)
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	fake, client, stop := startFakeLoki(t, map[string][]string{
+		"/loki/api/v1/query_range": {"query_range-matrix.json"},
+	})
+	defer stop()
+
+	p := param()
+	p.Operation = "histogram"
+	p.Interval = "15m"
+
+	result := client.Query(p)
+	if result.Status != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", result.Status, result.Error)
+	}
+
+	expected := &es.HistogramResult{
+		Total:      12,
+		StartTime:  1571400000000,
+		EndTime:    1571400010000,
+		Interval:   "15m",
+		Histograms: []es.HistogramRecord{{Time: 1571400000000, Count: 5}, {Time: 1571400900000, Count: 7}},
+	}
+	if !reflect.DeepEqual(result.Histogram, expected) {
+		t.Errorf("expected %+v, got %+v", expected, result.Histogram)
+	}
+
+	if step := fake.queries[0].Get("step"); step != "900s" {
+		t.Errorf("expected step 900s, got %s", step)
+	}
+}
+
+func TestAggregation(t *testing.T) {
+	fake, client, stop := startFakeLoki(t, map[string][]string{
+		"/loki/api/v1/query": {"query-topk.json", "query-count.json"},
+	})
+	defer stop()
+
+	p := param()
+	p.Operation = "aggregation"
+	p.AggregationField = "level"
+	p.Size = 2
+
+	result := client.Query(p)
+	expected := &es.AggregationResult{Field: "level", Others: 3, Buckets: []es.AggregationBucket{{Value: "info", Count: 27}, {Value: "error", Count: 12}}}
+	if result.Status != http.StatusOK || !reflect.DeepEqual(result.Aggregation, expected) {
+		t.Errorf("expected aggregation %+v, got %+v %+v", expected, result, result.Aggregation)
+	}
+
+	if query := fake.queries[0].Get("query"); query != `topk(2, sum by (level) (count_over_time({namespace_name=~"default|kube-system"} |~ "(?i)(GET|query)" | json | level!=""[10s])))` {
+		t.Errorf("unexpected aggregation query %s", query)
+	}
+}
+
+func TestQueryFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "too many outstanding requests", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	result := NewClient(server.URL).Query(param())
+	if result.Status != http.StatusInternalServerError || !strings.Contains(result.Error, "too many outstanding requests") {
+		t.Errorf("expected internal server error, got %+v", result)
+	}
+}
+
+func TestExport(t *testing.T) {
+	size := exportPageSize
+	exportPageSize = 2
+	defer func() { exportPageSize = size }()
+
+	fake, client, stop := startFakeLoki(t, map[string][]string{
+		"/loki/api/v1/query_range": {
+			streams([2]string{"1571400002000000000", "a"}, [2]string{"1571400003000000000", "b"}),
+			streams([2]string{"1571400003000000000", "b"}, [2]string{"1571400004000000000", "c"}),
+			streams([2]string{"1571400004000000000", "c"}),
+		},
+	})
+	defer stop()
+
+	logs := make([]string, 0)
+	err := client.Export(param(), func(record es.LogRecord) error {
+		logs = append(logs, record.Log)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(logs, []string{"a", "b", "c"}) {
+		t.Errorf("unexpected logs %v", logs)
+	}
+
+	starts := make([]string, 0)
+	for _, q := range fake.queries {
+		if q.Get("direction") != "forward" {
+			t.Errorf("expected forward direction, got %s", q.Get("direction"))
+		}
+		starts = append(starts, q.Get("start"))
+	}
+	if !reflect.DeepEqual(starts, []string{"1571400000000000000", "1571400003000000000", "1571400004000000000"}) {
+		t.Errorf("unexpected start times %v", starts)
+	}
+}
+
+func TestParseInterval(t *testing.T) {
+	tests := map[string]string{
+		"30s": "30s",
+		"15m": "15m0s",
+		"1d":  "24h0m0s",
+		"1M":  "720h0m0s",
+		"1":   "",
+		"0h":  "",
+		"5x":  "",
+	}
+
+	for interval, expected := range tests {
+		d, err := parseInterval(interval)
+		if expected == "" {
+			if err == nil {
+				t.Errorf("%s: expected error", interval)
+			}
+			continue
+		}
+		if err != nil || d.String() != expected {
+			t.Errorf("%s: expected %s, got %s %v", interval, expected, d, err)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package metering
+
+import (
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"kubesphere.io/kubesphere/pkg/apis/monitoring/v1alpha1"
+)
+
+// Resource usage is metered by ks-apiserver for reports of days without rollups, and rolled up daily by
+// ks-controller-manager, both meter it the same way.
+
+const (
+	resourceCPU     = "cpu"
+	resourceMemory  = "memory"
+	resourceStorage = "storage"
+	resourceNetwork = "network"
+	resourceGPU     = "gpu"
+
+	gib = 1 << 30
+
+	// ExcludedVirtualNetworkInterfaces excludes virtual interfaces from network usage
+	ExcludedVirtualNetworkInterfaces = `interface!~"^(cali.+|tunl.+|dummy.+|kube.+|flannel.+|cni.+|docker.+|veth.+|lo.*)"`
+)
+
+// query is a range query of the usage of a resource at the namespace or workload level,
+// series of namespaces carry the workspace label of namespaces
+type query struct {
+	level    string
+	resource string
+	expr     string
+}
+
+// $1 is replaced with the GPU resource
+var queries = []query{
+	{v1alpha1.MeteringLevelNamespace, resourceCPU, `sum by (namespace, label_kubesphere_io_workspace) (namespace:container_cpu_usage_seconds_total:sum_rate{namespace!=""})`},
+	{v1alpha1.MeteringLevelNamespace, resourceMemory, `sum by (namespace, label_kubesphere_io_workspace) (namespace:container_memory_usage_bytes_wo_cache:sum{namespace!=""})`},
+	{v1alpha1.MeteringLevelNamespace, resourceStorage, `sum by (namespace) (kube_persistentvolumeclaim_resource_requests_storage_bytes{namespace!=""}) * on (namespace) group_left(label_kubesphere_io_workspace)(kube_namespace_labels)`},
+	{v1alpha1.MeteringLevelNamespace, resourceNetwork, `(sum by (namespace) (rate(container_network_transmit_bytes_total{namespace!="", pod_name!="", ` + ExcludedVirtualNetworkInterfaces + `, job="kubelet"}[5m])) + sum by (namespace) (rate(container_network_receive_bytes_total{namespace!="", pod_name!="", ` + ExcludedVirtualNetworkInterfaces + `, job="kubelet"}[5m]))) * on (namespace) group_left(label_kubesphere_io_workspace)(kube_namespace_labels)`},
+	{v1alpha1.MeteringLevelNamespace, resourceGPU, `sum by (namespace) (kube_pod_container_resource_requests{namespace!="", resource="$1"}) * on (namespace) group_left(label_kubesphere_io_workspace)(kube_namespace_labels)`},
+	{v1alpha1.MeteringLevelWorkload, resourceCPU, `namespace:workload_cpu_usage:sum{namespace!=""}`},
+	{v1alpha1.MeteringLevelWorkload, resourceMemory, `namespace:workload_memory_usage_wo_cache:sum{namespace!=""}`},
+	{v1alpha1.MeteringLevelWorkload, resourceNetwork, `namespace:workload_net_bytes_transmitted:sum_irate{namespace!=""} + namespace:workload_net_bytes_received:sum_irate{namespace!=""}`},
+}
+
+// series is the result of a metering query
+type series struct {
+	query  query
+	matrix model.Matrix
+}
+
+// Querier runs range queries of Prometheus with the parameters
+type Querier func(expr string, params url.Values) (model.Matrix, error)
+
+// Meter integrates resource usage from Prometheus
+type Meter struct {
+	Query Querier
+	// Step is the resolution of integrating usage, network usage of namespaces is integrated from rates over 5m
+	Step time.Duration
+	// GPUResource is the resource label of GPUs in kube_pod_container_resource_requests
+	GPUResource string
+	// Timeout is the evaluation timeout of queries
+	Timeout time.Duration
+}
+
+// MeterUsage integrates resource usage of namespaces and workloads from start to end,
+// and aggregates usage of workspaces from their namespaces
+func (m *Meter) MeterUsage(start, end time.Time) ([]v1alpha1.MeteringEntry, error) {
+	// a sample covers the step before it
+	first := start.Add(m.Step)
+	if first.After(end) {
+		return nil, nil
+	}
+
+	values := url.Values{}
+	values.Set("start", formatTime(first))
+	values.Set("end", formatTime(end))
+	values.Set("step", strconv.FormatFloat(m.Step.Seconds(), 'f', -1, 64))
+	values.Set("timeout", model.Duration(m.Timeout).String())
+
+	results := make([]series, len(queries))
+	errs := make([]error, len(queries))
+	var wg sync.WaitGroup
+
+	for i, q := range queries {
+		wg.Add(1)
+		go func(i int, q query) {
+			defer wg.Done()
+			results[i].query = q
+			results[i].matrix, errs[i] = m.Query(strings.Replace(q.expr, "$1", m.GPUResource, -1), values)
+		}(i, q)
+	}
+
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return buildEntries(results, m.Step), nil
+}
+
+func formatTime(t time.Time) string {
+	return strconv.FormatFloat(float64(t.UnixNano())/float64(time.Second), 'f', -1, 64)
+}
+
+// buildEntries integrates values of series over the step, workloads and workspaces
+// take the workspace labels of their namespaces
+func buildEntries(results []series, step time.Duration) []v1alpha1.MeteringEntry {
+	namespaces := make(map[string]*v1alpha1.MeteringEntry)
+	workloads := make(map[string]*v1alpha1.MeteringEntry)
+
+	for _, item := range results {
+		for _, stream := range item.matrix {
+			namespace := string(stream.Metric["namespace"])
+			if namespace == "" {
+				continue
+			}
+
+			var integral float64
+			for _, sample := range stream.Values {
+				if value := float64(sample.Value); !math.IsNaN(value) && !math.IsInf(value, 0) {
+					integral += value * step.Seconds()
+				}
+			}
+
+			var entry *v1alpha1.MeteringEntry
+			if item.query.level == v1alpha1.MeteringLevelNamespace {
+				entry = namespaces[namespace]
+				if entry == nil {
+					entry = &v1alpha1.MeteringEntry{Level: v1alpha1.MeteringLevelNamespace, Namespace: namespace}
+					namespaces[namespace] = entry
+				}
+				if workspace := string(stream.Metric["label_kubesphere_io_workspace"]); workspace != "" {
+					entry.Workspace = workspace
+				}
+			} else {
+				workload := string(stream.Metric["workload"])
+				if workload == "" {
+					continue
+				}
+				key := namespace + "/" + workload
+				entry = workloads[key]
+				if entry == nil {
+					entry = &v1alpha1.MeteringEntry{Level: v1alpha1.MeteringLevelWorkload, Namespace: namespace, Workload: workload}
+					workloads[key] = entry
+				}
+			}
+
+			accumulateUsage(&entry.Usage, item.query.resource, integral)
+		}
+	}
+
+	workspaces := make(map[string]*v1alpha1.MeteringEntry)
+	for _, entry := range namespaces {
+		if entry.Workspace == "" {
+			continue
+		}
+		workspace := workspaces[entry.Workspace]
+		if workspace == nil {
+			workspace = &v1alpha1.MeteringEntry{Level: v1alpha1.MeteringLevelWorkspace, Workspace: entry.Workspace}
+			workspaces[entry.Workspace] = workspace
+		}
+		workspace.Usage = AddUsage(workspace.Usage, entry.Usage)
+	}
+
+	entries := make([]v1alpha1.MeteringEntry, 0, len(workspaces)+len(namespaces)+len(workloads))
+	for _, entry := range workspaces {
+		entries = append(entries, *entry)
+	}
+	for _, entry := range namespaces {
+		entries = append(entries, *entry)
+	}
+	for _, entry := range workloads {
+		if namespace, ok := namespaces[entry.Namespace]; ok {
+			entry.Workspace = namespace.Workspace
+		}
+		entries = append(entries, *entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return LessEntry(entries[i], entries[j])
+	})
+
+	return entries
+}
+
+func accumulateUsage(usage *v1alpha1.ResourceUsage, resource string, integral float64) {
+	switch resource {
+	case resourceCPU:
+		usage.CPUCoreHours += integral / 3600
+	case resourceMemory:
+		usage.MemoryGiBHours += integral / 3600 / gib
+	case resourceStorage:
+		usage.StorageGiBHours += integral / 3600 / gib
+	case resourceNetwork:
+		usage.NetworkGiB += integral / gib
+	case resourceGPU:
+		usage.GPUHours += integral / 3600
+	}
+}
+
+func AddUsage(a, b v1alpha1.ResourceUsage) v1alpha1.ResourceUsage {
+	return v1alpha1.ResourceUsage{
+		CPUCoreHours:    a.CPUCoreHours + b.CPUCoreHours,
+		MemoryGiBHours:  a.MemoryGiBHours + b.MemoryGiBHours,
+		StorageGiBHours: a.StorageGiBHours + b.StorageGiBHours,
+		NetworkGiB:      a.NetworkGiB + b.NetworkGiB,
+		GPUHours:        a.GPUHours + b.GPUHours,
+	}
+}
+
+// LessEntry orders entries by level, workspace, namespace and workload
+func LessEntry(a, b v1alpha1.MeteringEntry) bool {
+	levels := map[string]int{v1alpha1.MeteringLevelWorkspace: 0, v1alpha1.MeteringLevelNamespace: 1, v1alpha1.MeteringLevelWorkload: 2}
+	if a.Level != b.Level {
+		return levels[a.Level] < levels[b.Level]
+	}
+	if a.Workspace != b.Workspace {
+		return a.Workspace < b.Workspace
+	}
+	if a.Namespace != b.Namespace {
+		return a.Namespace < b.Namespace
+	}
+	return a.Workload < b.Workload
+}
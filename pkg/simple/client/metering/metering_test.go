@@ -0,0 +1,110 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package metering
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"kubesphere.io/kubesphere/pkg/apis/monitoring/v1alpha1"
+)
+
+func TestBuildEntries(t *testing.T) {
+	newSeries := func(level, resource string, labels model.Metric, value float64) series {
+		return series{
+			query: query{level: level, resource: resource},
+			matrix: model.Matrix{
+				&model.SampleStream{Metric: labels, Values: []model.SamplePair{{Timestamp: 1800000, Value: model.SampleValue(value)}, {Timestamp: 3600000, Value: model.SampleValue(value)}}},
+			},
+		}
+	}
+	demo := model.Metric{"namespace": "demo", "label_kubesphere_io_workspace": "ws"}
+	system := model.Metric{"namespace": "kube-system"}
+	web := model.Metric{"namespace": "demo", "workload": "Deployment:web"}
+
+	entries := buildEntries([]series{
+		newSeries(v1alpha1.MeteringLevelNamespace, resourceCPU, demo, 2),
+		newSeries(v1alpha1.MeteringLevelNamespace, resourceMemory, demo, gib),
+		newSeries(v1alpha1.MeteringLevelNamespace, resourceNetwork, demo, 1<<20),
+		newSeries(v1alpha1.MeteringLevelNamespace, resourceGPU, demo, 1),
+		newSeries(v1alpha1.MeteringLevelNamespace, resourceCPU, system, 1),
+		newSeries(v1alpha1.MeteringLevelWorkload, resourceCPU, web, 1),
+	}, 30*time.Minute)
+
+	expected := []v1alpha1.MeteringEntry{
+		{Level: v1alpha1.MeteringLevelWorkspace, Workspace: "ws", Usage: v1alpha1.ResourceUsage{CPUCoreHours: 2, MemoryGiBHours: 1, NetworkGiB: 3.515625, GPUHours: 1}},
+		{Level: v1alpha1.MeteringLevelNamespace, Namespace: "kube-system", Usage: v1alpha1.ResourceUsage{CPUCoreHours: 1}},
+		{Level: v1alpha1.MeteringLevelNamespace, Workspace: "ws", Namespace: "demo", Usage: v1alpha1.ResourceUsage{CPUCoreHours: 2, MemoryGiBHours: 1, NetworkGiB: 3.515625, GPUHours: 1}},
+		{Level: v1alpha1.MeteringLevelWorkload, Workspace: "ws", Namespace: "demo", Workload: "Deployment:web", Usage: v1alpha1.ResourceUsage{CPUCoreHours: 1}},
+	}
+
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("expected %+v, got %+v", expected, entries)
+	}
+}
+
+func TestRollups(t *testing.T) {
+	day := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		entries int
+		shards  int
+	}{
+		{1, 1},
+		{maxRollupEntries, 1},
+		{maxRollupEntries*2 + 1, 3},
+	}
+
+	for i, test := range tests {
+		entries := make([]v1alpha1.MeteringEntry, test.entries)
+		for j := range entries {
+			entries[j] = v1alpha1.MeteringEntry{Level: v1alpha1.MeteringLevelNamespace, Namespace: fmt.Sprintf("ns-%d", j)}
+		}
+
+		rollups := BuildRollups(day, entries)
+		if len(rollups) != test.shards {
+			t.Errorf("case %d: expected %d rollups, got %d", i, test.shards, len(rollups))
+			continue
+		}
+		for j, rollup := range rollups {
+			if name := fmt.Sprintf("daily-2019-06-01-%d", j); rollup.Name != name {
+				t.Errorf("case %d: expected rollup %s, got %s", i, name, rollup.Name)
+			}
+			if rollup.Labels[v1alpha1.MeteringRollupDateLabel] != "2019-06-01" || rollup.Spec.Date != "2019-06-01" || rollup.Spec.Shards != test.shards {
+				t.Errorf("case %d: unexpected rollup %s: labels %v, date %s, shards %d", i, rollup.Name, rollup.Labels, rollup.Spec.Date, rollup.Spec.Shards)
+			}
+			if len(rollup.Spec.Entries) > maxRollupEntries {
+				t.Errorf("case %d: rollup %s has %d entries", i, rollup.Name, len(rollup.Spec.Entries))
+			}
+		}
+
+		merged, complete := MergeRollups(rollups)
+		if !complete || !reflect.DeepEqual(merged, entries) {
+			t.Errorf("case %d: expected all entries merged, got %d %v", i, len(merged), complete)
+		}
+
+		// a day is metered again if any of its rollups is missing
+		if _, complete := MergeRollups(rollups[1:]); complete {
+			t.Errorf("case %d: expected rollups without the first one incomplete", i)
+		}
+	}
+}
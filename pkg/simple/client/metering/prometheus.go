@@ -0,0 +1,71 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package metering
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/prometheus/common/model"
+)
+
+// NewPrometheusQuerier returns the querier of the Prometheus API at the endpoint, eg. http://prometheus:9090/api/v1/
+func NewPrometheusQuerier(endpoint string, client *http.Client) Querier {
+	return func(expr string, params url.Values) (model.Matrix, error) {
+		values := url.Values{}
+		for key, value := range params {
+			values[key] = value
+		}
+		values.Set("query", expr)
+
+		resp, err := client.Get(endpoint + "query_range?" + values.Encode())
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		data, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		return DecodeMatrix(data)
+	}
+}
+
+// DecodeMatrix decodes the response of range queries of Prometheus
+func DecodeMatrix(data []byte) (model.Matrix, error) {
+	var response struct {
+		Status    string `json:"status"`
+		ErrorType string `json:"errorType,omitempty"`
+		Error     string `json:"error,omitempty"`
+		Data      struct {
+			Result model.Matrix `json:"result"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, err
+	}
+	if response.Status != "success" {
+		return nil, fmt.Errorf("%s: %s", response.ErrorType, response.Error)
+	}
+
+	return response.Data.Result, nil
+}
@@ -0,0 +1,91 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package metering
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"kubesphere.io/kubesphere/pkg/apis/monitoring/v1alpha1"
+)
+
+const (
+	rollupPrefix = "daily-"
+
+	// entries of workloads with long names take about 600 bytes, rollups are kept within the 1.5MiB limit of etcd
+	maxRollupEntries = 1000
+)
+
+// Date returns the start of the day of the time in UTC
+func Date(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// RollupSelector selects rollups of the day
+func RollupSelector(day time.Time) labels.Selector {
+	return labels.SelectorFromSet(labels.Set{v1alpha1.MeteringRollupDateLabel: day.Format(v1alpha1.MeteringRollupDateLayout)})
+}
+
+// BuildRollups splits entries of the day into rollups of at most maxRollupEntries entries,
+// rollups are named daily-<date>-<index>
+func BuildRollups(day time.Time, entries []v1alpha1.MeteringEntry) []*v1alpha1.MeteringRollup {
+	date := day.Format(v1alpha1.MeteringRollupDateLayout)
+	shards := (len(entries) + maxRollupEntries - 1) / maxRollupEntries
+
+	rollups := make([]*v1alpha1.MeteringRollup, 0, shards)
+	for i := 0; i < shards; i++ {
+		last := (i + 1) * maxRollupEntries
+		if last > len(entries) {
+			last = len(entries)
+		}
+		rollups = append(rollups, &v1alpha1.MeteringRollup{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   fmt.Sprintf("%s%s-%d", rollupPrefix, date, i),
+				Labels: map[string]string{v1alpha1.MeteringRollupDateLabel: date},
+			},
+			Spec: v1alpha1.MeteringRollupSpec{
+				Date:    date,
+				Shards:  shards,
+				Entries: entries[i*maxRollupEntries : last],
+			},
+		})
+	}
+
+	return rollups
+}
+
+// MergeRollups returns entries of rollups of a day, and whether all rollups of the day exist
+func MergeRollups(rollups []*v1alpha1.MeteringRollup) ([]v1alpha1.MeteringEntry, bool) {
+	if len(rollups) == 0 {
+		return nil, false
+	}
+
+	var entries []v1alpha1.MeteringEntry
+	for _, rollup := range rollups {
+		if rollup.Spec.Shards != len(rollups) {
+			return nil, false
+		}
+		entries = append(entries, rollup.Spec.Entries...)
+	}
+
+	return entries, true
+}
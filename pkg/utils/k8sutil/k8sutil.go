@@ -20,6 +20,8 @@ package k8sutil
 import (
 	"k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/serviceaccount"
+	"k8s.io/apiserver/pkg/authentication/user"
 	"kubesphere.io/kubesphere/pkg/models"
 )
 
@@ -71,3 +73,39 @@ func ContainsUser(subjects interface{}, username string) bool {
 	}
 	return false
 }
+
+// ServiceAccountTokenExtraKey marks identities authenticated by kubernetes TokenReview,
+// only these identities are matched with ServiceAccount subjects
+const ServiceAccountTokenExtraKey = "authentication.kubesphere.io/service-account-token"
+
+// IsServiceAccount reports whether the identity was authenticated with a service account token
+func IsServiceAccount(usr user.Info) bool {
+	return len(usr.GetExtra()[ServiceAccountTokenExtraKey]) > 0
+}
+
+// NewIdentity returns the identity of the username forwarded by the api gateway, kind is ServiceAccount
+// if the identity was authenticated with a service account token, otherwise it's a user
+func NewIdentity(username, kind string) user.Info {
+	identity := &user.DefaultInfo{Name: username}
+	if kind == v1.ServiceAccountKind {
+		identity.Extra = map[string][]string{ServiceAccountTokenExtraKey: {"true"}}
+	}
+	return identity
+}
+
+// ContainsIdentity is the same as ContainsUser, besides it matches ServiceAccount subjects if the identity
+// was authenticated with a service account token, a user named system:serviceaccount:{namespace}:{name}
+// doesn't inherit bindings of the service account
+func ContainsIdentity(subjects []v1.Subject, usr user.Info) bool {
+	if !IsServiceAccount(usr) {
+		return ContainsUser(subjects, usr.GetName())
+	}
+
+	for _, subject := range subjects {
+		if subject.Kind == v1.ServiceAccountKind && serviceaccount.MakeUsername(subject.Namespace, subject.Name) == usr.GetName() {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,67 @@
+/*
+
+ Copyright 2019 The KubeSphere Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+
+*/
+package k8sutil
+
+import (
+	"testing"
+
+	"k8s.io/api/rbac/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
+)
+
+func TestContainsIdentity(t *testing.T) {
+	subjects := []v1.Subject{
+		{Kind: v1.UserKind, Name: "admin"},
+		{Kind: v1.ServiceAccountKind, Namespace: "demo", Name: "builder"},
+		{Kind: v1.GroupKind, Name: "system:serviceaccounts"},
+	}
+
+	serviceAccount := map[string][]string{ServiceAccountTokenExtraKey: {"true"}}
+
+	tests := []struct {
+		user     user.Info
+		expected bool
+	}{
+		{&user.DefaultInfo{Name: "admin"}, true},
+		{&user.DefaultInfo{Name: "system:serviceaccount:demo:builder", Extra: serviceAccount}, true},
+		// users named like service accounts don't inherit their bindings
+		{&user.DefaultInfo{Name: "system:serviceaccount:demo:builder"}, false},
+		{&user.DefaultInfo{Name: "system:serviceaccount:test:builder", Extra: serviceAccount}, false},
+		// service accounts are not matched with user subjects
+		{&user.DefaultInfo{Name: "admin", Extra: serviceAccount}, false},
+		{&user.DefaultInfo{Name: "system:serviceaccounts"}, false},
+	}
+
+	for i, test := range tests {
+		if result := ContainsIdentity(subjects, test.user); result != test.expected {
+			t.Errorf("case %d: expected %t, got %t", i, test.expected, result)
+		}
+	}
+
+	if ContainsUser(subjects, "system:serviceaccount:demo:builder") {
+		t.Errorf("expected ContainsUser to match user subjects only")
+	}
+
+	// identities forwarded by the api gateway
+	if !ContainsIdentity(subjects, NewIdentity("system:serviceaccount:demo:builder", v1.ServiceAccountKind)) {
+		t.Errorf("expected forwarded service accounts to match service account subjects")
+	}
+	if ContainsIdentity(subjects, NewIdentity("system:serviceaccount:demo:builder", v1.UserKind)) {
+		t.Errorf("expected forwarded users not to match service account subjects")
+	}
+}
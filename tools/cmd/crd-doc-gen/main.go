@@ -17,6 +17,12 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	urlruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/kube-openapi/pkg/common"
+	alertinginstall "kubesphere.io/kubesphere/pkg/apis/alerting/crdinstall"
+	alertingv1alpha1 "kubesphere.io/kubesphere/pkg/apis/alerting/v1alpha1"
+	logginginstall "kubesphere.io/kubesphere/pkg/apis/logging/crdinstall"
+	loggingv1alpha1 "kubesphere.io/kubesphere/pkg/apis/logging/v1alpha1"
+	monitoringinstall "kubesphere.io/kubesphere/pkg/apis/monitoring/crdinstall"
+	monitoringv1alpha1 "kubesphere.io/kubesphere/pkg/apis/monitoring/v1alpha1"
 	networkinstall "kubesphere.io/kubesphere/pkg/apis/network/crdinstall"
 	networkv1alpha1 "kubesphere.io/kubesphere/pkg/apis/network/v1alpha1"
 	servicemeshinstall "kubesphere.io/kubesphere/pkg/apis/servicemesh/crdinstall"
@@ -45,6 +51,9 @@ func main() {
 	servicemeshinstall.Install(Scheme)
 	tenantinstall.Install(Scheme)
 	networkinstall.Install(Scheme)
+	logginginstall.Install(Scheme)
+	alertinginstall.Install(Scheme)
+	monitoringinstall.Install(Scheme)
 
 	mapper := meta.NewDefaultRESTMapper(nil)
 
@@ -76,6 +85,30 @@ func main() {
 		networkv1alpha1.SchemeGroupVersion.WithResource(networkv1alpha1.ResourcePluralWorkspaceNetworkPolicy),
 		networkv1alpha1.SchemeGroupVersion.WithResource(networkv1alpha1.ResourceSingularWorkspaceNetworkPolicy), meta.RESTScopeRoot)
 
+	mapper.AddSpecific(loggingv1alpha1.SchemeGroupVersion.WithKind(loggingv1alpha1.ResourceKindLogAlertRule),
+		loggingv1alpha1.SchemeGroupVersion.WithResource(loggingv1alpha1.ResourcePluralLogAlertRule),
+		loggingv1alpha1.SchemeGroupVersion.WithResource(loggingv1alpha1.ResourceSingularLogAlertRule), meta.RESTScopeRoot)
+
+	mapper.AddSpecific(alertingv1alpha1.SchemeGroupVersion.WithKind(alertingv1alpha1.ResourceKindAlertRule),
+		alertingv1alpha1.SchemeGroupVersion.WithResource(alertingv1alpha1.ResourcePluralAlertRule),
+		alertingv1alpha1.SchemeGroupVersion.WithResource(alertingv1alpha1.ResourceSingularAlertRule), meta.RESTScopeNamespace)
+
+	mapper.AddSpecific(alertingv1alpha1.SchemeGroupVersion.WithKind(alertingv1alpha1.ResourceKindAlertReceiver),
+		alertingv1alpha1.SchemeGroupVersion.WithResource(alertingv1alpha1.ResourcePluralAlertReceiver),
+		alertingv1alpha1.SchemeGroupVersion.WithResource(alertingv1alpha1.ResourceSingularAlertReceiver), meta.RESTScopeNamespace)
+
+	mapper.AddSpecific(alertingv1alpha1.SchemeGroupVersion.WithKind(alertingv1alpha1.ResourceKindAlertNotificationPolicy),
+		alertingv1alpha1.SchemeGroupVersion.WithResource(alertingv1alpha1.ResourcePluralAlertNotificationPolicy),
+		alertingv1alpha1.SchemeGroupVersion.WithResource(alertingv1alpha1.ResourceSingularAlertNotificationPolicy), meta.RESTScopeNamespace)
+
+	mapper.AddSpecific(monitoringv1alpha1.SchemeGroupVersion.WithKind(monitoringv1alpha1.ResourceKindDashboard),
+		monitoringv1alpha1.SchemeGroupVersion.WithResource(monitoringv1alpha1.ResourcePluralDashboard),
+		monitoringv1alpha1.SchemeGroupVersion.WithResource(monitoringv1alpha1.ResourceSingularDashboard), meta.RESTScopeRoot)
+
+	mapper.AddSpecific(monitoringv1alpha1.SchemeGroupVersion.WithKind(monitoringv1alpha1.ResourceKindMeteringRollup),
+		monitoringv1alpha1.SchemeGroupVersion.WithResource(monitoringv1alpha1.ResourcePluralMeteringRollup),
+		monitoringv1alpha1.SchemeGroupVersion.WithResource(monitoringv1alpha1.ResourceSingularMeteringRollup), meta.RESTScopeRoot)
+
 	spec, err := lib.RenderOpenAPISpec(lib.Config{
 		Scheme: Scheme,
 		Codecs: Codecs,
@@ -97,6 +130,9 @@ func main() {
 			tenantv1alpha1.GetOpenAPIDefinitions,
 			s2iv1alpha1.GetOpenAPIDefinitions,
 			networkv1alpha1.GetOpenAPIDefinitions,
+			loggingv1alpha1.GetOpenAPIDefinitions,
+			alertingv1alpha1.GetOpenAPIDefinitions,
+			monitoringv1alpha1.GetOpenAPIDefinitions,
 		},
 		Resources: []schema.GroupVersionResource{
 			//TODO（runzexia） At present, the document generation requires the openapi structure of the go language,
@@ -109,6 +145,12 @@ func main() {
 			s2iv1alpha1.SchemeGroupVersion.WithResource(s2iv1alpha1.ResourcePluralS2iBuilderTemplate),
 			s2iv1alpha1.SchemeGroupVersion.WithResource(s2iv1alpha1.ResourcePluralS2iBuilder),
 			networkv1alpha1.SchemeGroupVersion.WithResource(networkv1alpha1.ResourcePluralWorkspaceNetworkPolicy),
+			loggingv1alpha1.SchemeGroupVersion.WithResource(loggingv1alpha1.ResourcePluralLogAlertRule),
+			alertingv1alpha1.SchemeGroupVersion.WithResource(alertingv1alpha1.ResourcePluralAlertRule),
+			alertingv1alpha1.SchemeGroupVersion.WithResource(alertingv1alpha1.ResourcePluralAlertReceiver),
+			alertingv1alpha1.SchemeGroupVersion.WithResource(alertingv1alpha1.ResourcePluralAlertNotificationPolicy),
+			monitoringv1alpha1.SchemeGroupVersion.WithResource(monitoringv1alpha1.ResourcePluralDashboard),
+			monitoringv1alpha1.SchemeGroupVersion.WithResource(monitoringv1alpha1.ResourcePluralMeteringRollup),
 		},
 		Mapper: mapper,
 	})
@@ -101,7 +101,7 @@ func generateSwaggerJson() {
 		},
 		{
 			Name: "Logging",
-			Tags: []string{constants.LogQueryTag, constants.FluentBitSetting},
+			Tags: []string{constants.LogQueryTag, constants.EventQueryTag, constants.FluentBitSetting},
 		},
 	})
 